@@ -0,0 +1,497 @@
+package byop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	msgv1 "cosmossdk.io/api/cosmos/msg/v1"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/x/auth/migrations/legacytx"
+	"github.com/cosmos/gogoproto/jsonpb"
+	"github.com/cosmos/gogoproto/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// NewModuleFromDescriptors returns a module registering the messages named by msgNames,
+// such as osmosis.gamm.v1beta1.MsgSwapExactAmountIn, as implementations of sdk.Msg,
+// so that transactions and query responses holding them are decoded without compiling their Go types into lens.
+// The messages are implemented by DynamicMsg, from their definitions in fds.
+// If no names are given, the request messages of every Msg service defined in fds are registered.
+//
+// fds must hold the files defining the messages and the files they import, as written by
+// protoc --include_imports --descriptor_set_out or buf build, except for the files compiled into lens,
+// such as those of the Cosmos SDK.
+// The messages are only decoded by codecs built on an interface registry made by NewInterfaceRegistry.
+func NewModuleFromDescriptors(name string, fds *descriptorpb.FileDescriptorSet, msgNames ...string) (Module, error) {
+	files, err := newFiles(fds)
+	if err != nil {
+		return Module{}, err
+	}
+	resolver := typeResolver{local: newTypes(files)}
+
+	if len(msgNames) == 0 {
+		msgNames = msgServiceRequests(files)
+		if len(msgNames) == 0 {
+			return Module{}, errors.New("descriptors define no Msg service")
+		}
+	}
+
+	m := Module{ModuleName: name}
+	for _, msgName := range msgNames {
+		d, err := files.FindDescriptorByName(protoreflect.FullName(msgName))
+		if err != nil {
+			return Module{}, fmt.Errorf("message %s not found in descriptors", msgName)
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return Module{}, fmt.Errorf("%s is not a message", msgName)
+		}
+		t, err := newMsgType(md, resolver)
+		if err != nil {
+			return Module{}, err
+		}
+		m.msgTypes = append(m.msgTypes, t)
+	}
+	return m, nil
+}
+
+// newFiles returns the files of fds, whose imports are resolved from fds first,
+// then from the files compiled into the program.
+// Imports found in neither, such as those only declaring options, are left unresolved.
+func newFiles(fds *descriptorpb.FileDescriptorSet) (*protoregistry.Files, error) {
+	protos := make(map[string]*descriptorpb.FileDescriptorProto, len(fds.GetFile()))
+	for _, fd := range fds.GetFile() {
+		protos[fd.GetName()] = fd
+	}
+
+	files := new(protoregistry.Files)
+	resolver := fileResolver{local: files}
+	var add func(path string) error
+	add = func(path string) error {
+		fd, ok := protos[path]
+		if !ok {
+			return nil
+		}
+		// Files are only added once, even if they import each other.
+		delete(protos, path)
+		for _, dep := range fd.GetDependency() {
+			if err := add(dep); err != nil {
+				return err
+			}
+		}
+		f, err := protodesc.FileOptions{AllowUnresolvable: true}.New(fd, resolver)
+		if err != nil {
+			return fmt.Errorf("invalid descriptor of %s: %w", path, err)
+		}
+		return files.RegisterFile(f)
+	}
+	for _, fd := range fds.GetFile() {
+		if err := add(fd.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// newTypes returns the dynamic types of the messages and enums defined by files.
+func newTypes(files *protoregistry.Files) *protoregistry.Types {
+	types := new(protoregistry.Types)
+	var addMessages func(msgs protoreflect.MessageDescriptors)
+	addEnums := func(enums protoreflect.EnumDescriptors) {
+		for i := 0; i < enums.Len(); i++ {
+			_ = types.RegisterEnum(dynamicpb.NewEnumType(enums.Get(i)))
+		}
+	}
+	addMessages = func(msgs protoreflect.MessageDescriptors) {
+		for i := 0; i < msgs.Len(); i++ {
+			md := msgs.Get(i)
+			_ = types.RegisterMessage(dynamicpb.NewMessageType(md))
+			addEnums(md.Enums())
+			addMessages(md.Messages())
+		}
+	}
+	files.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+		addEnums(f.Enums())
+		addMessages(f.Messages())
+		return true
+	})
+	return types
+}
+
+// msgServiceRequests returns the names of the request messages of the services named Msg of files,
+// the services of the transaction messages of Cosmos SDK modules.
+func msgServiceRequests(files *protoregistry.Files) []string {
+	var names []string
+	seen := make(map[protoreflect.FullName]bool)
+	files.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+		for i := 0; i < f.Services().Len(); i++ {
+			sd := f.Services().Get(i)
+			if sd.Name() != "Msg" {
+				continue
+			}
+			for j := 0; j < sd.Methods().Len(); j++ {
+				input := sd.Methods().Get(j).Input().FullName()
+				if !seen[input] {
+					seen[input] = true
+					names = append(names, string(input))
+				}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// fileResolver resolves the imports of files from local first, then from the files compiled into the program.
+type fileResolver struct {
+	local *protoregistry.Files
+}
+
+func (r fileResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if f, err := r.local.FindFileByPath(path); err == nil {
+		return f, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r fileResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.local.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
+
+// typeResolver resolves the types of the Any values of dynamic messages, for their JSON encoding,
+// from local first, then from the types compiled into the program.
+type typeResolver struct {
+	local *protoregistry.Types
+}
+
+func (r typeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, err := r.local.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByName(name)
+}
+
+func (r typeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	if mt, err := r.local.FindMessageByURL(url); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByURL(url)
+}
+
+func (r typeResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r typeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+// msgType is the type of the messages of a module built by NewModuleFromDescriptors.
+type msgType struct {
+	desc     protoreflect.MessageDescriptor
+	resolver typeResolver
+
+	// signers are the fields holding the addresses of the signers of the messages,
+	// as named by the cosmos.msg.v1.signer option of their definition.
+	signers []protoreflect.FieldDescriptor
+
+	// aminoName is the name of the messages in their legacy amino JSON encoding, as set by Module.RegisterLegacyAminoCodec.
+	aminoName string
+}
+
+func newMsgType(md protoreflect.MessageDescriptor, resolver typeResolver) (*msgType, error) {
+	t := &msgType{desc: md, resolver: resolver, aminoName: string(md.FullName())}
+
+	// The options are decoded again, as the signer option is unknown to the decoder of descriptors
+	// that are not compiled with it.
+	b, err := protov2.Marshal(md.Options())
+	if err != nil {
+		return nil, err
+	}
+	var opts descriptorpb.MessageOptions
+	if err := protov2.Unmarshal(b, &opts); err != nil {
+		return nil, err
+	}
+	for _, name := range protov2.GetExtension(&opts, msgv1.E_Signer).([]string) {
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Kind() != protoreflect.StringKind {
+			return nil, fmt.Errorf("signer %s of message %s is not a string field", name, md.FullName())
+		}
+		t.signers = append(t.signers, fd)
+	}
+	return t, nil
+}
+
+func (t *msgType) typeURL() string {
+	return "/" + string(t.desc.FullName())
+}
+
+func (t *msgType) new() *DynamicMsg {
+	return &DynamicMsg{typ: t, msg: dynamicpb.NewMessage(t.desc)}
+}
+
+var (
+	_ sdk.Msg                = &DynamicMsg{}
+	_ legacytx.LegacyMsg     = &DynamicMsg{}
+	_ codec.ProtoMarshaler   = &DynamicMsg{}
+	_ jsonpb.JSONPBMarshaler = &DynamicMsg{}
+)
+
+// DynamicMsg is a message of a type registered by a module built by NewModuleFromDescriptors,
+// implemented from the descriptor of its type.
+//
+// Every DynamicMsg has the same Go type, from which the interface registry of the SDK cannot tell the type of a message:
+// it unpacks messages into DynamicMsg values that only hold their encoding,
+// which the InterfaceRegistry returned by NewInterfaceRegistry replaces with the decoded messages.
+// For the same reason, the binary tx decoder of the SDK, which checks the fields of messages against the descriptor of
+// their Go type, rejects transactions holding dynamic messages: they are decoded by unmarshaling a types/tx.Tx with the codec instead,
+// as lens query tx does.
+type DynamicMsg struct {
+	typ *msgType
+	msg *dynamicpb.Message
+
+	// raw is the encoding of the message, if its type is not known.
+	raw []byte
+}
+
+// Message returns the decoded message, or nil if the type of the message is not known.
+func (m *DynamicMsg) Message() *dynamicpb.Message {
+	return m.msg
+}
+
+func (m *DynamicMsg) Reset() {
+	if m.msg != nil {
+		m.msg.Reset()
+	}
+	m.raw = nil
+}
+
+func (m *DynamicMsg) String() string {
+	if m.msg == nil {
+		return fmt.Sprintf("%X", m.raw)
+	}
+	return m.msg.String()
+}
+
+func (m *DynamicMsg) ProtoMessage() {}
+
+// XXX_MessageName returns the name of the type of the message, as proto.MessageName does.
+func (m *DynamicMsg) XXX_MessageName() string {
+	if m.typ == nil {
+		return ""
+	}
+	return string(m.typ.desc.FullName())
+}
+
+func (m *DynamicMsg) Marshal() ([]byte, error) {
+	if m.msg == nil {
+		return m.raw, nil
+	}
+	return protov2.MarshalOptions{Deterministic: true}.Marshal(m.msg)
+}
+
+func (m *DynamicMsg) MarshalTo(dAtA []byte) (int, error) {
+	return m.MarshalToSizedBuffer(dAtA[:m.Size()])
+}
+
+func (m *DynamicMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	b, err := m.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > len(dAtA) {
+		return 0, errors.New("buffer too small for message")
+	}
+	return copy(dAtA[len(dAtA)-len(b):], b), nil
+}
+
+func (m *DynamicMsg) Size() int {
+	if m.msg == nil {
+		return len(m.raw)
+	}
+	return protov2.Size(m.msg)
+}
+
+func (m *DynamicMsg) Unmarshal(b []byte) error {
+	if m.msg == nil {
+		m.raw = append([]byte(nil), b...)
+		return nil
+	}
+	return protov2.Unmarshal(b, m.msg)
+}
+
+func (m *DynamicMsg) MarshalJSONPB(jm *jsonpb.Marshaler) ([]byte, error) {
+	if m.msg == nil {
+		return nil, errors.New("cannot encode a message of unknown type as JSON")
+	}
+	return protojson.MarshalOptions{
+		Indent:          jm.Indent,
+		UseProtoNames:   jm.OrigName,
+		EmitUnpopulated: jm.EmitDefaults,
+		Resolver:        m.typ.resolver,
+	}.Marshal(m.msg)
+}
+
+func (m *DynamicMsg) UnmarshalJSONPB(u *jsonpb.Unmarshaler, b []byte) error {
+	if m.msg == nil {
+		return errors.New("cannot decode a message of unknown type from JSON")
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: u.AllowUnknownFields, Resolver: m.typ.resolver}.Unmarshal(b, m.msg)
+}
+
+// ValidateBasic accepts any message, as the constraints of its type are not known.
+func (m *DynamicMsg) ValidateBasic() error {
+	return nil
+}
+
+// GetSigners returns the addresses held by the fields named by the cosmos.msg.v1.signer option of the type of the message.
+func (m *DynamicMsg) GetSigners() []sdk.AccAddress {
+	if m.msg == nil {
+		return nil
+	}
+	var signers []sdk.AccAddress
+	for _, fd := range m.typ.signers {
+		var addrs []string
+		if fd.IsList() {
+			list := m.msg.Get(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				addrs = append(addrs, list.Get(i).String())
+			}
+		} else {
+			addrs = append(addrs, m.msg.Get(fd).String())
+		}
+		for _, addr := range addrs {
+			if _, bz, err := bech32.DecodeAndConvert(addr); err == nil {
+				signers = append(signers, bz)
+			}
+		}
+	}
+	return signers
+}
+
+// GetSignBytes returns the legacy amino JSON encoding of the message, signed by SIGN_MODE_LEGACY_AMINO_JSON:
+// its amino name and its fields, named as in its definition, with sorted keys.
+// Fields holding their default value are omitted, as amino does.
+func (m *DynamicMsg) GetSignBytes() []byte {
+	if m.msg == nil {
+		panic("byop: cannot sign a message of unknown type")
+	}
+	value, err := protojson.MarshalOptions{UseProtoNames: true, Resolver: m.typ.resolver}.Marshal(m.msg)
+	if err != nil {
+		panic(err)
+	}
+	bz, err := json.Marshal(struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}{m.typ.aminoName, value})
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(bz)
+}
+
+// Route returns the part of the amino name of the message before its first slash, the module of most amino names,
+// or an empty string if the name has no slash.
+func (m *DynamicMsg) Route() string {
+	route, _, ok := strings.Cut(m.Type(), "/")
+	if !ok {
+		return ""
+	}
+	return route
+}
+
+// Type returns the amino name of the message.
+func (m *DynamicMsg) Type() string {
+	if m.typ == nil {
+		return ""
+	}
+	return m.typ.aminoName
+}
+
+// InterfaceRegistry is an interface registry that also unpacks the messages registered by the modules
+// built by NewModuleFromDescriptors, into DynamicMsg values.
+type InterfaceRegistry struct {
+	types.InterfaceRegistry
+
+	// msgTypes are the types of the messages of the modules built by NewModuleFromDescriptors, by type URL.
+	msgTypes map[string]*msgType
+}
+
+// NewInterfaceRegistry returns an empty interface registry, which, unlike that of types.NewInterfaceRegistry,
+// decodes the messages of the modules built by NewModuleFromDescriptors.
+func NewInterfaceRegistry() *InterfaceRegistry {
+	return &InterfaceRegistry{
+		InterfaceRegistry: types.NewInterfaceRegistry(),
+		msgTypes:          make(map[string]*msgType),
+	}
+}
+
+// registerMsgTypes registers the types of dynamic messages as implementations of sdk.Msg.
+// The embedded registry unpacks them into DynamicMsg values holding their encoding,
+// as it does for the messages nested in others, such as those of authz MsgExec,
+// which are resolved by their type URL when encoded as JSON.
+func (r *InterfaceRegistry) registerMsgTypes(msgTypes []*msgType) {
+	registry := r.InterfaceRegistry.(interface {
+		RegisterCustomTypeURL(iface interface{}, typeURL string, impl proto.Message)
+	})
+	for _, t := range msgTypes {
+		registry.RegisterCustomTypeURL((*sdk.Msg)(nil), t.typeURL(), &DynamicMsg{})
+		r.msgTypes[t.typeURL()] = t
+	}
+}
+
+// UnpackAny unpacks any as the embedded registry does, except that dynamic messages are decoded from their descriptor.
+func (r *InterfaceRegistry) UnpackAny(any *types.Any, iface interface{}) error {
+	if err := r.InterfaceRegistry.UnpackAny(any, iface); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(iface).Elem()
+	msg, ok := rv.Interface().(*DynamicMsg)
+	if !ok || msg.typ != nil {
+		return nil
+	}
+	t, ok := r.msgTypes[any.TypeUrl]
+	if !ok {
+		return nil
+	}
+	decoded := t.new()
+	if err := decoded.Unmarshal(any.Value); err != nil {
+		return err
+	}
+	rv.Set(reflect.ValueOf(decoded))
+
+	// Cache the decoded message in place of the one of the embedded registry,
+	// keeping the encoding of the message as it was.
+	packed, err := types.NewAnyWithValue(decoded)
+	if err != nil {
+		return err
+	}
+	packed.TypeUrl, packed.Value, packed.XXX_unrecognized = any.TypeUrl, any.Value, any.XXX_unrecognized
+	*any = *packed
+	return nil
+}
+
+// Resolve returns an empty message of the type of typeURL, as the embedded registry does,
+// except that dynamic messages know their type.
+func (r *InterfaceRegistry) Resolve(typeURL string) (proto.Message, error) {
+	if t, ok := r.msgTypes[typeURL]; ok {
+		return t.new(), nil
+	}
+	return r.InterfaceRegistry.Resolve(typeURL)
+}
@@ -0,0 +1,246 @@
+package byop_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	msgv1 "cosmossdk.io/api/cosmos/msg/v1"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"github.com/strangelove-ventures/lens/byop"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testDescriptors returns the descriptors of the lens.test.v1.Msg service,
+// whose MsgCustom is signed by its sender.
+func testDescriptors(t *testing.T) *descriptorpb.FileDescriptorSet {
+	t.Helper()
+
+	signer := &descriptorpb.MessageOptions{}
+	proto.SetExtension(signer, msgv1.E_Signer, []string{"sender"})
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+		protodesc.ToFileDescriptorProto(msgv1.File_cosmos_msg_v1_msg_proto),
+		{
+			Name:       proto.String("lens/test/v1/tx.proto"),
+			Package:    proto.String("lens.test.v1"),
+			Syntax:     proto.String("proto3"),
+			Dependency: []string{"cosmos/msg/v1/msg.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("MsgCustom"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("sender", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+						field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+					},
+					Options: signer,
+				},
+				{Name: proto.String("MsgCustomResponse")},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("Msg"),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("Custom"),
+					InputType:  proto.String(".lens.test.v1.MsgCustom"),
+					OutputType: proto.String(".lens.test.v1.MsgCustomResponse"),
+				}},
+			}},
+		},
+	}}
+}
+
+func TestNewModuleFromDescriptors(t *testing.T) {
+	t.Parallel()
+
+	fds := testDescriptors(t)
+	m, err := byop.NewModuleFromDescriptors("test", fds)
+	require.NoError(t, err)
+	cdc := client.MakeCodec(append(append([]module.AppModuleBasic{}, client.ModuleBasics...), m), nil)
+
+	files, err := protodesc.NewFiles(fds)
+	require.NoError(t, err)
+	d, err := files.FindDescriptorByName("lens.test.v1.MsgCustom")
+	require.NoError(t, err)
+	md := d.(protoreflect.MessageDescriptor)
+
+	signer := sdk.AccAddress(bytes.Repeat([]byte{1}, 20))
+	signerAddr, err := bech32.ConvertAndEncode("cosmos", signer)
+	require.NoError(t, err)
+	custom := dynamicpb.NewMessage(md)
+	custom.Set(md.Fields().ByName("sender"), protoreflect.ValueOfString(signerAddr))
+	custom.Set(md.Fields().ByName("amount"), protoreflect.ValueOfUint64(7))
+	// Fields are only encoded in the order of their numbers when deterministic, as DynamicMsg encodes them.
+	value, err := proto.MarshalOptions{Deterministic: true}.Marshal(custom)
+	require.NoError(t, err)
+
+	// A tx holding a MsgCustom, and another nested in an authz MsgExec.
+	exec, err := codectypes.NewAnyWithValue(&authz.MsgExec{
+		Grantee: signerAddr,
+		Msgs:    []*codectypes.Any{{TypeUrl: "/lens.test.v1.MsgCustom", Value: value}},
+	})
+	require.NoError(t, err)
+	body, err := (&txtypes.TxBody{Messages: []*codectypes.Any{{TypeUrl: "/lens.test.v1.MsgCustom", Value: value}, exec}}).Marshal()
+	require.NoError(t, err)
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{}}).Marshal()
+	require.NoError(t, err)
+	txBytes, err := (&txtypes.TxRaw{BodyBytes: body, AuthInfoBytes: authInfo, Signatures: [][]byte{{}}}).Marshal()
+	require.NoError(t, err)
+
+	var tx txtypes.Tx
+	require.NoError(t, cdc.Marshaler.Unmarshal(txBytes, &tx))
+	msgs := tx.GetMsgs()
+	require.Len(t, msgs, 2)
+	msg, ok := msgs[0].(*byop.DynamicMsg)
+	require.True(t, ok, "expected a DynamicMsg, got %T", msgs[0])
+	require.Equal(t, uint64(7), msg.Message().Get(msg.Message().Descriptor().Fields().ByName("amount")).Uint())
+	require.Equal(t, []sdk.AccAddress{signer}, msg.GetSigners())
+
+	out, err := cdc.Marshaler.MarshalJSON(&tx)
+	require.NoError(t, err)
+	var decoded struct {
+		Body struct {
+			Messages []json.RawMessage
+		}
+	}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	want := `{"@type":"/lens.test.v1.MsgCustom","sender":"` + signerAddr + `","amount":"7"}`
+	require.JSONEq(t, want, string(decoded.Body.Messages[0]))
+	require.JSONEq(t, `{"@type":"/cosmos.authz.v1beta1.MsgExec","grantee":"`+signerAddr+`","msgs":[`+want+`]}`, string(decoded.Body.Messages[1]))
+
+	// The JSON encoding is decoded back to the same tx.
+	var fromJSON txtypes.Tx
+	require.NoError(t, cdc.Marshaler.UnmarshalJSON(out, &fromJSON))
+	reencoded, err := cdc.Marshaler.Marshal(&fromJSON)
+	require.NoError(t, err)
+	require.Equal(t, txBytes, reencoded)
+}
+
+func TestNewModuleFromDescriptors_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := byop.NewModuleFromDescriptors("test", testDescriptors(t), "lens.test.v1.MsgMissing")
+	require.EqualError(t, err, "message lens.test.v1.MsgMissing not found in descriptors")
+
+	_, err = byop.NewModuleFromDescriptors("test", &descriptorpb.FileDescriptorSet{})
+	require.EqualError(t, err, "descriptors define no Msg service")
+
+	// Decoding dynamic messages requires the registry of byop.
+	require.PanicsWithValue(t,
+		"byop: the messages of module test built from descriptors require an interface registry made by NewInterfaceRegistry",
+		func() {
+			m, err := byop.NewModuleFromDescriptors("test", testDescriptors(t))
+			require.NoError(t, err)
+			m.RegisterInterfaces(codectypes.NewInterfaceRegistry())
+		},
+	)
+}
+
+func TestDynamicMsg_AminoJSONSignBytes(t *testing.T) {
+	t.Parallel()
+
+	m, err := byop.NewModuleFromDescriptors("test", testDescriptors(t))
+	require.NoError(t, err)
+	m.AminoNames = map[string]string{"/lens.test.v1.MsgCustom": "lens/MsgCustom"}
+	cdc := client.MakeCodec(append(append([]module.AppModuleBasic{}, client.ModuleBasics...), m), nil)
+
+	priv := secp256k1.GenPrivKey()
+	signer := sdk.AccAddress(priv.PubKey().Address())
+	signerAddr, err := bech32.ConvertAndEncode("cosmos", signer)
+	require.NoError(t, err)
+
+	// A MsgCustom decoded from its encoding, as when signing a transaction read from a file.
+	resolved, err := cdc.InterfaceRegistry.Resolve("/lens.test.v1.MsgCustom")
+	require.NoError(t, err)
+	msg := resolved.(*byop.DynamicMsg)
+	require.NoError(t, cdc.Marshaler.UnmarshalJSON([]byte(`{"sender":"`+signerAddr+`","amount":"7"}`), msg))
+	require.Equal(t, "lens/MsgCustom", msg.Type())
+	require.Equal(t, "lens", msg.Route())
+
+	signerData := authsigning.SignerData{
+		Address:       signerAddr,
+		ChainID:       "test-1",
+		AccountNumber: 3,
+		Sequence:      5,
+		PubKey:        priv.PubKey(),
+	}
+	signBytes := func() []byte {
+		txb := cdc.TxConfig.NewTxBuilder()
+		require.NoError(t, txb.SetMsgs(msg))
+		txb.SetGasLimit(100000)
+		require.NoError(t, txb.SetSignatures(signing.SignatureV2{
+			PubKey:   priv.PubKey(),
+			Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+			Sequence: signerData.Sequence,
+		}))
+		bz, err := cdc.TxConfig.SignModeHandler().GetSignBytes(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, signerData, txb.GetTx())
+		require.NoError(t, err)
+		return bz
+	}
+
+	bz := signBytes()
+	require.Equal(t, bz, signBytes())
+	require.JSONEq(t, `{
+		"account_number": "3",
+		"chain_id": "test-1",
+		"fee": {"amount": [], "gas": "100000"},
+		"memo": "",
+		"msgs": [{"type": "lens/MsgCustom", "value": {"amount": "7", "sender": "`+signerAddr+`"}}],
+		"sequence": "5"
+	}`, string(bz))
+	require.Contains(t, string(bz), `{"type":"lens/MsgCustom","value":{"amount":"7","sender":"`+signerAddr+`"}}`)
+
+	sig, err := priv.Sign(bz)
+	require.NoError(t, err)
+	require.True(t, priv.PubKey().VerifySignature(bz, sig))
+}
+
+func TestModule_RegisterLegacyAminoCodec(t *testing.T) {
+	t.Parallel()
+
+	m := byop.Module{
+		ModuleName: "test",
+		MsgsImplementations: []byop.RegisterImplementation{{
+			Iface: (*sdk.Msg)(nil),
+			Msgs:  []gogoproto.Message{&banktypes.MsgSend{}, &banktypes.MsgMultiSend{}},
+		}},
+		AminoNames: map[string]string{"/cosmos.bank.v1beta1.MsgSend": "test/MsgSend"},
+	}
+	cdc := codec.NewLegacyAmino()
+	m.RegisterLegacyAminoCodec(cdc)
+
+	bz, err := cdc.MarshalJSON(&banktypes.MsgSend{FromAddress: "from"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"test/MsgSend","value":{"from_address":"from","amount":[]}}`, string(bz))
+
+	// Messages without an amino name are named after their type URL.
+	bz, err = cdc.MarshalJSON(&banktypes.MsgMultiSend{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"cosmos.bank.v1beta1.MsgMultiSend","value":{"inputs":null,"outputs":null}}`, string(bz))
+}
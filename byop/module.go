@@ -2,6 +2,8 @@ package byop
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -31,6 +33,14 @@ type Module struct {
 
 	MsgsInterfaces      []RegisterInterface
 	MsgsImplementations []RegisterImplementation
+
+	// AminoNames are the names of the messages of the module on the legacy amino codec, such as osmosis/gamm/swap-exact-amount-in,
+	// by type URL, used by SIGN_MODE_LEGACY_AMINO_JSON, as required by Ledger devices.
+	// Messages without one are named after their type URL, without its leading slash.
+	AminoNames map[string]string
+
+	// msgTypes are the types of the messages of a module built by NewModuleFromDescriptors.
+	msgTypes []*msgType
 }
 
 // RegisterInterfaces is the only method that we care about. It registers the
@@ -42,27 +52,71 @@ func (m Module) RegisterInterfaces(registry types.InterfaceRegistry) {
 	for _, mi := range m.MsgsImplementations {
 		registry.RegisterImplementations(mi.Iface, mi.Msgs...)
 	}
+	if len(m.msgTypes) > 0 {
+		r, ok := registry.(*InterfaceRegistry)
+		if !ok {
+			panic(fmt.Sprintf("byop: the messages of module %s built from descriptors require an interface registry made by NewInterfaceRegistry", m.ModuleName))
+		}
+		r.registerMsgTypes(m.msgTypes)
+	}
 }
 
-// All other methods below exist just to fulfill the module.AppModuleBasic interface.
-
-func (m Module) Name() string { return m.ModuleName }
-
+// RegisterLegacyAminoCodec registers the messages of the module on cdc, under their amino name,
+// so that transactions holding them are signed with SIGN_MODE_LEGACY_AMINO_JSON.
+// The messages built from descriptors all share the Go type of DynamicMsg, so they are not registered on cdc:
+// their amino name is kept in their type instead, for DynamicMsg.GetSignBytes.
 func (m Module) RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	registered := make(map[string]bool)
+	register := func(msgs []proto.Message) {
+		for _, msg := range msgs {
+			typeURL := "/" + proto.MessageName(msg)
+			if registered[typeURL] {
+				continue
+			}
+			registered[typeURL] = true
+			cdc.RegisterConcrete(msg, m.aminoName(typeURL), nil)
+		}
+	}
+	for _, mi := range m.MsgsInterfaces {
+		register(mi.Msgs)
+	}
+	for _, mi := range m.MsgsImplementations {
+		register(mi.Msgs)
+	}
+	for _, t := range m.msgTypes {
+		t.aminoName = m.aminoName(t.typeURL())
+	}
 }
 
+// aminoName returns the amino name of the message of type typeURL.
+func (m Module) aminoName(typeURL string) string {
+	if name, ok := m.AminoNames[typeURL]; ok {
+		return name
+	}
+	return strings.TrimPrefix(typeURL, "/")
+}
+
+// All other methods below exist just to fulfill the module.AppModuleBasic interface,
+// and do nothing, so that the SDK code calling them on every module, such as the basic manager, does not fail.
+
+func (m Module) Name() string { return m.ModuleName }
+
+// DefaultGenesis returns an empty genesis state, as the module has none.
 func (m Module) DefaultGenesis(codec.JSONCodec) json.RawMessage {
-	panic("not required")
+	return json.RawMessage("{}")
 }
 
+// ValidateGenesis accepts any genesis state, as the module has none.
 func (m Module) ValidateGenesis(codec.JSONCodec, client.TxEncodingConfig, json.RawMessage) error {
-	panic("not required")
+	return nil
 }
 
-func (m Module) RegisterRESTRoutes(client.Context, *mux.Router) { panic("not required") }
+func (m Module) RegisterRESTRoutes(client.Context, *mux.Router) {}
 
-func (m Module) RegisterGRPCGatewayRoutes(client.Context, *runtime.ServeMux) { panic("not required") }
+func (m Module) RegisterGRPCGatewayRoutes(client.Context, *runtime.ServeMux) {}
 
-func (m Module) GetTxCmd() *cobra.Command { panic("not required") }
+// GetTxCmd returns nil, as the module has no commands, which the SDK skips when adding the commands of modules.
+func (m Module) GetTxCmd() *cobra.Command { return nil }
 
-func (m Module) GetQueryCmd() *cobra.Command { panic("not required") }
+// GetQueryCmd returns nil, as the module has no commands.
+func (m Module) GetQueryCmd() *cobra.Command { return nil }
@@ -0,0 +1,35 @@
+package byop_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/byop"
+	lensclient "github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModule_BasicManager(t *testing.T) {
+	t.Parallel()
+
+	m := byop.Module{ModuleName: "custom"}
+	cdc := lensclient.MakeCodec([]module.AppModuleBasic{m}, nil)
+	mgr := module.NewBasicManager(m)
+
+	genesis := mgr.DefaultGenesis(cdc.Marshaler)
+	require.Equal(t, map[string]json.RawMessage{"custom": json.RawMessage("{}")}, genesis)
+	require.NoError(t, mgr.ValidateGenesis(cdc.Marshaler, cdc.TxConfig, genesis))
+
+	rootTxCmd, rootQueryCmd := &cobra.Command{Use: "tx"}, &cobra.Command{Use: "query"}
+	mgr.AddTxCommands(rootTxCmd)
+	mgr.AddQueryCommands(rootQueryCmd)
+	require.Empty(t, rootTxCmd.Commands())
+	require.Empty(t, rootQueryCmd.Commands())
+
+	require.NotPanics(t, func() {
+		mgr.RegisterGRPCGatewayRoutes(client.Context{}, nil)
+	})
+}
@@ -0,0 +1,89 @@
+package byop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	reflectionv2 "github.com/cosmos/cosmos-sdk/server/grpc/reflection/v2alpha1"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// FromReflection returns a module registering the messages of the chain served at the other end of conn
+// whose Go types are not compiled into lens, as NewModuleFromDescriptors does, from the descriptors of the chain itself.
+// The messages are those the chain lists as implementations of sdk.Msg,
+// restricted to those whose type URL, such as /osmosis.gamm.v1beta1.MsgSwapExactAmountIn, starts with one of typeURLPrefixes, if any.
+// The module is named after the target of conn.
+//
+// Every call downloads the descriptors again: ReflectMsgDescriptors returns them, for callers that keep them between runs.
+func FromReflection(ctx context.Context, conn *grpc.ClientConn, typeURLPrefixes ...string) (Module, error) {
+	fds, msgNames, err := ReflectMsgDescriptors(ctx, conn, typeURLPrefixes...)
+	if err != nil {
+		return Module{}, err
+	}
+	return NewModuleFromReflectedDescriptors(conn.Target(), fds, msgNames)
+}
+
+// NewModuleFromReflectedDescriptors returns the module of the descriptors and message names returned by ReflectMsgDescriptors.
+// Unlike NewModuleFromDescriptors, it returns a module registering no message if msgNames is empty,
+// as the messages of chains built only from the modules compiled into lens are all known.
+func NewModuleFromReflectedDescriptors(name string, fds *descriptorpb.FileDescriptorSet, msgNames []string) (Module, error) {
+	if len(msgNames) == 0 {
+		return Module{ModuleName: name}, nil
+	}
+	return NewModuleFromDescriptors(name, fds, msgNames...)
+}
+
+// ReflectMsgDescriptors returns the names of the messages of the chain served at the other end of conn
+// that are selected by FromReflection, and the descriptors of the files defining them and their imports.
+//
+// The messages are listed by the cosmos.base.reflection.v2alpha1 service of the Cosmos SDK,
+// and their descriptors are resolved through the gRPC server reflection service.
+func ReflectMsgDescriptors(ctx context.Context, conn grpc.ClientConnInterface, typeURLPrefixes ...string) (*descriptorpb.FileDescriptorSet, []string, error) {
+	res, err := reflectionv2.NewReflectionServiceClient(conn).GetTxDescriptor(ctx, &reflectionv2.GetTxDescriptorRequest{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list the messages of the chain: %w", err)
+	}
+
+	rc := grpcreflect.NewClientAuto(ctx, conn)
+	defer rc.Reset()
+
+	var msgNames []string
+	var files []*desc.FileDescriptor
+	for _, md := range res.GetTx().GetMsgs() {
+		typeURL := md.GetMsgTypeUrl()
+		if !hasAnyPrefix(typeURL, typeURLPrefixes) {
+			continue
+		}
+		name := strings.TrimPrefix(typeURL, "/")
+		// Messages compiled into lens are decoded by their Go types.
+		if proto.MessageType(name) != nil {
+			continue
+		}
+		msgDesc, err := rc.ResolveMessage(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve message %s: %w", name, err)
+		}
+		msgNames = append(msgNames, name)
+		files = append(files, msgDesc.GetFile())
+	}
+	return desc.ToFileDescriptorSet(files...), msgNames, nil
+}
+
+// hasAnyPrefix reports whether typeURL starts with one of prefixes, with or without their leading slash,
+// or whether prefixes is empty.
+func hasAnyPrefix(typeURL string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(typeURL, "/"+strings.TrimPrefix(p, "/")) {
+			return true
+		}
+	}
+	return false
+}
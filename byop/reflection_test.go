@@ -0,0 +1,109 @@
+package byop_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	reflectionv2 "github.com/cosmos/cosmos-sdk/server/grpc/reflection/v2alpha1"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/byop"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/reflect/protodesc"
+)
+
+// txDescriptorServer lists msgTypeURLs as the messages of the chain.
+type txDescriptorServer struct {
+	reflectionv2.UnimplementedReflectionServiceServer
+	msgTypeURLs []string
+}
+
+func (s *txDescriptorServer) GetTxDescriptor(context.Context, *reflectionv2.GetTxDescriptorRequest) (*reflectionv2.GetTxDescriptorResponse, error) {
+	tx := &reflectionv2.TxDescriptor{Fullname: "cosmos.tx.v1beta1.Tx"}
+	for _, typeURL := range s.msgTypeURLs {
+		tx.Msgs = append(tx.Msgs, &reflectionv2.MsgDescriptor{MsgTypeUrl: typeURL})
+	}
+	return &reflectionv2.GetTxDescriptorResponse{Tx: tx}, nil
+}
+
+// dialReflectionServer starts an in-process gRPC server listing the messages of the lens.test.v1.Msg service,
+// and one compiled into lens, and resolving their descriptors, and returns a connection to it.
+func dialReflectionServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	files, err := protodesc.NewFiles(testDescriptors(t))
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	reflectionv2.RegisterReflectionServiceServer(srv, &txDescriptorServer{
+		msgTypeURLs: []string{"/cosmos.bank.v1beta1.MsgSend", "/lens.test.v1.MsgCustom"},
+	})
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{Services: srv, DescriptorResolver: files}))
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestFromReflection(t *testing.T) {
+	t.Parallel()
+
+	conn := dialReflectionServer(t)
+	m, err := byop.FromReflection(context.Background(), conn)
+	require.NoError(t, err)
+	cdc := client.MakeCodec(append(append([]module.AppModuleBasic{}, client.ModuleBasics...), m), nil)
+
+	// Only the message not compiled into lens is dynamic.
+	msg, err := cdc.InterfaceRegistry.Resolve("/lens.test.v1.MsgCustom")
+	require.NoError(t, err)
+	require.IsType(t, &byop.DynamicMsg{}, msg)
+	msg, err = cdc.InterfaceRegistry.Resolve("/cosmos.bank.v1beta1.MsgSend")
+	require.NoError(t, err)
+	require.IsType(t, &banktypes.MsgSend{}, msg)
+
+	// The descriptors decode the messages once the connection is gone.
+	fds, msgNames, err := byop.ReflectMsgDescriptors(context.Background(), conn)
+	require.NoError(t, err)
+	require.Equal(t, []string{"lens.test.v1.MsgCustom"}, msgNames)
+	require.NoError(t, conn.Close())
+	m, err = byop.NewModuleFromReflectedDescriptors("test", fds, msgNames)
+	require.NoError(t, err)
+	registry := byop.NewInterfaceRegistry()
+	m.RegisterInterfaces(registry)
+	msg, err = registry.Resolve("/lens.test.v1.MsgCustom")
+	require.NoError(t, err)
+	require.IsType(t, &byop.DynamicMsg{}, msg)
+}
+
+func TestFromReflection_TypeURLPrefixes(t *testing.T) {
+	t.Parallel()
+
+	conn := dialReflectionServer(t)
+
+	_, msgNames, err := byop.ReflectMsgDescriptors(context.Background(), conn, "lens.test.")
+	require.NoError(t, err)
+	require.Equal(t, []string{"lens.test.v1.MsgCustom"}, msgNames)
+
+	// A chain without unknown messages makes a module registering none.
+	m, err := byop.FromReflection(context.Background(), conn, "/osmosis.")
+	require.NoError(t, err)
+	registry := byop.NewInterfaceRegistry()
+	m.RegisterInterfaces(registry)
+	_, err = registry.Resolve("/lens.test.v1.MsgCustom")
+	require.Error(t, err)
+}
@@ -26,6 +26,12 @@ func (cc *ChainClient) GetAccount(clientCtx client.Context, addr sdk.AccAddress)
 // height of the query with the account. An error is returned if the query
 // or decoding fails.
 func (cc *ChainClient) GetAccountWithHeight(clientCtx client.Context, addr sdk.AccAddress) (client.Account, int64, error) {
+	// The methods of client.AccountRetriever take no context, so the query is only bounded by the timeout of the chain.
+	return cc.accountWithHeight(context.Background(), addr)
+}
+
+// accountWithHeight is GetAccountWithHeight, bounded by ctx.
+func (cc *ChainClient) accountWithHeight(ctx context.Context, addr sdk.AccAddress) (client.Account, int64, error) {
 	var header metadata.MD
 	address, err := cc.EncodeBech32AccAddr(addr)
 	if err != nil {
@@ -33,7 +39,7 @@ func (cc *ChainClient) GetAccountWithHeight(clientCtx client.Context, addr sdk.A
 	}
 
 	queryClient := authtypes.NewQueryClient(cc)
-	res, err := queryClient.Account(context.Background(), &authtypes.QueryAccountRequest{Address: address}, grpc.Header(&header))
+	res, err := queryClient.Account(ctx, &authtypes.QueryAccountRequest{Address: address}, grpc.Header(&header))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -39,13 +39,22 @@ func (cc *ChainClient) EncodeBech32ConsPub(addr sdk.AccAddress) (string, error)
 	return sdk.Bech32ifyAddressBytes(fmt.Sprintf("%s%s", cc.Config.AccountPrefix, "valconspub"), addr)
 }
 
+// DecodeBech32AccAddr decodes the account address addr, or the address of the contact it references, as in @alice.
 func (cc *ChainClient) DecodeBech32AccAddr(addr string) (sdk.AccAddress, error) {
+	if bz, ok, err := cc.contactAddress(addr); ok {
+		return bz, err
+	}
 	return sdk.GetFromBech32(addr, cc.Config.AccountPrefix)
 }
 func (cc *ChainClient) DecodeBech32AccPub(addr string) (sdk.AccAddress, error) {
 	return sdk.GetFromBech32(addr, fmt.Sprintf("%s%s", cc.Config.AccountPrefix, "pub"))
 }
+
+// DecodeBech32ValAddr decodes the validator operator address addr, or the address of the contact it references.
 func (cc *ChainClient) DecodeBech32ValAddr(addr string) (sdk.ValAddress, error) {
+	if bz, ok, err := cc.contactAddress(addr); ok {
+		return bz, err
+	}
 	return sdk.GetFromBech32(addr, fmt.Sprintf("%s%s", cc.Config.AccountPrefix, "valoper"))
 }
 func (cc *ChainClient) DecodeBech32ValPub(addr string) (sdk.AccAddress, error) {
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultBlockTimeSamples is how many recent blocks SampleBlockTime averages over by default.
+const DefaultBlockTimeSamples = 100
+
+// blockchainInfoMaxBlocks is the most block headers returned by a single blockchain RPC request.
+const blockchainInfoMaxBlocks = 20
+
+// BlockTimeSample is the average time between recent blocks of a chain.
+type BlockTimeSample struct {
+	// LatestHeight and LatestTime are the height and time of the latest block.
+	LatestHeight int64
+	LatestTime   time.Time
+
+	// Blocks is the number of block intervals averaged, fewer than requested if the node does not keep enough blocks.
+	Blocks int64
+
+	// Average is the average time between the blocks sampled.
+	Average time.Duration
+}
+
+// Estimate returns the estimated time at which the chain reaches height, from the latest block at the average block time.
+// Heights already reached return their estimated past time.
+func (s BlockTimeSample) Estimate(height int64) time.Time {
+	return s.LatestTime.Add(time.Duration(height-s.LatestHeight) * s.Average)
+}
+
+// SampleBlockTime fetches the headers of up to samples+1 recent blocks, and returns the average time between them.
+// Blocks older than the earliest one kept by the node are not sampled; at least two blocks must be available.
+func (cc *ChainClient) SampleBlockTime(ctx context.Context, samples int64) (BlockTimeSample, error) {
+	if samples < 1 {
+		samples = DefaultBlockTimeSamples
+	}
+	status, err := cc.RPCClient.Status(ctx)
+	if err != nil {
+		return BlockTimeSample{}, err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+	oldest := latest - samples
+	if earliest := status.SyncInfo.EarliestBlockHeight; oldest < earliest {
+		oldest = earliest
+	}
+	if oldest < 1 {
+		oldest = 1
+	}
+	if oldest >= latest {
+		return BlockTimeSample{}, fmt.Errorf("cannot sample block times: the node only has block %d", latest)
+	}
+
+	// The blockchain RPC method returns the headers of a range of blocks, a page of them at a time.
+	times := make(map[int64]time.Time, latest-oldest+1)
+	for max := latest; max >= oldest; max -= blockchainInfoMaxBlocks {
+		min := max - blockchainInfoMaxBlocks + 1
+		if min < oldest {
+			min = oldest
+		}
+		res, err := cc.RPCClient.BlockchainInfo(ctx, min, max)
+		if err != nil {
+			return BlockTimeSample{}, fmt.Errorf("failed to fetch the headers of blocks %d to %d: %w", min, max, err)
+		}
+		for _, meta := range res.BlockMetas {
+			times[meta.Header.Height] = meta.Header.Time
+		}
+	}
+	for _, h := range []int64{oldest, latest} {
+		if _, ok := times[h]; !ok {
+			return BlockTimeSample{}, fmt.Errorf("cannot sample block times: the node did not return the header of block %d", h)
+		}
+	}
+
+	blocks := latest - oldest
+	return BlockTimeSample{
+		LatestHeight: latest,
+		LatestTime:   times[latest],
+		Blocks:       blocks,
+		Average:      times[latest].Sub(times[oldest]) / time.Duration(blocks),
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
@@ -11,6 +12,7 @@ import (
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 )
 
 const (
@@ -19,16 +21,39 @@ const (
 )
 
 func (cc *ChainClient) BroadcastTx(ctx context.Context, tx []byte) (*sdk.TxResponse, error) {
-	var (
-		blockTimeout time.Duration = defaultBroadcastWaitTimeout
-		err          error
-	)
+	return cc.BroadcastTxWithMode(ctx, tx, BroadcastBlock, 0)
+}
 
-	if cc.Config.BlockTimeout != "" {
-		blockTimeout, err = time.ParseDuration(cc.Config.BlockTimeout)
+// BroadcastTxWithMode broadcasts tx in the given mode, one of the broadcast modes of TxOptions,
+// or BroadcastBlock if it is empty.
+// In BroadcastBlock mode, the inclusion of tx is waited for until blockTimeout if it is set,
+// or else until the chain's block-timeout.
+// In BroadcastSync mode, a transaction failing CheckTx is returned with its non-zero code;
+// in BroadcastAsync mode, only the hash of the transaction is returned.
+func (cc *ChainClient) BroadcastTxWithMode(ctx context.Context, tx []byte, mode string, blockTimeout time.Duration) (res *sdk.TxResponse, err error) {
+	defer func() { cc.metrics.observeBroadcast(cc.Config.ChainID, res, err) }()
+
+	switch mode {
+	case BroadcastAsync:
+		res, err := cc.RPCClient.BroadcastTxAsync(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.TxResponse{TxHash: res.Hash.String()}, nil
+	case BroadcastSync:
+		res, err := cc.RPCClient.BroadcastTxSync(ctx, tx)
 		if err != nil {
-			// Did you call Validate() method on ChainClientConfig struct
-			// before coming here?
+			return nil, err
+		}
+		return &sdk.TxResponse{Code: res.Code, Codespace: res.Codespace, TxHash: res.Hash.String(), RawLog: res.Log}, nil
+	case BroadcastBlock, "":
+	default:
+		return nil, fmt.Errorf("unknown broadcast mode %q (must be %s, %s, or %s)", mode, BroadcastBlock, BroadcastSync, BroadcastAsync)
+	}
+
+	if blockTimeout == 0 {
+		var err error
+		if blockTimeout, err = cc.blockTimeout(); err != nil {
 			return nil, err
 		}
 	}
@@ -36,7 +61,7 @@ func (cc *ChainClient) BroadcastTx(ctx context.Context, tx []byte) (*sdk.TxRespo
 	return broadcastTx(
 		ctx,
 		cc.RPCClient,
-		cc.Codec.TxConfig.TxDecoder(),
+		cc.txDecoder(),
 		tx,
 		blockTimeout,
 	)
@@ -53,7 +78,7 @@ type rpcTxBroadcaster interface {
 
 // broadcastTx broadcasts a TX and then waits for the TX to be included in the block.
 // The waiting will either be canceled after the waitTimeout has run out or the context
-// exited.
+// exited, returning an UnconfirmedTxError.
 func broadcastTx(
 	ctx context.Context,
 	broadcaster rpcTxBroadcaster,
@@ -84,6 +109,10 @@ func broadcastTx(
 	// This catches all of the sdk errors https://github.com/cosmos/cosmos-sdk/blob/f10f5e5974d2ecbf9efc05bc0bfe1c99fdeed4b6/types/errors/errors.go
 	err = errors.Unwrap(sdkerrors.ABCIError(syncRes.Codespace, syncRes.Code, "error broadcasting transaction"))
 	if err.Error() != errUnknown {
+		// The log details the error, such as the sequence expected instead of a wrong one.
+		if log := strings.TrimSuffix(syncRes.Log, ": "+err.Error()); log != "" && log != err.Error() {
+			return nil, fmt.Errorf("%w: %s", err, log)
+		}
 		return nil, err
 	}
 	if syncRes.Code != 0 {
@@ -94,22 +123,13 @@ func broadcastTx(
 	// if not, we need to find a new way to block until inclusion in a block
 
 	// wait for tx to be included in a block
-	exitAfter := time.After(waitTimeout)
-	for {
-		select {
-		case <-exitAfter:
-			return nil, fmt.Errorf("timed out after: %d; %w", waitTimeout, ErrTimeoutAfterWaitingForTxBroadcast)
-		// TODO: this is potentially less than optimal and may
-		// be better as something configurable
-		case <-time.After(time.Millisecond * 100):
-			resTx, err := broadcaster.Tx(ctx, syncRes.Hash, false)
-			if err == nil {
-				return mkTxResult(txDecoder, resTx)
-			}
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		}
+	// TODO: this is potentially less than optimal and may
+	// be better as something configurable
+	resTx, err := waitForTx(ctx, broadcaster, syncRes.Hash, waitTimeout, time.Millisecond*100, nil)
+	if err != nil {
+		return nil, UnconfirmedTxError{TxHash: syncRes.Hash.String(), Err: err}
 	}
+	return mkTxResult(txDecoder, resTx)
 }
 
 func mkTxResult(txDecoder sdk.TxDecoder, resTx *ctypes.ResultTx) (*sdk.TxResponse, error) {
@@ -129,6 +149,44 @@ func mkTxResult(txDecoder sdk.TxDecoder, resTx *ctypes.ResultTx) (*sdk.TxRespons
 	return sdk.NewResponseResultTx(resTx, any, time.Now().Format(time.RFC3339)), nil
 }
 
+// txDecoder returns the binary tx decoder of the codec of cc, falling back to unmarshaling the transaction with the codec
+// when the binary decoder rejects it, as it does transactions holding the dynamic messages of byop.
+func (cc *ChainClient) txDecoder() sdk.TxDecoder {
+	decode := cc.Codec.TxConfig.TxDecoder()
+	return func(bz []byte) (sdk.Tx, error) {
+		tx, err := decode(bz)
+		if err == nil {
+			return tx, nil
+		}
+		var raw txtypes.TxRaw
+		if rawErr := raw.Unmarshal(bz); rawErr != nil {
+			return nil, err
+		}
+		var body txtypes.TxBody
+		if bodyErr := cc.Codec.Marshaler.Unmarshal(raw.BodyBytes, &body); bodyErr != nil {
+			return nil, err
+		}
+		var authInfo txtypes.AuthInfo
+		if authErr := cc.Codec.Marshaler.Unmarshal(raw.AuthInfoBytes, &authInfo); authErr != nil {
+			return nil, err
+		}
+		return codecTx{Tx: &txtypes.Tx{Body: &body, AuthInfo: &authInfo, Signatures: raw.Signatures}}, nil
+	}
+}
+
+// codecTx is a transaction decoded with the codec rather than with the binary tx decoder.
+type codecTx struct {
+	*txtypes.Tx
+}
+
+func (tx codecTx) AsAny() *codectypes.Any {
+	any, err := codectypes.NewAnyWithValue(tx.Tx)
+	if err != nil {
+		return codectypes.UnsafePackAny(tx.Tx)
+	}
+	return any
+}
+
 // Deprecated: this interface is used only internally for scenario we are
 // deprecating (StdTxConfig support)
 type intoAny interface {
@@ -153,3 +153,28 @@ func TestBroadcast(t *testing.T) {
 	}
 
 }
+
+func TestBroadcast_ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := broadcastTx(ctx, fakeBroadcaster{
+		broadcastSync: func(_ context.Context, _ tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+			return &ctypes.ResultBroadcastTx{Hash: []byte(`123bob`)}, nil
+		},
+		tx: func(_ context.Context, _ []byte, _ bool) (*ctypes.ResultTx, error) {
+			return nil, errExpected
+		},
+	}, nil, nil, time.Minute)
+
+	// The error states what was interrupted.
+	var interrupted InterruptedError
+	assert.ErrorAs(t, err, &interrupted)
+	assert.Equal(t, "waiting for tx inclusion", interrupted.Op)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The transaction may still be included.
+	var unconfirmed UnconfirmedTxError
+	assert.ErrorAs(t, err, &unconfirmed)
+	assert.Equal(t, "313233626F62", unconfirmed.TxHash)
+}
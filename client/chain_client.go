@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"path"
 	"time"
 
@@ -44,18 +45,71 @@ type ChainClient struct {
 	// TODO: GRPC Client type?
 
 	Codec Codec
+
+	// sequences caches the account numbers and sequences of the accounts sending transactions.
+	sequences *sequenceManager
+
+	// memoLimit caches the maximum length of the memos of the chain's transactions.
+	memoLimit *memoLimit
+
+	// metrics records the operations of the client, if set by WithMetrics.
+	metrics *Metrics
+
+	// rateLimiter paces the requests to the RPC endpoints, as configured by Config.RateLimit.
+	rateLimiter *RateLimiter
+
+	// retrier retries the requests to the RPC endpoints failing transiently, as configured by Config.Retries.
+	retrier *Retrier
+
+	// contacts are the addresses of the contacts referenced by @name where an address is decoded, set by WithContacts.
+	contacts map[string]string
+
+	// tracer logs the gRPC queries of the client, if set by WithTracer.
+	tracer *Tracer
+}
+
+// ChainClientOption configures a ChainClient created by NewChainClientWithOptions.
+type ChainClientOption func(*ChainClient)
+
+// WithKeyringOptions adds kro to the options of the client's keyring.
+func WithKeyringOptions(kro ...keyring.Option) ChainClientOption {
+	return func(cc *ChainClient) {
+		cc.KeyringOptions = append(cc.KeyringOptions, kro...)
+	}
+}
+
+// WithMetrics makes the client record its operations in m.
+// Without it, no metrics are recorded.
+func WithMetrics(m *Metrics) ChainClientOption {
+	return func(cc *ChainClient) {
+		cc.metrics = m
+	}
 }
 
 func NewChainClient(log *zap.Logger, ccc *ChainClientConfig, homepath string, input io.Reader, output io.Writer, kro ...keyring.Option) (*ChainClient, error) {
+	return NewChainClientWithOptions(log, ccc, homepath, input, output, WithKeyringOptions(kro...))
+}
+
+// NewChainClientWithOptions is NewChainClient, configured by opts.
+func NewChainClientWithOptions(log *zap.Logger, ccc *ChainClientConfig, homepath string, input io.Reader, output io.Writer, opts ...ChainClientOption) (*ChainClient, error) {
 	ccc.KeyDirectory = keysDir(homepath, ccc.ChainID)
+	modules, err := ccc.codecModules(homepath)
+	if err != nil {
+		return nil, err
+	}
 	cc := &ChainClient{
 		log: log,
 
-		KeyringOptions: append([]keyring.Option{ethermint.EthSecp256k1Option()}, kro...),
+		KeyringOptions: []keyring.Option{ethermint.EthSecp256k1Option()},
 		Config:         ccc,
 		Input:          input,
 		Output:         output,
-		Codec:          MakeCodec(ccc.Modules, ccc.ExtraCodecs),
+		Codec:          MakeCodec(modules, ccc.ExtraCodecs),
+		sequences:      newSequenceManager(),
+		memoLimit:      &memoLimit{},
+	}
+	for _, opt := range opts {
+		opt(cc)
 	}
 	if err := cc.Init(); err != nil {
 		return nil, err
@@ -72,7 +126,25 @@ func (cc *ChainClient) Init() error {
 	// TODO: figure out how to deal with input or maybe just make all keyring backends test?
 
 	timeout, _ := time.ParseDuration(cc.Config.Timeout)
-	rpcClient, err := NewRPCClient(cc.Config.RPCAddr, timeout)
+	limit, err := cc.Config.RateLimiting()
+	if err != nil {
+		return err
+	}
+	cc.rateLimiter = NewRateLimiter(cc.log, limit)
+	retry, err := cc.Config.Retrying()
+	if err != nil {
+		return err
+	}
+	cc.retrier = NewRetrier(cc.log, retry)
+	// The metrics record every request sent, including those the rate limiter and the retrier retry,
+	// and each retry is paced by the rate limiter.
+	wrap := func(endpoint string, rt http.RoundTripper) http.RoundTripper {
+		if cc.metrics != nil {
+			rt = cc.metrics.rpcTransport(cc.Config.ChainID, endpoint, rt)
+		}
+		return cc.retrier.RPCTransport(endpoint, cc.rateLimiter.RPCTransport(endpoint, rt))
+	}
+	rpcClient, err := newFailoverRPCClient(cc.Config.RPCEndpoints(), timeout, cc.Config.RPCProxySetting(), wrap)
 	if err != nil {
 		return err
 	}
@@ -101,11 +173,28 @@ func (cc *ChainClient) GetKeyAddress() (sdk.AccAddress, error) {
 }
 
 func NewRPCClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
+	return newRPCClient(addr, timeout, "", nil)
+}
+
+// NewProxiedRPCClient is NewRPCClient, connecting to addr through the proxy given by the proxy setting,
+// as described by ChainClientConfig.Proxy.
+func NewProxiedRPCClient(addr string, timeout time.Duration, proxy string) (*rpchttp.HTTP, error) {
+	return newRPCClient(addr, timeout, proxy, nil)
+}
+
+// newRPCClient is NewProxiedRPCClient, sending the requests through the transport returned by wrap, if it is not nil.
+func newRPCClient(addr string, timeout time.Duration, proxy string, wrap endpointTransport) (*rpchttp.HTTP, error) {
 	httpClient, err := libclient.DefaultHTTPClient(addr)
 	if err != nil {
 		return nil, err
 	}
 	httpClient.Timeout = timeout
+	if err := proxyRPCTransport(httpClient.Transport.(*http.Transport), addr, proxy); err != nil {
+		return nil, err
+	}
+	if wrap != nil {
+		httpClient.Transport = wrap(addr, httpClient.Transport)
+	}
 	rpcClient, err := rpchttp.NewWithClient(addr, "/websocket", httpClient)
 	if err != nil {
 		return nil, err
@@ -140,7 +229,7 @@ func (cc *ChainClient) PrintTxResponse(res *sdk.TxResponse) error {
 func (cc *ChainClient) HandleAndPrintMsgSend(res *sdk.TxResponse, err error) error {
 	if err != nil {
 		if res != nil {
-			return fmt.Errorf("failed to withdraw rewards: code(%d) msg(%s)", res.Code, res.Logs)
+			return fmt.Errorf("failed to withdraw rewards: code(%d) msg(%s): %w", res.Code, res.Logs, err)
 		}
 		return fmt.Errorf("failed to withdraw rewards: err(%w)", err)
 	}
@@ -162,7 +251,7 @@ func (cc *ChainClient) PrintObject(res interface{}) error {
 		if err != nil {
 			return err
 		}
-	case "indent":
+	case "indent", "json-indent":
 		if m, ok := res.(proto.Message); ok {
 			bz, err = cc.MarshalProto(m)
 			if err != nil {
@@ -179,7 +268,8 @@ func (cc *ChainClient) PrintObject(res interface{}) error {
 				return err
 			}
 		}
-	case "yaml":
+	case "yaml", "text":
+		// There is no general text representation, so use the most readable one.
 		bz, err = yaml.Marshal(res)
 		if err != nil {
 			return err
@@ -2,24 +2,35 @@ package chain_registry
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type ChainInfo struct {
 	log *zap.Logger
 
+	// httpClient and registryURL are used to fetch the chain's asset list.
+	// If unset, http.DefaultClient and DefaultRegistryURL are used.
+	httpClient  *http.Client
+	registryURL string
+
 	Schema       string `json:"$schema"`
 	ChainName    string `json:"chain_name"`
 	Status       string `json:"status"`
@@ -32,7 +43,16 @@ type ChainInfo struct {
 	Genesis      struct {
 		GenesisURL string `json:"genesis_url"`
 	} `json:"genesis"`
-	Slip44   int `json:"slip44"`
+	Slip44 int `json:"slip44"`
+	Fees   struct {
+		FeeTokens []struct {
+			Denom            string  `json:"denom"`
+			FixedMinGasPrice float64 `json:"fixed_min_gas_price"`
+			LowGasPrice      float64 `json:"low_gas_price"`
+			AverageGasPrice  float64 `json:"average_gas_price"`
+			HighGasPrice     float64 `json:"high_gas_price"`
+		} `json:"fee_tokens"`
+	} `json:"fees"`
 	Codebase struct {
 		GitRepo            string   `json:"git_repo"`
 		RecommendedVersion string   `json:"recommended_version"`
@@ -58,6 +78,10 @@ type ChainInfo struct {
 			Address  string `json:"address"`
 			Provider string `json:"provider"`
 		} `json:"rest"`
+		GRPC []struct {
+			Address  string `json:"address"`
+			Provider string `json:"provider"`
+		} `json:"grpc"`
 	} `json:"apis"`
 }
 
@@ -111,22 +135,38 @@ func IsHealthyRPC(ctx context.Context, endpoint string) error {
 	return nil
 }
 
+// GetRPCEndpoints returns the RPC endpoints of the chain that are healthy,
+// in the order they are listed in the registry.
 func (c ChainInfo) GetRPCEndpoints(ctx context.Context) (out []string, err error) {
 	allRPCEndpoints, err := c.GetAllRPCEndpoints()
 	if err != nil {
 		return nil, err
 	}
 
+	healthy := c.checkEndpoints(ctx, allRPCEndpoints, IsHealthyRPC)
+	for i, endpoint := range allRPCEndpoints {
+		if healthy[i] {
+			out = append(out, endpoint)
+		}
+	}
+	c.log.Info("Endpoints queried",
+		zap.String("chain_name", c.ChainName),
+		zap.Int("healthy", len(out)),
+		zap.Int("unhealthy", len(allRPCEndpoints)-len(out)),
+	)
+	return out, nil
+}
+
+// checkEndpoints concurrently checks every endpoint with isHealthy,
+// and reports whether each one is healthy.
+func (c ChainInfo) checkEndpoints(ctx context.Context, endpoints []string, isHealthy func(context.Context, string) error) []bool {
+	healthy := make([]bool, len(endpoints))
+
 	var eg errgroup.Group
-	var endpoints []string
-	healthy := 0
-	unhealthy := 0
-	for _, endpoint := range allRPCEndpoints {
-		endpoint := endpoint
+	for i, endpoint := range endpoints {
+		i, endpoint := i, endpoint
 		eg.Go(func() error {
-			err := IsHealthyRPC(ctx, endpoint)
-			if err != nil {
-				unhealthy += 1
+			if err := isHealthy(ctx, endpoint); err != nil {
 				c.log.Debug(
 					"Ignoring endpoint due to error",
 					zap.String("endpoint", endpoint),
@@ -134,21 +174,82 @@ func (c ChainInfo) GetRPCEndpoints(ctx context.Context) (out []string, err error
 				)
 				return nil
 			}
-			healthy += 1
 			c.log.Debug("Verified healthy endpoint", zap.String("endpoint", endpoint))
-			endpoints = append(endpoints, endpoint)
+			healthy[i] = true
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
+	_ = eg.Wait() // Never returns an error.
+
+	return healthy
+}
+
+// GetAllGRPCEndpoints returns the gRPC endpoints of the chain listed in the registry, as host:port.
+// Addresses without a port are assumed to use 443 if they have an https scheme, or 9090 otherwise.
+func (c ChainInfo) GetAllGRPCEndpoints() (out []string, err error) {
+	for _, endpoint := range c.Apis.GRPC {
+		addr := endpoint.Address
+		defaultPort := "9090"
+		if i := strings.Index(addr, "://"); i >= 0 {
+			if addr[:i] == "https" {
+				defaultPort = "443"
+			}
+			addr = strings.TrimSuffix(addr[i+len("://"):], "/")
+		}
+
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, defaultPort)
+		}
+
+		out = append(out, addr)
+	}
+
+	return
+}
+
+// IsGRPCTLS reports whether the gRPC endpoint at addr is assumed to require TLS,
+// which is the case when it uses port 443.
+func IsGRPCTLS(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	return err == nil && port == "443"
+}
+
+// IsHealthyGRPC reports an error if the gRPC endpoint at addr
+// does not list its services through reflection within a few seconds.
+func IsHealthyGRPC(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	creds := insecure.NewCredentials()
+	if IsGRPCTLS(addr) {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpcdynamic.Dial(ctx, addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = grpcdynamic.NewReflectionClient(conn).ListServices(ctx)
+	return err
+}
+
+// GetGRPCEndpoints returns the gRPC endpoints of the chain that are healthy,
+// in the order they are listed in the registry.
+func (c ChainInfo) GetGRPCEndpoints(ctx context.Context) (out []string, err error) {
+	allGRPCEndpoints, err := c.GetAllGRPCEndpoints()
+	if err != nil {
 		return nil, err
 	}
-	c.log.Info("Endpoints queried",
-		zap.String("chain_name", c.ChainName),
-		zap.Int("healthy", healthy),
-		zap.Int("unhealthy", unhealthy),
-	)
-	return endpoints, nil
+
+	healthy := c.checkEndpoints(ctx, allGRPCEndpoints, IsHealthyGRPC)
+	for i, endpoint := range allGRPCEndpoints {
+		if healthy[i] {
+			out = append(out, endpoint)
+		}
+	}
+	return out, nil
 }
 
 func (c ChainInfo) GetRandomRPCEndpoint(ctx context.Context) (string, error) {
@@ -171,56 +272,100 @@ func (c ChainInfo) GetRandomRPCEndpoint(ctx context.Context) (string, error) {
 }
 
 func (c ChainInfo) GetAssetList(ctx context.Context) (AssetList, error) {
-	chainRegURL := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/%s/assetlist.json", c.ChainName)
+	httpClient, registryURL := c.httpClient, c.registryURL
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if registryURL == "" {
+		registryURL = DefaultRegistryURL
+	}
 
-	res, err := http.Get(chainRegURL)
-	if err != nil {
+	var assetList AssetList
+	if err := getJSON(ctx, httpClient, fmt.Sprintf("%s/%s/assetlist.json", registryURL, c.ChainName), &assetList); err != nil {
 		return AssetList{}, err
 	}
-	defer res.Body.Close()
-	if res.StatusCode == http.StatusNotFound {
-		return AssetList{}, fmt.Errorf("chain not found on registry: response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
-	}
-	if res.StatusCode != http.StatusOK {
-		return AssetList{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+	return assetList, nil
+}
+
+// GetGasPrices returns the gas prices to configure for the chain:
+// the average gas price of its first fee token,
+// or, if the registry lists no fee tokens, 0.01 of the base denom of its first asset.
+func (c ChainInfo) GetGasPrices(ctx context.Context) (string, error) {
+	if len(c.Fees.FeeTokens) > 0 {
+		fee := c.Fees.FeeTokens[0]
+		return strconv.FormatFloat(fee.AverageGasPrice, 'f', -1, 64) + fee.Denom, nil
 	}
 
-	body, err := ioutil.ReadAll(res.Body)
+	assetList, err := c.GetAssetList(ctx)
 	if err != nil {
-		return AssetList{}, err
+		return "", err
 	}
 
-	var assetList AssetList
-	if err := json.Unmarshal([]byte(body), &assetList); err != nil {
-		return AssetList{}, err
+	if len(assetList.Assets) > 0 {
+		return fmt.Sprintf("%.2f%s", 0.01, assetList.Assets[0].Base), nil
 	}
-	return assetList, nil
+	return "", nil
+}
 
+// selectEndpoint returns the endpoint at index of all,
+// or, if index is negative, the first endpoint returned by healthy.
+func selectEndpoint(ctx context.Context, kind string, all []string, index int, healthy func(context.Context) ([]string, error)) (string, error) {
+	if index >= 0 {
+		if index >= len(all) {
+			return "", fmt.Errorf("endpoint index %d out of range: registry lists %d %s endpoints", index, len(all), kind)
+		}
+		return all[index], nil
+	}
+
+	endpoints, err := healthy(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("no working %s endpoints found", kind)
+	}
+	return endpoints[0], nil
 }
 
-func (c ChainInfo) GetChainConfig(ctx context.Context) (*client.ChainClientConfig, error) {
+// GetChainConfig returns a configuration for the chain.
+//
+// If endpointIndex is negative, the RPC and gRPC addresses are the first healthy endpoints listed in the registry.
+// Otherwise, they are the endpoints at endpointIndex in the registry, whether healthy or not.
+// A chain without any usable gRPC endpoint is configured without a gRPC address.
+func (c ChainInfo) GetChainConfig(ctx context.Context, endpointIndex int) (*client.ChainClientConfig, error) {
 	debug := viper.GetBool("debug")
 	home := viper.GetString("home")
 
-	assetList, err := c.GetAssetList(ctx)
+	gasPrices, err := c.GetGasPrices(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var gasPrices string
-	if len(assetList.Assets) > 0 {
-		gasPrices = fmt.Sprintf("%.2f%s", 0.01, assetList.Assets[0].Base)
+	allRPCEndpoints, err := c.GetAllRPCEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	rpc, err := selectEndpoint(ctx, "RPC", allRPCEndpoints, endpointIndex, c.GetRPCEndpoints)
+	if err != nil {
+		return nil, err
 	}
 
-	rpc, err := c.GetRandomRPCEndpoint(ctx)
+	allGRPCEndpoints, err := c.GetAllGRPCEndpoints()
 	if err != nil {
 		return nil, err
 	}
+	gRPC, err := selectEndpoint(ctx, "gRPC", allGRPCEndpoints, endpointIndex, c.GetGRPCEndpoints)
+	if err != nil {
+		c.log.Info("Leaving gRPC address unset", zap.String("chain_name", c.ChainName), zap.Error(err))
+		gRPC = ""
+	}
 
 	return &client.ChainClientConfig{
 		Key:            "default",
 		ChainID:        c.ChainID,
 		RPCAddr:        rpc,
+		GRPCAddr:       gRPC,
+		GRPCTLS:        gRPC != "" && IsGRPCTLS(gRPC),
 		AccountPrefix:  c.Bech32Prefix,
 		KeyringBackend: "test",
 		GasAdjustment:  1.2,
@@ -230,6 +375,6 @@ func (c ChainInfo) GetChainConfig(ctx context.Context) (*client.ChainClientConfi
 		Timeout:        "20s",
 		OutputFormat:   "json",
 		SignModeStr:    "direct",
-		Slip44:			c.Slip44,
+		Slip44:         c.Slip44,
 	}, nil
 }
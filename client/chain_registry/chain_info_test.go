@@ -65,6 +65,53 @@ func TestGetAllRPCEndpoints(t *testing.T) {
 	}
 }
 
+func TestGetAllGRPCEndpoints(t *testing.T) {
+	testCases := map[string]struct {
+		address          string
+		expectedEndpoint string
+		expectedTLS      bool
+	}{
+		"host and port": {
+			address:          "grpc.test.com:9090",
+			expectedEndpoint: "grpc.test.com:9090",
+		},
+		"host without port": {
+			address:          "grpc.test.com",
+			expectedEndpoint: "grpc.test.com:9090",
+		},
+		"host and TLS port": {
+			address:          "grpc.test.com:443",
+			expectedEndpoint: "grpc.test.com:443",
+			expectedTLS:      true,
+		},
+		"https scheme without port": {
+			address:          "https://grpc.test.com/",
+			expectedEndpoint: "grpc.test.com:443",
+			expectedTLS:      true,
+		},
+		"http scheme with port": {
+			address:          "http://grpc.test.com:9091",
+			expectedEndpoint: "grpc.test.com:9091",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var c ChainInfo
+			c.Apis.GRPC = append(c.Apis.GRPC, struct {
+				Address  string `json:"address"`
+				Provider string `json:"provider"`
+			}{Address: tc.address})
+
+			endpoints, err := c.GetAllGRPCEndpoints()
+			require.NoError(t, err)
+			require.Equal(t, []string{tc.expectedEndpoint}, endpoints)
+			require.Equal(t, tc.expectedTLS, IsGRPCTLS(endpoints[0]))
+		})
+	}
+}
+
 func ChainInfoWithRPCEndpoint(endpoint string) ChainInfo {
 	return ChainInfo{
 		Apis: struct {
@@ -76,6 +123,10 @@ func ChainInfoWithRPCEndpoint(endpoint string) ChainInfo {
 				Address  string `json:"address"`
 				Provider string `json:"provider"`
 			} `json:"rest"`
+			GRPC []struct {
+				Address  string `json:"address"`
+				Provider string `json:"provider"`
+			} `json:"grpc"`
 		}{
 			RPC: []struct {
 				Address  string `json:"address"`
@@ -12,6 +12,6 @@ type ChainRegistry interface {
 	SourceLink() string
 }
 
-func DefaultChainRegistry(log *zap.Logger) ChainRegistry {
-	return NewCosmosGithubRegistry(log.With(zap.String("registry", "cosmos_github")))
+func DefaultChainRegistry(log *zap.Logger, opts ...CosmosGithubRegistryOption) ChainRegistry {
+	return NewCosmosGithubRegistry(log.With(zap.String("registry", "cosmos_github")), opts...)
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -13,16 +13,50 @@ import (
 	"go.uber.org/zap"
 )
 
+// DefaultRegistryURL is the base URL of the raw files in the cosmos/chain-registry GitHub repository.
+const DefaultRegistryURL = "https://raw.githubusercontent.com/cosmos/chain-registry/master"
+
 type CosmosGithubRegistry struct {
 	log *zap.Logger
+
+	httpClient *http.Client
+	baseURL    string
+}
+
+// CosmosGithubRegistryOption configures a CosmosGithubRegistry.
+type CosmosGithubRegistryOption func(*CosmosGithubRegistry)
+
+// WithHTTPClient makes the registry send its requests through c instead of http.DefaultClient.
+func WithHTTPClient(c *http.Client) CosmosGithubRegistryOption {
+	return func(r *CosmosGithubRegistry) {
+		r.httpClient = c
+	}
+}
+
+// WithBaseURL makes the registry fetch chain and asset files from under baseURL
+// instead of DefaultRegistryURL.
+// Listing chains always uses the GitHub API.
+func WithBaseURL(baseURL string) CosmosGithubRegistryOption {
+	return func(r *CosmosGithubRegistry) {
+		r.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
 }
 
-func NewCosmosGithubRegistry(log *zap.Logger) CosmosGithubRegistry {
-	return CosmosGithubRegistry{log: log}
+func NewCosmosGithubRegistry(log *zap.Logger, opts ...CosmosGithubRegistryOption) CosmosGithubRegistry {
+	r := CosmosGithubRegistry{
+		log: log,
+
+		httpClient: http.DefaultClient,
+		baseURL:    DefaultRegistryURL,
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
 }
 
 func (c CosmosGithubRegistry) ListChains(ctx context.Context) ([]string, error) {
-	client := github.NewClient(http.DefaultClient)
+	client := github.NewClient(c.httpClient)
 	var chains []string
 
 	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
@@ -47,29 +81,41 @@ func (c CosmosGithubRegistry) ListChains(ctx context.Context) ([]string, error)
 }
 
 func (c CosmosGithubRegistry) GetChain(ctx context.Context, name string) (ChainInfo, error) {
-	chainRegURL := fmt.Sprintf("https://raw.githubusercontent.com/cosmos/chain-registry/master/%s/chain.json", name)
+	chainRegURL := fmt.Sprintf("%s/%s/chain.json", c.baseURL, name)
 
-	res, err := http.Get(chainRegURL)
-	if err != nil {
+	result := NewChainInfo(c.log.With(zap.String("chain_name", name)))
+	result.httpClient = c.httpClient
+	result.registryURL = c.baseURL
+	if err := getJSON(ctx, c.httpClient, chainRegURL, &result); err != nil {
 		return ChainInfo{}, err
 	}
+	return result, nil
+}
+
+// getJSON decodes the JSON document at url into v.
+func getJSON(ctx context.Context, httpClient *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
 	defer res.Body.Close()
 	if res.StatusCode == http.StatusNotFound {
-		return ChainInfo{}, fmt.Errorf("chain not found on registry: response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+		return fmt.Errorf("chain not found on registry: response code: %d: GET failed: %s", res.StatusCode, url)
 	}
 	if res.StatusCode != http.StatusOK {
-		return ChainInfo{}, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, chainRegURL)
+		return fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, url)
 	}
 
-	result := NewChainInfo(c.log.With(zap.String("chain_name", name)))
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return ChainInfo{}, err
+		return err
 	}
-	if err := json.Unmarshal([]byte(body), &result); err != nil {
-		return ChainInfo{}, err
-	}
-	return result, nil
+	return json.Unmarshal(body, v)
 }
 
 func (c CosmosGithubRegistry) SourceLink() string {
@@ -1,10 +1,22 @@
 package client
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/vesting"
 	authz "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/capability"
@@ -13,6 +25,7 @@ import (
 	feegrant "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 	"github.com/cosmos/cosmos-sdk/x/gov"
 	"github.com/cosmos/cosmos-sdk/x/gov/client"
+	group "github.com/cosmos/cosmos-sdk/x/group/module"
 	"github.com/cosmos/cosmos-sdk/x/mint"
 	"github.com/cosmos/cosmos-sdk/x/params"
 	paramsclient "github.com/cosmos/cosmos-sdk/x/params/client"
@@ -20,6 +33,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/upgrade"
 	upgradeclient "github.com/cosmos/cosmos-sdk/x/upgrade/client"
+	ica "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts"
 	"github.com/cosmos/ibc-go/v7/modules/apps/transfer"
 	ibc "github.com/cosmos/ibc-go/v7/modules/core"
 )
@@ -27,6 +41,7 @@ import (
 var (
 	ModuleBasics = []module.AppModuleBasic{
 		auth.AppModuleBasic{},
+		vesting.AppModuleBasic{},
 		authz.AppModuleBasic{},
 		bank.AppModuleBasic{},
 		capability.AppModuleBasic{},
@@ -42,12 +57,14 @@ var (
 		crisis.AppModuleBasic{},
 		distribution.AppModuleBasic{},
 		feegrant.AppModuleBasic{},
+		group.AppModuleBasic{},
 		mint.AppModuleBasic{},
 		params.AppModuleBasic{},
 		slashing.AppModuleBasic{},
 		staking.AppModuleBasic{},
 		upgrade.AppModuleBasic{},
 		transfer.AppModuleBasic{},
+		ica.AppModuleBasic{},
 		ibc.AppModuleBasic{},
 	}
 )
@@ -56,7 +73,11 @@ type ChainClientConfig struct {
 	Key            string                  `json:"key" yaml:"key"`
 	ChainID        string                  `json:"chain-id" yaml:"chain-id"`
 	RPCAddr        string                  `json:"rpc-addr" yaml:"rpc-addr"`
+	RPCAddrs       []string                `json:"rpc-addrs,omitempty" yaml:"rpc-addrs,omitempty"`
 	GRPCAddr       string                  `json:"grpc-addr" yaml:"grpc-addr"`
+	GRPCAddrs      []string                `json:"grpc-addrs,omitempty" yaml:"grpc-addrs,omitempty"`
+	GRPCTLS        bool                    `json:"grpc-tls" yaml:"grpc-tls"`
+	GRPCTLSCAFile  string                  `json:"grpc-tls-ca-file" yaml:"grpc-tls-ca-file"`
 	AccountPrefix  string                  `json:"account-prefix" yaml:"account-prefix"`
 	KeyringBackend string                  `json:"keyring-backend" yaml:"keyring-backend"`
 	GasAdjustment  float64                 `json:"gas-adjustment" yaml:"gas-adjustment"`
@@ -71,20 +92,213 @@ type ChainClientConfig struct {
 	ExtraCodecs    []string                `json:"extra-codecs" yaml:"extra-codecs"`
 	Modules        []module.AppModuleBasic `json:"-" yaml:"-"`
 	Slip44         int                     `json:"slip44" yaml:"slip44"`
+	// AutoGasPrices discovers the gas prices of transactions from the chain, as DiscoverGasPrices does,
+	// with GasPrices as the fallback.
+	AutoGasPrices bool `json:"auto-gas-prices,omitempty" yaml:"auto-gas-prices,omitempty"`
+	// FeeGranter is the address of the account paying the fees of transactions, through a fee allowance, by default.
+	FeeGranter string `json:"fee-granter,omitempty" yaml:"fee-granter,omitempty"`
+	// GRPCHeaders are sent as metadata with every gRPC call and query of the chain, such as API keys.
+	// A value of env:VARNAME is read from the environment variable VARNAME.
+	GRPCHeaders map[string]string `json:"grpc-headers,omitempty" yaml:"grpc-headers,omitempty"`
+	// Proxy is the proxy that the connections to the endpoints of the chain go through:
+	// the URL of a SOCKS5 or HTTP proxy, such as socks5://127.0.0.1:9050, or "direct" for none.
+	// If empty, the proxy set in the environment by HTTPS_PROXY, HTTP_PROXY, or ALL_PROXY is used.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// RPCProxy overrides Proxy for the connections to the RPC endpoints, such as "direct" to only proxy gRPC.
+	RPCProxy string `json:"rpc-proxy,omitempty" yaml:"rpc-proxy,omitempty"`
+	// GRPCMaxRecvMsgSize is the largest message received from the gRPC endpoints, in bytes,
+	// or DefaultGRPCMaxRecvMsgSize if zero.
+	GRPCMaxRecvMsgSize int `json:"grpc-max-recv-msg-size,omitempty" yaml:"grpc-max-recv-msg-size,omitempty"`
+	// GRPCKeepaliveTime is how long a gRPC connection may be idle before the endpoint is pinged, such as 30s,
+	// so that load balancers do not drop it; if empty, the endpoint is never pinged.
+	GRPCKeepaliveTime string `json:"grpc-keepalive-time,omitempty" yaml:"grpc-keepalive-time,omitempty"`
+	// GRPCKeepaliveTimeout is how long to wait for the response to a keepalive ping before closing the connection.
+	GRPCKeepaliveTimeout string `json:"grpc-keepalive-timeout,omitempty" yaml:"grpc-keepalive-timeout,omitempty"`
+	// GRPCKeepalivePermitWithoutStream sends keepalive pings even when no gRPC call is in progress.
+	GRPCKeepalivePermitWithoutStream bool `json:"grpc-keepalive-permit-without-stream,omitempty" yaml:"grpc-keepalive-permit-without-stream,omitempty"`
+	// ExtraMsgDescriptors are the paths of protobuf descriptor sets, as written by buf build or protoc --descriptor_set_out,
+	// whose transaction messages are decoded without being compiled into lens, as registered by byop.NewModuleFromDescriptors.
+	// Relative paths are relative to the home directory of the chain client.
+	ExtraMsgDescriptors []string `json:"extra-msg-descriptors,omitempty" yaml:"extra-msg-descriptors,omitempty"`
+	// ReflectMsgs decodes the transaction messages of the chain that are not compiled into lens from the descriptors
+	// served by its gRPC endpoint, as registered by byop.FromReflection. The lens CLI caches the descriptors in its home directory.
+	ReflectMsgs bool `json:"reflect-msgs,omitempty" yaml:"reflect-msgs,omitempty"`
+	// RateLimit paces the requests sent to each RPC and gRPC endpoint of the chain, given as RATE or RATE:BURST
+	// requests per second, such as 5 or 10:20, as parsed by ParseRateLimit; if empty, requests are not paced.
+	RateLimit string `json:"rate-limit,omitempty" yaml:"rate-limit,omitempty"`
+	// RateLimitRetries is how many times a request throttled by an endpoint, with HTTP 429 or gRPC RESOURCE_EXHAUSTED,
+	// is retried after backing off, or DefaultRateLimitRetries if zero; if negative, throttled requests are not retried.
+	RateLimitRetries int `json:"rate-limit-retries,omitempty" yaml:"rate-limit-retries,omitempty"`
+	// Retries is how many times a request reading the chain that fails transiently, such as with a reset connection
+	// or HTTP 502, is retried after backing off, or DefaultRetries if zero; if negative, such requests are not retried.
+	// Broadcasting a transaction is never retried.
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+	// RetryBackoff is how long to back off before the first retry of a request failing transiently, such as 500ms,
+	// doubled for each further retry, or DefaultRetryBackoff if empty.
+	RetryBackoff string `json:"retry-backoff,omitempty" yaml:"retry-backoff,omitempty"`
 }
 
+// ConfigFieldError describes a ChainClientConfig field holding an invalid value.
+type ConfigFieldError struct {
+	// Field is the configuration key of the field, such as "rpc-addr".
+	Field string
+	Value string
+	Err   error
+}
+
+func (e ConfigFieldError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %v", e.Field, e.Value, e.Err)
+}
+
+func (e ConfigFieldError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors lists every invalid field of a ChainClientConfig, in field order.
+type ConfigErrors []ConfigFieldError
+
+func (es ConfigErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Field returns the error for the field with the given configuration key,
+// or nil if that field is valid.
+func (es ConfigErrors) Field(field string) error {
+	for _, e := range es {
+		if e.Field == field {
+			return e
+		}
+	}
+	return nil
+}
+
+// Validate checks the values of the fields that are interpreted by the client.
+// If any are invalid, the returned error is a ConfigErrors listing all of them.
 func (ccc *ChainClientConfig) Validate() error {
-	if _, err := time.ParseDuration(ccc.Timeout); err != nil {
-		return err
+	var errs ConfigErrors
+	check := func(field, value string, err error) {
+		if err != nil {
+			errs = append(errs, ConfigFieldError{Field: field, Value: value, Err: err})
+		}
+	}
+
+	if ccc.RPCAddr != "" {
+		check("rpc-addr", ccc.RPCAddr, validateAddr(ccc.RPCAddr))
+	}
+	for _, addr := range ccc.RPCAddrs {
+		check("rpc-addrs", addr, validateAddr(addr))
+	}
+	if ccc.GRPCAddr != "" {
+		check("grpc-addr", ccc.GRPCAddr, ValidateGRPCAddr(ccc.GRPCAddr))
+	}
+	for _, addr := range ccc.GRPCAddrs {
+		check("grpc-addrs", addr, ValidateGRPCAddr(addr))
+	}
+	if ccc.AccountPrefix == "" {
+		check("account-prefix", ccc.AccountPrefix, errors.New("must not be empty"))
+	}
+	if ccc.GasPrices != "" {
+		_, err := sdk.ParseDecCoins(ccc.GasPrices)
+		check("gas-prices", ccc.GasPrices, err)
+	}
+	check("keyring-backend", ccc.KeyringBackend, ValidateKeyringBackend(ccc.KeyringBackend))
+	if ccc.FeeGranter != "" && ccc.AccountPrefix != "" {
+		_, err := sdk.GetFromBech32(ccc.FeeGranter, ccc.AccountPrefix)
+		check("fee-granter", ccc.FeeGranter, err)
+	}
+	for _, pair := range FormatGRPCHeaders(ccc.GRPCHeaders) {
+		_, err := ParseGRPCHeaders([]string{pair})
+		check("grpc-headers", pair, err)
 	}
+	check("grpc-max-recv-msg-size", strconv.Itoa(ccc.GRPCMaxRecvMsgSize), ValidateGRPCMaxRecvMsgSize(ccc.GRPCMaxRecvMsgSize))
+	_, err := parseOptionalDuration(ccc.GRPCKeepaliveTime)
+	check("grpc-keepalive-time", ccc.GRPCKeepaliveTime, err)
+	_, err = parseOptionalDuration(ccc.GRPCKeepaliveTimeout)
+	check("grpc-keepalive-timeout", ccc.GRPCKeepaliveTimeout, err)
+	check("proxy", ccc.Proxy, ValidateProxy(ccc.Proxy))
+	check("rpc-proxy", ccc.RPCProxy, ValidateProxy(ccc.RPCProxy))
+	_, err = ParseRateLimit(ccc.RateLimit)
+	check("rate-limit", ccc.RateLimit, err)
+	if ccc.RetryBackoff != "" {
+		_, err := parseRetryBackoff(ccc.RetryBackoff)
+		check("retry-backoff", ccc.RetryBackoff, err)
+	}
+	if ccc.KeyDirectory != "" {
+		check("key-directory", ccc.KeyDirectory, validateDirCreatable(ccc.KeyDirectory))
+	}
+	_, err = time.ParseDuration(ccc.Timeout)
+	check("timeout", ccc.Timeout, err)
 	if ccc.BlockTimeout != "" {
-		if _, err := time.ParseDuration(ccc.BlockTimeout); err != nil {
+		_, err := time.ParseDuration(ccc.BlockTimeout)
+		check("block-timeout", ccc.BlockTimeout, err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// KeyringBackends are the supported keyring backends.
+var KeyringBackends = []string{
+	keyring.BackendOS, keyring.BackendFile, keyring.BackendKWallet,
+	keyring.BackendPass, keyring.BackendTest, keyring.BackendMemory,
+}
+
+// ValidateKeyringBackend checks that backend is one of KeyringBackends.
+func ValidateKeyringBackend(backend string) error {
+	for _, b := range KeyringBackends {
+		if backend == b {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown keyring backend %q (must be one of %s)", backend, strings.Join(KeyringBackends, ", "))
+}
+
+// validateAddr checks that addr is either a URL with a host, such as https://example.com:443,
+// or a bare host and port, such as example.com:9090.
+func validateAddr(addr string) error {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
 			return err
 		}
+		if u.Host == "" {
+			return errors.New("URL has no host")
+		}
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return errors.New("expected a URL or host:port")
 	}
 	return nil
 }
 
+// validateDirCreatable checks that dir is an existing directory,
+// or that its closest existing ancestor is a directory, so that it can be created.
+func validateDirCreatable(dir string) error {
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		fi, err := os.Stat(d)
+		if errors.Is(err, fs.ErrNotExist) {
+			if parent := filepath.Dir(d); parent != d {
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return fmt.Errorf("%s is not a directory", d)
+		}
+		return nil
+	}
+}
+
 func GetCosmosHubConfig(keyHome string, debug bool) *ChainClientConfig {
 	return &ChainClientConfig{
 		Key:            "default",
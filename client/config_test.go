@@ -0,0 +1,83 @@
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainClientConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	file := filepath.Join(home, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+
+	require.NoError(t, client.GetCosmosHubConfig(filepath.Join(home, "keys", "cosmoshub-4"), false).Validate())
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*client.ChainClientConfig)
+		fields []string
+	}{
+		{
+			name:   "bare host and port",
+			modify: func(c *client.ChainClientConfig) { c.GRPCAddr = "localhost:9090" },
+		},
+		{
+			name:   "no endpoints",
+			modify: func(c *client.ChainClientConfig) { c.RPCAddr, c.GRPCAddr = "", "" },
+		},
+		{
+			name:   "bare host",
+			modify: func(c *client.ChainClientConfig) { c.RPCAddr = "localhost" },
+			fields: []string{"rpc-addr"},
+		},
+		{
+			name: "every invalid field",
+			modify: func(c *client.ChainClientConfig) {
+				c.RPCAddr = "tcp://"
+				c.GRPCAddr = "grpc"
+				c.AccountPrefix = ""
+				c.GasPrices = "0.01"
+				c.KeyDirectory = filepath.Join(file, "keys")
+				c.Timeout = ""
+				c.BlockTimeout = "1 minute"
+				c.GRPCMaxRecvMsgSize = -1
+				c.GRPCKeepaliveTime = "-30s"
+				c.GRPCKeepaliveTimeout = "often"
+			},
+			fields: []string{
+				"rpc-addr", "grpc-addr", "account-prefix", "gas-prices",
+				"grpc-max-recv-msg-size", "grpc-keepalive-time", "grpc-keepalive-timeout",
+				"key-directory", "timeout", "block-timeout",
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := client.GetCosmosHubConfig(filepath.Join(home, "keys", "cosmoshub-4"), false)
+			tc.modify(c)
+
+			err := c.Validate()
+			if len(tc.fields) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var errs client.ConfigErrors
+			require.True(t, errors.As(err, &errs))
+			fields := make([]string, len(errs))
+			for i, e := range errs {
+				fields[i] = e.Field
+			}
+			require.Equal(t, tc.fields, fields)
+		})
+	}
+}
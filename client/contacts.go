@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// ContactSigil prefixes the name of a contact given where an address is expected, as in @alice,
+// so that a contact is never mistaken for an address or a key.
+const ContactSigil = "@"
+
+var contactNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateContact returns an error if name is not a valid contact name, or address not a bech32 address.
+// The address may be that of any chain: it is converted to the prefix of the chain it is used on.
+func ValidateContact(name, address string) error {
+	if !contactNameRe.MatchString(name) {
+		return fmt.Errorf("invalid contact name %q: must start with a letter or digit, followed by letters, digits, dots, dashes, or underscores", name)
+	}
+	if _, _, err := bech32.DecodeAndConvert(address); err != nil {
+		return fmt.Errorf("invalid address %q of contact %q: %w", address, name, err)
+	}
+	return nil
+}
+
+// UnknownContactError is the error of a reference to a contact that is not in the contact book.
+type UnknownContactError struct {
+	Name string
+	// Names are the names of the contacts in the contact book, sorted.
+	Names []string
+}
+
+func (e UnknownContactError) Error() string {
+	if len(e.Names) == 0 {
+		return fmt.Sprintf("unknown contact %s%s: the contact book is empty", ContactSigil, e.Name)
+	}
+	return fmt.Sprintf("unknown contact %s%s (contacts: %s)", ContactSigil, e.Name, strings.Join(e.Names, ", "))
+}
+
+// WithContacts makes the client resolve the references to contacts, @ followed by their name,
+// given where it decodes an address, to their address in contacts, by name.
+func WithContacts(contacts map[string]string) ChainClientOption {
+	return func(cc *ChainClient) {
+		cc.contacts = contacts
+	}
+}
+
+// contactAddress returns the address of the contact referenced by ref, and whether ref is a reference to a contact.
+func (cc *ChainClient) contactAddress(ref string) ([]byte, bool, error) {
+	if !strings.HasPrefix(ref, ContactSigil) {
+		return nil, false, nil
+	}
+	name := strings.TrimPrefix(ref, ContactSigil)
+	address, ok := cc.contacts[name]
+	if !ok {
+		names := make([]string, 0, len(cc.contacts))
+		for n := range cc.contacts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, true, UnknownContactError{Name: name, Names: names}
+	}
+	_, bz, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid address %q of contact %q: %w", address, name, err)
+	}
+	if err := sdk.VerifyAddressFormat(bz); err != nil {
+		return nil, true, fmt.Errorf("invalid address %q of contact %q: %w", address, name, err)
+	}
+	return bz, true, nil
+}
+
+// ResolveAccAddr returns addr, or, if it references a contact, the address of the contact with the account prefix of the chain.
+// Unlike DecodeBech32AccAddr, an address that is not a contact is returned as is, for commands that pass addresses through.
+func (cc *ChainClient) ResolveAccAddr(addr string) (string, error) {
+	bz, ok, err := cc.contactAddress(addr)
+	if !ok || err != nil {
+		return addr, err
+	}
+	return cc.EncodeBech32AccAddr(bz)
+}
+
+// ResolveValAddr is ResolveAccAddr for validator operator addresses.
+func (cc *ChainClient) ResolveValAddr(addr string) (string, error) {
+	bz, ok, err := cc.contactAddress(addr)
+	if !ok || err != nil {
+		return addr, err
+	}
+	return cc.EncodeBech32ValAddr(bz)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// Denoms is what is known of the denoms of a chain to show amounts of them to people:
+// the metadata registered in the bank module, and the paths of IBC denoms.
+type Denoms struct {
+	// Metadata is the metadata of denoms, by base denom.
+	Metadata map[string]banktypes.Metadata `json:"metadata"`
+	// Traces are the paths of IBC denoms, such as transfer/channel-0/uatom, by IBC denom (ibc/HASH).
+	Traces map[string]string `json:"traces"`
+}
+
+// NewDenoms returns the Denoms of the given metadata and traces.
+func NewDenoms(metadatas []banktypes.Metadata, traces map[string]string) Denoms {
+	d := Denoms{Metadata: make(map[string]banktypes.Metadata, len(metadatas)), Traces: traces}
+	for _, md := range metadatas {
+		d.Metadata[md.Base] = md
+	}
+	if d.Traces == nil {
+		d.Traces = map[string]string{}
+	}
+	return d
+}
+
+// Display returns the amount and denom of c in the display unit of its metadata, such as 12.345678 and osmo for 12345678uosmo.
+// The denom of an IBC coin is followed by its path in parentheses, or replaced by it if it has no metadata.
+// A coin of an unknown denom is returned as is.
+func (d Denoms) Display(c sdk.Coin) (amount, denom string) {
+	amount, denom = c.Amount.String(), c.Denom
+	path, traced := d.Traces[c.Denom]
+	if traced {
+		denom = path
+	}
+
+	md, ok := d.Metadata[c.Denom]
+	if !ok || md.Display == "" || md.Display == c.Denom {
+		return amount, denom
+	}
+	var baseExp, displayExp uint32
+	found := false
+	for _, unit := range md.DenomUnits {
+		switch {
+		case unit.Denom == c.Denom:
+			baseExp = unit.Exponent
+		case unit.Denom == md.Display:
+			displayExp, found = unit.Exponent, true
+		}
+	}
+	if !found || displayExp < baseExp {
+		return amount, denom
+	}
+
+	denom = md.Display
+	if traced {
+		denom += " (" + path + ")"
+	}
+	return shiftDecimal(amount, displayExp-baseExp), denom
+}
+
+// FormatCoin returns c in the display unit of its denom, such as "12.345678 osmo",
+// or as the SDK formats it, such as "12345678uosmo", if nothing is known of its denom.
+func (d Denoms) FormatCoin(c sdk.Coin) string {
+	amount, denom := d.Display(c)
+	if amount == c.Amount.String() && denom == c.Denom {
+		return c.String()
+	}
+	return amount + " " + denom
+}
+
+// FormatCoins returns the coins formatted by FormatCoin, separated by commas.
+func (d Denoms) FormatCoins(coins sdk.Coins) string {
+	formatted := make([]string, len(coins))
+	for i, c := range coins {
+		formatted[i] = d.FormatCoin(c)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// shiftDecimal divides the decimal integer amount by 10^exp, without the trailing zeros of the fraction.
+func shiftDecimal(amount string, exp uint32) string {
+	if exp == 0 {
+		return amount
+	}
+	n := int(exp)
+	if len(amount) <= n {
+		amount = strings.Repeat("0", n-len(amount)+1) + amount
+	}
+	whole, frac := amount[:len(amount)-n], strings.TrimRight(amount[len(amount)-n:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenoms_FormatCoin(t *testing.T) {
+	t.Parallel()
+
+	const ibcAtom = "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	const ibcJuno = "ibc/46B44899322F3CD854D2D46DEEF881958467CDD4B3B10086DA49296BBED94BED"
+	denoms := client.NewDenoms([]banktypes.Metadata{
+		{Base: "uosmo", Display: "osmo", DenomUnits: []*banktypes.DenomUnit{{Denom: "uosmo"}, {Denom: "osmo", Exponent: 6}}},
+		{Base: ibcAtom, Display: "atom", DenomUnits: []*banktypes.DenomUnit{{Denom: ibcAtom}, {Denom: "atom", Exponent: 6}}},
+		// Metadata without the unit of its display denom is not used.
+		{Base: "uion", Display: "ion", DenomUnits: []*banktypes.DenomUnit{{Denom: "uion"}}},
+	}, map[string]string{
+		ibcAtom: "transfer/channel-0/uatom",
+		ibcJuno: "transfer/channel-42/ujuno",
+	})
+
+	for _, tc := range []struct {
+		coin sdk.Coin
+		want string
+	}{
+		{sdk.NewInt64Coin("uosmo", 12345678), "12.345678 osmo"},
+		{sdk.NewInt64Coin("uosmo", 5000000), "5 osmo"},
+		{sdk.NewInt64Coin("uosmo", 42), "0.000042 osmo"},
+		{sdk.NewInt64Coin("uosmo", 0), "0 osmo"},
+		{sdk.NewInt64Coin(ibcAtom, 1500000), "1.5 atom (transfer/channel-0/uatom)"},
+		{sdk.NewInt64Coin(ibcJuno, 1000), "1000 transfer/channel-42/ujuno"},
+		{sdk.NewInt64Coin("uion", 1000), "1000uion"},
+		{sdk.NewInt64Coin("stake", 7), "7stake"},
+	} {
+		require.Equal(t, tc.want, denoms.FormatCoin(tc.coin), tc.coin.String())
+	}
+
+	require.Equal(t, "7stake, 1 osmo", denoms.FormatCoins(sdk.NewCoins(sdk.NewInt64Coin("uosmo", 1000000), sdk.NewInt64Coin("stake", 7))))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth/tx"
 
+	"github.com/strangelove-ventures/lens/byop"
 	ethermintcodecs "github.com/strangelove-ventures/lens/client/codecs/ethermint"
 	injectivecodecs "github.com/strangelove-ventures/lens/client/codecs/injective"
 )
@@ -43,7 +44,8 @@ func MakeCodec(moduleBasics []module.AppModuleBasic, extraCodecs []string) Codec
 }
 
 func MakeCodecConfig() Codec {
-	interfaceRegistry := types.NewInterfaceRegistry()
+	// The registry of byop also decodes the messages of the modules built from descriptors, such as by ExtraMsgDescriptors.
+	interfaceRegistry := byop.NewInterfaceRegistry()
 	marshaler := codec.NewProtoCodec(interfaceRegistry)
 	return Codec{
 		InterfaceRegistry: interfaceRegistry,
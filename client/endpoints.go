@@ -0,0 +1,320 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+)
+
+// endpointDialTimeout is how long an endpoint has to accept a connection
+// before the next endpoint in its list is tried.
+const endpointDialTimeout = 3 * time.Second
+
+// lastGoodEndpoints maps a list of endpoints, as returned by endpointsKey,
+// to the endpoint in that list that most recently accepted a connection.
+// It lives for the lifetime of the process.
+var lastGoodEndpoints sync.Map
+
+func endpointsKey(addrs []string) string {
+	return strings.Join(addrs, "\n")
+}
+
+// orderEndpoints returns addrs with the last good endpoint of the list moved to the front,
+// so that it is tried first.
+func orderEndpoints(addrs []string) []string {
+	good, ok := lastGoodEndpoints.Load(endpointsKey(addrs))
+	if !ok {
+		return addrs
+	}
+
+	ordered := make([]string, 1, len(addrs))
+	ordered[0] = good.(string)
+	for _, addr := range addrs {
+		if addr != ordered[0] {
+			ordered = append(ordered, addr)
+		}
+	}
+	return ordered
+}
+
+// RPCEndpoints returns RPCAddr followed by the fallback RPCAddrs, without empty or duplicate entries.
+func (ccc *ChainClientConfig) RPCEndpoints() []string {
+	return joinEndpoints(ccc.RPCAddr, ccc.RPCAddrs)
+}
+
+// GRPCEndpoints returns GRPCAddr followed by the fallback GRPCAddrs, without empty or duplicate entries.
+func (ccc *ChainClientConfig) GRPCEndpoints() []string {
+	return joinEndpoints(ccc.GRPCAddr, ccc.GRPCAddrs)
+}
+
+func joinEndpoints(primary string, fallbacks []string) []string {
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{primary}, fallbacks...) {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// WithEndpoint returns a copy of ccc restricted to a single RPC and gRPC endpoint.
+//
+// The selector is either an index into the lists returned by RPCEndpoints and GRPCEndpoints,
+// which selects that entry of each list that is long enough,
+// or one of the configured addresses, which selects that address in whichever list holds it.
+func (ccc *ChainClientConfig) WithEndpoint(selector string) (*ChainClientConfig, error) {
+	rpcAddrs, gRPCAddrs := ccc.RPCEndpoints(), ccc.GRPCEndpoints()
+	c := *ccc
+
+	if i, err := strconv.Atoi(selector); err == nil {
+		if i < 0 || (i >= len(rpcAddrs) && i >= len(gRPCAddrs)) {
+			return nil, fmt.Errorf("no endpoint at index %d (chain %s has %d RPC and %d gRPC endpoints)", i, ccc.ChainID, len(rpcAddrs), len(gRPCAddrs))
+		}
+		if i < len(rpcAddrs) {
+			c.RPCAddr, c.RPCAddrs = rpcAddrs[i], nil
+		}
+		if i < len(gRPCAddrs) {
+			c.GRPCAddr, c.GRPCAddrs = gRPCAddrs[i], nil
+		}
+		return &c, nil
+	}
+
+	found := false
+	for _, addr := range rpcAddrs {
+		if addr == selector {
+			c.RPCAddr, c.RPCAddrs = selector, nil
+			found = true
+		}
+	}
+	for _, addr := range gRPCAddrs {
+		if addr == selector {
+			c.GRPCAddr, c.GRPCAddrs = selector, nil
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%q is not a configured endpoint of chain %s", selector, ccc.ChainID)
+	}
+	return &c, nil
+}
+
+// SelectEndpoint returns the first of addrs that accepts a TCP connection within endpointDialTimeout,
+// starting with the endpoint last selected from the same list, if any.
+// A single endpoint is returned without being checked.
+func SelectEndpoint(ctx context.Context, addrs []string) (string, error) {
+	return SelectEndpointThroughProxy(ctx, addrs, ProxyDirect)
+}
+
+// SelectEndpointThroughProxy is SelectEndpoint, connecting to the endpoints through the proxy given by the proxy setting,
+// as described by ChainClientConfig.Proxy.
+func SelectEndpointThroughProxy(ctx context.Context, addrs []string, proxy string) (string, error) {
+	switch len(addrs) {
+	case 0:
+		return "", errors.New("no endpoints configured")
+	case 1:
+		return addrs[0], nil
+	}
+
+	var lastErr error
+	for _, addr := range orderEndpoints(addrs) {
+		network, address, err := endpointDialAddress(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		dial, err := endpointDialer(network, address, proxy)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.Close()
+
+		lastGoodEndpoints.Store(endpointsKey(addrs), addr)
+		return addr, nil
+	}
+	return "", fmt.Errorf("none of the %d endpoints accepted a connection: %w", len(addrs), lastErr)
+}
+
+// endpointDialAddress returns the network and address to dial to reach addr,
+// which is either a URL such as https://example.com, a gRPC target such as dns:///example.com:9090,
+// or a bare host:port.
+func endpointDialAddress(addr string) (network, address string, err error) {
+	if strings.HasPrefix(addr, gRPCSchemeDNS+":") || strings.HasPrefix(addr, gRPCSchemePassthrough+":") {
+		a, err := parseGRPCAddr(addr)
+		if err != nil {
+			return "", "", err
+		}
+		return "tcp", a.hostPort, nil
+	}
+	if !strings.Contains(addr, "://") {
+		return "tcp", addr, nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", err
+	}
+	switch u.Scheme {
+	case "unix":
+		return "unix", u.Host + u.Path, nil
+	case "https":
+		if u.Port() == "" {
+			return "tcp", net.JoinHostPort(u.Hostname(), "443"), nil
+		}
+	case "http":
+		if u.Port() == "" {
+			return "tcp", net.JoinHostPort(u.Hostname(), "80"), nil
+		}
+	}
+	return "tcp", u.Host, nil
+}
+
+// NewFailoverRPCClient returns an RPC client that sends each request to the first of addrs
+// accepting a connection within endpointDialTimeout, starting with the last one that did.
+// Only HTTP requests fail over; websocket subscriptions always use the first endpoint.
+//
+// With a single address, it is equivalent to NewRPCClient.
+func NewFailoverRPCClient(addrs []string, timeout time.Duration) (*rpchttp.HTTP, error) {
+	return newFailoverRPCClient(addrs, timeout, "", nil)
+}
+
+// endpointTransport returns the transport to send the requests to endpoint through, given its default rt.
+type endpointTransport func(endpoint string, rt http.RoundTripper) http.RoundTripper
+
+// newFailoverRPCClient is NewFailoverRPCClient, connecting to the endpoints through the proxy given by the proxy setting,
+// and sending the requests to each endpoint through the transport returned by wrap, if it is not nil.
+func newFailoverRPCClient(addrs []string, timeout time.Duration, proxy string, wrap endpointTransport) (*rpchttp.HTTP, error) {
+	if len(addrs) <= 1 {
+		addr := ""
+		if len(addrs) == 1 {
+			addr = addrs[0]
+		}
+		return newRPCClient(addr, timeout, proxy, wrap)
+	}
+
+	t := &failoverTransport{
+		addrs:     addrs,
+		endpoints: make(map[string]failoverEndpoint, len(addrs)),
+	}
+	for _, addr := range addrs {
+		ep, err := newFailoverEndpoint(addr, proxy, wrap)
+		if err != nil {
+			return nil, err
+		}
+		t.endpoints[addr] = ep
+	}
+
+	httpClient := &http.Client{
+		Transport: t,
+		Timeout:   timeout,
+	}
+	return rpchttp.NewWithClient(addrs[0], "/websocket", httpClient)
+}
+
+// failoverTransport is an http.RoundTripper sending requests addressed to the first of addrs
+// to whichever endpoint accepts a connection.
+type failoverTransport struct {
+	addrs     []string
+	endpoints map[string]failoverEndpoint
+}
+
+type failoverEndpoint struct {
+	url       *url.URL
+	transport http.RoundTripper
+}
+
+func newFailoverEndpoint(addr, proxy string, wrap endpointTransport) (failoverEndpoint, error) {
+	network, address, err := endpointDialAddress(addr)
+	if err != nil {
+		return failoverEndpoint{}, err
+	}
+	rawURL := addr
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return failoverEndpoint{}, err
+	}
+	switch u.Scheme {
+	case "tcp":
+		u.Scheme = "http"
+	case "unix":
+		// The path is that of the socket, and requests need some host to be valid.
+		u.Scheme, u.Host, u.Path = "http", "localhost", ""
+	}
+
+	httpClient, err := libclient.DefaultHTTPClient(addr)
+	if err != nil {
+		return failoverEndpoint{}, err
+	}
+	transport := httpClient.Transport.(*http.Transport)
+	d := net.Dialer{Timeout: endpointDialTimeout}
+	transport.Dial = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, network, address)
+	}
+	if err := proxyRPCTransport(transport, addr, proxy); err != nil {
+		return failoverEndpoint{}, err
+	}
+
+	if wrap != nil {
+		return failoverEndpoint{url: u, transport: wrap(addr, transport)}, nil
+	}
+	return failoverEndpoint{url: u, transport: transport}, nil
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	primary := t.endpoints[t.addrs[0]].url
+
+	var lastErr error
+	for _, addr := range orderEndpoints(t.addrs) {
+		ep := t.endpoints[addr]
+
+		r := req.Clone(req.Context())
+		r.Host = ""
+		r.URL.Scheme = ep.url.Scheme
+		r.URL.Host = ep.url.Host
+		r.URL.Path = ep.url.Path + strings.TrimPrefix(req.URL.Path, primary.Path)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		res, err := ep.transport.RoundTrip(r)
+		if err != nil {
+			var opErr *net.OpError
+			if errors.As(err, &opErr) && opErr.Op == "dial" {
+				// The endpoint did not accept a connection, so the request was not sent.
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+
+		lastGoodEndpoints.Store(endpointsKey(t.addrs), addr)
+		return res, nil
+	}
+	return nil, fmt.Errorf("none of the %d RPC endpoints accepted a connection: %w", len(t.addrs), lastErr)
+}
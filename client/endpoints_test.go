@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectEndpoint(t *testing.T) {
+	t.Parallel()
+
+	refused := refusedAddr(t)
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	live := ln.Addr().String()
+
+	ctx := context.Background()
+
+	addr, err := client.SelectEndpoint(ctx, []string{refused, "http://" + live})
+	require.NoError(t, err)
+	require.Equal(t, "http://"+live, addr)
+
+	// A single endpoint is used without checking it.
+	addr, err = client.SelectEndpoint(ctx, []string{refused})
+	require.NoError(t, err)
+	require.Equal(t, refused, addr)
+
+	_, err = client.SelectEndpoint(ctx, []string{refused, refusedAddr(t)})
+	require.ErrorContains(t, err, "none of the 2 endpoints accepted a connection")
+}
+
+func TestNewFailoverRPCClient(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": {}}`, req.ID)
+	}))
+	t.Cleanup(srv.Close)
+
+	rpcClient, err := client.NewFailoverRPCClient([]string{"http://" + refusedAddr(t), srv.URL}, 5*time.Second)
+	require.NoError(t, err)
+
+	// The first endpoint refuses connections, so the request is transparently sent to the second...
+	_, err = rpcClient.Health(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// ...and requests keep working afterwards.
+	_, err = rpcClient.Health(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestChainClientConfig_WithEndpoint(t *testing.T) {
+	t.Parallel()
+
+	c := client.GetCosmosHubConfig(t.TempDir(), false)
+	c.RPCAddr, c.RPCAddrs = "http://rpc-0:26657", []string{"http://rpc-1:26657", "http://rpc-2:26657"}
+	c.GRPCAddr, c.GRPCAddrs = "grpc-0:9090", []string{"grpc-1:9090"}
+
+	forced, err := c.WithEndpoint("1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://rpc-1:26657"}, forced.RPCEndpoints())
+	require.Equal(t, []string{"grpc-1:9090"}, forced.GRPCEndpoints())
+
+	// Lists shorter than the index are left alone.
+	forced, err = c.WithEndpoint("2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://rpc-2:26657"}, forced.RPCEndpoints())
+	require.Equal(t, c.GRPCEndpoints(), forced.GRPCEndpoints())
+
+	forced, err = c.WithEndpoint("grpc-1:9090")
+	require.NoError(t, err)
+	require.Equal(t, c.RPCEndpoints(), forced.RPCEndpoints())
+	require.Equal(t, []string{"grpc-1:9090"}, forced.GRPCEndpoints())
+
+	_, err = c.WithEndpoint("3")
+	require.ErrorContains(t, err, "no endpoint at index 3")
+	_, err = c.WithEndpoint("grpc-9:9090")
+	require.ErrorContains(t, err, `"grpc-9:9090" is not a configured endpoint`)
+
+	// The original configuration is unchanged.
+	require.Len(t, c.RPCEndpoints(), 3)
+}
+
+// refusedAddr returns the address of a local port that refuses connections.
+func refusedAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return addr
+}
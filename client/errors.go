@@ -1,5 +1,7 @@
 package client
 
+import "fmt"
+
 type _err string
 
 func (e _err) Error() string { return string(e) }
@@ -8,3 +10,46 @@ const (
 	ErrTimeoutAfterWaitingForTxBroadcast _err = "timed out after waiting for tx to get included in the block"
 	ErrUnexpectedNonZeroCode             _err = "node returned unexpected code"
 )
+
+// InterruptedError is returned when the context of an operation is canceled,
+// or its deadline passes, before the operation completes.
+type InterruptedError struct {
+	// Op describes the operation, such as "waiting for tx inclusion".
+	Op  string
+	Err error
+}
+
+func (e InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted %s: %v", e.Op, e.Err)
+}
+
+func (e InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// TxFailedError is returned when a transaction was included in a block
+// but its execution failed.
+type TxFailedError struct {
+	Code      uint32
+	Codespace string
+	TxHash    string
+}
+
+func (e TxFailedError) Error() string {
+	return fmt.Sprintf("transaction failed with code: %d", e.Code)
+}
+
+// UnconfirmedTxError is returned when a transaction passed CheckTx, but waiting for its inclusion in a block
+// timed out or was interrupted, so that it may still be included.
+type UnconfirmedTxError struct {
+	TxHash string
+	Err    error
+}
+
+func (e UnconfirmedTxError) Error() string {
+	return fmt.Sprintf("transaction %s was not confirmed: %v", e.TxHash, e.Err)
+}
+
+func (e UnconfirmedTxError) Unwrap() error {
+	return e.Err
+}
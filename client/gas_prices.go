@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// GasPricesAuto is the value of TxOptions.GasPrices discovering the gas prices from the chain, as DiscoverGasPrices does.
+const GasPricesAuto = "auto"
+
+// GasPriceSourceConfig is the source of gas prices taken from the chain's gas-prices by DiscoverGasPrices.
+const GasPriceSourceConfig = "gas-prices"
+
+// feemarketQuery is a query of the current base fee of a fee market module.
+// The response is only decoded as far as the base fee, so that the module's types need not be known to the codec.
+type feemarketQuery struct {
+	path string
+	// request returns the request for the base fee in denom.
+	request func(denom string) []byte
+	// price decodes the base fee from the response, in denom unless the response gives it.
+	price func(res []byte, denom string) (sdk.DecCoin, error)
+}
+
+// feemarketQueries are the queries of the fee market modules known to DiscoverGasPrices, in the order they are tried.
+var feemarketQueries = []feemarketQuery{
+	{
+		// The feemarket module: GasPriceResponse{price: DecCoin}.
+		path: "/feemarket.feemarket.v1.Query/GasPrice",
+		request: func(denom string) []byte {
+			return protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), denom)
+		},
+		price: func(res []byte, _ string) (sdk.DecCoin, error) {
+			var price sdk.DecCoin
+			bz, err := protoBytesField(res, 1)
+			if err != nil {
+				return sdk.DecCoin{}, err
+			}
+			return price, price.Unmarshal(bz)
+		},
+	},
+	{
+		// The EIP-1559 fee market of Osmosis: QueryEipBaseFeeResponse{base_fee: Dec}.
+		path:    "/osmosis.txfees.v1beta1.Query/GetEipBaseFee",
+		request: func(string) []byte { return nil },
+		price: func(res []byte, denom string) (sdk.DecCoin, error) {
+			var fee sdk.Dec
+			bz, err := protoBytesField(res, 1)
+			if err != nil {
+				return sdk.DecCoin{}, err
+			}
+			if err := fee.Unmarshal(bz); err != nil {
+				return sdk.DecCoin{}, err
+			}
+			return sdk.NewDecCoinFromDec(denom, fee), nil
+		},
+	},
+	{
+		// The fee market of Ethermint chains: QueryBaseFeeResponse{base_fee: Int}.
+		path:    "/ethermint.feemarket.v1.Query/BaseFee",
+		request: func(string) []byte { return nil },
+		price: func(res []byte, denom string) (sdk.DecCoin, error) {
+			var fee sdk.Int
+			bz, err := protoBytesField(res, 1)
+			if err != nil {
+				return sdk.DecCoin{}, err
+			}
+			if err := fee.Unmarshal(bz); err != nil {
+				return sdk.DecCoin{}, err
+			}
+			return sdk.NewDecCoinFromDec(denom, sdk.NewDecFromInt(fee)), nil
+		},
+	},
+}
+
+// protoBytesField returns the last value of the length-delimited field with the given number of the protobuf message bz.
+func protoBytesField(bz []byte, num protowire.Number) ([]byte, error) {
+	var value []byte
+	found := false
+	for len(bz) > 0 {
+		n, typ, l := protowire.ConsumeTag(bz)
+		if l < 0 {
+			return nil, protowire.ParseError(l)
+		}
+		bz = bz[l:]
+		if n == num && typ == protowire.BytesType {
+			v, l := protowire.ConsumeBytes(bz)
+			if l < 0 {
+				return nil, protowire.ParseError(l)
+			}
+			value, found = v, true
+			bz = bz[l:]
+			continue
+		}
+		l = protowire.ConsumeFieldValue(n, typ, bz)
+		if l < 0 {
+			return nil, protowire.ParseError(l)
+		}
+		bz = bz[l:]
+	}
+	if !found {
+		return nil, fmt.Errorf("field %d is missing", num)
+	}
+	return value, nil
+}
+
+// DiscoverGasPrices returns the current gas prices of the chain, and their source, trying in turn:
+//   - the base fee of a fee market module, in the denom of the chain's gas-prices,
+//     from the first of the known fee market services that the chain answers;
+//   - the minimum gas prices of the node, from its node service configuration;
+//   - the chain's gas-prices, whose source is GasPriceSourceConfig.
+//
+// The source of discovered prices is the path of the query answering them.
+// Services the chain does not offer are rejected by its query router, so they are skipped.
+func (cc *ChainClient) DiscoverGasPrices(ctx context.Context) (sdk.DecCoins, string, error) {
+	configured, err := sdk.ParseDecCoins(cc.Config.GasPrices)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid gas-prices %q: %w", cc.Config.GasPrices, err)
+	}
+
+	if len(configured) > 0 {
+		denom := configured[0].Denom
+		for _, q := range feemarketQueries {
+			res, err := cc.QueryABCI(ctx, abci.RequestQuery{Path: q.path, Data: q.request(denom)})
+			if err != nil {
+				cc.log.Debug("Fee market service not available", zap.String("path", q.path), zap.Error(err))
+				continue
+			}
+			price, err := q.price(res.Value, denom)
+			if err != nil {
+				cc.log.Debug("Failed to decode base fee", zap.String("path", q.path), zap.Error(err))
+				continue
+			}
+			if !price.Amount.IsNil() && price.Amount.IsPositive() {
+				return sdk.NewDecCoins(price), q.path, nil
+			}
+		}
+	}
+
+	const nodeConfigPath = "/cosmos.base.node.v1beta1.Service/Config"
+	req, err := (&node.ConfigRequest{}).Marshal()
+	if err != nil {
+		return nil, "", err
+	}
+	if res, err := cc.QueryABCI(ctx, abci.RequestQuery{Path: nodeConfigPath, Data: req}); err != nil {
+		cc.log.Debug("Node configuration not available", zap.Error(err))
+	} else {
+		var config node.ConfigResponse
+		if err := config.Unmarshal(res.Value); err != nil {
+			return nil, "", fmt.Errorf("failed to decode node configuration: %w", err)
+		}
+		prices, err := sdk.ParseDecCoins(config.MinimumGasPrice)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid minimum gas price %q of the node: %w", config.MinimumGasPrice, err)
+		}
+		if !prices.IsZero() {
+			return prices, nodeConfigPath, nil
+		}
+	}
+
+	if len(configured) == 0 {
+		return nil, "", fmt.Errorf("no gas prices were discovered on chain %s, and it has no gas-prices configured", cc.Config.ChainID)
+	}
+	return configured, GasPriceSourceConfig, nil
+}
+
+// autoGasPrices returns the gas prices discovered by DiscoverGasPrices,
+// or an error if any of them is higher than the price of the same denom in maxGasPrice.
+func (cc *ChainClient) autoGasPrices(ctx context.Context, maxGasPrice string) (string, error) {
+	prices, source, err := cc.DiscoverGasPrices(ctx)
+	if err != nil {
+		return "", err
+	}
+	cc.log.Info("Using discovered gas prices", zap.String("gas_prices", prices.String()), zap.String("source", source))
+
+	if maxGasPrice == "" {
+		return prices.String(), nil
+	}
+	limits, err := sdk.ParseDecCoins(maxGasPrice)
+	if err != nil {
+		return "", fmt.Errorf("invalid maximum gas price %q: %w", maxGasPrice, err)
+	}
+	compared := false
+	for _, price := range prices {
+		for _, limit := range limits {
+			if limit.Denom != price.Denom {
+				continue
+			}
+			compared = true
+			if price.Amount.GT(limit.Amount) {
+				return "", fmt.Errorf("discovered gas price %s, from %s, exceeds the maximum gas price %s", price, source, limit)
+			}
+		}
+	}
+	if !compared {
+		return "", fmt.Errorf("discovered gas prices %s, from %s, have no denom of the maximum gas price %s", prices, source, limits)
+	}
+	return prices.String(), nil
+}
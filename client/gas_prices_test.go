@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDiscoverGasPrices(t *testing.T) {
+	t.Parallel()
+
+	// field1 returns a protobuf message whose field 1 holds the marshaled bz.
+	field1 := func(bz []byte, err error) []byte {
+		require.NoError(t, err)
+		return protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), bz)
+	}
+	decCoin := sdk.NewDecCoinFromDec("uatom", sdk.MustNewDecFromStr("0.0042"))
+	eipBaseFee := sdk.MustNewDecFromStr("0.0025")
+	ethBaseFee := sdk.NewInt(7)
+	nodeConfig, err := (&node.ConfigResponse{MinimumGasPrice: "0.005uatom,0.1ibc/ABC"}).Marshal()
+	require.NoError(t, err)
+	zeroNodeConfig, err := (&node.ConfigResponse{MinimumGasPrice: "0uatom"}).Marshal()
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		responses map[string][]byte
+		gasPrices string
+		want      string
+		source    string
+		err       string
+	}{
+		{
+			name: "feemarket",
+			responses: map[string][]byte{
+				"/feemarket.feemarket.v1.Query/GasPrice":   field1(decCoin.Marshal()),
+				"/cosmos.base.node.v1beta1.Service/Config": nodeConfig,
+			},
+			want:   "0.004200000000000000uatom",
+			source: "/feemarket.feemarket.v1.Query/GasPrice",
+		},
+		{
+			name:      "osmosis eip base fee",
+			responses: map[string][]byte{"/osmosis.txfees.v1beta1.Query/GetEipBaseFee": field1(eipBaseFee.Marshal())},
+			want:      "0.002500000000000000uatom",
+			source:    "/osmosis.txfees.v1beta1.Query/GetEipBaseFee",
+		},
+		{
+			name:      "ethermint base fee",
+			responses: map[string][]byte{"/ethermint.feemarket.v1.Query/BaseFee": field1(ethBaseFee.Marshal())},
+			want:      "7.000000000000000000uatom",
+			source:    "/ethermint.feemarket.v1.Query/BaseFee",
+		},
+		{
+			name:      "node config",
+			responses: map[string][]byte{"/cosmos.base.node.v1beta1.Service/Config": nodeConfig},
+			want:      "0.100000000000000000ibc/ABC,0.005000000000000000uatom",
+			source:    "/cosmos.base.node.v1beta1.Service/Config",
+		},
+		{
+			name:      "configured",
+			responses: map[string][]byte{"/cosmos.base.node.v1beta1.Service/Config": zeroNodeConfig},
+			want:      "0.010000000000000000uatom",
+			source:    client.GasPriceSourceConfig,
+		},
+		{
+			name:      "none",
+			gasPrices: "-",
+			err:       "no gas prices were discovered on chain cosmoshub-4, and it has no gas-prices configured",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			homepath := t.TempDir()
+			ccc := client.GetCosmosHubConfig(homepath, true)
+			ccc.GasPrices = "0.01uatom"
+			if tt.gasPrices == "-" {
+				ccc.GasPrices = ""
+			}
+			cl, err := client.NewChainClient(zaptest.NewLogger(t), ccc, homepath, nil, nil)
+			require.NoError(t, err)
+			mc := new(mocks.Client)
+			for path, value := range tt.responses {
+				mc.On("ABCIQueryWithOptions", mock.Anything, path, mock.Anything, rpcclient.ABCIQueryOptions{}).
+					Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value}}, nil)
+			}
+			// Services the chain does not offer are rejected by its query router.
+			mc.On("ABCIQueryWithOptions", mock.Anything, mock.Anything, mock.Anything, rpcclient.ABCIQueryOptions{}).
+				Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 6, Log: "unknown query path"}}, nil)
+			cl.RPCClient = mc
+
+			prices, source, err := cl.DiscoverGasPrices(context.Background())
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, prices.String())
+			require.Equal(t, tt.source, source)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// Schemes of the gRPC addresses that are not a bare host:port.
+const (
+	gRPCSchemeUnix        = "unix"
+	gRPCSchemeDNS         = "dns"
+	gRPCSchemePassthrough = "passthrough"
+)
+
+// gRPCAddrSchemes are the schemes accepted in gRPC addresses.
+var gRPCAddrSchemes = []string{gRPCSchemeUnix, gRPCSchemeDNS, gRPCSchemePassthrough, "http", "https"}
+
+// gRPCAddr is a parsed gRPC address.
+type gRPCAddr struct {
+	// addr is the address as given.
+	addr string
+
+	// scheme is the scheme of the address, or empty for a bare host:port.
+	scheme string
+
+	// hostPort is the host:port that the address resolves to, or empty for a unix socket.
+	hostPort string
+
+	// socket is the path of the unix socket of the address.
+	socket string
+}
+
+// ValidateGRPCAddr checks that addr is a gRPC address accepted by GRPCProxyDialOptions:
+// a host:port, with IPv6 literals in brackets as in [::1]:9090;
+// the path of a unix socket, as in unix:///path/to/grpc.sock;
+// a target passed to the gRPC resolver of its scheme, as in dns:///example.com:9090 or passthrough:///example.com:9090;
+// or an http:// or https:// URL, with port 80 or 443 by default.
+func ValidateGRPCAddr(addr string) error {
+	_, err := parseGRPCAddr(addr)
+	return err
+}
+
+func parseGRPCAddr(addr string) (gRPCAddr, error) {
+	scheme, rest, ok := strings.Cut(addr, ":")
+	if !ok || !isURLScheme(scheme) || !(strings.HasPrefix(rest, "//") || stringsContain(gRPCAddrSchemes, scheme)) {
+		// A bare host:port, whose host may look like a scheme, as in localhost:9090.
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			if strings.Count(addr, ":") > 1 && !strings.HasPrefix(addr, "[") {
+				return gRPCAddr{}, errors.New("expected host:port, with IPv6 addresses in brackets, as in [::1]:9090")
+			}
+			return gRPCAddr{}, errors.New("expected host:port or a unix://, dns://, passthrough://, http://, or https:// address")
+		}
+		return gRPCAddr{addr: addr, hostPort: addr}, nil
+	}
+
+	// The target forms of gRPC are scheme://authority/endpoint and scheme:endpoint;
+	// the authority, naming a DNS server for instance, is only used by the resolver.
+	endpoint := rest
+	if strings.HasPrefix(rest, "//") {
+		authorityEnd := strings.Index(rest[2:], "/")
+		if authorityEnd < 0 {
+			// scheme://endpoint, as http:// and https:// URLs are written.
+			endpoint = rest[2:]
+		} else {
+			endpoint = rest[2+authorityEnd+1:]
+		}
+	}
+
+	switch scheme {
+	case gRPCSchemeUnix:
+		if endpoint == "" {
+			return gRPCAddr{}, errors.New("unix address has no socket path")
+		}
+		if strings.HasPrefix(rest, "///") {
+			// unix:///path/to/grpc.sock is the absolute path /path/to/grpc.sock.
+			endpoint = "/" + endpoint
+		}
+		return gRPCAddr{addr: addr, scheme: scheme, socket: endpoint}, nil
+	case gRPCSchemeDNS, gRPCSchemePassthrough:
+		if endpoint == "" {
+			return gRPCAddr{}, fmt.Errorf("%s address has no host", scheme)
+		}
+		hostPort := endpoint
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			// As with the DNS resolver of gRPC, the port is 443 by default.
+			hostPort = net.JoinHostPort(strings.Trim(endpoint, "[]"), "443")
+		}
+		return gRPCAddr{addr: addr, scheme: scheme, hostPort: hostPort}, nil
+	case "http", "https":
+		host := strings.TrimSuffix(endpoint, "/")
+		if host == "" {
+			return gRPCAddr{}, errors.New("URL has no host")
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			port := "443"
+			if scheme == "http" {
+				port = "80"
+			}
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+		return gRPCAddr{addr: addr, scheme: scheme, hostPort: host}, nil
+	default:
+		return gRPCAddr{}, fmt.Errorf("unsupported gRPC address scheme %q (must be one of %s)", scheme, strings.Join(gRPCAddrSchemes, ", "))
+	}
+}
+
+// target returns the target to dial for the address, without a proxy.
+func (a gRPCAddr) target() string {
+	switch a.scheme {
+	case gRPCSchemeUnix:
+		// Dialed by unixDialOptions, with the path passed through as the address.
+		return "passthrough:///" + a.socket
+	case gRPCSchemeDNS, gRPCSchemePassthrough:
+		// Left to the resolver of its scheme.
+		return a.addr
+	default:
+		return a.hostPort
+	}
+}
+
+// unixDialOptions returns the options connecting to the unix socket whose path is dialed as the target.
+func unixDialOptions() []grpc.DialOption {
+	var d net.Dialer
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, path string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", path)
+		}),
+		// The path is no host name to send as the authority of requests.
+		grpc.WithAuthority("localhost"),
+	}
+}
+
+// isURLScheme reports whether s is a valid URL scheme: a letter followed by letters, digits, '+', '-', or '.'.
+func isURLScheme(s string) bool {
+	for i, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// stringsContain reports whether s is one of values.
+func stringsContain(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
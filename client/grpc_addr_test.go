@@ -0,0 +1,67 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCProxyDialOptions_Targets(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		addr, target string
+	}{
+		{addr: "localhost:9090", target: "localhost:9090"},
+		{addr: "[::1]:9090", target: "[::1]:9090"},
+		{addr: "https://grpc.example.com", target: "grpc.example.com:443"},
+		{addr: "http://grpc.example.com:9090", target: "grpc.example.com:9090"},
+		{addr: "dns:///grpc.example.com:9090", target: "dns:///grpc.example.com:9090"},
+		{addr: "dns://8.8.8.8/grpc.example.com:9090", target: "dns://8.8.8.8/grpc.example.com:9090"},
+		{addr: "passthrough:///grpc.example.com:9090", target: "passthrough:///grpc.example.com:9090"},
+		{addr: "unix:///run/grpc.sock", target: "passthrough:////run/grpc.sock"},
+		{addr: "unix:grpc.sock", target: "passthrough:///grpc.sock"},
+	} {
+		require.NoError(t, client.ValidateGRPCAddr(tc.addr), tc.addr)
+		target, _, err := client.GRPCProxyDialOptions(tc.addr, client.ProxyDirect)
+		require.NoError(t, err, tc.addr)
+		require.Equal(t, tc.target, target, tc.addr)
+	}
+
+	// Through a proxy, the host and port are passed through to the proxy to resolve, whatever the scheme.
+	target, _, err := client.GRPCProxyDialOptions("dns:///grpc.example.com", "socks5h://127.0.0.1:9050")
+	require.NoError(t, err)
+	require.Equal(t, "passthrough:///grpc.example.com:443", target)
+
+	// Unix sockets are never proxied.
+	target, _, err = client.GRPCProxyDialOptions("unix:///run/grpc.sock", "socks5h://127.0.0.1:9050")
+	require.NoError(t, err)
+	require.Equal(t, "passthrough:////run/grpc.sock", target)
+
+	for addr, wantErr := range map[string]string{
+		"grpc.example.com":   "expected host:port or a unix://, dns://, passthrough://, http://, or https:// address",
+		"::1:9090":           "expected host:port, with IPv6 addresses in brackets, as in [::1]:9090",
+		"xds:///example.com": `unsupported gRPC address scheme "xds" (must be one of unix, dns, passthrough, http, https)`,
+		"unix://":            "unix address has no socket path",
+		"https://":           "URL has no host",
+	} {
+		require.EqualError(t, client.ValidateGRPCAddr(addr), wantErr, addr)
+	}
+}
+
+func TestSelectEndpoint_GRPCTargets(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	// The host and port of a target with a scheme are probed.
+	live := "passthrough:///" + ln.Addr().String()
+	addr, err := client.SelectEndpoint(context.Background(), []string{refusedAddr(t), live})
+	require.NoError(t, err)
+	require.Equal(t, live, addr)
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCHeaderEnvPrefix prefixes a gRPC header value that names the environment variable holding the value,
+// so that secrets such as API keys are kept out of the configuration file.
+const GRPCHeaderEnvPrefix = "env:"
+
+// ParseGRPCHeaders parses headers given as key=value pairs, such as x-api-key=env:API_KEY.
+// Keys are lowercased, as gRPC metadata keys are, and a later pair overrides an earlier one with the same key.
+func ParseGRPCHeaders(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid gRPC header %q: must be key=value", pair)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if err := validateGRPCHeaderKey(key); err != nil {
+			return nil, fmt.Errorf("invalid gRPC header %q: %w", pair, err)
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
+
+// FormatGRPCHeaders formats headers as key=value pairs sorted by key, the inverse of ParseGRPCHeaders.
+func FormatGRPCHeaders(headers map[string]string) []string {
+	pairs := make([]string, 0, len(headers))
+	for key, value := range headers {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// validateGRPCHeaderKey checks that key can be sent as a gRPC metadata key.
+func validateGRPCHeaderKey(key string) error {
+	if key == "" {
+		return errors.New("key must not be empty")
+	}
+	if strings.HasPrefix(key, "grpc-") {
+		return errors.New(`keys starting with "grpc-" are reserved`)
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return fmt.Errorf("key must only contain letters, digits, '-', '_', and '.'")
+		}
+	}
+	return nil
+}
+
+// ResolveGRPCHeaders returns headers as gRPC metadata,
+// with each value prefixed with GRPCHeaderEnvPrefix replaced by the value of the environment variable it names.
+// An unset environment variable is an error, so that a missing secret is not silently sent empty.
+func ResolveGRPCHeaders(headers map[string]string) (metadata.MD, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	md := make(metadata.MD, len(headers))
+	for key, value := range headers {
+		if strings.HasPrefix(value, GRPCHeaderEnvPrefix) {
+			name := strings.TrimPrefix(value, GRPCHeaderEnvPrefix)
+			v, set := os.LookupEnv(name)
+			if !set {
+				return nil, fmt.Errorf("environment variable %s of gRPC header %s is not set", name, key)
+			}
+			value = v
+		}
+		md.Set(key, value)
+	}
+	return md, nil
+}
+
+// sensitiveGRPCHeaderKeys are the substrings of the keys of the gRPC headers whose values may be secrets.
+var sensitiveGRPCHeaderKeys = []string{"auth", "token", "secret", "password", "cookie", "key"}
+
+// IsSensitiveGRPCHeader reports whether the values of the gRPC header key may be secrets,
+// such as those of authorization or x-api-key, which must not be logged or recorded.
+func IsSensitiveGRPCHeader(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveGRPCHeaderKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// GRPCMetadata returns the configured GRPCHeaders as gRPC metadata, as resolved by ResolveGRPCHeaders.
+func (ccc *ChainClientConfig) GRPCMetadata() (metadata.MD, error) {
+	return ResolveGRPCHeaders(ccc.GRPCHeaders)
+}
+
+// withGRPCMetadata returns ctx with md added to its outgoing metadata,
+// except for the keys that the outgoing metadata already has, whose values take precedence.
+func withGRPCMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	out, _ := metadata.FromOutgoingContext(ctx)
+	merged := md.Copy()
+	for key, values := range out {
+		merged[key] = values
+	}
+	return metadata.NewOutgoingContext(ctx, merged)
+}
+
+// GRPCHeadersDialOptions returns the options making a gRPC connection send md with every call and stream,
+// in addition to the outgoing metadata of the call, which takes precedence.
+func GRPCHeadersDialOptions(md metadata.MD) []grpc.DialOption {
+	if len(md) == 0 {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withGRPCMetadata(ctx, md), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withGRPCMetadata(ctx, md), desc, cc, method, opts...)
+		}),
+	}
+}
@@ -0,0 +1,81 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestResolveGRPCHeaders(t *testing.T) {
+	t.Setenv("LENS_TEST_API_KEY", "secret")
+
+	headers, err := client.ParseGRPCHeaders([]string{"X-Api-Key=env:LENS_TEST_API_KEY", "authorization=Bearer a=b", "x-api-key=env:LENS_TEST_API_KEY"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"x-api-key": "env:LENS_TEST_API_KEY", "authorization": "Bearer a=b"}, headers)
+	require.Equal(t, []string{"authorization=Bearer a=b", "x-api-key=env:LENS_TEST_API_KEY"}, client.FormatGRPCHeaders(headers))
+
+	md, err := client.ResolveGRPCHeaders(headers)
+	require.NoError(t, err)
+	require.Equal(t, metadata.Pairs("x-api-key", "secret", "authorization", "Bearer a=b"), md)
+
+	_, err = client.ResolveGRPCHeaders(map[string]string{"x-api-key": "env:LENS_TEST_UNSET"})
+	require.EqualError(t, err, "environment variable LENS_TEST_UNSET of gRPC header x-api-key is not set")
+
+	for _, pair := range []string{"novalue", "=value", "grpc-timeout=1S", "bad key=1"} {
+		_, err := client.ParseGRPCHeaders([]string{pair})
+		require.Error(t, err, pair)
+	}
+}
+
+func TestIsSensitiveGRPCHeader(t *testing.T) {
+	for key, want := range map[string]bool{
+		"authorization":         true,
+		"X-Api-Key":             true,
+		"x-auth-token":          true,
+		"cookie":                true,
+		"x-note":                false,
+		"x-cosmos-block-height": false,
+	} {
+		require.Equal(t, want, client.IsSensitiveGRPCHeader(key), key)
+	}
+}
+
+func TestInvoke_GRPCHeaders(t *testing.T) {
+	t.Parallel()
+
+	homepath := t.TempDir()
+	ccc := client.GetCosmosHubConfig(homepath, true)
+	ccc.GRPCHeaders = map[string]string{"x-cosmos-block-height": "42"}
+	require.NoError(t, ccc.Validate())
+	cl, err := client.NewChainClient(zaptest.NewLogger(t), ccc, homepath, nil, nil)
+	require.NoError(t, err)
+	mc := new(mocks.Client)
+	cl.RPCClient = mc
+
+	res := &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{}}
+	for _, height := range []int64{42, 7} {
+		mc.On("ABCIQueryWithOptions", mock.Anything, "/cosmos.auth.v1beta1.Query/Params", mock.Anything,
+			rpcclient.ABCIQueryOptions{Height: height}).Return(res, nil).Once()
+	}
+
+	// The configured block height applies, unless the call sets one.
+	qc := authtypes.NewQueryClient(cl)
+	_, err = qc.Params(context.Background(), &authtypes.QueryParamsRequest{})
+	require.NoError(t, err)
+	_, err = qc.Params(client.SetHeightOnContext(context.Background(), 7), &authtypes.QueryParamsRequest{})
+	require.NoError(t, err)
+	mc.AssertExpectations(t)
+
+	ccc.GRPCHeaders = map[string]string{"Bad Key": "1"}
+	require.ErrorContains(t, ccc.Validate(), `invalid grpc-headers "Bad Key=1"`)
+}
@@ -27,6 +27,15 @@ var protoCodec = encoding.GetCodec(proto.Name)
 
 // Invoke implements the grpc ClientConn.Invoke method
 func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) (err error) {
+	if cc.tracer != nil {
+		return cc.tracer.trace(ctx, cc.Config.RPCAddr, method, req, reply, func(ctx context.Context) error {
+			return cc.invoke(ctx, method, req, reply, opts...)
+		})
+	}
+	return cc.invoke(ctx, method, req, reply, opts...)
+}
+
+func (cc *ChainClient) invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) (err error) {
 	// Two things can happen here:
 	// 1. either we're broadcasting a Tx, in which call we call Tendermint's broadcast endpoint directly,
 	// 2. or we are querying for state, in which case we call ABCI's Querier.
@@ -55,6 +64,12 @@ func (cc *ChainClient) Invoke(ctx context.Context, method string, req, reply int
 	}
 
 	// Case 2. Querying state.
+	// The configured headers apply unless the call sets them, such as a historical block height.
+	headers, err := cc.Config.GRPCMetadata()
+	if err != nil {
+		return err
+	}
+	ctx = withGRPCMetadata(ctx, headers)
 	inMd, _ := metadata.FromOutgoingContext(ctx)
 	abciRes, outMd, err := cc.RunGRPCQuery(ctx, method, req, inMd)
 	if err != nil {
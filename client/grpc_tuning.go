@@ -0,0 +1,89 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// DefaultGRPCMaxRecvMsgSize is the largest gRPC message received by default, in bytes:
+// 64MB, well above the 4MB default of gRPC, so that large responses such as validator sets are received.
+const DefaultGRPCMaxRecvMsgSize = 64 << 20
+
+// GRPCTuning holds the message size and keepalive settings of a gRPC connection.
+type GRPCTuning struct {
+	// MaxRecvMsgSize is the largest message received, in bytes, or DefaultGRPCMaxRecvMsgSize if zero.
+	MaxRecvMsgSize int
+
+	// KeepaliveTime is how long the connection may be idle before the server is pinged, or zero to never ping it.
+	// gRPC pings at most every 10 seconds, and servers may close connections pinging more often than they allow.
+	KeepaliveTime time.Duration
+
+	// KeepaliveTimeout is how long to wait for the response to a ping before closing the connection,
+	// or zero for the gRPC default of 20 seconds.
+	KeepaliveTimeout time.Duration
+
+	// KeepalivePermitWithoutStream pings the server even when no call is in progress,
+	// so that connections left idle are kept open through load balancers.
+	KeepalivePermitWithoutStream bool
+}
+
+// DialOptions returns the options applying t to a gRPC connection.
+func (t GRPCTuning) DialOptions() []grpc.DialOption {
+	maxRecvMsgSize := t.MaxRecvMsgSize
+	if maxRecvMsgSize == 0 {
+		maxRecvMsgSize = DefaultGRPCMaxRecvMsgSize
+	}
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxRecvMsgSize))}
+	if t.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                t.KeepaliveTime,
+			Timeout:             t.KeepaliveTimeout,
+			PermitWithoutStream: t.KeepalivePermitWithoutStream,
+		}))
+	}
+	return opts
+}
+
+// GRPCTuning returns the configured message size and keepalive settings of the gRPC connections to the chain.
+func (ccc *ChainClientConfig) GRPCTuning() (GRPCTuning, error) {
+	t := GRPCTuning{
+		MaxRecvMsgSize:               ccc.GRPCMaxRecvMsgSize,
+		KeepalivePermitWithoutStream: ccc.GRPCKeepalivePermitWithoutStream,
+	}
+	var err error
+	if t.KeepaliveTime, err = parseOptionalDuration(ccc.GRPCKeepaliveTime); err != nil {
+		return GRPCTuning{}, fmt.Errorf("invalid grpc-keepalive-time %q: %w", ccc.GRPCKeepaliveTime, err)
+	}
+	if t.KeepaliveTimeout, err = parseOptionalDuration(ccc.GRPCKeepaliveTimeout); err != nil {
+		return GRPCTuning{}, fmt.Errorf("invalid grpc-keepalive-timeout %q: %w", ccc.GRPCKeepaliveTimeout, err)
+	}
+	return t, nil
+}
+
+// ValidateGRPCMaxRecvMsgSize checks that size is a valid maximum size of the gRPC messages received:
+// zero for the default, or a positive number of bytes.
+func ValidateGRPCMaxRecvMsgSize(size int) error {
+	if size < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+// parseOptionalDuration parses s as a non-negative duration, or zero if empty.
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return d, nil
+}
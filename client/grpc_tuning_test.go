@@ -0,0 +1,37 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainClientConfig_GRPCTuning(t *testing.T) {
+	t.Parallel()
+
+	c := client.GetCosmosHubConfig(t.TempDir(), false)
+	tuning, err := c.GRPCTuning()
+	require.NoError(t, err)
+	require.Equal(t, client.GRPCTuning{}, tuning)
+	require.Len(t, tuning.DialOptions(), 1, "only the default maximum message size applies without keepalive settings")
+
+	c.GRPCMaxRecvMsgSize = 8 << 20
+	c.GRPCKeepaliveTime = "30s"
+	c.GRPCKeepaliveTimeout = "5s"
+	c.GRPCKeepalivePermitWithoutStream = true
+	tuning, err = c.GRPCTuning()
+	require.NoError(t, err)
+	require.Equal(t, client.GRPCTuning{
+		MaxRecvMsgSize:               8 << 20,
+		KeepaliveTime:                30 * time.Second,
+		KeepaliveTimeout:             5 * time.Second,
+		KeepalivePermitWithoutStream: true,
+	}, tuning)
+	require.Len(t, tuning.DialOptions(), 2)
+
+	c.GRPCKeepaliveTimeout = "soon"
+	_, err = c.GRPCTuning()
+	require.ErrorContains(t, err, `invalid grpc-keepalive-timeout "soon"`)
+}
@@ -0,0 +1,261 @@
+// Package grpcdynamic uses gRPC server reflection to inspect and call
+// arbitrary services on a remote server, without compiled-in protobuf types.
+package grpcdynamic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	jgrpcdynamic "github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+)
+
+// DescriptorSource resolves the protobuf descriptors of a remote gRPC server.
+// It is satisfied by *grpcreflect.Client.
+type DescriptorSource interface {
+	ListServices() ([]string, error)
+	ResolveService(serviceName string) (*desc.ServiceDescriptor, error)
+	ResolveMessage(messageName string) (*desc.MessageDescriptor, error)
+}
+
+var _ DescriptorSource = (*grpcreflect.Client)(nil)
+
+// ReflectionClient resolves and invokes the methods of a remote gRPC server.
+type ReflectionClient struct {
+	conn grpc.ClientConnInterface
+	src  DescriptorSource
+}
+
+// ReflectionClientOption configures a ReflectionClient.
+type ReflectionClientOption func(*ReflectionClient)
+
+// WithDescriptorSource makes the client resolve descriptors through src,
+// such as a cache, instead of through the server reflection service.
+func WithDescriptorSource(src DescriptorSource) ReflectionClientOption {
+	return func(c *ReflectionClient) {
+		c.src = src
+	}
+}
+
+// NewReflectionClient returns a client for the server at the other end of conn.
+//
+// By default, each call opens its own server reflection stream, bound to the call's context,
+// using version v1 of the reflection service, or v1alpha if the server does not implement v1.
+// If a DescriptorSource is provided, descriptors are resolved through it instead,
+// and the context only applies to method invocations.
+func NewReflectionClient(conn grpc.ClientConnInterface, opts ...ReflectionClientOption) *ReflectionClient {
+	c := &ReflectionClient{conn: conn}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// source returns the DescriptorSource to use for a call with the given context,
+// and a function to release it once the call is complete.
+func (c *ReflectionClient) source(ctx context.Context) (DescriptorSource, func()) {
+	if c.src != nil {
+		return c.src, func() {}
+	}
+
+	rc := grpcreflect.NewClientAuto(ctx, c.conn)
+	return rc, rc.Reset
+}
+
+// ListServices returns the fully qualified names of the services offered by the server, sorted.
+func (c *ReflectionClient) ListServices(ctx context.Context) ([]string, error) {
+	src, release := c.source(ctx)
+	defer release()
+
+	services, err := src.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote services: %w", err)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// ResolveService returns the descriptor of the named service.
+// If the server does not offer the service,
+// the returned error satisfies grpcreflect.IsElementNotFoundError.
+func (c *ReflectionClient) ResolveService(ctx context.Context, serviceName string) (*desc.ServiceDescriptor, error) {
+	src, release := c.source(ctx)
+	defer release()
+
+	return src.ResolveService(serviceName)
+}
+
+// ResolveMethod returns the descriptor of the method with the given fully qualified name,
+// such as cosmos.bank.v1beta1.Query.AllBalances or cosmos.bank.v1beta1.Query/AllBalances.
+func (c *ReflectionClient) ResolveMethod(ctx context.Context, method string) (*desc.MethodDescriptor, error) {
+	serviceName, methodName, err := SplitMethodName(method)
+	if err != nil {
+		return nil, err
+	}
+
+	svcDesc, err := c.ResolveService(ctx, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve service %q: %w", serviceName, err)
+	}
+
+	methodDesc := svcDesc.FindMethodByName(methodName)
+	if methodDesc == nil {
+		return nil, fmt.Errorf("service %q has no method %q", serviceName, methodName)
+	}
+	return methodDesc, nil
+}
+
+// MethodIO returns the request and response message descriptors of the given method.
+// The method name is interpreted as in ResolveMethod.
+func (c *ReflectionClient) MethodIO(ctx context.Context, method string) (in, out *desc.MessageDescriptor, err error) {
+	methodDesc, err := c.ResolveMethod(ctx, method)
+	if err != nil {
+		return nil, nil, err
+	}
+	return methodDesc.GetInputType(), methodDesc.GetOutputType(), nil
+}
+
+// Invoke calls the given unary method with the JSON request jsonReq and the call options opts,
+// and returns the JSON serialization of the response.
+// The method name is interpreted as in ResolveMethod.
+func (c *ReflectionClient) Invoke(ctx context.Context, method string, jsonReq []byte, opts ...grpc.CallOption) (json.RawMessage, error) {
+	methodDesc, err := c.ResolveMethod(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return c.InvokeMethod(ctx, methodDesc, jsonReq, opts...)
+}
+
+// InvokeMethod calls the unary method described by methodDesc with the JSON request jsonReq and the call options opts,
+// such as grpc.Header to receive the response headers, and returns the JSON serialization of the response.
+// Any messages in the response are resolved through the client.
+//
+// If the server responds with an error, that error is returned unwrapped,
+// so that its status can be read with status.FromError.
+func (c *ReflectionClient) InvokeMethod(ctx context.Context, methodDesc *desc.MethodDescriptor, jsonReq []byte, opts ...grpc.CallOption) (json.RawMessage, error) {
+	if methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming() {
+		return nil, errors.New("TODO: handle client/server streaming")
+	}
+
+	inMsgDesc := methodDesc.GetInputType()
+	inputMsg := dynamic.NewMessage(inMsgDesc)
+	if err := inputMsg.UnmarshalJSON(jsonReq); err != nil {
+		return nil, fmt.Errorf("failed to marshal input into message of type %s: %w", inMsgDesc.GetFullyQualifiedName(), err)
+	}
+
+	output, err := jgrpcdynamic.NewStub(c.conn).InvokeRpc(ctx, methodDesc, inputMsg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to a dynamic message, so that we can use the AnyResolver
+	// based on the client that can resolve not-yet-known messages.
+	dynOutput, err := dynamic.AsDynamicMessage(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert output to dynamic message: %w", err)
+	}
+
+	src, release := c.source(ctx)
+	defer release()
+
+	j, err := dynOutput.MarshalJSONPB(&jsonpb.Marshaler{
+		// For Any fields, resolve through the client.
+		AnyResolver: AnyResolver{Source: src},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize output message: %w", err)
+	}
+
+	return j, nil
+}
+
+// SplitMethodName splits a fully qualified method name,
+// such as cosmos.bank.v1beta1.Query.AllBalances or cosmos.bank.v1beta1.Query/AllBalances,
+// into its service and method names.
+func SplitMethodName(method string) (serviceName, methodName string, err error) {
+	method = strings.TrimPrefix(method, "/")
+	i := strings.LastIndexAny(method, "./")
+	if i <= 0 || i == len(method)-1 {
+		return "", "", fmt.Errorf("%q is not a fully qualified method name (expected SERVICE.METHOD)", method)
+	}
+
+	return method[:i], method[i+1:], nil
+}
+
+// CollectDependencies returns the message and enum types referenced by the fields of msgDescs,
+// directly or through other messages, without duplicates.
+// The types are in the order they are first encountered in a depth-first walk of the fields.
+func CollectDependencies(msgDescs ...*desc.MessageDescriptor) []desc.Descriptor {
+	var deps []desc.Descriptor
+	seen := make(map[string]bool)
+
+	var walk func(msgDesc *desc.MessageDescriptor)
+	walk = func(msgDesc *desc.MessageDescriptor) {
+		for _, fDesc := range msgDesc.GetFields() {
+			if mDesc := fDesc.GetMessageType(); mDesc != nil {
+				if !seen[mDesc.GetFullyQualifiedName()] {
+					seen[mDesc.GetFullyQualifiedName()] = true
+					deps = append(deps, mDesc)
+					walk(mDesc)
+				}
+
+				continue
+			}
+
+			if eDesc := fDesc.GetEnumType(); eDesc != nil {
+				if !seen[eDesc.GetFullyQualifiedName()] {
+					seen[eDesc.GetFullyQualifiedName()] = true
+					// Enums are just lists of constants, so no need to descend into them.
+					deps = append(deps, eDesc)
+				}
+			}
+		}
+	}
+
+	for _, msgDesc := range msgDescs {
+		walk(msgDesc)
+	}
+
+	return deps
+}
+
+// AnyResolver resolves the types packed in google.protobuf.Any values through a DescriptorSource,
+// so that types unknown to the local binary can be serialized.
+type AnyResolver struct {
+	Source DescriptorSource
+}
+
+var _ jsonpb.AnyResolver = AnyResolver{}
+
+func (r AnyResolver) Resolve(typeURL string) (proto.Message, error) {
+	// Unclear if it is always safe to trim the leading slash here.
+	typeURL = strings.TrimPrefix(typeURL, "/")
+	messageDesc, err := r.Source.ResolveMessage(typeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewMessage(messageDesc), nil
+}
+
+// Dial connects to the gRPC server at addr.
+// Unlike grpc.DialContext, it blocks until the connection is ready or ctx is done,
+// so that unreachable servers are reported here, with the underlying connection error,
+// rather than on the first call.
+func Dial(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append(opts,
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithReturnConnectionError(),
+	)
+	return grpc.DialContext(ctx, addr, opts...)
+}
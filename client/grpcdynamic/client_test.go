@@ -0,0 +1,170 @@
+package grpcdynamic_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	channelzsvc "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+func TestReflectionClient_ListServices(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+
+	services, err := c.ListServices(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"grpc.channelz.v1.Channelz", "grpc.reflection.v1alpha.ServerReflection"}, services)
+}
+
+func TestReflectionClient_ResolveService(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	svcDesc, err := c.ResolveService(ctx, "grpc.channelz.v1.Channelz")
+	require.NoError(t, err)
+	require.NotNil(t, svcDesc.FindMethodByName("GetTopChannels"))
+
+	_, err = c.ResolveService(ctx, "grpc.channelz.v1.Nope")
+	require.True(t, grpcreflect.IsElementNotFoundError(err))
+}
+
+func TestReflectionClient_MethodIO(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	for _, method := range []string{
+		"grpc.channelz.v1.Channelz.GetServer",
+		"grpc.channelz.v1.Channelz/GetServer",
+		"/grpc.channelz.v1.Channelz/GetServer",
+	} {
+		in, out, err := c.MethodIO(ctx, method)
+		require.NoError(t, err, method)
+		require.Equal(t, "grpc.channelz.v1.GetServerRequest", in.GetFullyQualifiedName())
+		require.Equal(t, "grpc.channelz.v1.GetServerResponse", out.GetFullyQualifiedName())
+	}
+
+	_, _, err := c.MethodIO(ctx, "grpc.channelz.v1.Channelz.Nope")
+	require.ErrorContains(t, err, `service "grpc.channelz.v1.Channelz" has no method "Nope"`)
+
+	_, _, err = c.MethodIO(ctx, "GetServer")
+	require.ErrorContains(t, err, "not a fully qualified method name")
+}
+
+func TestReflectionClient_Invoke(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	res, err := c.Invoke(ctx, "grpc.channelz.v1.Channelz.GetTopChannels", []byte(`{"start_channel_id": 0}`))
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(res, &out))
+	require.Equal(t, true, out["end"])
+
+	_, err = c.Invoke(ctx, "grpc.channelz.v1.Channelz.GetTopChannels", []byte(`{"no_such_field": 1}`))
+	require.ErrorContains(t, err, "failed to marshal input into message of type grpc.channelz.v1.GetTopChannelsRequest")
+}
+
+func TestCollectDependencies(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t)
+
+	in, out, err := c.MethodIO(context.Background(), "grpc.channelz.v1.Channelz.GetServer")
+	require.NoError(t, err)
+
+	deps := grpcdynamic.CollectDependencies(in, out)
+
+	names := make([]string, len(deps))
+	for i, d := range deps {
+		names[i] = d.GetFullyQualifiedName()
+	}
+
+	// The response references a server, which references its data and its listen sockets...
+	require.Equal(t, "grpc.channelz.v1.Server", names[0])
+	require.Contains(t, names, "grpc.channelz.v1.ServerData")
+	require.Contains(t, names, "grpc.channelz.v1.SocketRef")
+	// ...and enums are included too.
+	require.Contains(t, names, "grpc.channelz.v1.ChannelTraceEvent.Severity")
+
+	// Each type is only listed once, even though several messages reference it.
+	seen := make(map[string]bool)
+	for _, name := range names {
+		require.False(t, seen[name], name)
+		seen[name] = true
+	}
+
+	// Collecting from the same messages twice yields the same result.
+	require.Equal(t, deps, grpcdynamic.CollectDependencies(in, out, in))
+}
+
+func TestReflectionClient_WithDescriptorSource(t *testing.T) {
+	t.Parallel()
+
+	conn := dialTestServer(t)
+	rc := grpcreflect.NewClient(context.Background(), rpb.NewServerReflectionClient(conn))
+	t.Cleanup(rc.Reset)
+
+	src := &countingSource{DescriptorSource: rc}
+	c := grpcdynamic.NewReflectionClient(conn, grpcdynamic.WithDescriptorSource(src))
+
+	_, err := c.ResolveService(context.Background(), "grpc.channelz.v1.Channelz")
+	require.NoError(t, err)
+	require.Equal(t, 1, src.resolved)
+}
+
+type countingSource struct {
+	grpcdynamic.DescriptorSource
+	resolved int
+}
+
+func (s *countingSource) ResolveService(serviceName string) (*desc.ServiceDescriptor, error) {
+	s.resolved++
+	return s.DescriptorSource.ResolveService(serviceName)
+}
+
+func newTestClient(t *testing.T) *grpcdynamic.ReflectionClient {
+	t.Helper()
+
+	return grpcdynamic.NewReflectionClient(dialTestServer(t))
+}
+
+// dialTestServer starts an in-process gRPC server offering the reflection and channelz services,
+// and returns a connection to it.
+func dialTestServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	reflection.Register(srv)
+	channelzsvc.RegisterChannelzServiceToServer(srv)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpcdynamic.Dial(context.Background(), ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
@@ -0,0 +1,301 @@
+package grpcdynamic
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// openAPISchemaPrefix is the location of the schemas of an OpenAPI document.
+const openAPISchemaPrefix = "#/components/schemas/"
+
+// openAPIStatusSchema is the name of the schema of the errors returned by grpc-gateway.
+const openAPIStatusSchema = "google.rpc.Status"
+
+// pathVariablePattern matches the variables of an HTTP rule path template,
+// such as {address} or {name=denoms/*}, capturing the field path.
+var pathVariablePattern = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// OpenAPI returns an OpenAPI 3.1 document, with the given title and version, describing the REST routes
+// that grpc-gateway serves for the methods of svcDescs, as declared by their google.api.http options.
+//
+// The path and query parameters of each route are derived from the method's request message,
+// and its request and response bodies are described by JSON Schemas as generated by JSONSchema,
+// under the components of the document.
+// Methods without a google.api.http option, or whose option cannot be described,
+// are left out of the document, and returned in skipped, mapped to the reason they were left out.
+func OpenAPI(title, version string, svcDescs []*desc.ServiceDescriptor) (doc map[string]interface{}, skipped map[string]string) {
+	g := newSchemaGenerator(openAPISchemaPrefix)
+	paths := make(map[string]map[string]interface{})
+	skipped = make(map[string]string)
+
+	for _, svcDesc := range svcDescs {
+		for _, methodDesc := range svcDesc.GetMethods() {
+			name := methodDesc.GetFullyQualifiedName()
+
+			rule, ok := methodHTTPRule(methodDesc)
+			if !ok {
+				skipped[name] = "no google.api.http option"
+				continue
+			}
+
+			// Each binding is checked before any is added, so that a method is either fully described or skipped.
+			bindings := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+			ops := make([]openAPIOperation, len(bindings))
+			var err error
+			for i, b := range bindings {
+				ops[i], err = newOpenAPIOperation(g, methodDesc, b)
+				if err != nil {
+					break
+				}
+				if _, ok := paths[ops[i].path][ops[i].method]; ok {
+					err = fmt.Errorf("route %s %s is already used by another method", strings.ToUpper(ops[i].method), ops[i].path)
+					break
+				}
+			}
+			if err != nil {
+				skipped[name] = err.Error()
+				continue
+			}
+
+			for i, op := range ops {
+				op.op["operationId"] = name
+				if i > 0 {
+					op.op["operationId"] = fmt.Sprintf("%s_%d", name, i)
+				}
+				op.op["tags"] = []string{svcDesc.GetFullyQualifiedName()}
+
+				if paths[op.path] == nil {
+					paths[op.path] = make(map[string]interface{})
+				}
+				paths[op.path][op.method] = op.op
+			}
+		}
+	}
+
+	g.defs[openAPIStatusSchema] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"code":    scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			"message": map[string]interface{}{"type": "string"},
+			"details": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"$ref": openAPISchemaPrefix + "google.protobuf.Any"},
+			},
+		},
+	}
+	if _, ok := g.defs["google.protobuf.Any"]; !ok {
+		g.defs["google.protobuf.Any"], _ = wellKnownSchema("google.protobuf.Any")
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.defs,
+		},
+	}, skipped
+}
+
+// methodHTTPRule returns the google.api.http option of methodDesc, if it has one.
+func methodHTTPRule(methodDesc *desc.MethodDescriptor) (*annotations.HttpRule, bool) {
+	opts := methodDesc.GetMethodOptions()
+	if opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+		return nil, false
+	}
+	rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	return rule, ok && rule != nil
+}
+
+// openAPIOperation is an operation of an OpenAPI document, along with its route.
+type openAPIOperation struct {
+	method string
+	path   string
+	op     map[string]interface{}
+}
+
+// newOpenAPIOperation returns the operation describing the route given by rule for methodDesc.
+func newOpenAPIOperation(g *schemaGenerator, methodDesc *desc.MethodDescriptor, rule *annotations.HttpRule) (openAPIOperation, error) {
+	var method, template string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, template = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		method, template = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		method, template = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		method, template = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		method, template = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		method, template = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	}
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch,
+		http.MethodHead, http.MethodOptions, http.MethodTrace:
+	default:
+		return openAPIOperation{}, fmt.Errorf("unsupported HTTP method %q", method)
+	}
+	if template == "" {
+		return openAPIOperation{}, fmt.Errorf("empty path in google.api.http option")
+	}
+
+	inDesc := methodDesc.GetInputType()
+	var params []interface{}
+
+	// Path parameters, in the order of the template.
+	inPath := make(map[string]bool)
+	for _, m := range pathVariablePattern.FindAllStringSubmatch(template, -1) {
+		fieldPath := m[1]
+		f, err := findFieldPath(inDesc, fieldPath)
+		if err != nil {
+			return openAPIOperation{}, err
+		}
+		inPath[fieldPath] = true
+		params = append(params, map[string]interface{}{
+			"name":     fieldPath,
+			"in":       "path",
+			"required": true,
+			"schema":   g.fieldSchema(f),
+		})
+	}
+
+	op := map[string]interface{}{
+		"summary": methodDesc.GetName(),
+	}
+
+	// The fields not bound to the path or the body are query parameters.
+	body := rule.GetBody()
+	if body != "*" {
+		params = append(params, queryParameters(g, inDesc, "", inPath, body, nil)...)
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if body != "" {
+		schema := g.messageRef(inDesc)
+		if body != "*" {
+			f := inDesc.FindFieldByName(body)
+			if f == nil {
+				return openAPIOperation{}, fmt.Errorf("body %q is not a field of %s", body, inDesc.GetFullyQualifiedName())
+			}
+			schema = g.fieldSchema(f)
+		}
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schema},
+			},
+		}
+	}
+
+	outDesc := methodDesc.GetOutputType()
+	respSchema := g.messageRef(outDesc)
+	if rb := rule.GetResponseBody(); rb != "" {
+		f := outDesc.FindFieldByName(rb)
+		if f == nil {
+			return openAPIOperation{}, fmt.Errorf("response body %q is not a field of %s", rb, outDesc.GetFullyQualifiedName())
+		}
+		respSchema = g.fieldSchema(f)
+	}
+	op["responses"] = map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "A successful response.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": respSchema},
+			},
+		},
+		"default": map[string]interface{}{
+			"description": "An error response.",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": openAPISchemaPrefix + openAPIStatusSchema},
+				},
+			},
+		},
+	}
+
+	return openAPIOperation{
+		method: strings.ToLower(method),
+		path:   pathVariablePattern.ReplaceAllString(template, "{$1}"),
+		op:     op,
+	}, nil
+}
+
+// findFieldPath returns the field at the dot-separated fieldPath, such as pagination.key, of msgDesc.
+func findFieldPath(msgDesc *desc.MessageDescriptor, fieldPath string) (*desc.FieldDescriptor, error) {
+	names := strings.Split(fieldPath, ".")
+	var f *desc.FieldDescriptor
+	for i, name := range names {
+		f = msgDesc.FindFieldByName(name)
+		if f == nil {
+			return nil, fmt.Errorf("path parameter %q is not a field of %s", fieldPath, msgDesc.GetFullyQualifiedName())
+		}
+		if i < len(names)-1 {
+			if msgDesc = f.GetMessageType(); msgDesc == nil || f.IsRepeated() {
+				return nil, fmt.Errorf("path parameter %q does not name a nested field", fieldPath)
+			}
+		}
+	}
+	return f, nil
+}
+
+// queryParameters returns the query parameters of the fields of msgDesc,
+// named by their proto field paths after prefix, excluding the fields in inPath and the body field.
+// The fields of nested messages are flattened into parameters such as pagination.limit,
+// except for repeated and recursive messages, and maps, which grpc-gateway cannot read from queries.
+// parents lists the messages that msgDesc is nested in.
+func queryParameters(g *schemaGenerator, msgDesc *desc.MessageDescriptor, prefix string, inPath map[string]bool, body string, parents []string) []interface{} {
+	parents = append(parents, msgDesc.GetFullyQualifiedName())
+
+	var params []interface{}
+	for _, f := range msgDesc.GetFields() {
+		fieldPath := prefix + f.GetName()
+		if inPath[fieldPath] || (prefix == "" && f.GetName() == body) || f.IsMap() {
+			continue
+		}
+
+		if fieldMsg := f.GetMessageType(); fieldMsg != nil {
+			name := fieldMsg.GetFullyQualifiedName()
+			if _, ok := wellKnownSchema(name); !ok {
+				if f.IsRepeated() || containsString(parents, name) {
+					continue
+				}
+				params = append(params, queryParameters(g, fieldMsg, fieldPath+".", inPath, body, parents)...)
+				continue
+			}
+			switch name {
+			case "google.protobuf.Any", "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue", "google.protobuf.Empty":
+				continue
+			}
+		}
+
+		params = append(params, map[string]interface{}{
+			"name":   fieldPath,
+			"in":     "query",
+			"schema": g.fieldSchema(f),
+		})
+	}
+	return params
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package grpcdynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/builder"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// httpOptions returns method options with the given google.api.http rule.
+func httpOptions(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, rule)
+	return opts
+}
+
+// buildOpenAPITestService returns a Query service whose methods cover the kinds of routes OpenAPI distinguishes.
+func buildOpenAPITestService(t *testing.T) *desc.ServiceDescriptor {
+	t.Helper()
+
+	pageReq := builder.NewMessage("PageRequest").
+		AddField(builder.NewField("key", builder.FieldTypeBytes())).
+		AddField(builder.NewField("limit", builder.FieldTypeUInt64()))
+	coin := builder.NewMessage("Coin").
+		AddField(builder.NewField("denom", builder.FieldTypeString())).
+		AddField(builder.NewField("amount", builder.FieldTypeString()))
+	balanceReq := builder.NewMessage("QueryBalanceRequest").
+		AddField(builder.NewField("address", builder.FieldTypeString())).
+		AddField(builder.NewField("denom", builder.FieldTypeString())).
+		AddField(builder.NewField("pagination", builder.FieldTypeMessage(pageReq)))
+	balanceRes := builder.NewMessage("QueryBalanceResponse").
+		AddField(builder.NewField("balance", builder.FieldTypeMessage(coin)))
+	tree := builder.NewMessage("Tree")
+	tree.
+		AddField(builder.NewField("name", builder.FieldTypeString())).
+		AddField(builder.NewField("parent", builder.FieldTypeMessage(tree)))
+	treeReq := builder.NewMessage("QueryTreeRequest").
+		AddField(builder.NewField("root", builder.FieldTypeMessage(tree))).
+		AddField(builder.NewMapField("labels", builder.FieldTypeString(), builder.FieldTypeString()))
+
+	svc := builder.NewService("Query").
+		AddMethod(builder.NewMethod("Balance", builder.RpcTypeMessage(balanceReq, false), builder.RpcTypeMessage(balanceRes, false)).
+			SetOptions(httpOptions(&annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/test/bank/v1/balances/{address}"},
+				AdditionalBindings: []*annotations.HttpRule{{
+					Pattern: &annotations.HttpRule_Get{Get: "/test/bank/v1/balances/{address}/by_denom/{denom=**}"},
+				}},
+			}))).
+		AddMethod(builder.NewMethod("Simulate", builder.RpcTypeMessage(balanceReq, false), builder.RpcTypeMessage(balanceRes, false)).
+			SetOptions(httpOptions(&annotations.HttpRule{
+				Pattern:      &annotations.HttpRule_Post{Post: "/test/bank/v1/simulate"},
+				Body:         "*",
+				ResponseBody: "balance",
+			}))).
+		AddMethod(builder.NewMethod("Tree", builder.RpcTypeMessage(treeReq, false), builder.RpcTypeMessage(tree, false)).
+			SetOptions(httpOptions(&annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/test/bank/v1/tree"},
+			}))).
+		AddMethod(builder.NewMethod("Unrouted", builder.RpcTypeMessage(balanceReq, false), builder.RpcTypeMessage(balanceRes, false))).
+		AddMethod(builder.NewMethod("BadPath", builder.RpcTypeMessage(balanceReq, false), builder.RpcTypeMessage(balanceRes, false)).
+			SetOptions(httpOptions(&annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/test/bank/v1/bad/{nope}"},
+			})))
+
+	fd, err := builder.NewFile("test/bank.proto").
+		SetPackageName("test.bank.v1").
+		SetProto3(true).
+		AddMessage(pageReq).
+		AddMessage(coin).
+		AddMessage(balanceReq).
+		AddMessage(balanceRes).
+		AddMessage(tree).
+		AddMessage(treeReq).
+		AddService(svc).
+		Build()
+	require.NoError(t, err)
+	return fd.FindService("test.bank.v1.Query")
+}
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	svcDesc := buildOpenAPITestService(t)
+	doc, skipped := grpcdynamic.OpenAPI("test REST API", "test-1", []*desc.ServiceDescriptor{svcDesc})
+
+	require.Equal(t, map[string]string{
+		"test.bank.v1.Query.Unrouted": "no google.api.http option",
+		"test.bank.v1.Query.BadPath":  `path parameter "nope" is not a field of test.bank.v1.QueryBalanceRequest`,
+	}, skipped)
+
+	// Inspect the document as serialized.
+	j, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var got struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Info       map[string]string                            `json:"info"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(j, &got))
+
+	require.Equal(t, "3.1.0", got.OpenAPI)
+	require.Equal(t, map[string]string{"title": "test REST API", "version": "test-1"}, got.Info)
+	require.ElementsMatch(t, []string{
+		"/test/bank/v1/balances/{address}",
+		"/test/bank/v1/balances/{address}/by_denom/{denom}",
+		"/test/bank/v1/simulate",
+		"/test/bank/v1/tree",
+	}, keys(got.Paths))
+
+	balance := got.Paths["/test/bank/v1/balances/{address}"]["get"]
+	require.Equal(t, "test.bank.v1.Query.Balance", balance["operationId"])
+	require.JSONEq(t, `[
+  {"name": "address", "in": "path", "required": true, "schema": {"type": "string"}},
+  {"name": "denom", "in": "query", "schema": {"type": "string"}},
+  {"name": "pagination.key", "in": "query", "schema": {"type": "string", "contentEncoding": "base64"}},
+  {"name": "pagination.limit", "in": "query", "schema": {"type": "string", "format": "uint64", "pattern": "^[0-9]+$"}}
+]`, mustJSON(t, balance["parameters"]))
+	require.JSONEq(t,
+		`{"$ref": "#/components/schemas/test.bank.v1.QueryBalanceResponse"}`,
+		mustJSON(t, balance["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"]),
+	)
+
+	// The additional binding has its own operation, with the denom bound to the path.
+	byDenom := got.Paths["/test/bank/v1/balances/{address}/by_denom/{denom}"]["get"]
+	require.Equal(t, "test.bank.v1.Query.Balance_1", byDenom["operationId"])
+	require.Len(t, byDenom["parameters"], 4)
+
+	// A body of * leaves no query parameters, and the response body selects a field.
+	simulate := got.Paths["/test/bank/v1/simulate"]["post"]
+	require.NotContains(t, simulate, "parameters")
+	require.JSONEq(t, `{
+  "required": true,
+  "content": {"application/json": {"schema": {"$ref": "#/components/schemas/test.bank.v1.QueryBalanceRequest"}}}
+}`, mustJSON(t, simulate["requestBody"]))
+	require.JSONEq(t,
+		`{"$ref": "#/components/schemas/test.bank.v1.Coin"}`,
+		mustJSON(t, simulate["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"]),
+	)
+
+	// Recursive messages are flattened once, and maps are not query parameters.
+	tree := got.Paths["/test/bank/v1/tree"]["get"]
+	require.JSONEq(t, `[{"name": "root.name", "in": "query", "schema": {"type": "string"}}]`, mustJSON(t, tree["parameters"]))
+
+	require.Contains(t, got.Components.Schemas, "test.bank.v1.Tree")
+	require.Contains(t, got.Components.Schemas, "google.rpc.Status")
+	require.Contains(t, got.Components.Schemas, "google.protobuf.Any")
+}
+
+func keys(m map[string]map[string]map[string]interface{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	j, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(j)
+}
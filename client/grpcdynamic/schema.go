@@ -0,0 +1,208 @@
+package grpcdynamic
+
+import (
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// JSON Schema drafts that JSONSchema can generate documents for.
+const (
+	JSONSchemaDraft2020_12 = "2020-12"
+	JSONSchemaDraft07      = "07"
+)
+
+// JSONSchema returns a JSON Schema document, in the given draft, describing the proto3 JSON
+// representation of the messages of type msgDesc.
+//
+// Each message type is defined once, keyed by its fully qualified name, under $defs
+// (or definitions, in draft 07), and referenced with $ref, so that recursive messages are valid.
+// 64-bit integers are strings, as in proto3 JSON; enums are strings of their value names;
+// repeated fields are arrays; maps are objects whose additionalProperties are the values;
+// and google.protobuf.Any is an object with an @type and the fields of the packed message.
+// Other well-known types have their special JSON representations.
+func JSONSchema(msgDesc *desc.MessageDescriptor, draft string) (map[string]interface{}, error) {
+	var schemaURI, defsKey string
+	switch draft {
+	case JSONSchemaDraft2020_12:
+		schemaURI, defsKey = "https://json-schema.org/draft/2020-12/schema", "$defs"
+	case JSONSchemaDraft07:
+		schemaURI, defsKey = "http://json-schema.org/draft-07/schema#", "definitions"
+	default:
+		return nil, fmt.Errorf("unsupported JSON Schema draft %q (must be %s or %s)", draft, JSONSchemaDraft2020_12, JSONSchemaDraft07)
+	}
+
+	g := newSchemaGenerator("#/" + defsKey + "/")
+	ref := g.messageRef(msgDesc)
+
+	return map[string]interface{}{
+		"$schema": schemaURI,
+		"title":   msgDesc.GetFullyQualifiedName(),
+		"$ref":    ref["$ref"],
+		defsKey:   g.defs,
+	}, nil
+}
+
+// schemaGenerator accumulates the definitions of the messages of a JSON Schema document.
+type schemaGenerator struct {
+	// refPrefix is the location of the definitions in the document, such as #/$defs/.
+	refPrefix string
+	defs      map[string]interface{}
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{
+		refPrefix: refPrefix,
+		defs:      make(map[string]interface{}),
+	}
+}
+
+// messageRef returns a reference to the definition of msgDesc, defining it first if necessary.
+func (g *schemaGenerator) messageRef(msgDesc *desc.MessageDescriptor) map[string]interface{} {
+	name := msgDesc.GetFullyQualifiedName()
+	ref := map[string]interface{}{"$ref": g.refPrefix + name}
+	if _, ok := g.defs[name]; ok {
+		return ref
+	}
+
+	if s, ok := wellKnownSchema(name); ok {
+		g.defs[name] = s
+		return ref
+	}
+
+	// Define the message before its fields, which may refer back to it.
+	def := map[string]interface{}{"type": "object"}
+	g.defs[name] = def
+
+	props := make(map[string]interface{}, len(msgDesc.GetFields()))
+	var required []string
+	for _, f := range msgDesc.GetFields() {
+		props[f.GetJSONName()] = g.fieldSchema(f)
+		if f.IsRequired() {
+			required = append(required, f.GetJSONName())
+		}
+	}
+	def["properties"] = props
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	return ref
+}
+
+// fieldSchema returns the schema of the JSON value of f.
+func (g *schemaGenerator) fieldSchema(f *desc.FieldDescriptor) map[string]interface{} {
+	switch {
+	case f.IsMap():
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.singularSchema(f.GetMapValueType()),
+		}
+	case f.IsRepeated():
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.singularSchema(f),
+		}
+	default:
+		return g.singularSchema(f)
+	}
+}
+
+// singularSchema returns the schema of a single value of f, ignoring whether it is repeated.
+func (g *schemaGenerator) singularSchema(f *desc.FieldDescriptor) map[string]interface{} {
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return g.messageRef(f.GetMessageType())
+
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		values := f.GetEnumType().GetValues()
+		names := make([]string, len(values))
+		for i, v := range values {
+			names[i] = v.GetName()
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+
+	default:
+		return scalarSchema(f.GetType())
+	}
+}
+
+// scalarSchema returns the schema of the proto3 JSON value of a scalar type.
+func scalarSchema(t descriptorpb.FieldDescriptorProto_Type) map[string]interface{} {
+	switch t {
+	case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		return map[string]interface{}{"type": "integer", "format": "uint32", "minimum": 0}
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return map[string]interface{}{"type": "string", "format": "int64", "pattern": "^-?[0-9]+$"}
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		return map[string]interface{}{"type": "string", "format": "uint64", "pattern": "^[0-9]+$"}
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return map[string]interface{}{"type": "boolean"}
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// wellKnownSchema returns the schema of the well-known type with the given name,
+// whose proto3 JSON representation differs from that of ordinary messages.
+func wellKnownSchema(name string) (map[string]interface{}, bool) {
+	switch name {
+	case "google.protobuf.Any":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"@type": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"@type"},
+		}, true
+	case "google.protobuf.Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}, true
+	case "google.protobuf.Duration":
+		return map[string]interface{}{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}, true
+	case "google.protobuf.FieldMask":
+		return map[string]interface{}{"type": "string"}, true
+	case "google.protobuf.Struct":
+		return map[string]interface{}{"type": "object"}, true
+	case "google.protobuf.ListValue":
+		return map[string]interface{}{"type": "array"}, true
+	case "google.protobuf.Value":
+		// Any JSON value.
+		return map[string]interface{}{}, true
+	case "google.protobuf.Empty":
+		return map[string]interface{}{"type": "object"}, true
+	case "google.protobuf.DoubleValue":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_DOUBLE), true
+	case "google.protobuf.FloatValue":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_FLOAT), true
+	case "google.protobuf.Int64Value":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_INT64), true
+	case "google.protobuf.UInt64Value":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_UINT64), true
+	case "google.protobuf.Int32Value":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_INT32), true
+	case "google.protobuf.UInt32Value":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_UINT32), true
+	case "google.protobuf.BoolValue":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_BOOL), true
+	case "google.protobuf.StringValue":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_STRING), true
+	case "google.protobuf.BytesValue":
+		return scalarSchema(descriptorpb.FieldDescriptorProto_TYPE_BYTES), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,113 @@
+package grpcdynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/builder"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// buildSchemaTestMessage returns a message with every kind of field that JSONSchema distinguishes,
+// including a field of its own type.
+func buildSchemaTestMessage(t *testing.T) *desc.MessageDescriptor {
+	t.Helper()
+
+	anyDesc, err := desc.LoadMessageDescriptorForMessage(&anypb.Any{})
+	require.NoError(t, err)
+
+	status := builder.NewEnum("Status").
+		AddValue(builder.NewEnumValue("STATUS_UNSPECIFIED")).
+		AddValue(builder.NewEnumValue("STATUS_BONDED"))
+
+	node := builder.NewMessage("Node")
+	node.
+		AddField(builder.NewField("amount", builder.FieldTypeUInt64())).
+		AddField(builder.NewField("height", builder.FieldTypeInt32())).
+		AddField(builder.NewField("memo_bytes", builder.FieldTypeBytes())).
+		AddField(builder.NewField("status", builder.FieldTypeEnum(status))).
+		AddField(builder.NewField("tags", builder.FieldTypeString()).SetRepeated()).
+		AddField(builder.NewMapField("labels", builder.FieldTypeString(), builder.FieldTypeInt64())).
+		AddField(builder.NewField("children", builder.FieldTypeMessage(node)).SetRepeated()).
+		AddField(builder.NewField("payload", builder.FieldTypeImportedMessage(anyDesc)))
+
+	msgDesc, err := builder.NewFile("test/schema.proto").
+		SetPackageName("test.v1").
+		SetProto3(true).
+		AddEnum(status).
+		AddMessage(node).
+		Build()
+	require.NoError(t, err)
+	return msgDesc.FindMessage("test.v1.Node")
+}
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	msgDesc := buildSchemaTestMessage(t)
+
+	schema, err := grpcdynamic.JSONSchema(msgDesc, grpcdynamic.JSONSchemaDraft2020_12)
+	require.NoError(t, err)
+
+	// Compare as JSON, for the same types as in the serialized document.
+	j, err := json.Marshal(schema)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "test.v1.Node",
+  "$ref": "#/$defs/test.v1.Node",
+  "$defs": {
+    "test.v1.Node": {
+      "type": "object",
+      "properties": {
+        "amount": {"type": "string", "format": "uint64", "pattern": "^[0-9]+$"},
+        "height": {"type": "integer", "format": "int32"},
+        "memoBytes": {"type": "string", "contentEncoding": "base64"},
+        "status": {"type": "string", "enum": ["STATUS_UNSPECIFIED", "STATUS_BONDED"]},
+        "tags": {"type": "array", "items": {"type": "string"}},
+        "labels": {
+          "type": "object",
+          "additionalProperties": {"type": "string", "format": "int64", "pattern": "^-?[0-9]+$"}
+        },
+        "children": {"type": "array", "items": {"$ref": "#/$defs/test.v1.Node"}},
+        "payload": {"$ref": "#/$defs/google.protobuf.Any"}
+      }
+    },
+    "google.protobuf.Any": {
+      "type": "object",
+      "properties": {"@type": {"type": "string"}},
+      "required": ["@type"]
+    }
+  }
+}`, string(j))
+
+	// Draft 07 keeps the definitions under a different keyword.
+	schema, err = grpcdynamic.JSONSchema(msgDesc, grpcdynamic.JSONSchemaDraft07)
+	require.NoError(t, err)
+	require.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	require.Equal(t, "#/definitions/test.v1.Node", schema["$ref"])
+	require.Contains(t, schema["definitions"], "test.v1.Node")
+	require.NotContains(t, schema, "$defs")
+
+	_, err = grpcdynamic.JSONSchema(msgDesc, "04")
+	require.ErrorContains(t, err, `unsupported JSON Schema draft "04"`)
+}
+
+func TestJSONSchema_Required(t *testing.T) {
+	t.Parallel()
+
+	msg := builder.NewMessage("Legacy").
+		AddField(builder.NewField("id", builder.FieldTypeString()).SetLabel(descriptorpb.FieldDescriptorProto_LABEL_REQUIRED)).
+		AddField(builder.NewField("note", builder.FieldTypeString()))
+	fd, err := builder.NewFile("test/legacy.proto").SetPackageName("test.v1").AddMessage(msg).Build()
+	require.NoError(t, err)
+
+	schema, err := grpcdynamic.JSONSchema(fd.FindMessage("test.v1.Legacy"), grpcdynamic.JSONSchemaDraft2020_12)
+	require.NoError(t, err)
+	def := schema["$defs"].(map[string]interface{})["test.v1.Legacy"].(map[string]interface{})
+	require.Equal(t, []string{"id"}, def["required"])
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// SentPacket is an IBC packet sent by a transaction, as described by its send_packet event.
+type SentPacket struct {
+	SourcePort    string
+	SourceChannel string
+	Sequence      uint64
+
+	// TimeoutTimestamp is the time after which the packet times out, or zero if it has no timeout timestamp.
+	TimeoutTimestamp time.Time
+}
+
+func (p SentPacket) String() string {
+	return fmt.Sprintf("%s/%s/%d", p.SourcePort, p.SourceChannel, p.Sequence)
+}
+
+// SentPackets returns the packets sent by the transaction of res, from its send_packet events.
+func SentPackets(res *sdk.TxResponse) ([]SentPacket, error) {
+	var packets []SentPacket
+	for _, event := range res.Events {
+		if event.Type != channeltypes.EventTypeSendPacket {
+			continue
+		}
+		var p SentPacket
+		for _, attr := range event.Attributes {
+			switch attr.Key {
+			case channeltypes.AttributeKeySrcPort:
+				p.SourcePort = attr.Value
+			case channeltypes.AttributeKeySrcChannel:
+				p.SourceChannel = attr.Value
+			case channeltypes.AttributeKeySequence:
+				seq, err := strconv.ParseUint(attr.Value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid packet sequence %q: %w", attr.Value, err)
+				}
+				p.Sequence = seq
+			case channeltypes.AttributeKeyTimeoutTimestamp:
+				ns, err := strconv.ParseUint(attr.Value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid packet timeout timestamp %q: %w", attr.Value, err)
+				}
+				if ns > 0 {
+					p.TimeoutTimestamp = time.Unix(0, int64(ns)).UTC()
+				}
+			}
+		}
+		packets = append(packets, p)
+	}
+	return packets, nil
+}
+
+// PacketAcknowledgement is the acknowledgement of a packet, as relayed back to the chain that sent it.
+type PacketAcknowledgement struct {
+	// TxHash and Height are the hash and height of the transaction relaying the acknowledgement.
+	TxHash string
+	Height int64
+
+	// Acknowledgement is the acknowledgement written by the receiving chain,
+	// the JSON of a channeltypes.Acknowledgement for the applications of ibc-go.
+	Acknowledgement []byte
+}
+
+// PacketTimedOutError is returned by WaitForPacketAcknowledgement when the packet timed out instead of being acknowledged.
+type PacketTimedOutError struct {
+	Packet SentPacket
+
+	// TxHash and Height are the hash and height of the transaction relaying the timeout.
+	TxHash string
+	Height int64
+}
+
+func (e PacketTimedOutError) Error() string {
+	return fmt.Sprintf("packet %s timed out, as relayed by transaction %s at height %d", e.Packet, e.TxHash, e.Height)
+}
+
+// WaitForPacketAcknowledgement polls every interval for the transaction relaying the acknowledgement of packet
+// back to the chain of cc, until it is found, until the packet is relayed as timed out, or until ctx is done.
+// The chain must index the events of its transactions.
+func (cc *ChainClient) WaitForPacketAcknowledgement(ctx context.Context, packet SentPacket, interval time.Duration) (PacketAcknowledgement, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ack, found, err := cc.findPacketAcknowledgement(ctx, packet)
+		if err != nil || found {
+			return ack, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return PacketAcknowledgement{}, InterruptedError{
+				Op:  fmt.Sprintf("waiting for the acknowledgement of packet %s", packet),
+				Err: ctx.Err(),
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// packetQuery returns the query of the transactions with an event of the given type for packet.
+func packetQuery(eventType string, packet SentPacket) string {
+	return fmt.Sprintf("%[1]s.%[2]s='%[3]s' AND %[1]s.%[4]s='%[5]s' AND %[1]s.%[6]s=%[7]d",
+		eventType,
+		channeltypes.AttributeKeySrcPort, packet.SourcePort,
+		channeltypes.AttributeKeySrcChannel, packet.SourceChannel,
+		channeltypes.AttributeKeySequence, packet.Sequence,
+	)
+}
+
+// findPacketAcknowledgement returns the acknowledgement of packet, if a transaction relaying it was included,
+// or a PacketTimedOutError if a transaction relaying its timeout was.
+func (cc *ChainClient) findPacketAcknowledgement(ctx context.Context, packet SentPacket) (PacketAcknowledgement, bool, error) {
+	res, err := cc.SearchTxs(ctx, packetQuery(channeltypes.EventTypeAcknowledgePacket, packet), 1, 1)
+	if err != nil {
+		return PacketAcknowledgement{}, false, fmt.Errorf("failed to search for the acknowledgement of packet %s: %w", packet, err)
+	}
+	for _, resTx := range res.Txs {
+		tx, err := cc.Codec.TxConfig.TxDecoder()(resTx.Tx)
+		if err != nil {
+			return PacketAcknowledgement{}, false, fmt.Errorf("failed to decode transaction %s: %w", resTx.Hash, err)
+		}
+		for _, msg := range tx.GetMsgs() {
+			ack, ok := msg.(*channeltypes.MsgAcknowledgement)
+			if !ok || ack.Packet.SourcePort != packet.SourcePort || ack.Packet.SourceChannel != packet.SourceChannel || ack.Packet.Sequence != packet.Sequence {
+				continue
+			}
+			return PacketAcknowledgement{
+				TxHash:          resTx.Hash.String(),
+				Height:          resTx.Height,
+				Acknowledgement: ack.Acknowledgement,
+			}, true, nil
+		}
+	}
+
+	res, err = cc.SearchTxs(ctx, packetQuery(channeltypes.EventTypeTimeoutPacket, packet), 1, 1)
+	if err != nil {
+		return PacketAcknowledgement{}, false, fmt.Errorf("failed to search for the timeout of packet %s: %w", packet, err)
+	}
+	if len(res.Txs) > 0 {
+		return PacketAcknowledgement{}, false, PacketTimedOutError{Packet: packet, TxHash: res.Txs[0].Hash.String(), Height: res.Txs[0].Height}
+	}
+	return PacketAcknowledgement{}, false, nil
+}
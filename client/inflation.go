@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	"go.uber.org/zap"
+)
+
+// Inflation is the rate at which a chain mints new tokens, as answered by an InflationProvider.
+type Inflation struct {
+	// Source is the path of the query answering the inflation.
+	Source string
+
+	// Denom is the denom minted.
+	Denom string
+
+	// Rate is the annual inflation: the tokens minted in a year, as a fraction of the supply.
+	Rate sdk.Dec
+
+	// AnnualProvisions is the amount of Denom minted in a year.
+	AnnualProvisions sdk.Dec
+
+	// StakingShare is the fraction of the tokens minted distributed to stakers,
+	// 1 unless the mint module sets part of them aside for other uses.
+	StakingShare sdk.Dec
+}
+
+// InflationProvider queries the inflation of the chains with a given mint module.
+type InflationProvider struct {
+	// Name names the mint module, such as "cosmos-sdk mint".
+	Name string
+
+	// Service is the fully qualified name of the Query service of the module,
+	// by which QueryInflation detects the chains that have it.
+	Service string
+
+	// Query returns the inflation of the chain at height, or 0 for the latest height.
+	Query func(ctx context.Context, cc *ChainClient, height int64) (Inflation, error)
+}
+
+// InflationProviders are the providers known to QueryInflation, in order of preference.
+var InflationProviders = []InflationProvider{
+	{Name: "cosmos-sdk mint", Service: "cosmos.mint.v1beta1.Query", Query: sdkMintInflation},
+	{Name: "osmosis mint", Service: "osmosis.mint.v1beta1.Query", Query: osmosisMintInflation},
+}
+
+// NoInflationProviderError is returned by QueryInflation when the chain has none of the mint modules of InflationProviders.
+type NoInflationProviderError struct {
+	ChainID string
+	Tried   []string
+}
+
+func (e NoInflationProviderError) Error() string {
+	return fmt.Sprintf("chain %s has none of the known mint modules (%s)", e.ChainID, strings.Join(e.Tried, ", "))
+}
+
+// QueryInflation returns the inflation of the chain at height, or 0 for the latest height,
+// from the first of InflationProviders whose Query service is among services,
+// the services of the chain as listed by gRPC reflection.
+// If the chain has none of their mint modules, the error is a NoInflationProviderError.
+func (cc *ChainClient) QueryInflation(ctx context.Context, height int64, services []string) (Inflation, error) {
+	tried := make([]string, 0, len(InflationProviders))
+	for _, p := range InflationProviders {
+		if !hasService(services, p.Service) {
+			cc.log.Debug("Mint module not available", zap.String("module", p.Name), zap.String("service", p.Service))
+			tried = append(tried, p.Name)
+			continue
+		}
+		inflation, err := p.Query(ctx, cc, height)
+		if err != nil {
+			return Inflation{}, fmt.Errorf("failed to query the inflation of the %s module: %w", p.Name, err)
+		}
+		return inflation, nil
+	}
+	return Inflation{}, NoInflationProviderError{ChainID: cc.Config.ChainID, Tried: tried}
+}
+
+// hasService reports whether services includes service.
+func hasService(services []string, service string) bool {
+	for _, s := range services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// sdkMintInflation returns the inflation of the mint module of the Cosmos SDK,
+// which answers the inflation and the annual provisions.
+func sdkMintInflation(ctx context.Context, cc *ChainClient, height int64) (Inflation, error) {
+	var params minttypes.QueryParamsResponse
+	if err := cc.queryMessage(ctx, "/cosmos.mint.v1beta1.Query/Params", height, &minttypes.QueryParamsRequest{}, &params); err != nil {
+		return Inflation{}, err
+	}
+	var inflation minttypes.QueryInflationResponse
+	if err := cc.queryMessage(ctx, "/cosmos.mint.v1beta1.Query/Inflation", height, &minttypes.QueryInflationRequest{}, &inflation); err != nil {
+		return Inflation{}, err
+	}
+	var provisions minttypes.QueryAnnualProvisionsResponse
+	if err := cc.queryMessage(ctx, "/cosmos.mint.v1beta1.Query/AnnualProvisions", height, &minttypes.QueryAnnualProvisionsRequest{}, &provisions); err != nil {
+		return Inflation{}, err
+	}
+	return Inflation{
+		Source:           "/cosmos.mint.v1beta1.Query/Inflation",
+		Denom:            params.Params.MintDenom,
+		Rate:             inflation.Inflation,
+		AnnualProvisions: provisions.AnnualProvisions,
+		StakingShare:     sdk.OneDec(),
+	}, nil
+}
+
+// queryMessage runs the query at path, with the request and response encoded by their own methods.
+// The gRPC codec of Invoke cannot decode the responses of the mint module, whose fields have custom types.
+func (cc *ChainClient) queryMessage(
+	ctx context.Context,
+	path string,
+	height int64,
+	req interface{ Marshal() ([]byte, error) },
+	res interface{ Unmarshal([]byte) error },
+) error {
+	bz, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	abciRes, err := cc.QueryABCI(ctx, abci.RequestQuery{Path: path, Data: bz, Height: height})
+	if err != nil {
+		return err
+	}
+	return res.Unmarshal(abciRes.Value)
+}
+
+// osmosisEpochsPerYear are the number of epochs in a year, by the epoch identifiers of the Osmosis mint module.
+var osmosisEpochsPerYear = map[string]int64{
+	"hour": 24 * 365,
+	"day":  365,
+	"week": 52,
+}
+
+// osmosisMintInflation returns the inflation of the Osmosis mint module, which mints the same provisions every epoch,
+// and distributes a share of them to stakers.
+// The responses are only decoded as far as needed, so that the module's types need not be known to the codec.
+func osmosisMintInflation(ctx context.Context, cc *ChainClient, height int64) (Inflation, error) {
+	const (
+		paramsPath          = "/osmosis.mint.v1beta1.Query/Params"
+		epochProvisionsPath = "/osmosis.mint.v1beta1.Query/EpochProvisions"
+	)
+
+	// QueryParamsResponse{params: Params{mint_denom: string, epoch_identifier: string,
+	// distribution_proportions: DistributionProportions{staking: Dec}}}.
+	res, err := cc.QueryABCI(ctx, abci.RequestQuery{Path: paramsPath, Height: height})
+	if err != nil {
+		return Inflation{}, err
+	}
+	params, err := protoBytesField(res.Value, 1)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode %s: %w", paramsPath, err)
+	}
+	denom, err := protoBytesField(params, 1)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode the mint denom: %w", err)
+	}
+	epoch, err := protoBytesField(params, 3)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode the epoch identifier: %w", err)
+	}
+	epochs, ok := osmosisEpochsPerYear[string(epoch)]
+	if !ok {
+		return Inflation{}, fmt.Errorf("unknown epoch identifier %q", epoch)
+	}
+	proportions, err := protoBytesField(params, 6)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode the distribution proportions: %w", err)
+	}
+	var share sdk.Dec
+	bz, err := protoBytesField(proportions, 1)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode the staking proportion: %w", err)
+	}
+	if err := share.Unmarshal(bz); err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode the staking proportion: %w", err)
+	}
+
+	// QueryEpochProvisionsResponse{epoch_provisions: Dec}.
+	res, err = cc.QueryABCI(ctx, abci.RequestQuery{Path: epochProvisionsPath, Height: height})
+	if err != nil {
+		return Inflation{}, err
+	}
+	var provisions sdk.Dec
+	bz, err = protoBytesField(res.Value, 1)
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode %s: %w", epochProvisionsPath, err)
+	}
+	if err := provisions.Unmarshal(bz); err != nil {
+		return Inflation{}, fmt.Errorf("failed to decode %s: %w", epochProvisionsPath, err)
+	}
+	annual := provisions.MulInt64(epochs)
+
+	supply, err := banktypes.NewQueryClient(cc).SupplyOf(SetHeightOnContext(ctx, height), &banktypes.QuerySupplyOfRequest{Denom: string(denom)})
+	if err != nil {
+		return Inflation{}, fmt.Errorf("failed to query the supply of %s: %w", denom, err)
+	}
+	if !supply.Amount.Amount.IsPositive() {
+		return Inflation{}, fmt.Errorf("the supply of %s is zero", denom)
+	}
+
+	return Inflation{
+		Source:           epochProvisionsPath,
+		Denom:            string(denom),
+		Rate:             annual.QuoInt(supply.Amount.Amount),
+		AnnualProvisions: annual,
+		StakingShare:     share,
+	}, nil
+}
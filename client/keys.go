@@ -1,12 +1,24 @@
 package client
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/go-bip39"
 	"github.com/strangelove-ventures/lens/client/codecs/ethermint"
 	"github.com/strangelove-ventures/lens/client/codecs/injective"
@@ -50,6 +62,29 @@ func (cc *ChainClient) KeystoreCreated(path string) bool {
 	return true
 }
 
+// OtherKeyringBackends returns the keyring backends, other than the chain's, with keys in the chain's key directory,
+// such as keys added before the chain's backend was changed.
+// Only the file and test backends store their keys there.
+func (cc *ChainClient) OtherKeyringBackends() []string {
+	var backends []string
+	for _, backend := range []string{keyring.BackendFile, keyring.BackendTest} {
+		if backend == cc.Config.KeyringBackend {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(cc.Config.KeyDirectory, "keyring-"+backend))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".info") {
+				backends = append(backends, backend)
+				break
+			}
+		}
+	}
+	return backends
+}
+
 func (cc *ChainClient) AddKey(name string, coinType uint32) (output *KeyOutput, err error) {
 	ko, err := cc.KeyAddOrRestore(name, coinType)
 	if err != nil {
@@ -73,7 +108,7 @@ func (cc *ChainClient) ShowAddress(name string) (address string, err error) {
 	}
 	acc, err := info.GetAddress()
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 	out, err := cc.EncodeBech32AccAddr(acc)
 	if err != nil {
@@ -119,54 +154,258 @@ func (cc *ChainClient) KeyExists(name string) bool {
 
 }
 
+// AddMultisigKey stores a multisig key of the given name, of the public keys of the named keys,
+// which threshold of them must sign for, and returns its address.
+// The public keys are sorted by address, so that the address does not depend on the order of the names.
+func (cc *ChainClient) AddMultisigKey(name string, threshold int, keyNames []string) (address string, err error) {
+	if threshold < 1 || threshold > len(keyNames) {
+		return "", fmt.Errorf("threshold %d must be between 1 and the number of keys, %d", threshold, len(keyNames))
+	}
+	seen := make(map[string]bool, len(keyNames))
+	pks := make([]cryptotypes.PubKey, len(keyNames))
+	for i, keyName := range keyNames {
+		if seen[keyName] {
+			return "", fmt.Errorf("key %q is given more than once", keyName)
+		}
+		seen[keyName] = true
+		info, err := cc.Keybase.Key(keyName)
+		if err != nil {
+			return "", fmt.Errorf("key %q not found: %w", keyName, err)
+		}
+		if pks[i], err = info.GetPubKey(); err != nil {
+			return "", err
+		}
+	}
+	sort.Slice(pks, func(i, j int) bool {
+		return bytes.Compare(pks[i].Address(), pks[j].Address()) < 0
+	})
+
+	info, err := cc.Keybase.SaveMultisig(name, kmultisig.NewLegacyAminoPubKey(threshold, pks))
+	if err != nil {
+		return "", err
+	}
+	acc, err := info.GetAddress()
+	if err != nil {
+		return "", err
+	}
+	return cc.EncodeBech32AccAddr(acc)
+}
+
+// MultisigKey returns the public key of the named multisig key.
+func (cc *ChainClient) MultisigKey(name string) (*kmultisig.LegacyAminoPubKey, error) {
+	info, err := cc.Keybase.Key(name)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := info.GetPubKey()
+	if err != nil {
+		return nil, err
+	}
+	multisigPub, ok := pk.(*kmultisig.LegacyAminoPubKey)
+	if !ok {
+		return nil, fmt.Errorf("key %q is not a multisig key", name)
+	}
+	return multisigPub, nil
+}
+
 func (cc *ChainClient) ExportPrivKeyArmor(keyName string) (armor string, err error) {
 	return cc.Keybase.ExportPrivKeyArmor(keyName, ckeys.DefaultKeyPass)
 }
 
-func (cc *ChainClient) KeyAddOrRestore(keyName string, coinType uint32, mnemonic ...string) (*KeyOutput, error) {
-	var mnemonicStr string
-	var err error
-	algo := keyring.SignatureAlgo(hd.Secp256k1)
+// ExportPrivKeyArmorWithPassphrase returns the private key of the named key, ASCII armored and encrypted with passphrase.
+func (cc *ChainClient) ExportPrivKeyArmorWithPassphrase(keyName, passphrase string) (armor string, err error) {
+	return cc.Keybase.ExportPrivKeyArmor(keyName, passphrase)
+}
+
+// ExportPrivKeyHex returns the unencrypted private key of the named key, hex encoded.
+func (cc *ChainClient) ExportPrivKeyHex(keyName string) (string, error) {
+	// The keyring only exports armored keys, so export and decrypt one.
+	armor, err := cc.Keybase.ExportPrivKeyArmor(keyName, ckeys.DefaultKeyPass)
+	if err != nil {
+		return "", err
+	}
+	priv, _, err := crypto.UnarmorDecryptPrivKey(armor, ckeys.DefaultKeyPass)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(priv.Bytes()), nil
+}
 
+// KeyAddressExistsError is returned when importing a key whose address is already that of another key.
+type KeyAddressExistsError struct {
+	Name         string
+	Address      string
+	ExistingName string
+}
+
+func (e KeyAddressExistsError) Error() string {
+	return fmt.Sprintf("the key to import as %q has address %s, which is already the address of key %q", e.Name, e.Address, e.ExistingName)
+}
+
+// ImportPrivKeyArmor imports the ASCII armored private key, encrypted with passphrase, as the named key,
+// and returns its address.
+// Unless force is set, a key whose address is already that of another key is not imported.
+func (cc *ChainClient) ImportPrivKeyArmor(keyName, armor, passphrase string, force bool) (address string, err error) {
+	priv, _, err := crypto.UnarmorDecryptPrivKey(armor, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+	return cc.importPrivKey(keyName, priv, force)
+}
+
+// ImportPrivKeyHex imports the unencrypted hex encoded private key as the named key, and returns its address.
+// The algorithm of the key, secp256k1 or eth_secp256k1, is not encoded, so it must be given as keyType.
+// Unless force is set, a key whose address is already that of another key is not imported.
+func (cc *ChainClient) ImportPrivKeyHex(keyName, hexKey, keyType string, force bool) (address string, err error) {
+	bz, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid hex private key: %w", err)
+	}
+	var algo keyring.SignatureAlgo
+	switch hd.PubKeyType(keyType) {
+	case hd.Secp256k1Type:
+		algo = hd.Secp256k1
+	case ethermint.EthSecp256k1Type:
+		algo = cc.ethAlgo()
+	default:
+		return "", fmt.Errorf("unknown key type %q (must be %s or %s)", keyType, hd.Secp256k1Type, ethermint.EthSecp256k1Type)
+	}
+	if len(bz) != 32 {
+		return "", fmt.Errorf("invalid %s private key: it has %d bytes, but must have 32", keyType, len(bz))
+	}
+	return cc.importPrivKey(keyName, algo.Generate()(bz), force)
+}
+
+// importPrivKey stores priv as the named key, and returns its address.
+func (cc *ChainClient) importPrivKey(keyName string, priv cryptotypes.PrivKey, force bool) (string, error) {
+	address, err := cc.EncodeBech32AccAddr(sdk.AccAddress(priv.PubKey().Address()))
+	if err != nil {
+		return "", err
+	}
+	if !force {
+		if existing, err := cc.Keybase.KeyByAddress(sdk.AccAddress(priv.PubKey().Address())); err == nil && existing.Name != keyName {
+			return "", KeyAddressExistsError{Name: keyName, Address: address, ExistingName: existing.Name}
+		}
+	}
+	// The keyring only imports armored keys, so armor priv.
+	armor := crypto.EncryptArmorPrivKey(priv, ckeys.DefaultKeyPass, priv.Type())
+	if err := cc.Keybase.ImportPrivKey(keyName, armor, ckeys.DefaultKeyPass); err != nil {
+		return "", err
+	}
+	return address, nil
+}
+
+func (cc *ChainClient) KeyAddOrRestore(keyName string, coinType uint32, mnemonic ...string) (*KeyOutput, error) {
+	opts := KeyOptions{CoinType: coinType}
 	if len(mnemonic) > 0 {
-		mnemonicStr = mnemonic[0]
+		opts.Mnemonic = mnemonic[0]
+	}
+	return cc.AddKeyWithOptions(keyName, opts)
+}
+
+// KeyOptions are the options of AddKeyWithOptions.
+type KeyOptions struct {
+	// Mnemonic is the BIP39 mnemonic the key is derived from. A new one is created if it is empty.
+	Mnemonic string
+	// CoinType, Account and Index are the components of the HD path m/44'/CoinType'/Account'/0/Index of the key.
+	CoinType uint32
+	Account  uint32
+	Index    uint32
+	// DryRun derives the key without storing it.
+	DryRun bool
+}
+
+// AddKeyWithOptions derives the named key from the mnemonic of opts, or from a new mnemonic,
+// along the HD path of opts, and stores it unless opts.DryRun is set.
+// Keys of coin type 60 use the Ethereum signing algorithm of the chain's codecs.
+func (cc *ChainClient) AddKeyWithOptions(keyName string, opts KeyOptions) (*KeyOutput, error) {
+	mnemonicStr := opts.Mnemonic
+	if mnemonicStr == "" {
+		var err error
+		if mnemonicStr, err = CreateMnemonic(); err != nil {
+			return nil, err
+		}
 	} else {
-		mnemonicStr, err = CreateMnemonic()
-		if err != nil {
+		if err := ValidateMnemonic(mnemonicStr); err != nil {
 			return nil, err
 		}
+		mnemonicStr = strings.Join(strings.Fields(mnemonicStr), " ")
 	}
 
-	if coinType == 60 {
-		algo = keyring.SignatureAlgo(ethermint.EthSecp256k1)
-		for _, codec := range cc.Config.ExtraCodecs {
-			if codec == "injective" {
-				algo = keyring.SignatureAlgo(injective.EthSecp256k1)
-			}
+	algo := keyring.SignatureAlgo(hd.Secp256k1)
+	if opts.CoinType == 60 {
+		algo = cc.ethAlgo()
+	}
+	hdPath := hd.CreateHDPath(opts.CoinType, opts.Account, opts.Index).String()
+
+	var pk cryptotypes.PubKey
+	if opts.DryRun {
+		derived, err := algo.Derive()(mnemonicStr, "", hdPath)
+		if err != nil {
+			return nil, err
+		}
+		pk = algo.Generate()(derived).PubKey()
+	} else {
+		info, err := cc.Keybase.NewAccount(keyName, mnemonicStr, "", hdPath, algo)
+		if err != nil {
+			return nil, err
+		}
+		if pk, err = info.GetPubKey(); err != nil {
+			return nil, err
 		}
 	}
 
-	info, err := cc.Keybase.NewAccount(keyName, mnemonicStr, "", hd.CreateHDPath(coinType, 0, 0).String(), algo)
+	out, err := cc.EncodeBech32AccAddr(sdk.AccAddress(pk.Address()))
 	if err != nil {
 		return nil, err
 	}
-
-	acc, err := info.GetAddress()
+	// The public key is encoded with the global registry of types,
+	// since Ethereum keys are not registered with the codec of every chain.
+	anyPk, err := codectypes.NewAnyWithValue(pk)
 	if err != nil {
 		return nil, err
 	}
-
-	out, err := cc.EncodeBech32AccAddr(acc)
+	pubKey, err := codec.ProtoMarshalJSON(anyPk, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &KeyOutput{Mnemonic: mnemonicStr, Address: out}, nil
+	return &KeyOutput{Name: keyName, Address: out, PubKey: string(pubKey), Mnemonic: mnemonicStr}, nil
+}
+
+// ethAlgo returns the Ethereum signing algorithm of the chain's codecs.
+func (cc *ChainClient) ethAlgo() keyring.SignatureAlgo {
+	for _, codec := range cc.Config.ExtraCodecs {
+		if codec == "injective" {
+			return injective.EthSecp256k1
+		}
+	}
+	return ethermint.EthSecp256k1
+}
+
+// ValidateMnemonic returns an error stating what is wrong with mnemonic, if it is not a valid BIP39 mnemonic:
+// its word count, a word not in the English word list, or its checksum.
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	if n := len(words); n < 12 || n > 24 || n%3 != 0 {
+		return fmt.Errorf("invalid mnemonic: it has %d words, but must have 12, 15, 18, 21 or 24", n)
+	}
+	for i, word := range words {
+		if _, ok := bip39.ReverseWordMap[word]; !ok {
+			return fmt.Errorf("invalid mnemonic: word %d, %q, is not in the BIP39 English word list", i+1, word)
+		}
+	}
+	if _, err := bip39.MnemonicToByteArray(strings.Join(words, " ")); err != nil {
+		return fmt.Errorf("invalid mnemonic: its checksum is wrong, so a word is misspelled or out of order")
+	}
+	return nil
 }
 
-// KeyOutput contains mnemonic and address of key
+// KeyOutput contains the name, address, public key and mnemonic of a key
 type KeyOutput struct {
-	Mnemonic string `json:"mnemonic" yaml:"mnemonic"`
+	Name     string `json:"name" yaml:"name"`
 	Address  string `json:"address" yaml:"address"`
+	PubKey   string `json:"pubkey" yaml:"pubkey"`
+	Mnemonic string `json:"mnemonic,omitempty" yaml:"mnemonic,omitempty"`
 }
 
 // CreateMnemonic creates a new mnemonic
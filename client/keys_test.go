@@ -3,7 +3,13 @@ package client_test
 import (
 	"testing"
 
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -116,3 +122,58 @@ func TestKeyRestoreInj(t *testing.T) {
 		t.Fatalf("Error deleting key: %v", err)
 	}
 }
+
+// TestMultisign signs a transaction of a 2-of-3 multisig key, with keys in an in-memory keyring.
+func TestMultisign(t *testing.T) {
+	homepath := t.TempDir()
+	config := client.GetCosmosHubConfig(homepath, true)
+	config.KeyringBackend = keyring.BackendMemory
+	cl, err := client.NewChainClient(zaptest.NewLogger(t), config, homepath, nil, nil)
+	require.NoError(t, err)
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		_, err := cl.AddKey(name, 118)
+		require.NoError(t, err)
+	}
+	address, err := cl.AddMultisigKey("team", 2, []string{"alice", "bob", "carol"})
+	require.NoError(t, err)
+	shown, err := cl.ShowAddress("team")
+	require.NoError(t, err)
+	require.Equal(t, address, shown)
+
+	from, err := cl.DecodeBech32AccAddr(address)
+	require.NoError(t, err)
+	txb := cl.Codec.TxConfig.NewTxBuilder()
+	require.NoError(t, txb.SetMsgs(banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)))))
+	txb.SetGasLimit(200000)
+
+	var sigs []signing.SignatureV2
+	for _, name := range []string{"alice", "carol"} {
+		cl.Config.Key = name
+		sig, err := cl.SignMultisigPart(txb, "team", 7, 3)
+		require.NoError(t, err)
+		sigs = append(sigs, sig)
+
+		err = cl.MultisignTx(txb, "team", sigs, 7, 3)
+		if len(sigs) < 2 {
+			require.ErrorContains(t, err, "needs 1 more signature(s)")
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	multisigPub, err := cl.MultisigKey("team")
+	require.NoError(t, err)
+	txSigs, err := txb.GetTx().GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, txSigs, 1)
+	require.True(t, multisigPub.Equals(txSigs[0].PubKey))
+	signerData := authsigning.SignerData{
+		Address:       address,
+		ChainID:       config.ChainID,
+		AccountNumber: 7,
+		Sequence:      3,
+		PubKey:        multisigPub,
+	}
+	require.NoError(t, authsigning.VerifySignature(multisigPub, signerData, txSigs[0].Data, cl.Codec.TxConfig.SignModeHandler(), txb.GetTx()))
+}
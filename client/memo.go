@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"go.uber.org/zap"
+)
+
+// MemoVars are the variables of a memo template, as expanded by ExpandMemo.
+type MemoVars struct {
+	// ChainID is the ID of the chain the transaction is sent to.
+	ChainID string
+	// Timestamp is the time the transaction is built, written as an RFC 3339 time in UTC.
+	Timestamp MemoTime
+	// Hostname is the name of the host building the transaction.
+	Hostname string
+}
+
+// MemoTime is a time written as an RFC 3339 time in UTC by memo templates,
+// whose methods, such as Unix, remain available to them.
+type MemoTime struct {
+	time.Time
+}
+
+func (t MemoTime) String() string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ExpandMemo expands memo as a text/template of MemoVars, such as "sent by {{.Hostname}} at {{.Timestamp}}",
+// for a transaction built at now.
+func (cc *ChainClient) ExpandMemo(memo string, now time.Time) (string, error) {
+	if !strings.Contains(memo, "{{") {
+		return memo, nil
+	}
+	tmpl, err := template.New("memo").Parse(memo)
+	if err != nil {
+		return "", fmt.Errorf("invalid memo template %q: %w", memo, err)
+	}
+	// The hostname is left empty if it cannot be found, rather than failing the transaction.
+	hostname, _ := os.Hostname()
+	var b strings.Builder
+	if err := tmpl.Execute(&b, MemoVars{ChainID: cc.Config.ChainID, Timestamp: MemoTime{now}, Hostname: hostname}); err != nil {
+		return "", fmt.Errorf("invalid memo template %q: %w", memo, err)
+	}
+	return b.String(), nil
+}
+
+// MemoTooLongError is the error of a memo longer than the chain's max_memo_characters auth param allows.
+type MemoTooLongError struct {
+	Length, Max uint64
+}
+
+func (e MemoTooLongError) Error() string {
+	return fmt.Sprintf("memo is %d characters long, more than the %d allowed by the chain's max_memo_characters auth param", e.Length, e.Max)
+}
+
+// memoLimit caches the max_memo_characters auth param of a chain, once queried.
+type memoLimit struct {
+	mu  sync.Mutex
+	max uint64
+}
+
+// checkMemoLength returns a MemoTooLongError if memo is longer than the chain allows.
+// The limit is queried once per client; if the query fails, the memo is not checked, and is left to the chain to check.
+func (cc *ChainClient) checkMemoLength(ctx context.Context, memo string) error {
+	if cc.memoLimit == nil {
+		return nil
+	}
+	cc.memoLimit.mu.Lock()
+	defer cc.memoLimit.mu.Unlock()
+
+	if cc.memoLimit.max == 0 {
+		res, err := authtypes.NewQueryClient(cc).Params(ctx, &authtypes.QueryParamsRequest{})
+		if err != nil {
+			cc.log.Debug("Failed to query the maximum memo length, not checking the memo", zap.Error(err))
+			return nil
+		}
+		cc.memoLimit.max = res.Params.MaxMemoCharacters
+	}
+	// As the chain counts them, the characters of the memo are its bytes.
+	if n := uint64(len(memo)); cc.memoLimit.max > 0 && n > cc.memoLimit.max {
+		return MemoTooLongError{Length: n, Max: cc.memoLimit.max}
+	}
+	return nil
+}
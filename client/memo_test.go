@@ -0,0 +1,38 @@
+package client_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandMemo(t *testing.T) {
+	t.Parallel()
+
+	cc := &client.ChainClient{Config: &client.ChainClientConfig{ChainID: "cosmoshub-4"}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	for memo, want := range map[string]string{
+		"":                             "",
+		"plain {memo}":                 "plain {memo}",
+		"{{.ChainID}}":                 "cosmoshub-4",
+		"at {{.Timestamp}}":            "at 2026-01-02T02:04:05Z",
+		"{{.Timestamp.Unix}}":          "1767319445",
+		"from {{.Hostname}}":           "from " + hostname,
+		`{{printf "%.6s" .ChainID}}-x`: "cosmos-x",
+	} {
+		got, err := cc.ExpandMemo(memo, now)
+		require.NoError(t, err, memo)
+		require.Equal(t, want, got, memo)
+	}
+
+	_, err = cc.ExpandMemo("{{.ChainID", now)
+	require.ErrorContains(t, err, `invalid memo template "{{.ChainID"`)
+	_, err = cc.ExpandMemo("{{.Height}}", now)
+	require.ErrorContains(t, err, "can't evaluate field Height")
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// metricsNamespace prefixes the names of the metrics recorded by Metrics.
+const metricsNamespace = "lens"
+
+// Metrics records the operations of chain clients as Prometheus metrics:
+// RPC requests, ABCI queries, transaction broadcasts and their gas used, sequence retries,
+// and the gRPC calls of connections dialed with GRPCDialOptions, such as those of gRPC reflection.
+//
+// A nil *Metrics records nothing, which is the default of clients created without WithMetrics.
+type Metrics struct {
+	rpcRequests     *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+	abciQueries     *prometheus.CounterVec
+	txBroadcasts    *prometheus.CounterVec
+	txGasUsed       *prometheus.HistogramVec
+	sequenceRetries *prometheus.CounterVec
+	grpcCalls       *prometheus.CounterVec
+	grpcDuration    *prometheus.HistogramVec
+}
+
+// NewMetrics returns Metrics registered with reg.
+// A registry holds the metrics of any number of chain clients, labeled by chain.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_requests_total",
+			Help:      "RPC requests, by chain, endpoint, JSON-RPC method, and HTTP status code or error.",
+		}, []string{"chain", "endpoint", "method", "status"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "rpc_request_duration_seconds",
+			Help:      "Duration of RPC requests, by chain, endpoint, and JSON-RPC method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"chain", "endpoint", "method"}),
+		abciQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "abci_queries_total",
+			Help:      "ABCI queries, by chain, query path, and gRPC status code.",
+		}, []string{"chain", "path", "status"}),
+		txBroadcasts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_broadcasts_total",
+			Help:      "Transaction broadcasts, by chain, result (success, failure, or error), and ABCI codespace and code.",
+		}, []string{"chain", "result", "codespace", "code"}),
+		txGasUsed: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_gas_used",
+			Help:      "Gas used by broadcast transactions that were executed, by chain.",
+			Buckets:   prometheus.ExponentialBuckets(25000, 2, 10),
+		}, []string{"chain"}),
+		sequenceRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_sequence_retries_total",
+			Help:      "Transactions signed again after being rejected for their account sequence, by chain.",
+		}, []string{"chain"}),
+		grpcCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_calls_total",
+			Help:      "gRPC calls, and opened gRPC streams such as those of server reflection, by endpoint, method, and status code.",
+		}, []string{"endpoint", "method", "status"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "grpc_call_duration_seconds",
+			Help:      "Duration of gRPC calls, and of opening gRPC streams, by endpoint and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "method"}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.rpcRequests, m.rpcDuration, m.abciQueries, m.txBroadcasts, m.txGasUsed, m.sequenceRetries, m.grpcCalls, m.grpcDuration,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// rpcTransport returns rt recording the RPC requests it sends to endpoint of chain,
+// or rt itself if m is nil.
+func (m *Metrics) rpcTransport(chain, endpoint string, rt http.RoundTripper) http.RoundTripper {
+	if m == nil {
+		return rt
+	}
+	return &metricsTransport{m: m, chain: chain, endpoint: endpoint, next: rt}
+}
+
+// metricsTransport is an http.RoundTripper recording the JSON-RPC requests sent through next.
+type metricsTransport struct {
+	m        *Metrics
+	chain    string
+	endpoint string
+	next     http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := rpcMethod(req)
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	t.m.rpcDuration.WithLabelValues(t.chain, t.endpoint, method).Observe(time.Since(start).Seconds())
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	t.m.rpcRequests.WithLabelValues(t.chain, t.endpoint, method, status).Inc()
+	return res, err
+}
+
+// rpcMethod returns the JSON-RPC method of req, "batch" for a batch of requests,
+// or "unknown" if its body cannot be read again.
+func rpcMethod(req *http.Request) string {
+	if req.GetBody == nil {
+		return errUnknown
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return errUnknown
+	}
+	defer body.Close()
+	bz, err := io.ReadAll(body)
+	if err != nil {
+		return errUnknown
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(bz), []byte("[")) {
+		return "batch"
+	}
+	var r struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(bz, &r); err != nil || r.Method == "" {
+		return errUnknown
+	}
+	return r.Method
+}
+
+// observeABCIQuery records an ABCI query of chain at path, which returned err.
+func (m *Metrics) observeABCIQuery(chain, path string, err error) {
+	if m == nil {
+		return
+	}
+	m.abciQueries.WithLabelValues(chain, path, status.Code(err).String()).Inc()
+}
+
+// observeBroadcast records the broadcast of a transaction of chain, which returned res and err,
+// and the gas it used if it was executed.
+func (m *Metrics) observeBroadcast(chain string, res *sdk.TxResponse, err error) {
+	if m == nil {
+		return
+	}
+	if err != nil || res == nil {
+		// A transaction failing CheckTx in BroadcastBlock mode is returned as its registered error.
+		var abciErr interface {
+			ABCICode() uint32
+			Codespace() string
+		}
+		if errors.As(err, &abciErr) {
+			m.txBroadcasts.WithLabelValues(chain, "failure", abciErr.Codespace(), strconv.FormatUint(uint64(abciErr.ABCICode()), 10)).Inc()
+			return
+		}
+		m.txBroadcasts.WithLabelValues(chain, "error", "", "").Inc()
+		return
+	}
+	result := "success"
+	if res.Code != 0 {
+		result = "failure"
+	}
+	m.txBroadcasts.WithLabelValues(chain, result, res.Codespace, strconv.FormatUint(uint64(res.Code), 10)).Inc()
+	if res.GasUsed > 0 {
+		m.txGasUsed.WithLabelValues(chain).Observe(float64(res.GasUsed))
+	}
+}
+
+// observeSequenceRetry records a transaction of chain signed again with the sequence the chain expects.
+func (m *Metrics) observeSequenceRetry(chain string) {
+	if m == nil {
+		return
+	}
+	m.sequenceRetries.WithLabelValues(chain).Inc()
+}
+
+// GRPCDialOptions returns the options making a gRPC connection record its calls and opened streams,
+// labeled with the connection's target, or nil if m is nil.
+func (m *Metrics) GRPCDialOptions() []grpc.DialOption {
+	if m == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			m.observeGRPCCall(cc.Target(), method, start, err)
+			return err
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			start := time.Now()
+			s, err := streamer(ctx, desc, cc, method, opts...)
+			m.observeGRPCCall(cc.Target(), method, start, err)
+			return s, err
+		}),
+	}
+}
+
+func (m *Metrics) observeGRPCCall(endpoint, method string, start time.Time, err error) {
+	m.grpcDuration.WithLabelValues(endpoint, method).Observe(time.Since(start).Seconds())
+	m.grpcCalls.WithLabelValues(endpoint, method, status.Code(err).String()).Inc()
+}
@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMetrics_RPCRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": {}}`, req.ID)
+	}))
+	t.Cleanup(srv.Close)
+
+	reg := prometheus.NewRegistry()
+	m, err := client.NewMetrics(reg)
+	require.NoError(t, err)
+
+	homepath := t.TempDir()
+	ccc := client.GetCosmosHubConfig(homepath, true)
+	ccc.RPCAddr = srv.URL
+	cl, err := client.NewChainClientWithOptions(zaptest.NewLogger(t), ccc, homepath, nil, nil, client.WithMetrics(m))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = cl.RPCClient.Health(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(fmt.Sprintf(`
+# HELP lens_rpc_requests_total RPC requests, by chain, endpoint, JSON-RPC method, and HTTP status code or error.
+# TYPE lens_rpc_requests_total counter
+lens_rpc_requests_total{chain="cosmoshub-4",endpoint=%q,method="health",status="200"} 2
+`, srv.URL)), "lens_rpc_requests_total"))
+	n, err := testutil.GatherAndCount(reg, "lens_rpc_request_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestMetrics_Transactions(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	m, err := client.NewMetrics(reg)
+	require.NoError(t, err)
+
+	mc := new(mocks.Client)
+	cl, addr := sequenceTestClient(t, mc, client.WithMetrics(m))
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{
+		Code:      32,
+		Codespace: "sdk",
+		Log:       "account sequence mismatch, expected 5, got 3: incorrect account sequence",
+	}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{1}}, nil)
+
+	msg := banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	_, err = cl.SendMsgsWithOptions(context.Background(), []sdk.Msg{msg}, client.TxOptions{BroadcastMode: client.BroadcastSync})
+	require.NoError(t, err)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP lens_abci_queries_total ABCI queries, by chain, query path, and gRPC status code.
+# TYPE lens_abci_queries_total counter
+lens_abci_queries_total{chain="cosmoshub-4",path="/cosmos.auth.v1beta1.Query/Account",status="OK"} 1
+lens_abci_queries_total{chain="cosmoshub-4",path="/cosmos.tx.v1beta1.Service/Simulate",status="OK"} 2
+# HELP lens_tx_broadcasts_total Transaction broadcasts, by chain, result (success, failure, or error), and ABCI codespace and code.
+# TYPE lens_tx_broadcasts_total counter
+lens_tx_broadcasts_total{chain="cosmoshub-4",code="0",codespace="",result="success"} 1
+lens_tx_broadcasts_total{chain="cosmoshub-4",code="32",codespace="sdk",result="failure"} 1
+# HELP lens_tx_sequence_retries_total Transactions signed again after being rejected for their account sequence, by chain.
+# TYPE lens_tx_sequence_retries_total counter
+lens_tx_sequence_retries_total{chain="cosmoshub-4"} 1
+`), "lens_abci_queries_total", "lens_tx_broadcasts_total", "lens_tx_sequence_retries_total"))
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/strangelove-ventures/lens/byop"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// codecModules returns the Modules of the chain, followed by the modules registering the messages
+// of the descriptor sets of ExtraMsgDescriptors, whose relative paths are relative to homepath.
+func (ccc *ChainClientConfig) codecModules(homepath string) ([]module.AppModuleBasic, error) {
+	modules := append([]module.AppModuleBasic{}, ccc.Modules...)
+	for _, path := range ccc.ExtraMsgDescriptors {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(homepath, path)
+		}
+		bz, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read extra-msg-descriptors: %w", err)
+		}
+		var fds descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(bz, &fds); err != nil {
+			return nil, fmt.Errorf("failed to decode extra-msg-descriptors %s: %w", path, err)
+		}
+		// Modules are keyed by name, so each set is named after its path.
+		m, err := byop.NewModuleFromDescriptors(path, &fds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extra-msg-descriptors %s: %w", path, err)
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
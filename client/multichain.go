@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMultiChainWorkers is how many chains MultiChainQuery queries at a time by default.
+	DefaultMultiChainWorkers = 8
+
+	// DefaultMultiChainTimeout is how long MultiChainQuery waits for the query of each chain by default.
+	DefaultMultiChainTimeout = 30 * time.Second
+)
+
+// ChainClients are chain clients by chain name.
+type ChainClients map[string]*ChainClient
+
+// MultiChainResult is the result of the query of one chain by MultiChainQuery:
+// either the value returned by the query, or its error.
+type MultiChainResult struct {
+	Value interface{}
+	Err   error
+}
+
+// MultiChainOption configures MultiChainQuery.
+type MultiChainOption func(*multiChainOptions)
+
+type multiChainOptions struct {
+	workers int
+	timeout time.Duration
+}
+
+// WithMultiChainWorkers sets how many chains MultiChainQuery queries at a time.
+func WithMultiChainWorkers(n int) MultiChainOption {
+	return func(o *multiChainOptions) {
+		o.workers = n
+	}
+}
+
+// WithMultiChainTimeout sets how long MultiChainQuery waits for the query of each chain.
+func WithMultiChainTimeout(d time.Duration) MultiChainOption {
+	return func(o *multiChainOptions) {
+		o.timeout = d
+	}
+}
+
+// MultiChainQuery calls fn with the client of each of the named chains, concurrently,
+// and returns the result of each chain by name.
+//
+// At most DefaultMultiChainWorkers chains are queried at a time, and each query is given a context
+// with a timeout of DefaultMultiChainTimeout, unless opts set otherwise.
+// A query that does not return by its timeout is abandoned, with its result recording the timeout.
+// The failure of a chain, including a name without a client, is recorded in its result
+// and does not stop the queries of the other chains.
+func (c ChainClients) MultiChainQuery(ctx context.Context, chains []string, fn func(ctx context.Context, cc *ChainClient) (interface{}, error), opts ...MultiChainOption) map[string]MultiChainResult {
+	o := multiChainOptions{workers: DefaultMultiChainWorkers, timeout: DefaultMultiChainTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workers < 1 {
+		o.workers = 1
+	}
+
+	results := make(map[string]MultiChainResult, len(chains))
+	var mu sync.Mutex
+	names := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers && i < len(chains); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				res := c.queryChain(ctx, name, fn, o.timeout)
+				mu.Lock()
+				results[name] = res
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, name := range chains {
+		names <- name
+	}
+	close(names)
+	wg.Wait()
+	return results
+}
+
+// queryChain calls fn with the client of the named chain, waiting for it at most timeout.
+func (c ChainClients) queryChain(ctx context.Context, name string, fn func(ctx context.Context, cc *ChainClient) (interface{}, error), timeout time.Duration) MultiChainResult {
+	cc, ok := c[name]
+	if !ok || cc == nil {
+		return MultiChainResult{Err: fmt.Errorf("no client for chain %q", name)}
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Buffered, so that an abandoned query does not block forever.
+	done := make(chan MultiChainResult, 1)
+	go func() {
+		v, err := fn(ctx, cc)
+		done <- MultiChainResult{Value: v, Err: err}
+	}()
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		// The deadline of the parent, such as that of a command's --timeout, may have passed first.
+		if parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+			return MultiChainResult{Err: fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())}
+		}
+		return MultiChainResult{Err: ctx.Err()}
+	}
+}
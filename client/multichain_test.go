@@ -0,0 +1,59 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMultiChainQuery(t *testing.T) {
+	t.Parallel()
+
+	clients := make(client.ChainClients)
+	for _, name := range []string{"cosmoshub", "osmosis", "juno"} {
+		homepath := t.TempDir()
+		ccc := client.GetCosmosHubConfig(homepath, true)
+		ccc.ChainID = name
+		cl, err := client.NewChainClient(zaptest.NewLogger(t), ccc, homepath, nil, nil)
+		require.NoError(t, err)
+		clients[name] = cl
+	}
+
+	var running, maxRunning int32
+	results := clients.MultiChainQuery(context.Background(), []string{"cosmoshub", "osmosis", "juno", "unknown"},
+		func(ctx context.Context, cl *client.ChainClient) (interface{}, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			switch cl.Config.ChainID {
+			case "osmosis":
+				return nil, errors.New("query failed")
+			case "juno":
+				// Abandoned when it times out.
+				<-ctx.Done()
+				time.Sleep(time.Second)
+				return "too late", nil
+			}
+			return cl.Config.ChainID, nil
+		}, client.WithMultiChainWorkers(2), client.WithMultiChainTimeout(50*time.Millisecond))
+
+	require.Len(t, results, 4)
+	require.Equal(t, client.MultiChainResult{Value: "cosmoshub"}, results["cosmoshub"])
+	require.EqualError(t, results["osmosis"].Err, "query failed")
+	require.ErrorIs(t, results["juno"].Err, context.DeadlineExceeded)
+	require.Nil(t, results["juno"].Value)
+	require.EqualError(t, results["unknown"].Err, `no client for chain "unknown"`)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+}
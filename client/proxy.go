@@ -0,0 +1,279 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// ProxyDirect is the proxy setting of connections that go to the endpoints directly,
+// even if a proxy is set in the environment.
+const ProxyDirect = "direct"
+
+// dialContextFunc dials a connection to address on the named network.
+type dialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// ValidateProxy checks that setting is a proxy setting: empty, for the proxy set in the environment,
+// ProxyDirect, or the URL of a proxy, with the scheme socks5, socks5h, http, or https.
+func ValidateProxy(setting string) error {
+	if setting == "" || setting == ProxyDirect {
+		return nil
+	}
+	_, err := parseProxy(setting)
+	return err
+}
+
+func parseProxy(setting string) (*url.URL, error) {
+	u, err := url.Parse(setting)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http", "https":
+	default:
+		return nil, fmt.Errorf("proxy must be %q or a URL with the scheme socks5, socks5h, http, or https", ProxyDirect)
+	}
+	if u.Host == "" {
+		return nil, errors.New("proxy URL must have a host")
+	}
+	return u, nil
+}
+
+// RPCProxySetting returns the proxy setting of the RPC connections of the chain: RPCProxy if set, or else Proxy.
+func (ccc *ChainClientConfig) RPCProxySetting() string {
+	if ccc.RPCProxy != "" {
+		return ccc.RPCProxy
+	}
+	return ccc.Proxy
+}
+
+// proxyURL returns the URL of the proxy to connect to target through, according to setting,
+// or nil if the connection goes to target directly.
+func proxyURL(setting string, target *url.URL) (*url.URL, error) {
+	switch setting {
+	case ProxyDirect:
+		return nil, nil
+	case "":
+		return proxyFromEnvironment(target)
+	}
+	return parseProxy(setting)
+}
+
+// proxyFromEnvironment returns the URL of the proxy to connect to target through,
+// from HTTPS_PROXY or HTTP_PROXY, depending on the scheme of target, or else from ALL_PROXY,
+// or nil if none is set or if NO_PROXY excludes target.
+// As with net/http, connections to the local host are never proxied.
+func proxyFromEnvironment(target *url.URL) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	u, err := cfg.ProxyFunc()(target)
+	if err != nil || u != nil {
+		return u, err
+	}
+
+	all := os.Getenv("ALL_PROXY")
+	if all == "" {
+		all = os.Getenv("all_proxy")
+	}
+	if all == "" {
+		return nil, nil
+	}
+	// Any proxy URL, to apply the NO_PROXY exclusions to ALL_PROXY.
+	cfg.HTTPProxy, cfg.HTTPSProxy = "http://all-proxy", "http://all-proxy"
+	if u, _ := cfg.ProxyFunc()(target); u == nil {
+		return nil, nil
+	}
+	u, err = parseProxy(all)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALL_PROXY %q: %w", all, err)
+	}
+	return u, nil
+}
+
+// proxyDialer returns the function dialing connections through the proxy at u,
+// using forward to connect to the proxy.
+// Connections through HTTP proxies are tunneled with the CONNECT method.
+func proxyDialer(u *url.URL, forward *net.Dialer) (dialContextFunc, error) {
+	switch u.Scheme {
+	case "http", "https":
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, u, forward, address)
+		}, nil
+	}
+
+	d, err := proxy.FromURL(u, forward)
+	if err != nil {
+		return nil, err
+	}
+	cd, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("proxy %s does not support contexts", u.Redacted())
+	}
+	return cd.DialContext, nil
+}
+
+// dialHTTPConnect connects to address through a tunnel opened with the CONNECT method of the HTTP proxy at u.
+func dialHTTPConnect(ctx context.Context, u *url.URL, forward *net.Dialer, address string) (net.Conn, error) {
+	proxyAddr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(u.Hostname(), port)
+	}
+	conn, err := forward.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to proxy %s: %w", u.Redacted(), err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response of proxy %s: %w", u.Redacted(), err)
+	}
+	// The body of a successful response is the tunnel, so it is not read nor closed.
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", u.Redacted(), address, res.Status)
+	}
+	if br.Buffered() > 0 {
+		// The server spoke first, so keep what was read past the response.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read into r.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// endpointDialer returns the function dialing connections to the endpoint at address on network
+// through the proxy given by setting, if any.
+func endpointDialer(network, address, setting string) (dialContextFunc, error) {
+	d := &net.Dialer{Timeout: endpointDialTimeout}
+	if network != "tcp" {
+		return d.DialContext, nil
+	}
+	u, err := proxyURL(setting, &url.URL{Scheme: "https", Host: address})
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return d.DialContext, nil
+	}
+	return proxyDialer(u, d)
+}
+
+// proxyRPCTransport makes transport, whose connections go to the RPC endpoint addr,
+// connect through the proxy given by setting, if any.
+// Unix socket endpoints are always connected to directly.
+func proxyRPCTransport(transport *http.Transport, addr, setting string) error {
+	network, address, err := endpointDialAddress(addr)
+	if err != nil {
+		return err
+	}
+	if network != "tcp" {
+		return nil
+	}
+	target := &url.URL{Scheme: "http", Host: address}
+	if strings.HasPrefix(addr, "https://") {
+		target.Scheme = "https"
+	}
+	u, err := proxyURL(setting, target)
+	if err != nil || u == nil {
+		return err
+	}
+
+	// The transport now dials either the proxy or the endpoint, rather than always the endpoint.
+	d := &net.Dialer{Timeout: endpointDialTimeout}
+	transport.Dial = nil
+	switch u.Scheme {
+	case "http", "https":
+		transport.Proxy = http.ProxyURL(u)
+		transport.DialContext = d.DialContext
+	default:
+		dial, err := proxyDialer(u, d)
+		if err != nil {
+			return err
+		}
+		transport.DialContext = dial
+	}
+	return nil
+}
+
+// GRPCProxyDialOptions returns the target to dial in place of the gRPC endpoint addr, one of the forms of ValidateGRPCAddr,
+// and the options making the connection go through the proxy given by setting, if any.
+// The target of a proxied connection is resolved by the proxy, not locally,
+// so that names only the proxy can resolve, such as those of Tor onion services, can be reached.
+// Unix sockets are always connected to directly.
+func GRPCProxyDialOptions(addr, setting string) (string, []grpc.DialOption, error) {
+	a, err := parseGRPCAddr(addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid gRPC address %q: %w", addr, err)
+	}
+	if a.scheme == gRPCSchemeUnix {
+		return a.target(), append(unixDialOptions(), grpc.WithNoProxy()), nil
+	}
+
+	u, err := proxyURL(setting, &url.URL{Scheme: "https", Host: a.hostPort})
+	if err != nil {
+		return "", nil, err
+	}
+	if u == nil {
+		// gRPC would otherwise apply HTTPS_PROXY itself.
+		return a.target(), []grpc.DialOption{grpc.WithNoProxy()}, nil
+	}
+	dial, err := proxyDialer(u, &net.Dialer{Timeout: endpointDialTimeout})
+	if err != nil {
+		return "", nil, err
+	}
+	return "passthrough:///" + a.hostPort, []grpc.DialOption{
+		grpc.WithNoProxy(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dial(ctx, "tcp", address)
+		}),
+	}, nil
+}
@@ -250,6 +250,20 @@ func (cc *ChainClient) QueryTxs(ctx context.Context, page, limit int, events []s
 	return res.Txs, nil
 }
 
+// SearchTxs returns a page of the transactions matching the Tendermint query,
+// along with the total number of matching transactions.
+func (cc *ChainClient) SearchTxs(ctx context.Context, query string, page, limit int) (*ctypes.ResultTxSearch, error) {
+	if page <= 0 {
+		return nil, errors.New("page must greater than 0")
+	}
+
+	if limit <= 0 {
+		return nil, errors.New("limit must greater than 0")
+	}
+
+	return cc.RPCClient.TxSearch(ctx, query, false, &page, &limit, "")
+}
+
 func DefaultPageRequest() *query.PageRequest {
 	return &query.PageRequest{
 		Key:        []byte(""),
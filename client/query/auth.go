@@ -0,0 +1,16 @@
+package query
+
+import (
+	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// auth_AccountRPC returns the account of an address.
+// The account is left packed, so that accounts of types unknown to the codec can still be read.
+func auth_AccountRPC(q *Query, address string) (*authTypes.QueryAccountResponse, error) {
+	req := &authTypes.QueryAccountRequest{Address: address}
+	var res authTypes.QueryAccountResponse
+	if err := invokeRaw(q, "/cosmos.auth.v1beta1.Query/Account", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
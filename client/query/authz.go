@@ -0,0 +1,50 @@
+package query
+
+import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+// authz_AllGrantsRPC returns all the grants of a granter to a grantee, only for msgTypeURL if it is not empty,
+// requesting every page of the results in turn.
+// The authorizations are left packed, so that grants of authorization types unknown to the codec can still be read.
+func authz_AllGrantsRPC(q *Query, granter, grantee, msgTypeURL string) ([]*authz.Grant, error) {
+	var grants []*authz.Grant
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &authz.QueryGrantsRequest{
+			Granter:    granter,
+			Grantee:    grantee,
+			MsgTypeUrl: msgTypeURL,
+			Pagination: pr,
+		}
+		var res authz.QueryGrantsResponse
+		if err := invokeRaw(q, "/cosmos.authz.v1beta1.Query/Grants", req, &res); err != nil {
+			return nil, err
+		}
+		grants = append(grants, res.Grants...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// authz_AllGranterGrantsRPC returns all the grants of a granter, requesting every page of the results in turn.
+// The authorizations are left packed, as by authz_AllGrantsRPC.
+func authz_AllGranterGrantsRPC(q *Query, granter string) ([]*authz.GrantAuthorization, error) {
+	var grants []*authz.GrantAuthorization
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &authz.QueryGranterGrantsRequest{Granter: granter, Pagination: pr}
+		var res authz.QueryGranterGrantsResponse
+		if err := invokeRaw(q, "/cosmos.authz.v1beta1.Query/GranterGrants", req, &res); err != nil {
+			return nil, err
+		}
+		grants = append(grants, res.Grants...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
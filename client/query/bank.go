@@ -1,6 +1,8 @@
 package query
 
 import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 )
 
@@ -43,6 +45,50 @@ func bank_AllBalancesRPC(q *Query, address string) (*bankTypes.QueryAllBalancesR
 	return res, nil
 }
 
+// bank_AllBalancesAllPagesRPC returns the balance of all coins for a single account,
+// requesting every page of the results in turn.
+func bank_AllBalancesAllPagesRPC(q *Query, address string) (sdk.Coins, error) {
+	queryClient := bankTypes.NewQueryClient(q.Client)
+	var balances sdk.Coins
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &bankTypes.QueryAllBalancesRequest{Address: address, Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.AllBalances(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, res.Balances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// bank_SpendableBalancesAllPagesRPC returns the spendable balance of all coins for a single account,
+// requesting every page of the results in turn.
+func bank_SpendableBalancesAllPagesRPC(q *Query, address string) (sdk.Coins, error) {
+	queryClient := bankTypes.NewQueryClient(q.Client)
+	var balances sdk.Coins
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &bankTypes.QuerySpendableBalancesRequest{Address: address, Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.SpendableBalances(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, res.Balances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
 // bank_SupplyOfRPC returns the supply of all coins
 func bank_SupplyOfRPC(q *Query, denom string) (*bankTypes.QuerySupplyOfResponse, error) {
 	req := &bankTypes.QuerySupplyOfRequest{Denom: denom}
@@ -69,6 +115,27 @@ func bank_TotalSupplyRPC(q *Query) (*bankTypes.QueryTotalSupplyResponse, error)
 	return res, nil
 }
 
+// bank_AllTotalSupplyRPC returns the supply of all coins, requesting every page of the results in turn.
+func bank_AllTotalSupplyRPC(q *Query) (sdk.Coins, error) {
+	queryClient := bankTypes.NewQueryClient(q.Client)
+	var supply sdk.Coins
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &bankTypes.QueryTotalSupplyRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.TotalSupply(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		supply = append(supply, res.Supply...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
+
 // bank_DenomMetadataRPC returns the metadata for given denom
 func bank_DenomMetadataRPC(q *Query, denom string) (*bankTypes.QueryDenomMetadataResponse, error) {
 	req := &bankTypes.QueryDenomMetadataRequest{Denom: denom}
@@ -94,3 +161,24 @@ func bank_DenomsMetadataRPC(q *Query) (*bankTypes.QueryDenomsMetadataResponse, e
 	}
 	return res, nil
 }
+
+// bank_AllDenomsMetadataRPC returns the metadata for all denoms, requesting every page of the results in turn.
+func bank_AllDenomsMetadataRPC(q *Query) ([]bankTypes.Metadata, error) {
+	queryClient := bankTypes.NewQueryClient(q.Client)
+	var metadatas []bankTypes.Metadata
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &bankTypes.QueryDenomsMetadataRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.DenomsMetadata(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		metadatas = append(metadatas, res.Metadatas...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadatas, nil
+}
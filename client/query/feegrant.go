@@ -0,0 +1,25 @@
+package query
+
+import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+)
+
+// feegrant_AllAllowancesRPC returns all the fee allowances granted to a grantee, requesting every page of the results in turn.
+// The allowances are left packed, so that allowances of types unknown to the codec can still be read.
+func feegrant_AllAllowancesRPC(q *Query, grantee string) ([]*feegrant.Grant, error) {
+	var grants []*feegrant.Grant
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &feegrant.QueryAllowancesRequest{Grantee: grantee, Pagination: pr}
+		var res feegrant.QueryAllowancesResponse
+		if err := invokeRaw(q, "/cosmos.feegrant.v1beta1.Query/Allowances", req, &res); err != nil {
+			return nil, err
+		}
+		grants = append(grants, res.Allowances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	govTypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"google.golang.org/grpc/metadata"
+)
+
+// protoUnmarshaler is implemented by the generated query response types.
+type protoUnmarshaler interface {
+	Unmarshal([]byte) error
+}
+
+// invokeRaw runs the query method with req and unmarshals the response into res.
+// Unlike the generated query clients, it does not unpack the Any values of the response,
+// so that responses holding types unknown to the codec, such as custom proposal contents, can still be read.
+func invokeRaw(q *Query, method string, req interface{}, res protoUnmarshaler) error {
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	md, _ := metadata.FromOutgoingContext(ctx)
+	abciRes, _, err := q.Client.RunGRPCQuery(ctx, method, req, md)
+	if err != nil {
+		return err
+	}
+	return res.Unmarshal(abciRes.Value)
+}
+
+// gov_AllProposalsRPC returns all the proposals with the given status, or of any status if it is unspecified,
+// requesting every page of the results in turn.
+func gov_AllProposalsRPC(q *Query, status govTypes.ProposalStatus) ([]govTypes.Proposal, error) {
+	var proposals []govTypes.Proposal
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &govTypes.QueryProposalsRequest{
+			ProposalStatus: status,
+			Pagination:     pr,
+		}
+		var res govTypes.QueryProposalsResponse
+		if err := invokeRaw(q, "/cosmos.gov.v1beta1.Query/Proposals", req, &res); err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, res.Proposals...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// gov_ProposalRPC returns the proposal with the given ID
+func gov_ProposalRPC(q *Query, id uint64) (*govTypes.QueryProposalResponse, error) {
+	req := &govTypes.QueryProposalRequest{ProposalId: id}
+	var res govTypes.QueryProposalResponse
+	if err := invokeRaw(q, "/cosmos.gov.v1beta1.Query/Proposal", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// gov_TallyResultRPC returns the current tally of the votes on a proposal
+func gov_TallyResultRPC(q *Query, id uint64) (*govTypes.QueryTallyResultResponse, error) {
+	req := &govTypes.QueryTallyResultRequest{ProposalId: id}
+	queryClient := govTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.TallyResult(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/group"
+)
+
+// group_AllGroupsByMemberRPC returns all the groups of which address is a member, requesting every page of the results in turn.
+func group_AllGroupsByMemberRPC(q *Query, address string) ([]*group.GroupInfo, error) {
+	queryClient := group.NewQueryClient(q.Client)
+	var groups []*group.GroupInfo
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &group.QueryGroupsByMemberRequest{Address: address, Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.GroupsByMember(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, res.Groups...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// group_AllGroupMembersRPC returns all the members of a group, requesting every page of the results in turn.
+func group_AllGroupMembersRPC(q *Query, groupID uint64) ([]*group.GroupMember, error) {
+	queryClient := group.NewQueryClient(q.Client)
+	var members []*group.GroupMember
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &group.QueryGroupMembersRequest{GroupId: groupID, Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.GroupMembers(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, res.Members...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// group_AllGroupPoliciesByGroupRPC returns all the policies of a group, requesting every page of the results in turn.
+// Their decision policies are left packed, so that policies of types unknown to the codec can still be listed.
+func group_AllGroupPoliciesByGroupRPC(q *Query, groupID uint64) ([]*group.GroupPolicyInfo, error) {
+	var policies []*group.GroupPolicyInfo
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &group.QueryGroupPoliciesByGroupRequest{GroupId: groupID, Pagination: pr}
+		var res group.QueryGroupPoliciesByGroupResponse
+		if err := invokeRaw(q, "/cosmos.group.v1.Query/GroupPoliciesByGroup", req, &res); err != nil {
+			return nil, err
+		}
+		policies = append(policies, res.GroupPolicies...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// group_GroupPolicyInfoRPC returns the group policy account at address, with its decision policy left packed.
+func group_GroupPolicyInfoRPC(q *Query, address string) (*group.QueryGroupPolicyInfoResponse, error) {
+	req := &group.QueryGroupPolicyInfoRequest{Address: address}
+	var res group.QueryGroupPolicyInfoResponse
+	if err := invokeRaw(q, "/cosmos.group.v1.Query/GroupPolicyInfo", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// group_AllProposalsByGroupPolicyRPC returns all the proposals of a group policy account,
+// requesting every page of the results in turn.
+// Their messages are left packed, so that proposals holding messages of types unknown to the codec can still be listed.
+func group_AllProposalsByGroupPolicyRPC(q *Query, address string) ([]*group.Proposal, error) {
+	var proposals []*group.Proposal
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &group.QueryProposalsByGroupPolicyRequest{Address: address, Pagination: pr}
+		var res group.QueryProposalsByGroupPolicyResponse
+		if err := invokeRaw(q, "/cosmos.group.v1.Query/ProposalsByGroupPolicy", req, &res); err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, res.Proposals...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// group_ProposalRPC returns the group proposal with the given ID, with its messages left packed.
+func group_ProposalRPC(q *Query, id uint64) (*group.QueryProposalResponse, error) {
+	req := &group.QueryProposalRequest{ProposalId: id}
+	var res group.QueryProposalResponse
+	if err := invokeRaw(q, "/cosmos.group.v1.Query/Proposal", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
 	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
@@ -134,3 +136,98 @@ func ibc_ChannelsRPC(q *Query) (*channeltypes.QueryChannelsResponse, error) {
 	}
 	return res, nil
 }
+
+// ibc_AllClientStatesRPC returns the states of all IBC clients, requesting every page of the results in turn.
+// The client states are left packed, so that clients of types unknown to the codec can still be listed.
+func ibc_AllClientStatesRPC(q *Query) (clienttypes.IdentifiedClientStates, error) {
+	var states clienttypes.IdentifiedClientStates
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &clienttypes.QueryClientStatesRequest{Pagination: pr}
+		var res clienttypes.QueryClientStatesResponse
+		if err := invokeRaw(q, "/ibc.core.client.v1.Query/ClientStates", req, &res); err != nil {
+			return nil, err
+		}
+		states = append(states, res.ClientStates...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ibc_AllConnectionsRPC returns all IBC connections, requesting every page of the results in turn.
+func ibc_AllConnectionsRPC(q *Query) ([]*connectiontypes.IdentifiedConnection, error) {
+	var connections []*connectiontypes.IdentifiedConnection
+	queryClient := connectiontypes.NewQueryClient(q.Client)
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &connectiontypes.QueryConnectionsRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.Connections(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, res.Connections...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
+
+// ibc_AllChannelsRPC returns all IBC channels, requesting every page of the results in turn.
+func ibc_AllChannelsRPC(q *Query) ([]*channeltypes.IdentifiedChannel, error) {
+	var channels []*channeltypes.IdentifiedChannel
+	queryClient := channeltypes.NewQueryClient(q.Client)
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &channeltypes.QueryChannelsRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.Channels(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, res.Channels...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// transfer_DenomTraceRPC returns the denom trace of the IBC denom with the given hash.
+func transfer_DenomTraceRPC(q *Query, hash string) (*transfertypes.QueryDenomTraceResponse, error) {
+	req := &transfertypes.QueryDenomTraceRequest{Hash: hash}
+	queryClient := transfertypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.DenomTrace(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// transfer_AllDenomTracesRPC returns the denom traces of all IBC denoms, requesting every page of the results in turn.
+func transfer_AllDenomTracesRPC(q *Query) (transfertypes.Traces, error) {
+	var traces transfertypes.Traces
+	queryClient := transfertypes.NewQueryClient(q.Client)
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &transfertypes.QueryDenomTracesRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.DenomTraces(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, res.DenomTraces...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
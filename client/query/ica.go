@@ -0,0 +1,18 @@
+package query
+
+import (
+	icacontrollertypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/controller/types"
+)
+
+// ica_InterchainAccountRPC returns the address, on the host chain, of the interchain account of owner over the connection.
+func ica_InterchainAccountRPC(q *Query, owner, connectionID string) (*icacontrollertypes.QueryInterchainAccountResponse, error) {
+	req := &icacontrollertypes.QueryInterchainAccountRequest{Owner: owner, ConnectionId: connectionID}
+	queryClient := icacontrollertypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.InterchainAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -1,9 +1,21 @@
 package query
 
 import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	distributionTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	govTypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	slashingTypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	upgradeTypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	icacontrollertypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/controller/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
 	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
@@ -12,8 +24,22 @@ import (
 )
 
 type Query struct {
+	// Ctx bounds the queries, such as with the deadline of a command or its cancellation,
+	// in addition to the timeout of the chain; context.Background() if nil.
+	Ctx     context.Context
 	Client  *client.ChainClient
 	Options *QueryOptions
+
+	// Pages describes the pages read by the last query following every page of its results.
+	Pages PageInfo
+}
+
+// Auth queries
+
+// Auth_Account returns the account of an address, with the account left packed.
+func (q *Query) Auth_Account(address string) (*authTypes.QueryAccountResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return auth_AccountRPC(q, address)
 }
 
 // Bank queries
@@ -36,6 +62,18 @@ func (q *Query) Bank_Balances(address string) (*bankTypes.QueryAllBalancesRespon
 	return bank_AllBalancesRPC(q, address)
 }
 
+// Bank_AllBalances returns the balance of all coins for a single account, across every page of results.
+func (q *Query) Bank_AllBalances(address string) (sdk.Coins, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return bank_AllBalancesAllPagesRPC(q, address)
+}
+
+// Bank_SpendableBalances returns the spendable balance of all coins for a single account, across every page of results.
+func (q *Query) Bank_SpendableBalances(address string) (sdk.Coins, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return bank_SpendableBalancesAllPagesRPC(q, address)
+}
+
 // SupplyOf returns the supply of given coin
 func (q *Query) Bank_SupplyOf(denom string) (*bankTypes.QuerySupplyOfResponse, error) {
 	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
@@ -48,6 +86,12 @@ func (q *Query) Bank_TotalSupply() (*bankTypes.QueryTotalSupplyResponse, error)
 	return bank_TotalSupplyRPC(q)
 }
 
+// Bank_AllTotalSupply returns the supply of all coins, across every page of results.
+func (q *Query) Bank_AllTotalSupply() (sdk.Coins, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return bank_AllTotalSupplyRPC(q)
+}
+
 // DenomMetadata returns the metadata for given denoms
 func (q *Query) Bank_DenomMetadata(denom string) (*bankTypes.QueryDenomMetadataResponse, error) {
 	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
@@ -60,6 +104,12 @@ func (q *Query) Bank_DenomsMetadata() (*bankTypes.QueryDenomsMetadataResponse, e
 	return bank_DenomsMetadataRPC(q)
 }
 
+// Bank_AllDenomsMetadata returns the metadata for all denoms, across every page of results.
+func (q *Query) Bank_AllDenomsMetadata() ([]bankTypes.Metadata, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return bank_AllDenomsMetadataRPC(q)
+}
+
 // Staking queries
 
 // Return params for staking module.
@@ -92,6 +142,12 @@ func (q *Query) Staking_ValidatorDelegations(validator string) (*stakingTypes.Qu
 	return staking_ValidatorDelegationsRPC(q, validator)
 }
 
+// Staking_AllValidatorDelegations returns all the delegations for a validator, across every page of results.
+func (q *Query) Staking_AllValidatorDelegations(validator string) (stakingTypes.DelegationResponses, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return staking_AllValidatorDelegationsRPC(q, validator)
+}
+
 // ValidatorDelegations returns all the unbonding delegations for a validator
 func (q *Query) Staking_ValidatorUnbondingDelegations(validator string) (*stakingTypes.QueryValidatorUnbondingDelegationsResponse, error) {
 	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
@@ -122,6 +178,25 @@ func (q *Query) Staking_DelegatorUnbondingDelegations(delegator string) (*stakin
 	return staking_DelegatorUnbondingDelegationsRPC(q, delegator)
 }
 
+// Staking_AllDelegatorDelegations returns all the delegations for a given delegator, across every page of results.
+func (q *Query) Staking_AllDelegatorDelegations(delegator string) (stakingTypes.DelegationResponses, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return staking_AllDelegatorDelegationsRPC(q, delegator)
+}
+
+// Staking_AllDelegatorUnbondingDelegations returns all the unbonding delegations for a given delegator, across every page of results.
+func (q *Query) Staking_AllDelegatorUnbondingDelegations(delegator string) ([]stakingTypes.UnbondingDelegation, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return staking_AllDelegatorUnbondingDelegationsRPC(q, delegator)
+}
+
+// Staking_AllValidators returns all the validators for a given status, or of any status if it is empty,
+// across every page of results.
+func (q *Query) Staking_AllValidators(status string) (stakingTypes.Validators, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return staking_AllValidatorsRPC(q, status)
+}
+
 // Delegation returns the delegations for a particular validator / delegator tuple
 func (q *Query) Staking_Redelegations(delegator string, src_validator string, dst_validator string) (*stakingTypes.QueryRedelegationsResponse, error) {
 	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
@@ -202,6 +277,27 @@ func (q *Query) Distribution_DelegatorWithdrawAddress(delegator string) (*distri
 	return distribution_DelegatorWithdrawAddressRPC(q, delegator)
 }
 
+// Gov queries
+
+// Gov_AllProposals returns all the proposals with the given status, across every page of results.
+// The proposal contents are left packed, as they may be of types unknown to the codec.
+func (q *Query) Gov_AllProposals(status govTypes.ProposalStatus) ([]govTypes.Proposal, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return gov_AllProposalsRPC(q, status)
+}
+
+// Gov_Proposal returns a single proposal, with its content left packed.
+func (q *Query) Gov_Proposal(id uint64) (*govTypes.QueryProposalResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return gov_ProposalRPC(q, id)
+}
+
+// Gov_TallyResult returns the current tally of the votes on a proposal.
+func (q *Query) Gov_TallyResult(id uint64) (*govTypes.QueryTallyResultResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return gov_TallyResultRPC(q, id)
+}
+
 // Tendermint queries
 
 // Block returns information about a block
@@ -240,6 +336,29 @@ func (q *Query) ABCIQuery(path string, data string, prove bool) (*coretypes.Resu
 	return ABCIQueryRPC(q, path, data, prove)
 }
 
+// Authz queries
+
+// Authz_AllGrants returns all the grants of a granter to a grantee, across every page of results.
+// If msgTypeURL is not empty, only the grant for that message type is returned.
+func (q *Query) Authz_AllGrants(granter, grantee, msgTypeURL string) ([]*authz.Grant, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return authz_AllGrantsRPC(q, granter, grantee, msgTypeURL)
+}
+
+// Authz_AllGranterGrants returns all the grants of a granter, to any grantee, across every page of results.
+func (q *Query) Authz_AllGranterGrants(granter string) ([]*authz.GrantAuthorization, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return authz_AllGranterGrantsRPC(q, granter)
+}
+
+// Feegrant queries
+
+// Feegrant_AllAllowances returns all the fee allowances granted to a grantee, across every page of results.
+func (q *Query) Feegrant_AllAllowances(grantee string) ([]*feegrant.Grant, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return feegrant_AllAllowancesRPC(q, grantee)
+}
+
 // IBC Queries
 
 // IBCQuery returns parameters for the IBC client submodule.
@@ -295,3 +414,122 @@ func (q *Query) Ibc_Channels() (*channeltypes.QueryChannelsResponse, error) {
 	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
 	return ibc_ChannelsRPC(q)
 }
+
+// Ibc_AllClientStates returns the client states of all IBC clients, following every page of the results.
+// The client states are left packed.
+func (q *Query) Ibc_AllClientStates() (clienttypes.IdentifiedClientStates, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return ibc_AllClientStatesRPC(q)
+}
+
+// Ibc_AllConnections returns all IBC connections, following every page of the results.
+func (q *Query) Ibc_AllConnections() ([]*connectiontypes.IdentifiedConnection, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return ibc_AllConnectionsRPC(q)
+}
+
+// Ibc_AllChannels returns all IBC channels, following every page of the results.
+func (q *Query) Ibc_AllChannels() ([]*channeltypes.IdentifiedChannel, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return ibc_AllChannelsRPC(q)
+}
+
+// Transfer_DenomTrace returns the denom trace of the IBC denom with the given hash.
+func (q *Query) Transfer_DenomTrace(hash string) (*transfertypes.QueryDenomTraceResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return transfer_DenomTraceRPC(q, hash)
+}
+
+// Transfer_AllDenomTraces returns the denom traces of all IBC denoms, following every page of the results.
+func (q *Query) Transfer_AllDenomTraces() (transfertypes.Traces, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return transfer_AllDenomTracesRPC(q)
+}
+
+// Slashing queries
+
+// Slashing_Params returns the slashing params.
+func (q *Query) Slashing_Params() (*slashingTypes.QueryParamsResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return slashing_ParamsRPC(q)
+}
+
+// Slashing_SigningInfo returns the signing info of the validator with the given consensus address.
+func (q *Query) Slashing_SigningInfo(consAddress string) (*slashingTypes.QuerySigningInfoResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return slashing_SigningInfoRPC(q, consAddress)
+}
+
+// Slashing_AllSigningInfos returns the signing infos of all validators, across every page of results.
+func (q *Query) Slashing_AllSigningInfos() ([]slashingTypes.ValidatorSigningInfo, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return slashing_AllSigningInfosRPC(q)
+}
+
+// Upgrade queries
+
+// Upgrade_CurrentPlan returns the currently scheduled upgrade plan, if any.
+func (q *Query) Upgrade_CurrentPlan() (*upgradeTypes.QueryCurrentPlanResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return upgrade_CurrentPlanRPC(q)
+}
+
+// Upgrade_AppliedPlan returns the height at which the upgrade of the given name was applied, or zero if it was not.
+func (q *Query) Upgrade_AppliedPlan(name string) (*upgradeTypes.QueryAppliedPlanResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return upgrade_AppliedPlanRPC(q, name)
+}
+
+// Upgrade_ModuleVersions returns the consensus versions of the modules, or of a single module if its name is not empty.
+func (q *Query) Upgrade_ModuleVersions(module string) (*upgradeTypes.QueryModuleVersionsResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return upgrade_ModuleVersionsRPC(q, module)
+}
+
+// Interchain accounts queries
+
+// Ica_InterchainAccount returns the address, on the host chain, of the interchain account of owner over the connection.
+func (q *Query) Ica_InterchainAccount(owner, connectionID string) (*icacontrollertypes.QueryInterchainAccountResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return ica_InterchainAccountRPC(q, owner, connectionID)
+}
+
+// Group queries
+
+// Group_AllGroupsByMember returns all the groups of which address is a member, across every page of results.
+func (q *Query) Group_AllGroupsByMember(address string) ([]*group.GroupInfo, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_AllGroupsByMemberRPC(q, address)
+}
+
+// Group_AllGroupMembers returns all the members of a group, across every page of results.
+func (q *Query) Group_AllGroupMembers(groupID uint64) ([]*group.GroupMember, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_AllGroupMembersRPC(q, groupID)
+}
+
+// Group_AllGroupPoliciesByGroup returns all the policies of a group, across every page of results,
+// with their decision policies left packed.
+func (q *Query) Group_AllGroupPoliciesByGroup(groupID uint64) ([]*group.GroupPolicyInfo, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_AllGroupPoliciesByGroupRPC(q, groupID)
+}
+
+// Group_GroupPolicyInfo returns the group policy account at address, with its decision policy left packed.
+func (q *Query) Group_GroupPolicyInfo(address string) (*group.QueryGroupPolicyInfoResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_GroupPolicyInfoRPC(q, address)
+}
+
+// Group_AllProposalsByGroupPolicy returns all the proposals of a group policy account, across every page of results,
+// with their messages left packed.
+func (q *Query) Group_AllProposalsByGroupPolicy(address string) ([]*group.Proposal, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_AllProposalsByGroupPolicyRPC(q, address)
+}
+
+// Group_Proposal returns a single group proposal, with its messages left packed.
+func (q *Query) Group_Proposal(id uint64) (*group.QueryProposalResponse, error) {
+	/// TODO: In the future have some logic to route the query to the appropriate client (gRPC or RPC)
+	return group_ProposalRPC(q, id)
+}
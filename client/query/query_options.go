@@ -12,6 +12,21 @@ import (
 type QueryOptions struct {
 	Pagination *query.PageRequest
 	Height     int64
+
+	// MaxPages is how many pages of results the queries following every page read at most, or zero for no bound.
+	MaxPages int
+}
+
+// PageInfo describes the pages of results read by a query.
+type PageInfo struct {
+	// Read is the number of pages read.
+	Read int
+
+	// Total is the total number of results, if the first page request asked to count it.
+	Total uint64
+
+	// NextKey is the key of the next page of results, if MaxPages stopped the query before the last page.
+	NextKey []byte
 }
 
 func DefaultOptions() *QueryOptions {
@@ -26,11 +41,56 @@ func DefaultOptions() *QueryOptions {
 	}
 }
 
-// GetQueryContext returns a context that includes the height and uses the timeout from the config
+// GetQueryContext returns a context derived from q.Ctx that includes the height and uses the timeout from the config
 func (q *Query) GetQueryContext() (context.Context, context.CancelFunc) {
+	parent := q.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
 	timeout, _ := time.ParseDuration(q.Client.Config.Timeout) // Timeout is validated in the config so no error check
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	strHeight := strconv.Itoa(int(q.Options.Height))
 	ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strHeight)
 	return ctx, cancel
 }
+
+// allPages calls fetch with successive page requests, starting from the options' pagination,
+// until the page response has no next key, or until MaxPages pages are read.
+// The pages read are described in q.Pages.
+func (q *Query) allPages(fetch func(pr *query.PageRequest) (*query.PageResponse, error)) error {
+	var pr query.PageRequest
+	if q.Options.Pagination != nil {
+		pr = *q.Options.Pagination
+	}
+	q.Pages = PageInfo{}
+
+	for {
+		res, err := fetch(&pr)
+		if err != nil {
+			return err
+		}
+		q.Pages.Read++
+		if res != nil && pr.CountTotal {
+			q.Pages.Total = res.Total
+		}
+		if res == nil || len(res.NextKey) == 0 {
+			return nil
+		}
+		if q.Options.MaxPages > 0 && q.Pages.Read >= q.Options.MaxPages {
+			q.Pages.NextKey = res.NextKey
+			return nil
+		}
+
+		// The key replaces the offset once the first page is read, and the total is only counted once.
+		pr.Key = res.NextKey
+		pr.Offset = 0
+		pr.CountTotal = false
+	}
+}
+
+// AllPages calls fetch with successive page requests as the paged queries of Query do,
+// for queries made outside of Query, such as those invoked through gRPC reflection.
+// Only the pagination and MaxPages of the options apply; the pages read are described in q.Pages.
+func (q *Query) AllPages(fetch func(pr *query.PageRequest) (*query.PageResponse, error)) error {
+	return q.allPages(fetch)
+}
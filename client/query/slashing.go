@@ -0,0 +1,53 @@
+package query
+
+import (
+	"github.com/cosmos/cosmos-sdk/types/query"
+	slashingTypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+)
+
+// slashing_ParamsRPC returns the slashing params
+func slashing_ParamsRPC(q *Query) (*slashingTypes.QueryParamsResponse, error) {
+	req := &slashingTypes.QueryParamsRequest{}
+	queryClient := slashingTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.Params(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// slashing_SigningInfoRPC returns the signing info of the validator with the given consensus address.
+func slashing_SigningInfoRPC(q *Query, consAddress string) (*slashingTypes.QuerySigningInfoResponse, error) {
+	req := &slashingTypes.QuerySigningInfoRequest{ConsAddress: consAddress}
+	queryClient := slashingTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.SigningInfo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// slashing_AllSigningInfosRPC returns the signing infos of all validators, requesting every page of the results in turn.
+func slashing_AllSigningInfosRPC(q *Query) ([]slashingTypes.ValidatorSigningInfo, error) {
+	queryClient := slashingTypes.NewQueryClient(q.Client)
+	var infos []slashingTypes.ValidatorSigningInfo
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &slashingTypes.QuerySigningInfosRequest{Pagination: pr}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.SigningInfos(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, res.Info...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
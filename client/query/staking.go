@@ -1,6 +1,7 @@
 package query
 
 import (
+	"github.com/cosmos/cosmos-sdk/types/query"
 	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 )
 
@@ -124,6 +125,91 @@ func staking_DelegatorUnbondingDelegationsRPC(q *Query, delegator string) (*stak
 	return res, nil
 }
 
+// staking_AllDelegatorDelegationsRPC returns all the delegations of a delegator,
+// requesting every page of the results in turn.
+func staking_AllDelegatorDelegationsRPC(q *Query, delegator string) (stakingTypes.DelegationResponses, error) {
+	// ensure the delegator parameter is a valid account address
+	_, err := q.Client.DecodeBech32AccAddr(delegator)
+	if err != nil {
+		return nil, err
+	}
+	queryClient := stakingTypes.NewQueryClient(q.Client)
+	var delegations stakingTypes.DelegationResponses
+	err = q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &stakingTypes.QueryDelegatorDelegationsRequest{
+			DelegatorAddr: delegator,
+			Pagination:    pr,
+		}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.DelegatorDelegations(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, res.DelegationResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// staking_AllDelegatorUnbondingDelegationsRPC returns all the unbonding delegations of a delegator,
+// requesting every page of the results in turn.
+func staking_AllDelegatorUnbondingDelegationsRPC(q *Query, delegator string) ([]stakingTypes.UnbondingDelegation, error) {
+	// ensure the delegator parameter is a valid account address
+	_, err := q.Client.DecodeBech32AccAddr(delegator)
+	if err != nil {
+		return nil, err
+	}
+	queryClient := stakingTypes.NewQueryClient(q.Client)
+	var unbondings []stakingTypes.UnbondingDelegation
+	err = q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &stakingTypes.QueryDelegatorUnbondingDelegationsRequest{
+			DelegatorAddr: delegator,
+			Pagination:    pr,
+		}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.DelegatorUnbondingDelegations(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		unbondings = append(unbondings, res.UnbondingResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return unbondings, nil
+}
+
+// staking_AllValidatorsRPC returns all the validators for a given status,
+// requesting every page of the results in turn.
+func staking_AllValidatorsRPC(q *Query, status string) (stakingTypes.Validators, error) {
+	queryClient := stakingTypes.NewQueryClient(q.Client)
+	var validators stakingTypes.Validators
+	err := q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &stakingTypes.QueryValidatorsRequest{
+			Status:     status,
+			Pagination: pr,
+		}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.Validators(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, res.Validators...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
 // staking_ValidatorsRPC returns all the validators for a given status
 func staking_ValidatorsRPC(q *Query, status string) (*stakingTypes.QueryValidatorsResponse, error) {
 	queryClient := stakingTypes.NewQueryClient(q.Client)
@@ -181,6 +267,35 @@ func staking_ValidatorDelegationsRPC(q *Query, validator string) (*stakingTypes.
 	return res, nil
 }
 
+// staking_AllValidatorDelegationsRPC returns all the delegations for a validator, requesting every page of the results in turn.
+func staking_AllValidatorDelegationsRPC(q *Query, validator string) (stakingTypes.DelegationResponses, error) {
+	// ensure the validator parameter is a valid validator address
+	_, err := q.Client.DecodeBech32ValAddr(validator)
+	if err != nil {
+		return nil, err
+	}
+	queryClient := stakingTypes.NewQueryClient(q.Client)
+	var delegations stakingTypes.DelegationResponses
+	err = q.allPages(func(pr *query.PageRequest) (*query.PageResponse, error) {
+		req := &stakingTypes.QueryValidatorDelegationsRequest{
+			ValidatorAddr: validator,
+			Pagination:    pr,
+		}
+		ctx, cancel := q.GetQueryContext()
+		defer cancel()
+		res, err := queryClient.ValidatorDelegations(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, res.DelegationResponses...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
 // staking_ValidatorUnbondingDelegationsRPC returns all the unbonding delegations for a validator
 func staking_ValidatorUnbondingDelegationsRPC(q *Query, validator string) (*stakingTypes.QueryValidatorUnbondingDelegationsResponse, error) {
 	// ensure the validator parameter is a valid validator address
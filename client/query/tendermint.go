@@ -1,7 +1,6 @@
 package query
 
 import (
-	"context"
 	"encoding/hex"
 	"errors"
 	"strings"
@@ -121,7 +120,9 @@ func TxsRPC(q *Query, events []string) (*coretypes.ResultTxSearch, error) {
 	page := int(q.Options.Pagination.Offset/q.Options.Pagination.Limit) + 1 // page is 1-indexed, not 0-indexed
 	limit := int(q.Options.Pagination.Limit)
 
-	res, err := q.Client.RPCClient.TxSearch(context.Background(), strings.Join(events, " AND "), true, &page, &limit, "")
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := q.Client.RPCClient.TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	upgradeTypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+)
+
+// upgrade_CurrentPlanRPC returns the currently scheduled upgrade plan, if any.
+func upgrade_CurrentPlanRPC(q *Query) (*upgradeTypes.QueryCurrentPlanResponse, error) {
+	req := &upgradeTypes.QueryCurrentPlanRequest{}
+	queryClient := upgradeTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.CurrentPlan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// upgrade_AppliedPlanRPC returns the height at which the upgrade of the given name was applied, or zero if it was not.
+func upgrade_AppliedPlanRPC(q *Query, name string) (*upgradeTypes.QueryAppliedPlanResponse, error) {
+	req := &upgradeTypes.QueryAppliedPlanRequest{Name: name}
+	queryClient := upgradeTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.AppliedPlan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// upgrade_ModuleVersionsRPC returns the consensus versions of the modules, or of the module of the given name if it is not empty.
+func upgrade_ModuleVersionsRPC(q *Query, module string) (*upgradeTypes.QueryModuleVersionsResponse, error) {
+	req := &upgradeTypes.QueryModuleVersionsRequest{ModuleName: module}
+	queryClient := upgradeTypes.NewQueryClient(q.Client)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+	res, err := queryClient.ModuleVersions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
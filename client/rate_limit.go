@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRateLimitRetries is how many times a request throttled by an endpoint is retried by default.
+const DefaultRateLimitRetries = 3
+
+const (
+	// rateLimitBaseBackoff is how long to back off before the first retry of a throttled request;
+	// each further retry backs off twice as long, up to rateLimitMaxBackoff.
+	rateLimitBaseBackoff = 500 * time.Millisecond
+	rateLimitMaxBackoff  = 30 * time.Second
+)
+
+// RateLimit paces the requests sent to an endpoint, and retries those the endpoint throttles.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which requests are sent to each endpoint, or zero not to pace them.
+	RequestsPerSecond float64
+
+	// Burst is how many requests may be sent at once before the rate applies.
+	Burst int
+
+	// Retries is how many times a request the endpoint throttles, with HTTP 429 or gRPC RESOURCE_EXHAUSTED,
+	// is retried after backing off exponentially.
+	Retries int
+}
+
+// ParseRateLimit parses a rate limit given as RATE or RATE:BURST requests per second, such as 5 or 10:20.
+// Without a burst, as many requests as the rate may be sent at once. An empty string does not pace requests.
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "" {
+		return RateLimit{}, nil
+	}
+	rateStr, burstStr, hasBurst := strings.Cut(s, ":")
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+		return RateLimit{}, errors.New("must be a positive number of requests per second, optionally followed by :BURST")
+	}
+	burst := int(math.Ceil(rate))
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+			return RateLimit{}, errors.New("burst must be a positive number of requests")
+		}
+	}
+	return RateLimit{RequestsPerSecond: rate, Burst: burst}, nil
+}
+
+// RateLimiting returns the configured rate limit of the requests to each endpoint of the chain.
+func (ccc *ChainClientConfig) RateLimiting() (RateLimit, error) {
+	limit, err := ParseRateLimit(ccc.RateLimit)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid rate-limit %q: %w", ccc.RateLimit, err)
+	}
+	switch {
+	case ccc.RateLimitRetries == 0:
+		limit.Retries = DefaultRateLimitRetries
+	case ccc.RateLimitRetries > 0:
+		limit.Retries = ccc.RateLimitRetries
+	}
+	return limit, nil
+}
+
+// clock is the time of a RateLimiter, faked in tests.
+type clock interface {
+	Now() time.Time
+	// Sleep waits for d, or until ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimiter paces the requests sent to endpoints, each with a token bucket of its own,
+// and retries the requests the endpoints throttle, logging each retry at warn level.
+type RateLimiter struct {
+	limit RateLimit
+	log   *zap.Logger
+	clock clock
+	// jitter returns a random duration in [0, d).
+	jitter func(d time.Duration) time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter returns a RateLimiter applying limit to every endpoint.
+func NewRateLimiter(log *zap.Logger, limit RateLimit) *RateLimiter {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &RateLimiter{
+		limit:   limit,
+		log:     log,
+		clock:   realClock{},
+		jitter:  func(d time.Duration) time.Duration { return time.Duration(rand.Int63n(int64(d))) },
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Wait waits until a request may be sent to endpoint, or until ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	if l.limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	b, ok := l.buckets[endpoint]
+	if !ok {
+		b = &tokenBucket{rate: l.limit.RequestsPerSecond, burst: float64(l.limit.Burst)}
+		l.buckets[endpoint] = b
+	}
+	l.mu.Unlock()
+
+	if d := b.reserve(l.clock.Now()); d > 0 {
+		return l.clock.Sleep(ctx, d)
+	}
+	return nil
+}
+
+// do sends a request to endpoint with send, once the rate limit allows it,
+// and again after backing off for as long as send reports that the endpoint throttled it, up to the retries of the limit.
+// send may ask to back off for at least retryAfter, as given by the endpoint.
+func (l *RateLimiter) do(ctx context.Context, endpoint, method string, send func() (throttled bool, retryAfter time.Duration, err error)) error {
+	for attempt := 0; ; attempt++ {
+		if err := l.Wait(ctx, endpoint); err != nil {
+			return err
+		}
+		throttled, retryAfter, err := send()
+		if !throttled || attempt >= l.limit.Retries {
+			return err
+		}
+
+		backoff := l.backoff(attempt)
+		if retryAfter > backoff {
+			backoff = retryAfter
+		}
+		l.log.Warn(
+			"Endpoint throttled the request; backing off",
+			zap.String("endpoint", endpoint),
+			zap.String("method", method),
+			zap.Int("retry", attempt+1),
+			zap.Int("retries", l.limit.Retries),
+			zap.Duration("backoff", backoff),
+		)
+		if err := l.clock.Sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns how long to back off before retry attempt+1:
+// between half and all of rateLimitBaseBackoff doubled attempt times, capped at rateLimitMaxBackoff.
+func (l *RateLimiter) backoff(attempt int) time.Duration {
+	d := rateLimitMaxBackoff
+	if attempt < 16 {
+		if exp := rateLimitBaseBackoff << attempt; exp < d {
+			d = exp
+		}
+	}
+	return d/2 + l.jitter(d/2)
+}
+
+// RPCTransport returns rt pacing the requests it sends to endpoint, and retrying those answered with HTTP 429.
+// Requests whose body cannot be read again are not retried.
+func (l *RateLimiter) RPCTransport(endpoint string, rt http.RoundTripper) http.RoundTripper {
+	return &rateLimitTransport{l: l, endpoint: endpoint, next: rt}
+}
+
+// rateLimitTransport is the http.RoundTripper returned by RateLimiter.RPCTransport.
+type rateLimitTransport struct {
+	l        *RateLimiter
+	endpoint string
+	next     http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var res *http.Response
+	first := true
+	err := t.l.do(req.Context(), t.endpoint, rpcMethod(req), func() (bool, time.Duration, error) {
+		r := req
+		if !first {
+			drainAndClose(res.Body)
+			res = nil
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return false, 0, err
+				}
+				r.Body = body
+			}
+		}
+		first = false
+
+		var err error
+		res, err = t.next.RoundTrip(r)
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || (req.Body != nil && req.GetBody == nil) {
+			return false, 0, err
+		}
+		return true, retryAfter(res.Header.Get("Retry-After")), nil
+	})
+	if err != nil {
+		if res != nil {
+			drainAndClose(res.Body)
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// retryAfter returns the delay given by the value of a Retry-After header in seconds, or zero.
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// GRPCDialOptions returns the options making a gRPC connection to endpoint pace its calls and opened streams,
+// and retry the unary calls failing with RESOURCE_EXHAUSTED because the endpoint throttled them.
+func (l *RateLimiter) GRPCDialOptions(endpoint string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return l.do(ctx, endpoint, method, func() (bool, time.Duration, error) {
+				err := invoker(ctx, method, req, reply, cc, opts...)
+				return isThrottled(err), 0, err
+			})
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			if err := l.Wait(ctx, endpoint); err != nil {
+				return nil, err
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+// isThrottled reports whether err is a RESOURCE_EXHAUSTED status of an endpoint throttling calls,
+// rather than one of a response exceeding the largest message received.
+func isThrottled(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.ResourceExhausted && !strings.Contains(st.Message(), "message larger than max")
+}
+
+// tokenBucket holds the tokens of the requests that may be sent to an endpoint,
+// refilled at rate tokens per second up to burst.
+type tokenBucket struct {
+	rate, burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at now, and returns how long to wait until it is available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// drainAndClose reads the rest of body and closes it, so that its connection is reused.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	body.Close()
+}
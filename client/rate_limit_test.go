@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClock is a clock whose Sleep records the duration and advances the time by it, without waiting.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// newTestRateLimiter returns a RateLimiter with a fake clock and no jitter, logging to the returned logs.
+func newTestRateLimiter(limit RateLimit) (*RateLimiter, *fakeClock, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.WarnLevel)
+	l := NewRateLimiter(zap.New(core), limit)
+	c := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l.clock = c
+	l.jitter = func(time.Duration) time.Duration { return 0 }
+	return l, c, logs
+}
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]RateLimit{
+		"":      {},
+		"5":     {RequestsPerSecond: 5, Burst: 5},
+		"0.5":   {RequestsPerSecond: 0.5, Burst: 1},
+		"10:20": {RequestsPerSecond: 10, Burst: 20},
+	} {
+		limit, err := ParseRateLimit(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, limit, s)
+	}
+	for _, s := range []string{"fast", "0", "-1", "5:0", "5:x", "Inf"} {
+		_, err := ParseRateLimit(s)
+		require.Error(t, err, s)
+	}
+
+	// Throttled requests are retried by default, even without a rate.
+	limit, err := (&ChainClientConfig{}).RateLimiting()
+	require.NoError(t, err)
+	require.Equal(t, RateLimit{Retries: DefaultRateLimitRetries}, limit)
+	limit, err = (&ChainClientConfig{RateLimit: "2", RateLimitRetries: -1}).RateLimiting()
+	require.NoError(t, err)
+	require.Equal(t, RateLimit{RequestsPerSecond: 2, Burst: 2}, limit)
+}
+
+func TestRateLimiter_Pacing(t *testing.T) {
+	t.Parallel()
+
+	l, c, _ := newTestRateLimiter(RateLimit{RequestsPerSecond: 2, Burst: 2})
+	ctx := context.Background()
+
+	// The burst is sent at once, then a request every half second.
+	for i := 0; i < 5; i++ {
+		require.NoError(t, l.Wait(ctx, "rpc-a"))
+	}
+	require.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}, c.sleeps)
+
+	// Each endpoint has its own bucket.
+	require.NoError(t, l.Wait(ctx, "rpc-b"))
+	require.Len(t, c.sleeps, 3)
+
+	// Tokens accumulate while idle, up to the burst.
+	c.now = c.now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Wait(ctx, "rpc-a"))
+	}
+	require.Equal(t, []time.Duration{500 * time.Millisecond}, c.sleeps[3:])
+}
+
+func TestRateLimiter_RPCTransportRetries(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		n := len(bodies)
+		mu.Unlock()
+		switch n {
+		case 1:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+		}
+	}))
+	defer srv.Close()
+
+	l, c, logs := newTestRateLimiter(RateLimit{Retries: 3})
+	httpClient := &http.Client{Transport: l.RPCTransport(srv.URL, http.DefaultTransport)}
+	const body = `{"jsonrpc":"2.0","id":1,"method":"status"}`
+	res, err := httpClient.Post(srv.URL, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// The request is sent again with its body, after the Retry-After delay, then after the backoff of the second retry.
+	require.Equal(t, []string{body, body, body}, bodies)
+	require.Equal(t, []time.Duration{2 * time.Second, 500 * time.Millisecond}, c.sleeps)
+	require.Equal(t, 2, logs.Len())
+	entry := logs.All()[1]
+	require.Equal(t, "Endpoint throttled the request; backing off", entry.Message)
+	require.Equal(t, "status", entry.ContextMap()["method"])
+	require.Equal(t, int64(2), entry.ContextMap()["retry"])
+
+	// Once the retries are exhausted, the throttled response is returned.
+	mu.Lock()
+	bodies = nil
+	mu.Unlock()
+	l, _, _ = newTestRateLimiter(RateLimit{Retries: 1})
+	httpClient = &http.Client{Transport: l.RPCTransport(srv.URL, http.DefaultTransport)}
+	res, err = httpClient.Post(srv.URL, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Len(t, bodies, 2)
+}
+
+func TestRateLimiter_Backoff(t *testing.T) {
+	t.Parallel()
+
+	l, _, _ := newTestRateLimiter(RateLimit{})
+	require.Equal(t, 250*time.Millisecond, l.backoff(0))
+	require.Equal(t, 2*time.Second, l.backoff(3))
+	require.Equal(t, 15*time.Second, l.backoff(10))
+	require.Equal(t, 15*time.Second, l.backoff(100))
+
+	// The jitter adds up to the other half of the backoff.
+	l.jitter = func(d time.Duration) time.Duration { return d - 1 }
+	require.Equal(t, 500*time.Millisecond-1, l.backoff(0))
+}
+
+func TestIsThrottled(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isThrottled(status.Error(codes.ResourceExhausted, "rate limit exceeded")))
+	require.False(t, isThrottled(status.Error(codes.ResourceExhausted, "grpc: received message larger than max (5000000 vs. 4194304)")))
+	require.False(t, isThrottled(status.Error(codes.Unavailable, "connection refused")))
+	require.False(t, isThrottled(nil))
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultRetries is how many times a request failing transiently is retried by default.
+	DefaultRetries = 2
+
+	// DefaultRetryBackoff is how long to back off before the first retry of a request failing transiently by default;
+	// each further retry backs off twice as long, up to retryMaxBackoff.
+	DefaultRetryBackoff = 250 * time.Millisecond
+
+	retryMaxBackoff = 10 * time.Second
+)
+
+// Retry retries the requests failing transiently, such as those whose connection was reset,
+// or which a load balancer answered with HTTP 502, 503, or 504.
+// Only requests reading the chain are retried: transactions are never broadcast twice.
+type Retry struct {
+	// Retries is how many times a request failing transiently is retried, or zero not to retry requests.
+	Retries int
+
+	// Backoff is how long to back off before the first retry, with jitter;
+	// each further retry backs off twice as long.
+	Backoff time.Duration
+}
+
+// Retrying returns the configured retries of the requests to the endpoints of the chain.
+func (ccc *ChainClientConfig) Retrying() (Retry, error) {
+	r := Retry{Retries: DefaultRetries, Backoff: DefaultRetryBackoff}
+	if ccc.Retries < 0 {
+		r.Retries = 0
+	} else if ccc.Retries > 0 {
+		r.Retries = ccc.Retries
+	}
+	if ccc.RetryBackoff != "" {
+		backoff, err := parseRetryBackoff(ccc.RetryBackoff)
+		if err != nil {
+			return Retry{}, fmt.Errorf("invalid retry-backoff %q: %w", ccc.RetryBackoff, err)
+		}
+		r.Backoff = backoff
+	}
+	return r, nil
+}
+
+func parseRetryBackoff(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New("must be a positive duration, such as 500ms")
+	}
+	return d, nil
+}
+
+// RetryError is the error of a request that still failed once retried.
+type RetryError struct {
+	// Attempts is how many times the request was sent.
+	Attempts int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Retrier retries the requests failing transiently, as configured by a Retry,
+// logging each retry at debug level.
+type Retrier struct {
+	retry Retry
+	log   *zap.Logger
+	clock clock
+	// jitter returns a random duration in [0, d).
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewRetrier returns a Retrier retrying requests as configured by retry.
+func NewRetrier(log *zap.Logger, retry Retry) *Retrier {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Retrier{
+		retry:  retry,
+		log:    log,
+		clock:  realClock{},
+		jitter: randomJitter,
+	}
+}
+
+// randomJitter returns a random duration in [0, d), or 0 if d is not positive,
+// as for the first retry of a backoff of 1ns.
+func randomJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// do sends a request to endpoint with send, and again after backing off for as long as send reports a transient failure,
+// up to the retries of r. A retry that would not start before the deadline of ctx is not attempted.
+// The error of a request failing every attempt is a RetryError wrapping the error of the last one.
+func (r *Retrier) do(ctx context.Context, endpoint, method string, send func() (transient bool, err error)) error {
+	for attempt := 0; ; attempt++ {
+		transient, err := send()
+		if !transient || ctx.Err() != nil {
+			if err != nil && attempt > 0 {
+				return &RetryError{Attempts: attempt + 1, Err: err}
+			}
+			return err
+		}
+
+		backoff := r.backoff(attempt)
+		deadline, hasDeadline := ctx.Deadline()
+		if attempt >= r.retry.Retries || (hasDeadline && r.clock.Now().Add(backoff).After(deadline)) {
+			if attempt == 0 {
+				return err
+			}
+			return &RetryError{Attempts: attempt + 1, Err: err}
+		}
+		r.log.Debug(
+			"Request failed transiently; retrying",
+			zap.String("endpoint", endpoint),
+			zap.String("method", method),
+			zap.Int("attempt", attempt+2),
+			zap.Int("attempts", r.retry.Retries+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		if err := r.clock.Sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns how long to back off before retry attempt+1:
+// between half and all of the configured backoff doubled attempt times, capped at retryMaxBackoff.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := retryMaxBackoff
+	if attempt < 16 {
+		if exp := r.retry.Backoff << attempt; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	return d/2 + r.jitter(d/2)
+}
+
+// RPCTransport returns rt retrying the requests to endpoint that fail transiently, with an error or HTTP 502, 503, or 504,
+// unless they broadcast a transaction or change the state of the node.
+// Requests whose body cannot be read again are not retried.
+func (r *Retrier) RPCTransport(endpoint string, rt http.RoundTripper) http.RoundTripper {
+	if r.retry.Retries <= 0 {
+		return rt
+	}
+	return &retryTransport{r: r, endpoint: endpoint, next: rt}
+}
+
+// retryTransport is the http.RoundTripper returned by Retrier.RPCTransport.
+type retryTransport struct {
+	r        *Retrier
+	endpoint string
+	next     http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := rpcMethod(req)
+	if !isIdempotentRPCMethod(method) {
+		return t.next.RoundTrip(req)
+	}
+
+	var res *http.Response
+	first := true
+	err := t.r.do(req.Context(), t.endpoint, method, func() (bool, error) {
+		r := req
+		if !first {
+			r = req.Clone(req.Context())
+			body, err := req.GetBody()
+			if err != nil {
+				return false, err
+			}
+			r.Body = body
+		}
+		first = false
+
+		var err error
+		res, err = t.next.RoundTrip(r)
+		if err != nil {
+			return req.Context().Err() == nil, err
+		}
+		switch res.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			// The response of a transient failure is discarded, so that an error states how many attempts were made.
+			drainAndClose(res.Body)
+			code := res.StatusCode
+			res = nil
+			return true, fmt.Errorf("endpoint responded with HTTP %d %s", code, http.StatusText(code))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return res, nil
+}
+
+// isIdempotentRPCMethod reports whether the RPC method only reads the state of the chain or of the node,
+// and so may be sent again: all but those broadcasting transactions or evidence, and the unsafe methods.
+// Batches and requests of unknown methods are not.
+func isIdempotentRPCMethod(method string) bool {
+	switch {
+	case method == errUnknown, method == "batch":
+		return false
+	case strings.HasPrefix(method, "broadcast_"), strings.HasPrefix(method, "unsafe_"), strings.HasPrefix(method, "dial_"):
+		return false
+	}
+	return true
+}
+
+// GRPCDialOptions returns the options making a gRPC connection to endpoint retry the unary calls
+// failing with UNAVAILABLE, except those broadcasting a transaction.
+func (r *Retrier) GRPCDialOptions(endpoint string) []grpc.DialOption {
+	if r.retry.Retries <= 0 {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if method == broadcastTxMethod {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			return r.do(ctx, endpoint, method, func() (bool, error) {
+				err := invoker(ctx, method, req, reply, cc, opts...)
+				return status.Code(err) == codes.Unavailable, err
+			})
+		}),
+	}
+}
+
+// broadcastTxMethod is the gRPC method broadcasting transactions, whose calls are not retried.
+const broadcastTxMethod = "/cosmos.tx.v1beta1.Service/BroadcastTx"
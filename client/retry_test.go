@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestRetrier returns a Retrier with a fake clock at now and no jitter, logging to the returned logs.
+func newTestRetrier(retry Retry, now time.Time) (*Retrier, *fakeClock, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	r := NewRetrier(zap.New(core), retry)
+	c := &fakeClock{now: now}
+	r.clock = c
+	r.jitter = func(time.Duration) time.Duration { return 0 }
+	return r, c, logs
+}
+
+func TestRetrying(t *testing.T) {
+	t.Parallel()
+
+	retry, err := (&ChainClientConfig{}).Retrying()
+	require.NoError(t, err)
+	require.Equal(t, Retry{Retries: DefaultRetries, Backoff: DefaultRetryBackoff}, retry)
+	retry, err = (&ChainClientConfig{Retries: 5, RetryBackoff: "1s"}).Retrying()
+	require.NoError(t, err)
+	require.Equal(t, Retry{Retries: 5, Backoff: time.Second}, retry)
+	retry, err = (&ChainClientConfig{Retries: -1}).Retrying()
+	require.NoError(t, err)
+	require.Zero(t, retry.Retries)
+
+	_, err = (&ChainClientConfig{RetryBackoff: "-1s"}).Retrying()
+	require.EqualError(t, err, `invalid retry-backoff "-1s": must be a positive duration, such as 500ms`)
+}
+
+func TestRetrier_RPCTransport(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var bodies []string
+	// statuses are the statuses of the responses to the next requests, after which requests succeed.
+	var statuses []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(b))
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+			return
+		}
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	}))
+	defer srv.Close()
+	reset := func(s ...int) {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies, statuses = nil, s
+	}
+	post := func(r *Retrier, ctx context.Context, body string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		return (&http.Client{Transport: r.RPCTransport(srv.URL, http.DefaultTransport)}).Do(req)
+	}
+	const status = `{"jsonrpc":"2.0","id":1,"method":"status"}`
+
+	// The request is sent again with its body, after backing off twice as long each time.
+	reset(http.StatusBadGateway, http.StatusServiceUnavailable)
+	r, c, logs := newTestRetrier(Retry{Retries: 2, Backoff: 100 * time.Millisecond}, time.Now())
+	res, err := post(r, context.Background(), status)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, []string{status, status, status}, bodies)
+	require.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}, c.sleeps)
+	require.Equal(t, 2, logs.Len())
+	entry := logs.All()[1]
+	require.Equal(t, "Request failed transiently; retrying", entry.Message)
+	require.Equal(t, "status", entry.ContextMap()["method"])
+	require.Equal(t, int64(3), entry.ContextMap()["attempt"])
+	require.Equal(t, int64(3), entry.ContextMap()["attempts"])
+
+	// Once the retries are exhausted, the error of the last attempt is returned with the number of attempts.
+	reset(http.StatusGatewayTimeout, http.StatusGatewayTimeout)
+	r, _, _ = newTestRetrier(Retry{Retries: 1, Backoff: time.Millisecond}, time.Now())
+	_, err = post(r, context.Background(), status)
+	require.ErrorContains(t, err, "status: failed after 2 attempts: endpoint responded with HTTP 504 Gateway Timeout")
+	var retryErr *RetryError
+	require.True(t, errors.As(err, &retryErr))
+	require.Equal(t, 2, retryErr.Attempts)
+	require.Len(t, bodies, 2)
+
+	// Transactions are never broadcast again.
+	reset(http.StatusBadGateway)
+	r, _, _ = newTestRetrier(Retry{Retries: 2, Backoff: time.Millisecond}, time.Now())
+	res, err = post(r, context.Background(), `{"jsonrpc":"2.0","id":1,"method":"broadcast_tx_sync"}`)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadGateway, res.StatusCode)
+	require.Len(t, bodies, 1)
+
+	// A retry that would start after the deadline of the request is not attempted.
+	reset(http.StatusBadGateway)
+	now := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	r, c, _ = newTestRetrier(Retry{Retries: 2, Backoff: 4 * time.Second}, now)
+	_, err = post(r, ctx, status)
+	require.ErrorContains(t, err, "status: endpoint responded with HTTP 502 Bad Gateway")
+	require.False(t, errors.As(err, &retryErr))
+	require.Empty(t, c.sleeps)
+	require.Len(t, bodies, 1)
+
+	// Without retries, the transport is not wrapped.
+	r, _, _ = newTestRetrier(Retry{Backoff: time.Second}, time.Now())
+	require.Equal(t, http.DefaultTransport, r.RPCTransport(srv.URL, http.DefaultTransport))
+}
+
+func TestRetrier_Backoff(t *testing.T) {
+	t.Parallel()
+
+	r, _, _ := newTestRetrier(Retry{Backoff: 250 * time.Millisecond}, time.Now())
+	require.Equal(t, 125*time.Millisecond, r.backoff(0))
+	require.Equal(t, time.Second, r.backoff(3))
+	require.Equal(t, 5*time.Second, r.backoff(10))
+	require.Equal(t, 5*time.Second, r.backoff(100))
+}
+
+func TestRetrier_BackoffNanosecond(t *testing.T) {
+	t.Parallel()
+
+	// The jitter of the first retry of the shortest backoff is drawn from an empty range.
+	retry, err := (&ChainClientConfig{Retries: 2, RetryBackoff: "1ns"}).Retrying()
+	require.NoError(t, err)
+	r := NewRetrier(nil, retry)
+	require.NotPanics(t, func() {
+		require.Equal(t, time.Duration(0), r.backoff(0))
+		require.Equal(t, time.Nanosecond, r.backoff(1))
+	})
+
+	attempts := 0
+	err = r.do(context.Background(), "localhost", "test", func() (bool, error) {
+		attempts++
+		return attempts < 3, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+}
+
+func TestIsIdempotentRPCMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range []string{"status", "block", "abci_query", "tx_search", "check_tx"} {
+		require.True(t, isIdempotentRPCMethod(method), method)
+	}
+	for _, method := range []string{"broadcast_tx_sync", "broadcast_tx_commit", "broadcast_evidence", "unsafe_flush_mempool", "dial_peers", "batch", errUnknown} {
+		require.False(t, isIdempotentRPCMethod(method), method)
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// sequenceManager caches the account number and the next sequence of the accounts sending transactions,
+// so that a transaction sent right after another one from the same account is given the next sequence,
+// instead of the sequence queried from the chain, which does not count the other one until it is included.
+type sequenceManager struct {
+	mu       sync.Mutex
+	accounts map[string]*accountSequence
+}
+
+func newSequenceManager() *sequenceManager {
+	return &sequenceManager{accounts: make(map[string]*accountSequence)}
+}
+
+// account returns the cached sequence of the account at address on the chain with the given ID.
+func (m *sequenceManager) account(chainID string, address sdk.AccAddress) *accountSequence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := chainID + "/" + address.String()
+	acc, ok := m.accounts[key]
+	if !ok {
+		acc = &accountSequence{}
+		m.accounts[key] = acc
+	}
+	return acc
+}
+
+// accountSequence is the cached account number and next sequence of an account.
+// Its mutex is held while a transaction is sent from the account, to serialize the assignment of sequences.
+type accountSequence struct {
+	sync.Mutex
+
+	known    bool
+	number   uint64
+	sequence uint64
+}
+
+// get returns the cached account number and next sequence, which are zero if they are not known.
+func (a *accountSequence) get() (number, sequence uint64) {
+	if !a.known {
+		return 0, 0
+	}
+	return a.number, a.sequence
+}
+
+// set caches the account number and next sequence.
+func (a *accountSequence) set(number, sequence uint64) {
+	a.known, a.number, a.sequence = true, number, sequence
+}
+
+// forget drops the cached account number and sequence, so that they are queried again.
+func (a *accountSequence) forget() {
+	a.known, a.number, a.sequence = false, 0, 0
+}
+
+// sequenceMismatchRe matches the log of the error of a transaction signed with the wrong sequence.
+var sequenceMismatchRe = regexp.MustCompile(`account sequence mismatch, expected (\d+), got \d+`)
+
+// expectedSequence returns the sequence expected by the chain, parsed from the log of the error
+// of a transaction signed with the wrong sequence, and whether log is such an error.
+func expectedSequence(log string) (uint64, bool) {
+	m := sequenceMismatchRe.FindStringSubmatch(log)
+	if m == nil {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
@@ -0,0 +1,148 @@
+package client_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// sequenceTestClient returns a client of the Cosmos Hub with a key whose account,
+// with account number 7 and sequence 3, is queried from mc, and the address of the key.
+// The client is configured by opts.
+func sequenceTestClient(t *testing.T, mc *mocks.Client, opts ...client.ChainClientOption) (*client.ChainClient, sdk.AccAddress) {
+	t.Helper()
+
+	homepath := t.TempDir()
+	ccc := client.GetCosmosHubConfig(homepath, true)
+	ccc.Modules = client.ModuleBasics
+	cl, err := client.NewChainClientWithOptions(zaptest.NewLogger(t), ccc, homepath, nil, nil, opts...)
+	require.NoError(t, err)
+	_, err = cl.AddKey(cl.Config.Key, 118)
+	require.NoError(t, err)
+	addr, err := cl.GetKeyAddress()
+	require.NoError(t, err)
+	cl.RPCClient = mc
+
+	account, err := codectypes.NewAnyWithValue(authtypes.NewBaseAccount(addr, nil, 7, 3))
+	require.NoError(t, err)
+	for path, res := range map[string]interface{ Marshal() ([]byte, error) }{
+		"/cosmos.auth.v1beta1.Query/Account":  &authtypes.QueryAccountResponse{Account: account},
+		"/cosmos.tx.v1beta1.Service/Simulate": &txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100000}},
+	} {
+		value, err := res.Marshal()
+		require.NoError(t, err)
+		mc.On("ABCIQueryWithOptions", mock.Anything, path, mock.Anything, rpcclient.ABCIQueryOptions{}).
+			Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value}}, nil)
+	}
+	return cl, addr
+}
+
+// signedSequence returns the sequence the transaction tx was signed with.
+func signedSequence(t *testing.T, cl *client.ChainClient, tx tmtypes.Tx) uint64 {
+	t.Helper()
+
+	decoded, err := cl.Codec.TxConfig.TxDecoder()(tx)
+	require.NoError(t, err)
+	sigs, err := decoded.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+	return sigs[0].Sequence
+}
+
+// accountQueries returns how many times the account was queried from mc.
+func accountQueries(mc *mocks.Client) int {
+	var n int
+	for _, call := range mc.Calls {
+		if call.Method == "ABCIQueryWithOptions" && call.Arguments.Get(1) == "/cosmos.auth.v1beta1.Query/Account" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSendMsgs_SequenceCache(t *testing.T) {
+	mc := new(mocks.Client)
+	cl, addr := sequenceTestClient(t, mc)
+
+	var sequences []uint64
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sequences = append(sequences, signedSequence(t, cl, args.Get(1).(tmtypes.Tx)))
+	}).Return(&coretypes.ResultBroadcastTx{
+		Code:      32,
+		Codespace: "sdk",
+		Log:       "account sequence mismatch, expected 5, got 3: incorrect account sequence",
+	}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sequences = append(sequences, signedSequence(t, cl, args.Get(1).(tmtypes.Tx)))
+	}).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{1}}, nil)
+
+	ctx := context.Background()
+	msg := banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	opts := client.TxOptions{BroadcastMode: client.BroadcastSync}
+
+	// The transaction signed with the queried sequence is rejected, and signed again with the expected one.
+	_, err := cl.SendMsgsWithOptions(ctx, []sdk.Msg{msg}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 5}, sequences)
+	queries := accountQueries(mc)
+
+	// The next transaction is given the next sequence, without querying the account.
+	_, err = cl.SendMsgsWithOptions(ctx, []sdk.Msg{msg}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 5, 6}, sequences)
+	require.Equal(t, queries, accountQueries(mc))
+
+	// Without the cache, the account is queried.
+	opts.NoSequenceCache = true
+	_, err = cl.SendMsgsWithOptions(ctx, []sdk.Msg{msg}, opts)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 5, 6, 3}, sequences)
+	require.Greater(t, accountQueries(mc), queries)
+}
+
+func TestSendMsgs_ConcurrentSequences(t *testing.T) {
+	mc := new(mocks.Client)
+	cl, addr := sequenceTestClient(t, mc)
+
+	var mu sync.Mutex
+	var sequences []uint64
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		sequences = append(sequences, signedSequence(t, cl, args.Get(1).(tmtypes.Tx)))
+	}).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{1}}, nil)
+
+	msg := banktypes.NewMsgSend(addr, addr, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cl.SendMsgsWithOptions(context.Background(), []sdk.Msg{msg}, client.TxOptions{BroadcastMode: client.BroadcastSync})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Every transaction is given its own sequence.
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	require.Equal(t, []uint64{3, 4, 5, 6, 7}, sequences)
+}
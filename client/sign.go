@@ -0,0 +1,338 @@
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+// SignerCheck is the result of checking the signature of one signer of a transaction.
+type SignerCheck struct {
+	Signer string
+	// SignedSequence is the account sequence the signer signed the transaction with.
+	SignedSequence uint64
+	// AccountSequence is the current sequence of the signer's account.
+	// The transaction is rejected by the chain unless it is SignedSequence.
+	AccountSequence uint64
+}
+
+// VerifyTxSignatures verifies every signature of tx against the chain ID of the chain,
+// and the current account number of its signer, and returns the signed and current sequences of each signer.
+// A signature that does not verify means tx was signed for another chain, or by another account.
+func (cc *ChainClient) VerifyTxSignatures(tx sdk.Tx) ([]SignerCheck, error) {
+	sigTx, ok := tx.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil, fmt.Errorf("transaction of type %T cannot be verified", tx)
+	}
+
+	var signers []sdk.AccAddress
+	func() {
+		// The signers of messages are decoded with the global bech32 prefixes.
+		done := cc.SetSDKContext()
+		defer done()
+		signers = sigTx.GetSigners()
+	}()
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("transaction is not signed")
+	}
+	if len(sigs) != len(signers) {
+		return nil, fmt.Errorf("transaction has %d signatures, but %d signers", len(sigs), len(signers))
+	}
+
+	checks := make([]SignerCheck, len(signers))
+	for i, signer := range signers {
+		address, err := cc.EncodeBech32AccAddr(signer)
+		if err != nil {
+			return nil, err
+		}
+		sig := sigs[i]
+		if sig.PubKey == nil {
+			return nil, fmt.Errorf("signature of %s has no public key", address)
+		}
+
+		account, err := cc.GetAccount(client.Context{}, signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query account of signer %s: %w", address, err)
+		}
+
+		signerData := authsigning.SignerData{
+			Address:       address,
+			ChainID:       cc.Config.ChainID,
+			AccountNumber: account.GetAccountNumber(),
+			Sequence:      sig.Sequence,
+			PubKey:        sig.PubKey,
+		}
+		if err := authsigning.VerifySignature(sig.PubKey, signerData, sig.Data, cc.Codec.TxConfig.SignModeHandler(), sigTx); err != nil {
+			return nil, fmt.Errorf(
+				"signature of %s does not verify for chain ID %s and account number %d: the transaction was signed for another chain or account: %w",
+				address, cc.Config.ChainID, account.GetAccountNumber(), err,
+			)
+		}
+
+		checks[i] = SignerCheck{
+			Signer:          address,
+			SignedSequence:  sig.Sequence,
+			AccountSequence: account.GetSequence(),
+		}
+	}
+	return checks, nil
+}
+
+// SignTx signs txb with the chain's key, for the given account number and sequence of its account,
+// in signMode, or in the chain's sign mode if it is unspecified.
+// The key must be one of the signers of the transaction.
+// The signature replaces the signatures of txb, unless appendSig is set,
+// so that a transaction can be signed by several keys in turn.
+func (cc *ChainClient) SignTx(txb client.TxBuilder, accountNumber, sequence uint64, signMode signing.SignMode, appendSig bool) error {
+	address, err := cc.GetKeyAddress()
+	if err != nil {
+		return err
+	}
+	encoded, err := cc.EncodeBech32AccAddr(address)
+	if err != nil {
+		return err
+	}
+
+	done := cc.SetSDKContext()
+	defer done()
+
+	var isSigner bool
+	for _, signer := range txb.GetTx().GetSigners() {
+		if signer.Equals(address) {
+			isSigner = true
+			break
+		}
+	}
+	if !isSigner {
+		return fmt.Errorf("key %q (%s) is not a signer of the transaction", cc.Config.Key, encoded)
+	}
+
+	if appendSig {
+		sigs, err := txb.GetTx().GetSignaturesV2()
+		if err != nil {
+			return err
+		}
+		for _, sig := range sigs {
+			if sig.PubKey != nil && address.Equals(sdk.AccAddress(sig.PubKey.Address())) {
+				return fmt.Errorf("transaction is already signed by %s", encoded)
+			}
+		}
+	}
+
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = cc.Config.SignMode()
+	}
+	txf := cc.TxFactory().
+		WithAccountNumber(accountNumber).
+		WithSequence(sequence).
+		WithSignMode(signMode)
+	return tx.Sign(txf, cc.Config.Key, txb, !appendSig)
+}
+
+// SignDoc is what a key signs to sign a transaction, in a sign mode.
+type SignDoc struct {
+	SignMode signing.SignMode
+	// Bytes are the bytes signed: the encoded SignDoc in direct sign mode, or its JSON in amino-json sign mode.
+	Bytes []byte
+	// PubKey is the public key of the signing key.
+	PubKey cryptotypes.PubKey
+}
+
+// Digest returns the SHA-256 digest of the bytes of d, which secp256k1 keys sign.
+func (d SignDoc) Digest() [sha256.Size]byte {
+	return sha256.Sum256(d.Bytes)
+}
+
+// Verify reports whether sig is a signature of d by its key.
+func (d SignDoc) Verify(sig []byte) bool {
+	return d.PubKey.VerifySignature(d.Bytes, sig)
+}
+
+// SignDoc returns the sign doc that SignTx has the chain's key sign, for the given account number and sequence of its account,
+// in signMode, or in the chain's sign mode if it is unspecified, without signing it, so that external signers can check theirs.
+// The signer infos of txb are replaced by that of the key, which the sign doc covers in direct sign mode.
+// Nothing is queried.
+func (cc *ChainClient) SignDoc(txb client.TxBuilder, accountNumber, sequence uint64, signMode signing.SignMode) (SignDoc, error) {
+	info, err := cc.Keybase.Key(cc.Config.Key)
+	if err != nil {
+		return SignDoc{}, err
+	}
+	pk, err := info.GetPubKey()
+	if err != nil {
+		return SignDoc{}, err
+	}
+	address, err := cc.EncodeBech32AccAddr(sdk.AccAddress(pk.Address()))
+	if err != nil {
+		return SignDoc{}, err
+	}
+
+	done := cc.SetSDKContext()
+	defer done()
+
+	if err := checkSigner(txb.GetTx(), pk); err != nil {
+		return SignDoc{}, fmt.Errorf("key %q %w", cc.Config.Key, err)
+	}
+
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = cc.Config.SignMode()
+	}
+	// As when signing, the signer info of the key is set, with an empty signature, before the sign bytes are computed.
+	if err := txb.SetSignatures(signing.SignatureV2{
+		PubKey:   pk,
+		Data:     &signing.SingleSignatureData{SignMode: signMode},
+		Sequence: sequence,
+	}); err != nil {
+		return SignDoc{}, err
+	}
+	signerData := authsigning.SignerData{
+		Address:       address,
+		ChainID:       cc.Config.ChainID,
+		AccountNumber: accountNumber,
+		Sequence:      sequence,
+		PubKey:        pk,
+	}
+	bz, err := cc.Codec.TxConfig.SignModeHandler().GetSignBytes(signMode, signerData, txb.GetTx())
+	if err != nil {
+		return SignDoc{}, err
+	}
+	return SignDoc{SignMode: signMode, Bytes: bz, PubKey: pk}, nil
+}
+
+// SignMultisigPart signs txb with the chain's key, as one of the keys of the multisig key multisigName,
+// for the given account number and sequence of the multisig account, and returns the signature,
+// to be combined with those of the other keys by MultisignTx.
+// Multisig accounts only support the amino-json sign mode.
+func (cc *ChainClient) SignMultisigPart(txb client.TxBuilder, multisigName string, accountNumber, sequence uint64) (signing.SignatureV2, error) {
+	multisigPub, err := cc.MultisigKey(multisigName)
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+	info, err := cc.Keybase.Key(cc.Config.Key)
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+	pk, err := info.GetPubKey()
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+	var isMember bool
+	for _, member := range multisigPub.GetPubKeys() {
+		if member.Equals(pk) {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return signing.SignatureV2{}, fmt.Errorf("key %q is not one of the keys of multisig key %q", cc.Config.Key, multisigName)
+	}
+
+	done := cc.SetSDKContext()
+	defer done()
+
+	if err := checkSigner(txb.GetTx(), multisigPub); err != nil {
+		return signing.SignatureV2{}, fmt.Errorf("multisig key %q %w", multisigName, err)
+	}
+
+	txf := cc.TxFactory().
+		WithAccountNumber(accountNumber).
+		WithSequence(sequence).
+		WithSignMode(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	// The signatures of txb are replaced, so sign a copy of it.
+	part, err := cc.Codec.TxConfig.WrapTxBuilder(txb.GetTx())
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+	if err := tx.Sign(txf, cc.Config.Key, part, true); err != nil {
+		return signing.SignatureV2{}, err
+	}
+	sigs, err := part.GetTx().GetSignaturesV2()
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+	return sigs[0], nil
+}
+
+// MultisignTx combines sigs, each made by SignMultisigPart with one of the keys of the multisig key multisigName,
+// into the signature of txb by the multisig account, with the given account number and sequence.
+// Every signature is verified, and at least the threshold of the multisig key must be given.
+// The signature replaces the signatures of txb.
+func (cc *ChainClient) MultisignTx(txb client.TxBuilder, multisigName string, sigs []signing.SignatureV2, accountNumber, sequence uint64) error {
+	multisigPub, err := cc.MultisigKey(multisigName)
+	if err != nil {
+		return err
+	}
+	address, err := cc.EncodeBech32AccAddr(sdk.AccAddress(multisigPub.Address()))
+	if err != nil {
+		return err
+	}
+
+	done := cc.SetSDKContext()
+	defer done()
+
+	if err := checkSigner(txb.GetTx(), multisigPub); err != nil {
+		return fmt.Errorf("multisig key %q %w", multisigName, err)
+	}
+
+	signerData := authsigning.SignerData{
+		Address:       address,
+		ChainID:       cc.Config.ChainID,
+		AccountNumber: accountNumber,
+		Sequence:      sequence,
+		PubKey:        multisigPub,
+	}
+	multisigSig := multisig.NewMultisig(len(multisigPub.GetPubKeys()))
+	signed := make(map[string]bool, len(sigs))
+	for _, sig := range sigs {
+		if sig.PubKey == nil {
+			return fmt.Errorf("signature has no public key")
+		}
+		signer := sdk.AccAddress(sig.PubKey.Address()).String()
+		if signed[signer] {
+			return fmt.Errorf("%s signed more than once", signer)
+		}
+		signed[signer] = true
+		if err := authsigning.VerifySignature(sig.PubKey, signerData, sig.Data, cc.Codec.TxConfig.SignModeHandler(), txb.GetTx()); err != nil {
+			return fmt.Errorf(
+				"signature of %s does not verify for multisig key %q with chain ID %s, account number %d and sequence %d: %w",
+				signer, multisigName, cc.Config.ChainID, accountNumber, sequence, err,
+			)
+		}
+		if err := multisig.AddSignatureV2(multisigSig, sig, multisigPub.GetPubKeys()); err != nil {
+			return fmt.Errorf("signature of %s: %w", signer, err)
+		}
+	}
+	if threshold := int(multisigPub.GetThreshold()); len(sigs) < threshold {
+		return fmt.Errorf(
+			"multisig key %q needs %d more signature(s): %d of its threshold of %d were given",
+			multisigName, threshold-len(sigs), len(sigs), threshold,
+		)
+	}
+
+	return txb.SetSignatures(signing.SignatureV2{
+		PubKey:   multisigPub,
+		Data:     multisigSig,
+		Sequence: sequence,
+	})
+}
+
+// checkSigner returns an error unless the account of pk is one of the signers of tx.
+// The SDK context must be set, to decode the signers of messages.
+func checkSigner(tx authsigning.Tx, pk cryptotypes.PubKey) error {
+	for _, signer := range tx.GetSigners() {
+		if signer.Equals(sdk.AccAddress(pk.Address())) {
+			return nil
+		}
+	}
+	return fmt.Errorf("(%s) is not a signer of the transaction", sdk.AccAddress(pk.Address()))
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+const simulatePath = "/cosmos.tx.v1beta1.Service/Simulate"
+
+// SimulationError is the error of a transaction whose simulation the chain rejected.
+type SimulationError struct {
+	Code      uint32
+	Codespace string
+	// Log is the log of the failure, as written by the chain.
+	Log string
+}
+
+func (e SimulationError) Error() string {
+	return fmt.Sprintf("simulation failed with code %d (%s): %s", e.Code, e.Codespace, e.Log)
+}
+
+// Simulate simulates a transaction of msgs built by txf, signed by the chain's key with an empty signature,
+// and returns the response of the chain, or a SimulationError with the code and log of the failure if it rejected it.
+// Unlike CalculateGas, a failing simulation is not retried.
+func (cc *ChainClient) Simulate(ctx context.Context, txf tx.Factory, msgs ...sdk.Msg) (res txtypes.SimulateResponse, err error) {
+	defer func() { cc.metrics.observeABCIQuery(cc.Config.ChainID, simulatePath, err) }()
+
+	keyInfo, err := cc.Keybase.Key(cc.Config.Key)
+	if err != nil {
+		return txtypes.SimulateResponse{}, err
+	}
+	txBytes, err := BuildSimTx(keyInfo, txf, msgs...)
+	if err != nil {
+		return txtypes.SimulateResponse{}, err
+	}
+
+	result, err := cc.RPCClient.ABCIQueryWithOptions(ctx, simulatePath, txBytes, rpcclient.ABCIQueryOptions{})
+	if err != nil {
+		return txtypes.SimulateResponse{}, err
+	}
+	if !result.Response.IsOK() {
+		return txtypes.SimulateResponse{}, SimulationError{
+			Code:      result.Response.Code,
+			Codespace: result.Response.Codespace,
+			Log:       result.Response.Log,
+		}
+	}
+	if err := res.Unmarshal(result.Response.Value); err != nil {
+		return txtypes.SimulateResponse{}, err
+	}
+	return res, nil
+}
+
+// AdjustGas returns the gas limit that CalculateGas sets on a transaction whose simulation used gasUsed,
+// multiplied by adjustment.
+func AdjustGas(gasUsed uint64, adjustment float64) uint64 {
+	return uint64(adjustment * float64(gasUsed))
+}
+
+// FeeForGas returns the fees of a transaction with the given gas limit, at gasPrices, rounded up as the transaction factory computes them.
+func FeeForGas(gasPrices sdk.DecCoins, gas uint64) sdk.Coins {
+	limit := sdk.NewDec(int64(gas))
+	fees := make(sdk.Coins, 0, len(gasPrices))
+	for _, gp := range gasPrices {
+		fees = append(fees, sdk.NewCoin(gp.Denom, gp.Amount.Mul(limit).Ceil().RoundInt()))
+	}
+	return fees.Sort()
+}
@@ -0,0 +1,354 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	tmbytes "github.com/cometbft/cometbft/libs/bytes"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultStreamPollInterval is how often StreamBlocks polls for new blocks without a websocket subscription,
+	// and how long it waits before fetching a block again.
+	defaultStreamPollInterval = time.Second
+
+	// streamLiveTimeout is how long StreamBlocks waits for a new block event
+	// before polling for new blocks, in case the subscription silently stopped.
+	streamLiveTimeout = 30 * time.Second
+
+	// streamMaxRetries is how many times in a row StreamBlocks fetches a block again before failing.
+	streamMaxRetries = 5
+
+	// streamSubscriber is the subscriber of the new block events of StreamBlocks.
+	streamSubscriber = "lens-stream"
+)
+
+// StreamedBlock is a block delivered by StreamBlocks.
+type StreamedBlock struct {
+	Block *tmtypes.Block
+	Hash  tmbytes.HexBytes
+
+	// Txs are the decoded transactions of the block, in order,
+	// with nil for those that the codec of the chain cannot decode.
+	Txs []sdk.Tx
+}
+
+// BlockHandler handles a block delivered by StreamBlocks.
+// Blocks are delivered one at a time, so a slow handler slows the stream down.
+// Returning an error stops the stream, without saving the cursor of the block.
+type BlockHandler func(ctx context.Context, b *StreamedBlock) error
+
+// StreamCursor is the position of a stream of blocks: the last block that was handled.
+type StreamCursor struct {
+	Height int64            `json:"height"`
+	Hash   tmbytes.HexBytes `json:"hash"`
+}
+
+// CursorStore persists the cursor of StreamBlocks, so that a restarted stream resumes after the last handled block.
+type CursorStore interface {
+	// LoadCursor returns the saved cursor, or nil if none was saved.
+	LoadCursor(ctx context.Context) (*StreamCursor, error)
+	// SaveCursor saves c, after its block was handled.
+	SaveCursor(ctx context.Context, c StreamCursor) error
+}
+
+// StreamOption configures StreamBlocks.
+type StreamOption func(*blockStream)
+
+// WithCursorStore makes StreamBlocks resume after the cursor saved in s, if any,
+// and save the cursor of each block it handles.
+func WithCursorStore(s CursorStore) StreamOption {
+	return func(bs *blockStream) {
+		bs.cursor = s
+	}
+}
+
+// WithPollInterval sets how often StreamBlocks polls for new blocks when it cannot subscribe to new block events.
+func WithPollInterval(d time.Duration) StreamOption {
+	return func(bs *blockStream) {
+		bs.pollInterval = d
+	}
+}
+
+// StreamBlocks calls handler with each block of the chain in order, starting at fromHeight, or at the latest block
+// if fromHeight is not positive, until ctx is done or handler fails.
+//
+// Blocks up to the latest one are fetched from the RPC endpoint. Then new blocks are taken from the new block events
+// of a websocket subscription, or, if the endpoint does not accept one, polled for.
+// Each block must follow the previous one: a missing height, such as an event dropped while handler was busy,
+// is fetched, and a block not chained to the previous one, as served by a node behind a load balancer
+// that is on another fork or behind, is fetched again, until streamMaxRetries attempts fail.
+// A node reporting a latest height below the stream's is waited for.
+//
+// With WithCursorStore, the stream resumes after the saved cursor instead of at fromHeight,
+// and the first block must follow the block of the cursor.
+func (cc *ChainClient) StreamBlocks(ctx context.Context, fromHeight int64, handler BlockHandler, opts ...StreamOption) error {
+	s := &blockStream{
+		cc:           cc,
+		handler:      handler,
+		pollInterval: defaultStreamPollInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s.run(ctx, fromHeight)
+}
+
+// blockStream is the state of StreamBlocks.
+type blockStream struct {
+	cc           *ChainClient
+	handler      BlockHandler
+	cursor       CursorStore
+	pollInterval time.Duration
+
+	// next is the height of the next block to handle, and prevHash the hash of the last handled block, if known.
+	next     int64
+	prevHash tmbytes.HexBytes
+}
+
+func (s *blockStream) run(ctx context.Context, fromHeight int64) error {
+	if err := s.start(ctx, fromHeight); err != nil {
+		return err
+	}
+
+	events, stop := s.subscribe(ctx)
+	defer stop()
+
+	// The latest height is queried at first, and then whenever no new block event arrived in time.
+	failures, poll := 0, true
+	for {
+		if poll {
+			status, err := s.cc.RPCClient.Status(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				failures++
+				if failures > streamMaxRetries {
+					return fmt.Errorf("failed to query the latest height: %w", err)
+				}
+				s.cc.log.Debug("Failed to query the latest height", zap.Error(err))
+				if err := s.wait(ctx, s.pollInterval); err != nil {
+					return err
+				}
+				continue
+			}
+			failures = 0
+
+			latest := status.SyncInfo.LatestBlockHeight
+			if latest < s.next-1 {
+				s.cc.log.Debug("Node is behind the stream", zap.Int64("latest_height", latest), zap.Int64("next_height", s.next))
+			}
+			if err := s.catchUp(ctx, latest); err != nil {
+				return err
+			}
+		}
+
+		timeout := s.pollInterval
+		if events != nil {
+			timeout = streamLiveTimeout
+		}
+		timer := time.NewTimer(timeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			poll = true
+		case ev, ok := <-events:
+			timer.Stop()
+			if !ok {
+				s.cc.log.Debug("New block subscription closed")
+				stop()
+				events, stop = s.subscribe(ctx)
+				poll = true
+				continue
+			}
+			if err := s.live(ctx, ev); err != nil {
+				return err
+			}
+			// A block not chained to the last handled one is fetched by polling.
+			poll = !s.handled(ev)
+		}
+	}
+}
+
+// start sets the first block to handle, after the saved cursor if any, or else at fromHeight or the latest block.
+func (s *blockStream) start(ctx context.Context, fromHeight int64) error {
+	if s.cursor != nil {
+		c, err := s.cursor.LoadCursor(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load the stream cursor: %w", err)
+		}
+		if c != nil {
+			s.next, s.prevHash = c.Height+1, c.Hash
+			return nil
+		}
+	}
+	if fromHeight > 0 {
+		s.next = fromHeight
+		return nil
+	}
+	status, err := s.cc.RPCClient.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query the latest height: %w", err)
+	}
+	s.next = status.SyncInfo.LatestBlockHeight
+	return nil
+}
+
+// subscribe subscribes to new block events, starting the RPC client's websocket if needed,
+// returning the events and a function ending the subscription.
+// If the endpoint does not accept a subscription, the events are nil, so that new blocks are polled for.
+func (s *blockStream) subscribe(ctx context.Context) (<-chan ctypes.ResultEvent, func()) {
+	rpc := s.cc.RPCClient
+	started := false
+	if !rpc.IsRunning() {
+		if err := rpc.Start(); err != nil {
+			s.cc.log.Info("Polling for new blocks: failed to start the websocket client", zap.Error(err))
+			return nil, func() {}
+		}
+		started = true
+	}
+
+	query := tmtypes.EventQueryNewBlock.String()
+	events, err := rpc.Subscribe(ctx, streamSubscriber, query)
+	if err != nil {
+		s.cc.log.Info("Polling for new blocks: failed to subscribe to new block events", zap.Error(err))
+		if started {
+			_ = rpc.Stop()
+		}
+		return nil, func() {}
+	}
+	return events, func() {
+		// The stream's context may be done already.
+		ctx, cancel := context.WithTimeout(context.Background(), endpointDialTimeout)
+		defer cancel()
+		if err := rpc.Unsubscribe(ctx, streamSubscriber, query); err != nil {
+			s.cc.log.Debug("Failed to unsubscribe from new block events", zap.Error(err))
+		}
+		if started {
+			_ = rpc.Stop()
+		}
+	}
+}
+
+// live handles the block of a new block event, after fetching the blocks missing before it.
+// An event for a block that was already handled is ignored.
+func (s *blockStream) live(ctx context.Context, ev ctypes.ResultEvent) error {
+	data, ok := ev.Data.(tmtypes.EventDataNewBlock)
+	if !ok || data.Block == nil || data.Block.Height < s.next {
+		return nil
+	}
+	if err := s.catchUp(ctx, data.Block.Height-1); err != nil {
+		return err
+	}
+	if !s.follows(data.Block) {
+		// The block is fetched from the RPC endpoint instead, by the next catch up.
+		s.cc.log.Debug("New block event does not follow the last handled block", zap.Int64("height", data.Block.Height))
+		return nil
+	}
+	return s.deliver(ctx, data.Block, data.Block.Hash())
+}
+
+// handled returns whether the block of the new block event ev was handled.
+func (s *blockStream) handled(ev ctypes.ResultEvent) bool {
+	data, ok := ev.Data.(tmtypes.EventDataNewBlock)
+	return !ok || data.Block == nil || data.Block.Height < s.next
+}
+
+// catchUp handles the blocks from the next one up to height, fetching them from the RPC endpoint.
+func (s *blockStream) catchUp(ctx context.Context, height int64) error {
+	for s.next <= height {
+		block, hash, err := s.fetch(ctx, s.next)
+		if err != nil {
+			return err
+		}
+		if err := s.deliver(ctx, block, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch returns the block at height from the RPC endpoint, and its hash,
+// fetching it again while it cannot be fetched or does not follow the last handled block.
+func (s *blockStream) fetch(ctx context.Context, height int64) (*tmtypes.Block, tmbytes.HexBytes, error) {
+	var lastErr error
+	for attempt := 0; attempt <= streamMaxRetries; attempt++ {
+		if attempt > 0 {
+			s.cc.log.Debug("Fetching block again", zap.Int64("height", height), zap.Int("attempt", attempt), zap.Error(lastErr))
+			if err := s.wait(ctx, s.pollInterval); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		res, err := s.cc.RPCClient.Block(ctx, &height)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			lastErr = err
+		case res.Block == nil || res.Block.Height != height:
+			lastErr = fmt.Errorf("the node returned another block than block %d", height)
+		case !s.follows(res.Block):
+			lastErr = fmt.Errorf("block %d does not follow the last handled block: its last block hash is %s, not %s",
+				height, res.Block.LastBlockID.Hash, s.prevHash)
+		default:
+			return res.Block, res.BlockID.Hash, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("failed to fetch block %d: %w", height, lastErr)
+}
+
+// follows returns whether block is chained to the last handled block, if its hash is known.
+func (s *blockStream) follows(block *tmtypes.Block) bool {
+	return len(s.prevHash) == 0 || bytes.Equal(block.LastBlockID.Hash, s.prevHash)
+}
+
+// deliver calls the handler with block, then saves the cursor of the block.
+func (s *blockStream) deliver(ctx context.Context, block *tmtypes.Block, hash tmbytes.HexBytes) error {
+	b := &StreamedBlock{
+		Block: block,
+		Hash:  hash,
+		Txs:   make([]sdk.Tx, len(block.Txs)),
+	}
+	decode := s.cc.Codec.TxConfig.TxDecoder()
+	for i, tx := range block.Txs {
+		if decoded, err := decode(tx); err == nil {
+			b.Txs[i] = decoded
+		} else {
+			s.cc.log.Debug("Failed to decode transaction", zap.Int64("height", block.Height), zap.Int("index", i), zap.Error(err))
+		}
+	}
+
+	if err := s.handler(ctx, b); err != nil {
+		return fmt.Errorf("failed to handle block %d: %w", block.Height, err)
+	}
+	if s.cursor != nil {
+		if err := s.cursor.SaveCursor(ctx, StreamCursor{Height: block.Height, Hash: hash}); err != nil {
+			return fmt.Errorf("failed to save the stream cursor at block %d: %w", block.Height, err)
+		}
+	}
+	s.next, s.prevHash = block.Height+1, hash
+	return nil
+}
+
+// wait waits for d, or until ctx is done.
+func (s *blockStream) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
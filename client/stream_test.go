@@ -0,0 +1,119 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryCursorStore is a CursorStore keeping the cursor in memory.
+type memoryCursorStore struct {
+	cursor *client.StreamCursor
+}
+
+func (s *memoryCursorStore) LoadCursor(context.Context) (*client.StreamCursor, error) {
+	return s.cursor, nil
+}
+
+func (s *memoryCursorStore) SaveCursor(_ context.Context, c client.StreamCursor) error {
+	s.cursor = &c
+	return nil
+}
+
+// makeChain returns blocks 1 to n of a chain, each chained to the previous one, indexed by height.
+func makeChain(n int64) map[int64]*tmtypes.Block {
+	blocks := make(map[int64]*tmtypes.Block)
+	var lastID tmtypes.BlockID
+	for h := int64(1); h <= n; h++ {
+		b := tmtypes.MakeBlock(h, nil, &tmtypes.Commit{}, nil)
+		b.LastBlockID = lastID
+		// A header without a validators hash has no hash.
+		b.ValidatorsHash = tmhash.Sum([]byte("validators"))
+		blocks[h] = b
+		lastID = tmtypes.BlockID{Hash: b.Hash()}
+	}
+	return blocks
+}
+
+// mockBlock makes mc return b as the block at its height.
+func mockBlock(mc *mocks.Client, b *tmtypes.Block) *mock.Call {
+	return mc.On("Block", mock.Anything, mock.MatchedBy(func(h *int64) bool { return h != nil && *h == b.Height })).
+		Return(&coretypes.ResultBlock{BlockID: tmtypes.BlockID{Hash: b.Hash()}, Block: b}, nil)
+}
+
+func newBlockEvent(b *tmtypes.Block) coretypes.ResultEvent {
+	return coretypes.ResultEvent{Data: tmtypes.EventDataNewBlock{Block: b}}
+}
+
+func TestStreamBlocks(t *testing.T) {
+	t.Parallel()
+
+	homepath := t.TempDir()
+	cl, err := client.NewChainClient(zaptest.NewLogger(t), client.GetCosmosHubConfig(homepath, true), homepath, nil, nil)
+	require.NoError(t, err)
+	mc := new(mocks.Client)
+	cl.RPCClient = mc
+
+	chain := makeChain(7)
+	fork := makeChain(2)
+	fork[2].LastBlockID = tmtypes.BlockID{Hash: tmhash.Sum([]byte("fork"))}
+
+	// Blocks 1 to 3 are fetched, block 2 first from a node on another fork.
+	mc.On("Status", mock.Anything).Return(&coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 3}}, nil).Once()
+	mockBlock(mc, chain[1])
+	mockBlock(mc, fork[2]).Once()
+	for _, h := range []int64{2, 3, 5, 7} {
+		mockBlock(mc, chain[h])
+	}
+	// Then blocks come from events: the event of block 5 was dropped, and block 4 is repeated.
+	events := make(chan coretypes.ResultEvent, 3)
+	events <- newBlockEvent(chain[4])
+	events <- newBlockEvent(chain[4])
+	events <- newBlockEvent(chain[6])
+	mc.On("IsRunning").Return(false)
+	mc.On("Start").Return(nil)
+	mc.On("Stop").Return(nil)
+	mc.On("Subscribe", mock.Anything, mock.Anything, "tm.event='NewBlock'").Return((<-chan coretypes.ResultEvent)(events), nil).Once()
+	mc.On("Unsubscribe", mock.Anything, mock.Anything, "tm.event='NewBlock'").Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cursor := &memoryCursorStore{}
+	var heights []int64
+	err = cl.StreamBlocks(ctx, 1, func(_ context.Context, b *client.StreamedBlock) error {
+		heights = append(heights, b.Block.Height)
+		require.Equal(t, chain[b.Block.Height].Hash(), b.Hash)
+		if b.Block.Height == 6 {
+			cancel()
+		}
+		return nil
+	}, client.WithCursorStore(cursor), client.WithPollInterval(0))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, heights)
+	require.Equal(t, &client.StreamCursor{Height: 6, Hash: chain[6].Hash()}, cursor.cursor)
+	mc.AssertCalled(t, "Unsubscribe", mock.Anything, mock.Anything, "tm.event='NewBlock'")
+
+	// A restarted stream resumes after the cursor, polling when it cannot subscribe,
+	// and the cursor of a block that failed to be handled is not saved.
+	mc.On("Status", mock.Anything).Return(&coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 7}}, nil)
+	mc.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("websocket unavailable"))
+	heights = nil
+	errStop := errors.New("stop")
+	err = cl.StreamBlocks(context.Background(), 1, func(_ context.Context, b *client.StreamedBlock) error {
+		heights = append(heights, b.Block.Height)
+		return errStop
+	}, client.WithCursorStore(cursor))
+	require.ErrorIs(t, err, errStop)
+	require.EqualError(t, err, "failed to handle block 7: stop")
+	require.Equal(t, []int64{7}, heights)
+	require.EqualValues(t, 6, cursor.cursor.Height)
+}
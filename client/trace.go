@@ -0,0 +1,252 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cosmos/gogoproto/jsonpb"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+)
+
+// Tracer logs the gRPC calls of the connections it is installed on at debug level:
+// the method, the sizes of the request and response, the duration, and the status code of each,
+// and optionally the request and response bodies as JSON, with the metadata sent.
+// Install it with GRPCDialOptions, or with UnaryClientInterceptor and StreamClientInterceptor.
+type Tracer struct {
+	log    *zap.Logger
+	bodies bool
+}
+
+// NewTracer returns a Tracer logging to log, with the bodies of the calls if bodies is set.
+func NewTracer(log *zap.Logger, bodies bool) *Tracer {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Tracer{log: log, bodies: bodies}
+}
+
+// GRPCDialOptions returns the options making a gRPC connection trace its calls and streams, or nil if t is nil.
+func (t *Tracer) GRPCDialOptions() []grpc.DialOption {
+	if t == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(t.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(t.StreamClientInterceptor()),
+	}
+}
+
+// WithTracer makes the client log its gRPC queries, sent over ABCI, with t.
+func WithTracer(t *Tracer) ChainClientOption {
+	return func(cc *ChainClient) {
+		cc.tracer = t
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor tracing unary calls.
+func (t *Tracer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		target := ""
+		if cc != nil {
+			target = cc.Target()
+		}
+		return t.trace(ctx, target, method, req, reply, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// trace traces the unary call of method to target made by invoke.
+func (t *Tracer) trace(ctx context.Context, target, method string, req, reply interface{}, invoke func(context.Context) error) error {
+	start := time.Now()
+	err := invoke(ctx)
+	fields := []zap.Field{
+		zap.String("target", target),
+		zap.String("method", method),
+		zap.Int("request_bytes", messageSize(req)),
+	}
+	if err == nil {
+		fields = append(fields, zap.Int("response_bytes", messageSize(reply)))
+	}
+	fields = append(fields,
+		zap.Duration("duration", time.Since(start)),
+		zap.String("code", status.Code(err).String()),
+	)
+	if t.bodies {
+		fields = append(fields, zap.Any("metadata", redactedMetadata(ctx)), zap.String("request", messageJSON(req)))
+		if err == nil {
+			fields = append(fields, zap.String("response", messageJSON(reply)))
+		}
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	t.log.Debug("gRPC call", fields...)
+	return err
+}
+
+// StreamClientInterceptor returns an interceptor tracing streams, logged once they end,
+// with the number and total size of the messages sent and received, and each message if bodies are traced.
+func (t *Tracer) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		s := &tracedStream{t: t, target: cc.Target(), method: method, start: time.Now()}
+		if t.bodies {
+			s.metadata = redactedMetadata(ctx)
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			s.finish(err)
+			return nil, err
+		}
+		s.ClientStream = cs
+		s.ended = make(chan struct{})
+		// A stream abandoned by canceling its context, rather than read to its end, is logged once canceled.
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.finish(status.FromContextError(ctx.Err()).Err())
+			case <-s.ended:
+			}
+		}()
+		return s, nil
+	}
+}
+
+// tracedStream is a client stream traced by a Tracer.
+type tracedStream struct {
+	grpc.ClientStream
+
+	t              *Tracer
+	target, method string
+	start          time.Time
+	metadata       map[string][]string
+
+	mu                      sync.Mutex
+	sent, received          int
+	sentBytes, receivedSize int
+	done                    bool
+	// ended is closed once the stream is logged.
+	ended chan struct{}
+}
+
+func (s *tracedStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		s.finish(err)
+		return err
+	}
+	s.mu.Lock()
+	s.sent++
+	s.sentBytes += messageSize(m)
+	s.mu.Unlock()
+	if s.t.bodies {
+		s.t.log.Debug("gRPC stream message sent", zap.String("method", s.method), zap.String("message", messageJSON(m)))
+	}
+	return nil
+}
+
+func (s *tracedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if err == io.EOF {
+			s.finish(nil)
+		} else {
+			s.finish(err)
+		}
+		return err
+	}
+	s.mu.Lock()
+	s.received++
+	s.receivedSize += messageSize(m)
+	s.mu.Unlock()
+	if s.t.bodies {
+		s.t.log.Debug("gRPC stream message received", zap.String("method", s.method), zap.String("message", messageJSON(m)))
+	}
+	return nil
+}
+
+// finish logs the stream once it ended with err, or nil if it ended normally.
+func (s *tracedStream) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	if s.ended != nil {
+		close(s.ended)
+	}
+	fields := []zap.Field{
+		zap.String("target", s.target),
+		zap.String("method", s.method),
+		zap.Int("messages_sent", s.sent),
+		zap.Int("request_bytes", s.sentBytes),
+		zap.Int("messages_received", s.received),
+		zap.Int("response_bytes", s.receivedSize),
+		zap.Duration("duration", time.Since(s.start)),
+		zap.String("code", status.Code(err).String()),
+	}
+	if s.t.bodies {
+		fields = append(fields, zap.Any("metadata", s.metadata))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	s.t.log.Debug("gRPC stream", fields...)
+}
+
+// messageSize returns the size of the message m on the wire, or -1 if it cannot be encoded.
+func messageSize(m interface{}) int {
+	bz, err := protoCodec.Marshal(m)
+	if err != nil {
+		return -1
+	}
+	return len(bz)
+}
+
+// messageJSON returns the message m as JSON, whether it is a dynamic message,
+// a message of the protobuf API, or a gogoproto message, such as those of the Cosmos SDK.
+func messageJSON(m interface{}) string {
+	var bz []byte
+	var err error
+	switch m := m.(type) {
+	case json.Marshaler:
+		bz, err = m.MarshalJSON()
+	case protov2.Message:
+		bz, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	case gogoproto.Message:
+		var b bytes.Buffer
+		err = (&jsonpb.Marshaler{OrigName: true}).Marshal(&b, m)
+		bz = b.Bytes()
+	default:
+		bz, err = json.Marshal(m)
+	}
+	if err != nil {
+		return "unavailable: " + err.Error()
+	}
+	return string(bz)
+}
+
+// redactedMetadata returns the metadata sent with the call of ctx,
+// with the values of the keys that may hold secrets, such as authorization, replaced.
+func redactedMetadata(ctx context.Context) map[string][]string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	out := make(map[string][]string, len(md))
+	for k, vs := range md {
+		if IsSensitiveGRPCHeader(k) {
+			vs = []string{"REDACTED"}
+		}
+		out[k] = vs
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+)
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	tracer := client.NewTracer(zap.New(core), true)
+	conn, err := grpc.Dial(ln.Addr().String(), append(tracer.GRPCDialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The calls are logged with their sizes and status, and their bodies and metadata, with secrets redacted.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret", "x-chain", "cosmoshub")
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Error(t, err)
+
+	calls := logs.FilterMessage("gRPC call").AllUntimed()
+	require.Len(t, calls, 2)
+	fields := calls[0].ContextMap()
+	require.Equal(t, ln.Addr().String(), fields["target"])
+	require.Equal(t, "/grpc.health.v1.Health/Check", fields["method"])
+	require.Equal(t, int64(0), fields["request_bytes"])
+	require.Equal(t, int64(2), fields["response_bytes"])
+	require.Equal(t, "OK", fields["code"])
+	require.Equal(t, `{"status":"SERVING"}`, fields["response"])
+	require.Equal(t, map[string][]string{"authorization": {"REDACTED"}, "x-chain": {"cosmoshub"}}, fields["metadata"])
+
+	fields = calls[1].ContextMap()
+	require.Equal(t, `{"service":"unknown"}`, fields["request"])
+	require.Equal(t, "NotFound", fields["code"])
+	require.NotContains(t, fields, "response")
+	require.Contains(t, fields["error"], "unknown service")
+
+	// Streams are logged once they end.
+	_, err = grpcdynamic.NewReflectionClient(conn).ListServices(context.Background())
+	require.NoError(t, err)
+	streams := func() *observer.ObservedLogs {
+		return logs.FilterMessage("gRPC stream").FilterField(zap.String("method", "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"))
+	}
+	require.Eventually(t, func() bool { return streams().Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	fields = streams().AllUntimed()[0].ContextMap()
+	require.Equal(t, int64(1), fields["messages_sent"])
+	require.Equal(t, int64(1), fields["messages_received"])
+	require.Equal(t, "OK", fields["code"])
+	require.Positive(t, logs.FilterMessageSnippet("gRPC stream message").Len())
+
+	// Without bodies, only the sizes and status of the calls are logged.
+	core, logs = observer.New(zapcore.DebugLevel)
+	var opts []grpc.DialOption
+	opts = append(opts, client.NewTracer(zap.New(core), false).GRPCDialOptions()...)
+	opts = append(opts, (*client.Tracer)(nil).GRPCDialOptions()...)
+	conn, err = grpc.Dial(ln.Addr().String(), append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	fields = logs.FilterMessage("gRPC call").AllUntimed()[0].ContextMap()
+	require.Equal(t, "OK", fields["code"])
+	require.NotContains(t, fields, "request")
+	require.NotContains(t, fields, "metadata")
+}
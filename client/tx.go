@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/avast/retry-go/v4"
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -18,6 +20,7 @@ import (
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -48,36 +51,104 @@ func (cc *ChainClient) SendMsg(ctx context.Context, msg sdk.Msg, memo string) (*
 	return cc.SendMsgs(ctx, []sdk.Msg{msg}, memo)
 }
 
+// Broadcast modes of TxOptions.
+const (
+	// BroadcastBlock waits for the transaction to be included in a block.
+	BroadcastBlock = "block"
+	// BroadcastSync waits for the transaction to pass CheckTx, but not for its inclusion.
+	BroadcastSync = "sync"
+	// BroadcastAsync returns as soon as the transaction is submitted.
+	BroadcastAsync = "async"
+)
+
+// TxOptions customize how a transaction is built and broadcast by SendMsgsWithOptions.
+// The zero value builds and broadcasts the transaction as SendMsgs does.
+type TxOptions struct {
+	Memo string
+	// MemoTemplate makes Memo a template of MemoVars, expanded by ExpandMemo when the transaction is built.
+	MemoTemplate bool
+	// Gas is the gas limit of the transaction.
+	// If zero, it is estimated by simulating the transaction, and multiplied by the chain's gas adjustment.
+	Gas uint64
+	// Fees are paid instead of the fees computed from the gas prices.
+	Fees string
+	// GasPrices replace the chain's gas prices.
+	// GasPricesAuto discovers them from the chain, as DiscoverGasPrices does.
+	GasPrices string
+	// MaxGasPrice, if set, fails the transaction if a discovered gas price exceeds the price of its denom.
+	MaxGasPrice string
+	// BroadcastMode is BroadcastBlock, the default, BroadcastSync, or BroadcastAsync.
+	BroadcastMode string
+	// BlockTimeout replaces the chain's block-timeout, how long to wait for the transaction to be included in a block.
+	BlockTimeout time.Duration
+	// FeeGranter pays the fees through a fee allowance granted to the signer,
+	// instead of the chain's fee-granter, if any.
+	FeeGranter sdk.AccAddress
+	// FeePayer pays the fees instead of the signer, and must also sign the transaction.
+	FeePayer sdk.AccAddress
+	// NoSequenceCache disables the cache of the account number and sequence of the key's account,
+	// which are then queried from the chain.
+	NoSequenceCache bool
+}
+
 // SendMsgs wraps the msgs in a StdTx, signs and sends it. An error is returned if there
 // was an issue sending the transaction. A successfully sent, but failed transaction will
 // not return an error. If a transaction is successfully sent, the result of the execution
 // of that transaction will be logged. A boolean indicating if a transaction was successfully
 // sent and executed successfully is returned.
 func (cc *ChainClient) SendMsgs(ctx context.Context, msgs []sdk.Msg, memo string) (*sdk.TxResponse, error) {
-	txf, err := cc.PrepareFactory(cc.TxFactory())
-	if err != nil {
-		return nil, err
-	}
+	return cc.SendMsgsWithOptions(ctx, msgs, TxOptions{Memo: memo})
+}
 
-	// TODO: Make this work with new CalculateGas method
-	// TODO: This is related to GRPC client stuff?
-	// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
-	_, adjusted, err := cc.CalculateGas(ctx, txf, msgs...)
-	if err != nil {
-		return nil, err
+// SendMsgsWithOptions is SendMsgs, building and broadcasting the transaction as set by opts.
+// A transaction that failed CheckTx or its execution is returned with a TxFailedError.
+//
+// Unless opts.NoSequenceCache is set, the account number and the next sequence of the key's account
+// are cached after a transaction is broadcast, and concurrent sends from the same key are serialized,
+// so that consecutive transactions are given consecutive sequences without waiting for their inclusion.
+// A transaction rejected for its sequence is signed again, once, with the sequence the chain expects.
+func (cc *ChainClient) SendMsgsWithOptions(ctx context.Context, msgs []sdk.Msg, opts TxOptions) (*sdk.TxResponse, error) {
+	txf := cc.TxFactory()
+	var acc *accountSequence
+	if !opts.NoSequenceCache && cc.sequences != nil {
+		from, err := cc.GetKeyAddress()
+		if err != nil {
+			return nil, err
+		}
+		acc = cc.sequences.account(cc.Config.ChainID, from)
+		acc.Lock()
+		defer acc.Unlock()
+		num, seq := acc.get()
+		txf = txf.WithAccountNumber(num).WithSequence(seq)
 	}
 
-	if memo != "" {
-		txf = txf.WithMemo(memo)
+	res, txf, err := cc.sendMsgs(ctx, txf, msgs, opts)
+	if seq, ok := sequenceMismatch(res, err); ok {
+		cc.log.Info(
+			"Retrying transaction with the expected account sequence",
+			zap.Uint64("signed_sequence", txf.Sequence()),
+			zap.Uint64("expected_sequence", seq),
+		)
+		cc.metrics.observeSequenceRetry(cc.Config.ChainID)
+		res, txf, err = cc.sendMsgs(ctx, cc.TxFactory().WithAccountNumber(txf.AccountNumber()).WithSequence(seq), msgs, opts)
 	}
 
-	// Set the gas amount on the transaction factory
-	txf = txf.WithGas(adjusted)
+	if acc != nil {
+		if sequenceUsed(err, opts.BroadcastMode) {
+			acc.set(txf.AccountNumber(), txf.Sequence()+1)
+		} else {
+			acc.forget()
+		}
+	}
+	return res, err
+}
 
-	// Build the transaction builder
-	txb, err := txf.BuildUnsignedTx(msgs...)
+// sendMsgs builds a transaction of msgs from txf, signs and broadcasts it,
+// returning the factory it was signed with, or txf if it was not built.
+func (cc *ChainClient) sendMsgs(ctx context.Context, txf tx.Factory, msgs []sdk.Msg, opts TxOptions) (*sdk.TxResponse, tx.Factory, error) {
+	txf, txb, err := cc.buildUnsignedTx(ctx, txf, msgs, opts)
 	if err != nil {
-		return nil, err
+		return nil, txf, err
 	}
 
 	// Attach the signature to the transaction
@@ -98,32 +169,148 @@ func (cc *ChainClient) SendMsgs(ctx context.Context, msgs []sdk.Msg, memo string
 	}()
 
 	if err != nil {
-		return nil, err
+		return nil, txf, err
 	}
 
 	// Generate the transaction bytes
 	txBytes, err := cc.Codec.TxConfig.TxEncoder()(txb.GetTx())
 	if err != nil {
-		return nil, err
+		return nil, txf, err
 	}
 
 	// Broadcast those bytes
-	res, err := cc.BroadcastTx(ctx, txBytes)
+	res, err := cc.BroadcastTxWithMode(ctx, txBytes, opts.BroadcastMode, opts.BlockTimeout)
 	if err != nil {
-		return nil, err
+		return nil, txf, err
 	}
 
 	// transaction was executed, log the success or failure using the tx response code
 	// NOTE: error is nil, logic should use the returned error to determine if the
 	// transaction was successfully executed.
 	if res.Code != 0 {
-		return res, fmt.Errorf("transaction failed with code: %d", res.Code)
+		return res, txf, TxFailedError{Code: res.Code, Codespace: res.Codespace, TxHash: res.TxHash}
+	}
+
+	return res, txf, nil
+}
+
+// sequenceMismatch returns the sequence expected by the chain,
+// and whether the transaction sent by sendMsgs was rejected for its sequence.
+// The transaction is rejected while it is simulated, by CheckTx, or, rarely, during its execution.
+func sequenceMismatch(res *sdk.TxResponse, err error) (uint64, bool) {
+	if res != nil && res.Code != 0 {
+		if seq, ok := expectedSequence(res.RawLog); ok {
+			return seq, true
+		}
+	}
+	if err != nil {
+		return expectedSequence(err.Error())
 	}
+	return 0, false
+}
+
+// sequenceUsed returns whether the transaction sent by sendMsgs in the given broadcast mode, returning err,
+// used its sequence, so that the next transaction must be signed with the following one.
+func sequenceUsed(err error, mode string) bool {
+	var failed TxFailedError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &failed):
+		// In sync mode, the transaction failed CheckTx; otherwise, its execution failed.
+		return mode != BroadcastSync
+	default:
+		// The transaction passed CheckTx, but its inclusion was not waited for until the end.
+		return errors.As(err, new(UnconfirmedTxError))
+	}
+}
 
-	return res, nil
+// BuildUnsignedTx builds a transaction of msgs, signed by the chain's key, as set by opts,
+// returning the factory to sign it with.
+// Unless opts sets the gas limit, the gas is estimated by simulating the transaction.
+func (cc *ChainClient) BuildUnsignedTx(ctx context.Context, msgs []sdk.Msg, opts TxOptions) (tx.Factory, client.TxBuilder, error) {
+	return cc.buildUnsignedTx(ctx, cc.TxFactory(), msgs, opts)
 }
 
-func (cc *ChainClient) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
+// buildUnsignedTx is BuildUnsignedTx, starting from txf.
+// The account number and sequence are queried unless txf sets them both.
+func (cc *ChainClient) buildUnsignedTx(ctx context.Context, txf tx.Factory, msgs []sdk.Msg, opts TxOptions) (tx.Factory, client.TxBuilder, error) {
+	if opts.GasPrices == GasPricesAuto || (opts.GasPrices == "" && opts.Fees == "" && cc.Config.AutoGasPrices) {
+		prices, err := cc.autoGasPrices(ctx, opts.MaxGasPrice)
+		if err != nil {
+			return tx.Factory{}, nil, err
+		}
+		opts.GasPrices = prices
+	}
+
+	switch {
+	case opts.Fees != "" && opts.GasPrices != "":
+		return tx.Factory{}, nil, fmt.Errorf("fees and gas prices cannot both be set")
+	case opts.Fees != "":
+		// The factory panics on invalid coins.
+		if _, err := sdk.ParseCoinsNormalized(opts.Fees); err != nil {
+			return tx.Factory{}, nil, fmt.Errorf("invalid fees %q: %w", opts.Fees, err)
+		}
+		txf = txf.WithGasPrices("").WithFees(opts.Fees)
+	case opts.GasPrices != "":
+		if _, err := sdk.ParseDecCoins(opts.GasPrices); err != nil {
+			return tx.Factory{}, nil, fmt.Errorf("invalid gas prices %q: %w", opts.GasPrices, err)
+		}
+		txf = txf.WithGasPrices(opts.GasPrices)
+	}
+
+	txf, err := cc.PrepareFactory(ctx, txf)
+	if err != nil {
+		return tx.Factory{}, nil, err
+	}
+
+	memo := opts.Memo
+	if opts.MemoTemplate {
+		if memo, err = cc.ExpandMemo(memo, time.Now()); err != nil {
+			return tx.Factory{}, nil, err
+		}
+	}
+	if memo != "" {
+		if err := cc.checkMemoLength(ctx, memo); err != nil {
+			return tx.Factory{}, nil, err
+		}
+		txf = txf.WithMemo(memo)
+	}
+
+	gas := opts.Gas
+	if gas == 0 {
+		// TODO: Make this work with new CalculateGas method
+		// TODO: This is related to GRPC client stuff?
+		// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
+		// The transaction is simulated without its fee granter and payer,
+		// since the simulated transaction is only signed by the key.
+		if _, gas, err = cc.CalculateGas(ctx, txf, msgs...); err != nil {
+			return tx.Factory{}, nil, err
+		}
+	}
+
+	feeGranter := opts.FeeGranter
+	if feeGranter == nil && cc.Config.FeeGranter != "" {
+		if feeGranter, err = cc.DecodeBech32AccAddr(cc.Config.FeeGranter); err != nil {
+			return tx.Factory{}, nil, fmt.Errorf("invalid fee-granter %q: %w", cc.Config.FeeGranter, err)
+		}
+	}
+	txf = txf.WithFeeGranter(feeGranter).WithFeePayer(opts.FeePayer)
+
+	// Set the gas amount on the transaction factory
+	txf = txf.WithGas(gas)
+
+	// Build the transaction builder
+	txb, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return tx.Factory{}, nil, err
+	}
+	return txf, txb, nil
+}
+
+// PrepareFactory sets the account number and sequence of the key of the chain on txf, unless it sets them both,
+// querying the account within ctx.
+func (cc *ChainClient) PrepareFactory(ctx context.Context, txf tx.Factory) (tx.Factory, error) {
 	var (
 		err      error
 		from     sdk.AccAddress
@@ -141,30 +328,18 @@ func (cc *ChainClient) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
 		return tx.Factory{}, err
 	}
 
-	cliCtx := client.Context{}.WithClient(cc.RPCClient).
-		WithInterfaceRegistry(cc.Codec.InterfaceRegistry).
-		WithChainID(cc.Config.ChainID).
-		WithCodec(cc.Codec.Marshaler)
-
 	initNum, initSeq := txf.AccountNumber(), txf.Sequence()
 	// if num or seq are already set, don't set them again
 	if initNum == 0 || initSeq == 0 {
 		// Set the account number and sequence on the transaction factory and retry if fail
 		if err = retry.Do(func() error {
-			if err = txf.AccountRetriever().EnsureExists(cliCtx, from); err != nil {
-				return err
-			}
-			return err
-		}, RtyAtt, RtyDel, RtyErr); err != nil {
-			return txf, err
-		}
-		if err = retry.Do(func() error {
-			num, seq, err = txf.AccountRetriever().GetAccountNumberSequence(cliCtx, from)
+			acc, _, err := cc.accountWithHeight(ctx, from)
 			if err != nil {
 				return err
 			}
-			return err
-		}, RtyAtt, RtyDel, RtyErr); err != nil {
+			num, seq = acc.GetAccountNumber(), acc.GetSequence()
+			return nil
+		}, RtyAtt, RtyDel, RtyErr, retry.Context(ctx)); err != nil {
 			return txf, err
 		}
 
@@ -224,10 +399,12 @@ func (cc *ChainClient) CalculateGas(ctx context.Context, txf tx.Factory, msgs ..
 		return txtypes.SimulateResponse{}, 0, err
 	}
 
-	return simRes, uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
+	return simRes, AdjustGas(simRes.GasInfo.GasUsed, txf.GasAdjustment()), nil
 }
 
-func (cc *ChainClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+func (cc *ChainClient) QueryABCI(ctx context.Context, req abci.RequestQuery) (res abci.ResponseQuery, err error) {
+	defer func() { cc.metrics.observeABCIQuery(cc.Config.ChainID, req.Path, err) }()
+
 	opts := rpcclient.ABCIQueryOptions{
 		Height: req.Height,
 		Prove:  req.Prove,
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"go.uber.org/zap"
+)
+
+// DefaultTxPollInterval is how often WaitForTx queries the awaited transaction, unless set otherwise.
+const DefaultTxPollInterval = time.Second
+
+// txSubscriber is the name under which WaitForTx subscribes to transactions.
+const txSubscriber = "lens"
+
+// WaitTxOptions tunes WaitForTx.
+type WaitTxOptions struct {
+	// Timeout is how long to wait for the transaction, or the chain's block-timeout if it is 0.
+	Timeout time.Duration
+	// PollInterval is how often to query the transaction, or DefaultTxPollInterval if it is 0.
+	PollInterval time.Duration
+	// Subscribe also subscribes to the transaction through the websocket of the RPC endpoint,
+	// to learn of its inclusion without waiting for the next query.
+	// The transaction is still queried at PollInterval, so nodes without a websocket endpoint are polled instead.
+	Subscribe bool
+}
+
+// WaitForTx waits for the transaction of the given hash to be included in a block, and returns it.
+// It returns an error wrapping ErrTimeoutAfterWaitingForTxBroadcast once the timeout of opts has passed,
+// or an InterruptedError if ctx is done first.
+// A transaction that was included but failed is returned as is, with its non-zero code.
+func (cc *ChainClient) WaitForTx(ctx context.Context, hash []byte, opts WaitTxOptions) (*ctypes.ResultTx, error) {
+	if opts.Timeout == 0 {
+		var err error
+		if opts.Timeout, err = cc.blockTimeout(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultTxPollInterval
+	}
+
+	var events <-chan ctypes.ResultEvent
+	if opts.Subscribe {
+		ch, unsubscribe, err := subscribeTx(ctx, cc.RPCClient, hash)
+		if err != nil {
+			cc.log.Debug("Failed to subscribe to the transaction, polling for it instead", zap.Error(err))
+		} else {
+			defer unsubscribe()
+			events = ch
+		}
+	}
+	return waitForTx(ctx, cc.RPCClient, hash, opts.Timeout, opts.PollInterval, events)
+}
+
+// blockTimeout returns how long to wait for a transaction to be included by default:
+// the chain's block-timeout if it is set, or else defaultBroadcastWaitTimeout.
+func (cc *ChainClient) blockTimeout() (time.Duration, error) {
+	if cc.Config.BlockTimeout == "" {
+		return defaultBroadcastWaitTimeout, nil
+	}
+	// Did you call Validate() method on ChainClientConfig struct
+	// before coming here?
+	return time.ParseDuration(cc.Config.BlockTimeout)
+}
+
+// subscribeTx subscribes through c to the inclusion of the transaction of the given hash,
+// starting the websocket connection of c if it is not running yet.
+// The returned function ends the subscription, and stops the connection if it was started.
+func subscribeTx(ctx context.Context, c rpcclient.Client, hash []byte) (<-chan ctypes.ResultEvent, func(), error) {
+	stop := func() {}
+	if !c.IsRunning() {
+		if err := c.Start(); err != nil {
+			return nil, nil, err
+		}
+		stop = func() { c.Stop() }
+	}
+
+	query := fmt.Sprintf("%s='%s' AND %s='%X'", tmtypes.EventTypeKey, tmtypes.EventTx, tmtypes.TxHashKey, hash)
+	events, err := c.Subscribe(ctx, txSubscriber, query)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return events, func() {
+		c.Unsubscribe(context.Background(), txSubscriber, query)
+		stop()
+	}, nil
+}
+
+// txQuerier queries transactions by hash.
+type txQuerier interface {
+	Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error)
+}
+
+// waitForTx queries the transaction of the given hash through q, at once and then every pollInterval,
+// until it is found, or until a transaction event for it is received on events, if it is not nil.
+// The waiting will either be canceled after the waitTimeout has run out or the context exited.
+func waitForTx(
+	ctx context.Context,
+	q txQuerier,
+	hash []byte,
+	waitTimeout time.Duration,
+	pollInterval time.Duration,
+	events <-chan ctypes.ResultEvent,
+) (*ctypes.ResultTx, error) {
+	exitAfter := time.After(waitTimeout)
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	timedOut := fmt.Errorf("timed out after %s waiting for tx inclusion: %w", waitTimeout, ErrTimeoutAfterWaitingForTxBroadcast)
+	for {
+		// The timeout takes precedence over querying again, even after a query slower than the timeout.
+		select {
+		case <-exitAfter:
+			return nil, timedOut
+		default:
+		}
+		if resTx, err := q.Tx(ctx, hash, false); err == nil && resTx != nil {
+			return resTx, nil
+		}
+
+		select {
+		case <-exitAfter:
+			return nil, timedOut
+		case <-poll.C:
+		case ev, ok := <-events:
+			if !ok {
+				// The subscription ended, as when the websocket connection is lost: keep polling.
+				events = nil
+				continue
+			}
+			if data, ok := ev.Data.(tmtypes.EventDataTx); ok {
+				return &ctypes.ResultTx{
+					Hash:     hash,
+					Height:   data.Height,
+					Index:    data.Index,
+					TxResult: data.Result,
+					Tx:       data.Tx,
+				}, nil
+			}
+		case <-ctx.Done():
+			return nil, InterruptedError{Op: "waiting for tx inclusion", Err: ctx.Err()}
+		}
+	}
+}
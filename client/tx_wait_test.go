@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// notFoundQuerier finds no transaction, counting the queries.
+type notFoundQuerier struct {
+	queries int
+}
+
+func (q *notFoundQuerier) Tx(context.Context, []byte, bool) (*ctypes.ResultTx, error) {
+	q.queries++
+	return nil, errors.New("tx not found")
+}
+
+func TestWaitForTx(t *testing.T) {
+	ctx := context.Background()
+	hash := []byte{0xab}
+
+	t.Run("event", func(t *testing.T) {
+		events := make(chan ctypes.ResultEvent, 1)
+		events <- ctypes.ResultEvent{Data: tmtypes.EventDataTx{TxResult: abci.TxResult{
+			Height: 10,
+			Tx:     []byte("tx"),
+			Result: abci.ResponseDeliverTx{Code: 5},
+		}}}
+		q := new(notFoundQuerier)
+		res, err := waitForTx(ctx, q, hash, time.Minute, time.Minute, events)
+		require.NoError(t, err)
+		require.Equal(t, hash, []byte(res.Hash))
+		require.Equal(t, int64(10), res.Height)
+		require.Equal(t, uint32(5), res.TxResult.Code)
+		require.Equal(t, 1, q.queries)
+	})
+
+	t.Run("closed subscription is polled", func(t *testing.T) {
+		events := make(chan ctypes.ResultEvent)
+		close(events)
+		q := new(notFoundQuerier)
+		_, err := waitForTx(ctx, q, hash, 50*time.Millisecond, 10*time.Millisecond, events)
+		require.ErrorIs(t, err, ErrTimeoutAfterWaitingForTxBroadcast)
+		require.Greater(t, q.queries, 2)
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := waitForTx(ctx, new(notFoundQuerier), hash, time.Minute, time.Minute, nil)
+		var interrupted InterruptedError
+		require.ErrorAs(t, err, &interrupted)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestexported "github.com/cosmos/cosmos-sdk/x/auth/vesting/exported"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+func queryAccountCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "account [chain-name] [key-or-address]",
+		Aliases: []string{"acct"},
+		Short:   "inspect an account: its number, sequence, public key, vesting schedule, and spendable balance",
+		Long: `Inspect the account of a key or address on the given chain, or on the default chain,
+to answer why it cannot send funds.
+
+` + chainAndAddressArgsHelp + `
+
+The account number, sequence, and type of public key are shown, along with the name and permissions of module accounts.
+For vesting accounts, the original vesting amount is split into the vested and still vesting amounts
+at the time of the queried block, and the end time of the schedule is shown.
+The total balance is compared with the spendable balance; the difference is locked, as by a vesting schedule.
+
+An account of a type unknown to the chain's codec is shown as its type URL and the raw JSON of its fields,
+keyed by field number.
+
+With text output, amounts are shown in the display units of their denoms, as by query bank balances, unless --raw is given.
+With other output, --human adds the balances in display units as a display field.`,
+		Args: cobra.RangeArgs(0, 2),
+		Example: fmt.Sprintf(`$ %s query account
+$ %s q account cosmoshub cosmos1...
+$ %s q acct osmosis mykey --height 1000000 -o json`,
+			appName, appName, appName),
+		ValidArgsFunction: completeChainThenKey(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, encodedAddr, err := chainClientAndAddress(a, args)
+			if err != nil {
+				return err
+			}
+			options, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			human, err := humanAmounts(cmd, a)
+			if err != nil {
+				return err
+			}
+			q := query.Query{Ctx: cmd.Context(), Client: cl, Options: options}
+
+			res, err := q.Auth_Account(encodedAddr)
+			if err != nil {
+				return err
+			}
+			block, err := q.Block()
+			if err != nil {
+				return fmt.Errorf("failed to query the block time: %w", err)
+			}
+			summary := accountSummary{Address: encodedAddr, BlockTime: block.Block.Time.UTC()}
+			summary.setAccount(cl, res.Account)
+
+			if summary.Balances, err = q.Bank_AllBalances(encodedAddr); err != nil {
+				return err
+			}
+			if summary.Spendable, err = q.Bank_SpendableBalances(encodedAddr); err != nil {
+				return err
+			}
+			summary.Locked = sdk.Coins{}
+			if locked, hasNeg := summary.Balances.SafeSub(summary.Spendable...); !hasNeg && locked != nil {
+				summary.Locked = locked
+			}
+			if summary.Balances == nil {
+				summary.Balances = sdk.Coins{}
+			}
+			if summary.Spendable == nil {
+				summary.Spendable = sdk.Coins{}
+			}
+			if human {
+				summary.setDisplay(cmd.Context(), cl, a)
+			}
+			return writeOutput(cmd, a, summary)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	addDenomFlags(cmd)
+	return cmd
+}
+
+// setDisplay resolves the denoms of the amounts of s on the chain of cl, to show them in display units.
+func (s *accountSummary) setDisplay(ctx context.Context, cl *client.ChainClient, a *appState) {
+	coins := []sdk.Coins{s.Balances}
+	if v := s.Vesting; v != nil {
+		coins = append(coins, v.OriginalVesting)
+	}
+	denoms := resolveDenoms(ctx, a, cl, coins...)
+	s.denoms = &denoms
+	s.Display = &accountDisplay{
+		Balances:  denoms.FormatCoins(s.Balances),
+		Spendable: denoms.FormatCoins(s.Spendable),
+		Locked:    denoms.FormatCoins(s.Locked),
+	}
+}
+
+// setAccount sets the account of s from any, decoded with the codec of cl,
+// computing the vesting schedule at the block time of s.
+// If the type of the account is unknown to the codec, only its type and the raw JSON of its fields are set.
+func (s *accountSummary) setAccount(cl *client.ChainClient, any *codectypes.Any) {
+	var account authtypes.AccountI
+	decoded, ok := decodeAny(cl, any, &account)
+	s.Type = decoded.Type
+	if !ok {
+		if raw, err := decodeRawProto(decoded.Payload); err == nil {
+			s.Raw, _ = json.Marshal(raw)
+		}
+		return
+	}
+
+	number, sequence := account.GetAccountNumber(), account.GetSequence()
+	s.AccountNumber, s.Sequence = &number, &sequence
+	if pk := account.GetPubKey(); pk != nil {
+		s.PubKeyType = "/" + proto.MessageName(pk)
+	}
+	if m, ok := account.(authtypes.ModuleAccountI); ok {
+		s.ModuleName = m.GetName()
+		s.Permissions = m.GetPermissions()
+	}
+	if v, ok := account.(vestexported.VestingAccount); ok {
+		s.Vesting = &vestingSummary{
+			OriginalVesting:  v.GetOriginalVesting(),
+			Vested:           v.GetVestedCoins(s.BlockTime),
+			Vesting:          v.GetVestingCoins(s.BlockTime),
+			DelegatedFree:    v.GetDelegatedFree(),
+			DelegatedVesting: v.GetDelegatedVesting(),
+			StartTime:        unixTime(v.GetStartTime()),
+			EndTime:          unixTime(v.GetEndTime()),
+		}
+	}
+}
+
+// unixTime returns the time of the Unix timestamp sec, or nil if it is unset.
+func unixTime(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
+
+// accountSummary is the result of query account.
+// The account number and sequence are only known if the account type is known to the codec;
+// otherwise Raw holds the fields of the account.
+type accountSummary struct {
+	Address       string          `json:"address"`
+	Type          string          `json:"type"`
+	AccountNumber *uint64         `json:"account_number,omitempty"`
+	Sequence      *uint64         `json:"sequence,omitempty"`
+	PubKeyType    string          `json:"pub_key_type,omitempty"`
+	ModuleName    string          `json:"module_name,omitempty"`
+	Permissions   []string        `json:"permissions,omitempty"`
+	Vesting       *vestingSummary `json:"vesting,omitempty"`
+	Raw           json.RawMessage `json:"raw,omitempty"`
+	BlockTime     time.Time       `json:"block_time"`
+	Balances      sdk.Coins       `json:"balances"`
+	Spendable     sdk.Coins       `json:"spendable"`
+	Locked        sdk.Coins       `json:"locked"`
+	Display       *accountDisplay `json:"display,omitempty"`
+
+	// denoms formats the amounts of the text output, if set.
+	denoms *client.Denoms
+}
+
+// accountDisplay is the balances of an account in the display units of their denoms.
+type accountDisplay struct {
+	Balances  string `json:"balances"`
+	Spendable string `json:"spendable"`
+	Locked    string `json:"locked"`
+}
+
+// vestingSummary is the vesting schedule of a vesting account, at the time of the queried block.
+type vestingSummary struct {
+	OriginalVesting  sdk.Coins  `json:"original_vesting"`
+	Vested           sdk.Coins  `json:"vested"`
+	Vesting          sdk.Coins  `json:"vesting"`
+	DelegatedFree    sdk.Coins  `json:"delegated_free"`
+	DelegatedVesting sdk.Coins  `json:"delegated_vesting"`
+	StartTime        *time.Time `json:"start_time,omitempty"`
+	EndTime          *time.Time `json:"end_time,omitempty"`
+}
+
+var _ fmt.Stringer = accountSummary{}
+
+// String returns the account as a list of aligned fields.
+func (s accountSummary) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Address:\t%s\n", s.Address)
+	fmt.Fprintf(w, "Type:\t%s\n", orDash(s.Type))
+	if s.AccountNumber != nil {
+		fmt.Fprintf(w, "Account number:\t%d\n", *s.AccountNumber)
+		fmt.Fprintf(w, "Sequence:\t%d\n", *s.Sequence)
+		pubKey := s.PubKeyType
+		if pubKey == "" {
+			pubKey = "none (no transaction signed yet)"
+		}
+		fmt.Fprintf(w, "Public key:\t%s\n", pubKey)
+	}
+	if s.ModuleName != "" {
+		fmt.Fprintf(w, "Module:\t%s\n", s.ModuleName)
+		fmt.Fprintf(w, "Permissions:\t%s\n", orDash(strings.Join(s.Permissions, ", ")))
+	}
+	if v := s.Vesting; v != nil {
+		fmt.Fprintf(w, "Original vesting:\t%s\n", orDash(formatCoins(s.denoms, v.OriginalVesting)))
+		fmt.Fprintf(w, "Vested:\t%s\n", orDash(formatCoins(s.denoms, v.Vested)))
+		fmt.Fprintf(w, "Vesting:\t%s\n", orDash(formatCoins(s.denoms, v.Vesting)))
+		fmt.Fprintf(w, "Delegated vesting:\t%s\n", orDash(formatCoins(s.denoms, v.DelegatedVesting)))
+		if v.StartTime != nil {
+			fmt.Fprintf(w, "Start time:\t%s\n", v.StartTime.Format(time.RFC3339))
+		}
+		endTime := "never (permanently locked)"
+		if v.EndTime != nil {
+			endTime = v.EndTime.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "End time:\t%s\n", endTime)
+	}
+	fmt.Fprintf(w, "Block time:\t%s\n", s.BlockTime.Format(time.RFC3339))
+	fmt.Fprintf(w, "Balances:\t%s\n", orDash(formatCoins(s.denoms, s.Balances)))
+	fmt.Fprintf(w, "Spendable:\t%s\n", orDash(formatCoins(s.denoms, s.Spendable)))
+	fmt.Fprintf(w, "Locked:\t%s\n", orDash(formatCoins(s.denoms, s.Locked)))
+	w.Flush()
+
+	if s.Raw != nil {
+		b.WriteString("\nAccount fields (raw, by field number):\n")
+		if err := writeJSON(&b, s.Raw); err != nil {
+			fmt.Fprintln(&b, string(s.Raw))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestQueryAccount(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	vestingAddr, err := sdk.GetFromBech32(ZeroCosmosAddr, "cosmos")
+	require.NoError(t, err)
+	otherAddr := sdk.MustBech32ifyAddressBytes("cosmos", make([]byte, 20))
+
+	// Half of the vesting schedule has passed at the time of the block.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * 24 * time.Hour)
+	blockTime := start.Add(5 * 24 * time.Hour)
+	original := sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))
+	base := authtypes.NewBaseAccount(vestingAddr, secp256k1.GenPrivKey().PubKey(), 7, 3)
+	vestingAccount := vestingtypes.NewContinuousVestingAccount(base, original, start.Unix(), end.Unix())
+	vestingAny, err := codectypes.NewAnyWithValue(vestingAccount)
+	require.NoError(t, err)
+
+	// An account of another chain, wrapping a base account and adding a field of its own.
+	otherBase, err := authtypes.NewBaseAccount(nil, nil, 9, 2).Marshal()
+	require.NoError(t, err)
+	var otherValue []byte
+	otherValue = protowire.AppendBytes(protowire.AppendTag(otherValue, 1, protowire.BytesType), otherBase)
+	otherValue = protowire.AppendString(protowire.AppendTag(otherValue, 2, protowire.BytesType), "0xabc")
+	otherAny := &codectypes.Any{TypeUrl: "/ethermint.types.v1.EthAccount", Value: otherValue}
+
+	mc := new(mocks.Client)
+	mockBlockStatus(mc)
+	mc.On("Block", mock.Anything, matchHeight(100)).Return(&coretypes.ResultBlock{
+		Block: &tmtypes.Block{Header: tmtypes.Header{Height: 100, Time: blockTime}},
+	}, nil)
+	for addr, account := range map[string]*codectypes.Any{ZeroCosmosAddr: vestingAny, otherAddr: otherAny} {
+		addr := addr
+		mockABCIQuery(t, mc, "/cosmos.auth.v1beta1.Query/Account", func(data bytes.HexBytes) bool {
+			var req authtypes.QueryAccountRequest
+			return req.Unmarshal(data) == nil && req.Address == addr
+		}, &authtypes.QueryAccountResponse{Account: account})
+	}
+	ibcCoin := sdk.NewInt64Coin(transfertypes.ParseDenomTrace("transfer/channel-141/uosmo").IBCDenom(), 42)
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/AllBalances", func(bytes.HexBytes) bool { return true },
+		&banktypes.QueryAllBalancesResponse{Balances: original.Add(ibcCoin), Pagination: &query.PageResponse{}})
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/SpendableBalances", func(bytes.HexBytes) bool { return true },
+		&banktypes.QuerySpendableBalancesResponse{Balances: sdk.NewCoins(sdk.NewInt64Coin("uatom", 500), ibcCoin), Pagination: &query.PageResponse{}})
+
+	// The denoms are resolved once, then read from the cache.
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/DenomsMetadata", func(bytes.HexBytes) bool { return true },
+		&banktypes.QueryDenomsMetadataResponse{
+			Metadatas: []banktypes.Metadata{{
+				Base:       "uatom",
+				Display:    "atom",
+				DenomUnits: []*banktypes.DenomUnit{{Denom: "uatom"}, {Denom: "atom", Exponent: 6}},
+			}},
+			Pagination: &query.PageResponse{},
+		})
+	mockABCIQuery(t, mc, "/ibc.applications.transfer.v1.Query/DenomTrace", func(bytes.HexBytes) bool { return true },
+		&transfertypes.QueryDenomTraceResponse{DenomTrace: &transfertypes.DenomTrace{Path: "transfer/channel-141", BaseDenom: "uosmo"}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "account", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Type:               /cosmos.vesting.v1beta1.ContinuousVestingAccount\n")
+	require.Contains(t, out, "Account number:     7\n")
+	require.Contains(t, out, "Sequence:           3\n")
+	require.Contains(t, out, "Public key:         /cosmos.crypto.secp256k1.PubKey\n")
+	require.Contains(t, out, "Original vesting:   0.001 atom\n")
+	require.Contains(t, out, "Vested:             0.0005 atom\n")
+	require.Contains(t, out, "Vesting:            0.0005 atom\n")
+	require.Contains(t, out, "End time:           2026-01-11T00:00:00Z\n")
+	require.Contains(t, out, "Spendable:          42 transfer/channel-141/uosmo, 0.0005 atom\n")
+	require.Contains(t, out, "Locked:             0.0005 atom\n")
+	mc.AssertNumberOfCalls(t, "ABCIQueryWithOptions", 5)
+
+	// --raw shows the base denoms, and --human adds the display amounts to JSON.
+	res = sys.MustRun(t, "q", "account", "cosmoshub", ZeroCosmosAddr, "--raw", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Locked:             500uatom\n")
+	res = sys.MustRun(t, "q", "account", "cosmoshub", ZeroCosmosAddr, "--human", "-o", "json")
+	var display struct {
+		Locked  sdk.Coins
+		Display struct{ Locked string }
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &display))
+	require.Equal(t, "500uatom", display.Locked.String())
+	require.Equal(t, "0.0005 atom", display.Display.Locked)
+	res = sys.Run(zaptest.NewLogger(t), "q", "account", "cosmoshub", ZeroCosmosAddr, "--human", "--raw")
+	require.Equal(t, 1, res.ExitCode)
+	require.Contains(t, res.Stderr.String(), "--human and --raw cannot be used together")
+
+	// The account of an unknown type is shown with its raw fields.
+	res = sys.MustRun(t, "q", "acct", otherAddr, "-o", "json")
+	var summary struct {
+		Type          string
+		AccountNumber *uint64 `json:"account_number"`
+		Raw           map[string]interface{}
+		Spendable     sdk.Coins
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &summary))
+	require.Equal(t, "/ethermint.types.v1.EthAccount", summary.Type)
+	require.Nil(t, summary.AccountNumber)
+	require.Equal(t, map[string]interface{}{"3": 9.0, "4": 2.0}, summary.Raw["1"])
+	require.Equal(t, "0xabc", summary.Raw["2"])
+	require.Equal(t, ibcCoin.String()+",500uatom", summary.Spendable.String())
+}
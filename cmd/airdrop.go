@@ -20,7 +20,10 @@ func airdropCmd(a *appState) *cobra.Command {
 		Long:  "The airdrop file consists of map[string]float64 where the key is the address on the target chain and the value is the amount of coins to be airdropped to that address/1e6 (i.e. atom instead of uatom). The airdrop command 1. checks the addresses in the file to ensure that they are valid for the given chain l",
 		Args:  cobra.RangeArgs(3, 4),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			keyNameOrAddress := ""
 			if len(args) == 3 {
 				keyNameOrAddress = cl.Config.Key
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"net/http"
 	"os"
-	"path"
+	"time"
 
 	"github.com/spf13/viper"
+	"github.com/strangelove-ventures/lens/client"
 	"go.uber.org/zap"
 )
 
@@ -19,8 +22,55 @@ type appState struct {
 
 	HomePath        string
 	OverriddenChain string
-	Debug           bool
-	Config          *Config
+
+	// KeyringBackend is the value of the --keyring-backend flag,
+	// overriding the keyring backend of every chain, or the empty string.
+	KeyringBackend string
+
+	// Proxy is the value of the --proxy flag, overriding the proxy of every chain, or the empty string.
+	Proxy string
+
+	// RateLimit is the value of the --rate-limit flag, overriding the rate limit of every chain, or the empty string.
+	RateLimit string
+
+	// Retries is the value of the --retries flag, overriding the retries of every chain, or nil if it is not set.
+	Retries *int
+
+	// KeyringPassphraseFile is the value of the --keyring-passphrase-file flag.
+	KeyringPassphraseFile string
+
+	// Endpoint is the value of the --endpoint flag,
+	// selecting one of the configured endpoints of the chain in use.
+	Endpoint string
+
+	Debug  bool
+	Config *Config
+
+	// OutputFormat is the value of the --output flag, or if the flag was not set, the default of the running command,
+	// with the empty string meaning the text format; outputSet reports whether the flag was set.
+	OutputFormat string
+	outputSet    bool
+
+	// HTTPClient is used to fetch remote documents, such as chain registry files.
+	HTTPClient *http.Client
+
+	// Metrics records the operations of the chain clients if --metrics-listen is set, or else is nil.
+	Metrics *client.Metrics
+
+	// Tracer logs the gRPC calls of the chain clients and dynamic commands if --trace is set, or else is nil.
+	Tracer *client.Tracer
+
+	// configLock is the open configuration lock file while the running command holds the lock, or else nil.
+	configLock *os.File
+
+	// timeout is the value of the --timeout flag, and timeoutCtx the context of the running command with its deadline,
+	// while cancelTimeout releases it; all are unset without a timeout.
+	timeout       time.Duration
+	timeoutCtx    context.Context
+	cancelTimeout context.CancelFunc
+
+	// MultiChain is set on the copies of the state that run a query on one of the chains of --chains or --all-chains.
+	MultiChain bool
 }
 
 // OverwriteConfig overwrites the config files on disk with the serialization of cfg,
@@ -30,8 +80,12 @@ type appState struct {
 // but typically the argument is a.Config.
 func (a *appState) OverwriteConfig(cfg *Config) error {
 	home := a.Viper.GetString("home")
-	cfgPath := path.Join(home, "config.yaml")
-	if err := os.WriteFile(cfgPath, cfg.MustYAML(), 0600); err != nil {
+	cfgPath := configFilePath(home)
+	out, err := cfg.Marshal(cfg.format)
+	if err != nil {
+		return err
+	}
+	if err := writeConfigFile(cfgPath, out); err != nil {
 		return err
 	}
 
@@ -12,7 +12,10 @@ func authAccountCmd(a *appState) *cobra.Command {
 		Short:   "query an account for its number and sequence or pass no arguement to query default account",
 		Args:    cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			keyNameOrAddress := ""
 			if len(args) == 0 {
 				keyNameOrAddress = cl.Config.Key
@@ -28,7 +31,7 @@ func authAccountCmd(a *appState) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, codecJSON(cl, res))
 		},
 	}
 	return cmd
@@ -41,7 +44,10 @@ func authAccountsCmd(a *appState) *cobra.Command {
 		Short:   "query all accounts on a given chain w/ pagination",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			pr, err := ReadPageRequest(cmd.Flags())
 			if err != nil {
 				return err
@@ -51,7 +57,7 @@ func authAccountsCmd(a *appState) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, codecJSON(cl, res))
 		},
 	}
 	return paginationFlags(cmd, a.Viper)
@@ -64,12 +70,15 @@ func authParamsCmd(a *appState) *cobra.Command {
 		Short:   "query the current auth parameters",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			res, err := authtypes.NewQueryClient(cl).Params(cmd.Context(), &authtypes.QueryParamsRequest{})
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, codecJSON(cl, res))
 		},
 	}
 	return cmd
@@ -1,89 +1,288 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const (
+	authzMsgTypeFlag    = "msg-type"
+	authzSpendLimitFlag = "spend-limit"
+	expirationFlag      = "expiration"
 )
 
+// expirationHelp describes the formats accepted by parseExpiration, for use in flag usages.
+const expirationHelp = "as a date (2006-01-02) or an RFC 3339 time (2006-01-02T15:04:05Z)"
+
+// parseExpiration parses the value of an --expiration flag, which must be in the future.
+// The empty value is no expiration.
+func parseExpiration(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("invalid expiration %q: must be given %s", value, expirationHelp)
+		}
+	}
+	if !t.After(time.Now()) {
+		return nil, fmt.Errorf("invalid expiration %q: must be in the future", value)
+	}
+	return &t, nil
+}
+
 func authzGrantsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "grants [grantor] [grantee] [msg_type]?",
-		Aliases: []string{"grants"},
-		Short:   "query the grants for an account",
-		Args:    cobra.RangeArgs(2, 3),
+		Use:   "grants [chain-name] <granter> [grantee]",
+		Short: "query the authorizations granted by an account",
+		Long: `Query the authorizations granted by the granter, to the grantee or to any account,
+on the given chain, or on the default chain, listing the authorization type, message type, and expiration of each.
+The granter and grantee may be key names or addresses.
+
+If two arguments are given and the first names a configured chain, they are the chain and the granter;
+otherwise they are the granter and the grantee.
+Expired grants, which the chain no longer honors, are marked as such.
+Every page of grants is requested in turn.`,
+		Example: fmt.Sprintf(`$ %s query authz grants cosmoshub cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
+$ %s q authz grants mykey cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p --msg-type /cosmos.bank.v1beta1.MsgSend`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 3)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: allow the user to specify the grantor client
-			// and the grantee client. This will allow for use of a
-			// ledger as the grantor (i.e. cosmoshub-ledger in the config)
-			// and test keyringbacked for the grantee (i.e. cosmoshub)
-			cl := a.Config.GetDefaultClient()
-			pageReq, err := ReadPageRequest(cmd.Flags())
+			chainName, granterArg, granteeArg := a.Config.DefaultChain, args[0], ""
+			switch len(args) {
+			case 2:
+				if _, ok := a.Config.Chains[args[0]]; ok {
+					chainName, granterArg = args[0], args[1]
+				} else {
+					granteeArg = args[1]
+				}
+			case 3:
+				chainName, granterArg, granteeArg = args[0], args[1], args[2]
+			}
+			msgType, err := cmd.Flags().GetString(authzMsgTypeFlag)
 			if err != nil {
 				return err
 			}
 
-			orAddr, err := cl.AccountFromKeyOrAddress(args[0])
+			cl, err := chainClientByName(a, chainName)
 			if err != nil {
 				return err
 			}
-
-			eeAddr, err := cl.AccountFromKeyOrAddress(args[1])
+			granterAddr, err := cl.AccountFromKeyOrAddress(granterArg)
 			if err != nil {
 				return err
 			}
+			granter := cl.MustEncodeAccAddr(granterAddr)
 
-			var MsgTypeUrl string
-			if len(args) == 3 {
-				// TODO: input validation for msg_type
-				MsgTypeUrl = args[2]
-			}
-			req := &authz.QueryGrantsRequest{
-				Granter:    cl.MustEncodeAccAddr(orAddr),
-				Grantee:    cl.MustEncodeAccAddr(eeAddr),
-				MsgTypeUrl: MsgTypeUrl,
-				Pagination: pageReq,
-			}
-
-			res, err := authz.NewQueryClient(cl).Grants(cmd.Context(), req)
+			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+
+			var grants []*authz.GrantAuthorization
+			if granteeArg != "" {
+				granteeAddr, err := cl.AccountFromKeyOrAddress(granteeArg)
+				if err != nil {
+					return err
+				}
+				grantee := cl.MustEncodeAccAddr(granteeAddr)
+				res, err := query.Authz_AllGrants(granter, grantee, msgType)
+				if err != nil {
+					return err
+				}
+				for _, g := range res {
+					grants = append(grants, &authz.GrantAuthorization{
+						Granter:       granter,
+						Grantee:       grantee,
+						Authorization: g.Authorization,
+						Expiration:    g.Expiration,
+					})
+				}
+			} else {
+				if grants, err = query.Authz_AllGranterGrants(granter); err != nil {
+					return err
+				}
+			}
 
-			return cl.PrintObject(res)
+			result := grantsResult{}
+			now := time.Now()
+			for _, g := range grants {
+				summary := summarizeGrant(cl, g, now)
+				// The grants of a granter cannot be filtered by message type by the chain.
+				if msgType != "" && summary.MsgTypeURL != msgType {
+					continue
+				}
+				result = append(result, summary)
+			}
+			return writeOutput(cmd, a, result)
 		},
 	}
-	return paginationFlags(cmd, a.Viper)
+	addQueryHeightFlag(cmd)
+	cmd.Flags().String(authzMsgTypeFlag, "", "only list the grants for this message type URL")
+	return cmd
+}
+
+// summarizeGrant returns the summary of the grant g, checking its expiration against now.
+// The message type and spend limit are only known if the authorization type is known to the codec.
+func summarizeGrant(cl *client.ChainClient, g *authz.GrantAuthorization, now time.Time) grantSummary {
+	summary := grantSummary{
+		Granter:    g.Granter,
+		Grantee:    g.Grantee,
+		Expiration: g.Expiration,
+		Expired:    g.Expiration != nil && !g.Expiration.After(now),
+	}
+	var authorization authz.Authorization
+	var ok bool
+	summary.Authorization, ok = decodeAny(cl, g.Authorization, &authorization)
+	if ok {
+		summary.MsgTypeURL = authorization.MsgTypeURL()
+		if send, isSend := authorization.(*banktypes.SendAuthorization); isSend {
+			summary.SpendLimit = send.SpendLimit
+		}
+	}
+	return summary
+}
+
+// grantSummary is one grant listed by query authz grants.
+type grantSummary struct {
+	Granter       string     `json:"granter"`
+	Grantee       string     `json:"grantee"`
+	Authorization decodedAny `json:"authorization"`
+	MsgTypeURL    string     `json:"msg_type_url,omitempty"`
+	SpendLimit    sdk.Coins  `json:"spend_limit,omitempty"`
+	Expiration    *time.Time `json:"expiration"`
+	Expired       bool       `json:"expired"`
+}
+
+// grantsResult is the result of query authz grants.
+type grantsResult []grantSummary
+
+var _ fmt.Stringer = grantsResult{}
+
+// String returns the grants as a table with aligned columns.
+func (r grantsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GRANTEE\tAUTHORIZATION\tMSG TYPE\tSPEND LIMIT\tEXPIRATION")
+	for _, g := range r {
+		expiration := "never"
+		if g.Expiration != nil {
+			expiration = g.Expiration.UTC().Format(time.RFC3339)
+		}
+		if g.Expired {
+			expiration += " (expired)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			g.Grantee, orDash(g.Authorization.Type), orDash(g.MsgTypeURL), orDash(g.SpendLimit.String()), expiration)
+	}
+	w.Flush()
+	return b.String()
 }
 
-// TODO: rethink UX here. We should break this up into a number
-// of smaller commands. For example, we should have a grantSend,
-// grantStake, grantWithdraw, grantVote, grantValidator, etc...
-// authzGrantAuthorizationCmd returns the authz grant authorization command for this module
 func authzGrantAuthorizationCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "grant [grantor] [grantee] [role]",
-		Aliases: []string{"grant"},
-		Short:   "grant an authorization",
+		Use:   "grant [chain-name] <grantee-address>",
+		Short: "grant an authorization to an account",
+		Long: `Grant the grantee an authorization to execute messages on behalf of the --from key, or of the chain's key,
+on the given chain, or on the default chain.
+
+With --spend-limit, a send authorization is granted, allowing the grantee to send up to that amount;
+otherwise a generic authorization for the message type given with --msg-type is granted.
+With --expiration, the authorization expires at that time.
+With --dry-run, the message of the transaction is written without signing or broadcasting it.`,
+		Example: fmt.Sprintf(`$ %s tx authz grant cosmoshub cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p --msg-type /cosmos.gov.v1beta1.MsgVote
+$ %s tx authz grant cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p --spend-limit 100uatom --expiration 2030-01-01 --from mykey`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: allow the user to specify the grantor client
-			// and the grantee client. This will allow for use of a
-			// ledger as the grantor (i.e. cosmoshub-ledger in the config)
-			// and test keyringbacked for the grantee (i.e. cosmoshub)
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			msgType, err := cmd.Flags().GetString(authzMsgTypeFlag)
+			if err != nil {
+				return err
+			}
+			spendLimitArg, err := cmd.Flags().GetString(authzSpendLimitFlag)
+			if err != nil {
+				return err
+			}
+			expirationArg, err := cmd.Flags().GetString(expirationFlag)
+			if err != nil {
+				return err
+			}
+			expiration, err := parseExpiration(expirationArg)
+			if err != nil {
+				return err
+			}
 
-			// cl := config.GetDefaultClient()
-			// var authorization authz.Authorization
-			// msg := &authz.MsgGrant{
-			// 	Granter: "cosmos1y54exmx84cqtasvjnskf9f63djuuj68p7hqf47",
-			// 	Grantee: "cosmos1y54exmx84cqtasvjnskf9f63djuuj68p7hqf47",
-			// 	Grant: authz.Grant{
-			// 		Authorization: authorization,
-			// 		Expiration:    0,
-			// 	},
-			// }
-			// return cl.HandleAndPrintMsgSend(cl.SendMsg(cmd.Context(), msg))
-			return nil
+			var authorization authz.Authorization
+			sendMsgType := sdk.MsgTypeURL(&banktypes.MsgSend{})
+			switch {
+			case spendLimitArg != "":
+				if msgType != "" && msgType != sendMsgType {
+					return fmt.Errorf("--%s is only valid for %s, not %s", authzSpendLimitFlag, sendMsgType, msgType)
+				}
+				spendLimit, err := sdk.ParseCoinsNormalized(spendLimitArg)
+				if err != nil {
+					return fmt.Errorf("invalid spend limit %q: %w", spendLimitArg, err)
+				}
+				authorization = banktypes.NewSendAuthorization(spendLimit, nil)
+			case msgType != "":
+				authorization = authz.NewGenericAuthorization(msgType)
+			default:
+				return fmt.Errorf("--%s or --%s is required", authzMsgTypeFlag, authzSpendLimitFlag)
+			}
+			if err := authorization.ValidateBasic(); err != nil {
+				return err
+			}
+
+			from, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			cl, granter, err := txChainClient(cmd, a, chainName, from)
+			if err != nil {
+				return err
+			}
+			grantee, err := cl.DecodeBech32AccAddr(args[len(args)-1])
+			if err != nil {
+				return fmt.Errorf("invalid grantee address %q: %w", args[len(args)-1], err)
+			}
+
+			// Not authz.NewMsgGrant, which encodes the addresses with the global bech32 prefix.
+			authorizationAny, err := codectypes.NewAnyWithValue(authorization)
+			if err != nil {
+				return err
+			}
+			msg := &authz.MsgGrant{
+				Granter: cl.MustEncodeAccAddr(granter),
+				Grantee: cl.MustEncodeAccAddr(grantee),
+				Grant:   authz.Grant{Authorization: authorizationAny, Expiration: expiration},
+			}
+			return sendOrWriteMsgs(cmd, a, cl, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().String(authzMsgTypeFlag, "", "type URL of the messages to authorize, such as /cosmos.gov.v1beta1.MsgVote")
+	cmd.Flags().String(authzSpendLimitFlag, "", "grant a send authorization for up to these coins, such as 100uatom")
+	cmd.Flags().String(expirationFlag, "", "time at which the authorization expires, "+expirationHelp)
+	addDryRunFlag(cmd)
+	AddTxFlagsToCmd(cmd)
+	memoFlag(a.Viper, cmd)
 	return cmd
 }
 
@@ -99,7 +298,10 @@ func authzRevokeAuthorizationCmd(a *appState) *cobra.Command {
 			// and the grantee client. This will allow for use of a
 			// ledger as the grantor (i.e. cosmoshub-ledger in the config)
 			// and test keyringbacked for the grantee (i.e. cosmoshub)
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			var key string
 			if len(args) == 3 {
 				key = args[2]
@@ -131,34 +333,90 @@ func authzRevokeAuthorizationCmd(a *appState) *cobra.Command {
 	return cmd
 }
 
-// authzExecAuthorizationCmd returns the authz exec authorization command for this module
 func authzExecAuthorizationCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "exec [msg_tx_json_file] [grantee]?",
-		Aliases: []string{"exec"},
-		Short:   "execute an authorization",
-		Args:    cobra.RangeArgs(1, 2),
+		Use:   "exec [chain-name] <msgs-json-file>",
+		Short: "execute messages on behalf of a granter",
+		Long: `Execute the messages of a JSON file with the authorizations granted to the --from key, or to the chain's key,
+on the given chain, or on the default chain.
+
+The file holds a list of messages, a single message, or a transaction such as one written by --generate-only,
+each message having its type URL in an @type field.
+With --dry-run, the message of the transaction is written without signing or broadcasting it.`,
+		Example: fmt.Sprintf(`$ %s tx authz exec cosmoshub msgs.json --from mykey
+$ %s tx authz exec tx.json --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: should we expose this to the user?
-			// is there a way to check in the other transaction commands
-			// if the sender is a grantee and then wrap their messages in
-			// execute messages?
-
-			// cl := config.GetDefaultClient()
-			// var key string
-			// if len(args) == 2 {
-			// 	key = args[1]
-			// }
-			// sender, err := cl.AccountFromKeyOrAddress(key)
-			// if err != nil {
-			// 	return err
-			// }
-			// msg := &authz.MsgExec{
-			// 	Grantee: cl.MustEncodeAccAddr(sender),
-			// 	Msgs:    []authz.Msg{},
-			// }
-			return nil
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			file := args[len(args)-1]
+			bz, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			from, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			cl, grantee, err := txChainClient(cmd, a, chainName, from)
+			if err != nil {
+				return err
+			}
+			msgs, err := readMsgsJSON(cl, bz)
+			if err != nil {
+				return fmt.Errorf("failed to read messages from %s: %w", file, err)
+			}
+
+			// Not authz.NewMsgExec, which encodes the grantee with the global bech32 prefix.
+			msg := &authz.MsgExec{Grantee: cl.MustEncodeAccAddr(grantee)}
+			for _, m := range msgs {
+				any, err := codectypes.NewAnyWithValue(m)
+				if err != nil {
+					return err
+				}
+				msg.Msgs = append(msg.Msgs, any)
+			}
+			return sendOrWriteMsgs(cmd, a, cl, []sdk.Msg{msg})
 		},
 	}
+	addDryRunFlag(cmd)
+	AddTxFlagsToCmd(cmd)
+	memoFlag(a.Viper, cmd)
 	return cmd
 }
+
+// readMsgsJSON decodes the messages of bz with the codec of cl.
+// bz is a JSON list of messages, a single message, or a transaction holding the messages in body.messages.
+func readMsgsJSON(cl *client.ChainClient, bz []byte) ([]sdk.Msg, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(bz, &raws); err != nil {
+		var tx struct {
+			Body *struct {
+				Messages []json.RawMessage `json:"messages"`
+			} `json:"body"`
+		}
+		if err := json.Unmarshal(bz, &tx); err != nil {
+			return nil, err
+		}
+		if tx.Body != nil {
+			raws = tx.Body.Messages
+		} else {
+			raws = []json.RawMessage{bz}
+		}
+	}
+	if len(raws) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+
+	msgs := make([]sdk.Msg, len(raws))
+	for i, raw := range raws {
+		if err := cl.Codec.Marshaler.UnmarshalInterfaceJSON(raw, &msgs[i]); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,152 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAuthzGrants(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	generic, err := codectypes.NewAnyWithValue(authz.NewGenericAuthorization("/cosmos.gov.v1beta1.MsgVote"))
+	require.NoError(t, err)
+	send, err := codectypes.NewAnyWithValue(banktypes.NewSendAuthorization(sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), nil))
+	require.NoError(t, err)
+	unknown := &codectypes.Any{TypeUrl: "/example.v1.CustomAuthorization", Value: []byte{1}}
+
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.authz.v1beta1.Query/GranterGrants", func(bytes.HexBytes) bool { return true },
+		&authz.QueryGranterGrantsResponse{
+			Grants: []*authz.GrantAuthorization{
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Authorization: generic, Expiration: &past},
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Authorization: send, Expiration: &future},
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Authorization: unknown},
+			},
+			Pagination: &query.PageResponse{},
+		})
+	mockABCIQuery(t, mc, "/cosmos.authz.v1beta1.Query/Grants", func(bytes.HexBytes) bool { return true },
+		&authz.QueryGrantsResponse{
+			Grants:     []*authz.Grant{{Authorization: send, Expiration: &future}},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "authz", "grants", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"GRANTEE", "AUTHORIZATION", "MSG", "TYPE", "SPEND", "LIMIT", "EXPIRATION"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{ZeroCosmosAddr, "/cosmos.authz.v1beta1.GenericAuthorization", "/cosmos.gov.v1beta1.MsgVote", "-", "2020-01-01T00:00:00Z", "(expired)"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{ZeroCosmosAddr, "/cosmos.bank.v1beta1.SendAuthorization", "/cosmos.bank.v1beta1.MsgSend", "100uatom", "2999-01-01T00:00:00Z"}, strings.Fields(lines[2]))
+	// The grant of an unknown authorization type does not fail the command.
+	require.Equal(t, []string{ZeroCosmosAddr, "/example.v1.CustomAuthorization", "-", "-", "never"}, strings.Fields(lines[3]))
+
+	res = sys.MustRun(t, "query", "authz", "grants", ZeroCosmosAddr, "--msg-type", "/cosmos.gov.v1beta1.MsgVote", "-o", "json")
+	var grants []struct {
+		MsgTypeURL string `json:"msg_type_url"`
+		Expired    bool
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &grants))
+	require.Len(t, grants, 1)
+	require.True(t, grants[0].Expired)
+
+	// With a grantee, the grants are queried for that pair.
+	res = sys.MustRun(t, "query", "authz", "grants", ZeroCosmosAddr, ZeroCosmosAddr, "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &grants))
+	require.Len(t, grants, 1)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", grants[0].MsgTypeURL)
+	require.False(t, grants[0].Expired)
+}
+
+func TestAuthzGrant(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	res := sys.MustRun(t, "tx", "authz", "grant", "cosmoshub", ZeroCosmosAddr, "--from", ZeroCosmosAddr, "--dry-run",
+		"--msg-type", "/cosmos.gov.v1beta1.MsgVote", "--expiration", "2999-01-01")
+	var msgs []struct {
+		Type    string `json:"@type"`
+		Granter string
+		Grant   struct {
+			Authorization map[string]interface{}
+			Expiration    time.Time
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.authz.v1beta1.MsgGrant", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Granter)
+	require.Equal(t, "/cosmos.authz.v1beta1.GenericAuthorization", msgs[0].Grant.Authorization["@type"])
+	require.Equal(t, "/cosmos.gov.v1beta1.MsgVote", msgs[0].Grant.Authorization["msg"])
+	require.Equal(t, time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC), msgs[0].Grant.Expiration)
+
+	res = sys.MustRun(t, "tx", "authz", "grant", ZeroCosmosAddr, "--from", ZeroCosmosAddr, "--dry-run", "--spend-limit", "100uatom")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.bank.v1beta1.SendAuthorization", msgs[0].Grant.Authorization["@type"])
+
+	for args, msg := range map[string]string{
+		"": "--msg-type or --spend-limit is required",
+		"--spend-limit 1uatom --msg-type /cosmos.gov.v1beta1.MsgVote": "--spend-limit is only valid for /cosmos.bank.v1beta1.MsgSend",
+		"--msg-type /x.MsgY --expiration 2020-01-01":                  `invalid expiration "2020-01-01": must be in the future`,
+		"--msg-type /x.MsgY --expiration tomorrow":                    `invalid expiration "tomorrow"`,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "authz", "grant", ZeroCosmosAddr, "--from", ZeroCosmosAddr, "--dry-run"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg)
+	}
+}
+
+func TestAuthzExec(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	send := `{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":"` + ZeroCosmosAddr + `","to_address":"` + ZeroCosmosAddr + `","amount":[{"denom":"uatom","amount":"5"}]}`
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"list.json": "[" + send + "," + send + "]",
+		"tx.json":   `{"body":{"messages":[` + send + `,` + send + `]}}`,
+	} {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		res := sys.MustRun(t, "tx", "authz", "exec", "cosmoshub", file, "--from", ZeroCosmosAddr, "--dry-run")
+		var msgs []struct {
+			Type    string `json:"@type"`
+			Grantee string
+			Msgs    []map[string]interface{}
+		}
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+		require.Len(t, msgs, 1)
+		require.Equal(t, "/cosmos.authz.v1beta1.MsgExec", msgs[0].Type)
+		require.Equal(t, ZeroCosmosAddr, msgs[0].Grantee)
+		require.Len(t, msgs[0].Msgs, 2)
+		require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", msgs[0].Msgs[1]["@type"])
+	}
+
+	file := filepath.Join(dir, "unknown.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"@type":"/example.v1.MsgCustom"}`), 0o600))
+	res := sys.Run(zaptest.NewLogger(t), "tx", "authz", "exec", file, "--from", ZeroCosmosAddr, "--dry-run")
+	require.ErrorContains(t, res.Err, "failed to read messages from "+file+": message 0:")
+}
@@ -2,29 +2,38 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmquery "github.com/cosmos/cosmos-sdk/types/query"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
 	query "github.com/strangelove-ventures/lens/client/query"
 )
 
 func bankSendCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "send [from] [to] [amount]",
-		Short: "send coins from one address to another",
-		Args:  cobra.ExactArgs(3),
+		Use:   "send [chain-name] <from-key> <to-address> <amount>",
+		Short: "send coins from a key to an address",
+		Long: `Send coins from a key in the keyring of the given chain, or of the default chain, to an address on that chain.
+
+` + txOptionsHelp,
+		Example: `$ lens tx bank send cosmoshub mykey cosmos1... 1000uatom
+$ lens tx bank send mykey cosmos1... 1000uatom --gas 200000 --fees 5000uatom
+$ lens tx bank send cosmoshub mykey cosmos1... 1000uatom --generate-only`,
+		Args: withUsage(cobra.RangeArgs(3, 4)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			fromAddr, err := cl.AccountFromKeyOrAddress(args[0])
+			chainName, args := txArgs(a, args, 3)
+			cl, fromAddr, err := txChainClient(cmd, a, chainName, args[0])
 			if err != nil {
 				return err
 			}
 
 			toAddr, err := cl.DecodeBech32AccAddr(args[1])
 			if err != nil {
-				return err
+				return fmt.Errorf("invalid destination address %q for account prefix %q: %w", args[1], cl.Config.AccountPrefix, err)
 			}
 
 			coins, err := sdk.ParseCoinsNormalized(args[2])
@@ -38,96 +47,179 @@ func bankSendCmd(a *appState) *cobra.Command {
 				Amount:      coins,
 			}
 
-			memo, err := cmd.Flags().GetString(flagMemo)
-			if err != nil {
-				return err
-			}
-
-			res, err := cl.SendMsg(cmd.Context(), req, memo)
-			if err != nil {
-				if res != nil {
-					return fmt.Errorf("failed to send coins: code(%d) msg(%s)", res.Code, res.Logs)
-				}
-				return fmt.Errorf("failed to send coins: err(%w)", err)
-			}
-			return cl.PrintTxResponse(res)
-
+			return sendTx(cmd, a, cl, req)
 		},
 	}
-	memoFlag(a.Viper, cmd)
+	addTxOptionsFlags(a, cmd)
 	return cmd
 }
 
 // ========== Querier Functions ==========
 
 func bankBalanceCmd(a *appState) *cobra.Command {
+	const denomFlag = "denom"
+
 	cmd := &cobra.Command{
-		Use:     "balances [key-or-address]",
+		Use:     "balances [chain-name] [key-or-address]",
 		Aliases: []string{"bal", "b"},
 		Short:   "query the account balance for a key or address (if none is specified, the balance of the default account is returned)",
-		Args:    cobra.RangeArgs(0, 1),
+		Long: `Query the balances of a key or address on the given chain, or on the default chain.
+
+` + chainAndAddressArgsHelp + `
+
+` + paginationHelp + `
+The balances are printed as a table, or as a list of coins with --output json or yaml.
+
+With text output, amounts are shown in the display units of their denoms' metadata, such as 1.5 atom,
+and IBC denoms as their paths, such as transfer/channel-0/uatom; --raw shows the base denoms instead.
+With other output, amounts stay in base denoms, and --human adds the display amount to each coin.
+The metadata of a chain's denoms is cached for a day under the lens home directory.`,
+		Args: cobra.RangeArgs(0, 2),
+		Example: fmt.Sprintf(`$ %s query bank balances
+$ %s q bank balances osmosis
+$ %s q bank balances cosmoshub cosmos1... --denom uatom --height 1000000 -o json
+$ %s q bank balances osmosis --raw
+$ %s q bank balances osmosis --limit 10`,
+			appName, appName, appName, appName, appName),
+		ValidArgsFunction: completeChainThenKey(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			pr, err := ReadPageRequest(cmd.Flags())
+			cl, encodedAddr, err := chainClientAndAddress(a, args)
 			if err != nil {
 				return err
 			}
-			height, err := ReadHeight(cmd.Flags())
+
+			pages, options, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			keyNameOrAddress := ""
-			if len(args) == 0 {
-				keyNameOrAddress = cl.Config.Key
-			} else {
-				keyNameOrAddress = args[0]
-			}
-			address, err := cl.AccountFromKeyOrAddress(keyNameOrAddress)
+			denom, err := cmd.Flags().GetString(denomFlag)
 			if err != nil {
 				return err
 			}
-			encodedAddr := cl.MustEncodeAccAddr(address)
-			options := query.QueryOptions{Pagination: pr, Height: height}
-			query := query.Query{Client: cl, Options: &options}
-			balance, err := query.Bank_Balances(encodedAddr)
+			human, err := humanAmounts(cmd, a)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(balance)
+
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: options}
+
+			balances := sdk.Coins{}
+			if denom != "" {
+				res, err := query.Bank_Balance(encodedAddr, denom)
+				if err != nil {
+					return err
+				}
+				if res.Balance != nil {
+					balances = sdk.Coins{*res.Balance}
+				}
+			} else {
+				all, err := query.Bank_AllBalances(encodedAddr)
+				if err != nil {
+					return err
+				}
+				if all != nil {
+					balances = all
+				}
+			}
+
+			if !human || len(balances) == 0 {
+				return pages.writeOutput(cmd, a, coinBalances(balances), query.Pages)
+			}
+			return pages.writeOutput(cmd, a, newDisplayBalances(resolveDenoms(cmd.Context(), a, cl, balances), balances), query.Pages)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "balance")
+	addQueryHeightFlag(cmd)
+	cmd.Flags().String(denomFlag, "", "only return the balance of this denom")
+	addDenomFlags(cmd)
+	addPaginationFlags(cmd, "balances")
 	return cmd
 }
 
+// coinBalances is the result of query bank balances.
+type coinBalances sdk.Coins
+
+var _ fmt.Stringer = coinBalances(nil)
+
+// String returns the balances as a table with aligned columns.
+func (cs coinBalances) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DENOM\tAMOUNT")
+	for _, c := range cs {
+		fmt.Fprintf(w, "%s\t%s\n", c.Denom, c.Amount)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// displayCoin is a coin along with its amount in the display unit of its denom.
+type displayCoin struct {
+	Denom   string  `json:"denom"`
+	Amount  sdk.Int `json:"amount"`
+	Display string  `json:"display"`
+
+	displayAmount, displayDenom string
+}
+
+// displayBalances is the result of query bank balances with amounts in display units.
+type displayBalances []displayCoin
+
+// newDisplayBalances returns the balances in the display units of denoms.
+func newDisplayBalances(denoms client.Denoms, balances sdk.Coins) displayBalances {
+	ds := make(displayBalances, len(balances))
+	for i, c := range balances {
+		ds[i] = displayCoin{Denom: c.Denom, Amount: c.Amount, Display: denoms.FormatCoin(c)}
+		ds[i].displayAmount, ds[i].displayDenom = denoms.Display(c)
+	}
+	return ds
+}
+
+var _ fmt.Stringer = displayBalances(nil)
+
+// String returns the balances in display units as a table with aligned columns.
+func (ds displayBalances) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DENOM\tAMOUNT")
+	for _, c := range ds {
+		fmt.Fprintf(w, "%s\t%s\n", c.displayDenom, c.displayAmount)
+	}
+	w.Flush()
+	return b.String()
+}
+
 func bankTotalSupplyCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "total-supply",
 		Aliases: []string{"totalsupply", "tot", "ts", "totsupplys"},
 		Short:   "query the total supply of coins in the chain",
-		Args:    cobra.NoArgs,
+		Long: `Query the total supply of every coin of the default chain.
+
+` + paginationHelp,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			pr, err := ReadPageRequest(cmd.Flags())
+			cl, err := defaultChainClient(a)
 			if err != nil {
 				return err
 			}
-			height, err := ReadHeight(cmd.Flags())
+			pages, options, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			options := query.QueryOptions{Pagination: pr, Height: height}
-			query := query.Query{Client: cl, Options: &options}
-			totalSupply, err := query.Bank_TotalSupply()
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: options}
+			supply, err := query.Bank_AllTotalSupply()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(totalSupply)
+			pages.logNextPage(a, query.Pages)
+			return writeOutput(cmd, a, codecJSON(cl, &banktypes.QueryTotalSupplyResponse{
+				Supply:     supply,
+				Pagination: &tmquery.PageResponse{NextKey: query.Pages.NextKey, Total: query.Pages.Total},
+			}))
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "total-supply")
+	addQueryHeightFlag(cmd)
+	addPaginationFlags(cmd, "coins")
 	return cmd
 }
 
@@ -136,27 +228,32 @@ func bankDenomsMetadataCmd(a *appState) *cobra.Command {
 		Use:     "denoms-metadata",
 		Aliases: []string{"denoms", "d"},
 		Short:   "query the denoms metadata",
-		Args:    cobra.NoArgs,
+		Long: `Query the metadata of every denom of the default chain.
+
+` + paginationHelp,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			pr, err := ReadPageRequest(cmd.Flags())
+			cl, err := defaultChainClient(a)
 			if err != nil {
 				return err
 			}
-			height, err := ReadHeight(cmd.Flags())
+			pages, options, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			options := query.QueryOptions{Pagination: pr, Height: height}
-			query := query.Query{Client: cl, Options: &options}
-			denoms, err := query.Bank_DenomsMetadata()
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: options}
+			metadatas, err := query.Bank_AllDenomsMetadata()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(denoms)
+			pages.logNextPage(a, query.Pages)
+			return writeOutput(cmd, a, codecJSON(cl, &banktypes.QueryDenomsMetadataResponse{
+				Metadatas:  metadatas,
+				Pagination: &tmquery.PageResponse{NextKey: query.Pages.NextKey, Total: query.Pages.Total},
+			}))
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "denoms-metadata")
+	addQueryHeightFlag(cmd)
+	addPaginationFlags(cmd, "denoms")
 	return cmd
 }
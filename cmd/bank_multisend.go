@@ -0,0 +1,515 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const (
+	multisendMaxRecipientsFlag = "max-recipients-per-tx"
+	multisendMaxGasFlag        = "max-gas-per-tx"
+	multisendResultsFlag       = "results"
+	multisendResumeFlag        = "resume"
+	multisendYesFlag           = "yes"
+)
+
+// The statuses of the recipients in the results file of tx bank multisend.
+const (
+	multisendSent    = "sent"
+	multisendFailed  = "failed"
+	multisendPending = "pending"
+	// multisendUnconfirmed is the status of the recipients of a transaction that passed CheckTx,
+	// but whose inclusion was not confirmed, so that it may still be included.
+	multisendUnconfirmed = "unconfirmed"
+)
+
+func bankMultisendCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multisend [chain-name] <from-key> <recipients.csv>",
+		Short: "send coins from a key to the recipients of a CSV file",
+		Long: `Send coins from a key in the keyring of the given chain, or of the default chain, to the recipients of a CSV file,
+each row of which is an address and the coins to send to it:
+
+  address,amount
+  cosmos1...,1000uatom
+  cosmos1...,"2500uatom,10uosmo"
+
+The header row is optional, and lines starting with # are ignored. Every row is checked, and every address
+must have the chain's account prefix, before anything is sent. The total is then shown for confirmation,
+unless --yes is given.
+
+The recipients are sent to in multi-send transactions of at most --max-recipients-per-tx recipients,
+and with --max-gas-per-tx, of recipients whose estimated gas is at most that much, broadcast one after another
+with consecutive sequences. A failed transaction does not stop the others.
+
+The status and transaction hash of every recipient are written to the --results CSV file after each transaction
+(default: <recipients>.results.csv). Given to --resume, a results file skips the recipients it records as sent,
+so that the recipients of failed transactions are sent to again, and is updated in place.
+
+A transaction whose inclusion was not confirmed, because waiting for it timed out or was interrupted,
+may still be included: its recipients are recorded as unconfirmed, with its hash. On --resume, the transaction
+is looked up first, and its recipients are sent to again only if it was included and failed. Those of a transaction
+not found are not sent to again; set their status to failed in the results file to send to them anyway.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx bank multisend cosmoshub mykey airdrop.csv
+$ %s tx bank multisend mykey airdrop.csv --max-recipients-per-tx 50 --yes
+$ %s tx bank multisend cosmoshub mykey airdrop.csv --resume airdrop.results.csv`,
+			appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			maxRecipients, err := f.GetInt(multisendMaxRecipientsFlag)
+			if err != nil {
+				return err
+			}
+			if maxRecipients < 1 {
+				return fmt.Errorf("invalid --%s %d: must be positive", multisendMaxRecipientsFlag, maxRecipients)
+			}
+			maxGas, err := f.GetUint64(multisendMaxGasFlag)
+			if err != nil {
+				return err
+			}
+			resultsPath, err := f.GetString(multisendResultsFlag)
+			if err != nil {
+				return err
+			}
+			resumePath, err := f.GetString(multisendResumeFlag)
+			if err != nil {
+				return err
+			}
+			yes, err := f.GetBool(multisendYesFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 2)
+			cl, fromAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			bz, err := os.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			recipients, err := parseMultisendCSV(cl, bz)
+			if err != nil {
+				return fmt.Errorf("failed to read recipients from %s: %w", args[1], err)
+			}
+
+			if resumePath != "" {
+				if err := resumeMultisend(resumePath, recipients); err != nil {
+					return err
+				}
+				if err := confirmMultisend(cmd, cl, recipients); err != nil {
+					return err
+				}
+				if resultsPath == "" {
+					resultsPath = resumePath
+				}
+			}
+			if resultsPath == "" {
+				resultsPath = strings.TrimSuffix(args[1], filepath.Ext(args[1])) + ".results.csv"
+			}
+
+			var pending []*multisendRecipient
+			unconfirmed := 0
+			for _, r := range recipients {
+				switch r.Status {
+				case multisendSent:
+				case multisendUnconfirmed:
+					unconfirmed++
+				default:
+					pending = append(pending, r)
+				}
+			}
+			if len(pending) == 0 {
+				// Record the transactions confirmed since.
+				if err := writeMultisendResults(resultsPath, recipients); err != nil {
+					return err
+				}
+				if unconfirmed > 0 {
+					return errMultisendUnconfirmed(unconfirmed, resultsPath)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "All %d recipients were already sent to, according to %s\n", len(recipients), resumePath)
+				return nil
+			}
+
+			from := cl.MustEncodeAccAddr(fromAddr)
+			chunks := chunkMultisend(pending, maxRecipients)
+
+			dryRun, err := f.GetBool(dryRunFlag)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				msgs := make([]sdk.Msg, len(chunks))
+				for i, chunk := range chunks {
+					msgs[i] = multisendMsg(from, chunk)
+				}
+				return writeMsgs(cmd, a, cl, msgs)
+			}
+			generateOnly, err := f.GetBool(txGenerateOnlyFlag)
+			if err != nil {
+				return err
+			}
+			if generateOnly {
+				if len(chunks) > 1 {
+					return fmt.Errorf("--%s cannot split the recipients into several transactions: raise --%s to %d", txGenerateOnlyFlag, multisendMaxRecipientsFlag, len(pending))
+				}
+				return sendTx(cmd, a, cl, multisendMsg(from, chunks[0]))
+			}
+
+			opts, err := txOptionsFromFlags(cmd, cl)
+			if err != nil {
+				return err
+			}
+			if err := checkFeeOptions(cmd, a, cl, opts, false); err != nil {
+				return err
+			}
+			if maxGas > 0 {
+				if chunks, err = splitMultisendByGas(cmd, cl, from, chunks, opts, maxGas); err != nil {
+					return err
+				}
+			}
+
+			total := sdk.NewCoins()
+			for _, r := range pending {
+				total = total.Add(r.Amount...)
+			}
+			if !yes {
+				ok, err := confirm(cmd, fmt.Sprintf("Send %s from %s to %d recipients on %s, in %d transactions? (y/N)",
+					total, from, len(pending), cl.Config.ChainID, len(chunks)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errors.New("multisend was not confirmed")
+				}
+			}
+
+			if err := writeMultisendResults(resultsPath, recipients); err != nil {
+				return err
+			}
+			res := multisendResult{Recipients: len(pending), Total: total, ResultsFile: resultsPath}
+			failed, unconfirmedTxs := 0, 0
+			for i, chunk := range chunks {
+				txRes, err := cl.SendMsgsWithOptions(cmd.Context(), []sdk.Msg{multisendMsg(from, chunk)}, opts)
+				r := multisendTxResult{Recipients: len(chunk)}
+				status, txHash, errMsg := multisendSent, "", ""
+				if txRes != nil {
+					r.txResult = txResultWithEvents(cmd, txRes)
+					txHash = txRes.TxHash
+				}
+				var unconfirmedErr client.UnconfirmedTxError
+				switch {
+				case errors.As(err, &unconfirmedErr):
+					unconfirmedTxs++
+					r.TxHash = unconfirmedErr.TxHash
+					status, txHash, errMsg, r.Error = multisendUnconfirmed, unconfirmedErr.TxHash, err.Error(), err.Error()
+				case err != nil:
+					failed++
+					status, errMsg, r.Error = multisendFailed, err.Error(), err.Error()
+				}
+				for _, rcpt := range chunk {
+					rcpt.Status, rcpt.TxHash, rcpt.Error = status, txHash, errMsg
+				}
+				res.Txs = append(res.Txs, r)
+				if werr := writeMultisendResults(resultsPath, recipients); werr != nil {
+					return werr
+				}
+				// The remaining transactions cannot be sent once the command is interrupted or timed out.
+				if cmd.Context().Err() != nil {
+					res.Unsent = len(chunks) - i - 1
+					break
+				}
+			}
+
+			if err := writeOutput(cmd, a, res); err != nil {
+				return err
+			}
+			if unconfirmedTxs > 0 {
+				var notSent string
+				if n := failed + res.Unsent; n > 0 {
+					notSent = fmt.Sprintf(", and %d were not sent", n)
+				}
+				return fmt.Errorf("%d of %d transactions were not confirmed to be included%s: "+
+					"resume with --%s %s, which looks up the unconfirmed transactions before sending to their recipients again",
+					unconfirmedTxs, len(chunks), notSent, multisendResumeFlag, resultsPath)
+			}
+			if failed > 0 || res.Unsent > 0 {
+				return fmt.Errorf("%d of %d transactions were not sent: send to their recipients again with --%s %s",
+					failed+res.Unsent, len(chunks), multisendResumeFlag, resultsPath)
+			}
+			if unconfirmed > 0 {
+				return errMultisendUnconfirmed(unconfirmed, resultsPath)
+			}
+			return nil
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().Int(multisendMaxRecipientsFlag, 100, "the most recipients sent to in one transaction")
+	cmd.Flags().Uint64(multisendMaxGasFlag, 0, "split transactions whose estimated gas exceeds this much (default: no bound)")
+	cmd.Flags().String(multisendResultsFlag, "", "the CSV file to write the status of every recipient to (default: <recipients>.results.csv, or the --resume file)")
+	cmd.Flags().String(multisendResumeFlag, "", "a results file of an earlier multisend, whose recipients recorded as sent are skipped")
+	cmd.Flags().BoolP(multisendYesFlag, "y", false, "send without asking for confirmation")
+	return cmd
+}
+
+// multisendRecipient is a row of the recipients file of tx bank multisend, with its status in the results file.
+type multisendRecipient struct {
+	// Line is the line of the row in the recipients file.
+	Line    int
+	Address string
+	Amount  sdk.Coins
+
+	Status string
+	TxHash string
+	Error  string
+}
+
+// parseMultisendCSV parses the rows of the recipients file of tx bank multisend,
+// reporting every invalid row.
+func parseMultisendCSV(cl *client.ChainClient, bz []byte) ([]*multisendRecipient, error) {
+	r := csv.NewReader(bytes.NewReader(bz))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var recipients []*multisendRecipient
+	var invalid []string
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(recipients) == 0 && len(invalid) == 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "address") {
+			continue
+		}
+		if len(rec) != 2 {
+			invalid = append(invalid, fmt.Sprintf("line %d: expected address,amount, got %d fields", line, len(rec)))
+			continue
+		}
+
+		addr, err := cl.DecodeBech32AccAddr(strings.TrimSpace(rec[0]))
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("line %d: invalid address %q for account prefix %q: %v", line, rec[0], cl.Config.AccountPrefix, err))
+			continue
+		}
+		amount, err := sdk.ParseCoinsNormalized(strings.TrimSpace(rec[1]))
+		if err != nil || amount.Empty() {
+			invalid = append(invalid, fmt.Sprintf("line %d: invalid amount %q: must be a positive amount of coins (e.g. 1000uatom)", line, rec[1]))
+			continue
+		}
+		recipients = append(recipients, &multisendRecipient{
+			Line:    line,
+			Address: cl.MustEncodeAccAddr(addr),
+			Amount:  amount,
+			Status:  multisendPending,
+		})
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%d invalid rows:\n  %s", len(invalid), strings.Join(invalid, "\n  "))
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	return recipients, nil
+}
+
+// multisendResultsHeader is the header row of the results file of tx bank multisend.
+var multisendResultsHeader = []string{"address", "amount", "status", "tx_hash", "error"}
+
+// writeMultisendResults writes the status of recipients to the results file at path.
+func writeMultisendResults(path string, recipients []*multisendRecipient) error {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(multisendResultsHeader); err != nil {
+		return err
+	}
+	for _, r := range recipients {
+		if err := w.Write([]string{r.Address, r.Amount.String(), r.Status, r.TxHash, r.Error}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b.Bytes())
+}
+
+// resumeMultisend marks the recipients that the results file at path records as sent or unconfirmed,
+// matching each row of the file to a recipient with the same address and amount.
+func resumeMultisend(path string, recipients []*multisendRecipient) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid results file %s: %w", path, err)
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(multisendResultsHeader, ",") {
+		return fmt.Errorf("invalid results file %s: expected the header %s", path, strings.Join(multisendResultsHeader, ","))
+	}
+
+	// The rows of each address and amount, sent or unconfirmed.
+	done := make(map[string][][]string)
+	for _, row := range rows[1:] {
+		if len(row) == len(multisendResultsHeader) && (row[2] == multisendSent || row[2] == multisendUnconfirmed) {
+			key := row[0] + "," + row[1]
+			done[key] = append(done[key], row)
+		}
+	}
+	for _, r := range recipients {
+		key := r.Address + "," + r.Amount.String()
+		if rows := done[key]; len(rows) > 0 {
+			r.Status, r.TxHash, r.Error = rows[0][2], rows[0][3], rows[0][4]
+			done[key] = rows[1:]
+		}
+	}
+	return nil
+}
+
+// confirmMultisend looks up the transactions of the unconfirmed recipients,
+// marking them sent if their transaction was included and succeeded, or failed if it was included and failed.
+// The recipients of a transaction that is not found are left unconfirmed.
+func confirmMultisend(cmd *cobra.Command, cl *client.ChainClient, recipients []*multisendRecipient) error {
+	byHash := make(map[string][]*multisendRecipient)
+	var hashes []string
+	for _, r := range recipients {
+		if r.Status == multisendUnconfirmed {
+			if _, ok := byHash[r.TxHash]; !ok {
+				hashes = append(hashes, r.TxHash)
+			}
+			byHash[r.TxHash] = append(byHash[r.TxHash], r)
+		}
+	}
+
+	for _, hash := range hashes {
+		res, err := cl.QueryTx(cmd.Context(), hash, false)
+		if err != nil {
+			// The RPC endpoint reports a transaction it has not indexed as not found.
+			if strings.Contains(err.Error(), "not found") {
+				continue
+			}
+			return fmt.Errorf("failed to look up the unconfirmed transaction %s: %w", hash, err)
+		}
+		status, errMsg := multisendSent, ""
+		if res.TxResult.Code != 0 {
+			failed := client.TxFailedError{Code: res.TxResult.Code, Codespace: res.TxResult.Codespace, TxHash: hash}
+			status, errMsg = multisendFailed, failed.Error()
+		}
+		for _, r := range byHash[hash] {
+			r.Status, r.Error = status, errMsg
+		}
+	}
+	return nil
+}
+
+// errMultisendUnconfirmed returns the error for n recipients left unconfirmed, whose status is recorded in the results file at path.
+func errMultisendUnconfirmed(n int, path string) error {
+	return fmt.Errorf("%d recipients were not sent to again, since their transactions were not found, but may still be included: "+
+		"resume with --%s %s once they are, or set their status to failed to send to them anyway", n, multisendResumeFlag, path)
+}
+
+// chunkMultisend splits recipients into chunks of at most n recipients.
+func chunkMultisend(recipients []*multisendRecipient, n int) [][]*multisendRecipient {
+	var chunks [][]*multisendRecipient
+	for len(recipients) > n {
+		chunks = append(chunks, recipients[:n])
+		recipients = recipients[n:]
+	}
+	return append(chunks, recipients)
+}
+
+// splitMultisendByGas splits the chunks whose transaction is estimated to need more than maxGas in halves,
+// until every chunk fits.
+func splitMultisendByGas(cmd *cobra.Command, cl *client.ChainClient, from string, chunks [][]*multisendRecipient, opts client.TxOptions, maxGas uint64) ([][]*multisendRecipient, error) {
+	var fit [][]*multisendRecipient
+	for len(chunks) > 0 {
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		_, txb, err := cl.BuildUnsignedTx(cmd.Context(), []sdk.Msg{multisendMsg(from, chunk)}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate the gas of a transaction to %d recipients: %w", len(chunk), err)
+		}
+		gas := txb.GetTx().GetGas()
+		if gas <= maxGas {
+			fit = append(fit, chunk)
+			continue
+		}
+		if len(chunk) == 1 {
+			return nil, fmt.Errorf("sending to %s (line %d) alone needs %d gas, more than --%s %d", chunk[0].Address, chunk[0].Line, gas, multisendMaxGasFlag, maxGas)
+		}
+		half := len(chunk) / 2
+		chunks = append([][]*multisendRecipient{chunk[:half], chunk[half:]}, chunks...)
+	}
+	return fit, nil
+}
+
+// multisendMsg returns the message sending to recipients from the address from.
+func multisendMsg(from string, recipients []*multisendRecipient) *banktypes.MsgMultiSend {
+	total := sdk.NewCoins()
+	outputs := make([]banktypes.Output, len(recipients))
+	for i, r := range recipients {
+		total = total.Add(r.Amount...)
+		outputs[i] = banktypes.Output{Address: r.Address, Coins: r.Amount}
+	}
+	return &banktypes.MsgMultiSend{
+		Inputs:  []banktypes.Input{{Address: from, Coins: total}},
+		Outputs: outputs,
+	}
+}
+
+// multisendResult is the result of the transactions of tx bank multisend.
+type multisendResult struct {
+	Recipients  int                 `json:"recipients"`
+	Total       sdk.Coins           `json:"total"`
+	Txs         []multisendTxResult `json:"txs"`
+	Unsent      int                 `json:"unsent,omitempty"`
+	ResultsFile string              `json:"results_file"`
+}
+
+// multisendTxResult is the result of one transaction of tx bank multisend.
+type multisendTxResult struct {
+	Recipients int    `json:"recipients"`
+	Error      string `json:"error,omitempty"`
+	txResult
+}
+
+var _ fmt.Stringer = multisendResult{}
+
+func (r multisendResult) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	n := len(r.Txs) + r.Unsent
+	for i, tx := range r.Txs {
+		detail := tx.RawLog
+		if tx.Error != "" {
+			detail = tx.Error
+		}
+		fmt.Fprintf(w, "%d/%d\t%s\t%d recipients\tcode %d\t%s\n", i+1, n, orDash(tx.TxHash), tx.Recipients, tx.Code, orDash(detail))
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "%d recipients, %s in total; results written to %s\n", r.Recipients, r.Total, r.ResultsFile)
+	return b.String()
+}
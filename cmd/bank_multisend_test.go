@@ -0,0 +1,213 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBankMultisend(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "airdrop.csv")
+	require.NoError(t, os.WriteFile(file, []byte(`address,amount
+# The team.
+`+ZeroCosmosAddr+`,10uatom
+`+testGroupMemberAddr+`,"5uatom,1uosmo"
+`+testContractAddr+`,7uatom
+`), 0o600))
+	resultsFile := filepath.Join(dir, "airdrop.results.csv")
+
+	var dryRun []struct {
+		Type    string `json:"@type"`
+		Inputs  []banktypes.Input
+		Outputs []banktypes.Output
+	}
+	res := sys.MustRun(t, "tx", "bank", "multisend", "mykey", file, "--max-recipients-per-tx", "2", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &dryRun))
+	require.Len(t, dryRun, 2)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgMultiSend", dryRun[0].Type)
+	require.Equal(t, ZeroCosmosAddr, dryRun[0].Inputs[0].Address)
+	require.Equal(t, "15uatom,1uosmo", dryRun[0].Inputs[0].Coins.String())
+	require.Len(t, dryRun[0].Outputs, 2)
+	require.Equal(t, testContractAddr, dryRun[1].Outputs[0].Address)
+
+	txConfig := client.MakeCodec(client.ModuleBasics, nil).TxConfig
+	var sent []*banktypes.MsgMultiSend
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	record := func(args mock.Arguments) {
+		tx, err := txConfig.TxDecoder()(args.Get(1).(tmtypes.Tx))
+		require.NoError(t, err)
+		sent = append(sent, tx.GetMsgs()[0].(*banktypes.MsgMultiSend))
+	}
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(record).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(record).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xbc}, Code: 5, Codespace: "sdk", Log: "insufficient funds"}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(record).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xcd}}, nil).Once()
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// Nothing is sent unless the total is confirmed.
+	res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader("n\n"), "tx", "bank", "multisend", "cosmoshub", "mykey", file, "--broadcast-mode", "sync")
+	require.EqualError(t, res.Err, "multisend was not confirmed")
+	require.Contains(t, res.Stderr.String(), "Send 22uatom,1uosmo from "+ZeroCosmosAddr+" to 3 recipients on cosmoshub-4, in 1 transactions? (y/N)")
+	mc.AssertNotCalled(t, "BroadcastTxSync", mock.Anything, mock.Anything)
+
+	// A failed transaction is recorded in the results file, and does not stop the others.
+	res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader("y\n"), "tx", "bank", "multisend", "cosmoshub", "mykey", file,
+		"--broadcast-mode", "sync", "--max-recipients-per-tx", "2", "-o", "text")
+	require.ErrorContains(t, res.Err, "1 of 2 transactions were not sent: send to their recipients again with --resume "+resultsFile)
+	require.Len(t, sent, 2)
+	require.Len(t, sent[0].Outputs, 2)
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"1/2", "AB", "2", "recipients", "code", "0", "-"}, strings.Fields(lines[0]))
+	require.True(t, strings.HasPrefix(lines[1], "2/2  BC"), lines[1])
+	require.Equal(t, "3 recipients, 22uatom,1uosmo in total; results written to "+resultsFile, lines[2])
+
+	readResults := func() [][]string {
+		f, err := os.Open(resultsFile)
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		return rows
+	}
+	rows := readResults()
+	require.Len(t, rows, 4)
+	require.Equal(t, []string{"address", "amount", "status", "tx_hash", "error"}, rows[0])
+	require.Equal(t, []string{testGroupMemberAddr, "5uatom,1uosmo", "sent", "AB", ""}, rows[2])
+	require.Equal(t, testContractAddr, rows[3][0])
+	require.Equal(t, "failed", rows[3][2])
+	require.Equal(t, "BC", rows[3][3])
+
+	// Resumed, only the recipients of the failed transaction are sent to again.
+	res = sys.MustRun(t, "tx", "bank", "multisend", "mykey", file, "--broadcast-mode", "sync", "--resume", resultsFile, "--yes")
+	require.Len(t, sent, 3)
+	require.Equal(t, []banktypes.Output{{Address: testContractAddr, Coins: sdk.NewCoins(sdk.NewInt64Coin("uatom", 7))}}, sent[2].Outputs)
+	rows = readResults()
+	require.Equal(t, []string{testContractAddr, "7uatom", "sent", "CD", ""}, rows[3])
+	require.Equal(t, "AB", rows[1][3])
+
+	res = sys.MustRun(t, "tx", "bank", "multisend", "mykey", file, "--resume", resultsFile, "--yes")
+	require.Contains(t, res.Stderr.String(), "All 3 recipients were already sent to")
+	mc.AssertNumberOfCalls(t, "BroadcastTxSync", 3)
+
+	// The simulated gas of every transaction is 120000.
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "multisend", "mykey", file, "--max-gas-per-tx", "100000", "--yes")
+	require.ErrorContains(t, res.Err, "sending to "+ZeroCosmosAddr+" (line 3) alone needs 120000 gas, more than --max-gas-per-tx 100000")
+
+	invalid := filepath.Join(dir, "invalid.csv")
+	require.NoError(t, os.WriteFile(invalid, []byte(`osmo1qyqszqgpqyqszqgpqyqszqgpqyqszqgpjnp7du,1uatom
+`+ZeroCosmosAddr+`,lots
+`+ZeroCosmosAddr+`
+`), 0o600))
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "multisend", "mykey", invalid, "--dry-run")
+	require.ErrorContains(t, res.Err, "failed to read recipients from "+invalid+": 3 invalid rows:\n"+
+		`  line 1: invalid address "osmo1qyqszqgpqyqszqgpqyqszqgpqyqszqgpjnp7du" for account prefix "cosmos"`)
+	require.ErrorContains(t, res.Err, `  line 2: invalid amount "lots"`)
+	require.ErrorContains(t, res.Err, "  line 3: expected address,amount, got 1 fields")
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "multisend", "mykey", file, "--max-recipients-per-tx", "2", "--generate-only")
+	require.ErrorContains(t, res.Err, "--generate-only cannot split the recipients into several transactions: raise --max-recipients-per-tx to 3")
+}
+
+func TestBankMultisend_Unconfirmed(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "airdrop.csv")
+	require.NoError(t, os.WriteFile(file, []byte(ZeroCosmosAddr+`,10uatom
+`+testGroupMemberAddr+`,5uatom
+`+testContractAddr+`,7uatom
+`), 0o600))
+	resultsFile := filepath.Join(dir, "airdrop.results.csv")
+
+	// The transactions are accepted, but not included until they are marked so.
+	included := make(map[byte]uint32)
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xbc}}, nil).Once()
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xcd}}, nil).Once()
+	mc.On("Tx", mock.Anything, mock.Anything, false).Return(
+		func(_ context.Context, hash []byte, _ bool) *coretypes.ResultTx {
+			if code, ok := included[hash[0]]; ok {
+				return &coretypes.ResultTx{Hash: hash, Height: 100, TxResult: abci.ResponseDeliverTx{Code: code}}
+			}
+			return nil
+		},
+		func(_ context.Context, hash []byte, _ bool) error {
+			if _, ok := included[hash[0]]; ok {
+				return nil
+			}
+			return fmt.Errorf("tx (%X) not found", hash)
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	readResults := func() [][]string {
+		f, err := os.Open(resultsFile)
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		return rows
+	}
+
+	// Waiting for the inclusion of the transactions times out, so their recipients are recorded as unconfirmed.
+	res := sys.Run(zaptest.NewLogger(t), "tx", "bank", "multisend", "mykey", file, "--max-recipients-per-tx", "2",
+		"--broadcast-mode", "block", "--block-timeout", "100ms", "--yes")
+	require.EqualError(t, res.Err, "2 of 2 transactions were not confirmed to be included: "+
+		"resume with --resume "+resultsFile+", which looks up the unconfirmed transactions before sending to their recipients again")
+	rows := readResults()
+	require.Len(t, rows, 4)
+	require.Equal(t, []string{ZeroCosmosAddr, "10uatom", "unconfirmed", "AB"}, rows[1][:4])
+	require.Contains(t, rows[1][4], "timed out after 100ms waiting for tx inclusion")
+	require.Equal(t, []string{testContractAddr, "7uatom", "unconfirmed", "BC"}, rows[3][:4])
+
+	// Resumed, the recipients of an unconfirmed transaction that is still not found are not sent to again.
+	included[0xbc] = 0
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "multisend", "mykey", file, "--resume", resultsFile, "--yes")
+	require.EqualError(t, res.Err, "2 recipients were not sent to again, since their transactions were not found, but may still be included: "+
+		"resume with --resume "+resultsFile+" once they are, or set their status to failed to send to them anyway")
+	mc.AssertNumberOfCalls(t, "BroadcastTxSync", 2)
+	rows = readResults()
+	require.Equal(t, []string{testContractAddr, "7uatom", "sent", "BC", ""}, rows[3])
+	require.Equal(t, "unconfirmed", rows[1][2])
+
+	// Those of a transaction that was included but failed are.
+	included[0xab] = 5
+	sys.MustRun(t, "tx", "bank", "multisend", "mykey", file, "--broadcast-mode", "sync", "--resume", resultsFile, "--yes")
+	mc.AssertNumberOfCalls(t, "BroadcastTxSync", 3)
+	rows = readResults()
+	require.Equal(t, []string{ZeroCosmosAddr, "10uatom", "sent", "CD", ""}, rows[1])
+	require.Equal(t, []string{testGroupMemberAddr, "5uatom", "sent", "CD", ""}, rows[2])
+	require.Equal(t, []string{testContractAddr, "7uatom", "sent", "BC", ""}, rows[3])
+}
@@ -0,0 +1,264 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// mockSendLookups makes mc answer the queries needed to build a transaction signed by ZeroCosmosAddr,
+// simulating it to use 100000 gas, on a chain allowing memos of up to 256 characters.
+func mockSendLookups(t *testing.T, mc *mocks.Client) {
+	t.Helper()
+
+	addr, err := sdk.GetFromBech32(ZeroCosmosAddr, "cosmos")
+	require.NoError(t, err)
+	account, err := codectypes.NewAnyWithValue(authtypes.NewBaseAccount(addr, nil, 7, 3))
+	require.NoError(t, err)
+	mockABCIQuery(t, mc, "/cosmos.auth.v1beta1.Query/Account", func(bytes.HexBytes) bool { return true },
+		&authtypes.QueryAccountResponse{Account: account})
+	mockABCIQuery(t, mc, "/cosmos.tx.v1beta1.Service/Simulate", func(bytes.HexBytes) bool { return true },
+		&txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100000}})
+	mockABCIQuery(t, mc, "/cosmos.auth.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&authtypes.QueryParamsResponse{Params: authtypes.Params{MaxMemoCharacters: 256}})
+}
+
+func TestBankSend_GenerateOnly(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	var tx struct {
+		Body struct {
+			Messages []map[string]interface{}
+			Memo     string
+		}
+		AuthInfo struct {
+			Fee struct {
+				Amount   sdk.Coins
+				GasLimit string `json:"gas_limit"`
+			}
+		} `json:"auth_info"`
+		Signatures []string
+	}
+
+	// The simulated gas is multiplied by the gas adjustment of the chain.
+	res := sys.MustRun(t, "tx", "bank", "send", "cosmoshub", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only", "--note", "hello")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Len(t, tx.Body.Messages, 1)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", tx.Body.Messages[0]["@type"])
+	require.Equal(t, ZeroCosmosAddr, tx.Body.Messages[0]["from_address"])
+	require.Equal(t, "hello", tx.Body.Memo)
+	require.Equal(t, "120000", tx.AuthInfo.Fee.GasLimit)
+	require.Empty(t, tx.Signatures)
+
+	// The memo is expanded as a template.
+	res = sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only", "--memo", "from {{.ChainID}} at {{.Timestamp.Year}}")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, fmt.Sprintf("from cosmoshub-4 at %d", time.Now().Year()), tx.Body.Memo)
+
+	res = sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only", "--gas", "50000", "--fees", "500uatom")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, "50000", tx.AuthInfo.Fee.GasLimit)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)), tx.AuthInfo.Fee.Amount)
+
+	for args, msg := range map[string]string{
+		"mykey cosmos1xyz 10uatom":                                                `invalid destination address "cosmos1xyz" for account prefix "cosmos"`,
+		"mykey " + ZeroCosmosAddr + " 10uatom --gas lots":                         `invalid gas "lots": must be "auto" or a positive integer`,
+		"mykey " + ZeroCosmosAddr + " 10uatom --fees 1uatom --gas-prices 1uatom":  "--fees and --gas-prices cannot both be given",
+		"mykey " + ZeroCosmosAddr + " 10uatom --memo a --note b":                  "--memo and --note are aliases",
+		"mykey " + ZeroCosmosAddr + " 10uatom --memo " + strings.Repeat("a", 257): "memo is 257 characters long, more than the 256 allowed by the chain's max_memo_characters auth param",
+		"mykey " + ZeroCosmosAddr + " 10uatom --memo {{.Chain}}":                  `invalid memo template "{{.Chain}}"`,
+		"mykey " + ZeroCosmosAddr + " 10uatom --broadcast-mode commit":            `unknown broadcast mode "commit"`,
+		"otherkey " + ZeroCosmosAddr + " 10uatom":                                 "a key is needed to sign the transaction",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "bank", "send", "--generate-only"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestBankSend_Broadcast(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	hash := bytes.HexBytes{0xab, 0xcd}
+	for _, tc := range []struct {
+		name     string
+		code     uint32
+		exitCode int
+	}{
+		{name: "success"},
+		{name: "failure", code: 5, exitCode: cmd.ErrCodeTxFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := new(mocks.Client)
+			mockSendLookups(t, mc)
+			// The included transaction is the broadcast one.
+			resTx := &coretypes.ResultTx{
+				Hash:   hash,
+				Height: 42,
+				TxResult: abci.ResponseDeliverTx{Code: tc.code, Codespace: "bank", GasUsed: 90000, GasWanted: 120000, Log: "the log", Events: []abci.Event{
+					{Type: "message", Attributes: []abci.EventAttribute{{Key: "sender", Value: ZeroCosmosAddr}}},
+					{Type: "transfer", Attributes: []abci.EventAttribute{{Key: "recipient", Value: ZeroCosmosAddr}, {Key: "amount", Value: "10uatom"}}},
+					{Type: "tx", Attributes: []abci.EventAttribute{{Key: "fee", Value: "3000uatom"}}},
+				}},
+			}
+			mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				resTx.Tx = args.Get(1).(tmtypes.Tx)
+			}).Return(&coretypes.ResultBroadcastTx{Hash: hash}, nil)
+			mc.On("Tx", mock.Anything, []byte(hash), false).Return(resTx, nil)
+			sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+				RPCClient: mc,
+			})
+
+			res := sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "-o", "json")
+			require.Equal(t, tc.exitCode, res.ExitCode)
+			var out map[string]interface{}
+			require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+			require.Equal(t, "ABCD", out["txhash"])
+			require.EqualValues(t, 42, out["height"])
+			require.EqualValues(t, 90000, out["gas_used"])
+			require.Equal(t, "the log", out["raw_log"])
+			require.EqualValues(t, tc.code, out["code"])
+			require.NotContains(t, out, "events")
+
+			// Only the events of the given types are shown.
+			res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--show-events", "transfer,message", "-o", "text")
+			require.Equal(t, tc.exitCode, res.ExitCode)
+			text := res.Stdout.String()
+			require.Contains(t, text, "Events:\n")
+			require.Regexp(t, `\n  message\.sender +`+ZeroCosmosAddr+`\n  transfer\.recipient +`+ZeroCosmosAddr+`\n  transfer\.amount +10uatom\n`, text)
+			require.NotContains(t, text, "tx.fee")
+		})
+	}
+
+	// In async mode, only the hash is known.
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mc.On("BroadcastTxAsync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: hash}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res := sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--broadcast-mode", "async", "-o", "text")
+	require.Equal(t, []string{"TxHash:", "ABCD"}, strings.Fields(strings.Split(res.Stdout.String(), "\n")[0]))
+	mc.AssertNotCalled(t, "Tx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBankSend_AutoGasPrices(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mockABCIQuery(t, mc, "/cosmos.base.node.v1beta1.Service/Config", func(bytes.HexBytes) bool { return true },
+		&node.ConfigResponse{MinimumGasPrice: "0.005uatom"})
+	// The chain has no fee market module.
+	mc.On("ABCIQueryWithOptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 6, Log: "unknown query path"}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	var tx struct {
+		AuthInfo struct {
+			Fee struct {
+				Amount sdk.Coins
+			}
+		} `json:"auth_info"`
+	}
+	send := []string{"tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only"}
+
+	// The 120000 gas is priced at the minimum gas price of the node.
+	core, logs := observer.New(zap.InfoLevel)
+	res := sys.Run(zap.New(core), append(send, "--gas-prices", "auto", "--max-gas-price", "0.01uatom")...)
+	require.NoError(t, res.Err)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 600)), tx.AuthInfo.Fee.Amount)
+	require.Equal(t, 1, logs.FilterField(zap.String("source", "/cosmos.base.node.v1beta1.Service/Config")).Len())
+
+	res = sys.Run(zaptest.NewLogger(t), append(send, "--gas-prices", "auto", "--max-gas-price", "0.001uatom")...)
+	require.ErrorContains(t, res.Err, "discovered gas price 0.005000000000000000uatom, from /cosmos.base.node.v1beta1.Service/Config, exceeds the maximum gas price 0.001000000000000000uatom")
+	res = sys.Run(zaptest.NewLogger(t), append(send, "--max-gas-price", "0.01uatom")...)
+	require.ErrorContains(t, res.Err, "--max-gas-price only applies to discovered gas prices, with --gas-prices auto")
+
+	// With the chain's auto-gas-prices, the prices are discovered unless given.
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "auto-gas-prices", "true")
+	res = sys.MustRun(t, send...)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 600)), tx.AuthInfo.Fee.Amount)
+	res = sys.MustRun(t, append(send, "--gas-prices", "0.01uatom")...)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1200)), tx.AuthInfo.Fee.Amount)
+}
+
+func TestBankTotalSupply_Output(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	mc := new(mocks.Client)
+	value, err := (&banktypes.QueryTotalSupplyResponse{Supply: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))}).Marshal()
+	require.NoError(t, err)
+	mc.On("ABCIQueryWithOptions", mock.Anything, "/cosmos.bank.v1beta1.Query/TotalSupply", mock.Anything, mock.Anything).
+		Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The root --output flag selects the format, rather than a local flag of the command.
+	res := sys.MustRun(t, "query", "bank", "total-supply", "-o", "yaml", "--height", "5")
+	require.Contains(t, res.Stdout.String(), "supply:\n- amount: \"1000\"\n  denom: uatom\n")
+
+	res = sys.MustRun(t, "query", "bank", "total-supply", "--output", "json")
+	var out struct {
+		Supply sdk.Coins
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000)), out.Supply)
+
+	// Without --output, the chain's output-format is used, which defaults to compact JSON.
+	res = sys.MustRun(t, "query", "bank", "total-supply")
+	require.Equal(t, `{"supply":[{"denom":"uatom","amount":"1000"}],"pagination":{"next_key":null,"total":"0"}}`+"\n", res.Stdout.String())
+
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	cfg = []byte(strings.ReplaceAll(string(cfg), "output-format: json", "output-format: yaml"))
+	require.NoError(t, os.WriteFile(cfgPath, cfg, 0600))
+	res = sys.MustRun(t, "query", "bank", "total-supply")
+	require.Contains(t, res.Stdout.String(), "supply:\n- amount: \"1000\"\n  denom: uatom\n")
+	res = sys.MustRun(t, "query", "bank", "total-supply", "-o", "text")
+	require.NotContains(t, res.Stdout.String(), "supply:")
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const blockTxsFlag = "txs"
+
+// chainAndHeightArgsHelp describes the arguments resolved by chainClientAndHeight, for use in command help.
+const chainAndHeightArgsHelp = `If a single argument is given and it names a configured chain, the latest block of that chain is used;
+otherwise it is taken as a height on the default chain.
+The height must be between the earliest height available from the node and the current height of the chain.`
+
+// chainClientAndHeight resolves the optional [chain-name] [height] arguments of a block command
+// to the client of the chain, and the height, which defaults to the latest height of the chain.
+// The height is checked against the range of heights available from the node.
+func chainClientAndHeight(cmd *cobra.Command, a *appState, args []string) (*client.ChainClient, int64, error) {
+	chainName, heightArg := a.Config.DefaultChain, ""
+	switch len(args) {
+	case 1:
+		if _, ok := a.Config.Chains[args[0]]; ok {
+			chainName = args[0]
+		} else {
+			heightArg = args[0]
+		}
+	case 2:
+		chainName, heightArg = args[0], args[1]
+	}
+
+	var height int64
+	if heightArg != "" {
+		var err error
+		height, err = strconv.ParseInt(heightArg, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid height %q: %w", heightArg, err)
+		}
+	}
+
+	cl, err := chainClientByName(a, chainName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	status, err := cl.RPCClient.Status(cmd.Context())
+	if err != nil {
+		return nil, 0, err
+	}
+	latest, earliest := status.SyncInfo.LatestBlockHeight, status.SyncInfo.EarliestBlockHeight
+	switch {
+	case heightArg == "":
+		return cl, latest, nil
+	case height < 1 || height > latest:
+		return nil, 0, fmt.Errorf("height %d is out of range: the current height of chain %s is %d", height, chainName, latest)
+	case height < earliest:
+		return nil, 0, fmt.Errorf(
+			"height %d is not available: the earliest height kept by the node is %d (the current height of chain %s is %d)",
+			height, earliest, chainName, latest,
+		)
+	}
+	return cl, height, nil
+}
+
+func queryBlockCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "block [chain-name] [height]",
+		Short: "query a block of a chain",
+		Long: `Query a block of the given chain, or of the default chain, at the given height or the latest one,
+showing its time, its proposer, and its number of transactions.
+
+` + chainAndHeightArgsHelp + `
+
+The proposer is shown with the moniker of its validator, unless --no-resolve is set.
+With --txs, the transactions of the block are decoded too.
+With --output json or yaml, the block is written as returned by the node,
+with the decoded transactions added as decoded_txs.`,
+		Example: fmt.Sprintf(`$ %s query block cosmoshub
+$ %s q block cosmoshub 15000000 --txs`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(0, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, height, err := chainClientAndHeight(cmd, a, args)
+			if err != nil {
+				return err
+			}
+			withTxs, err := cmd.Flags().GetBool(blockTxsFlag)
+			if err != nil {
+				return err
+			}
+			noResolve, err := cmd.Flags().GetBool(noResolveFlag)
+			if err != nil {
+				return err
+			}
+
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{Height: height}}
+			res, err := query.Block()
+			if err != nil {
+				return err
+			}
+
+			summary := blockSummary{
+				Height:   res.Block.Height,
+				Hash:     res.BlockID.Hash.String(),
+				Time:     res.Block.Time,
+				Proposer: res.Block.ProposerAddress.String(),
+				TxCount:  len(res.Block.Txs),
+			}
+			if !noResolve {
+				// The moniker is only informative, so the block is shown even if it cannot be resolved.
+				summary.ProposerMoniker = proposerMoniker(cmd.Context(), cl, res.Block.ProposerAddress)
+			}
+			if withTxs {
+				summary.Txs = make([]decodedTx, len(res.Block.Txs))
+				for i, tx := range res.Block.Txs {
+					decoded, err := decodeTxResult(cl, &coretypes.ResultTx{Hash: tx.Hash(), Height: res.Block.Height, Tx: tx})
+					if err != nil {
+						return fmt.Errorf("transaction %d of block %d: %w", i, res.Block.Height, err)
+					}
+					summary.Txs[i] = decoded
+				}
+			}
+
+			structured, err := cmtjson.Marshal(res)
+			if err != nil {
+				return err
+			}
+			if withTxs {
+				structured, err = addJSONField(structured, "decoded_txs", summary.Txs)
+				if err != nil {
+					return err
+				}
+			}
+			return writeTextOr(cmd, a, summary, json.RawMessage(structured))
+		},
+	}
+	cmd.Flags().Bool(blockTxsFlag, false, "decode the transactions of the block")
+	cmd.Flags().Bool(noResolveFlag, false, "do not query the validators to resolve the moniker of the proposer")
+	return cmd
+}
+
+func queryBlockResultsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "block-results [chain-name] [height]",
+		Short: "query the results of executing a block of a chain",
+		Long: `Query the results of executing a block of the given chain, or of the default chain,
+at the given height or the latest one: the begin and end block events, and the result of each transaction.
+
+` + chainAndHeightArgsHelp + `
+
+With --output json or yaml, the results are written as returned by the node.`,
+		Example: fmt.Sprintf(`$ %s query block-results cosmoshub
+$ %s q block-results cosmoshub 15000000 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(0, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, height, err := chainClientAndHeight(cmd, a, args)
+			if err != nil {
+				return err
+			}
+
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{Height: height}}
+			res, err := query.BlockResults()
+			if err != nil {
+				return err
+			}
+
+			structured, err := cmtjson.Marshal(res)
+			if err != nil {
+				return err
+			}
+			return writeTextOr(cmd, a, blockResultsSummary{res}, json.RawMessage(structured))
+		},
+	}
+	return cmd
+}
+
+// proposerMoniker returns the moniker of the validator with the consensus address proposer,
+// or the empty string if it cannot be found.
+func proposerMoniker(ctx context.Context, cl *client.ChainClient, proposer []byte) string {
+	q := query.Query{Ctx: ctx, Client: cl, Options: &query.QueryOptions{}}
+	validators, err := q.Staking_AllValidators("")
+	if err != nil {
+		return ""
+	}
+	// The consensus keys are left packed by the query.
+	if err := validators.UnpackInterfaces(cl.Codec.InterfaceRegistry); err != nil {
+		return ""
+	}
+	for _, v := range validators {
+		addr, err := v.GetConsAddr()
+		if err == nil && bytes.Equal(addr, proposer) {
+			return v.Description.Moniker
+		}
+	}
+	return ""
+}
+
+// addJSONField returns the JSON object obj with the field name set to the JSON encoding of v.
+func addJSONField(obj []byte, name string, v interface{}) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(obj, &fields); err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields[name] = value
+	return json.Marshal(fields)
+}
+
+// blockSummary is the text output of query block.
+type blockSummary struct {
+	Height          int64
+	Hash            string
+	Time            time.Time
+	Proposer        string
+	ProposerMoniker string
+	TxCount         int
+	Txs             []decodedTx
+}
+
+var _ fmt.Stringer = blockSummary{}
+
+// String returns the fields of the block one per line, followed by its decoded transactions if any.
+func (b blockSummary) String() string {
+	var s strings.Builder
+	w := tabwriter.NewWriter(&s, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Height:\t%d\n", b.Height)
+	fmt.Fprintf(w, "Hash:\t%s\n", b.Hash)
+	fmt.Fprintf(w, "Time:\t%s\n", b.Time.UTC().Format(time.RFC3339Nano))
+	if b.ProposerMoniker != "" {
+		fmt.Fprintf(w, "Proposer:\t%s (%s)\n", b.Proposer, b.ProposerMoniker)
+	} else {
+		fmt.Fprintf(w, "Proposer:\t%s\n", b.Proposer)
+	}
+	fmt.Fprintf(w, "Txs:\t%d\n", b.TxCount)
+	w.Flush()
+
+	for i, tx := range b.Txs {
+		fmt.Fprintf(&s, "\nTx %d:\n%s", i, tx)
+	}
+	return s.String()
+}
+
+// blockResultsSummary is the text output of query block-results.
+type blockResultsSummary struct {
+	*coretypes.ResultBlockResults
+}
+
+var _ fmt.Stringer = blockResultsSummary{}
+
+// String returns the begin block events, the result of each transaction, and the end block events.
+func (r blockResultsSummary) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Height: %d\n", r.Height)
+
+	fmt.Fprintln(&s, "\nBegin block:")
+	writeStringEvents(&s, sdk.StringifyEvents(r.BeginBlockEvents))
+
+	for i, tx := range r.TxsResults {
+		fmt.Fprintf(&s, "\nTx %d: code %d", i, tx.Code)
+		if tx.Codespace != "" {
+			fmt.Fprintf(&s, " (%s)", tx.Codespace)
+		}
+		fmt.Fprintf(&s, ", gas used/wanted %d/%d\n", tx.GasUsed, tx.GasWanted)
+		if tx.Code != 0 {
+			fmt.Fprintf(&s, "Log: %s\n", tx.Log)
+		}
+		writeStringEvents(&s, sdk.StringifyEvents(tx.Events))
+	}
+
+	fmt.Fprintln(&s, "\nEnd block:")
+	writeStringEvents(&s, sdk.StringifyEvents(r.EndBlockEvents))
+	return s.String()
+}
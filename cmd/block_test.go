@@ -0,0 +1,169 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mockBlockStatus makes mc report that the node has the blocks from height 10 to 100.
+func mockBlockStatus(mc *mocks.Client) {
+	mc.On("Status", mock.Anything).Return(&coretypes.ResultStatus{
+		SyncInfo: coretypes.SyncInfo{LatestBlockHeight: 100, EarliestBlockHeight: 10},
+	}, nil)
+}
+
+// matchHeight matches a height argument of an RPC client method.
+func matchHeight(height int64) interface{} {
+	return mock.MatchedBy(func(h *int64) bool { return h != nil && *h == height })
+}
+
+func TestQueryBlock(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	pk := ed25519.GenPrivKey().PubKey()
+	pkAny, err := codectypes.NewAnyWithValue(pk)
+	require.NoError(t, err)
+
+	mc := new(mocks.Client)
+	mockBlockStatus(mc)
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validators", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorsResponse{
+			Validators: []stakingtypes.Validator{{
+				OperatorAddress: testValoperA,
+				ConsensusPubkey: pkAny,
+				Description:     stakingtypes.Description{Moniker: "alpha"},
+			}},
+			Pagination: &query.PageResponse{},
+		})
+
+	tx := testTxResult(t, 50, 10).Tx
+	blockTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, height := range []int64{50, 100} {
+		mc.On("Block", mock.Anything, matchHeight(height)).Return(&coretypes.ResultBlock{
+			BlockID: tmtypes.BlockID{Hash: []byte{0xab, 0xcd}},
+			Block: &tmtypes.Block{
+				Header: tmtypes.Header{Height: height, Time: blockTime, ProposerAddress: pk.Address()},
+				Data:   tmtypes.Data{Txs: tmtypes.Txs{tx}},
+			},
+		}, nil)
+	}
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "block", "cosmoshub", "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Height:    100\n")
+	require.Contains(t, out, "Hash:      ABCD\n")
+	require.Contains(t, out, "Time:      2026-01-02T03:04:05Z\n")
+	require.Contains(t, out, "Proposer:  "+pk.Address().String()+" (alpha)\n")
+	require.Contains(t, out, "Txs:       1\n")
+
+	res = sys.MustRun(t, "query", "block", "50", "--txs", "--no-resolve", "-o", "text")
+	out = res.Stdout.String()
+	require.Contains(t, out, "Height:    50\n")
+	require.Contains(t, out, "Proposer:  "+pk.Address().String()+"\n")
+	require.Contains(t, out, "Message 0: /cosmos.bank.v1beta1.MsgSend")
+
+	// The JSON output is the block as returned by the node, with the decoded transactions.
+	res = sys.MustRun(t, "query", "block", "cosmoshub", "50", "--txs", "-o", "json")
+	var block struct {
+		Block struct {
+			Header struct {
+				Height string
+			}
+		}
+		DecodedTxs []struct {
+			Fee []map[string]string
+		} `json:"decoded_txs"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &block))
+	require.Equal(t, "50", block.Block.Header.Height)
+	require.Equal(t, "10", block.DecodedTxs[0].Fee[0]["amount"])
+
+	for _, height := range []string{"-1", "0", "101", "5"} {
+		// A negative height is only taken as an argument after --.
+		res = sys.Run(zaptest.NewLogger(t), "query", "block", "cosmoshub", "--", height)
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "height "+height+" ")
+		require.Contains(t, res.Err.Error(), "the current height of chain cosmoshub is 100")
+	}
+}
+
+func TestQueryBlockResults(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockBlockStatus(mc)
+	mc.On("BlockResults", mock.Anything, matchHeight(42)).Return(&coretypes.ResultBlockResults{
+		Height: 42,
+		BeginBlockEvents: []abci.Event{
+			{Type: "mint", Attributes: []abci.EventAttribute{{Key: "amount", Value: "100"}}},
+		},
+		TxsResults: []*abci.ResponseDeliverTx{
+			{Code: 0, GasWanted: 200, GasUsed: 150, Events: []abci.Event{
+				{Type: "transfer", Attributes: []abci.EventAttribute{{Key: "amount", Value: "5uatom"}}},
+			}},
+			{Code: 5, Codespace: "sdk", Log: "insufficient funds", GasWanted: 200, GasUsed: 50},
+		},
+		EndBlockEvents: []abci.Event{
+			{Type: "complete_unbonding", Attributes: []abci.EventAttribute{{Key: "validator", Value: testValoperA}}},
+		},
+	}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "block-results", "cosmoshub", "42", "-o", "text")
+	require.Equal(t, strings.Join([]string{
+		"Height: 42",
+		"",
+		"Begin block:",
+		"Events:",
+		"  mint: amount=100",
+		"",
+		"Tx 0: code 0, gas used/wanted 150/200",
+		"Events:",
+		"  transfer: amount=5uatom",
+		"",
+		"Tx 1: code 5 (sdk), gas used/wanted 50/200",
+		"Log: insufficient funds",
+		"",
+		"End block:",
+		"Events:",
+		"  complete_unbonding: validator=" + testValoperA,
+		"",
+	}, "\n"), res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "block-results", "42", "-o", "json")
+	var results struct {
+		Height     string
+		TxsResults []struct {
+			Code int
+		} `json:"txs_results"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &results))
+	require.Equal(t, "42", results.Height)
+	require.Len(t, results.TxsResults, 2)
+	require.Equal(t, 5, results.TxsResults[1].Code)
+}
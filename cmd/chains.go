@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -10,8 +12,10 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
 	"github.com/strangelove-ventures/lens/client/chain_registry"
 	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
 )
 
 func chainsCmd(a *appState) *cobra.Command {
@@ -22,12 +26,15 @@ func chainsCmd(a *appState) *cobra.Command {
 	}
 
 	cmd.AddCommand(
-		cmdChainsAdd(a),
-		cmdChainsDelete(a),
-		cmdChainsEdit(a),
+		withConfigLock(a, cmdChainsAdd(a)),
+		withConfigLock(a, cmdChainsDelete(a)),
+		withConfigLock(a, cmdChainsEdit(a)),
 		cmdChainsList(a),
 		cmdChainsShow(a),
-		cmdChainsSetDefault(a),
+		cmdChainsExport(a),
+		withConfigLock(a, cmdChainsImport(a)),
+		cmdChainsStatus(a),
+		withConfigLock(a, cmdChainsSetDefault(a)),
 		cmdChainsRegistryList(a),
 		cmdChainsShowDefault(a),
 		cmdChainsEditorDefault(),
@@ -43,27 +50,69 @@ func cmdChainsRegistryList(a *appState) *cobra.Command {
 		Aliases: []string{"rl"},
 		Short:   "list chains available for configuration from the registry",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			chains, err := chain_registry.DefaultChainRegistry(a.Log).ListChains(cmd.Context())
+			cl, err := defaultChainClient(a)
 			if err != nil {
 				return err
 			}
-			return a.Config.GetDefaultClient().PrintObject(chains)
+			chains, err := chain_registry.DefaultChainRegistry(a.Log, chain_registry.WithHTTPClient(a.HTTPClient)).ListChains(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return cl.PrintObject(chains)
 		},
 	}
 	return cmd
 }
 
 func cmdChainsAdd(a *appState) *cobra.Command {
+	const (
+		forceFlag         = "force"
+		endpointIndexFlag = "endpoint-index"
+		registryURLFlag   = "registry-url"
+	)
+
 	cmd := &cobra.Command{
 		Use:     "add [[chain-name]]",
 		Args:    cobra.MinimumNArgs(1),
 		Aliases: []string{"a"},
 		Short:   "add configuration for a chain or a number of chains from the chain registry",
+		Long: `Add configuration for a chain or a number of chains from the chain registry.
+
+Each chain's ID, account prefix, and gas prices are taken from its chain.json in the registry.
+Its RPC and gRPC addresses are the first endpoints listed in the registry that respond,
+or the endpoints at --endpoint-index in the registry's lists, without checking them.
+
+Chains that are already configured are skipped unless --force is set.`,
+		Example: fmt.Sprintf(`$ %s chains add osmosis juno
+$ %s chains add osmosis --force --endpoint-index 1`,
+			appName, appName),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			registry := chain_registry.DefaultChainRegistry(a.Log)
+			force, err := cmd.Flags().GetBool(forceFlag)
+			if err != nil {
+				return err
+			}
+			endpointIndex, err := cmd.Flags().GetInt(endpointIndexFlag)
+			if err != nil {
+				return err
+			}
+			registryURL, err := cmd.Flags().GetString(registryURLFlag)
+			if err != nil {
+				return err
+			}
+
+			registry := chain_registry.DefaultChainRegistry(
+				a.Log,
+				chain_registry.WithHTTPClient(a.HTTPClient),
+				chain_registry.WithBaseURL(registryURL),
+			)
 			overwriteConfig := false
 
 			for _, chain := range args {
+				if _, ok := a.Config.Chains[chain]; ok && !force {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Ignoring add request for %s, chain already exists (use --%s to overwrite).\n", chain, forceFlag)
+					continue
+				}
+
 				chainInfo, err := registry.GetChain(cmd.Context(), chain)
 				if err != nil {
 					a.Log.Info(
@@ -74,7 +123,7 @@ func cmdChainsAdd(a *appState) *cobra.Command {
 					continue
 				}
 
-				chainConfig, err := chainInfo.GetChainConfig(cmd.Context())
+				chainConfig, err := chainInfo.GetChainConfig(cmd.Context(), endpointIndex)
 				if err != nil {
 					a.Log.Info(
 						"Failed to generate chain config",
@@ -93,19 +142,23 @@ func cmdChainsAdd(a *appState) *cobra.Command {
 			}
 		},
 	}
+	cmd.Flags().Bool(forceFlag, false, "overwrite the configuration of chains that already exist")
+	cmd.Flags().Int(endpointIndexFlag, -1, "use the endpoints at this index in the registry instead of the first healthy ones")
+	cmd.Flags().String(registryURLFlag, chain_registry.DefaultRegistryURL, "base URL of the chain registry files")
 	return cmd
 }
 
 func cmdChainsDelete(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "delete [[chain-name]]",
-		Aliases: []string{"d"},
-		Short:   "delete a chain from the configuration",
-		Args:    cobra.MinimumNArgs(1),
+		Use:               "delete [[chain-name]]",
+		Aliases:           []string{"d"},
+		Short:             "delete a chain from the configuration",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeChainNames(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			originalChainCount := len(a.Config.Chains)
 			for _, arg := range args {
-				if a.Config.DefaultChain == arg {
+				if a.Config.DefaultChain == arg || a.Config.ConfiguredDefaultChain() == arg {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Ignoring delete request for %s, unable to delete default chain.\n", arg)
 					continue
 				}
@@ -128,72 +181,206 @@ func cmdChainsEdit(a *appState) *cobra.Command {
 		Use:     "edit [chain-name] [key] [value]",
 		Aliases: []string{"e"},
 		Short:   "edit a chain configuration value",
-		Args:    cobra.ExactArgs(3),
+		Long: `Edit a chain configuration value.
+
+The rpc-addrs and grpc-addrs keys take a comma-separated list of fallback endpoints,
+tried in order when the rpc-addr or grpc-addr endpoint does not accept connections.
+
+The grpc-headers key takes a comma-separated list of key=value headers sent with every gRPC call,
+replacing the current ones; a value of env:VARNAME is read from the environment variable VARNAME when used,
+so that secrets are not stored in the configuration file.
+
+The proxy key sets the proxy of the connections to the chain's endpoints, as a socks5, socks5h, http, or https URL,
+or direct for none; if unset, HTTPS_PROXY, HTTP_PROXY, or ALL_PROXY apply. The rpc-proxy key overrides it for RPC endpoints,
+such as rpc-proxy direct to only proxy gRPC.
+
+The grpc-max-recv-msg-size key sets the largest gRPC response accepted, in bytes (64MB if unset),
+and the grpc-keepalive-time, grpc-keepalive-timeout, and grpc-keepalive-permit-without-stream keys
+ping idle gRPC connections so that load balancers do not drop them.
+
+The extra-msg-descriptors key takes a comma-separated list of protobuf descriptor sets,
+as written by buf build or protoc --include_imports --descriptor_set_out,
+whose Msg service messages are decoded in transactions and query responses; relative paths are relative to the lens home directory.
+With reflect-msgs true, query tx decodes the messages the chain's gRPC endpoint lists that are not compiled into lens
+from the descriptors it serves, cached in the lens home directory.
+
+The rate-limit key paces the requests sent to each endpoint of the chain, as RATE or RATE:BURST requests per second,
+such as 5 or 10:20, for public endpoints that throttle aggressive clients. Requests the endpoint throttles anyway,
+with HTTP 429 or gRPC RESOURCE_EXHAUSTED, are retried after backing off exponentially, rate-limit-retries times
+(3 if unset, none if negative), whether or not rate-limit is set.
+
+The retries key sets how many times the requests reading the chain that fail transiently, such as with a reset connection
+or HTTP 502, 503, or 504 from a load balancer, are retried (2 if unset, none if negative), after backing off exponentially
+from retry-backoff (250ms if unset). Transactions are never broadcast again.`,
+		Example: fmt.Sprintf(`$ %s chains edit cosmoshub rpc-addr https://rpc.cosmos.directory:443/cosmoshub
+$ %s chains edit cosmoshub grpc-addrs grpc-1.example.com:9090,grpc-2.example.com:9090
+$ %s chains edit cosmoshub grpc-headers x-api-key=env:COSMOSHUB_API_KEY
+$ %s chains edit cosmoshub proxy socks5h://127.0.0.1:9050
+$ %s chains edit cosmoshub grpc-keepalive-time 30s
+$ %s chains edit cosmoshub rate-limit 5:10`,
+			appName, appName, appName, appName, appName, appName),
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: completeChainNames(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if _, ok := a.Config.Chains[args[0]]; !ok {
+			orig, ok := a.Config.Chains[args[0]]
+			if !ok {
 				return fmt.Errorf("chain %s not found in configuration", args[0])
 			}
+
+			// Edit a copy, so that the configuration is left unchanged if the new value is invalid.
+			chain := *orig
 			switch args[1] {
 			case "key":
-				a.Config.Chains[args[0]].Key = args[2]
+				chain.Key = args[2]
 			case "chain-id":
-				a.Config.Chains[args[0]].ChainID = args[2]
+				chain.ChainID = args[2]
 			case "rpc-addr":
-				a.Config.Chains[args[0]].RPCAddr = args[2]
+				chain.RPCAddr = args[2]
+			case "rpc-addrs":
+				chain.RPCAddrs = splitList(args[2])
 			case "grpc-addr":
-				a.Config.Chains[args[0]].GRPCAddr = args[2]
+				chain.GRPCAddr = args[2]
+			case "grpc-addrs":
+				chain.GRPCAddrs = splitList(args[2])
+			case "grpc-tls":
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return err
+				}
+				chain.GRPCTLS = b
+			case "grpc-tls-ca-file":
+				chain.GRPCTLSCAFile = args[2]
+			case "grpc-headers":
+				headers, err := client.ParseGRPCHeaders(splitList(args[2]))
+				if err != nil {
+					return err
+				}
+				chain.GRPCHeaders = headers
+			case "grpc-max-recv-msg-size":
+				n, err := strconv.Atoi(args[2])
+				if err != nil {
+					return err
+				}
+				chain.GRPCMaxRecvMsgSize = n
+			case "grpc-keepalive-time":
+				chain.GRPCKeepaliveTime = args[2]
+			case "grpc-keepalive-timeout":
+				chain.GRPCKeepaliveTimeout = args[2]
+			case "grpc-keepalive-permit-without-stream":
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return err
+				}
+				chain.GRPCKeepalivePermitWithoutStream = b
 			case "account-prefix":
-				a.Config.Chains[args[0]].AccountPrefix = args[2]
+				chain.AccountPrefix = args[2]
 			case "gas-adjustment":
 				fl, err := strconv.ParseFloat(args[2], 64)
 				if err != nil {
 					return err
 				}
-				a.Config.Chains[args[0]].GasAdjustment = fl
+				chain.GasAdjustment = fl
 			case "gas-prices":
-				a.Config.Chains[args[0]].GasPrices = args[2]
+				chain.GasPrices = args[2]
+			case "auto-gas-prices":
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return err
+				}
+				chain.AutoGasPrices = b
 			case "min-gas-amount":
 				ga, err := strconv.ParseUint(args[2], 10, 64)
 				if err != nil {
 					return err
 				}
-				a.Config.Chains[args[0]].MinGasAmount = ga
+				chain.MinGasAmount = ga
 			case "debug":
 				b, err := strconv.ParseBool(args[2])
 				if err != nil {
 					return err
 				}
-				a.Config.Chains[args[0]].Debug = b
+				chain.Debug = b
 			case "timeout":
-				a.Config.Chains[args[0]].Timeout = args[2]
+				chain.Timeout = args[2]
+			case "keyring-backend":
+				chain.KeyringBackend = args[2]
+			case "fee-granter":
+				chain.FeeGranter = args[2]
+			case "proxy":
+				chain.Proxy = args[2]
+			case "rpc-proxy":
+				chain.RPCProxy = args[2]
+			case "extra-msg-descriptors":
+				chain.ExtraMsgDescriptors = splitList(args[2])
+			case "rate-limit":
+				chain.RateLimit = args[2]
+			case "rate-limit-retries":
+				n, err := strconv.Atoi(args[2])
+				if err != nil {
+					return err
+				}
+				chain.RateLimitRetries = n
+			case "retries":
+				n, err := strconv.Atoi(args[2])
+				if err != nil {
+					return err
+				}
+				chain.Retries = n
+			case "retry-backoff":
+				chain.RetryBackoff = args[2]
+			case "reflect-msgs":
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return err
+				}
+				chain.ReflectMsgs = b
+			case "slip44":
+				n, err := strconv.ParseUint(args[2], 10, 31)
+				if err != nil {
+					return err
+				}
+				chain.Slip44 = int(n)
 			default:
-				return fmt.Errorf("unknown key %s, try 'key', 'chain-id', 'rpc-addr', 'grpc-addr', 'account-prefix', 'gas-adjustment', 'gas-prices', 'min-gas-amount', 'debug', or 'timeout'", args[1])
+				return fmt.Errorf("unknown key %s, try 'key', 'chain-id', 'rpc-addr', 'rpc-addrs', 'grpc-addr', 'grpc-addrs', 'grpc-tls', 'grpc-tls-ca-file', 'grpc-headers', 'grpc-max-recv-msg-size', 'grpc-keepalive-time', 'grpc-keepalive-timeout', 'grpc-keepalive-permit-without-stream', 'account-prefix', 'gas-adjustment', 'gas-prices', 'auto-gas-prices', 'min-gas-amount', 'debug', 'timeout', 'keyring-backend', 'fee-granter', 'proxy', 'rpc-proxy', 'extra-msg-descriptors', 'reflect-msgs', 'rate-limit', 'rate-limit-retries', 'retries', 'retry-backoff', or 'slip44'", args[1])
+			}
+
+			// Only reject problems with the edited field,
+			// so that other invalid fields can still be fixed one at a time.
+			if err := chain.Validate(); err != nil {
+				var errs client.ConfigErrors
+				if errors.As(err, &errs) {
+					if err := errs.Field(args[1]); err != nil {
+						return err
+					}
+				}
 			}
+
+			*orig = chain
 			return a.OverwriteConfig(a.Config)
 		},
 	}
 	return cmd
 }
 
-func cmdChainsList(a *appState) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:     "list",
-		Aliases: []string{"l"},
-		Short:   "List all chains in the configuration",
-		Args:    cobra.NoArgs,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return a.Config.GetDefaultClient().PrintObject(a.Config.Chains)
-		},
+// splitList splits a comma-separated list, such as of endpoints, as given to chains edit.
+// An empty string yields an empty list.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return cmd
+	return items
 }
 
 func cmdChainsShow(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "show [chain-name]",
-		Aliases: []string{"s"},
-		Short:   "show an individual chain configuration",
-		Args:    cobra.RangeArgs(0, 1),
+		Use:               "show [chain-name]",
+		Aliases:           []string{"s"},
+		Short:             "show an individual chain configuration",
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				// Return a helpful error so the user knows which chain names are available.
@@ -206,7 +393,11 @@ func cmdChainsShow(a *appState) *cobra.Command {
 			}
 
 			if ch, ok := a.Config.Chains[args[0]]; ok {
-				return a.Config.GetDefaultClient().PrintObject(ch)
+				cl, err := defaultChainClient(a)
+				if err != nil {
+					return err
+				}
+				return cl.PrintObject(ch)
 			}
 			return fmt.Errorf("chain %s not found", args[0])
 		},
@@ -214,15 +405,121 @@ func cmdChainsShow(a *appState) *cobra.Command {
 	return cmd
 }
 
+func cmdChainsExport(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [[chain-name]]",
+		Short: "print the configuration of one or more chains, to be shared or imported elsewhere",
+		Long: `Print the configuration of one or more chains, keyed by chain name,
+in a form that can be read back by the chains import command.
+
+The configuration is printed as JSON, or as YAML with --output yaml.`,
+		Args: cobra.MinimumNArgs(1),
+		Example: fmt.Sprintf(`$ %s chains export cosmoshub > cosmoshub.json
+$ %s chains export cosmoshub osmosis -o yaml`,
+			appName, appName),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chains := make(map[string]*client.ChainClientConfig, len(args))
+			for _, name := range args {
+				chain, ok := a.Config.Chains[name]
+				if !ok {
+					return ChainNotFoundError{Requested: name, Config: a.Config}
+				}
+				chains[name] = chain
+			}
+			return writeOutput(cmd, a, chains)
+		},
+	}
+	return cmd
+}
+
+func cmdChainsImport(a *appState) *cobra.Command {
+	const forceFlag = "force"
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "add chain configurations from a file or stdin, as printed by chains export",
+		Long: `Add the chain configurations in the given file, or in stdin if the file is omitted or "-".
+The input is a JSON or YAML object keyed by chain name, as printed by the chains export command.
+
+Each chain's key directory is set to the local keys directory for its chain ID,
+as key directories on other machines are not meaningful here.
+
+If any imported chain is already configured, nothing is imported unless --force is set.`,
+		Args: cobra.MaximumNArgs(1),
+		Example: fmt.Sprintf(`$ %s chains import cosmoshub.json
+$ %s chains export cosmoshub | %s --home /tmp/lens chains import --force`,
+			appName, appName, appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, err := cmd.Flags().GetBool(forceFlag)
+			if err != nil {
+				return err
+			}
+
+			var in []byte
+			if len(args) == 0 || args[0] == "-" {
+				in, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				in, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read chain configurations: %w", err)
+			}
+
+			// YAML is a superset of JSON, so this accepts both formats.
+			var chains map[string]*client.ChainClientConfig
+			if err := yaml.UnmarshalStrict(in, &chains); err != nil {
+				return fmt.Errorf("failed to parse chain configurations: %w", err)
+			}
+			if len(chains) == 0 {
+				return errors.New("no chain configurations found in input")
+			}
+
+			names := make([]string, 0, len(chains))
+			for name := range chains {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			// Check every chain before changing anything, so that a failed import leaves the configuration as it was.
+			home := a.Viper.GetString("home")
+			for _, name := range names {
+				chain := chains[name]
+				if chain == nil {
+					return fmt.Errorf("chain %s has no configuration", name)
+				}
+				if _, ok := a.Config.Chains[name]; ok && !force {
+					return fmt.Errorf("chain %s already exists (use --%s to overwrite)", name, forceFlag)
+				}
+				chain.KeyDirectory = path.Join(home, "keys", chain.ChainID)
+				if err := chain.Validate(); err != nil {
+					return fmt.Errorf("invalid configuration for chain %s: %w", name, err)
+				}
+			}
+
+			if a.Config.Chains == nil {
+				a.Config.Chains = make(map[string]*client.ChainClientConfig, len(chains))
+			}
+			for _, name := range names {
+				a.Config.Chains[name] = chains[name]
+			}
+			return a.OverwriteConfig(a.Config)
+		},
+	}
+	cmd.Flags().Bool(forceFlag, false, "overwrite the configuration of chains that already exist")
+	return cmd
+}
+
 func cmdChainsSetDefault(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "set-default [chain-name]",
-		Aliases: []string{"sd"},
-		Short:   "set the default chain",
-		Args:    cobra.ExactArgs(1),
+		Use:               "set-default [chain-name]",
+		Aliases:           []string{"sd"},
+		Short:             "set the default chain",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeChainNames(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if _, ok := a.Config.Chains[args[0]]; ok {
-				a.Config.DefaultChain = args[0]
+				a.Config.SetDefaultChain(args[0])
 				return a.OverwriteConfig(a.Config)
 			}
 			return fmt.Errorf("chain %s not found", args[0])
@@ -267,7 +564,7 @@ an editor in your path that can be called using $LENS_EDITOR <file-path>.`,
 				}
 			}
 
-			c := exec.Command(editor, path.Join(home, "config.yaml"))
+			c := exec.Command(editor, configFilePath(home))
 			c.Stdin = os.Stdin
 			c.Stdout = os.Stdout
 			return c.Run()
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+// chainListColumn is a column of the table printed by chains list.
+type chainListColumn struct {
+	field  string
+	header string
+	value  func(e chainListEntry) string
+}
+
+// chainListColumns are the columns of chains list, in their default order.
+var chainListColumns = []chainListColumn{
+	{"name", "NAME", func(e chainListEntry) string { return e.Name }},
+	{"chain-id", "CHAIN-ID", func(e chainListEntry) string { return e.ChainID }},
+	{"rpc-addr", "RPC", func(e chainListEntry) string { return e.RPCAddr }},
+	{"grpc-addr", "GRPC", func(e chainListEntry) string { return e.GRPCAddr }},
+	{"account-prefix", "PREFIX", func(e chainListEntry) string { return e.AccountPrefix }},
+	{"gas-prices", "GAS-PRICES", func(e chainListEntry) string { return e.GasPrices }},
+	{"keys", "KEYS", func(e chainListEntry) string {
+		if e.Keys == nil {
+			return ""
+		}
+		return strconv.Itoa(*e.Keys)
+	}},
+	{"default", "DEFAULT", func(e chainListEntry) string {
+		if e.Default {
+			return "*"
+		}
+		return ""
+	}},
+}
+
+// chainListFilters are the keys accepted by the --filter flag of chains list,
+// mapped to whether an entry matches a value.
+var chainListFilters = map[string]func(e chainListEntry, value string) (bool, error){
+	"name":            func(e chainListEntry, v string) (bool, error) { return e.Name == v, nil },
+	"chain-id":        func(e chainListEntry, v string) (bool, error) { return e.ChainID == v, nil },
+	"prefix":          func(e chainListEntry, v string) (bool, error) { return e.AccountPrefix == v, nil },
+	"keyring-backend": func(e chainListEntry, v string) (bool, error) { return e.KeyringBackend == v, nil },
+	"has-rpc":         boolFilter(func(e chainListEntry) bool { return len(e.RPCEndpoints()) > 0 }),
+	"has-grpc":        boolFilter(func(e chainListEntry) bool { return len(e.GRPCEndpoints()) > 0 }),
+	"default":         boolFilter(func(e chainListEntry) bool { return e.Default }),
+}
+
+func boolFilter(f func(e chainListEntry) bool) func(e chainListEntry, value string) (bool, error) {
+	return func(e chainListEntry, value string) (bool, error) {
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("value %q is not a boolean", value)
+		}
+		return f(e) == want, nil
+	}
+}
+
+func cmdChainsList(a *appState) *cobra.Command {
+	const (
+		fieldsFlag = "fields"
+		filterFlag = "filter"
+		sortFlag   = "sort"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l"},
+		Short:   "List all chains in the configuration",
+		Long: fmt.Sprintf(`List the configured chains as a table of their name, chain ID, primary endpoints,
+account prefix, gas prices, number of keys, and whether they are the default chain.
+
+--%[1]s selects and orders the columns of the table, among: %[2]s.
+--%[3]s key=value, which may be repeated, lists only the chains matching every filter,
+with keys among: %[4]s.
+The has-rpc, has-grpc, and default filters take true or false.
+
+With --output json or yaml, the full configuration of each chain is written instead,
+with its name, number of keys, and whether it is the default chain.
+gRPC header values, other than references to environment variables, and proxy passwords are redacted.
+
+The number of keys is only known for the file and test keyring backends.`,
+			fieldsFlag, strings.Join(chainListFieldNames(), ", "), filterFlag, strings.Join(chainListFilterKeys(), ", ")),
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`$ %[1]s chains list
+$ %[1]s chains list --fields name,chain-id,keys --sort chain-id
+$ %[1]s chains list --filter prefix=osmo
+$ %[1]s chains list --filter has-grpc=false -o json`,
+			appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fields, err := cmd.Flags().GetStringSlice(fieldsFlag)
+			if err != nil {
+				return err
+			}
+			filters, err := cmd.Flags().GetStringArray(filterFlag)
+			if err != nil {
+				return err
+			}
+			sortBy, err := cmd.Flags().GetString(sortFlag)
+			if err != nil {
+				return err
+			}
+
+			columns, err := selectChainListColumns(fields)
+			if err != nil {
+				return err
+			}
+
+			entries := make(chainListEntries, 0, len(a.Config.Chains))
+			for name, chain := range a.Config.Chains {
+				entries = append(entries, newChainListEntry(name, chain, name == a.Config.DefaultChain))
+			}
+
+			entries, err = filterChainListEntries(entries, filters)
+			if err != nil {
+				return err
+			}
+
+			switch sortBy {
+			case "name":
+				sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+			case "chain-id":
+				sort.Slice(entries, func(i, j int) bool {
+					if entries[i].ChainID != entries[j].ChainID {
+						return entries[i].ChainID < entries[j].ChainID
+					}
+					return entries[i].Name < entries[j].Name
+				})
+			default:
+				return fmt.Errorf("invalid --%s %q (must be name or chain-id)", sortFlag, sortBy)
+			}
+
+			return writeTextOr(cmd, a, chainListTable{entries: entries, columns: columns}, entries)
+		},
+	}
+
+	cmd.Flags().StringSlice(fieldsFlag, nil, "comma-separated columns of the table (default all)")
+	cmd.Flags().StringArray(filterFlag, nil, "only list chains matching key=value (repeatable)")
+	cmd.Flags().String(sortFlag, "name", "sort the chains by name or chain-id")
+	return cmd
+}
+
+// chainListEntry is a chain as listed by chains list.
+type chainListEntry struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	// Keys is the number of keys of the chain, if it is known.
+	Keys *int `json:"keys,omitempty"`
+
+	*client.ChainClientConfig
+}
+
+type chainListEntries []chainListEntry
+
+func newChainListEntry(name string, chain *client.ChainClientConfig, isDefault bool) chainListEntry {
+	e := chainListEntry{
+		Name:              name,
+		Default:           isDefault,
+		ChainClientConfig: redactedChainConfig(chain),
+	}
+	if n, ok := storedKeyCount(chain); ok {
+		e.Keys = &n
+	}
+	return e
+}
+
+// redactedChainConfig returns a copy of chain without the secrets it may hold:
+// the gRPC header values that are not references to environment variables, and proxy passwords.
+func redactedChainConfig(chain *client.ChainClientConfig) *client.ChainClientConfig {
+	c := *chain
+	if len(chain.GRPCHeaders) > 0 {
+		c.GRPCHeaders = make(map[string]string, len(chain.GRPCHeaders))
+		for k, v := range chain.GRPCHeaders {
+			if !strings.HasPrefix(v, client.GRPCHeaderEnvPrefix) {
+				v = "REDACTED"
+			}
+			c.GRPCHeaders[k] = v
+		}
+	}
+	c.Proxy = redactedURL(chain.Proxy)
+	c.RPCProxy = redactedURL(chain.RPCProxy)
+	return &c
+}
+
+// redactedURL returns rawURL with any password replaced, or rawURL itself if it is not a URL.
+func redactedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// storedKeyCount returns the number of keys of chain, if its keyring backend stores them in its key directory.
+// The keys are counted from the names of the files, so that no passphrase is needed.
+func storedKeyCount(chain *client.ChainClientConfig) (int, bool) {
+	if chain.KeyringBackend != keyring.BackendFile && chain.KeyringBackend != keyring.BackendTest {
+		return 0, false
+	}
+
+	entries, err := os.ReadDir(filepath.Join(chain.KeyDirectory, "keyring-"+chain.KeyringBackend))
+	if err != nil {
+		// No key was ever added.
+		return 0, os.IsNotExist(err)
+	}
+	var n int
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".info") {
+			n++
+		}
+	}
+	return n, true
+}
+
+func chainListFieldNames() []string {
+	names := make([]string, len(chainListColumns))
+	for i, c := range chainListColumns {
+		names[i] = c.field
+	}
+	return names
+}
+
+func chainListFilterKeys() []string {
+	keys := make([]string, 0, len(chainListFilters))
+	for k := range chainListFilters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// selectChainListColumns returns the columns with the given field names, in order,
+// or every column if none is given.
+func selectChainListColumns(fields []string) ([]chainListColumn, error) {
+	if len(fields) == 0 {
+		return chainListColumns, nil
+	}
+
+	columns := make([]chainListColumn, 0, len(fields))
+	for _, f := range fields {
+		var found bool
+		for _, c := range chainListColumns {
+			if c.field == f {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown field %q (must be one of %s)", f, strings.Join(chainListFieldNames(), ", "))
+		}
+	}
+	return columns, nil
+}
+
+// filterChainListEntries returns the entries matching every filter, each given as key=value.
+func filterChainListEntries(entries chainListEntries, filters []string) (chainListEntries, error) {
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q (must be key=value)", filter)
+		}
+		match, ok := chainListFilters[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter key %q (must be one of %s)", key, strings.Join(chainListFilterKeys(), ", "))
+		}
+
+		kept := entries[:0]
+		for _, e := range entries {
+			ok, err := match(e, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			if ok {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+	return entries, nil
+}
+
+// chainListTable is the text output of chains list.
+type chainListTable struct {
+	entries chainListEntries
+	columns []chainListColumn
+}
+
+// String returns the entries as a table with aligned columns.
+func (t chainListTable) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+
+	headers := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		headers[i] = c.header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, e := range t.entries {
+		values := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			if values[i] = c.value(e); values[i] == "" {
+				values[i] = "-"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// chainStatusWorkers is the most endpoints probed concurrently by chains status.
+const chainStatusWorkers = 8
+
+// Kinds of endpoint probed by chains status.
+const (
+	endpointRPC  = "rpc"
+	endpointGRPC = "grpc"
+)
+
+func cmdChainsStatus(a *appState) *cobra.Command {
+	const timeoutFlag = "timeout"
+
+	cmd := &cobra.Command{
+		Use:   "status [chain-name...]",
+		Short: "Check whether the RPC and gRPC endpoints of configured chains are reachable",
+		Long: `Check whether the RPC and gRPC endpoints of the given chains, or of every configured chain, are reachable.
+
+RPC endpoints are asked for their node status, to report the latest block height,
+whether the node is catching up, and how far the latest block lags behind the local clock.
+gRPC endpoints are asked to list their services through reflection.
+Every endpoint is probed, including fallback endpoints.
+Endpoints that are not configured are skipped.
+
+If any endpoint is unreachable, the command exits with a non-zero status.`,
+		Args: cobra.ArbitraryArgs,
+		Example: fmt.Sprintf(`$ %s chains status
+$ %s chains status cosmoshub osmosis --timeout 2s`,
+			appName, appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timeout, err := cmd.Flags().GetDuration(timeoutFlag)
+			if err != nil {
+				return err
+			}
+
+			names := args
+			if len(names) == 0 {
+				for name := range a.Config.Chains {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+			}
+
+			var probes []endpointProbe
+			for _, name := range names {
+				chain, ok := a.Config.Chains[name]
+				if !ok {
+					return ChainNotFoundError{Requested: name, Config: a.Config}
+				}
+				if a.Proxy != "" {
+					c := *chain
+					c.Proxy, c.RPCProxy = a.Proxy, ""
+					chain = &c
+				}
+
+				for _, addr := range chain.RPCEndpoints() {
+					probes = append(probes, endpointProbe{chainName: name, chain: chain, kind: endpointRPC, addr: addr})
+				}
+				for _, addr := range chain.GRPCEndpoints() {
+					probes = append(probes, endpointProbe{chainName: name, chain: chain, kind: endpointGRPC, addr: addr})
+				}
+			}
+
+			statuses := probeEndpoints(cmd.Context(), a.Log, probes, timeout)
+			if err := writeOutput(cmd, a, statuses); err != nil {
+				return err
+			}
+
+			var unreachable int
+			for _, s := range statuses {
+				if !s.Reachable {
+					unreachable++
+				}
+			}
+			if unreachable > 0 {
+				return UnreachableEndpointsError{Endpoints: unreachable}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Duration(timeoutFlag, 5*time.Second, "how long to wait for each endpoint to respond")
+	return cmd
+}
+
+// endpointProbe identifies one endpoint to be probed by chains status.
+type endpointProbe struct {
+	chainName string
+	chain     *client.ChainClientConfig
+	kind      string
+	addr      string
+}
+
+// endpointStatus is the result of probing one endpoint of a chain.
+type endpointStatus struct {
+	Chain     string `json:"chain"`
+	Kind      string `json:"kind"`
+	Endpoint  string `json:"endpoint"`
+	Reachable bool   `json:"reachable"`
+
+	// Height, CatchingUp, and BlockLag are only set for reachable RPC endpoints.
+	Height     int64  `json:"height,omitempty"`
+	CatchingUp bool   `json:"catching_up,omitempty"`
+	BlockLag   string `json:"block_lag,omitempty"`
+
+	Latency string `json:"latency,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type endpointStatuses []endpointStatus
+
+var _ fmt.Stringer = endpointStatuses(nil)
+
+// String returns the statuses as a table with aligned columns.
+func (ss endpointStatuses) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tKIND\tENDPOINT\tREACHABLE\tHEIGHT\tLAG\tLATENCY\tERROR")
+	for _, s := range ss {
+		reachable, height, lag, latency, errMsg := "yes", "-", "-", "-", "-"
+		if !s.Reachable {
+			reachable = "no"
+		}
+		if s.Height > 0 {
+			height = strconv.FormatInt(s.Height, 10)
+			if s.CatchingUp {
+				height += " (catching up)"
+			}
+		}
+		if s.BlockLag != "" {
+			lag = s.BlockLag
+		}
+		if s.Latency != "" {
+			latency = s.Latency
+		}
+		if s.Error != "" {
+			errMsg = s.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Chain, s.Kind, s.Endpoint, reachable, height, lag, latency, errMsg)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// probeEndpoints probes every endpoint, at most chainStatusWorkers at a time,
+// and returns their statuses in the same order as probes.
+func probeEndpoints(ctx context.Context, log *zap.Logger, probes []endpointProbe, timeout time.Duration) endpointStatuses {
+	statuses := make(endpointStatuses, len(probes))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < chainStatusWorkers && i < len(probes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range indices {
+				statuses[j] = probeEndpoint(ctx, log, probes[j], timeout)
+			}
+		}()
+	}
+
+	for i := range probes {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return statuses
+}
+
+func probeEndpoint(ctx context.Context, log *zap.Logger, p endpointProbe, timeout time.Duration) endpointStatus {
+	s := endpointStatus{
+		Chain:    p.chainName,
+		Kind:     p.kind,
+		Endpoint: p.addr,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	switch p.kind {
+	case endpointRPC:
+		err = probeRPC(ctx, &s, p.chain.RPCProxySetting(), timeout)
+	case endpointGRPC:
+		err = probeGRPC(ctx, p.chain, p.addr)
+	}
+
+	if err != nil {
+		log.Debug(
+			"Endpoint unreachable",
+			zap.String("chain_name", p.chainName),
+			zap.String("endpoint", s.Endpoint),
+			zap.Error(err),
+		)
+		s.Error = err.Error()
+		return s
+	}
+
+	s.Reachable = true
+	s.Latency = time.Since(start).Round(time.Millisecond).String()
+	return s
+}
+
+// probeRPC fetches the node status from the RPC endpoint in s, through the proxy given by the proxy setting,
+// and records the latest block details in s.
+func probeRPC(ctx context.Context, s *endpointStatus, proxy string, timeout time.Duration) error {
+	rpcClient, err := client.NewProxiedRPCClient(s.Endpoint, timeout, proxy)
+	if err != nil {
+		return err
+	}
+
+	status, err := rpcClient.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.Height = status.SyncInfo.LatestBlockHeight
+	s.CatchingUp = status.SyncInfo.CatchingUp
+	s.BlockLag = time.Since(status.SyncInfo.LatestBlockTime).Round(time.Second).String()
+	return nil
+}
+
+// probeGRPC connects to the chain's gRPC endpoint at addr, using TLS and a proxy according to the chain's settings,
+// and lists its services through reflection.
+func probeGRPC(ctx context.Context, chain *client.ChainClientConfig, addr string) error {
+	creds := insecure.NewCredentials()
+	if chain.GRPCTLS {
+		cfg, err := newTLSConfig(chain.GRPCTLSCAFile, "", "", "")
+		if err != nil {
+			return err
+		}
+		creds = credentials.NewTLS(cfg)
+	}
+
+	tuning, err := chain.GRPCTuning()
+	if err != nil {
+		return err
+	}
+
+	target, proxyOpts, err := client.GRPCProxyDialOptions(addr, chain.Proxy)
+	if err != nil {
+		return err
+	}
+	dialOpts := append(proxyOpts, tuning.DialOptions()...)
+	conn, err := grpcdynamic.Dial(ctx, target, append(dialOpts, grpc.WithTransportCredentials(creds))...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = grpcdynamic.NewReflectionClient(conn).ListServices(ctx)
+	return err
+}
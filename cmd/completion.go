@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+// completionFunc is the signature of cobra's ValidArgsFunction and flag completion functions.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func completionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: fmt.Sprintf(`Generate a script completing %[1]s commands in the given shell.
+
+Besides commands and flags, arguments naming a chain complete to the configured chain names,
+key arguments complete to the chain's key names when it uses the test keyring backend,
+and the service, method, and message arguments of dynamic commands complete
+from the descriptors cached by earlier dynamic commands, without contacting the chain.
+
+To load completions in the current bash session:
+    source <(%[1]s completion bash)
+
+To load completions for every new zsh session,
+write the script to a directory in your $fpath:
+    %[1]s completion zsh > "${fpath[1]}/_%[1]s"
+
+To load completions for every new fish session:
+    %[1]s completion fish > ~/.config/fish/completions/%[1]s.fish`,
+			appName),
+		Args:                  withUsage(cobra.ExactValidArgs(1)),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(out)
+			case "fish":
+				return cmd.Root().GenFishCompletion(out, true)
+			default:
+				return cmd.Root().GenPowerShellCompletionWithDesc(out)
+			}
+		},
+	}
+}
+
+// registerFlagCompletions registers completion functions for the root command's persistent flags.
+func registerFlagCompletions(rootCmd *cobra.Command, a *appState) error {
+	fixed := func(values ...string) completionFunc {
+		return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	for flag, f := range map[string]completionFunc{
+		"chain": func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return filterCompletions(completionChainNames(a), toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		"output":           fixed("text", "json", "json-indent", "yaml"),
+		keyringBackendFlag: fixed(client.KeyringBackends...),
+		logLevelFlag:       fixed(logLevels...),
+		logFormatFlag:      fixed(logFormats...),
+	} {
+		if err := rootCmd.RegisterFlagCompletionFunc(flag, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completionConfig returns the configuration to complete arguments from, or nil if there is none.
+// Completion requests skip the root command's pre-run,
+// so the configuration file is read here, without being created or validated.
+func completionConfig(a *appState) *Config {
+	if a.Config != nil {
+		return a.Config
+	}
+
+	cfg, err := readConfig(configFilePath(a.HomePath))
+	if err != nil {
+		return nil
+	}
+	a.Config = cfg
+	return a.Config
+}
+
+// completionChainNames returns the sorted names of the configured chains.
+func completionChainNames(a *appState) []string {
+	cfg := completionConfig(a)
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Chains))
+	for name := range cfg.Chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeChainNames completes the first argument of a command to the configured chain names.
+func completeChainNames(a *appState) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterCompletions(completionChainNames(a), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeChainThenKey completes the arguments of commands taking [chain-name] [key-or-address],
+// as resolved by chainClientAndAddress.
+// The first argument may be either a chain name or a key of the default chain,
+// and the second is a key of the chain named by the first.
+func completeChainThenKey(a *appState) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var candidates []string
+		switch len(args) {
+		case 0:
+			candidates = completionChainNames(a)
+			if cfg := completionConfig(a); cfg != nil {
+				chainName := cfg.DefaultChain
+				if env := os.Getenv(chainEnvVar); env != "" {
+					chainName = env
+				}
+				if a.OverriddenChain != "" {
+					chainName = a.OverriddenChain
+				}
+				candidates = append(candidates, completionKeyNames(a, chainName)...)
+			}
+		case 1:
+			candidates = completionKeyNames(a, args[0])
+		}
+		return filterCompletions(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionKeyNames returns the sorted names of the keys of the named chain.
+// Only keyrings of the test backend are read,
+// since the other backends may prompt for a passphrase or unlock a system keychain.
+func completionKeyNames(a *appState, chainName string) []string {
+	cfg := completionConfig(a)
+	if cfg == nil {
+		return nil
+	}
+	chain, ok := cfg.Chains[chainName]
+	if !ok {
+		return nil
+	}
+
+	c := *chain
+	if a.KeyringBackend != "" {
+		c.KeyringBackend = a.KeyringBackend
+	}
+	if c.KeyringBackend != keyring.BackendTest {
+		return nil
+	}
+	c.Modules = append([]module.AppModuleBasic{}, ModuleBasics...)
+
+	cl, err := client.NewChainClient(a.Log, &c, a.HomePath, nil, io.Discard)
+	if err != nil {
+		return nil
+	}
+	records, err := cl.Keybase.List()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(records))
+	for _, r := range records {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dynamicArg is the kind of a positional argument of a dynamic command, following CHAIN_NAME_OR_GRPC_ADDR.
+type dynamicArg int
+
+const (
+	// dynArgChain is another chain name or gRPC address.
+	dynArgChain dynamicArg = iota
+	// dynArgService is a fully qualified service name.
+	dynArgService
+	// dynArgMethod is the name of a method of the service in the preceding argument.
+	dynArgMethod
+	// dynArgFullMethod is a fully qualified method name, such as cosmos.bank.v1beta1.Query.Balance.
+	dynArgFullMethod
+	// dynArgMessage is a fully qualified message name.
+	dynArgMessage
+)
+
+// completeDynamicArgs completes the arguments of a dynamic command.
+// The first argument is a chain name, and the arguments after it are of the kinds in rest.
+// Services, methods, and messages are completed from the descriptor cache of the chain,
+// so they are only offered once a dynamic command has contacted the chain,
+// and completing never makes a network request.
+func completeDynamicArgs(a *appState, rest ...dynamicArg) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return filterCompletions(completionChainNames(a), toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) > len(rest) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		kind := rest[len(args)-1]
+		if kind == dynArgChain {
+			return filterCompletions(completionChainNames(a), toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+
+		s := completionDescriptors(a, args[0])
+		if s == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var candidates []string
+		switch kind {
+		case dynArgService:
+			candidates, _ = s.ListServices()
+		case dynArgMethod:
+			if svcDesc, ok := s.services[args[len(args)-1]]; ok {
+				for _, m := range svcDesc.GetMethods() {
+					candidates = append(candidates, m.GetName())
+				}
+			}
+		case dynArgFullMethod:
+			services, _ := s.ListServices()
+			for _, svc := range services {
+				for _, m := range s.services[svc].GetMethods() {
+					candidates = append(candidates, m.GetFullyQualifiedName())
+				}
+			}
+		case dynArgMessage:
+			for _, fd := range s.files {
+				candidates = appendMessageNames(candidates, fd.GetMessageTypes())
+			}
+			sort.Strings(candidates)
+		}
+		return filterCompletions(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionDescriptors returns the cached descriptors of the chain or gRPC address addrOrChainName,
+// from the first of the chain's gRPC endpoints with a cache entry, or nil if none has one.
+func completionDescriptors(a *appState, addrOrChainName string) *cachedDescriptorSource {
+	addrs := []string{addrOrChainName}
+	if client.ValidateGRPCAddr(addrOrChainName) != nil {
+		cfg := completionConfig(a)
+		if cfg == nil {
+			return nil
+		}
+		chain, ok := cfg.Chains[addrOrChainName]
+		if !ok {
+			return nil
+		}
+		addrs = chain.GRPCEndpoints()
+	}
+
+	for _, addr := range addrs {
+		s := &cachedDescriptorSource{a: a, path: descriptorCachePath(a.HomePath, addr)}
+		if err := s.load(); err == nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// appendMessageNames appends the fully qualified names of msgDescs and their nested messages to names,
+// except for the entries of map fields.
+func appendMessageNames(names []string, msgDescs []*desc.MessageDescriptor) []string {
+	for _, msgDesc := range msgDescs {
+		if msgDesc.IsMapEntry() {
+			continue
+		}
+		names = append(names, msgDesc.GetFullyQualifiedName())
+		names = appendMessageNames(names, msgDesc.GetNestedMessageTypes())
+	}
+	return names
+}
+
+// filterCompletions returns the candidates starting with toComplete.
+func filterCompletions(candidates []string, toComplete string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
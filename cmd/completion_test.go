@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// completions runs cobra's hidden completion command for args, as the shell scripts do,
+// and returns the completion candidates.
+func completions(t *testing.T, sys *System, args ...string) []string {
+	t.Helper()
+
+	res := sys.MustRun(t, append([]string{"__complete"}, args...)...)
+
+	// Candidates are listed one per line, followed by a line with the directive, such as :4.
+	var candidates []string
+	for _, line := range strings.Split(strings.TrimSpace(res.Stdout.String()), "\n") {
+		if strings.HasPrefix(line, ":") {
+			break
+		}
+		candidates = append(candidates, line)
+	}
+	return candidates
+}
+
+func TestCompletion_ChainNames(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// Without a configuration, there is nothing to complete, and none is created.
+	require.Empty(t, completions(t, sys, "dynamic", "list-methods", ""))
+	_, err := os.Stat(filepath.Join(sys.HomeDir, "config.yaml"))
+	require.True(t, os.IsNotExist(err))
+
+	_ = sys.MustRun(t, "chains", "list")
+
+	require.Equal(t, []string{"cosmoshub", "osmosis"}, completions(t, sys, "dynamic", "list-methods", ""))
+	require.Equal(t, []string{"osmosis"}, completions(t, sys, "dyn", "inspect", "os"))
+	require.Equal(t, []string{"cosmoshub", "osmosis"}, completions(t, sys, "dynamic", "compare", "cosmoshub", ""))
+	require.Equal(t, []string{"cosmoshub", "osmosis"}, completions(t, sys, "chains", "show", ""))
+	require.Empty(t, completions(t, sys, "chains", "show", "cosmoshub", ""))
+
+	// Flags complete too.
+	require.Equal(t, []string{"cosmoshub"}, completions(t, sys, "query", "bank", "total-supply", "--chain", "c"))
+	require.Equal(t, []string{"test"}, completions(t, sys, "keys", "list", "--keyring-backend", "t"))
+}
+
+func TestCompletion_Keys(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	_ = sys.MustRun(t, "keys", "add", "alice")
+	_ = sys.MustRun(t, "keys", "add", "bob", "--chain", "osmosis")
+
+	// The first argument is either a chain or a key of the default chain.
+	require.Equal(t, []string{"cosmoshub", "osmosis", "alice"}, completions(t, sys, "query", "bank", "balances", ""))
+	require.Equal(t, []string{"cosmoshub", "osmosis", "bob"}, completions(t, sys, "query", "bank", "balances", "--chain", "osmosis", ""))
+
+	// The second argument is a key of the chain in the first.
+	require.Equal(t, []string{"bob"}, completions(t, sys, "query", "bank", "balances", "osmosis", ""))
+	require.Equal(t, []string{"alice"}, completions(t, sys, "q", "staking", "delegations", "cosmoshub", "a"))
+	require.Empty(t, completions(t, sys, "query", "bank", "balances", "nochain", ""))
+
+	// Keyrings that might prompt are not read.
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "keyring-backend", "file")
+	require.Empty(t, completions(t, sys, "query", "distribution", "rewards", "cosmoshub", ""))
+}
+
+func TestCompletion_CachedDescriptors(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "grpc-addr", gRPCAddr)
+
+	// Nothing is cached yet, and completing does not contact the server.
+	require.Empty(t, completions(t, sys, "dynamic", "list-methods", "osmosis", ""))
+
+	_ = sys.MustRun(t, "dynamic", "list-services", "osmosis")
+
+	require.Equal(t, []string{
+		"grpc.channelz.v1.Channelz",
+		"grpc.reflection.v1alpha.ServerReflection",
+	}, completions(t, sys, "dynamic", "list-methods", "osmosis", ""))
+	require.Equal(t, []string{
+		"grpc.channelz.v1.Channelz",
+	}, completions(t, sys, "dynamic", "inspect", gRPCAddr, "grpc.c"))
+	// Methods are in the order the service declares them.
+	require.Equal(t, []string{
+		"GetServers",
+		"GetServer",
+		"GetServerSockets",
+	}, completions(t, sys, "dynamic", "query", "osmosis", "grpc.channelz.v1.Channelz", "GetServer"))
+	require.Equal(t, []string{
+		"grpc.channelz.v1.Channelz.GetChannel",
+	}, completions(t, sys, "dynamic", "call", "osmosis", "grpc.channelz.v1.Channelz.GetCh"))
+	require.Equal(t, []string{
+		"grpc.channelz.v1.GetChannelRequest",
+		"grpc.channelz.v1.GetChannelResponse",
+	}, completions(t, sys, "dynamic", "schema", "osmosis", "grpc.channelz.v1.GetChannelR"))
+
+	// Only the arguments that name services, methods, or messages complete.
+	require.Empty(t, completions(t, sys, "dynamic", "list-methods", "osmosis", "grpc.channelz.v1.Channelz", ""))
+	require.Empty(t, completions(t, sys, "dynamic", "list-methods", "cosmoshub", ""))
+
+	// Clearing the cache leaves nothing to complete.
+	_ = sys.MustRun(t, "dynamic", "cache", "clear", "osmosis")
+	require.Empty(t, completions(t, sys, "dynamic", "list-methods", "osmosis", ""))
+}
+
+func TestCompletion_Scripts(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		res := sys.MustRun(t, "completion", shell)
+		require.Contains(t, res.Stdout.String(), "__complete", shell)
+	}
+
+	res := sys.Run(zaptest.NewLogger(t), "completion", "tcsh")
+	require.Error(t, res.Err)
+
+	require.Equal(t, []string{"bash", "zsh", "fish", "powershell"}, completions(t, sys, "completion", ""))
+}
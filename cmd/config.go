@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/types/module"
@@ -31,7 +34,7 @@ func createConfig(home string, debug bool) error {
 
 	// Then create the file...
 	content := defaultConfig(path.Join(home, "keys"), debug)
-	if err := os.WriteFile(cfgPath, content, 0600); err != nil {
+	if err := writeFileAtomic(cfgPath, content); err != nil {
 		return err
 	}
 
@@ -40,10 +43,49 @@ func createConfig(home string, debug bool) error {
 
 // Config represents the config file for the relayer
 type Config struct {
+	// Version is the version of the configuration file, which configMigrations upgrade from earlier versions.
+	Version int `yaml:"version" json:"version"`
+
 	DefaultChain string                               `yaml:"default_chain" json:"default_chain"`
 	Chains       map[string]*client.ChainClientConfig `yaml:"chains" json:"chains"`
 
+	// Contacts are the addresses of the contact book, by name, referenced as @name where an address is expected.
+	Contacts map[string]string `yaml:"contacts,omitempty" json:"contacts,omitempty"`
+
 	cl map[string]*client.ChainClient
+
+	// defaultOverridden is set when DefaultChain was overridden for one command, by --chain or LENS_CHAIN,
+	// in which case configuredDefaultChain is written in its place, so that the override is not saved.
+	defaultOverridden      bool
+	configuredDefaultChain string
+
+	// format is the format of the configuration file, in which the configuration is written back.
+	format configFormat
+
+	// unknownFields are the keys of the configuration file that are not fields of the configuration.
+	unknownFields []unknownConfigField
+
+	// envOverrides are the fields of the chain configurations overridden by environment variables,
+	// by chain name and field name.
+	envOverrides map[string]map[string]envOverride
+}
+
+// chainEnvVar is the environment variable overriding the configured default chain.
+const chainEnvVar = "LENS_CHAIN"
+
+// ConfiguredDefaultChain returns the default chain of the configuration file,
+// which differs from DefaultChain if that was overridden for the running command.
+func (c *Config) ConfiguredDefaultChain() string {
+	if c.defaultOverridden {
+		return c.configuredDefaultChain
+	}
+	return c.DefaultChain
+}
+
+// SetDefaultChain sets the default chain, both for the running command and in the configuration written from c.
+func (c *Config) SetDefaultChain(name string) {
+	c.DefaultChain = name
+	c.configuredDefaultChain = name
 }
 
 func (c *Config) GetDefaultClient() *client.ChainClient {
@@ -58,21 +100,299 @@ func (c *Config) GetClient(chainID string) *client.ChainClient {
 }
 
 // Called to initialize the relayer.Chain types on Config
-func validateConfig(c *Config) error {
-	for _, chain := range c.Chains {
-		if err := chain.Validate(); err != nil {
-			return err
-		}
+func validateConfig(log *zap.Logger, c *Config) error {
+	// Invalid chains are only reported, so that the other chains can still be used
+	// and the invalid ones can be fixed with chains edit.
+	for _, problem := range configProblems(c) {
+		log.Warn("Invalid configuration", zap.String("problem", problem))
 	}
-	if c.GetDefaultClient() == nil {
+	// Without a default chain, the commands whose chain argument is omitted fail with a NoDefaultChainError.
+	if c.DefaultChain != "" && c.GetDefaultClient() == nil {
 		return fmt.Errorf("default chain (%s) configuration not found", c.DefaultChain)
 	}
 	return nil
 }
 
+// configProblems returns a description of every problem with c, sorted by chain name.
+func configProblems(c *Config) []string {
+	var problems []string
+	for _, f := range c.unknownFields {
+		if f.Chain == "" {
+			problems = append(problems, f.String())
+		}
+	}
+	if _, ok := c.Chains[c.DefaultChain]; c.DefaultChain != "" && !ok {
+		problems = append(problems, fmt.Sprintf("default_chain: chain %q is not configured", c.DefaultChain))
+	}
+	for _, name := range sortedContactNames(c.Contacts) {
+		if err := client.ValidateContact(name, c.Contacts[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("contacts: %v", err))
+		}
+	}
+
+	names := make([]string, 0, len(c.Chains))
+	for name := range c.Chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, f := range c.unknownFields {
+			if f.Chain == name {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, f))
+			}
+		}
+
+		err := c.Chains[name].Validate()
+		if err == nil {
+			continue
+		}
+
+		var errs client.ConfigErrors
+		if !errors.As(err, &errs) {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, e := range errs {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, e))
+		}
+	}
+	return problems
+}
+
+func configCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config",
+		Aliases: []string{"cfg"},
+		Short:   "inspect the configuration file",
+
+		// Replace the root pre-run, which fails on some invalid configurations,
+		// so that the config subcommands can report on them.
+		// Their output, of the configuration rather than of a chain, is text unless --output is set.
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			a.outputSet = cmd.Flags().Changed("output")
+			if err := validateOutputFormat(a.OutputFormat); err != nil {
+				return err
+			}
+
+			home := a.Viper.GetString("home")
+			if err := a.lockConfigFor(cmd, home); err != nil {
+				return err
+			}
+			if err := loadConfigCmdConfig(a, home); err != nil {
+				a.unlockConfig()
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		cmdConfigShow(a),
+		cmdConfigValidate(a),
+		withConfigLock(a, cmdConfigSetDefaultChain(a)),
+		withConfigLock(a, cmdConfigConvert(a)),
+		withConfigLock(a, cmdConfigMigrate(a)),
+	)
+
+	return cmd
+}
+
+// loadConfigCmdConfig loads the configuration of home for the config subcommands,
+// without the validation and chain clients of initConfig.
+// An earlier version of the configuration is migrated in memory only, so that config migrate can show the changes.
+func loadConfigCmdConfig(a *appState, home string) error {
+	cfgPath := configFilePath(home)
+	if _, err := os.Stat(cfgPath); err != nil {
+		if err := createConfig(home, a.Viper.GetBool("debug")); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := readConfig(cfgPath)
+	if err != nil {
+		return err
+	}
+	a.Config = cfg
+	return applyEnvOverrides(a.Config)
+}
+
+func cmdConfigShow(a *appState) *cobra.Command {
+	const resolvedFlag = "resolved"
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "print the configuration",
+		Long: fmt.Sprintf(`Print the configuration file.
+
+Any field of a chain configuration is overridden by an environment variable
+named after its key, prefixed by %[1]s_, in upper case, with dots and dashes replaced by underscores:
+the grpc-addr of the chain cosmoshub is overridden by %[1]s_CHAINS_COSMOSHUB_GRPC_ADDR.
+Lists, such as rpc-addrs, are comma-separated, and grpc-headers are comma-separated key=value pairs.
+The overrides are applied when the configuration is loaded, and are never written to the configuration file.
+Flags, such as --keyring-backend, take precedence over the environment.
+
+With --%[2]s, the configuration in effect is printed instead, after the overrides of the environment
+and of flags, with every overridden key followed by a comment naming what overrides it,
+or, with --output json or yaml, listed under overrides.`, strings.ToUpper(appName), resolvedFlag),
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`$ %[1]s config show
+$ %[2]s_CHAINS_COSMOSHUB_GRPC_ADDR=localhost:9090 %[1]s config show --resolved`,
+			appName, strings.ToUpper(appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolved, err := cmd.Flags().GetBool(resolvedFlag)
+			if err != nil {
+				return err
+			}
+
+			if !resolved {
+				if a.OutputFormat == "" || a.OutputFormat == outputText {
+					out, err := a.Config.Marshal(a.Config.format)
+					if err != nil {
+						return err
+					}
+					_, err = cmd.OutOrStdout().Write(out)
+					return err
+				}
+				return writeOutput(cmd, a, a.Config.fileConfig())
+			}
+
+			r, err := resolveConfig(a)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, r)
+		},
+	}
+
+	cmd.Flags().Bool(resolvedFlag, false, "print the configuration in effect, after the overrides of the environment and flags")
+	return cmd
+}
+
+func cmdConfigValidate(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "check every chain configuration for invalid values",
+		Long: `Check the configuration file for invalid values, such as malformed addresses,
+gas prices, and timeouts, and print every problem found.
+
+If any problem is found, the command exits with a non-zero status.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems := configProblems(a.Config)
+			if len(problems) == 0 {
+				return nil
+			}
+			if err := writeOutput(cmd, a, problems); err != nil {
+				return err
+			}
+			return InvalidConfigError{Problems: len(problems)}
+		},
+	}
+	return cmd
+}
+
+func cmdConfigSetDefaultChain(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-default-chain [chain-name]",
+		Short: "set the chain used by commands whose chain argument is omitted",
+		Long: fmt.Sprintf(`Set the default chain in the configuration file.
+
+Commands that take an optional chain argument, such as the query, tx, keys, and dynamic commands,
+use the default chain when the argument is omitted.
+For one command, --chain overrides the default chain, and so does the %s environment variable,
+with a lower precedence than --chain.`, chainEnvVar),
+		Args:              cobra.ExactArgs(1),
+		Example:           fmt.Sprintf(`$ %s config set-default-chain osmosis`, appName),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := a.Config.Chains[args[0]]; !ok {
+				return ChainNotFoundError{Requested: args[0], Config: a.Config}
+			}
+			a.Config.SetDefaultChain(args[0])
+			return a.OverwriteConfig(a.Config)
+		},
+	}
+	return cmd
+}
+
+func cmdConfigConvert(a *appState) *cobra.Command {
+	const toFlag = "to"
+
+	formats := make([]string, len(configFormats))
+	for i, f := range configFormats {
+		formats[i] = string(f)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "rewrite the configuration file in another format",
+		Long: fmt.Sprintf(`Rewrite the configuration file in the format given by --%[1]s, among: %[2]s.
+
+The configuration file of the home directory is the first found of %[3]s;
+the format of a file without an extension is detected from its content.
+The converted file is written with the extension of its format, and the original file is renamed to its backup, with a .bak suffix.
+Commands that write the configuration, such as chains add and chains edit, keep its format.`,
+			toFlag, strings.Join(formats, ", "), strings.Join(configFileNames, ", ")),
+		Args:    cobra.NoArgs,
+		Example: fmt.Sprintf(`$ %s config convert --to toml`, appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, err := cmd.Flags().GetString(toFlag)
+			if err != nil {
+				return err
+			}
+			format := configFormat(strings.ToLower(to))
+			if format == "yml" {
+				format = configYAML
+			}
+			out, err := a.Config.Marshal(format)
+			if err != nil {
+				return err
+			}
+
+			home := a.Viper.GetString("home")
+			oldPath := configFilePath(home)
+			newPath := path.Join(home, "config."+string(format))
+			if err := writeConfigFile(newPath, out); err != nil {
+				return err
+			}
+			// The original file is kept as its backup.
+			if oldPath != newPath {
+				if err := os.Rename(oldPath, configBackupPath(oldPath)); err != nil {
+					return err
+				}
+			}
+
+			a.Config.format = format
+			a.Log.Info("Converted lens configuration", zap.String("path", newPath), zap.String("format", string(format)))
+			return nil
+		},
+	}
+
+	cmd.Flags().String(toFlag, "", fmt.Sprintf("format of the configuration file (%s)", strings.Join(formats, ", ")))
+	if err := cmd.MarkFlagRequired(toFlag); err != nil {
+		panic(err)
+	}
+	if err := cmd.RegisterFlagCompletionFunc(toFlag, func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(formats, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// fileConfig returns the configuration as written to the configuration file,
+// without the overrides of the running command.
+func (c Config) fileConfig() Config {
+	c.Version = configVersion
+	c.DefaultChain = c.ConfiguredDefaultChain()
+	c.Chains = c.fileChains()
+	return c
+}
+
 // MustYAML returns the yaml string representation of the Paths
 func (c Config) MustYAML() []byte {
-	out, err := yaml.Marshal(c)
+	out, err := yaml.Marshal(c.fileConfig())
 	if err != nil {
 		panic(err)
 	}
@@ -102,7 +422,7 @@ func initConfig(cmd *cobra.Command, a *appState, o map[string]ClientOverrides) e
 		return err
 	}
 
-	cfgPath := path.Join(home, "config.yaml")
+	cfgPath := configFilePath(home)
 	_, err = os.Stat(cfgPath)
 	if err != nil {
 		err = createConfig(home, debug)
@@ -110,38 +430,71 @@ func initConfig(cmd *cobra.Command, a *appState, o map[string]ClientOverrides) e
 			return err
 		}
 	}
-	a.Viper.SetConfigFile(cfgPath)
-	err = a.Viper.ReadInConfig()
+
+	// read the config file into the struct, in whichever format it is, migrating it if needed
+	a.Config, err = loadConfig(a, cfgPath)
 	if err != nil {
-		return fmt.Errorf("failed to read in config: %w", err)
+		return err
 	}
 
-	// read the config file bytes
-	file, err := os.ReadFile(a.Viper.ConfigFileUsed())
+	a.Viper.SetConfigFile(cfgPath)
+	a.Viper.SetConfigType(string(a.Config.format))
+	err = a.Viper.ReadInConfig()
 	if err != nil {
-		return fmt.Errorf("error reading config file: %w", err)
+		return fmt.Errorf("failed to read in config: %w", err)
 	}
 
-	// unmarshall them into the struct
-	if err = yaml.Unmarshal(file, &a.Config); err != nil {
-		return fmt.Errorf("error unmarshalling config: %w", err)
+	// The environment overrides the file, and flags override both.
+	if err := applyEnvOverrides(a.Config); err != nil {
+		return err
 	}
 
 	// instantiate chain client
 	// TODO: this is a bit of a hack, we should probably have a
 	// better way to inject modules into the client
 	a.Config.cl = make(map[string]*client.ChainClient)
+	if a.KeyringBackend != "" {
+		if err := client.ValidateKeyringBackend(a.KeyringBackend); err != nil {
+			return err
+		}
+	}
+	if err := client.ValidateProxy(a.Proxy); err != nil {
+		return fmt.Errorf("invalid --%s %q: %w", proxyFlag, a.Proxy, err)
+	}
+	if _, err := client.ParseRateLimit(a.RateLimit); err != nil {
+		return fmt.Errorf("invalid --%s %q: %w", rateLimitFlag, a.RateLimit, err)
+	}
+	input, err := keyringInput(cmd, a)
+	if err != nil {
+		return err
+	}
+	activeChain, source := resolveActiveChain(a)
 	for name, chain := range a.Config.Chains {
 		chain.Modules = append([]module.AppModuleBasic{}, ModuleBasics...)
-		cl, err := client.NewChainClient(
+
+		clientConfig, _, err := chainClientConfig(a, chain, name == activeChain)
+		if err != nil {
+			return err
+		}
+
+		cl, err := client.NewChainClientWithOptions(
 			a.Log.With(zap.String("chain", name)),
-			chain,
+			clientConfig,
 			home,
-			cmd.InOrStdin(),
+			input,
 			cmd.OutOrStdout(),
+			client.WithMetrics(a.Metrics),
+			client.WithContacts(a.Config.Contacts),
+			client.WithTracer(a.Tracer),
 		)
 		if err != nil {
-			return fmt.Errorf("error creating chain client: %w", err)
+			// The chain may be misconfigured, which validateConfig reports.
+			a.Log.Warn(
+				"Failed to create chain client",
+				zap.String("chain", name),
+				zap.Error(err),
+			)
+			continue
 		}
 		// If overrides are present (should only happen in test), modify the client to use those overrides.
 		if o != nil {
@@ -156,14 +509,12 @@ func initConfig(cmd *cobra.Command, a *appState, o map[string]ClientOverrides) e
 	}
 
 	// override chain if needed
-	if cmd.PersistentFlags().Changed("chain") {
-		defaultChain, err := cmd.PersistentFlags().GetString("chain")
-		if err != nil {
-			return err
-		}
-
-		a.Config.DefaultChain = defaultChain
+	if activeChain != a.Config.DefaultChain {
+		a.Config.configuredDefaultChain = a.Config.DefaultChain
+		a.Config.defaultOverridden = true
+		a.Config.DefaultChain = activeChain
 	}
+	a.Log.Debug("Using default chain", zap.String("chain", activeChain), zap.String("source", source))
 
 	if cmd.PersistentFlags().Changed("output") {
 		output, err := cmd.PersistentFlags().GetString("output")
@@ -178,8 +529,72 @@ func initConfig(cmd *cobra.Command, a *appState, o map[string]ClientOverrides) e
 	}
 
 	// validate configuration
-	if err := validateConfig(a.Config); err != nil {
+	if err := validateConfig(a.Log, a.Config); err != nil {
 		return fmt.Errorf("error validating config: %w", err)
 	}
 	return nil
 }
+
+// resolveActiveChain returns the default chain of the running command, set by --chain, or else by LENS_CHAIN,
+// or else by the configuration, and which of them set it.
+func resolveActiveChain(a *appState) (chain, source string) {
+	chain, source = a.Config.ConfiguredDefaultChain(), "config"
+	if env := os.Getenv(chainEnvVar); env != "" {
+		chain, source = env, chainEnvVar
+	}
+	if a.OverriddenChain != "" {
+		chain, source = a.OverriddenChain, "--chain"
+	}
+	return chain, source
+}
+
+// chainClientConfig returns the configuration of the client of chain, with the flags of the running command applied,
+// and the fields they override, mapped to the flag overriding them.
+// active is set for the default chain of the running command, to which --endpoint applies.
+func chainClientConfig(a *appState, chain *client.ChainClientConfig, active bool) (*client.ChainClientConfig, map[string]string, error) {
+	overrides := make(map[string]string)
+
+	// The client of the chain in use gets its own copy of the configuration restricted to --endpoint,
+	// so that the restriction is not saved if the configuration is overwritten.
+	c := chain
+	if active && a.Endpoint != "" {
+		var err error
+		if c, err = chain.WithEndpoint(a.Endpoint); err != nil {
+			return nil, nil, err
+		}
+		for _, field := range []string{"rpc-addr", "rpc-addrs", "grpc-addr", "grpc-addrs"} {
+			overrides[field] = "--endpoint"
+		}
+	}
+	// Likewise for --keyring-backend and --proxy, which apply to every chain.
+	if a.KeyringBackend != "" {
+		cc := *c
+		cc.KeyringBackend = a.KeyringBackend
+		c = &cc
+		overrides["keyring-backend"] = "--" + keyringBackendFlag
+	}
+	if a.Proxy != "" {
+		cc := *c
+		cc.Proxy, cc.RPCProxy = a.Proxy, ""
+		c = &cc
+		overrides["proxy"] = "--" + proxyFlag
+		overrides["rpc-proxy"] = "--" + proxyFlag
+	}
+	if a.RateLimit != "" {
+		cc := *c
+		cc.RateLimit = a.RateLimit
+		c = &cc
+		overrides["rate-limit"] = "--" + rateLimitFlag
+	}
+	if a.Retries != nil {
+		cc := *c
+		cc.Retries = *a.Retries
+		if cc.Retries == 0 {
+			// A chain's retries of zero are the default, and negative ones none.
+			cc.Retries = -1
+		}
+		c = &cc
+		overrides["retries"] = "--" + retriesFlag
+	}
+	return c, overrides, nil
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/strangelove-ventures/lens/client"
+	"gopkg.in/yaml.v2"
+)
+
+// configEnvKeyReplacer maps configuration keys, such as chains.cosmoshub.grpc-addr,
+// to the environment variables overriding them, such as LENS_CHAINS_COSMOSHUB_GRPC_ADDR.
+var configEnvKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// configEnvVar returns the environment variable overriding the configuration key:
+// the key prefixed by LENS_, in upper case, with dots and dashes replaced by underscores.
+func configEnvVar(key string) string {
+	return strings.ToUpper(configEnvKeyReplacer.Replace(appName + "_" + key))
+}
+
+// chainConfigKey returns the configuration key of the field of the named chain.
+func chainConfigKey(chainName, field string) string {
+	return "chains." + chainName + "." + field
+}
+
+// newConfigEnv returns a viper instance reading the configuration keys from their environment variables only.
+func newConfigEnv() *viper.Viper {
+	v := viper.New()
+	v.SetEnvPrefix(appName)
+	v.SetEnvKeyReplacer(configEnvKeyReplacer)
+	v.AutomaticEnv()
+	return v
+}
+
+// chainConfigField is a field of client.ChainClientConfig that environment variables may override.
+type chainConfigField struct {
+	// name is the key of the field in a chain configuration, such as grpc-addr.
+	name  string
+	index int
+}
+
+// chainConfigFields returns the fields of client.ChainClientConfig stored in the configuration file.
+func chainConfigFields() []chainConfigField {
+	t := reflect.TypeOf(client.ChainClientConfig{})
+	fields := make([]chainConfigField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, chainConfigField{name: name, index: i})
+	}
+	return fields
+}
+
+// envOverride is a field of a chain configuration overridden by an environment variable.
+type envOverride struct {
+	envVar string
+	index  int
+
+	// fileValue is the value of the field in the configuration file, and value the value of the environment variable.
+	fileValue interface{}
+	value     interface{}
+}
+
+// applyEnvOverrides sets the fields of the chain configurations of c
+// that are overridden by environment variables, as named by configEnvVar.
+// The values of the configuration file are kept, so that MustYAML writes them instead.
+func applyEnvOverrides(c *Config) error {
+	env := newConfigEnv()
+	fields := chainConfigFields()
+
+	c.envOverrides = make(map[string]map[string]envOverride)
+	for name, chain := range c.Chains {
+		if chain == nil {
+			continue
+		}
+		v := reflect.ValueOf(chain).Elem()
+		for _, f := range fields {
+			key := chainConfigKey(name, f.name)
+			if !env.IsSet(key) {
+				continue
+			}
+
+			fv := v.Field(f.index)
+			fileValue := fv.Interface()
+			if err := setConfigField(fv, env.GetString(key)); err != nil {
+				return fmt.Errorf("invalid %s: %w", configEnvVar(key), err)
+			}
+
+			if c.envOverrides[name] == nil {
+				c.envOverrides[name] = make(map[string]envOverride)
+			}
+			c.envOverrides[name][f.name] = envOverride{
+				envVar:    configEnvVar(key),
+				index:     f.index,
+				fileValue: fileValue,
+				value:     fv.Interface(),
+			}
+		}
+	}
+	return nil
+}
+
+// setConfigField sets the configuration field fv to s, parsed as the type of the field.
+// Lists are comma-separated, and maps are comma-separated key=value pairs, as given to chains edit.
+func setConfigField(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(n))
+	case reflect.Slice:
+		fv.Set(reflect.ValueOf(splitList(s)))
+	case reflect.Map:
+		headers, err := client.ParseGRPCHeaders(splitList(s))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(headers))
+	default:
+		return fmt.Errorf("cannot set a field of type %s", fv.Type())
+	}
+	return nil
+}
+
+// fileChains returns the chain configurations as written to the configuration file:
+// the fields overridden by environment variables hold their value from the file,
+// unless they were changed since, such as by chains edit.
+func (c Config) fileChains() map[string]*client.ChainClientConfig {
+	if len(c.envOverrides) == 0 {
+		return c.Chains
+	}
+
+	chains := make(map[string]*client.ChainClientConfig, len(c.Chains))
+	for name, chain := range c.Chains {
+		overrides := c.envOverrides[name]
+		if chain == nil || len(overrides) == 0 {
+			chains[name] = chain
+			continue
+		}
+
+		fileChain := *chain
+		v := reflect.ValueOf(&fileChain).Elem()
+		for _, o := range overrides {
+			if fv := v.Field(o.index); reflect.DeepEqual(fv.Interface(), o.value) {
+				fv.Set(reflect.ValueOf(o.fileValue))
+			}
+		}
+		chains[name] = &fileChain
+	}
+	return chains
+}
+
+// resolvedConfig is the configuration in effect for a command, as written by config show --resolved.
+type resolvedConfig struct {
+	DefaultChain string                               `json:"default_chain"`
+	Chains       map[string]*client.ChainClientConfig `json:"chains"`
+	Contacts     map[string]string                    `json:"contacts,omitempty"`
+
+	// Overrides maps the overridden configuration keys, such as chains.cosmoshub.grpc-addr,
+	// to the environment variable or flag overriding them.
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// resolveConfig returns the configuration in effect for the running command:
+// a.Config, whose environment variable overrides are applied when it is loaded,
+// with the default chain and the chain configurations overridden by flags as they are when creating the chain clients.
+func resolveConfig(a *appState) (resolvedConfig, error) {
+	r := resolvedConfig{
+		Chains:    make(map[string]*client.ChainClientConfig, len(a.Config.Chains)),
+		Contacts:  a.Config.Contacts,
+		Overrides: make(map[string]string),
+	}
+
+	var source string
+	r.DefaultChain, source = resolveActiveChain(a)
+	if r.DefaultChain != a.Config.ConfiguredDefaultChain() {
+		r.Overrides["default_chain"] = source
+	}
+
+	for name, chain := range a.Config.Chains {
+		if chain == nil {
+			continue
+		}
+		for field, o := range a.Config.envOverrides[name] {
+			r.Overrides[chainConfigKey(name, field)] = o.envVar
+		}
+
+		c, flagOverrides, err := chainClientConfig(a, chain, name == r.DefaultChain)
+		if err != nil {
+			return resolvedConfig{}, err
+		}
+		for field, flag := range flagOverrides {
+			r.Overrides[chainConfigKey(name, field)] = flag
+		}
+
+		// --output sets the output format of every chain, as initConfig does.
+		resolved := *c
+		if a.outputSet {
+			resolved.OutputFormat = a.OutputFormat
+			r.Overrides[chainConfigKey(name, "output-format")] = "--output"
+		}
+		r.Chains[name] = &resolved
+	}
+	return r, nil
+}
+
+// String returns the configuration as YAML, with the overridden keys followed by a comment naming what overrides them.
+func (r resolvedConfig) String() string {
+	out, err := yaml.Marshal(Config{Version: configVersion, DefaultChain: r.DefaultChain, Chains: r.Chains, Contacts: r.Contacts})
+	if err != nil {
+		panic(err)
+	}
+
+	// The configuration is written with two spaces of indentation per level:
+	// the chain names are at the second level, and their fields at the third.
+	var b strings.Builder
+	var chainName string
+	for _, line := range strings.SplitAfter(string(out), "\n") {
+		trimmed := strings.TrimSpace(line)
+		key, _, _ := strings.Cut(trimmed, ":")
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		var source string
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "- "):
+		case indent == 0:
+			source = r.Overrides[key]
+		case indent == 2:
+			chainName = strings.Trim(key, `"'`)
+		case indent == 4:
+			source = r.Overrides[chainConfigKey(chainName, key)]
+		}
+
+		if source == "" {
+			b.WriteString(line)
+			continue
+		}
+		fmt.Fprintf(&b, "%s  # %s\n", strings.TrimSuffix(line, "\n"), source)
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// configLockAnnotation marks the commands that modify the configuration,
+// which hold the configuration lock from loading the configuration until they return.
+const configLockAnnotation = "lens-config-lock"
+
+// configLockFile is the name of the file in the home directory locked by commands modifying the configuration.
+// A separate file is locked, as the configuration file is replaced, rather than changed, when written.
+const configLockFile = "config.lock"
+
+// withConfigLock makes cmd hold the configuration lock while it runs,
+// so that concurrent commands modifying the configuration are serialized instead of losing each other's updates.
+// The lock is taken by the pre-run loading the configuration, and released when cmd returns.
+func withConfigLock(a *appState, cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[configLockAnnotation] = "true"
+
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		defer a.unlockConfig()
+		return runE(cmd, args)
+	}
+	return cmd
+}
+
+// lockConfigFor takes the configuration lock of home if cmd modifies the configuration.
+func (a *appState) lockConfigFor(cmd *cobra.Command, home string) error {
+	if _, ok := cmd.Annotations[configLockAnnotation]; !ok {
+		return nil
+	}
+	return a.lockConfig(home)
+}
+
+// lockConfig takes the configuration lock of home, waiting until no other process holds it.
+func (a *appState) lockConfig(home string) error {
+	if err := os.MkdirAll(home, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(home, configLockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open configuration lock: %w", err)
+	}
+	a.Log.Debug("Locking configuration", zap.String("path", f.Name()))
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to lock configuration: %w", err)
+	}
+	a.configLock = f
+	return nil
+}
+
+// unlockConfig releases the configuration lock, if it is held.
+func (a *appState) unlockConfig() {
+	if a.configLock == nil {
+		return
+	}
+	if err := unlockFile(a.configLock); err != nil {
+		a.Log.Warn("Failed to unlock configuration", zap.Error(err))
+	}
+	a.configLock.Close()
+	a.configLock = nil
+}
+
+// configBackupPath returns the path of the backup of the previous version of the configuration file at cfgPath.
+func configBackupPath(cfgPath string) string {
+	return cfgPath + ".bak"
+}
+
+// writeConfigFile replaces the configuration file at cfgPath with data,
+// after keeping its previous version as its backup.
+func writeConfigFile(cfgPath string, data []byte) error {
+	prev, err := os.ReadFile(cfgPath)
+	switch {
+	case err == nil:
+		if err := writeFileAtomic(configBackupPath(cfgPath), prev); err != nil {
+			return fmt.Errorf("failed to back up configuration: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	return writeFileAtomic(cfgPath, data)
+}
+
+// writeFileAtomic replaces the file at path with data, so that it holds either its previous content or data,
+// even if the process crashes or the system fails while writing:
+// data is written to a temporary file in the same directory, synced, and renamed over the file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Once renamed, the temporary file no longer exists, and this fails harmlessly.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Sync the directory too, so that the rename survives a system failure.
+	// Not every platform can sync directories, so this is best effort.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
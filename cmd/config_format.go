@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// configFormat is the format of a configuration file.
+type configFormat string
+
+// Formats of the configuration file.
+const (
+	configYAML configFormat = "yaml"
+	configJSON configFormat = "json"
+	configTOML configFormat = "toml"
+)
+
+// configFormats are the formats of the configuration file, as accepted by config convert --to.
+var configFormats = []configFormat{configYAML, configJSON, configTOML}
+
+// configFileNames are the names of the configuration file in the home directory, in the order they are looked for.
+// The format of a file without an extension is detected from its content.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json", "config.toml", "config"}
+
+// configFilePath returns the path of the configuration file in home:
+// the first of configFileNames that exists, or else config.yaml, which createConfig creates.
+func configFilePath(home string) string {
+	for _, name := range configFileNames {
+		p := path.Join(home, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return path.Join(home, configFileNames[0])
+}
+
+// configFormatOf returns the format of the configuration file at cfgPath with the given content:
+// the format of its extension, or else the format detected from the content.
+func configFormatOf(cfgPath string, content []byte) (configFormat, error) {
+	switch ext := strings.ToLower(filepath.Ext(cfgPath)); ext {
+	case ".yaml", ".yml":
+		return configYAML, nil
+	case ".json":
+		return configJSON, nil
+	case ".toml":
+		return configTOML, nil
+	case "":
+		return sniffConfigFormat(content), nil
+	default:
+		return "", fmt.Errorf("unsupported configuration file extension %q (must be .yaml, .yml, .json, or .toml)", ext)
+	}
+}
+
+// sniffConfigFormat returns the format of the configuration file content b.
+// JSON is an object, and YAML, unlike TOML, maps keys with a colon, so a document parsing as TOML is TOML.
+func sniffConfigFormat(b []byte) configFormat {
+	trimmed := bytes.TrimSpace(b)
+	switch {
+	case len(trimmed) == 0:
+		return configYAML
+	case trimmed[0] == '{':
+		return configJSON
+	}
+
+	var m map[string]interface{}
+	if err := toml.Unmarshal(trimmed, &m); err == nil {
+		return configTOML
+	}
+	return configYAML
+}
+
+// readConfig reads the configuration file at cfgPath, in its format, which is kept to write the configuration back.
+// A file of an earlier version is migrated in memory, but not written back.
+func readConfig(cfgPath string) (*Config, error) {
+	raw, err := parseConfigFile(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrateConfig(raw); err != nil {
+		return nil, err
+	}
+	return raw.decode()
+}
+
+// rawConfig is a configuration file parsed as JSON values,
+// on which the migrations operate before it is decoded into a Config.
+type rawConfig struct {
+	path   string
+	format configFormat
+	data   []byte
+	values map[string]interface{}
+}
+
+// parseConfigFile reads the configuration file at cfgPath, in its format, as JSON values.
+func parseConfigFile(cfgPath string) (*rawConfig, error) {
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	format, err := configFormatOf(cfgPath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var j []byte
+	switch format {
+	case configJSON:
+		j = data
+	case configTOML:
+		var m map[string]interface{}
+		if err = toml.Unmarshal(data, &m); err == nil {
+			j, err = json.Marshal(m)
+		}
+	default:
+		j, err = sigsyaml.YAMLToJSON(data)
+	}
+
+	var values map[string]interface{}
+	if err == nil {
+		dec := json.NewDecoder(bytes.NewReader(j))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	}
+	if err != nil {
+		backup := configBackupPath(cfgPath)
+		if _, statErr := os.Stat(backup); statErr != nil {
+			backup = ""
+		}
+		return nil, CorruptConfigError{Path: cfgPath, Backup: backup, Err: err}
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	return &rawConfig{path: cfgPath, format: format, data: data, values: values}, nil
+}
+
+// decode returns the configuration of the values of r, recording the fields it does not know.
+func (r *rawConfig) decode() (*Config, error) {
+	j, err := json.Marshal(r.values)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(j, &c); err != nil {
+		backup := configBackupPath(r.path)
+		if _, statErr := os.Stat(backup); statErr != nil {
+			backup = ""
+		}
+		return nil, CorruptConfigError{Path: r.path, Backup: backup, Err: err}
+	}
+	c.format = r.format
+	c.unknownFields = unknownConfigFields(r.values)
+	return &c, nil
+}
+
+// Marshal returns the configuration as written to the configuration file, in the given format.
+func (c Config) Marshal(format configFormat) ([]byte, error) {
+	switch format {
+	case configYAML, "":
+		return c.MustYAML(), nil
+	case configJSON:
+		b, err := json.MarshalIndent(c.fileConfig(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case configTOML:
+		return marshalTOML(c.fileConfig())
+	default:
+		return nil, fmt.Errorf("unknown configuration format %q (must be yaml, json, or toml)", format)
+	}
+}
+
+// marshalTOML returns v as TOML, with the keys of its JSON serialization,
+// since the configuration types only declare JSON and YAML keys.
+func marshalTOML(v interface{}) ([]byte, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	var m interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return toml.Marshal(tomlValue(m))
+}
+
+// tomlValue returns the JSON value v as a value that TOML can represent:
+// TOML has no null, so null values are left out of objects,
+// and it distinguishes integers from floats, which JSON numbers do not.
+func tomlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if e == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = tomlValue(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = tomlValue(e)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// unmarshalTOML parses the TOML document b into v, by the keys of the JSON serialization of v, as marshalTOML writes them.
+func unmarshalTOML(b []byte, v interface{}) error {
+	var m map[string]interface{}
+	if err := toml.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	j, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// configMigration upgrades the configuration file from one version to the next.
+type configMigration struct {
+	Description string
+
+	// Renamed maps the chain configuration keys that the migration renames to their new names,
+	// so that unknown keys of files written for earlier versions can be explained.
+	Renamed map[string]string
+
+	// Migrate transforms the values of the configuration file in place.
+	Migrate func(values map[string]interface{}) error
+}
+
+// configMigrations are the migrations of the configuration file, in order:
+// the migration at index N upgrades a file from version N to N+1.
+// Files written before versioning, without a version key, are at version 0.
+//
+// Migrations operate on the file as JSON values, before it is decoded,
+// so that they can read keys that the current types no longer declare.
+var configMigrations = []configMigration{
+	{
+		Description: "rename the output-format indent to json-indent",
+		Migrate: func(values map[string]interface{}) error {
+			for _, chain := range rawChains(values) {
+				if chain["output-format"] == outputIndent {
+					chain["output-format"] = outputJSONIndent
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// configVersion is the version of the configuration files written by this version of lens.
+var configVersion = len(configMigrations)
+
+// rawChains returns the chain configurations of the configuration file values, by name.
+func rawChains(values map[string]interface{}) map[string]map[string]interface{} {
+	chains := make(map[string]map[string]interface{})
+	m, _ := values["chains"].(map[string]interface{})
+	for name, v := range m {
+		if chain, ok := v.(map[string]interface{}); ok {
+			chains[name] = chain
+		}
+	}
+	return chains
+}
+
+// version returns the version of the configuration file.
+func (r *rawConfig) version() (int, error) {
+	v, ok := r.values["version"]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid configuration version %v in %s: must be an integer", v, r.path)
+	}
+	version, err := n.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid configuration version %v in %s: must be a non-negative integer", v, r.path)
+	}
+	return int(version), nil
+}
+
+// migrateConfig migrates the values of r to the current version, and returns the version they were at.
+func migrateConfig(r *rawConfig) (int, error) {
+	from, err := r.version()
+	if err != nil {
+		return 0, err
+	}
+	if from > configVersion {
+		return 0, fmt.Errorf(
+			"configuration file %s is at version %d, newer than the latest version this %s supports (%d); upgrade %s",
+			r.path, from, appName, configVersion, appName,
+		)
+	}
+
+	for v := from; v < configVersion; v++ {
+		if err := configMigrations[v].Migrate(r.values); err != nil {
+			return 0, fmt.Errorf("failed to migrate configuration from version %d to %d (%s): %w",
+				v, v+1, configMigrations[v].Description, err)
+		}
+	}
+	r.values["version"] = json.Number(fmt.Sprint(configVersion))
+	return from, nil
+}
+
+// loadConfig reads the configuration file at cfgPath, as readConfig does,
+// and writes it back, keeping a backup, if it was migrated from an earlier version.
+// The file is read again and written under the configuration lock,
+// unless the running command already holds it.
+func loadConfig(a *appState, cfgPath string) (*Config, error) {
+	raw, err := parseConfigFile(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	v, err := raw.version()
+	if err != nil {
+		return nil, err
+	}
+	if v == configVersion {
+		return raw.decode()
+	}
+
+	if a.configLock == nil {
+		if err := a.lockConfig(filepath.Dir(cfgPath)); err != nil {
+			return nil, err
+		}
+		defer a.unlockConfig()
+
+		// Another command may have migrated the file in the meantime.
+		if raw, err = parseConfigFile(cfgPath); err != nil {
+			return nil, err
+		}
+	}
+
+	from, err := migrateConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := raw.decode()
+	if err != nil {
+		return nil, err
+	}
+	if from == configVersion {
+		return cfg, nil
+	}
+	if err := writeMigratedConfig(a, cfgPath, cfg, from); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// writeMigratedConfig writes cfg, migrated from version from, to the configuration file at cfgPath.
+func writeMigratedConfig(a *appState, cfgPath string, cfg *Config, from int) error {
+	out, err := cfg.Marshal(cfg.format)
+	if err != nil {
+		return err
+	}
+	if err := writeConfigFile(cfgPath, out); err != nil {
+		return fmt.Errorf("failed to write migrated configuration: %w", err)
+	}
+	a.Log.Info(
+		"Migrated lens configuration",
+		zap.String("path", cfgPath),
+		zap.Int("from_version", from),
+		zap.Int("to_version", configVersion),
+		zap.String("backup", configBackupPath(cfgPath)),
+	)
+	return nil
+}
+
+func cmdConfigMigrate(a *appState) *cobra.Command {
+	const dryRunFlag = "dry-run"
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "upgrade the configuration file to the current version",
+		Long: fmt.Sprintf(`Upgrade the configuration file to the current version (%d), keeping its previous version as its backup.
+
+Commands loading an earlier version of the configuration migrate it and write it back automatically;
+this command does so explicitly, and with --%s, prints the changes as a unified diff without writing them.`,
+			configVersion, dryRunFlag),
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`$ %[1]s config migrate --dry-run
+$ %[1]s config migrate`, appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+			if err != nil {
+				return err
+			}
+
+			cfgPath := configFilePath(a.Viper.GetString("home"))
+			raw, err := parseConfigFile(cfgPath)
+			if err != nil {
+				return err
+			}
+			from, err := migrateConfig(raw)
+			if err != nil {
+				return err
+			}
+			if from == configVersion {
+				fmt.Fprintf(cmd.OutOrStdout(), "Configuration file %s is at the current version (%d).\n", cfgPath, configVersion)
+				return nil
+			}
+
+			cfg, err := raw.decode()
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				return writeMigratedConfig(a, cfgPath, cfg, from)
+			}
+
+			out, err := cfg.Marshal(cfg.format)
+			if err != nil {
+				return err
+			}
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(raw.data)),
+				B:        difflib.SplitLines(string(out)),
+				FromFile: cfgPath,
+				ToFile:   fmt.Sprintf("%s (version %d)", cfgPath, configVersion),
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), diff)
+			return err
+		},
+	}
+
+	cmd.Flags().Bool(dryRunFlag, false, "print the changes as a unified diff instead of writing them")
+	return cmd
+}
+
+// unknownConfigField is a key of the configuration file that the configuration types do not declare,
+// which is ignored, and dropped when the configuration is written.
+type unknownConfigField struct {
+	// Chain is the name of the chain whose configuration has the key, or empty for a top-level key.
+	Chain string
+	Key   string
+}
+
+// String describes the field, with the likely key it was meant to be.
+func (f unknownConfigField) String() string {
+	var known []string
+	if f.Chain == "" {
+		known = []string{"version", "default_chain", "chains"}
+	} else {
+		for _, field := range chainConfigFields() {
+			known = append(known, field.name)
+		}
+	}
+
+	s := fmt.Sprintf("unknown field %s", f.Key)
+	for v, m := range configMigrations {
+		if renamed, ok := m.Renamed[f.Key]; ok && f.Chain != "" {
+			return fmt.Sprintf("%s (renamed to %s in version %d of the configuration)", s, renamed, v+1)
+		}
+	}
+	if matches := closestMatches(f.Key, known, 1); len(matches) > 0 {
+		return fmt.Sprintf("%s (did you mean %s?)", s, matches[0])
+	}
+	return s
+}
+
+// unknownConfigFields returns the keys of the configuration file values that the configuration types do not declare,
+// sorted by chain and key.
+func unknownConfigFields(values map[string]interface{}) []unknownConfigField {
+	var unknown []unknownConfigField
+	for key := range values {
+		switch key {
+		case "version", "default_chain", "chains", "contacts":
+		default:
+			unknown = append(unknown, unknownConfigField{Key: key})
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, field := range chainConfigFields() {
+		known[field.name] = true
+	}
+	for name, chain := range rawChains(values) {
+		for key := range chain {
+			if !known[key] {
+				unknown = append(unknown, unknownConfigField{Chain: name, Key: key})
+			}
+		}
+	}
+
+	sort.Slice(unknown, func(i, j int) bool {
+		if unknown[i].Chain != unknown[j].Chain {
+			return unknown[i].Chain < unknown[j].Chain
+		}
+		return unknown[i].Key < unknown[j].Key
+	})
+	return unknown
+}
@@ -0,0 +1,450 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// The default configuration is valid.
+	res := sys.MustRun(t, "config", "validate")
+	require.Empty(t, res.Stdout.String())
+
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	cfg = []byte(strings.NewReplacer(
+		"rpc-addr: https://osmosis-1.technofractal.com:443", "rpc-addr: osmosis",
+		"gas-prices: 0.01uosmo", "gas-prices: cheap",
+	).Replace(string(cfg)))
+	require.NoError(t, os.WriteFile(cfgPath, cfg, 0600))
+
+	res = sys.Run(zaptest.NewLogger(t), "config", "validate")
+	require.ErrorIs(t, res.Err, cmd.InvalidConfigError{Problems: 2})
+	problems := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, problems, 2)
+	require.Equal(t, `osmosis: invalid rpc-addr "osmosis": expected a URL or host:port`, problems[0])
+	require.Contains(t, problems[1], `osmosis: invalid gas-prices "cheap"`)
+
+	// Other commands still work, as the invalid chain is not the default chain.
+	res = sys.MustRun(t, "chains", "show-default")
+	require.Equal(t, "cosmoshub\n", res.Stdout.String())
+}
+
+func TestConfigSetDefaultChain(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	_ = sys.MustRun(t, "config", "set-default-chain", "osmosis")
+	res := sys.MustRun(t, "chains", "show-default")
+	require.Equal(t, "osmosis\n", res.Stdout.String())
+
+	res = sys.Run(zaptest.NewLogger(t), "config", "set-default-chain", "osmosiss")
+	require.ErrorContains(t, res.Err, `no chain "osmosiss" found; did you mean "osmosis"?`)
+	require.Equal(t, cmd.ErrCodeChainNotFound, res.ExitCode)
+
+	// --chain overrides the default for one command, without saving the override.
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "gas-adjustment", "1.5", "--chain", "cosmoshub")
+	res = sys.MustRun(t, "chains", "show-default")
+	require.Equal(t, "osmosis\n", res.Stdout.String())
+}
+
+func TestConfigDefaultChain_Env(t *testing.T) {
+	// Not parallel, as it sets an environment variable.
+	sys := NewSystem(t)
+
+	t.Setenv("LENS_CHAIN", "osmosis")
+	res := sys.MustRun(t, "chains", "show-default")
+	require.Equal(t, "osmosis\n", res.Stdout.String())
+
+	// --chain takes precedence.
+	res = sys.MustRun(t, "chains", "show-default", "--chain", "cosmoshub")
+	require.Equal(t, "cosmoshub\n", res.Stdout.String())
+
+	// The environment does not change the configured default.
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-adjustment", "1.5")
+	cfg, err := os.ReadFile(filepath.Join(sys.HomeDir, "config.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(cfg), "default_chain: cosmoshub\n")
+
+	t.Setenv("LENS_CHAIN", "nochain")
+	res = sys.Run(zaptest.NewLogger(t), "chains", "show-default")
+	require.ErrorContains(t, res.Err, "default chain (nochain) configuration not found")
+}
+
+func TestConfigDefaultChain_None(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// Remove the default chain from the configuration.
+	_ = sys.MustRun(t, "config", "validate")
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	cfg = []byte(strings.Replace(string(cfg), "default_chain: cosmoshub", `default_chain: ""`, 1))
+	require.NoError(t, os.WriteFile(cfgPath, cfg, 0600))
+
+	res := sys.MustRun(t, "config", "validate")
+	require.Empty(t, res.Stdout.String())
+
+	// Commands given a chain still work.
+	res = sys.MustRun(t, "chains", "list", "--fields", "name", "-o", "text")
+	require.Equal(t, "NAME\ncosmoshub\nosmosis\n", res.Stdout.String())
+	_ = sys.MustRun(t, "keys", "add", "alice", "--chain", "osmosis")
+
+	// Commands whose chain is omitted fail.
+	for _, args := range [][]string{
+		{"keys", "list"},
+		{"query", "bank", "balances"},
+		{"tx", "bank", "send", "alice", "cosmos1xyz", "1uatom"},
+		{"dynamic", "list-services"},
+	} {
+		res = sys.Run(zaptest.NewLogger(t), args...)
+		require.ErrorIs(t, res.Err, cmd.NoDefaultChainError{}, args)
+		require.Equal(t, "no chain specified and no default chain configured", res.Err.Error(), args)
+		require.Equal(t, cmd.ErrCodeChainNotFound, res.ExitCode, args)
+	}
+}
+
+func TestConfigEnvOverrides(t *testing.T) {
+	// Not parallel, as it sets environment variables.
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "config", "validate")
+
+	gRPCAddr := runGRPCReflectionServer(t)
+	t.Setenv("LENS_CHAINS_COSMOSHUB_GRPC_ADDR", gRPCAddr)
+	t.Setenv("LENS_CHAINS_COSMOSHUB_GAS_PRICES", "0.5uatom")
+	t.Setenv("LENS_CHAINS_OSMOSIS_RPC_ADDRS", "https://rpc-1.example.com:443, https://rpc-2.example.com:443")
+
+	// The overrides apply when the configuration is loaded.
+	res := sys.MustRun(t, "dynamic", "list-services", "cosmoshub")
+	require.Contains(t, res.Stdout.String(), "grpc.reflection.v1alpha.ServerReflection")
+
+	var resolved struct {
+		DefaultChain string `json:"default_chain"`
+		Chains       map[string]struct {
+			GRPCAddr  string   `json:"grpc-addr"`
+			GasPrices string   `json:"gas-prices"`
+			RPCAddrs  []string `json:"rpc-addrs"`
+		} `json:"chains"`
+		Overrides map[string]string `json:"overrides"`
+	}
+	res = sys.MustRun(t, "config", "show", "--resolved", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &resolved))
+	require.Equal(t, "cosmoshub", resolved.DefaultChain)
+	require.Equal(t, gRPCAddr, resolved.Chains["cosmoshub"].GRPCAddr)
+	require.Equal(t, "0.5uatom", resolved.Chains["cosmoshub"].GasPrices)
+	require.Equal(t, []string{"https://rpc-1.example.com:443", "https://rpc-2.example.com:443"}, resolved.Chains["osmosis"].RPCAddrs)
+	require.Equal(t, map[string]string{
+		"chains.cosmoshub.grpc-addr":     "LENS_CHAINS_COSMOSHUB_GRPC_ADDR",
+		"chains.cosmoshub.gas-prices":    "LENS_CHAINS_COSMOSHUB_GAS_PRICES",
+		"chains.cosmoshub.output-format": "--output",
+		"chains.osmosis.rpc-addrs":       "LENS_CHAINS_OSMOSIS_RPC_ADDRS",
+		"chains.osmosis.output-format":   "--output",
+	}, resolved.Overrides)
+
+	// The text output annotates the overridden keys.
+	res = sys.MustRun(t, "config", "show", "--resolved")
+	require.Contains(t, res.Stdout.String(), "    gas-prices: 0.5uatom  # LENS_CHAINS_COSMOSHUB_GAS_PRICES\n")
+	require.Contains(t, res.Stdout.String(), "    gas-prices: 0.01uosmo\n")
+
+	// Overrides are never written to the configuration file, but edits are.
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-adjustment", "1.5")
+	res = sys.MustRun(t, "config", "show")
+	require.Contains(t, res.Stdout.String(), "    gas-prices: 0.01uatom\n")
+	require.NotContains(t, res.Stdout.String(), gRPCAddr)
+	require.NotContains(t, res.Stdout.String(), "rpc-1.example.com")
+	require.Contains(t, res.Stdout.String(), "    gas-adjustment: 1.5\n")
+
+	// Editing an overridden field saves the edited value.
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "gas-prices", "0.02uatom")
+	cfg, err := os.ReadFile(filepath.Join(sys.HomeDir, "config.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(cfg), "    gas-prices: 0.02uatom\n")
+}
+
+func TestConfigEnvOverrides_Precedence(t *testing.T) {
+	// Not parallel, as it sets environment variables.
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "config", "validate")
+
+	keyringBackend := func(args ...string) (string, string) {
+		t.Helper()
+
+		var resolved struct {
+			Chains map[string]struct {
+				KeyringBackend string `json:"keyring-backend"`
+			} `json:"chains"`
+			Overrides map[string]string `json:"overrides"`
+		}
+		res := sys.MustRun(t, append([]string{"config", "show", "--resolved", "-o", "json"}, args...)...)
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &resolved))
+		return resolved.Chains["cosmoshub"].KeyringBackend, resolved.Overrides["chains.cosmoshub.keyring-backend"]
+	}
+
+	// The file.
+	backend, source := keyringBackend()
+	require.Equal(t, "test", backend)
+	require.Empty(t, source)
+
+	// The environment overrides the file.
+	t.Setenv("LENS_CHAINS_COSMOSHUB_KEYRING_BACKEND", "memory")
+	backend, source = keyringBackend()
+	require.Equal(t, "memory", backend)
+	require.Equal(t, "LENS_CHAINS_COSMOSHUB_KEYRING_BACKEND", source)
+
+	// Flags override the environment.
+	backend, source = keyringBackend("--keyring-backend", "file")
+	require.Equal(t, "file", backend)
+	require.Equal(t, "--keyring-backend", source)
+
+	// The keys of commands follow the same precedence: the memory keyring starts empty.
+	_ = sys.MustRun(t, "keys", "add", "alice", "--keyring-backend", "test")
+	res := sys.MustRun(t, "keys", "list")
+	require.NotContains(t, res.Stdout.String(), "alice")
+	res = sys.MustRun(t, "keys", "list", "--keyring-backend", "test")
+	require.Contains(t, res.Stdout.String(), "alice")
+
+	// Invalid values are reported with their variable.
+	t.Setenv("LENS_CHAINS_OSMOSIS_GRPC_TLS", "maybe")
+	res = sys.Run(zaptest.NewLogger(t), "chains", "list")
+	require.ErrorContains(t, res.Err, `invalid LENS_CHAINS_OSMOSIS_GRPC_TLS: strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
+func TestConfigConvert(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "rpc-addrs", "https://rpc-1.example.com:443,https://rpc-2.example.com:443")
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-headers", "x-api-key=env:API_KEY,x-team=lens")
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-adjustment", "1.75")
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "min-gas-amount", "200000")
+	_ = sys.MustRun(t, "config", "set-default-chain", "osmosis")
+
+	config := func() map[string]interface{} {
+		t.Helper()
+
+		var cfg map[string]interface{}
+		res := sys.MustRun(t, "config", "show", "-o", "json")
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &cfg))
+		return cfg
+	}
+	want := config()
+
+	for _, format := range []string{"toml", "json", "yaml", "toml", "yaml"} {
+		_ = sys.MustRun(t, "config", "convert", "--to", format)
+
+		// Only the converted file is left, besides backups.
+		entries, err := os.ReadDir(sys.HomeDir)
+		require.NoError(t, err)
+		var files []string
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), "config.") && e.Name() != "config.lock" && !strings.HasSuffix(e.Name(), ".bak") {
+				files = append(files, e.Name())
+			}
+		}
+		require.Equal(t, []string{"config." + format}, files)
+
+		require.Equal(t, want, config(), format)
+	}
+
+	res := sys.Run(zaptest.NewLogger(t), "config", "convert", "--to", "ini")
+	require.ErrorContains(t, res.Err, `unknown configuration format "ini"`)
+}
+
+func TestConfigFormats(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		format, file string
+		marker       string
+	}{
+		{format: "json", file: "config.json", marker: `"default_chain": "cosmoshub"`},
+		{format: "toml", file: "config.toml", marker: `default_chain = 'cosmoshub'`},
+		// Without an extension, the format is detected from the content.
+		{format: "json", file: "config", marker: `"default_chain": "cosmoshub"`},
+		{format: "toml", file: "config", marker: `default_chain = 'cosmoshub'`},
+		{format: "yaml", file: "config", marker: `default_chain: cosmoshub`},
+	} {
+		tc := tc
+		t.Run(tc.format+"/"+tc.file, func(t *testing.T) {
+			t.Parallel()
+
+			sys := NewSystem(t)
+			_ = sys.MustRun(t, "config", "convert", "--to", tc.format)
+			if tc.file == "config" {
+				require.NoError(t, os.Rename(filepath.Join(sys.HomeDir, "config."+tc.format), filepath.Join(sys.HomeDir, "config")))
+			}
+			cfgPath := filepath.Join(sys.HomeDir, tc.file)
+
+			res := sys.MustRun(t, "chains", "list", "--fields", "name,chain-id", "-o", "text")
+			require.Equal(t, "NAME       CHAIN-ID\ncosmoshub  cosmoshub-4\nosmosis    osmosis-1\n", res.Stdout.String())
+
+			// Writing the configuration keeps its file and format.
+			_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-prices", "0.025uosmo")
+			_ = sys.MustRunWithInput(t, strings.NewReader(`{"juno": {"chain-id": "juno-1", "rpc-addr": "https://rpc.juno.example.com:443", "account-prefix": "juno", "keyring-backend": "test", "gas-prices": "0.01ujuno", "gas-adjustment": 1.2, "timeout": "20s", "sign-mode": "direct"}}`), "chains", "import")
+
+			cfg, err := os.ReadFile(cfgPath)
+			require.NoError(t, err)
+			require.Contains(t, string(cfg), tc.marker)
+
+			res = sys.MustRun(t, "chains", "list", "--fields", "name,gas-prices", "-o", "text")
+			require.Equal(t, "NAME       GAS-PRICES\ncosmoshub  0.01uatom\njuno       0.01ujuno\nosmosis    0.025uosmo\n", res.Stdout.String())
+		})
+	}
+}
+
+func TestConfigWrite_Backup(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-prices", "0.02uosmo")
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "gas-prices", "0.03uosmo")
+
+	// The previous version is kept as the backup, and no temporary file is left.
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	require.Contains(t, string(cfg), "gas-prices: 0.03uosmo")
+	backup, err := os.ReadFile(cfgPath + ".bak")
+	require.NoError(t, err)
+	require.Contains(t, string(backup), "gas-prices: 0.02uosmo")
+
+	entries, err := os.ReadDir(sys.HomeDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		require.NotContains(t, e.Name(), ".tmp-")
+	}
+
+	// A corrupt configuration is reported with its backup.
+	require.NoError(t, os.WriteFile(cfgPath, []byte("chains: [\n"), 0600))
+	res := sys.Run(zaptest.NewLogger(t), "chains", "list")
+	var corrupt cmd.CorruptConfigError
+	require.ErrorAs(t, res.Err, &corrupt)
+	require.Equal(t, cfgPath+".bak", corrupt.Backup)
+	require.ErrorContains(t, res.Err, "configuration file "+cfgPath+" is corrupt: ")
+	require.ErrorContains(t, res.Err, "restore it with: cp "+cfgPath+".bak "+cfgPath)
+
+	// The config subcommands report it too.
+	res = sys.Run(zaptest.NewLogger(t), "config", "validate")
+	require.ErrorAs(t, res.Err, &corrupt)
+
+	require.NoError(t, os.Rename(cfgPath+".bak", cfgPath))
+	res = sys.MustRun(t, "chains", "list", "--fields", "name,gas-prices", "-o", "text")
+	require.Equal(t, "NAME       GAS-PRICES\ncosmoshub  0.01uatom\nosmosis    0.02uosmo\n", res.Stdout.String())
+
+	// Without a backup, the hint is to fix or remove the file.
+	require.NoError(t, os.WriteFile(cfgPath, []byte("chains: [\n"), 0600))
+	res = sys.Run(zaptest.NewLogger(t), "chains", "list")
+	require.ErrorContains(t, res.Err, "fix it with lens chains editor, or remove it to recreate the default configuration")
+}
+
+func TestConfigWrite_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "config", "validate")
+
+	// Concurrent commands each adding a chain all keep their update.
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			chain := fmt.Sprintf(`{"chain-%d": {"chain-id": "chain-%d", "rpc-addr": "localhost:26657", "account-prefix": "cosmos", "keyring-backend": "test", "gas-adjustment": 1.2, "gas-prices": "0.01stake", "timeout": "20s", "sign-mode": "direct"}}`, i, i)
+			errs[i] = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(chain), "chains", "import").Err
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	res := sys.MustRun(t, "chains", "list", "--fields", "name", "-o", "text")
+	require.Len(t, strings.Split(strings.TrimSpace(res.Stdout.String()), "\n"), n+3)
+}
+
+func TestConfigMigrate(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "config", "validate")
+
+	// A configuration written before versioning, with the output format indent since renamed to json-indent.
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	cfg = []byte(strings.Replace(strings.TrimPrefix(string(cfg), "version: 1\n"), "output-format: json", "output-format: indent", 1))
+	require.NoError(t, os.WriteFile(cfgPath, cfg, 0600))
+
+	// A dry run prints the changes without writing them.
+	res := sys.MustRun(t, "config", "migrate", "--dry-run")
+	require.Contains(t, res.Stdout.String(), "--- "+cfgPath+"\n+++ "+cfgPath+" (version 1)\n")
+	require.Contains(t, res.Stdout.String(), "\n+version: 1\n")
+	require.Contains(t, res.Stdout.String(), "\n-    output-format: indent\n+    output-format: json-indent\n")
+	unchanged, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	require.Equal(t, cfg, unchanged)
+
+	// Loading the configuration migrates it, keeping the previous version as the backup.
+	_ = sys.MustRun(t, "chains", "list")
+	migrated, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(migrated), "version: 1\n"))
+	require.NotContains(t, string(migrated), "output-format: indent")
+	backup, err := os.ReadFile(cfgPath + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, cfg, backup)
+
+	res = sys.MustRun(t, "config", "migrate")
+	require.Equal(t, "Configuration file "+cfgPath+" is at the current version (1).\n", res.Stdout.String())
+
+	// A configuration of a later version is refused.
+	require.NoError(t, os.WriteFile(cfgPath, []byte(strings.Replace(string(migrated), "version: 1", "version: 99", 1)), 0600))
+	res = sys.Run(zaptest.NewLogger(t), "chains", "list")
+	require.ErrorContains(t, res.Err, "is at version 99, newer than the latest version this lens supports (1)")
+}
+
+func TestConfigUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "config", "validate")
+
+	cfgPath := filepath.Join(sys.HomeDir, "config.yaml")
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	cfg = []byte(strings.Replace(string(cfg), "gas-prices: 0.01uosmo", "gas-prices: 0.01uosmo\n    grpc_addr: localhost:9090", 1))
+	require.NoError(t, os.WriteFile(cfgPath, append([]byte("defaultchain: osmosis\n"), cfg...), 0600))
+
+	res := sys.Run(zaptest.NewLogger(t), "config", "validate")
+	require.ErrorIs(t, res.Err, cmd.InvalidConfigError{Problems: 2})
+	require.Equal(t,
+		"unknown field defaultchain (did you mean default_chain?)\nosmosis: unknown field grpc_addr (did you mean grpc-addr?)\n",
+		res.Stdout.String(),
+	)
+
+	// Unknown fields are warnings for other commands.
+	res = sys.MustRun(t, "chains", "show-default")
+	require.Equal(t, "cosmoshub\n", res.Stdout.String())
+}
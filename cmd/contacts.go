@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+func contactsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contacts",
+		Short: "manage the contact book of named addresses",
+		Long: fmt.Sprintf(`Manage the contact book of the configuration: addresses saved under a name.
+
+Wherever the query and tx commands expect an address, %[1]sNAME stands for the address of the contact NAME,
+converted to the account or validator prefix of the chain, so that an address saved once serves on every chain
+sharing its key. The %[1]s sigil tells a contact from a key or an address.`, client.ContactSigil),
+		Example: fmt.Sprintf(`$ %[1]s contacts add alice cosmos1...
+$ %[1]s tx bank send mykey %[2]salice 10uatom
+$ %[1]s q bank balances osmosis %[2]salice`, appName, client.ContactSigil),
+	}
+
+	cmd.AddCommand(
+		withConfigLock(a, cmdContactsAdd(a)),
+		cmdContactsList(a),
+		withConfigLock(a, cmdContactsRemove(a)),
+	)
+	return cmd
+}
+
+func cmdContactsAdd(a *appState) *cobra.Command {
+	const forceFlag = "force"
+
+	cmd := &cobra.Command{
+		Use:   "add <name> <address>",
+		Short: "save an address in the contact book",
+		Long: fmt.Sprintf(`Save the bech32 address under name in the contact book, to be referenced as %sNAME.
+The address may be that of any chain. An existing contact is only replaced with --%s.`, client.ContactSigil, forceFlag),
+		Args:    withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s contacts add alice cosmos1...`, appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, err := cmd.Flags().GetBool(forceFlag)
+			if err != nil {
+				return err
+			}
+			name, address := strings.TrimPrefix(args[0], client.ContactSigil), args[1]
+			if err := client.ValidateContact(name, address); err != nil {
+				return err
+			}
+			if existing, ok := a.Config.Contacts[name]; ok && !force {
+				return fmt.Errorf("contact %q already exists, with address %s; use --%s to replace it", name, existing, forceFlag)
+			}
+
+			if a.Config.Contacts == nil {
+				a.Config.Contacts = make(map[string]string)
+			}
+			a.Config.Contacts[name] = address
+			return a.OverwriteConfig(a.Config)
+		},
+	}
+	cmd.Flags().Bool(forceFlag, false, "replace the address of an existing contact")
+	return cmd
+}
+
+func cmdContactsList(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l"},
+		Short:   "list the contacts of the contact book",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contacts := contactList{}
+			for _, name := range sortedContactNames(a.Config.Contacts) {
+				contacts = append(contacts, contact{Name: name, Address: a.Config.Contacts[name]})
+			}
+			return writeOutput(cmd, a, contacts)
+		},
+	}
+	return cmd
+}
+
+func cmdContactsRemove(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <name>...",
+		Aliases: []string{"rm"},
+		Short:   "remove contacts from the contact book",
+		Args:    withUsage(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			cfg := completionConfig(a)
+			if cfg == nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return filterCompletions(sortedContactNames(cfg.Contacts), toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				name := strings.TrimPrefix(arg, client.ContactSigil)
+				if _, ok := a.Config.Contacts[name]; !ok {
+					return client.UnknownContactError{Name: name, Names: sortedContactNames(a.Config.Contacts)}
+				}
+			}
+			for _, arg := range args {
+				delete(a.Config.Contacts, strings.TrimPrefix(arg, client.ContactSigil))
+			}
+			return a.OverwriteConfig(a.Config)
+		},
+	}
+	return cmd
+}
+
+// sortedContactNames returns the names of contacts, sorted.
+func sortedContactNames(contacts map[string]string) []string {
+	names := make([]string, 0, len(contacts))
+	for name := range contacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contact is a contact listed by contacts list.
+type contact struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// contactList is the result of contacts list.
+type contactList []contact
+
+var _ fmt.Stringer = contactList{}
+
+// String returns the contacts as a table with aligned columns.
+func (l contactList) String() string {
+	if len(l) == 0 {
+		return fmt.Sprintf("No contacts; add one with %s contacts add <name> <address>.\n", appName)
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS")
+	for _, c := range l {
+		fmt.Fprintf(w, "%s\t%s\n", c.Name, c.Address)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// resolveAddressArg returns the address argument arg of a command on the chain chainName,
+// or, if it references a contact, the address of the contact on that chain,
+// for the commands passing addresses through without a chain client.
+func resolveAddressArg(a *appState, chainName, arg string) (string, error) {
+	if !strings.HasPrefix(arg, client.ContactSigil) {
+		return arg, nil
+	}
+	cl, err := chainClientByName(a, chainName)
+	if err != nil {
+		return "", err
+	}
+	return cl.ResolveAccAddr(arg)
+}
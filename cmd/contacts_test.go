@@ -0,0 +1,91 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestContacts(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	res := sys.MustRun(t, "contacts", "list", "-o", "text")
+	require.Equal(t, "No contacts; add one with lens contacts add <name> <address>.\n", res.Stdout.String())
+
+	// The address of a contact may be that of another chain.
+	_, bz, err := bech32.DecodeAndConvert(testGroupMemberAddr)
+	require.NoError(t, err)
+	osmoAddr, err := bech32.ConvertAndEncode("osmo", bz)
+	require.NoError(t, err)
+	sys.MustRun(t, "contacts", "add", "alice", osmoAddr)
+	sys.MustRun(t, "contacts", "add", "bob", ZeroCosmosAddr)
+
+	res = sys.MustRun(t, "contacts", "list", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Equal(t, []string{"NAME", "ADDRESS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"alice", osmoAddr}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"bob", ZeroCosmosAddr}, strings.Fields(lines[2]))
+	res = sys.MustRun(t, "config", "show", "-o", "json")
+	var cfg struct {
+		Contacts map[string]string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &cfg))
+	require.Equal(t, map[string]string{"alice": osmoAddr, "bob": ZeroCosmosAddr}, cfg.Contacts)
+
+	// A contact stands for its address, with the prefix of the chain.
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res = sys.MustRun(t, "tx", "bank", "send", "mykey", "@alice", "10uatom", "--generate-only")
+	var tx struct {
+		Body struct {
+			Messages []struct {
+				ToAddress string `json:"to_address"`
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, testGroupMemberAddr, tx.Body.Messages[0].ToAddress)
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", "@carol", "10uatom", "--generate-only")
+	require.ErrorContains(t, res.Err, "unknown contact @carol (contacts: alice, bob)")
+
+	// An existing contact is only replaced with --force.
+	res = sys.Run(zaptest.NewLogger(t), "contacts", "add", "alice", ZeroCosmosAddr)
+	require.ErrorContains(t, res.Err, `contact "alice" already exists, with address `+osmoAddr+"; use --force to replace it")
+	sys.MustRun(t, "contacts", "add", "@alice", ZeroCosmosAddr, "--force")
+	res = sys.MustRun(t, "contacts", "list", "-o", "json")
+	var contacts []struct {
+		Name    string
+		Address string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &contacts))
+	require.Len(t, contacts, 2)
+	require.Equal(t, ZeroCosmosAddr, contacts[0].Address)
+
+	for args, msg := range map[string]string{
+		"add al/ice " + ZeroCosmosAddr: `invalid contact name "al/ice"`,
+		"add carol cosmos1x":           `invalid address "cosmos1x" of contact "carol"`,
+		"remove alice carol":           "unknown contact @carol (contacts: alice, bob)",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"contacts"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+
+	sys.MustRun(t, "contacts", "remove", "alice", "@bob")
+	res = sys.MustRun(t, "contacts", "list", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "No contacts")
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", "@alice", "10uatom", "--generate-only")
+	require.ErrorContains(t, res.Err, "unknown contact @alice: the contact book is empty")
+}
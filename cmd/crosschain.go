@@ -61,7 +61,10 @@ func getEnabledChainbalancesCmd(a *appState) *cobra.Command {
 			sort.StringSlice(enabledChains).Sort()
 
 			// copied from bank.go
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			var (
 				keyNameOrAddress = ""
 				address          sdk.AccAddress
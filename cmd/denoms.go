@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const (
+	humanFlag      = "human"
+	rawAmountsFlag = "raw"
+)
+
+// denomCacheTTL is how long the cached denom metadata of a chain is used before it is queried again.
+// The paths of IBC denoms never change, so they are kept.
+const denomCacheTTL = 24 * time.Hour
+
+// addDenomFlags adds the flags choosing between raw amounts and amounts in display units to cmd.
+func addDenomFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(humanFlag, false, "show amounts in the display units of their denoms, and IBC denoms as their paths (the default with text output; adds a display field to other output)")
+	cmd.Flags().Bool(rawAmountsFlag, false, "show amounts in base denoms, as stored on chain")
+}
+
+// humanAmounts reports whether cmd shows amounts in display units:
+// by default with text output, unless --raw is given, and with --human with any output.
+func humanAmounts(cmd *cobra.Command, a *appState) (bool, error) {
+	human, err := cmd.Flags().GetBool(humanFlag)
+	if err != nil {
+		return false, err
+	}
+	raw, err := cmd.Flags().GetBool(rawAmountsFlag)
+	if err != nil {
+		return false, err
+	}
+	if human && raw {
+		return false, fmt.Errorf("--%s and --%s cannot be used together", humanFlag, rawAmountsFlag)
+	}
+	if human {
+		return true, nil
+	}
+	return !raw && (a.OutputFormat == "" || a.OutputFormat == outputText), nil
+}
+
+// denomCacheEntry is what is cached of the denoms of a chain.
+type denomCacheEntry struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Denoms    client.Denoms `json:"denoms"`
+}
+
+// resolveDenoms returns what is known of the denoms of coins on the chain of cl.
+// The denoms are read from the cache of the chain under the lens home directory;
+// the metadata of all denoms is queried when the cache is older than denomCacheTTL,
+// and the trace of each IBC denom not cached yet.
+// Failed queries are logged and leave the denoms unresolved, so that amounts are shown raw.
+func resolveDenoms(ctx context.Context, a *appState, cl *client.ChainClient, coins ...sdk.Coins) client.Denoms {
+	path := denomCachePath(a.HomePath, cl.Config.ChainID)
+	entry, err := loadDenomCacheEntry(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		a.Log.Info("Ignoring unreadable denom cache", zap.String("path", path), zap.Error(err))
+	}
+	denoms := client.NewDenoms(nil, entry.Denoms.Traces)
+	if entry.Denoms.Metadata != nil {
+		denoms.Metadata = entry.Denoms.Metadata
+	}
+
+	q := query.Query{Ctx: ctx, Client: cl, Options: &query.QueryOptions{}}
+	changed := false
+	if time.Since(entry.FetchedAt) > denomCacheTTL {
+		metadatas, err := q.Bank_AllDenomsMetadata()
+		if err != nil {
+			a.Log.Warn("Failed to query denom metadata; showing amounts in base denoms", zap.String("chain_id", cl.Config.ChainID), zap.Error(err))
+		} else {
+			denoms.Metadata = client.NewDenoms(metadatas, nil).Metadata
+			entry.FetchedAt = time.Now().UTC()
+			changed = true
+		}
+	}
+	for _, cs := range coins {
+		for _, c := range cs {
+			if _, ok := denoms.Traces[c.Denom]; ok || !strings.HasPrefix(c.Denom, "ibc/") {
+				continue
+			}
+			res, err := q.Transfer_DenomTrace(strings.TrimPrefix(c.Denom, "ibc/"))
+			if err != nil {
+				a.Log.Warn("Failed to query IBC denom trace", zap.String("denom", c.Denom), zap.Error(err))
+				continue
+			}
+			denoms.Traces[c.Denom] = res.DenomTrace.GetFullDenomPath()
+			changed = true
+		}
+	}
+
+	if changed {
+		entry.Denoms = denoms
+		if err := saveDenomCacheEntry(path, entry); err != nil {
+			a.Log.Info("Failed to write denom cache", zap.String("path", path), zap.Error(err))
+		}
+	}
+	return denoms
+}
+
+// formatCoins returns coins formatted with denoms, or as the SDK formats them if denoms is nil.
+func formatCoins(denoms *client.Denoms, coins sdk.Coins) string {
+	if denoms == nil {
+		return coins.String()
+	}
+	return denoms.FormatCoins(coins)
+}
+
+// loadDenomCacheEntry reads the denom cache entry at path.
+func loadDenomCacheEntry(path string) (denomCacheEntry, error) {
+	var entry denomCacheEntry
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return entry, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return entry, nil
+}
+
+// saveDenomCacheEntry writes entry to the denom cache entry at path.
+func saveDenomCacheEntry(path string, entry denomCacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// denomCachePath returns the path of the denom cache entry of the chain chainID under the lens home directory.
+func denomCachePath(home, chainID string) string {
+	return filepath.Join(home, "cache", "denoms", unsafeCacheKeyChars.ReplaceAllString(chainID, "_")+".json")
+}
@@ -4,12 +4,13 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/distribution/types"
 	"github.com/spf13/cobra"
 	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
 )
 
 const (
@@ -36,7 +37,10 @@ $ lens tx withdraw-rewards --from mykey --all
 		),
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			key := ""
 			if len(args) == 1 {
 				key = cl.Config.Key
@@ -58,7 +62,7 @@ $ lens tx withdraw-rewards --from mykey --all
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			if all, _ := cmd.Flags().GetBool(FlagAll); all {
 
 				resp, err := query.Distribution_DelegatorValidators(encodedAddr)
@@ -102,22 +106,106 @@ $ lens tx withdraw-rewards --from mykey --all
 	return cmd
 }
 
+func distributionWithdrawAllRewardsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdraw-all-rewards [chain-name]",
+		Short: "withdraw the rewards of a delegator from all its validators in one transaction",
+		Long: `Withdraw the pending rewards of the --from key, or of the chain's key,
+from every validator it has non-zero rewards from, in a single transaction on the given chain, or on the default chain.
+
+With --commission, the commission of the validator operated by the key is withdrawn too.
+The gas is simulated and multiplied by the chain's gas-adjustment.
+With --dry-run, the messages of the transaction are written without signing or broadcasting it.`,
+		Example: fmt.Sprintf(`$ %s tx distribution withdraw-all-rewards cosmoshub --from mykey
+$ %s tx distr withdraw-all-rewards cosmoshub --from mykey --commission --dry-run`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			withCommission, err := cmd.Flags().GetBool(FlagCommission)
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			cl, delAddr, err := txChainClient(cmd, a, chainName, from)
+			if err != nil {
+				return err
+			}
+			delegator := cl.MustEncodeAccAddr(delAddr)
+
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+			rewards, err := query.Distribution_DelegationTotalRewards(delegator)
+			if err != nil {
+				return err
+			}
+
+			var msgs []sdk.Msg
+			for _, r := range rewards.Rewards {
+				if r.Reward.IsZero() {
+					continue
+				}
+				msgs = append(msgs, &types.MsgWithdrawDelegatorReward{
+					DelegatorAddress: delegator,
+					ValidatorAddress: r.ValidatorAddress,
+				})
+			}
+
+			if withCommission {
+				operator := cl.MustEncodeValAddr(sdk.ValAddress(delAddr))
+				if _, err := query.Staking_Validator(operator); err != nil {
+					return fmt.Errorf("%s is not the operator of a validator (%s): %w", delegator, operator, err)
+				}
+				commission, err := query.Distribution_ValidatorCommission(operator)
+				if err != nil {
+					return err
+				}
+				if commission.Commission.Commission.IsZero() {
+					a.Log.Info("No commission to withdraw", zap.String("validator", operator))
+				} else {
+					msgs = append(msgs, &types.MsgWithdrawValidatorCommission{ValidatorAddress: operator})
+				}
+			}
+
+			if len(msgs) == 0 {
+				return fmt.Errorf("%s has no rewards to withdraw on chain %s", delegator, chainName)
+			}
+
+			return sendOrWriteMsgs(cmd, a, cl, msgs)
+		},
+	}
+	cmd.Flags().BoolP(FlagCommission, "c", false, "also withdraw the commission of the validator operated by the key")
+	addDryRunFlag(cmd)
+	AddTxFlagsToCmd(cmd)
+	memoFlag(a.Viper, cmd)
+	return cmd
+}
+
 func distributionParamsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "params",
 		Short: "query things about a chain's distribution params",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			params, err := query.Distribution_Params()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(params.Params)
+			return writeOutput(cmd, a, codecJSON(cl, &params.Params))
 		},
 	}
 
@@ -129,17 +217,20 @@ func distributionCommunityPoolCmd(a *appState) *cobra.Command {
 		Use:   "community-pool",
 		Short: "query things about a chain's community pool",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			pool, err := query.Distribution_CommunityPool()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(pool.Pool)
+			return writeOutput(cmd, a, pool.Pool)
 		},
 	}
 
@@ -152,70 +243,130 @@ func distributionCommissionCmd(a *appState) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		Short: "query a specific validator's commission",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			commission, err := query.Distribution_ValidatorCommission(args[0])
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			validator, err := cl.ResolveValAddr(args[0])
+			if err != nil {
+				return err
+			}
+			commission, err := query.Distribution_ValidatorCommission(validator)
 			if err != nil {
 				return err
 			}
 
-			return cl.PrintObject(commission.Commission)
+			return writeOutput(cmd, a, codecJSON(cl, &commission.Commission))
 		},
 	}
 
 	return cmd
 }
 
+const distributionValidatorFlag = "validator"
+
 func distributionRewardsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "rewards [key-or-delegator-address] [validator-address]",
-		Short: "query things about a delegator's rewards",
-		Args:  cobra.ExactArgs(2),
+		Use:   "rewards [chain-name] [key-or-delegator-address]",
+		Short: "query the pending rewards of a delegator",
+		Long: `Query the pending rewards of a delegator from each of its validators, with their total.
+
+` + chainAndAddressArgsHelp + `
+
+With --validator, only the rewards from that validator are shown.`,
+		Example: fmt.Sprintf(`$ %s query distribution rewards cosmoshub
+$ %s q distr rewards cosmoshub cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgsFunction: completeChainThenKey(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			opts, err := queryOptionsFromFlags(cmd.Flags())
+			cl, delegator, err := chainClientAndAddress(a, args)
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			delAddr, err := cl.AccountFromKeyOrAddress(args[0])
+			validator, err := cmd.Flags().GetString(distributionValidatorFlag)
 			if err != nil {
 				return err
 			}
-
-			valAddr, err := cl.DecodeBech32ValAddr(args[1])
+			height, err := ReadHeight(cmd.Flags())
 			if err != nil {
 				return err
 			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{Height: height}}
 
-			rewards, err := query.Distribution_DelegationRewards(delAddr.String(), valAddr.String())
+			res, err := query.Distribution_DelegationTotalRewards(delegator)
 			if err != nil {
 				return err
 			}
 
-			return cl.PrintObject(rewards.Rewards)
+			result := rewardsResult{Rewards: []validatorRewards{}, Total: res.Total}
+			for _, r := range res.Rewards {
+				if validator != "" && r.ValidatorAddress != validator {
+					continue
+				}
+				result.Rewards = append(result.Rewards, validatorRewards{Validator: r.ValidatorAddress, Rewards: r.Reward})
+			}
+			if validator != "" {
+				if len(result.Rewards) == 0 {
+					return fmt.Errorf("%s has no delegation to validator %s", delegator, validator)
+				}
+				result.Total = result.Rewards[0].Rewards
+			}
+			return writeOutput(cmd, a, result)
 		},
 	}
-
+	addQueryHeightFlag(cmd)
+	cmd.Flags().String(distributionValidatorFlag, "", "only show the rewards from this validator operator address")
 	return cmd
 }
 
+// validatorRewards is the pending rewards of a delegator from one validator.
+type validatorRewards struct {
+	Validator string       `json:"validator"`
+	Rewards   sdk.DecCoins `json:"rewards"`
+}
+
+// rewardsResult is the result of query distribution rewards.
+type rewardsResult struct {
+	Rewards []validatorRewards `json:"rewards"`
+	Total   sdk.DecCoins       `json:"total"`
+}
+
+var _ fmt.Stringer = rewardsResult{}
+
+// String returns the rewards as a table with aligned columns, followed by the total.
+func (r rewardsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VALIDATOR\tREWARDS")
+	for _, v := range r.Rewards {
+		fmt.Fprintf(w, "%s\t%s\n", v.Validator, orDash(v.Rewards.String()))
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "Total: %s\n", orDash(r.Total.String()))
+	return b.String()
+}
+
 func distributionSlashesCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "slashes [validator-address] [start-height] [end-height]",
 		Short: "query things about a validator's slashes on a chain",
 		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 
 			address, err := cl.DecodeBech32ValAddr(args[0])
 			if err != nil {
@@ -237,7 +388,7 @@ func distributionSlashesCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			return cl.PrintObject(slashes)
+			return writeOutput(cmd, a, codecJSON(cl, slashes))
 		},
 	}
 
@@ -250,12 +401,15 @@ func distributionValidatorRewardsCmd(a *appState) *cobra.Command {
 		Short: "query things about a validator's (and all their delegators) outstanding rewards on a chain",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			address, err := cl.DecodeBech32ValAddr(args[0])
 			if err != nil {
 				return err
@@ -266,7 +420,7 @@ func distributionValidatorRewardsCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			return cl.PrintObject(rewards.Rewards)
+			return writeOutput(cmd, a, codecJSON(cl, &rewards.Rewards))
 		},
 	}
 	return cmd
@@ -292,7 +446,10 @@ func distributionDelegatorValidatorsCmd(a *appState) *cobra.Command {
 			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			// Check if the address has a valid format
 			if len(delegator) > 0 {
 				_, err := cl.DecodeBech32AccAddr(delegator)
@@ -311,14 +468,14 @@ func distributionDelegatorValidatorsCmd(a *appState) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			delValidators, err := query.Distribution_DelegatorValidators(encodedAddr)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(delValidators.Validators)
+			return writeOutput(cmd, a, delValidators.Validators)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 	return cmd
 }
@@ -0,0 +1,99 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mockDelegationRewards makes mc answer the query of the total rewards of a delegator
+// with 5uatom from validator A and none from validator B.
+func mockDelegationRewards(t *testing.T, mc *mocks.Client) {
+	t.Helper()
+
+	mockABCIQuery(t, mc, "/cosmos.distribution.v1beta1.Query/DelegationTotalRewards", func(bytes.HexBytes) bool { return true },
+		&distrtypes.QueryDelegationTotalRewardsResponse{
+			Rewards: []distrtypes.DelegationDelegatorReward{
+				{ValidatorAddress: testValoperA, Reward: sdk.NewDecCoins(sdk.NewInt64DecCoin("uatom", 5))},
+				{ValidatorAddress: testValoperB},
+			},
+			Total: sdk.NewDecCoins(sdk.NewInt64DecCoin("uatom", 5)),
+		})
+}
+
+func TestDistributionRewards(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockDelegationRewards(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "distribution", "rewards", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"VALIDATOR", "REWARDS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{testValoperA, "5.000000000000000000uatom"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{testValoperB, "-"}, strings.Fields(lines[2]))
+	require.Equal(t, "Total: 5.000000000000000000uatom", lines[3])
+
+	res = sys.MustRun(t, "query", "distribution", "rewards", ZeroCosmosAddr, "--validator", testValoperB, "-o", "json")
+	var rewards struct {
+		Rewards []struct {
+			Validator string
+		}
+		Total sdk.DecCoins
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &rewards))
+	require.Len(t, rewards.Rewards, 1)
+	require.Equal(t, testValoperB, rewards.Rewards[0].Validator)
+	require.True(t, rewards.Total.IsZero())
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "distribution", "rewards", ZeroCosmosAddr, "--validator", "cosmosvaloper1cccc")
+	require.ErrorContains(t, res.Err, "has no delegation to validator cosmosvaloper1cccc")
+}
+
+func TestDistributionWithdrawAllRewards(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockDelegationRewards(t, mc)
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validator", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorResponse{})
+	mockABCIQuery(t, mc, "/cosmos.distribution.v1beta1.Query/ValidatorCommission", func(bytes.HexBytes) bool { return true },
+		&distrtypes.QueryValidatorCommissionResponse{Commission: distrtypes.ValidatorAccumulatedCommission{
+			Commission: sdk.NewDecCoins(sdk.NewInt64DecCoin("uatom", 7)),
+		}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// Only the validators with rewards are withdrawn from.
+	res := sys.MustRun(t, "tx", "distribution", "withdraw-all-rewards", "cosmoshub", "--from", ZeroCosmosAddr, "--dry-run", "--commission", "-o", "json")
+	var msgs []map[string]string
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 2)
+	require.Equal(t, "/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward", msgs[0]["@type"])
+	require.Equal(t, ZeroCosmosAddr, msgs[0]["delegator_address"])
+	require.Equal(t, testValoperA, msgs[0]["validator_address"])
+	require.Equal(t, "/cosmos.distribution.v1beta1.MsgWithdrawValidatorCommission", msgs[1]["@type"])
+	require.True(t, strings.HasPrefix(msgs[1]["validator_address"], "cosmosvaloper1"))
+
+	// Broadcasting needs a key to sign with.
+	res = sys.Run(zaptest.NewLogger(t), "tx", "distribution", "withdraw-all-rewards", "cosmoshub", "--from", ZeroCosmosAddr)
+	require.ErrorContains(t, res.Err, "a key is needed to sign the transaction")
+}
@@ -1,29 +1,39 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoprint"
-	"github.com/jhump/protoreflect/dynamic"
-	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
-	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
 
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
-
 )
 
 func dynamicCmd(a *appState) *cobra.Command {
@@ -33,9 +43,25 @@ func dynamicCmd(a *appState) *cobra.Command {
 		Short:   "Dynamic integration with remote chains",
 	}
 
-	cmd.AddCommand(
+	for _, c := range []*cobra.Command{
 		dynInspectCmd(a),
 		dynQueryCmd(a),
+		dynCallCmd(a),
+		dynListServicesCmd(a),
+		dynListMethodsCmd(a),
+		dynSearchCmd(a),
+		dynExportProtoCmd(a),
+	} {
+		cmd.AddCommand(withDefaultChainArg(a, c))
+	}
+	cmd.AddCommand(
+		dynCompareCmd(a),
+		dynReplayCmd(a),
+		withDefaultChainArg(a, dynSkeletonCmd(a)),
+		withDefaultChainArg(a, dynShowMessagesCmd(a)),
+		withDefaultChainArg(a, dynSchemaCmd(a)),
+		withDefaultChainArg(a, dynOpenAPICmd(a)),
+		dynCacheCmd(a),
 	)
 
 	return cmd
@@ -43,8 +69,6 @@ func dynamicCmd(a *appState) *cobra.Command {
 
 func dynQueryCmd(a *appState) *cobra.Command {
 	const stdinFlag = "stdin"
-	const heightFlag = "height"
-	var height int64
 
 	cmd := &cobra.Command{
 		Use:     "query CHAIN_NAME_OR_GRPC_ADDR SERVICE_NAME METHOD_NAME [INPUT_OBJECT|@PATH_TO_INPUT_FILE]",
@@ -75,8 +99,9 @@ $ %[1]s dynamic q my-chain cosmos.base.tendermint.v1beta1.Service GetBlockByHeig
 $ %[1]s dynamic q my-chain cosmos.base.tendermint.v1beta1.Service GetBlockByHeight @path/to/input.json
 $ echo '{"validator_address": "..."}' | %[1]s dyn q my-chain cosmos.distribution.v1beta1.Query ValidatorOutstandingRewards --stdin`,
 			appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgService, dynArgMethod),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			gRPCAddr, err := chooseGRPCAddr(a, args[0])
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
 			if err != nil {
 				return err
 			}
@@ -89,156 +114,877 @@ $ echo '{"validator_address": "..."}' | %[1]s dyn q my-chain cosmos.distribution
 				return fmt.Errorf("method name may not be empty")
 			}
 
-			var in []byte
-			if len(args) > 3 {
-				if strings.HasPrefix(args[3], "@") {
-					// @file format.
-					name := strings.TrimPrefix(args[3], "@")
-					in, err = os.ReadFile(name)
-					if err != nil {
-						return fmt.Errorf("failed to read input file: %w", err)
-					}
-				} else {
-					// Provided explicit value on command line.
-					in = []byte(args[3])
-				}
-			} else if useStdin, _ := cmd.Flags().GetBool(stdinFlag); useStdin {
-				// Provided --stdin.
-				in, err = io.ReadAll(cmd.InOrStdin())
-				if err != nil {
-					return fmt.Errorf("error reading from stdin: %w", err)
+			var in []byte
+			if len(args) > 3 {
+				if strings.HasPrefix(args[3], "@") {
+					// @file format.
+					name := strings.TrimPrefix(args[3], "@")
+					in, err = os.ReadFile(name)
+					if err != nil {
+						return fmt.Errorf("failed to read input file: %w", err)
+					}
+				} else {
+					// Provided explicit value on command line.
+					in = []byte(args[3])
+				}
+			} else if useStdin, _ := cmd.Flags().GetBool(stdinFlag); useStdin {
+				// Provided --stdin.
+				in, err = io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("error reading from stdin: %w", err)
+				}
+			} else {
+				// Didn't provide command line argument and didn't use --stdin.
+				// Default to empty object for input.
+				in = []byte("{}")
+			}
+			return dynamicQuery(cmd, a, gRPCAddr, serviceName, methodName, in)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().Bool(stdinFlag, false, "read input from stdin instead of as command-line argument")
+	return cmd
+}
+
+func dynamicQuery(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, methodName string, input []byte) error {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
+
+	verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
+	if err != nil {
+		return err
+	}
+
+	methodDesc, err := resolveMethod(c, serviceName, methodName, verbose)
+	if err != nil {
+		return err
+	}
+
+	var header metadata.MD
+	j, err := invokeDynamic(cmd.Context(), conn, c, methodDesc, input, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+	writeAnsweredHeight(cmd, header)
+
+	return writeOutput(cmd, a, json.RawMessage(j))
+}
+
+func dynCallCmd(a *appState) *cobra.Command {
+	const fileFlag = "file"
+
+	cmd := &cobra.Command{
+		Use:   "call CHAIN_NAME_OR_GRPC_ADDR FULLY_QUALIFIED_METHOD [INPUT_OBJECT|-]",
+		Short: "Invoke a unary gRPC method by its fully qualified name, using reflection to encode the JSON input",
+		Long: fmt.Sprintf(`Invoke a unary gRPC method against the remote server and print the response as JSON.
+
+The method is given as a single fully qualified name,
+using either a dot or a slash between the service and the method:
+    cosmos.bank.v1beta1.Query.AllBalances
+    cosmos.bank.v1beta1.Query/AllBalances
+
+The request body may be given as a command line argument,
+read from a file with the --%[2]s flag,
+or read from stdin by passing '-' as the input argument.
+If no input is given, the request defaults to an empty object.
+
+To see the shape of the request, use '%[1]s dynamic inspect'.
+`,
+			appName, fileFlag),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		Example: fmt.Sprintf(`$ %[1]s dynamic call cosmoshub cosmos.bank.v1beta1.Query.AllBalances '{"address":"cosmos1..."}'
+$ %[1]s dynamic call example.com:9090 cosmos.bank.v1beta1.Query/TotalSupply
+$ %[1]s dyn call cosmoshub cosmos.bank.v1beta1.Query.Balance --file my_account.json
+$ echo '{"height": 2222222}' | %[1]s dyn call cosmoshub cosmos.base.tendermint.v1beta1.Service.GetBlockByHeight -`,
+			appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgFullMethod),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			serviceName, methodName, err := grpcdynamic.SplitMethodName(args[1])
+			if err != nil {
+				return err
+			}
+
+			inFile, err := cmd.Flags().GetString(fileFlag)
+			if err != nil {
+				return err
+			}
+
+			var in []byte
+			switch {
+			case len(args) > 2 && inFile != "":
+				return fmt.Errorf("cannot provide both an input argument and the --%s flag", fileFlag)
+			case inFile != "":
+				in, err = os.ReadFile(inFile)
+				if err != nil {
+					return fmt.Errorf("failed to read input file: %w", err)
+				}
+			case len(args) > 2 && args[2] == "-":
+				in, err = io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("error reading from stdin: %w", err)
+				}
+			case len(args) > 2:
+				in = []byte(args[2])
+			default:
+				in = []byte("{}")
+			}
+
+			return dynamicCall(cmd, a, gRPCAddr, serviceName, methodName, in)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(fileFlag, "", "read the request body from the given file")
+	cmd.Flags().String(dynRecordFlag, "", "append the call, with its request, response, and headers other than secrets, to this newline delimited JSON file, for dynamic replay; a relative path is in the recordings directory of the home directory")
+	return cmd
+}
+
+func dynamicCall(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, methodName string, input []byte) error {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
+
+	verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
+	if err != nil {
+		return err
+	}
+
+	methodDesc, err := resolveMethod(c, serviceName, methodName, verbose)
+	if err != nil {
+		return err
+	}
+
+	recordFile, err := cmd.Flags().GetString(dynRecordFlag)
+	if err != nil {
+		return err
+	}
+	var recordHeaders metadata.MD
+	if recordFile != "" {
+		if recordHeaders, err = recordedGRPCHeaders(cmd, a, gRPCAddr); err != nil {
+			return err
+		}
+	}
+
+	a.Log.Debug("Invoking method", zap.String("method", methodDesc.GetFullyQualifiedName()))
+	var header metadata.MD
+	start := time.Now()
+	j, err := invokeDynamic(cmd.Context(), conn, c, methodDesc, input, grpc.Header(&header))
+	if recordFile != "" {
+		// Failed calls are recorded too, so that their replay tells whether they still fail.
+		rec := newDynamicCallRecord(gRPCAddr, methodDesc.GetFullyQualifiedName(), recordHeaders, input, j, start, err)
+		if recErr := appendDynamicCallRecord(recordingPath(a.HomePath, recordFile), rec); recErr != nil {
+			return fmt.Errorf("failed to record call: %w", recErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	writeAnsweredHeight(cmd, header)
+
+	// Unlike query, call indents its response in the text format.
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, j, "", "  "); err != nil {
+		return err
+	}
+	return writeOutput(cmd, a, json.RawMessage(indented.Bytes()))
+}
+
+// resolveMethod resolves the named method through the reflection client.
+// If the service or method cannot be found,
+// the returned error lists the available alternatives where possible.
+func resolveMethod(c grpcdynamic.DescriptorSource, serviceName, methodName string, verbose bool) (*desc.MethodDescriptor, error) {
+	if serviceName == "" {
+		return nil, fmt.Errorf("service name may not be empty")
+	}
+	if methodName == "" {
+		return nil, fmt.Errorf("method name may not be empty")
+	}
+
+	svcDesc, err := resolveService(c, serviceName, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	methodDesc := svcDesc.FindMethodByName(methodName)
+	if methodDesc == nil {
+		return nil, GRPCMethodNotFoundError{
+			TargetService: serviceName,
+			Requested:     methodName,
+			Available:     svcDesc.GetMethods(),
+		}
+	}
+
+	return methodDesc, nil
+}
+
+// resolveService returns the descriptor for serviceName,
+// or a GRPCServiceNotFoundError if the server does not offer it.
+// If verbose is set, that error lists every available service.
+func resolveService(c grpcdynamic.DescriptorSource, serviceName string, verbose bool) (*desc.ServiceDescriptor, error) {
+	svcDesc, err := c.ResolveService(serviceName)
+	if err != nil {
+		if grpcreflect.IsElementNotFoundError(err) {
+			// If we can list the available services, return a more useful error.
+			services, svcErr := c.ListServices()
+			if svcErr == nil {
+				return nil, GRPCServiceNotFoundError{
+					Requested: serviceName,
+					Available: services,
+					Verbose:   verbose,
+				}
+			}
+		}
+
+		return nil, fmt.Errorf("failed to resolve service %q: %w", serviceName, err)
+	}
+
+	return svcDesc, nil
+}
+
+// resolveMessage returns the descriptor of the message with the fully qualified name messageName,
+// or a GRPCMessageNotFoundError if the server does not know it.
+func resolveMessage(c grpcdynamic.DescriptorSource, messageName string) (*desc.MessageDescriptor, error) {
+	messageName = strings.TrimPrefix(messageName, ".")
+	msgDesc, err := c.ResolveMessage(messageName)
+	if err != nil {
+		if grpcreflect.IsElementNotFoundError(err) {
+			return nil, GRPCMessageNotFoundError{Requested: messageName}
+		}
+		return nil, fmt.Errorf("failed to resolve message %q: %w", messageName, err)
+	}
+
+	return msgDesc, nil
+}
+
+// invokeDynamic unmarshals the JSON input into the method's input type,
+// invokes the unary method over conn with the call options opts,
+// and returns the JSON serialization of the response.
+// Errors returned by the server are reported as a GRPCCallError,
+// or as a PrunedHeightError if the node no longer has the state of the requested height.
+func invokeDynamic(ctx context.Context, conn *grpc.ClientConn, c grpcdynamic.DescriptorSource, methodDesc *desc.MethodDescriptor, input []byte, opts ...grpc.CallOption) ([]byte, error) {
+	rc := grpcdynamic.NewReflectionClient(conn, grpcdynamic.WithDescriptorSource(c))
+	j, err := rc.InvokeMethod(ctx, methodDesc, input, opts...)
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			callErr := GRPCCallError{
+				Method:  methodDesc.GetFullyQualifiedName(),
+				Code:    st.Code(),
+				Message: st.Message(),
+			}
+			if pruned, ok := newPrunedHeightError(callErr, st); ok {
+				return nil, pruned
+			}
+			return nil, callErr
+		}
+		return nil, fmt.Errorf("failed to invoke rpc: %w", err)
+	}
+
+	return j, nil
+}
+
+// writeAnsweredHeight writes the block height of the state a query was answered from,
+// given by the block height header of the response, to the standard error of cmd,
+// as it may not be the requested height.
+func writeAnsweredHeight(cmd *cobra.Command, header metadata.MD) {
+	if heights := header.Get(grpctypes.GRPCBlockHeightHeader); len(heights) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Height: %s\n", heights[0])
+	}
+}
+
+func dynInspectCmd(a *appState) *cobra.Command {
+	const (
+		descriptorSetOutFlag = "descriptor-set-out"
+		fullFlag             = "full"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "inspect CHAIN_NAME_OR_GRPC_ADDR [SERVICE_NAME [METHOD_NAME]]",
+		Aliases: []string{"i"},
+		Short:   "Use gRPC reflection to see protobuf definitions of services or methods",
+		Args:    withUsage(cobra.RangeArgs(1, 3)),
+		Example: fmt.Sprintf(`$ %s dynamic inspect example.com:9090
+$ %s dynamic i my-chain
+$ %s dyn i my-chain --full
+$ %s dyn i my-chain cosmos.bank.v1beta1.Query TotalSupply
+$ %s dyn i my-chain --descriptor-set-out my-chain.protoset`,
+			appName, appName, appName, appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgService, dynArgMethod),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			var serviceName, methodName string
+			if len(args) > 1 {
+				serviceName = args[1]
+			}
+			if len(args) > 2 {
+				methodName = args[2]
+			}
+
+			a.Log.Debug("Inspecting server", zap.String("addr", gRPCAddr))
+
+			setOut, err := cmd.Flags().GetString(descriptorSetOutFlag)
+			if err != nil {
+				return err
+			}
+			if setOut != "" {
+				if methodName != "" {
+					return fmt.Errorf("--%s cannot be combined with a method name", descriptorSetOutFlag)
+				}
+				return dynamicWriteDescriptorSet(cmd, a, gRPCAddr, serviceName, setOut)
+			}
+
+			full, err := cmd.Flags().GetBool(fullFlag)
+			if err != nil {
+				return err
+			}
+			if full && serviceName != "" {
+				return fmt.Errorf("--%s cannot be combined with a service name", fullFlag)
+			}
+
+			return dynamicInspect(cmd, a, gRPCAddr, serviceName, methodName, full)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(descriptorSetOutFlag, "", "write a serialized FileDescriptorSet of all services (or of SERVICE_NAME) to the given file, for use with protoc --descriptor_set_in")
+	cmd.Flags().Bool(fullFlag, false, "print the full definition of every service, instead of only their names")
+	return cmd
+}
+
+// dynamicWriteDescriptorSet writes the FileDescriptorSet of the remote services to outPath.
+// If serviceName is empty, every service listed by the server is included.
+func dynamicWriteDescriptorSet(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, outPath string) error {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
+
+	files, err := resolveServiceFiles(a, c, serviceName)
+	if err != nil {
+		return err
+	}
+
+	set := fileDescriptorSet(files)
+	b, err := proto.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to serialize descriptor set: %w", err)
+	}
+
+	if err := os.WriteFile(outPath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write descriptor set: %w", err)
+	}
+
+	a.Log.Info(
+		"Wrote descriptor set",
+		zap.String("path", outPath),
+		zap.Int("file_count", len(set.File)),
+	)
+	return nil
+}
+
+// resolveServiceFiles returns the files declaring the named service,
+// or declaring every remote service if serviceName is empty.
+// Services that fail to resolve are logged and skipped when listing all services.
+func resolveServiceFiles(a *appState, c grpcdynamic.DescriptorSource, serviceName string) ([]*desc.FileDescriptor, error) {
+	svcDescs, err := resolveServices(a, c, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*desc.FileDescriptor, len(svcDescs))
+	for i, svcDesc := range svcDescs {
+		files[i] = svcDesc.GetFile()
+	}
+	return files, nil
+}
+
+// resolveServices returns the descriptor of the named service,
+// or of every remote service, sorted by name, if serviceName is empty.
+// Services that fail to resolve are logged and skipped when listing all services.
+func resolveServices(a *appState, c grpcdynamic.DescriptorSource, serviceName string) ([]*desc.ServiceDescriptor, error) {
+	services := []string{serviceName}
+	if serviceName == "" {
+		var err error
+		services, err = c.ListServices()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remote services: %w", err)
+		}
+		sort.Strings(services)
+	}
+
+	svcDescs := make([]*desc.ServiceDescriptor, 0, len(services))
+	for _, svc := range services {
+		svcDesc, err := c.ResolveService(svc)
+		if err != nil {
+			if serviceName != "" {
+				return nil, fmt.Errorf("failed to resolve service %q: %w", svc, err)
+			}
+			a.Log.Info(
+				"Error resolving service",
+				zap.String("service_name", svc),
+				zap.Error(err),
+			)
+			continue
+		}
+		svcDescs = append(svcDescs, svcDesc)
+	}
+
+	return svcDescs, nil
+}
+
+// transitiveFiles returns files and all of their transitive imports,
+// de-duplicated by file name.
+// Imports always precede the files that depend on them.
+func transitiveFiles(files []*desc.FileDescriptor) []*desc.FileDescriptor {
+	var out []*desc.FileDescriptor
+	seen := make(map[string]bool)
+
+	var add func(fd *desc.FileDescriptor)
+	add = func(fd *desc.FileDescriptor) {
+		if seen[fd.GetName()] {
+			return
+		}
+		seen[fd.GetName()] = true
+
+		for _, dep := range fd.GetDependencies() {
+			add(dep)
+		}
+		out = append(out, fd)
+	}
+
+	for _, fd := range files {
+		add(fd)
+	}
+
+	return out
+}
+
+// fileDescriptorSet returns a FileDescriptorSet containing files
+// and all of their transitive imports, in the dependency-first order
+// that protoc expects from --descriptor_set_in.
+func fileDescriptorSet(files []*desc.FileDescriptor) *descriptorpb.FileDescriptorSet {
+	all := transitiveFiles(files)
+	set := &descriptorpb.FileDescriptorSet{
+		File: make([]*descriptorpb.FileDescriptorProto, len(all)),
+	}
+	for i, fd := range all {
+		set.File[i] = fd.AsFileDescriptorProto()
+	}
+	return set
+}
+
+func dynExportProtoCmd(a *appState) *cobra.Command {
+	const (
+		outFlag       = "out"
+		serviceFlag   = "service"
+		overwriteFlag = "overwrite"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-proto CHAIN_NAME_OR_GRPC_ADDR",
+		Short: "Use gRPC reflection to write the remote .proto files to a directory",
+		Long: `Use gRPC reflection to reconstruct the .proto files of a remote gRPC server.
+
+Each file, along with every file it imports, is written to its declared path
+under the output directory, so that the resulting tree can be compiled with protoc.
+Files that already exist with identical content are skipped;
+files that exist with different content are an error unless --overwrite is set.`,
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: fmt.Sprintf(`$ %s dynamic export-proto example.com:9090 --out ./protos
+$ %s dyn export-proto my-chain --out ./protos --service cosmos.bank.v1beta1.Query`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			outDir, err := cmd.Flags().GetString(outFlag)
+			if err != nil {
+				return err
+			}
+			serviceName, err := cmd.Flags().GetString(serviceFlag)
+			if err != nil {
+				return err
+			}
+			overwrite, err := cmd.Flags().GetBool(overwriteFlag)
+			if err != nil {
+				return err
+			}
+
+			return dynamicExportProto(cmd, a, gRPCAddr, serviceName, outDir, overwrite)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(outFlag, ".", "directory to write .proto files into")
+	cmd.Flags().String(serviceFlag, "", "only export the files needed by this fully qualified service name")
+	cmd.Flags().Bool(overwriteFlag, false, "replace existing files whose content differs")
+	return cmd
+}
+
+func dynamicExportProto(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, outDir string, overwrite bool) error {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
+
+	files, err := resolveServiceFiles(a, c, serviceName)
+	if err != nil {
+		return err
+	}
+
+	pp := &protoprint.Printer{
+		SortElements: true,
+	}
+
+	var written int
+	for _, fd := range transitiveFiles(files) {
+		dst, err := protoFilePath(outDir, fd.GetName())
+		if err != nil {
+			return err
+		}
+
+		src, err := pp.PrintProtoToString(fd)
+		if err != nil {
+			return fmt.Errorf("failed to print %s: %w", fd.GetName(), err)
+		}
+
+		existing, err := os.ReadFile(dst)
+		switch {
+		case err == nil && string(existing) == src:
+			a.Log.Debug("Skipping unchanged file", zap.String("path", dst))
+			continue
+		case err == nil && !overwrite:
+			return fmt.Errorf("refusing to overwrite %s with different content (use --overwrite)", dst)
+		case err != nil && !os.IsNotExist(err):
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(src), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+		written++
+
+		a.Log.Debug("Wrote proto file", zap.String("path", dst))
+	}
+
+	a.Log.Info(
+		"Exported proto files",
+		zap.String("dir", outDir),
+		zap.Int("written", written),
+	)
+	return nil
+}
+
+// protoFilePath returns the location under outDir for the proto file
+// with the given declared name, rejecting names that would escape outDir.
+func protoFilePath(outDir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("remote file name %q is outside the output directory", name)
+	}
+	return filepath.Join(outDir, clean), nil
+}
+
+func dynListServicesCmd(a *appState) *cobra.Command {
+	const longFlag = "long"
+
+	cmd := &cobra.Command{
+		Use:     "list-services CHAIN_NAME_OR_GRPC_ADDR",
+		Aliases: []string{"ls"},
+		Short:   "Use gRPC reflection to list fully qualified service names",
+		Long: `Use gRPC reflection to list the fully qualified names of the server's services, one per line.
+
+With --long, each service is resolved to also show its number of methods
+and the .proto file declaring it.
+Services that cannot be resolved are shown as <unresolvable>.`,
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: fmt.Sprintf(`$ %s dynamic list-services example.com:9090
+$ %s dyn ls my-chain -l
+$ %s dyn ls my-chain -l -o json`,
+			appName, appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			long, err := cmd.Flags().GetBool(longFlag)
+			if err != nil {
+				return err
+			}
+
+			return dynamicListServices(cmd, a, gRPCAddr, long)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().BoolP(longFlag, "l", false, "show the number of methods and the source file of each service")
+	return cmd
+}
+
+func dynamicListServices(cmd *cobra.Command, a *appState, gRPCAddr string, long bool) error {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
+
+	services, err := c.ListServices()
+	if err != nil {
+		return fmt.Errorf("failed to list remote services: %w", err)
+	}
+	sort.Strings(services)
+
+	if !long {
+		return writeOutput(cmd, a, services)
+	}
+
+	summaries := make(serviceSummaries, len(services))
+	for i, svc := range services {
+		summaries[i].Name = svc
+
+		svcDesc, err := c.ResolveService(svc)
+		if err != nil {
+			a.Log.Warn(
+				"Error resolving service",
+				zap.String("service_name", svc),
+				zap.Error(err),
+			)
+			summaries[i].Unresolvable = true
+			continue
+		}
+
+		summaries[i].Methods = len(svcDesc.GetMethods())
+		summaries[i].File = svcDesc.GetFile().GetFullyQualifiedName()
+	}
+
+	return writeOutput(cmd, a, summaries)
+}
+
+// serviceSummary describes a remote service for list-services --long.
+type serviceSummary struct {
+	Name    string `json:"name"`
+	Methods int    `json:"methods"`
+	File    string `json:"file"`
+
+	// Unresolvable is set if the service was listed by the server
+	// but its descriptor could not be resolved,
+	// in which case Methods and File are unset.
+	Unresolvable bool `json:"unresolvable,omitempty"`
+}
+
+type serviceSummaries []serviceSummary
+
+var _ fmt.Stringer = serviceSummaries(nil)
+
+// String returns the summaries as a table with aligned columns.
+func (ss serviceSummaries) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tMETHODS\tFILE")
+	for _, s := range ss {
+		if s.Unresolvable {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, unresolvable, unresolvable)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", s.Name, s.Methods, s.File)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// unresolvable is displayed in place of details that could not be resolved.
+const unresolvable = "<unresolvable>"
+
+func dynListMethodsCmd(a *appState) *cobra.Command {
+	const filterFlag = "filter"
+
+	cmd := &cobra.Command{
+		Use:     "list-methods CHAIN_NAME_OR_GRPC_ADDR [SERVICE_NAME]",
+		Aliases: []string{"lm"},
+		Short:   "Use gRPC reflection to list fully qualified method names",
+		Long: `Use gRPC reflection to list the fully qualified names of the methods of SERVICE_NAME,
+or of every service if SERVICE_NAME is omitted, one per line.
+
+The --filter flag restricts the output to method names containing the given substring,
+or, if the filter contains any of the characters *?[, matching the given glob.`,
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		Example: fmt.Sprintf(`$ %s dynamic list-methods example.com:9090
+$ %s dyn lm my-chain cosmos.bank.v1beta1.Query
+$ %s dyn lm my-chain --filter 'cosmos.*.Query.Params'`,
+			appName, appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgService),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			var serviceName string
+			if len(args) > 1 {
+				serviceName = args[1]
+			}
+
+			filter, err := cmd.Flags().GetString(filterFlag)
+			if err != nil {
+				return err
+			}
+			if isGlob(filter) {
+				// Validate the pattern up front, so that a bad pattern is not silently treated as no match.
+				if _, err := path.Match(filter, ""); err != nil {
+					return fmt.Errorf("invalid --%s pattern %q: %w", filterFlag, filter, err)
 				}
-			} else {
-				// Didn't provide command line argument and didn't use --stdin.
-				// Default to empty object for input.
-				in = []byte("{}")
 			}
-			return dynamicQuery(cmd, a, gRPCAddr, serviceName, methodName, in, height)
+
+			return dynamicListMethods(cmd, a, gRPCAddr, serviceName, filter)
 		},
 	}
 
 	cmd = gRPCFlags(cmd, a.Viper)
-	cmd.Flags().Bool(stdinFlag, false, "read input from stdin instead of as command-line argument")
-	cmd.Flags().Int64Var(&height, heightFlag, 0, "specify the height for the query or use latest")
+	cmd.Flags().String(filterFlag, "", "only list methods whose fully qualified name contains this substring or matches this glob")
 	return cmd
 }
 
-func dynamicQuery(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, methodName string, input []byte, height int64) error {
+func dynamicListMethods(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, filter string) error {
 	conn, err := dialGRPC(cmd, a, gRPCAddr)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	stub := rpb.NewServerReflectionClient(conn)
-	c := grpcreflect.NewClient(cmd.Context(), stub)
-	defer c.Reset()
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
 
-	svcDesc, err := c.ResolveService(serviceName)
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
 	if err != nil {
-		if grpcreflect.IsElementNotFoundError(err) {
-			// If we can list the available services, return a more useful error.
-			services, svcErr := c.ListServices()
-			if svcErr == nil {
-				return GRPCServiceNotFoundError{
-					Requested: serviceName,
-					Available: services,
-				}
-			}
-		}
-
-		return fmt.Errorf("failed to resolve service %q: %w", serviceName, err)
-	}
-
-	methodDesc := svcDesc.FindMethodByName(methodName)
-	if methodDesc == nil {
-		return GRPCMethodNotFoundError{
-			TargetService: serviceName,
-			Requested:     methodName,
-			Available:     svcDesc.GetMethods(),
-		}
-	}
-
-	inMsgDesc := methodDesc.GetInputType() // TODO: check for nil input type?
-	inputMsg := dynamic.NewMessage(inMsgDesc)
-
-	if err := inputMsg.UnmarshalJSON(input); err != nil {
-		return fmt.Errorf("failed to marshal input into message of type %s: %w", inMsgDesc.GetFullyQualifiedName(), err)
-	}
-
-	dynClient := grpcdynamic.NewStub(conn)
-	if methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming() {
-		return fmt.Errorf("TODO: handle client/server streaming")
+		return err
 	}
 
-	md := metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
-	ctx := metadata.NewOutgoingContext(cmd.Context(), md)
-	output, err := dynClient.InvokeRpc(ctx, methodDesc, inputMsg)
+	verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
 	if err != nil {
-		return fmt.Errorf("failed to invoke rpc: %w", err)
+		return err
 	}
 
-	// Convert to a dynamic message, so that we can use the AnyResolver
-	// based on the client that can resolve not-yet-known messages.
-	dynOutput, err := dynamic.AsDynamicMessage(output)
-	if err != nil {
-		return fmt.Errorf("failed to convert output to dynamic message: %w", err)
-	}
-	j, err := dynOutput.MarshalJSONPB(&jsonpb.Marshaler{
-		// For Any fields, resolve through the client.
-		AnyResolver: reflectClientAnyResolver{c: c},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to serialize output message: %w", err)
+	var svcDescs []*desc.ServiceDescriptor
+	if serviceName != "" {
+		svcDesc, err := resolveService(c, serviceName, verbose)
+		if err != nil {
+			return err
+		}
+		svcDescs = append(svcDescs, svcDesc)
+	} else {
+		svcDescs, err = resolveServices(a, c, "")
+		if err != nil {
+			return err
+		}
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), string(j))
-	return nil
-}
-
-func dynInspectCmd(a *appState) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:     "inspect CHAIN_NAME_OR_GRPC_ADDR [SERVICE_NAME [METHOD_NAME]]",
-		Aliases: []string{"i"},
-		Short:   "Use gRPC reflection to see protobuf definitions of services or methods",
-		Args:    withUsage(cobra.RangeArgs(1, 3)),
-		Example: fmt.Sprintf(`$ %s dynamic inspect example.com:9090
-$ %s dynamic i my-chain
-$ %s dyn i my-chain cosmos.bank.v1beta1.Query TotalSupply`,
-			appName, appName, appName),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			gRPCAddr, err := chooseGRPCAddr(a, args[0])
-			if err != nil {
-				return err
+	methods := []string{}
+	for _, svcDesc := range svcDescs {
+		for _, m := range svcDesc.GetMethods() {
+			name := m.GetFullyQualifiedName()
+			if matchesFilter(name, filter) {
+				methods = append(methods, name)
 			}
+		}
+	}
 
-			var serviceName, methodName string
-			if len(args) > 1 {
-				serviceName = args[1]
-			}
-			if len(args) > 2 {
-				methodName = args[2]
-			}
+	return writeOutput(cmd, a, methods)
+}
 
-			a.Log.Debug("Inspecting server", zap.String("addr", gRPCAddr))
+// isGlob reports whether filter should be interpreted as a glob pattern rather than a substring.
+func isGlob(filter string) bool {
+	return strings.ContainsAny(filter, "*?[")
+}
 
-			return dynamicInspect(cmd, a, gRPCAddr, serviceName, methodName)
-		},
+// matchesFilter reports whether name contains filter,
+// or matches it if filter is a glob pattern.
+// An empty filter matches every name.
+func matchesFilter(name, filter string) bool {
+	if isGlob(filter) {
+		ok, _ := path.Match(filter, name)
+		return ok
 	}
-
-	return gRPCFlags(cmd, a.Viper)
+	return strings.Contains(name, filter)
 }
 
-func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, methodName string) error {
+func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, methodName string, full bool) error {
 	conn, err := dialGRPC(cmd, a, gRPCAddr)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	stub := rpb.NewServerReflectionClient(conn)
-	c := grpcreflect.NewClient(cmd.Context(), stub)
-	defer c.Reset()
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return err
+	}
 
 	pp := &protoprint.Printer{
 		SortElements:             true,
@@ -253,6 +999,8 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 			return fmt.Errorf("failed to list remote services: %w", err)
 		}
 
+		names := make([]string, 0, len(services))
+		var defs protoDefinitions
 		for _, svc := range services {
 			svcDesc, err := c.ResolveService(svc)
 			if err != nil {
@@ -263,10 +1011,26 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 				)
 				continue
 			}
-			fmt.Fprintln(cmd.OutOrStdout(), svcDesc.GetFullyQualifiedName())
+			names = append(names, svcDesc.GetFullyQualifiedName())
+
+			if full {
+				proto, err := pp.PrintProtoToString(svcDesc)
+				if err != nil {
+					return fmt.Errorf("failed to print service %q: %w", svc, err)
+				}
+				defs = append(defs, newProtoDefinition(svcDesc, proto))
+			}
+		}
+
+		if full {
+			return writeOutput(cmd, a, defs)
 		}
+		return writeOutput(cmd, a, names)
+	}
 
-		return nil
+	verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
+	if err != nil {
+		return err
 	}
 
 	a.Log.Debug("Resolving requested service", zap.String("service_name", serviceName))
@@ -280,6 +1044,7 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 				return GRPCServiceNotFoundError{
 					Requested: serviceName,
 					Available: services,
+					Verbose:   verbose,
 				}
 			}
 		}
@@ -301,9 +1066,8 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 			)
 			return err
 		}
-		fmt.Fprintln(cmd.OutOrStdout(), proto)
 
-		return nil
+		return writeOutput(cmd, a, protoDefinitions{newProtoDefinition(svcDesc, proto)})
 	}
 
 	a.Log.Debug("Resolving requested method", zap.String("service_name", serviceName), zap.String("method_name", methodName))
@@ -326,9 +1090,9 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 		)
 		return err
 	}
-	fmt.Fprintln(cmd.OutOrStdout(), proto)
+	defs := protoDefinitions{newProtoDefinition(mDesc, proto)}
 
-	var s sources
+	var walked []*desc.MessageDescriptor
 	if inType := mDesc.GetInputType(); inType != nil {
 		proto, err := pp.PrintProtoToString(inType)
 		if err != nil {
@@ -339,10 +1103,9 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 				zap.Error(err),
 			)
 		} else {
-			fmt.Fprintln(cmd.OutOrStdout(), "// "+inType.GetFile().GetFullyQualifiedName())
-			fmt.Fprintln(cmd.OutOrStdout(), proto)
+			defs = append(defs, newProtoDefinition(inType, proto))
 
-			s = walkMessageType(inType, s)
+			walked = append(walked, inType)
 		}
 	}
 
@@ -356,36 +1119,62 @@ func dynamicInspect(cmd *cobra.Command, a *appState, gRPCAddr, serviceName, meth
 				zap.Error(err),
 			)
 		} else {
-			fmt.Fprintln(cmd.OutOrStdout(), "// "+outType.GetFile().GetFullyQualifiedName())
-			fmt.Fprintln(cmd.OutOrStdout(), proto)
+			defs = append(defs, newProtoDefinition(outType, proto))
 
-			s = walkMessageType(outType, s)
+			walked = append(walked, outType)
 		}
 	}
 
-	s.Print(a.Log, cmd.OutOrStdout(), pp)
+	deps := sources(grpcdynamic.CollectDependencies(walked...))
+	defs = append(defs, deps.Definitions(a.Log, pp)...)
 
-	return nil
+	return writeOutput(cmd, a, defs)
 }
 
-// sources is a collection of descriptors.
-// It is a slice so iteration order when printing is maintained.
-type sources []desc.Descriptor
+// protoDefinition is the protobuf source of a single descriptor.
+type protoDefinition struct {
+	Name       string `json:"name"`
+	File       string `json:"file"`
+	Definition string `json:"definition"`
+}
 
-// Contains iterates through the existing sources
-// and reports whether it already contains a descriptor matching d's fully qualified name.
-func (s sources) Contains(d desc.Descriptor) bool {
-	want := d.GetFullyQualifiedName()
-	for _, have := range s {
-		if have.GetFullyQualifiedName() == want {
-			return true
-		}
+func newProtoDefinition(d desc.Descriptor, proto string) protoDefinition {
+	return protoDefinition{
+		Name:       d.GetFullyQualifiedName(),
+		File:       d.GetFile().GetFullyQualifiedName(),
+		Definition: proto,
 	}
+}
+
+// protoDefinitions is the result of inspecting a service or method:
+// the inspected descriptor followed by the descriptors it references.
+type protoDefinitions []protoDefinition
 
-	return false
+var _ fmt.Stringer = protoDefinitions(nil)
+
+// String returns the definitions as protobuf source.
+// Every definition after the first is preceded by a comment
+// with its fully qualified name and filename.
+func (defs protoDefinitions) String() string {
+	var b strings.Builder
+	for i, d := range defs {
+		if i > 0 {
+			fmt.Fprintf(&b, "// %s (%s)\n", d.Name, d.File)
+		}
+		b.WriteString(d.Definition)
+		b.WriteByte('\n')
+	}
+	return b.String()
 }
 
-func (s sources) Print(log *zap.Logger, out io.Writer, pp *protoprint.Printer) {
+// sources is a collection of descriptors.
+// It is a slice so iteration order when printing is maintained.
+type sources []desc.Descriptor
+
+// Definitions returns the protobuf source of each descriptor in s.
+// Descriptors that cannot be printed are logged and omitted.
+func (s sources) Definitions(log *zap.Logger, pp *protoprint.Printer) protoDefinitions {
+	defs := make(protoDefinitions, 0, len(s))
 	for _, desc := range s {
 		proto, err := pp.PrintProtoToString(desc)
 		if err != nil {
@@ -397,90 +1186,358 @@ func (s sources) Print(log *zap.Logger, out io.Writer, pp *protoprint.Printer) {
 			continue
 		}
 
-		fmt.Fprintf(out, "// %s (%s)\n", desc.GetFullyQualifiedName(), desc.GetFile().GetFullyQualifiedName())
-		fmt.Fprintln(out, proto)
+		defs = append(defs, newProtoDefinition(desc, proto))
 	}
+	return defs
 }
 
-func walkMessageType(msgDesc *desc.MessageDescriptor, s sources) sources {
-	for _, fDesc := range msgDesc.GetFields() {
-		if mDesc := fDesc.GetMessageType(); mDesc != nil {
-			if !s.Contains(mDesc) {
-				s = append(s, mDesc)
-				s = walkMessageType(mDesc, s)
-			}
-
-			continue
-		}
-
-		if eDesc := fDesc.GetEnumType(); eDesc != nil {
-			if !s.Contains(eDesc) {
-				s = append(s, eDesc)
-				// Enums are just lists of constants, so no need to descend into them.
-			}
+func dialGRPC(cmd *cobra.Command, a *appState, addr string) (*grpc.ClientConn, error) {
+	requireSecure, err := cmd.Flags().GetBool(gRPCSecureOnlyFlag)
+	if err != nil {
+		return nil, err
+	}
+	chain := chainForGRPCAddr(a, addr)
+	tlsConfig, err := tlsConfigFromFlags(cmd, chain)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := gRPCHeadersFromFlags(cmd, chain)
+	if err != nil {
+		return nil, err
+	}
+	tuning, err := gRPCTuningFromFlags(cmd, chain)
+	if err != nil {
+		return nil, err
+	}
 
-			continue
-		}
+	target, proxyOpts, err := client.GRPCProxyDialOptions(addr, gRPCProxy(a, chain))
+	if err != nil {
+		return nil, err
 	}
 
-	return s
-}
+	limit, err := gRPCRateLimit(a, chain)
+	if err != nil {
+		return nil, err
+	}
 
-func dialGRPC(cmd *cobra.Command, a *appState, addr string) (*grpc.ClientConn, error) {
-	requireSecure, err := cmd.Flags().GetBool(gRPCSecureOnlyFlag)
+	retry, err := gRPCRetry(a, chain)
 	if err != nil {
 		return nil, err
 	}
-	var dialOpts []grpc.DialOption
-	if !requireSecure {
+
+	// The retrier and rate limiter are outermost, so that the metrics record each call they retry,
+	// and each retry is paced.
+	dialOpts := append(client.NewRetrier(a.Log, retry).GRPCDialOptions(addr), client.NewRateLimiter(a.Log, limit).GRPCDialOptions(addr)...)
+	dialOpts = append(dialOpts, a.Metrics.GRPCDialOptions()...)
+	dialOpts = append(dialOpts, client.GRPCHeadersDialOptions(headers)...)
+	// The tracer is inside the retrier and the headers, so that it logs each attempt, with the headers sent.
+	dialOpts = append(dialOpts, a.Tracer.GRPCDialOptions()...)
+	dialOpts = append(dialOpts, proxyOpts...)
+	dialOpts = append(dialOpts, tuning.DialOptions()...)
+	dialOpts = append(dialOpts, maxRecvMsgSizeHintDialOptions()...)
+	switch {
+	case tlsConfig != nil:
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	case !requireSecure:
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	a.Log.Debug("Opening remote gRPC connection", zap.String("addr", addr))
-	conn, err := grpc.DialContext(cmd.Context(), addr, dialOpts...)
+	timeout, err := cmd.Flags().GetDuration(gRPCTimeoutFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	a.Log.Debug("Opening remote gRPC connection", zap.String("addr", addr), zap.Duration("timeout", timeout))
+	conn, err := grpcdynamic.Dial(ctx, target, dialOpts...)
 	if err != nil {
 		if requireSecure && strings.Contains(err.Error(), "grpc: no transport security set") {
 			// Have to use string matching for unexported grpc.errNoTransportSecurity error value.
 			a.Log.Warn("Refusing to connect to non-TLS server when --" + gRPCSecureOnlyFlag + " flag set")
 		}
-		return nil, fmt.Errorf("failed to dial gRPC address %q: %w", addr, err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ConnectionError{Addr: addr, Err: fmt.Errorf("failed to connect to %s within %s: %w", addr, timeout, err)}
+		}
+		return nil, ConnectionError{Addr: addr, Err: fmt.Errorf("failed to dial gRPC address %q: %w", addr, err)}
 	}
 
 	return conn, nil
 }
 
-type reflectClientAnyResolver struct {
-	c *grpcreflect.Client
-}
+// gRPCHeadersFromFlags returns the headers to send with every call:
+// those of the chain's grpc-headers setting if chain is not nil, overridden by the --header flags,
+// and by the block height header of the --height flag.
+func gRPCHeadersFromFlags(cmd *cobra.Command, chain *client.ChainClientConfig) (metadata.MD, error) {
+	pairs, err := cmd.Flags().GetStringArray(gRPCHeaderFlag)
+	if err != nil {
+		return nil, err
+	}
+	flagHeaders, err := client.ParseGRPCHeaders(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", gRPCHeaderFlag, err)
+	}
+	height, err := cmd.Flags().GetInt64(gRPCHeightFlag)
+	if err != nil {
+		return nil, err
+	}
+	if height < 0 {
+		return nil, fmt.Errorf("invalid --%s: must not be negative", gRPCHeightFlag)
+	}
 
-var _ jsonpb.AnyResolver = reflectClientAnyResolver{}
+	headers := make(map[string]string)
+	if chain != nil {
+		for k, v := range chain.GRPCHeaders {
+			headers[strings.ToLower(k)] = v
+		}
+	}
+	for k, v := range flagHeaders {
+		headers[k] = v
+	}
+	if height > 0 {
+		headers[grpctypes.GRPCBlockHeightHeader] = strconv.FormatInt(height, 10)
+	}
+	return client.ResolveGRPCHeaders(headers)
+}
 
-func (r reflectClientAnyResolver) Resolve(typeURL string) (proto.Message, error) {
-	// Unclear if it is always safe to trim the leading slash here.
-	typeURL = strings.TrimPrefix(typeURL, "/")
-	messageDesc, err := r.c.ResolveMessage(typeURL)
+// tlsConfigFromFlags returns the TLS configuration described by the --tls flags,
+// or nil if the connection should not use TLS.
+//
+// If chain is not nil and none of the --tls-* flags were set,
+// TLS is enabled according to the chain's grpc-tls and grpc-tls-ca-file settings.
+// An explicit --tls flag overrides both.
+func tlsConfigFromFlags(cmd *cobra.Command, chain *client.ChainClientConfig) (*tls.Config, error) {
+	caFile, err := cmd.Flags().GetString(gRPCTLSCAFlag)
+	if err != nil {
+		return nil, err
+	}
+	certFile, err := cmd.Flags().GetString(gRPCTLSCertFlag)
 	if err != nil {
 		return nil, err
 	}
+	keyFile, err := cmd.Flags().GetString(gRPCTLSKeyFlag)
+	if err != nil {
+		return nil, err
+	}
+	serverName, err := cmd.Flags().GetString(gRPCTLSServerFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsFlagsSet := caFile != "" || certFile != "" || keyFile != "" || serverName != ""
+	enabled := tlsFlagsSet
+	if chain != nil && !tlsFlagsSet {
+		enabled = chain.GRPCTLS
+		caFile = chain.GRPCTLSCAFile
+	}
+
+	if cmd.Flags().Changed(gRPCTLSFlag) {
+		enabled, err = cmd.Flags().GetBool(gRPCTLSFlag)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled && tlsFlagsSet {
+			return nil, fmt.Errorf("--%s=false cannot be combined with the other --tls-* flags", gRPCTLSFlag)
+		}
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("--%s and --%s must be provided together", gRPCTLSCertFlag, gRPCTLSKeyFlag)
+	}
+
+	return newTLSConfig(caFile, certFile, keyFile, serverName)
+}
+
+// newTLSConfig returns a client TLS configuration verifying the server against the CA certificates in caFile,
+// or against the system pool if caFile is empty.
+// If certFile and keyFile are not empty, the client presents that certificate to the server.
+func newTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %q", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// gRPCProxy returns the proxy setting of the connections to the gRPC endpoints of chain,
+// which may be nil for an endpoint of no configured chain: that of --proxy if set, or else that of the chain.
+func gRPCProxy(a *appState, chain *client.ChainClientConfig) string {
+	if a.Proxy != "" || chain == nil {
+		return a.Proxy
+	}
+	return chain.Proxy
+}
+
+// gRPCRateLimit returns the rate limit of the calls to the gRPC endpoints of chain,
+// which may be nil for an endpoint of no configured chain: that of --rate-limit if set, or else that of the chain.
+func gRPCRateLimit(a *appState, chain *client.ChainClientConfig) (client.RateLimit, error) {
+	c := client.ChainClientConfig{RateLimit: a.RateLimit}
+	if chain != nil {
+		c = *chain
+		if a.RateLimit != "" {
+			c.RateLimit = a.RateLimit
+		}
+	}
+	return c.RateLimiting()
+}
+
+// gRPCRetry returns the retries of the calls to the gRPC endpoints of chain,
+// which may be nil for an endpoint of no configured chain: those of --retries if set, or else those of the chain.
+func gRPCRetry(a *appState, chain *client.ChainClientConfig) (client.Retry, error) {
+	var c client.ChainClientConfig
+	if chain != nil {
+		c = *chain
+	}
+	if a.Retries != nil {
+		c.Retries = *a.Retries
+		if c.Retries == 0 {
+			c.Retries = -1
+		}
+	}
+	return c.Retrying()
+}
+
+// chainForGRPCAddr returns the configuration of the chain with addr among its gRPC endpoints,
+// or nil if there is no such chain.
+// If several chains share the address, the first by name is returned.
+func chainForGRPCAddr(a *appState, addr string) *client.ChainClientConfig {
+	names := make([]string, 0, len(a.Config.Chains))
+	for name := range a.Config.Chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		chain := a.Config.Chains[name]
+		if stringsContain(chain.GRPCEndpoints(), addr) {
+			return chain
+		}
+	}
+	return nil
+}
+
+// withDefaultChainArg lets the leading CHAIN_NAME_OR_GRPC_ADDR argument of cmd be omitted,
+// in favor of the default chain.
+// The argument is taken to be omitted if it is neither a gRPC address nor a configured chain,
+// and the other arguments are valid without it.
+func withDefaultChainArg(a *appState, cmd *cobra.Command) *cobra.Command {
+	validate, run := cmd.Args, cmd.RunE
+	cmd.Use = strings.Replace(cmd.Use, " CHAIN_NAME_OR_GRPC_ADDR", " [CHAIN_NAME_OR_GRPC_ADDR]", 1)
+
+	// Arguments are validated before the configuration is read,
+	// so they are accepted either with or without the chain argument.
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		err := validate(cmd, args)
+		if err != nil && validate(cmd, append([]string{""}, args...)) == nil {
+			return nil
+		}
+		return err
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && isChainOrGRPCAddr(a, args[0]) {
+			if err := validate(cmd, args); err != nil {
+				return err
+			}
+			return run(cmd, args)
+		}
+
+		withDefault := append([]string{a.Config.DefaultChain}, args...)
+		if err := validate(cmd, withDefault); err != nil {
+			if len(args) == 0 {
+				return err
+			}
+			// The arguments are only valid with the chain argument, which does not name a chain.
+			_, err := chooseGRPCAddr(cmd, a, args[0])
+			return err
+		}
+		if a.Config.DefaultChain == "" {
+			return NoDefaultChainError{}
+		}
+		a.Log.Debug("No chain argument given; using default chain", zap.String("chain", a.Config.DefaultChain))
+		return run(cmd, withDefault)
+	}
+
+	return cmd
+}
 
-	return dynamic.NewMessage(messageDesc), nil
+// isChainOrGRPCAddr reports whether s is a gRPC address, or looks like a URL, or names a configured chain.
+func isChainOrGRPCAddr(a *appState, s string) bool {
+	if client.ValidateGRPCAddr(s) == nil || strings.Contains(s, "://") {
+		return true
+	}
+	_, ok := a.Config.Chains[s]
+	return ok
 }
 
-func chooseGRPCAddr(a *appState, addrOrChainName string) (string, error) {
-	if _, _, err := net.SplitHostPort(addrOrChainName); err == nil {
-		// Argument looks like a host:port, so just return that value.
+// chooseGRPCAddr returns addrOrChainName if it is a gRPC address, in one of the forms of client.ValidateGRPCAddr,
+// or else the first reachable gRPC endpoint of the chain by that name,
+// restricted to the endpoint selected by --endpoint if set.
+func chooseGRPCAddr(cmd *cobra.Command, a *appState, addrOrChainName string) (string, error) {
+	addrErr := client.ValidateGRPCAddr(addrOrChainName)
+	if addrErr == nil {
+		// Argument is a gRPC address, so just return that value.
 		return addrOrChainName, nil
 	}
+	if strings.Contains(addrOrChainName, "://") {
+		// No chain name looks like a URL.
+		return "", fmt.Errorf("invalid gRPC address %q: %w", addrOrChainName, addrErr)
+	}
 
 	chain, ok := a.Config.Chains[addrOrChainName]
 	if !ok {
 		return "", fmt.Errorf("%q did not look like host:port and no chain exists by that name", addrOrChainName)
 	}
 
-	gRPCAddr := chain.GRPCAddr
-	if gRPCAddr == "" {
+	if a.Endpoint != "" {
+		var err error
+		chain, err = chain.WithEndpoint(a.Endpoint)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	gRPCAddrs := chain.GRPCEndpoints()
+	if len(gRPCAddrs) == 0 {
 		return "", fmt.Errorf("no gRPC address set for chain %q", addrOrChainName)
 	}
 
+	gRPCAddr, err := client.SelectEndpointThroughProxy(cmd.Context(), gRPCAddrs, gRPCProxy(a, chain))
+	if err != nil {
+		return "", ConnectionError{
+			Addr: strings.Join(gRPCAddrs, ","),
+			Err:  fmt.Errorf("no gRPC endpoint of chain %q is reachable: %w", addrOrChainName, err),
+		}
+	}
+	if gRPCAddr != gRPCAddrs[0] {
+		a.Log.Debug("Using fallback gRPC endpoint", zap.String("chain", addrOrChainName), zap.String("addr", gRPCAddr))
+	}
+
 	return gRPCAddr, nil
 }
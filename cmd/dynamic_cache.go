@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/byop"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	_ grpcdynamic.DescriptorSource = (*cachedDescriptorSource)(nil)
+	_ grpcdynamic.DescriptorSource = retryingDescriptorSource{}
+)
+
+// retryingDescriptorSource wraps a reflection client,
+// retrying requests that fail because the server is unavailable.
+type retryingDescriptorSource struct {
+	ctx      context.Context
+	log      *zap.Logger
+	c        *grpcreflect.Client
+	attempts uint
+}
+
+func (r retryingDescriptorSource) ListServices() (services []string, err error) {
+	err = r.do(func() (err error) {
+		services, err = r.c.ListServices()
+		return err
+	})
+	return services, err
+}
+
+func (r retryingDescriptorSource) ResolveService(serviceName string) (svcDesc *desc.ServiceDescriptor, err error) {
+	err = r.do(func() (err error) {
+		svcDesc, err = r.c.ResolveService(serviceName)
+		return err
+	})
+	return svcDesc, err
+}
+
+func (r retryingDescriptorSource) ResolveMessage(messageName string) (msgDesc *desc.MessageDescriptor, err error) {
+	err = r.do(func() (err error) {
+		msgDesc, err = r.c.ResolveMessage(messageName)
+		return err
+	})
+	return msgDesc, err
+}
+
+func (r retryingDescriptorSource) do(f func() error) error {
+	return retry.Do(
+		f,
+		retry.Context(r.ctx),
+		retry.Attempts(r.attempts),
+		retry.Delay(250*time.Millisecond),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retry.RetryIf(func(err error) bool {
+			return status.Code(err) == codes.Unavailable
+		}),
+		retry.OnRetry(func(n uint, err error) {
+			r.log.Debug("Retrying reflection request", zap.Uint("attempt", n+1), zap.Error(err))
+			// The reflection stream is unusable after an error, so start a new one.
+			r.c.Reset()
+		}),
+	)
+}
+
+// descriptorCacheEntry is the on-disk format of a cached descriptor set for one gRPC address.
+type descriptorCacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// Services is the list of services reported by the server, at the time of fetching.
+	Services []string `json:"services"`
+
+	// DescriptorSet is a serialized FileDescriptorSet
+	// containing every file needed by Services.
+	DescriptorSet []byte `json:"descriptor_set"`
+}
+
+// cachedDescriptorSource serves descriptors from a cache entry on disk,
+// refreshing the entry from the remote server when a service is not found.
+type cachedDescriptorSource struct {
+	a      *appState
+	path   string
+	remote grpcdynamic.DescriptorSource
+
+	fetchedAt time.Time
+	services  map[string]*desc.ServiceDescriptor
+	files     []*desc.FileDescriptor
+}
+
+// newDescriptorSource returns a grpcdynamic.DescriptorSource for the server at gRPCAddr,
+// backed by the descriptor cache in the lens home directory.
+// If the --no-cache flag is set, or there is no usable cache entry,
+// the descriptors are fetched through remote and the cache entry is rewritten.
+func newDescriptorSource(cmd *cobra.Command, a *appState, gRPCAddr string, remote *grpcreflect.Client) (grpcdynamic.DescriptorSource, error) {
+	noCache, err := cmd.Flags().GetBool(gRPCNoCacheFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	retries, err := cmd.Flags().GetUint(gRPCRetriesFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &cachedDescriptorSource{
+		a:    a,
+		path: descriptorCachePath(a.HomePath, gRPCAddr),
+		remote: retryingDescriptorSource{
+			ctx:      cmd.Context(),
+			log:      a.Log,
+			c:        remote,
+			attempts: retries + 1,
+		},
+	}
+
+	if !noCache {
+		err := s.load()
+		if err == nil {
+			a.Log.Debug(
+				"Using cached descriptors",
+				zap.String("path", s.path),
+				zap.Time("fetched_at", s.fetchedAt),
+			)
+			return s, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			a.Log.Info("Ignoring unreadable descriptor cache", zap.String("path", s.path), zap.Error(err))
+		}
+	}
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *cachedDescriptorSource) ListServices() ([]string, error) {
+	services := make([]string, 0, len(s.services))
+	for name := range s.services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// ResolveService returns the cached descriptor for serviceName.
+// A miss is treated as a stale cache, so the cache is refreshed before retrying.
+func (s *cachedDescriptorSource) ResolveService(serviceName string) (*desc.ServiceDescriptor, error) {
+	if svcDesc, ok := s.services[serviceName]; ok {
+		return svcDesc, nil
+	}
+
+	s.a.Log.Debug("Service not in descriptor cache; refreshing", zap.String("service_name", serviceName))
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	if svcDesc, ok := s.services[serviceName]; ok {
+		return svcDesc, nil
+	}
+
+	// Still missing, so let the remote produce its own not-found error.
+	return s.remote.ResolveService(serviceName)
+}
+
+// ResolveMessage returns the cached descriptor for messageName.
+// Messages not reachable from any service, such as some types packed in an Any,
+// are resolved directly through the remote without invalidating the cache.
+func (s *cachedDescriptorSource) ResolveMessage(messageName string) (*desc.MessageDescriptor, error) {
+	for _, fd := range s.files {
+		if msgDesc := fd.FindMessage(messageName); msgDesc != nil {
+			return msgDesc, nil
+		}
+	}
+
+	return s.remote.ResolveMessage(messageName)
+}
+
+// load populates s from the cache entry on disk.
+func (s *cachedDescriptorSource) load() error {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var entry descriptorCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(entry.DescriptorSet, &set); err != nil {
+		return fmt.Errorf("failed to decode cached descriptor set: %w", err)
+	}
+
+	fds, err := desc.CreateFileDescriptorsFromSet(&set)
+	if err != nil {
+		return fmt.Errorf("failed to build cached descriptors: %w", err)
+	}
+
+	s.files = make([]*desc.FileDescriptor, 0, len(fds))
+	for _, fd := range fds {
+		s.files = append(s.files, fd)
+	}
+
+	s.services = make(map[string]*desc.ServiceDescriptor, len(entry.Services))
+	for _, name := range entry.Services {
+		for _, fd := range s.files {
+			if svcDesc := fd.FindService(name); svcDesc != nil {
+				s.services[name] = svcDesc
+				break
+			}
+		}
+	}
+
+	s.fetchedAt = entry.FetchedAt
+	return nil
+}
+
+// refresh fetches every service's descriptors from the remote
+// and rewrites the cache entry on disk.
+// Failing to write the cache is logged but is not an error.
+func (s *cachedDescriptorSource) refresh() error {
+	s.a.Log.Debug("Fetching remote descriptors")
+
+	files, err := resolveServiceFiles(s.a, s.remote, "")
+	if err != nil {
+		return err
+	}
+
+	s.services = make(map[string]*desc.ServiceDescriptor)
+	for _, fd := range files {
+		for _, svcDesc := range fd.GetServices() {
+			s.services[svcDesc.GetFullyQualifiedName()] = svcDesc
+		}
+	}
+	s.files = transitiveFiles(files)
+	s.fetchedAt = time.Now().UTC()
+
+	if err := s.save(); err != nil {
+		s.a.Log.Info("Failed to write descriptor cache", zap.String("path", s.path), zap.Error(err))
+	}
+
+	return nil
+}
+
+// save writes the current descriptors of s to the cache entry on disk.
+func (s *cachedDescriptorSource) save() error {
+	set, err := proto.Marshal(fileDescriptorSet(s.files))
+	if err != nil {
+		return fmt.Errorf("failed to serialize descriptor set: %w", err)
+	}
+
+	services, _ := s.ListServices()
+	b, err := json.Marshal(descriptorCacheEntry{
+		FetchedAt:     s.fetchedAt,
+		Services:      services,
+		DescriptorSet: set,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0644)
+}
+
+// msgDescriptorCacheEntry is the on-disk format of the cached descriptors of the messages of the chain at one gRPC address
+// that are not compiled into lens, as returned by byop.ReflectMsgDescriptors.
+type msgDescriptorCacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// MsgNames are the full names of the messages.
+	MsgNames []string `json:"msg_names"`
+
+	// DescriptorSet is a serialized FileDescriptorSet
+	// containing every file needed by MsgNames.
+	DescriptorSet []byte `json:"descriptor_set"`
+}
+
+// newReflectedMsgsModule returns the module of the messages of the chain at gRPCAddr, as byop.FromReflection does,
+// from the message descriptor cache in the lens home directory.
+// If the --no-cache flag is set, or there is no usable cache entry,
+// the descriptors are fetched from the server and the cache entry is rewritten.
+func newReflectedMsgsModule(cmd *cobra.Command, a *appState, gRPCAddr string) (byop.Module, error) {
+	noCache, err := cmd.Flags().GetBool(gRPCNoCacheFlag)
+	if err != nil {
+		return byop.Module{}, err
+	}
+
+	path := msgDescriptorCachePath(a.HomePath, gRPCAddr)
+	if !noCache {
+		entry, err := loadMsgDescriptorCacheEntry(path)
+		if err == nil {
+			a.Log.Debug(
+				"Using cached message descriptors",
+				zap.String("path", path),
+				zap.Time("fetched_at", entry.FetchedAt),
+			)
+			var set descriptorpb.FileDescriptorSet
+			if err := proto.Unmarshal(entry.DescriptorSet, &set); err != nil {
+				return byop.Module{}, fmt.Errorf("failed to decode cached descriptor set: %w", err)
+			}
+			return byop.NewModuleFromReflectedDescriptors(gRPCAddr, &set, entry.MsgNames)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			a.Log.Info("Ignoring unreadable message descriptor cache", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return byop.Module{}, err
+	}
+	defer conn.Close()
+
+	a.Log.Debug("Fetching remote message descriptors")
+	set, msgNames, err := byop.ReflectMsgDescriptors(cmd.Context(), conn)
+	if err != nil {
+		return byop.Module{}, err
+	}
+	if err := saveMsgDescriptorCacheEntry(path, set, msgNames); err != nil {
+		a.Log.Info("Failed to write message descriptor cache", zap.String("path", path), zap.Error(err))
+	}
+	return byop.NewModuleFromReflectedDescriptors(gRPCAddr, set, msgNames)
+}
+
+// loadMsgDescriptorCacheEntry reads the message descriptor cache entry at path.
+func loadMsgDescriptorCacheEntry(path string) (msgDescriptorCacheEntry, error) {
+	var entry msgDescriptorCacheEntry
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return entry, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return entry, nil
+}
+
+// saveMsgDescriptorCacheEntry writes set and msgNames to the message descriptor cache entry at path.
+func saveMsgDescriptorCacheEntry(path string, set *descriptorpb.FileDescriptorSet, msgNames []string) error {
+	bz, err := proto.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to serialize descriptor set: %w", err)
+	}
+
+	b, err := json.Marshal(msgDescriptorCacheEntry{
+		FetchedAt:     time.Now().UTC(),
+		MsgNames:      msgNames,
+		DescriptorSet: bz,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// descriptorCacheDir returns the directory holding cached descriptors under the lens home directory.
+func descriptorCacheDir(home string) string {
+	return filepath.Join(home, "cache", "descriptors")
+}
+
+var unsafeCacheKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// descriptorCachePath returns the path of the cache entry for gRPCAddr.
+func descriptorCachePath(home, gRPCAddr string) string {
+	return filepath.Join(descriptorCacheDir(home), unsafeCacheKeyChars.ReplaceAllString(gRPCAddr, "_")+".json")
+}
+
+// msgDescriptorCachePath returns the path of the message descriptor cache entry for gRPCAddr.
+func msgDescriptorCachePath(home, gRPCAddr string) string {
+	return filepath.Join(descriptorCacheDir(home), unsafeCacheKeyChars.ReplaceAllString(gRPCAddr, "_")+".msgs.json")
+}
+
+func dynCacheCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk cache of remote protobuf descriptors",
+	}
+
+	cmd.AddCommand(
+		dynCacheClearCmd(a),
+	)
+
+	return cmd
+}
+
+func dynCacheClearCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear [CHAIN_NAME_OR_GRPC_ADDR]",
+		Short: "Delete cached descriptors for one server, or for all servers",
+		Args:  withUsage(cobra.MaximumNArgs(1)),
+		Example: fmt.Sprintf(`$ %s dynamic cache clear
+$ %s dyn cache clear my-chain`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				dir := descriptorCacheDir(a.HomePath)
+				a.Log.Debug("Clearing descriptor cache", zap.String("dir", dir))
+				return os.RemoveAll(dir)
+			}
+
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, path := range []string{descriptorCachePath(a.HomePath, gRPCAddr), msgDescriptorCachePath(a.HomePath, gRPCAddr)} {
+				a.Log.Debug("Clearing descriptor cache", zap.String("path", path))
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
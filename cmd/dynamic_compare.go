@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func dynCompareCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare CHAIN_NAME_OR_GRPC_ADDR CHAIN_NAME_OR_GRPC_ADDR",
+		Short: "Use gRPC reflection to compare the services of two servers",
+		Long: `Use gRPC reflection to compare the services of two servers.
+
+Differences are grouped by service, with - marking what only the first server has
+and + marking what only the second server has:
+services, methods within shared services,
+and the fields of the request and response messages of shared methods.
+
+Identical servers produce no output.
+If any difference is found, the command exits with status 2.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s dynamic compare cosmoshub osmosis
+$ %s dyn compare example.com:9090 localhost:9090 -o json`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgChain),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sides [2]map[string]*desc.ServiceDescriptor
+			for i, arg := range args {
+				gRPCAddr, err := chooseGRPCAddr(cmd, a, arg)
+				if err != nil {
+					return err
+				}
+
+				sides[i], err = remoteServices(cmd, a, gRPCAddr)
+				if err != nil {
+					return err
+				}
+			}
+
+			diff := compareServices(sides[0], sides[1])
+			if len(diff) == 0 {
+				return nil
+			}
+
+			if err := writeOutput(cmd, a, diff); err != nil {
+				return err
+			}
+			return SurfaceDiffError{Services: len(diff)}
+		},
+	}
+
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// remoteServices returns every service offered by the server at gRPCAddr, keyed by name.
+func remoteServices(cmd *cobra.Command, a *appState, gRPCAddr string) (map[string]*desc.ServiceDescriptor, error) {
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Log.Debug("Resolving services", zap.String("addr", gRPCAddr))
+	svcDescs, err := resolveServices(a, c, "")
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]*desc.ServiceDescriptor, len(svcDescs))
+	for _, svcDesc := range svcDescs {
+		services[svcDesc.GetFullyQualifiedName()] = svcDesc
+	}
+	return services, nil
+}
+
+// Values of the Change fields of the surface diff types.
+const (
+	changeRemoved = "-"
+	changeAdded   = "+"
+)
+
+// surfaceDiff is the result of comparing the services of two servers.
+type surfaceDiff []serviceDiff
+
+// serviceDiff describes a service that differs between two servers.
+type serviceDiff struct {
+	Service string `json:"service"`
+
+	// Change is changeRemoved if only the first server has the service,
+	// changeAdded if only the second server has it,
+	// or empty if both servers have it but its methods differ.
+	Change string `json:"change,omitempty"`
+
+	Methods []methodDiff `json:"methods,omitempty"`
+}
+
+// methodDiff describes a method that differs between two versions of a service.
+type methodDiff struct {
+	Method string `json:"method"`
+
+	// Change is changeRemoved if only the first server has the method,
+	// changeAdded if only the second server has it,
+	// or empty if both servers have it but its messages differ.
+	Change string `json:"change,omitempty"`
+
+	Fields []fieldDiff `json:"fields,omitempty"`
+}
+
+// fieldDiff describes a field of a request or response message
+// that only one server has, or that has a different type on each server.
+// A field whose type changed is represented by a removal and an addition.
+type fieldDiff struct {
+	Change string `json:"change"`
+
+	// Field is the fully qualified name of the field.
+	Field string `json:"field"`
+
+	Type string `json:"type"`
+}
+
+var _ fmt.Stringer = surfaceDiff(nil)
+
+// String renders the diff with one difference per line,
+// indented to show which service and method it belongs to.
+func (d surfaceDiff) String() string {
+	var b strings.Builder
+	for _, svc := range d {
+		fmt.Fprintf(&b, "%-2s%s\n", svc.Change, svc.Service)
+		for _, m := range svc.Methods {
+			fmt.Fprintf(&b, "  %-2s%s\n", m.Change, m.Method)
+			for _, f := range m.Fields {
+				fmt.Fprintf(&b, "    %-2s%s %s\n", f.Change, f.Field, f.Type)
+			}
+		}
+	}
+	return b.String()
+}
+
+// compareServices returns the differences between the services a and b,
+// sorted by service name.
+func compareServices(a, b map[string]*desc.ServiceDescriptor) surfaceDiff {
+	var diff surfaceDiff
+	for _, name := range unionKeys(a, b) {
+		svcA, inA := a[name]
+		svcB, inB := b[name]
+		switch {
+		case !inB:
+			diff = append(diff, serviceDiff{Service: name, Change: changeRemoved})
+		case !inA:
+			diff = append(diff, serviceDiff{Service: name, Change: changeAdded})
+		default:
+			if methods := compareMethods(svcA, svcB); len(methods) > 0 {
+				diff = append(diff, serviceDiff{Service: name, Methods: methods})
+			}
+		}
+	}
+	return diff
+}
+
+func compareMethods(svcA, svcB *desc.ServiceDescriptor) []methodDiff {
+	a := make(map[string]*desc.MethodDescriptor)
+	for _, m := range svcA.GetMethods() {
+		a[m.GetName()] = m
+	}
+	b := make(map[string]*desc.MethodDescriptor)
+	for _, m := range svcB.GetMethods() {
+		b[m.GetName()] = m
+	}
+
+	var diff []methodDiff
+	for _, name := range unionKeys(a, b) {
+		mA, inA := a[name]
+		mB, inB := b[name]
+		switch {
+		case !inB:
+			diff = append(diff, methodDiff{Method: name, Change: changeRemoved})
+		case !inA:
+			diff = append(diff, methodDiff{Method: name, Change: changeAdded})
+		default:
+			fields := compareFields(mA.GetInputType(), mB.GetInputType())
+			fields = append(fields, compareFields(mA.GetOutputType(), mB.GetOutputType())...)
+			if len(fields) > 0 {
+				diff = append(diff, methodDiff{Method: name, Fields: fields})
+			}
+		}
+	}
+	return diff
+}
+
+// compareFields returns the fields of msgA and msgB that differ in name or type.
+// If the two messages have different names, every field is reported as changed,
+// as the fields of unrelated messages cannot be meaningfully compared.
+func compareFields(msgA, msgB *desc.MessageDescriptor) []fieldDiff {
+	if msgA.GetFullyQualifiedName() != msgB.GetFullyQualifiedName() {
+		var diff []fieldDiff
+		for _, f := range msgA.GetFields() {
+			diff = append(diff, fieldDiff{Change: changeRemoved, Field: f.GetFullyQualifiedName(), Type: fieldTypeName(f)})
+		}
+		for _, f := range msgB.GetFields() {
+			diff = append(diff, fieldDiff{Change: changeAdded, Field: f.GetFullyQualifiedName(), Type: fieldTypeName(f)})
+		}
+		return diff
+	}
+
+	a := make(map[string]*desc.FieldDescriptor)
+	for _, f := range msgA.GetFields() {
+		a[f.GetName()] = f
+	}
+	b := make(map[string]*desc.FieldDescriptor)
+	for _, f := range msgB.GetFields() {
+		b[f.GetName()] = f
+	}
+
+	var diff []fieldDiff
+	for _, name := range unionKeys(a, b) {
+		fA, inA := a[name]
+		fB, inB := b[name]
+		if inA && inB && fieldTypeName(fA) == fieldTypeName(fB) {
+			continue
+		}
+		if inA {
+			diff = append(diff, fieldDiff{Change: changeRemoved, Field: fA.GetFullyQualifiedName(), Type: fieldTypeName(fA)})
+		}
+		if inB {
+			diff = append(diff, fieldDiff{Change: changeAdded, Field: fB.GetFullyQualifiedName(), Type: fieldTypeName(fB)})
+		}
+	}
+	return diff
+}
+
+// fieldTypeName returns the type of f as it would be written in a .proto file,
+// such as "repeated cosmos.base.v1beta1.Coin" or "map<string, string>".
+func fieldTypeName(f *desc.FieldDescriptor) string {
+	if f.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldTypeName(f.GetMapKeyType()), fieldTypeName(f.GetMapValueType()))
+	}
+
+	name := fieldElementTypeName(f)
+	if f.IsRepeated() {
+		return "repeated " + name
+	}
+	return name
+}
+
+// fieldElementTypeName returns the type of f, or of its elements if it is repeated:
+// the fully qualified name of a message or enum, or the name of a scalar type.
+func fieldElementTypeName(f *desc.FieldDescriptor) string {
+	switch {
+	case f.GetMessageType() != nil:
+		return f.GetMessageType().GetFullyQualifiedName()
+	case f.GetEnumType() != nil:
+		return f.GetEnumType().GetFullyQualifiedName()
+	default:
+		return strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+	}
+}
+
+// unionKeys returns the keys present in either a or b, sorted.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
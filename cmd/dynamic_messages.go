@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+)
+
+func dynShowMessagesCmd(a *appState) *cobra.Command {
+	const detailFlag = "detail"
+
+	cmd := &cobra.Command{
+		Use:   "show-messages CHAIN_NAME_OR_GRPC_ADDR FULLY_QUALIFIED_METHOD",
+		Short: "Show the fields of the messages used by a gRPC method",
+		Long: `Use gRPC reflection to show the fields of a method's request and response messages,
+and of every message they refer to, keyed by fully qualified message name.
+
+By default, each message maps the JSON names of its fields to their types.
+With --detail, each message lists its fields in declaration order, with their
+proto and JSON names, field numbers, labels (optional, required, repeated, or map),
+types, the oneof they belong to, if any, and whether they are deprecated.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s dynamic show-messages example.com:9090 cosmos.bank.v1beta1.Query.Balance
+$ %s dyn show-messages my-chain cosmos.bank.v1beta1.Query/Balance --detail -o yaml`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgFullMethod),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			serviceName, methodName, err := grpcdynamic.SplitMethodName(args[1])
+			if err != nil {
+				return err
+			}
+
+			detail, err := cmd.Flags().GetBool(detailFlag)
+			if err != nil {
+				return err
+			}
+
+			conn, err := dialGRPC(cmd, a, gRPCAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			rc := newReflectionClient(cmd.Context(), a.Log, conn)
+			defer rc.Reset()
+
+			c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+			if err != nil {
+				return err
+			}
+
+			verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
+			if err != nil {
+				return err
+			}
+
+			methodDesc, err := resolveMethod(c, serviceName, methodName, verbose)
+			if err != nil {
+				return err
+			}
+
+			msgDescs := referencedMessages(methodDesc.GetInputType(), methodDesc.GetOutputType())
+			if !detail {
+				return writeOutput(cmd, a, messageFieldTypes(msgDescs))
+			}
+			return writeOutput(cmd, a, messageFieldDetails(msgDescs))
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().Bool(detailFlag, false, "show the number, label, proto name, oneof, and deprecation of each field, in declaration order")
+	return cmd
+}
+
+// referencedMessages returns msgDescs and every message their fields refer to, transitively,
+// excluding the entries of map fields, which are shown as map types.
+func referencedMessages(msgDescs ...*desc.MessageDescriptor) []*desc.MessageDescriptor {
+	var out []*desc.MessageDescriptor
+	seen := make(map[string]bool)
+
+	var add func(msgDesc *desc.MessageDescriptor)
+	add = func(msgDesc *desc.MessageDescriptor) {
+		if seen[msgDesc.GetFullyQualifiedName()] {
+			return
+		}
+		seen[msgDesc.GetFullyQualifiedName()] = true
+		out = append(out, msgDesc)
+
+		for _, f := range msgDesc.GetFields() {
+			if f.IsMap() {
+				f = f.GetMapValueType()
+			}
+			if fieldMsg := f.GetMessageType(); fieldMsg != nil {
+				add(fieldMsg)
+			}
+		}
+	}
+
+	for _, msgDesc := range msgDescs {
+		add(msgDesc)
+	}
+	return out
+}
+
+// messageFieldTypes maps the fully qualified name of each message
+// to a map of the JSON names of its fields to their types.
+func messageFieldTypes(msgDescs []*desc.MessageDescriptor) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(msgDescs))
+	for _, msgDesc := range msgDescs {
+		fields := make(map[string]string, len(msgDesc.GetFields()))
+		for _, f := range msgDesc.GetFields() {
+			fields[f.GetJSONName()] = fieldTypeName(f)
+		}
+		out[msgDesc.GetFullyQualifiedName()] = fields
+	}
+	return out
+}
+
+// messageFieldDetail describes a message field for show-messages --detail.
+type messageFieldDetail struct {
+	Name     string `json:"name"`
+	JSONName string `json:"json_name"`
+	Number   int32  `json:"number"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+
+	// OneOf is the name of the oneof the field is part of, if any.
+	// Proto3 optional fields are not shown as part of their synthetic oneof.
+	OneOf string `json:"oneof,omitempty"`
+
+	Deprecated bool `json:"deprecated"`
+}
+
+// messageFieldDetails maps the fully qualified name of each message
+// to the details of its fields, in declaration order.
+func messageFieldDetails(msgDescs []*desc.MessageDescriptor) map[string][]messageFieldDetail {
+	out := make(map[string][]messageFieldDetail, len(msgDescs))
+	for _, msgDesc := range msgDescs {
+		fields := make([]messageFieldDetail, len(msgDesc.GetFields()))
+		for i, f := range msgDesc.GetFields() {
+			fields[i] = messageFieldDetail{
+				Name:       f.GetName(),
+				JSONName:   f.GetJSONName(),
+				Number:     f.GetNumber(),
+				Label:      fieldLabel(f),
+				Type:       fieldElementTypeName(f),
+				Deprecated: f.GetFieldOptions().GetDeprecated(),
+			}
+			if f.IsMap() {
+				fields[i].Type = fieldTypeName(f)
+			}
+			if oneOf := f.GetOneOf(); oneOf != nil && !oneOf.IsSynthetic() {
+				fields[i].OneOf = oneOf.GetName()
+			}
+		}
+		out[msgDesc.GetFullyQualifiedName()] = fields
+	}
+	return out
+}
+
+// fieldLabel returns the label of f as written in a .proto file, or map for map fields.
+// Singular fields, with or without presence, are optional.
+func fieldLabel(f *desc.FieldDescriptor) string {
+	switch {
+	case f.IsMap():
+		return "map"
+	case f.IsRepeated():
+		return "repeated"
+	case f.IsRequired():
+		return "required"
+	default:
+		return "optional"
+	}
+}
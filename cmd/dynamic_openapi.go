@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+func dynOpenAPICmd(a *appState) *cobra.Command {
+	const outFlag = "out"
+
+	cmd := &cobra.Command{
+		Use:   "openapi CHAIN_NAME_OR_GRPC_ADDR",
+		Short: "Generate an OpenAPI document for the REST routes of a chain's Query services",
+		Long: `Use gRPC reflection to generate an OpenAPI 3.1 document describing the REST routes
+that the chain's grpc-gateway serves for the methods of its Query services,
+as declared by their google.api.http options.
+
+Path and query parameters are derived from each method's request message,
+and response schemas from its response message, as generated by the schema subcommand.
+Methods without a google.api.http option are skipped, as logged with --debug.
+
+With --out, the document is written to the given file, as JSON if its name ends in .json,
+or as YAML otherwise.`,
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: fmt.Sprintf(`$ %s dynamic openapi example.com:9090
+$ %s dyn openapi my-chain --out openapi.yaml`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			out, err := cmd.Flags().GetString(outFlag)
+			if err != nil {
+				return err
+			}
+
+			conn, err := dialGRPC(cmd, a, gRPCAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			rc := newReflectionClient(cmd.Context(), a.Log, conn)
+			defer rc.Reset()
+
+			c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+			if err != nil {
+				return err
+			}
+
+			svcDescs, err := resolveQueryServices(a, c)
+			if err != nil {
+				return err
+			}
+
+			// Documents of configured chains are versioned by chain ID.
+			version := "unknown"
+			if chain, ok := a.Config.Chains[args[0]]; ok {
+				version = chain.ChainID
+			}
+
+			doc, skipped := grpcdynamic.OpenAPI(args[0]+" REST API", version, svcDescs)
+			methods := make([]string, 0, len(skipped))
+			for m := range skipped {
+				methods = append(methods, m)
+			}
+			sort.Strings(methods)
+			for _, m := range methods {
+				a.Log.Debug("Skipping method", zap.String("method", m), zap.String("reason", skipped[m]))
+			}
+
+			if out == "" {
+				return writeOutput(cmd, a, doc)
+			}
+			return writeOpenAPI(out, doc)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(outFlag, "", "write the document to this file, as JSON if it ends in .json or else as YAML, instead of to stdout")
+	return cmd
+}
+
+// resolveQueryServices returns the descriptors of the remote services named Query, sorted by name.
+// Services that fail to resolve are logged and skipped.
+func resolveQueryServices(a *appState, c grpcdynamic.DescriptorSource) ([]*desc.ServiceDescriptor, error) {
+	services, err := c.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote services: %w", err)
+	}
+	sort.Strings(services)
+
+	var svcDescs []*desc.ServiceDescriptor
+	for _, svc := range services {
+		if !strings.HasSuffix(svc, ".Query") {
+			continue
+		}
+		svcDesc, err := c.ResolveService(svc)
+		if err != nil {
+			a.Log.Info(
+				"Error resolving service",
+				zap.String("service_name", svc),
+				zap.Error(err),
+			)
+			continue
+		}
+		svcDescs = append(svcDescs, svcDesc)
+	}
+	return svcDescs, nil
+}
+
+// writeOpenAPI writes doc to the file at path, as JSON if the file name ends in .json, or else as YAML.
+func writeOpenAPI(path string, doc map[string]interface{}) error {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		b = append(b, '\n')
+	} else if b, err = yaml.JSONToYAML(b); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("failed to write OpenAPI document: %w", err)
+	}
+	return nil
+}
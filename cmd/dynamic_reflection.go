@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jhump/protoreflect/grpcreflect"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newReflectionClient returns a client of the server reflection service at the other end of conn.
+// It uses version v1 of the service, and falls back to v1alpha if the server does not implement v1,
+// as servers older than v1 only implement v1alpha, and newer ones may no longer implement it.
+// The version used is logged at the debug level.
+func newReflectionClient(ctx context.Context, log *zap.Logger, conn grpc.ClientConnInterface) *grpcreflect.Client {
+	return grpcreflect.NewClientAuto(ctx, reflectionLogConn{ClientConnInterface: conn, log: log})
+}
+
+// reflectionLogConn logs the version of the reflection streams opened through it.
+type reflectionLogConn struct {
+	grpc.ClientConnInterface
+	log *zap.Logger
+}
+
+func (c reflectionLogConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := c.ClientConnInterface.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The method is /grpc.reflection.VERSION.ServerReflection/ServerReflectionInfo.
+	version, _, ok := strings.Cut(strings.TrimPrefix(method, "/grpc.reflection."), ".")
+	if !ok || !strings.HasPrefix(method, "/grpc.reflection.") {
+		return s, nil
+	}
+	return &reflectionLogStream{ClientStream: s, log: c.log, version: version}, nil
+}
+
+// reflectionLogStream logs whether the server implements the version of its reflection stream,
+// once its first response, or the error in its place, is received.
+type reflectionLogStream struct {
+	grpc.ClientStream
+	log     *zap.Logger
+	version string
+	once    sync.Once
+}
+
+func (s *reflectionLogStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.once.Do(func() {
+		switch {
+		case err == nil:
+			s.log.Debug("Using gRPC reflection", zap.String("version", s.version))
+		case status.Code(err) == codes.Unimplemented:
+			s.log.Debug("Server does not implement gRPC reflection version", zap.String("version", s.version))
+		}
+	})
+	return err
+}
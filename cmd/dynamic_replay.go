@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	dynRecordFlag        = "record"
+	dynReplayAgainstFlag = "against"
+)
+
+// recordingPath returns the path of the recording file name: name itself if it is absolute,
+// or else name in the recordings directory of the home directory.
+func recordingPath(home, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(home, "recordings", name)
+}
+
+// dynamicCallRecord is a call made by dynamic call, recorded by --record as a line of newline delimited JSON.
+type dynamicCallRecord struct {
+	Time     time.Time `json:"time"`
+	Endpoint string    `json:"endpoint"`
+	// Method is the fully qualified name of the method.
+	Method string `json:"method"`
+	// Headers are the headers sent with the call, without those that may be secrets.
+	Headers  map[string][]string `json:"headers,omitempty"`
+	Request  json.RawMessage     `json:"request"`
+	Response json.RawMessage     `json:"response,omitempty"`
+	// Code is the gRPC status code of the call, and Error the error of a failed call.
+	Code     string `json:"code"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// newDynamicCallRecord returns the record of the call of method at endpoint with input and headers,
+// which started at start and returned the response j, or err.
+func newDynamicCallRecord(endpoint, method string, headers metadata.MD, input, j []byte, start time.Time, err error) dynamicCallRecord {
+	rec := dynamicCallRecord{
+		Time:     start.UTC(),
+		Endpoint: endpoint,
+		Method:   method,
+		Request:  compactJSON(input),
+		Code:     gRPCErrorCode(err).String(),
+		Duration: time.Since(start).String(),
+	}
+	for k, vs := range headers {
+		if client.IsSensitiveGRPCHeader(k) {
+			continue
+		}
+		if rec.Headers == nil {
+			rec.Headers = make(map[string][]string)
+		}
+		rec.Headers[k] = vs
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		rec.Response = compactJSON(j)
+	}
+	return rec
+}
+
+// compactJSON returns j without insignificant whitespace, or as a JSON string if it is not valid JSON.
+func compactJSON(j []byte) json.RawMessage {
+	var b bytes.Buffer
+	if err := json.Compact(&b, j); err != nil {
+		s, _ := json.Marshal(string(j))
+		return s
+	}
+	return b.Bytes()
+}
+
+// appendDynamicCallRecord appends rec to the recording file at path, creating it and its directory if needed.
+func appendDynamicCallRecord(path string, rec dynamicCallRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordedGRPCHeaders returns the headers that dialGRPC sends to addr, without those configured from environment variables,
+// which hold secrets; those whose keys name secrets are removed by newDynamicCallRecord.
+func recordedGRPCHeaders(cmd *cobra.Command, a *appState, addr string) (metadata.MD, error) {
+	chain := chainForGRPCAddr(a, addr)
+	headers, err := gRPCHeadersFromFlags(cmd, chain)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := cmd.Flags().GetStringArray(gRPCHeaderFlag)
+	if err != nil {
+		return nil, err
+	}
+	flagHeaders, err := client.ParseGRPCHeaders(pairs)
+	if err != nil {
+		return nil, err
+	}
+	fromEnv := func(k string) bool {
+		if v, ok := flagHeaders[k]; ok {
+			return strings.HasPrefix(v, client.GRPCHeaderEnvPrefix)
+		}
+		if chain != nil {
+			for ck, v := range chain.GRPCHeaders {
+				if strings.ToLower(ck) == k {
+					return strings.HasPrefix(v, client.GRPCHeaderEnvPrefix)
+				}
+			}
+		}
+		return false
+	}
+	for k := range headers {
+		if fromEnv(k) {
+			delete(headers, k)
+		}
+	}
+	return headers, nil
+}
+
+// gRPCErrorCode returns the gRPC status code of err, as returned by invokeDynamic, or codes.OK if err is nil.
+func gRPCErrorCode(err error) codes.Code {
+	var pruned PrunedHeightError
+	if errors.As(err, &pruned) {
+		return pruned.Code
+	}
+	var callErr GRPCCallError
+	if errors.As(err, &callErr) {
+		return callErr.Code
+	}
+	return status.Code(err)
+}
+
+func dynReplayCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay FILE",
+		Short: "Call again the methods recorded by dynamic call --record, and compare their responses",
+		Long: fmt.Sprintf(`Call again each method recorded by '%[1]s dynamic call --record FILE', in order,
+with its recorded request and headers, such as the block height of the call,
+and report for each call whether its response is identical to the recorded one.
+The values of the responses that differ are listed by path, such as balances.0.amount,
+and a call failing with another status code than the recorded one differs too.
+
+The calls are made against the endpoint they were recorded from,
+or against the chain or gRPC address given by --%[2]s.
+As with --record, a relative FILE is in the recordings directory of the home directory.
+
+If any call differs or fails, the command exits with status 2.`, appName, dynReplayAgainstFlag),
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: fmt.Sprintf(`$ %[1]s dynamic call cosmoshub cosmos.bank.v1beta1.Query.TotalSupply --record session.ndjson
+$ %[1]s dynamic replay session.ndjson
+$ %[1]s dyn replay session.ndjson --against localhost:9090 -o json`,
+			appName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			against, err := cmd.Flags().GetString(dynReplayAgainstFlag)
+			if err != nil {
+				return err
+			}
+			if against != "" {
+				if against, err = chooseGRPCAddr(cmd, a, against); err != nil {
+					return err
+				}
+			}
+
+			f, err := os.Open(recordingPath(a.HomePath, args[0]))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			r := dynamicReplayer{cmd: cmd, a: a, endpoints: make(map[string]*replayEndpoint)}
+			defer r.close()
+			var report replayReport
+			dec := json.NewDecoder(f)
+			for i := 1; ; i++ {
+				var rec dynamicCallRecord
+				if err := dec.Decode(&rec); err == io.EOF {
+					break
+				} else if err != nil {
+					return fmt.Errorf("invalid record %d of %s: %w", i, args[0], err)
+				}
+				endpoint := rec.Endpoint
+				if against != "" {
+					endpoint = against
+				}
+				report = append(report, r.replay(i, endpoint, rec))
+			}
+
+			if err := writeOutput(cmd, a, report); err != nil {
+				return err
+			}
+			if n := report.differing(); n > 0 {
+				return ReplayDiffError{Calls: n, Replayed: len(report)}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String(dynReplayAgainstFlag, "", "call the methods on this chain or gRPC address instead of on the recorded endpoints")
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// replayEndpoint is the connection to an endpoint of replayed calls, or the error connecting to it.
+type replayEndpoint struct {
+	conn *grpc.ClientConn
+	rc   *grpcreflect.Client
+	c    grpcdynamic.DescriptorSource
+	err  error
+}
+
+// dynamicReplayer replays recorded calls, connecting once to each endpoint.
+type dynamicReplayer struct {
+	cmd       *cobra.Command
+	a         *appState
+	endpoints map[string]*replayEndpoint
+}
+
+// endpoint returns the connection to the endpoint at addr, connecting to it on first use.
+func (r dynamicReplayer) endpoint(addr string) *replayEndpoint {
+	if e, ok := r.endpoints[addr]; ok {
+		return e
+	}
+	e := &replayEndpoint{}
+	r.endpoints[addr] = e
+	if e.conn, e.err = dialGRPC(r.cmd, r.a, addr); e.err != nil {
+		return e
+	}
+	e.rc = newReflectionClient(r.cmd.Context(), r.a.Log, e.conn)
+	e.c, e.err = newDescriptorSource(r.cmd, r.a, addr, e.rc)
+	return e
+}
+
+// close closes the connections to the endpoints.
+func (r dynamicReplayer) close() {
+	for _, e := range r.endpoints {
+		if e.rc != nil {
+			e.rc.Reset()
+		}
+		if e.conn != nil {
+			e.conn.Close()
+		}
+	}
+}
+
+// replay calls the method of the record rec, the ith of its file, on endpoint,
+// and compares the result with the recorded one.
+func (r dynamicReplayer) replay(i int, endpoint string, rec dynamicCallRecord) replayResult {
+	res := replayResult{Index: i, Method: rec.Method, Endpoint: endpoint, RecordedCode: rec.Code}
+	failed := func(err error) replayResult {
+		res.Result = replayFailed
+		res.Error = err.Error()
+		return res
+	}
+
+	e := r.endpoint(endpoint)
+	if e.err != nil {
+		return failed(e.err)
+	}
+	serviceName, methodName, err := grpcdynamic.SplitMethodName(rec.Method)
+	if err != nil {
+		return failed(err)
+	}
+	methodDesc, err := resolveMethod(e.c, serviceName, methodName, false)
+	if err != nil {
+		return failed(err)
+	}
+
+	r.a.Log.Debug("Replaying call", zap.Int("index", i), zap.String("method", rec.Method), zap.String("addr", endpoint))
+	ctx := metadata.NewOutgoingContext(r.cmd.Context(), metadata.MD(rec.Headers))
+	start := time.Now()
+	j, err := invokeDynamic(ctx, e.conn, e.c, methodDesc, rec.Request)
+	res.Duration = time.Since(start).String()
+	res.Code = gRPCErrorCode(err).String()
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	switch {
+	case res.Code != rec.Code:
+		res.Result = replayDifferent
+	case err != nil:
+		res.Result = replayIdentical
+	default:
+		for _, d := range compareJSON("", decodeJSONValue(rec.Response), decodeJSONValue(j)) {
+			res.Diffs = append(res.Diffs, replayDiff{Path: d.Path, Recorded: d.Values[0], Replayed: d.Values[1]})
+		}
+		res.Result = replayIdentical
+		if len(res.Diffs) > 0 {
+			res.Result = replayDifferent
+		}
+	}
+	return res
+}
+
+// Values of the Result field of replayResult.
+const (
+	replayIdentical = "identical"
+	replayDifferent = "different"
+	replayFailed    = "failed"
+)
+
+// replayReport is the result of dynamic replay, with the result of each call in order.
+type replayReport []replayResult
+
+// replayResult is the result of replaying a recorded call.
+type replayResult struct {
+	// Index is the number of the call in the recording, from 1.
+	Index    int    `json:"index"`
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	// Result is replayIdentical, replayDifferent, or replayFailed if the call could not be made.
+	Result       string       `json:"result"`
+	RecordedCode string       `json:"recorded_code"`
+	Code         string       `json:"code,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	Duration     string       `json:"duration,omitempty"`
+	Diffs        []replayDiff `json:"diffs,omitempty"`
+}
+
+// replayDiff is a value of a response that differs from the recorded response.
+type replayDiff struct {
+	Path     string          `json:"path"`
+	Recorded json.RawMessage `json:"recorded"`
+	Replayed json.RawMessage `json:"replayed"`
+}
+
+// differing returns the number of calls whose result is not identical.
+func (r replayReport) differing() int {
+	n := 0
+	for _, res := range r {
+		if res.Result != replayIdentical {
+			n++
+		}
+	}
+	return n
+}
+
+var _ fmt.Stringer = replayReport(nil)
+
+// String returns the result of each call on a line, followed by its differences, indented.
+func (r replayReport) String() string {
+	var b strings.Builder
+	for _, res := range r {
+		fmt.Fprintf(&b, "#%d %s on %s: %s", res.Index, res.Method, res.Endpoint, res.Result)
+		switch {
+		case res.Result == replayFailed:
+			fmt.Fprintf(&b, ": %s\n", res.Error)
+			continue
+		case res.Code != res.RecordedCode:
+			fmt.Fprintf(&b, ": code %s, recorded %s", res.Code, res.RecordedCode)
+			if res.Error != "" {
+				fmt.Fprintf(&b, ": %s", res.Error)
+			}
+		}
+		fmt.Fprintf(&b, " (%s)\n", res.Duration)
+		for _, d := range res.Diffs {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", d.Path, jsonValueText(d.Recorded), jsonValueText(d.Replayed))
+		}
+	}
+	return b.String()
+}
+
+// jsonValueText returns the JSON value v, or a dash if there is none.
+func jsonValueText(v json.RawMessage) string {
+	if v == nil {
+		return "-"
+	}
+	return string(v)
+}
@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDynamicRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	mainnet := runParamsServer(t, map[string]string{
+		"test.staking.v1beta1": `{"denom": "uatom", "max": 100, "limits": {"rate": "0.1"}}`,
+		"test.wasm.v1":         "",
+	})
+	testnet := runParamsServer(t, map[string]string{
+		"test.staking.v1beta1": `{"denom": "uatom", "max": 175, "limits": {"rate": "0.1"}}`,
+	})
+
+	sys := NewSystem(t)
+
+	// Calls are appended to the recording, failed ones too, without the headers that may be secrets.
+	_ = sys.MustRun(t, "dynamic", "call", mainnet, "test.staking.v1beta1.Query.Params",
+		"--record", "session.ndjson", "--header", "x-api-key=secret", "--header", "x-note=hi")
+	res := sys.Run(zaptest.NewLogger(t), "dynamic", "call", mainnet, "test.wasm.v1.Query.Params", "--record", "session.ndjson")
+	require.ErrorContains(t, res.Err, "module disabled")
+
+	recording, err := os.ReadFile(filepath.Join(sys.HomeDir, "recordings", "session.ndjson"))
+	require.NoError(t, err)
+	require.NotContains(t, string(recording), "secret")
+	lines := strings.Split(strings.TrimSpace(string(recording)), "\n")
+	require.Len(t, lines, 2)
+	var rec struct {
+		Endpoint, Method, Code, Error, Duration string
+		Headers                                 map[string][]string
+		Request, Response                       json.RawMessage
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	require.Equal(t, mainnet, rec.Endpoint)
+	require.Equal(t, "test.staking.v1beta1.Query.Params", rec.Method)
+	require.Equal(t, "OK", rec.Code)
+	require.Equal(t, map[string][]string{"x-note": {"hi"}}, rec.Headers)
+	require.JSONEq(t, `{}`, string(rec.Request))
+	require.JSONEq(t, `{"params": {"denom": "uatom", "max": 100, "limits": {"rate": "0.1"}}}`, string(rec.Response))
+	require.NotEmpty(t, rec.Duration)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	require.Equal(t, "Unimplemented", rec.Code)
+	require.Contains(t, rec.Error, "module disabled")
+
+	// Replayed against the same endpoint, the calls are identical, including the failure.
+	res = sys.MustRun(t, "dynamic", "replay", "session.ndjson")
+	out := res.Stdout.String()
+	require.Contains(t, out, "#1 test.staking.v1beta1.Query.Params on "+mainnet+": identical (")
+	require.Contains(t, out, "#2 test.wasm.v1.Query.Params on "+mainnet+": identical (")
+
+	// Against another endpoint, the values that differ are listed, and the command fails.
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "replay", filepath.Join(sys.HomeDir, "recordings", "session.ndjson"), "--against", testnet)
+	var diffErr cmd.ReplayDiffError
+	require.ErrorAs(t, res.Err, &diffErr)
+	require.Equal(t, cmd.ReplayDiffError{Calls: 2, Replayed: 2}, diffErr)
+	out = res.Stdout.String()
+	require.Contains(t, out, "#1 test.staking.v1beta1.Query.Params on "+testnet+": different (")
+	require.Contains(t, out, "    params.max: 100 -> 175\n")
+	require.Contains(t, out, "#2 test.wasm.v1.Query.Params on "+testnet+": failed: ")
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "replay", "session.ndjson", "--against", testnet, "-o", "json")
+	require.ErrorAs(t, res.Err, &diffErr)
+	var report []struct {
+		Index                      int
+		Result, RecordedCode, Code string
+		Diffs                      []struct {
+			Path               string
+			Recorded, Replayed json.RawMessage
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &report))
+	require.Len(t, report, 2)
+	require.Equal(t, "different", report[0].Result)
+	require.Len(t, report[0].Diffs, 1)
+	require.Equal(t, "params.max", report[0].Diffs[0].Path)
+	require.JSONEq(t, "100", string(report[0].Diffs[0].Recorded))
+	require.JSONEq(t, "175", string(report[0].Diffs[0].Replayed))
+	require.Equal(t, "failed", report[1].Result)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "replay", "missing.ndjson")
+	require.ErrorContains(t, res.Err, "no such file")
+}
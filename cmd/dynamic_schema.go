@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+)
+
+func dynSchemaCmd(a *appState) *cobra.Command {
+	const draftFlag = "draft"
+
+	cmd := &cobra.Command{
+		Use:   "schema CHAIN_NAME_OR_GRPC_ADDR FULLY_QUALIFIED_MESSAGE",
+		Short: "Print a JSON Schema for a protobuf message type",
+		Long: `Use gRPC reflection to print a JSON Schema document describing the proto3 JSON form of a message type,
+for use by tools such as form builders and validators.
+
+Every message type is defined under $defs (or definitions, with --draft 07) and referenced with $ref.
+64-bit integers are strings, enums are strings of their value names, repeated fields are arrays,
+map fields are objects, and google.protobuf.Any is an object with an @type
+alongside the fields of the packed message.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s dynamic schema example.com:9090 cosmos.bank.v1beta1.MsgSend
+$ %s dyn schema my-chain cosmos.staking.v1beta1.QueryValidatorsRequest --draft 07`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgMessage),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			draft, err := cmd.Flags().GetString(draftFlag)
+			if err != nil {
+				return err
+			}
+
+			conn, err := dialGRPC(cmd, a, gRPCAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			rc := newReflectionClient(cmd.Context(), a.Log, conn)
+			defer rc.Reset()
+
+			c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+			if err != nil {
+				return err
+			}
+
+			msgDesc, err := resolveMessage(c, args[1])
+			if err != nil {
+				return err
+			}
+
+			schema, err := grpcdynamic.JSONSchema(msgDesc, draft)
+			if err != nil {
+				return err
+			}
+
+			return writeOutput(cmd, a, schema)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(draftFlag, grpcdynamic.JSONSchemaDraft2020_12,
+		fmt.Sprintf("JSON Schema draft of the document (%s or %s)", grpcdynamic.JSONSchemaDraft2020_12, grpcdynamic.JSONSchemaDraft07))
+	return cmd
+}
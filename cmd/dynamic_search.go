@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of element matched by dynamic search, in the order they are reported.
+const (
+	searchKindService = "service"
+	searchKindMethod  = "method"
+	searchKindMessage = "message"
+	searchKindField   = "field"
+)
+
+var searchKinds = []string{searchKindService, searchKindMethod, searchKindMessage, searchKindField}
+
+func dynSearchCmd(a *appState) *cobra.Command {
+	const (
+		kindFlag  = "kind"
+		regexFlag = "regex"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search CHAIN_NAME_OR_GRPC_ADDR KEYWORD",
+		Short: "Use gRPC reflection to find services, methods, messages, and fields by name",
+		Long: `Use gRPC reflection to find services, methods, messages, and fields
+whose fully qualified name contains KEYWORD, ignoring case.
+
+Messages and fields are searched across every file used by the server's services.
+Matches are grouped by kind.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s dynamic search example.com:9090 balance
+$ %s dyn search my-chain pagination --kind field
+$ %s dyn search my-chain '^cosmos\.bank\..*Request$' --regex --kind message`,
+			appName, appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			kind, err := cmd.Flags().GetString(kindFlag)
+			if err != nil {
+				return err
+			}
+			if kind != "" && !stringsContain(searchKinds, kind) {
+				return fmt.Errorf("--%s must be one of %s", kindFlag, strings.Join(searchKinds, ", "))
+			}
+
+			isRegex, err := cmd.Flags().GetBool(regexFlag)
+			if err != nil {
+				return err
+			}
+
+			pattern := regexp.QuoteMeta(args[1])
+			if isRegex {
+				pattern = args[1]
+			}
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return fmt.Errorf("invalid regular expression %q: %w", args[1], err)
+			}
+
+			conn, err := dialGRPC(cmd, a, gRPCAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			rc := newReflectionClient(cmd.Context(), a.Log, conn)
+			defer rc.Reset()
+
+			c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+			if err != nil {
+				return err
+			}
+
+			svcDescs, err := resolveServices(a, c, "")
+			if err != nil {
+				return err
+			}
+
+			matches := searchDescriptors(svcDescs, re)
+			if kind != "" {
+				var filtered searchMatches
+				for _, m := range matches {
+					if m.Kind == kind {
+						filtered = append(filtered, m)
+					}
+				}
+				matches = filtered
+			}
+
+			if matches == nil {
+				matches = searchMatches{}
+			}
+			return writeOutput(cmd, a, matches)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().String(kindFlag, "", "only search one kind of element: "+strings.Join(searchKinds, ", "))
+	cmd.Flags().Bool(regexFlag, false, "treat KEYWORD as a regular expression")
+	return cmd
+}
+
+// searchMatch is an element found by dynamic search.
+type searchMatch struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// searchMatches is the result of dynamic search.
+type searchMatches []searchMatch
+
+var _ fmt.Stringer = searchMatches(nil)
+
+// String returns one "kind: name" line per match.
+func (ms searchMatches) String() string {
+	var b strings.Builder
+	for _, m := range ms {
+		fmt.Fprintf(&b, "%s: %s\n", m.Kind, m.Name)
+	}
+	return b.String()
+}
+
+// searchDescriptors returns the fully qualified names matching re
+// of the given services, their methods,
+// and the messages and fields of every file they use.
+// Matches are grouped by kind, in the order of searchKinds, and sorted by name within each kind.
+func searchDescriptors(svcDescs []*desc.ServiceDescriptor, re *regexp.Regexp) searchMatches {
+	byKind := make(map[string][]string)
+	add := func(kind string, d desc.Descriptor) {
+		if name := d.GetFullyQualifiedName(); re.MatchString(name) {
+			byKind[kind] = append(byKind[kind], name)
+		}
+	}
+
+	files := make([]*desc.FileDescriptor, len(svcDescs))
+	for i, svcDesc := range svcDescs {
+		files[i] = svcDesc.GetFile()
+
+		add(searchKindService, svcDesc)
+		for _, m := range svcDesc.GetMethods() {
+			add(searchKindMethod, m)
+		}
+	}
+
+	var addMessage func(msgDesc *desc.MessageDescriptor)
+	addMessage = func(msgDesc *desc.MessageDescriptor) {
+		if msgDesc.IsMapEntry() {
+			// Map entries are synthesized by the compiler, so are only noise here.
+			return
+		}
+		add(searchKindMessage, msgDesc)
+		for _, f := range msgDesc.GetFields() {
+			add(searchKindField, f)
+		}
+		for _, nested := range msgDesc.GetNestedMessageTypes() {
+			addMessage(nested)
+		}
+	}
+	for _, fd := range transitiveFiles(files) {
+		for _, msgDesc := range fd.GetMessageTypes() {
+			addMessage(msgDesc)
+		}
+	}
+
+	var matches searchMatches
+	for _, kind := range searchKinds {
+		names := byKind[kind]
+		sort.Strings(names)
+		for _, name := range names {
+			matches = append(matches, searchMatch{Kind: kind, Name: name})
+		}
+	}
+	return matches
+}
+
+func stringsContain(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func dynSkeletonCmd(a *appState) *cobra.Command {
+	const commentsFlag = "comments"
+
+	cmd := &cobra.Command{
+		Use:   "skeleton CHAIN_NAME_OR_GRPC_ADDR FULLY_QUALIFIED_METHOD",
+		Short: "Print a JSON request skeleton for a gRPC method",
+		Long: `Use gRPC reflection to print a JSON document containing every field of a method's request message.
+
+Scalars are set to their zero values, repeated fields to [], and map fields to {}.
+Nested messages are expanded one level deep; deeper messages are left as {}.
+Enums are set to their first value, and oneofs are populated with only their first alternative.
+
+Without --comments, the output is valid input for the call subcommand.
+With --comments, enum fields are annotated with their possible values,
+so the output must be edited before use.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: fmt.Sprintf(`$ %s dynamic skeleton example.com:9090 cosmos.bank.v1beta1.Query.Balance
+$ %s dyn skeleton my-chain cosmos.staking.v1beta1.Query/Validators --comments`,
+			appName, appName),
+		ValidArgsFunction: completeDynamicArgs(a, dynArgFullMethod),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gRPCAddr, err := chooseGRPCAddr(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+
+			serviceName, methodName, err := grpcdynamic.SplitMethodName(args[1])
+			if err != nil {
+				return err
+			}
+
+			comments, err := cmd.Flags().GetBool(commentsFlag)
+			if err != nil {
+				return err
+			}
+
+			conn, err := dialGRPC(cmd, a, gRPCAddr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			rc := newReflectionClient(cmd.Context(), a.Log, conn)
+			defer rc.Reset()
+
+			c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+			if err != nil {
+				return err
+			}
+
+			verbose, err := cmd.Flags().GetBool(gRPCVerboseFlag)
+			if err != nil {
+				return err
+			}
+
+			methodDesc, err := resolveMethod(c, serviceName, methodName, verbose)
+			if err != nil {
+				return err
+			}
+
+			return writeSkeleton(cmd.OutOrStdout(), methodDesc.GetInputType(), comments)
+		},
+	}
+
+	cmd = gRPCFlags(cmd, a.Viper)
+	cmd.Flags().Bool(commentsFlag, false, "annotate enum fields with their possible values (the output is then no longer valid JSON)")
+	return cmd
+}
+
+// skeletonMaxDepth is how many levels of nested messages are expanded in a skeleton.
+// Messages below this depth are rendered as {}.
+const skeletonMaxDepth = 1
+
+// writeSkeleton writes an indented JSON skeleton of msgDesc to w.
+// If comments is set, enum fields are followed by a // comment listing their values.
+func writeSkeleton(w io.Writer, msgDesc *desc.MessageDescriptor, comments bool) error {
+	var b strings.Builder
+	skeletonMessage(&b, msgDesc, 0, "", comments)
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func skeletonMessage(b *strings.Builder, msgDesc *desc.MessageDescriptor, depth int, indent string, comments bool) {
+	fields := skeletonFields(msgDesc)
+	if len(fields) == 0 {
+		b.WriteString("{}")
+		return
+	}
+
+	b.WriteString("{\n")
+	for i, f := range fields {
+		b.WriteString(indent + "  ")
+		b.WriteString(strconv.Quote(f.GetJSONName()))
+		b.WriteString(": ")
+
+		comment := skeletonValue(b, f, depth, indent+"  ", comments)
+		if i < len(fields)-1 {
+			b.WriteByte(',')
+		}
+		if comment != "" {
+			b.WriteString(" // " + comment)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(indent + "}")
+}
+
+// skeletonFields returns the fields of msgDesc to include in a skeleton.
+// Only the first alternative of each oneof is included.
+func skeletonFields(msgDesc *desc.MessageDescriptor) []*desc.FieldDescriptor {
+	var fields []*desc.FieldDescriptor
+	for _, f := range msgDesc.GetFields() {
+		if oneOf := f.GetOneOf(); oneOf != nil && !oneOf.IsSynthetic() && oneOf.GetChoices()[0] != f {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// skeletonValue writes the skeleton value of f to b,
+// and returns the comment to place after the value, if any.
+func skeletonValue(b *strings.Builder, f *desc.FieldDescriptor, depth int, indent string, comments bool) string {
+	switch {
+	case f.IsMap():
+		b.WriteString("{}")
+		return ""
+	case f.IsRepeated():
+		b.WriteString("[]")
+		return ""
+	}
+
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		msgDesc := f.GetMessageType()
+		switch {
+		case strings.HasPrefix(msgDesc.GetFullyQualifiedName(), "google.protobuf."):
+			// Well-known types have special JSON representations, so leave them unset.
+			b.WriteString("null")
+		case depth < skeletonMaxDepth:
+			skeletonMessage(b, msgDesc, depth+1, indent, comments)
+		default:
+			b.WriteString("{}")
+		}
+		return ""
+
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		values := f.GetEnumType().GetValues()
+		b.WriteString(strconv.Quote(values[0].GetName()))
+		if !comments {
+			return ""
+		}
+		names := make([]string, len(values))
+		for i, v := range values {
+			names[i] = v.GetName()
+		}
+		return "one of: " + strings.Join(names, ", ")
+
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		b.WriteString("false")
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		b.WriteString(`""`)
+	default:
+		b.WriteString("0")
+	}
+	return ""
+}
@@ -1,18 +1,52 @@
 package cmd_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"flag"
 	"fmt"
+	"math/big"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/builder"
+	"github.com/strangelove-ventures/lens/cmd"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
 	channelzsvc "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
 )
 
 func TestDynamicInspect_ChainID(t *testing.T) {
@@ -69,22 +103,904 @@ func TestDynamicInspectService(t *testing.T) {
 	require.Empty(t, res.Stderr.String())
 }
 
+func TestDynamicInspect_Full(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--full")
+	require.Contains(t, res.Stdout.String(), "service Channelz {")
+	require.Contains(t, res.Stdout.String(), "service ServerReflection {")
+	require.Contains(t, res.Stdout.String(), "rpc GetServer ( .grpc.channelz.v1.GetServerRequest ) returns ( .grpc.channelz.v1.GetServerResponse );\n")
+	require.Empty(t, res.Stderr.String())
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Channelz", "--full")
+	require.Error(t, res.Err)
+	require.Contains(t, res.Stderr.String(), "--full cannot be combined with a service name")
+}
+
 func TestDynamicInspectMethod(t *testing.T) {
 	t.Parallel()
 
-	sys := NewSystem(t)
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServer")
+	// Includes the request and response messages.
+	require.Contains(t, res.Stdout.String(), "message GetServerRequest {\n")
+	require.Contains(t, res.Stdout.String(), "message GetServerResponse {\n")
+	// Includes transitive message definitions, with a leading comment of the fully qualified name and filename.
+	require.Contains(t, res.Stdout.String(), "// grpc.channelz.v1.SubchannelRef (grpc/channelz/v1/channelz.proto)\nmessage SubchannelRef {")
+	// Includes enums, with a leading comment of the fully qualified name and filename.
+	require.Contains(t, res.Stdout.String(), "// grpc.channelz.v1.ChannelTraceEvent.Severity (grpc/channelz/v1/channelz.proto)\nenum Severity {")
+	require.Empty(t, res.Stderr.String())
+}
+
+func TestDynamicInspect_DescriptorSetOut(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	outPath := filepath.Join(t.TempDir(), "out.protoset")
+	res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--descriptor-set-out", outPath)
+	require.Empty(t, res.Stdout.String())
+
+	b, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+
+	var set descriptorpb.FileDescriptorSet
+	require.NoError(t, proto.Unmarshal(b, &set))
+
+	// Every file appears once, and only after all of its imports.
+	seen := map[string]bool{}
+	for _, f := range set.File {
+		require.False(t, seen[f.GetName()], "duplicate file %s", f.GetName())
+		for _, dep := range f.Dependency {
+			require.True(t, seen[dep], "file %s listed before its import %s", f.GetName(), dep)
+		}
+		seen[f.GetName()] = true
+	}
+
+	// The set round-trips through the descriptor loader.
+	fds, err := desc.CreateFileDescriptorsFromSet(&set)
+	require.NoError(t, err)
+	require.Contains(t, fds, "grpc/channelz/v1/channelz.proto")
+	require.Contains(t, fds, "grpc/reflection/v1alpha/reflection.proto")
+	// Well-known types are included because channelz imports them...
+	require.Contains(t, fds, "google/protobuf/any.proto")
+	// ...but unreferenced ones are not.
+	require.NotContains(t, fds, "google/protobuf/empty.proto")
+}
+
+func TestDynamicExportProto(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	t.Run("all services", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+		outDir := t.TempDir()
+
+		res := sys.MustRun(t, "dynamic", "export-proto", gRPCAddr, "--out", outDir)
+		require.Empty(t, res.Stdout.String())
+
+		channelz, err := os.ReadFile(filepath.Join(outDir, "grpc", "channelz", "v1", "channelz.proto"))
+		require.NoError(t, err)
+		require.Contains(t, string(channelz), "package grpc.channelz.v1;")
+		require.Contains(t, string(channelz), `import "google/protobuf/any.proto";`)
+
+		require.FileExists(t, filepath.Join(outDir, "grpc", "reflection", "v1alpha", "reflection.proto"))
+		require.FileExists(t, filepath.Join(outDir, "google", "protobuf", "any.proto"))
+	})
+
+	t.Run("single service", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+		outDir := t.TempDir()
+
+		_ = sys.MustRun(t, "dynamic", "export-proto", gRPCAddr, "--out", outDir, "--service", "grpc.channelz.v1.Channelz")
+
+		require.FileExists(t, filepath.Join(outDir, "grpc", "channelz", "v1", "channelz.proto"))
+		require.NoFileExists(t, filepath.Join(outDir, "grpc", "reflection", "v1alpha", "reflection.proto"))
+	})
+
+	t.Run("existing files", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+		outDir := t.TempDir()
+
+		_ = sys.MustRun(t, "dynamic", "export-proto", gRPCAddr, "--out", outDir)
+
+		// Exporting again over identical files is a no-op.
+		_ = sys.MustRun(t, "dynamic", "export-proto", gRPCAddr, "--out", outDir)
+
+		anyPath := filepath.Join(outDir, "google", "protobuf", "any.proto")
+		require.NoError(t, os.WriteFile(anyPath, []byte("// modified\n"), 0644))
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "export-proto", gRPCAddr, "--out", outDir)
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "refusing to overwrite")
+
+		_ = sys.MustRun(t, "dynamic", "export-proto", gRPCAddr, "--out", outDir, "--overwrite")
+		b, err := os.ReadFile(anyPath)
+		require.NoError(t, err)
+		require.Contains(t, string(b), "package google.protobuf;")
+	})
+}
+
+func TestDynamicDescriptorCache(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	cachePath := func(sys *System, addr string) string {
+		return filepath.Join(sys.HomeDir, "cache", "descriptors", strings.ReplaceAll(addr, ":", "_")+".json")
+	}
+
+	t.Run("populated and reused", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		_ = sys.MustRun(t, "dynamic", "inspect", gRPCAddr)
+		require.FileExists(t, cachePath(sys, gRPCAddr))
+
+		// Trim the cached service list, to show that listing services
+		// is served from the cache rather than from the server.
+		var entry map[string]any
+		b, err := os.ReadFile(cachePath(sys, gRPCAddr))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &entry))
+		entry["services"] = []string{"grpc.channelz.v1.Channelz"}
+		b, err = json.Marshal(entry)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(cachePath(sys, gRPCAddr), b, 0644))
+
+		res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr)
+		require.Equal(t, "grpc.channelz.v1.Channelz\n", res.Stdout.String())
+
+		// --no-cache forces a refresh from the server.
+		res = sys.MustRun(t, "dynamic", "inspect", "--no-cache", gRPCAddr)
+		require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+
+		// Clearing a different address leaves this entry alone.
+		const otherAddr = "127.0.0.1:1"
+		require.NoError(t, os.WriteFile(cachePath(sys, otherAddr), b, 0644))
+		_ = sys.MustRun(t, "dynamic", "cache", "clear", otherAddr)
+		require.NoFileExists(t, cachePath(sys, otherAddr))
+		require.FileExists(t, cachePath(sys, gRPCAddr))
+
+		_ = sys.MustRun(t, "dynamic", "cache", "clear")
+		require.NoFileExists(t, cachePath(sys, gRPCAddr))
+	})
+
+	t.Run("refreshed on miss", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		// Seed a stale entry that does not know about any services.
+		require.NoError(t, os.MkdirAll(filepath.Dir(cachePath(sys, gRPCAddr)), 0755))
+		require.NoError(t, os.WriteFile(cachePath(sys, gRPCAddr), []byte(`{"services":[]}`), 0644))
+
+		res := sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServerSockets")
+		require.Equal(t, "{\n  \"end\": true\n}\n", res.Stdout.String())
+
+		b, err := os.ReadFile(cachePath(sys, gRPCAddr))
+		require.NoError(t, err)
+		require.Contains(t, string(b), "grpc.channelz.v1.Channelz")
+	})
+}
+
+func TestDynamicSkeleton(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	t.Run("oneof", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		// Only the first alternative of the message_request oneof is rendered.
+		res := sys.MustRun(t, "dynamic", "skeleton", gRPCAddr, "grpc.reflection.v1alpha.ServerReflection.ServerReflectionInfo")
+		require.Equal(t, "{\n  \"host\": \"\",\n  \"fileByFilename\": \"\"\n}\n", res.Stdout.String())
+	})
+
+	t.Run("valid call input", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		const method = "grpc.channelz.v1.Channelz.GetServerSockets"
+		res := sys.MustRun(t, "dynamic", "skeleton", gRPCAddr, method)
+		require.Equal(t, "{\n  \"serverId\": 0,\n  \"startSocketId\": 0,\n  \"maxResults\": 0\n}\n", res.Stdout.String())
+
+		res = sys.MustRunWithInput(t, strings.NewReader(res.Stdout.String()), "dynamic", "call", gRPCAddr, method, "-")
+		require.Equal(t, "{\n  \"end\": true\n}\n", res.Stdout.String())
+	})
+}
+
+// updateGolden rewrites the golden files of tests with the current output, instead of comparing against them.
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// requireGolden requires got to equal the contents of testdata/name,
+// or writes got there if -update is set.
+func requireGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(want), got)
+}
+
+func TestDynamicShowMessages(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	const method = "grpc.reflection.v1alpha.ServerReflection.ServerReflectionInfo"
+
+	t.Run("simple", func(t *testing.T) {
+		res := sys.MustRun(t, "dynamic", "show-messages", gRPCAddr, method)
+		requireGolden(t, "show_messages.golden", res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+
+	t.Run("detail", func(t *testing.T) {
+		res := sys.MustRun(t, "dynamic", "show-messages", gRPCAddr, method, "--detail")
+		requireGolden(t, "show_messages_detail.golden", res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "show-messages", gRPCAddr, "grpc.reflection.v1alpha.ServerReflection.Nope")
+		require.Error(t, res.Err)
+	})
+}
+
+func TestDynamicSchema(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	res := sys.MustRun(t, "dynamic", "schema", gRPCAddr, "grpc.reflection.v1alpha.ServerReflectionResponse")
+	var schema struct {
+		Schema string                            `json:"$schema"`
+		Ref    string                            `json:"$ref"`
+		Defs   map[string]map[string]interface{} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &schema))
+	require.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema.Schema)
+	require.Equal(t, "#/$defs/grpc.reflection.v1alpha.ServerReflectionResponse", schema.Ref)
+	// Messages referenced by the fields, directly or not, are defined too.
+	require.Contains(t, schema.Defs, "grpc.reflection.v1alpha.ServerReflectionRequest")
+	require.Contains(t, schema.Defs, "grpc.reflection.v1alpha.ServiceResponse")
+	require.Equal(t,
+		map[string]interface{}{"$ref": "#/$defs/grpc.reflection.v1alpha.ServerReflectionRequest"},
+		schema.Defs["grpc.reflection.v1alpha.ServerReflectionResponse"]["properties"].(map[string]interface{})["originalRequest"],
+	)
+	require.Empty(t, res.Stderr.String())
+
+	res = sys.MustRun(t, "dynamic", "schema", gRPCAddr, "grpc.reflection.v1alpha.ErrorResponse", "--draft", "07", "-o", "yaml")
+	require.Contains(t, res.Stdout.String(), "$ref: '#/definitions/grpc.reflection.v1alpha.ErrorResponse'\n")
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "schema", gRPCAddr, "grpc.reflection.v1alpha.Nope")
+	var notFound cmd.GRPCMessageNotFoundError
+	require.ErrorAs(t, res.Err, &notFound)
+	require.Equal(t, "grpc.reflection.v1alpha.Nope", notFound.Requested)
+}
+
+// staticServices advertises a fixed set of services through reflection.
+type staticServices map[string]grpc.ServiceInfo
+
+func (s staticServices) GetServiceInfo() map[string]grpc.ServiceInfo { return s }
+
+// fallbackResolver resolves descriptors from local, and then from the global registry.
+type fallbackResolver struct {
+	local *protoregistry.Files
+}
+
+func (r fallbackResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.local.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r fallbackResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.local.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
+
+// runGRPCGatewayReflectionServer runs a server whose reflection service describes
+// a test.bank.v1.Query service with google.api.http options,
+// and a test.bank.v1.Msg service, which is listed but not described.
+func runGRPCGatewayReflectionServer(t *testing.T) string {
+	t.Helper()
+
+	getOpts := &descriptorpb.MethodOptions{}
+	protov2.SetExtension(getOpts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/test/bank/v1/balances/{address}"},
+	})
+
+	req := builder.NewMessage("QueryBalanceRequest").
+		AddField(builder.NewField("address", builder.FieldTypeString())).
+		AddField(builder.NewField("denom", builder.FieldTypeString()))
+	res := builder.NewMessage("QueryBalanceResponse").
+		AddField(builder.NewField("amount", builder.FieldTypeUInt64()))
+	svc := builder.NewService("Query").
+		AddMethod(builder.NewMethod("Balance", builder.RpcTypeMessage(req, false), builder.RpcTypeMessage(res, false)).SetOptions(getOpts)).
+		AddMethod(builder.NewMethod("Unrouted", builder.RpcTypeMessage(req, false), builder.RpcTypeMessage(res, false)))
+	fd, err := builder.NewFile("test/bank/v1/query.proto").
+		SetPackageName("test.bank.v1").
+		SetProto3(true).
+		AddMessage(req).
+		AddMessage(res).
+		AddService(svc).
+		Build()
+	require.NoError(t, err)
+
+	file, err := protodesc.NewFile(fd.AsFileDescriptorProto(), protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	local := new(protoregistry.Files)
+	require.NoError(t, local.RegisterFile(file))
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{
+		Services: staticServices{
+			"test.bank.v1.Query": {},
+			"test.bank.v1.Msg":   {},
+		},
+		DescriptorResolver: fallbackResolver{local: local},
+	}))
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return ln.Addr().String()
+}
+
+func TestDynamicOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCGatewayReflectionServer(t)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+
+	outPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	res := sys.MustRun(t, "dynamic", "openapi", "cosmoshub", "--out", outPath)
+	require.Empty(t, res.Stdout.String())
+
+	b, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	var doc struct {
+		OpenAPI string            `yaml:"openapi"`
+		Info    map[string]string `yaml:"info"`
+		Paths   map[string]map[string]struct {
+			OperationID string `yaml:"operationId"`
+			Parameters  []struct {
+				Name string `yaml:"name"`
+				In   string `yaml:"in"`
+			} `yaml:"parameters"`
+		} `yaml:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	require.NoError(t, yaml.Unmarshal(b, &doc))
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	require.Equal(t, map[string]string{"title": "cosmoshub REST API", "version": "cosmoshub-4"}, doc.Info)
+
+	// Only the method with a google.api.http option has a route.
+	require.Len(t, doc.Paths, 1)
+	op := doc.Paths["/test/bank/v1/balances/{address}"]["get"]
+	require.Equal(t, "test.bank.v1.Query.Balance", op.OperationID)
+	require.Len(t, op.Parameters, 2)
+	require.Equal(t, "address", op.Parameters[0].Name)
+	require.Equal(t, "path", op.Parameters[0].In)
+	require.Equal(t, "denom", op.Parameters[1].Name)
+	require.Equal(t, "query", op.Parameters[1].In)
+	require.Contains(t, doc.Components.Schemas, "test.bank.v1.QueryBalanceResponse")
+
+	// Without --out, the document is written as JSON.
+	res = sys.MustRun(t, "dynamic", "openapi", gRPCAddr)
+	var jsonDoc map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &jsonDoc))
+	require.Equal(t, map[string]interface{}{"title": gRPCAddr + " REST API", "version": "unknown"}, jsonDoc["info"])
+}
+
+func TestDynamicInspect_TLS(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeSelfSignedCert(t)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	t.Run("server TLS", func(t *testing.T) {
+		t.Parallel()
+
+		gRPCAddr := runGRPCReflectionServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+		})))
+
+		sys := NewSystem(t)
+
+		// Without the CA, the self-signed certificate fails verification.
+		// Handshake failures are retried until the dial times out, so keep that short.
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "--tls-server-name", "localhost", "--timeout", "500ms")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "certificate")
+
+		res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--tls-ca", certFile)
+		require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		t.Parallel()
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		pool.AddCert(leaf)
+		gRPCAddr := runGRPCReflectionServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pool,
+		})))
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "--tls-ca", certFile, "--timeout", "500ms")
+		require.Error(t, res.Err)
+
+		res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--tls-ca", certFile, "--tls-cert", certFile, "--tls-key", keyFile)
+		require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+	})
+
+	t.Run("cert without key", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "localhost:1", "--tls-cert", certFile)
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "--tls-cert and --tls-key must be provided together")
+	})
+}
+
+// writeSelfSignedCert writes a self-signed certificate for localhost,
+// usable as both a server and client certificate and as its own CA,
+// and returns the paths of the PEM certificate and key files.
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certFile, keyFile
+}
+
+func TestDynamicInspect_ChainTLS(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeSelfSignedCert(t)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	t.Run("chain config over default", func(t *testing.T) {
+		t.Parallel()
+
+		gRPCAddr := runGRPCReflectionServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+		})))
+
+		sys := NewSystem(t)
+		_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+
+		// By default, the connection is plaintext, which the server rejects.
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--timeout", "500ms")
+		require.Error(t, res.Err)
+
+		_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-tls", "true")
+		_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-tls-ca-file", certFile)
+
+		res = sys.MustRun(t, "dynamic", "inspect", "cosmoshub")
+		require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+	})
+
+	t.Run("flag over chain config", func(t *testing.T) {
+		t.Parallel()
+
+		gRPCAddr := runGRPCReflectionServer(t)
+
+		sys := NewSystem(t)
+		_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+		_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-tls", "true")
+
+		// The chain says TLS, but the server is plaintext.
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--timeout", "500ms")
+		require.Error(t, res.Err)
+
+		res = sys.MustRun(t, "dynamic", "inspect", "cosmoshub", "--tls=false")
+		require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+
+		res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--tls=false", "--tls-ca", certFile)
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "--tls=false cannot be combined with the other --tls-* flags")
+	})
+
+	t.Run("edit rejects invalid value", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+		res := sys.Run(zaptest.NewLogger(t), "chains", "edit", "cosmoshub", "grpc-tls", "maybe")
+		require.Error(t, res.Err)
+	})
+}
+
+func TestDynamicInspect_Timeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("connection refused", func(t *testing.T) {
+		t.Parallel()
+
+		// Reserve a port, then close it so nothing is listening there.
+		ln, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+
+		sys := NewSystem(t)
+
+		start := time.Now()
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", addr)
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "connection refused")
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("unresponsive server", func(t *testing.T) {
+		t.Parallel()
+
+		// Accept TCP connections but never speak gRPC.
+		ln, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { ln.Close() })
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", ln.Addr().String(), "--timeout", "200ms")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "failed to connect to "+ln.Addr().String()+" within 200ms")
+	})
+}
+
+func TestDynamicListServices(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	res := sys.MustRun(t, "dynamic", "list-services", gRPCAddr)
+	require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+
+	res = sys.MustRun(t, "dyn", "ls", gRPCAddr, "-l")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"SERVICE", "METHODS", "FILE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"grpc.channelz.v1.Channelz", "7", "grpc/channelz/v1/channelz.proto"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"grpc.reflection.v1alpha.ServerReflection", "1", "grpc/reflection/v1alpha/reflection.proto"}, strings.Fields(lines[2]))
+
+	res = sys.MustRun(t, "dyn", "ls", gRPCAddr, "-l", "-o", "json")
+	var summaries []map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &summaries))
+	require.Equal(t, []map[string]interface{}{
+		{"name": "grpc.channelz.v1.Channelz", "methods": float64(7), "file": "grpc/channelz/v1/channelz.proto"},
+		{"name": "grpc.reflection.v1alpha.ServerReflection", "methods": float64(1), "file": "grpc/reflection/v1alpha/reflection.proto"},
+	}, summaries)
+}
+
+func TestDynamicListServices_FallbackEndpoint(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	refused := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "grpc-addr", refused)
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "grpc-addrs", gRPCAddr)
+
+	// The first endpoint refuses connections, so the fallback is used.
+	res := sys.MustRun(t, "dynamic", "list-services", "osmosis")
+	require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+
+	// Forcing the first endpoint fails.
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "list-services", "osmosis", "--endpoint", "0")
+	require.ErrorContains(t, res.Err, refused)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "list-services", "osmosis", "--endpoint", "2")
+	require.ErrorContains(t, res.Err, "no endpoint at index 2")
+}
+
+func TestDynamicListMethods(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	t.Run("all services", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "list-methods", gRPCAddr)
+		lines := strings.Split(strings.TrimSuffix(res.Stdout.String(), "\n"), "\n")
+		require.Contains(t, lines, "grpc.channelz.v1.Channelz.GetTopChannels")
+		require.Contains(t, lines, "grpc.channelz.v1.Channelz.GetSocket")
+		require.Contains(t, lines, "grpc.reflection.v1alpha.ServerReflection.ServerReflectionInfo")
+	})
+
+	t.Run("single service", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "list-methods", gRPCAddr, "grpc.reflection.v1alpha.ServerReflection")
+		require.Equal(t, "grpc.reflection.v1alpha.ServerReflection.ServerReflectionInfo\n", res.Stdout.String())
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "list-methods", gRPCAddr, "--filter", "GetServer")
+		// Methods are listed in declaration order.
+		require.Equal(t, "grpc.channelz.v1.Channelz.GetServers\ngrpc.channelz.v1.Channelz.GetServer\ngrpc.channelz.v1.Channelz.GetServerSockets\n", res.Stdout.String())
+
+		res = sys.MustRun(t, "dynamic", "list-methods", gRPCAddr, "--filter", "*.Get*Socket")
+		require.Equal(t, "grpc.channelz.v1.Channelz.GetSocket\n", res.Stdout.String())
+
+		res = sys.Run(zaptest.NewLogger(t), "dynamic", "list-methods", gRPCAddr, "--filter", "[")
+		require.Error(t, res.Err)
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "list-methods", gRPCAddr, "foo.Bar")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), "no service")
+	})
+}
+
+func TestDynamic_OutputFormats(t *testing.T) {
+	t.Parallel()
 
 	gRPCAddr := runGRPCReflectionServer(t)
+	services := []string{"grpc.channelz.v1.Channelz", "grpc.reflection.v1alpha.ServerReflection"}
 
-	res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServer")
-	// Includes the request and response messages.
-	require.Contains(t, res.Stdout.String(), "message GetServerRequest {\n")
-	require.Contains(t, res.Stdout.String(), "message GetServerResponse {\n")
-	// Includes transitive message definitions, with a leading comment of the fully qualified name and filename.
-	require.Contains(t, res.Stdout.String(), "// grpc.channelz.v1.SubchannelRef (grpc/channelz/v1/channelz.proto)\nmessage SubchannelRef {")
-	// Includes enums, with a leading comment of the fully qualified name and filename.
-	require.Contains(t, res.Stdout.String(), "// grpc.channelz.v1.ChannelTraceEvent.Severity (grpc/channelz/v1/channelz.proto)\nenum Severity {")
-	require.Empty(t, res.Stderr.String())
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "-o", "json")
+		var got []string
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &got))
+		require.Equal(t, services, got)
+
+		res = sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServerSockets", "-o", "json")
+		require.Equal(t, `{"end":true}`+"\n", res.Stdout.String())
+	})
+
+	t.Run("json-indent", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "list-methods", gRPCAddr, "grpc.reflection.v1alpha.ServerReflection", "-o", "json-indent")
+		require.Equal(t, "[\n  \"grpc.reflection.v1alpha.ServerReflection.ServerReflectionInfo\"\n]\n", res.Stdout.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "-o", "yaml")
+		var got []string
+		require.NoError(t, yaml.Unmarshal(res.Stdout.Bytes(), &got))
+		require.Equal(t, services, got)
+
+		res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServer", "-o", "yaml")
+		var defs []map[string]string
+		require.NoError(t, yaml.Unmarshal(res.Stdout.Bytes(), &defs))
+		require.Equal(t, "grpc.channelz.v1.Channelz.GetServer", defs[0]["name"])
+		require.Equal(t, "grpc/channelz/v1/channelz.proto", defs[0]["file"])
+		require.Equal(t, "grpc.channelz.v1.GetServerRequest", defs[1]["name"])
+		require.Contains(t, defs[1]["definition"], "message GetServerRequest {")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "-o", "xml")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Err.Error(), `unknown output format "xml"`)
+	})
+}
+
+func TestDynamicCompare(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	// A second server that offers health instead of channelz.
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	reflection.Register(srv)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+	otherAddr := ln.Addr().String()
+
+	t.Run("identical", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "compare", gRPCAddr, gRPCAddr)
+		require.Empty(t, res.Stdout.String())
+	})
+
+	t.Run("different", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "compare", gRPCAddr, otherAddr)
+		var diffErr cmd.SurfaceDiffError
+		require.ErrorAs(t, res.Err, &diffErr)
+		require.Equal(t, 2, diffErr.ExitCode())
+		require.Equal(t, "- grpc.channelz.v1.Channelz\n+ grpc.health.v1.Health\n", res.Stdout.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "compare", otherAddr, gRPCAddr, "-o", "json")
+		require.Error(t, res.Err)
+		require.JSONEq(t, `[
+			{"service": "grpc.channelz.v1.Channelz", "change": "+"},
+			{"service": "grpc.health.v1.Health", "change": "-"}
+		]`, res.Stdout.String())
+	})
+}
+
+func TestDynamicSearch(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	t.Run("all kinds", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		// Case-insensitive, grouped by kind, matching anywhere in the fully qualified name.
+		res := sys.MustRun(t, "dynamic", "search", gRPCAddr, "getserversockets")
+		require.Equal(t, `method: grpc.channelz.v1.Channelz.GetServerSockets
+message: grpc.channelz.v1.GetServerSocketsRequest
+message: grpc.channelz.v1.GetServerSocketsResponse
+field: grpc.channelz.v1.GetServerSocketsRequest.max_results
+field: grpc.channelz.v1.GetServerSocketsRequest.server_id
+field: grpc.channelz.v1.GetServerSocketsRequest.start_socket_id
+field: grpc.channelz.v1.GetServerSocketsResponse.end
+field: grpc.channelz.v1.GetServerSocketsResponse.socket_ref
+`, res.Stdout.String())
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "search", gRPCAddr, "server_id", "--kind", "field")
+		require.Equal(t, `field: grpc.channelz.v1.GetServerRequest.server_id
+field: grpc.channelz.v1.GetServerSocketsRequest.server_id
+field: grpc.channelz.v1.GetServersRequest.start_server_id
+field: grpc.channelz.v1.ServerRef.server_id
+`, res.Stdout.String())
+
+		res = sys.Run(zaptest.NewLogger(t), "dynamic", "search", gRPCAddr, "server", "--kind", "enum")
+		require.Error(t, res.Err)
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		t.Parallel()
+
+		sys := NewSystem(t)
+
+		res := sys.MustRun(t, "dynamic", "search", gRPCAddr, `^grpc\.reflection\..*Response$`, "--regex", "--kind", "message")
+		require.Equal(t, `message: grpc.reflection.v1alpha.ErrorResponse
+message: grpc.reflection.v1alpha.ExtensionNumberResponse
+message: grpc.reflection.v1alpha.FileDescriptorResponse
+message: grpc.reflection.v1alpha.ListServiceResponse
+message: grpc.reflection.v1alpha.ServerReflectionResponse
+message: grpc.reflection.v1alpha.ServiceResponse
+`, res.Stdout.String())
+
+		// Without --regex, the keyword is a literal.
+		res = sys.MustRun(t, "dynamic", "search", gRPCAddr, `^grpc`, "-o", "json")
+		require.Equal(t, "[]\n", res.Stdout.String())
+	})
 }
 
 func TestDynamicQuery_ChainID(t *testing.T) {
@@ -156,6 +1072,142 @@ func TestDynamicQuery_Response(t *testing.T) {
 	require.True(t, ok, "expected response's server field to be an array of objects, got %T", val)
 }
 
+func TestDynamicQuery_Headers(t *testing.T) {
+	// Not parallel, as it sets an environment variable.
+	t.Setenv("LENS_TEST_GRPC_API_KEY", "secret")
+
+	var mu sync.Mutex
+	var unaryMD, streamMD metadata.MD
+	gRPCAddr := runGRPCReflectionServer(t,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			mu.Lock()
+			unaryMD, _ = metadata.FromIncomingContext(ctx)
+			mu.Unlock()
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			mu.Lock()
+			streamMD, _ = metadata.FromIncomingContext(ss.Context())
+			mu.Unlock()
+			return handler(srv, ss)
+		}),
+	)
+
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-headers", "X-Api-Key=env:LENS_TEST_GRPC_API_KEY,x-cosmos-block-height=7")
+
+	// The secret is only referenced in the configuration.
+	res := sys.MustRun(t, "chains", "show", "cosmoshub")
+	require.Contains(t, res.Stdout.String(), "env:LENS_TEST_GRPC_API_KEY")
+	require.NotContains(t, res.Stdout.String(), "secret")
+
+	// Reflection and query calls send the headers, and --header overrides those of the chain.
+	_ = sys.MustRun(t, "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers", "--header", "x-cosmos-block-height=9", "--header", "x-extra=1")
+	mu.Lock()
+	require.Equal(t, []string{"secret"}, streamMD.Get("x-api-key"))
+	require.Equal(t, []string{"secret"}, unaryMD.Get("x-api-key"))
+	require.Equal(t, []string{"9"}, unaryMD.Get("x-cosmos-block-height"))
+	require.Equal(t, []string{"1"}, unaryMD.Get("x-extra"))
+	mu.Unlock()
+
+	// --height takes precedence over the headers.
+	_ = sys.MustRun(t, "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers", "--height", "11")
+	mu.Lock()
+	require.Equal(t, []string{"11"}, unaryMD.Get("x-cosmos-block-height"))
+	mu.Unlock()
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers", "--header", "x-other=env:LENS_TEST_GRPC_UNSET")
+	require.ErrorContains(t, res.Err, "environment variable LENS_TEST_GRPC_UNSET of gRPC header x-other is not set")
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers", "--header", "novalue")
+	require.ErrorContains(t, res.Err, `invalid --header: invalid gRPC header "novalue": must be key=value`)
+	res = sys.Run(zaptest.NewLogger(t), "chains", "edit", "cosmoshub", "grpc-headers", "grpc-timeout=1")
+	require.ErrorContains(t, res.Err, `keys starting with "grpc-" are reserved`)
+}
+
+func TestDynamicQuery_Height(t *testing.T) {
+	t.Parallel()
+
+	// The server answers at the requested height, and has pruned the state before height 100.
+	gRPCAddr := runGRPCReflectionServer(t,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			heights := md.Get("x-cosmos-block-height")
+			if len(heights) == 0 {
+				heights = []string{"120"}
+			}
+			if h, _ := strconv.Atoi(heights[0]); h < 100 {
+				return nil, status.Errorf(codes.InvalidArgument, "height %d is not available, lowest height is 100", h)
+			}
+			if err := grpc.SetHeader(ctx, metadata.Pairs("x-cosmos-block-height", heights[0])); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+	)
+
+	sys := NewSystem(t)
+
+	res := sys.MustRun(t, "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers")
+	require.Equal(t, "Height: 120\n", res.Stderr.String())
+	res = sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServers", "--height", "110")
+	require.Equal(t, "Height: 110\n", res.Stderr.String())
+	require.Contains(t, res.Stdout.String(), `"server"`)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--height", "7")
+	require.EqualError(t, res.Err, "the node no longer has the state at height 7, which was pruned; its earliest available height is 100 "+
+		"(rpc grpc.channelz.v1.Channelz.GetServers failed with status InvalidArgument: height 7 is not available, lowest height is 100)")
+	var pruned cmd.PrunedHeightError
+	require.ErrorAs(t, res.Err, &pruned)
+	require.Equal(t, int64(100), pruned.EarliestHeight)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--height", "-1")
+	require.ErrorContains(t, res.Err, "invalid --height: must not be negative")
+}
+
+func TestDynamicQuery_MaxRecvMsgSize(t *testing.T) {
+	t.Parallel()
+
+	// GetServers responds with a server whose name is larger than the 4MB default limit of gRPC.
+	const nameSize = 5 << 20
+	gRPCAddr := runGRPCReflectionServer(t,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if info.FullMethod != "/grpc.channelz.v1.Channelz/GetServers" {
+				return handler(ctx, req)
+			}
+			return &channelzpb.GetServersResponse{
+				Server: []*channelzpb.Server{{Ref: &channelzpb.ServerRef{ServerId: 1, Name: strings.Repeat("x", nameSize)}}},
+				End:    true,
+			}, nil
+		}),
+	)
+
+	sys := NewSystem(t)
+
+	// The default limit accepts the response.
+	res := sys.MustRun(t, "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--keepalive-time", "30s", "--keepalive-permit-without-stream")
+	require.Greater(t, res.Stdout.Len(), nameSize)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--max-recv-msg-size", "4194304")
+	require.Error(t, res.Err)
+	require.Empty(t, res.Stdout.String())
+	require.Contains(t, res.Stderr.String(), "received message larger than max")
+	require.Contains(t, res.Stderr.String(), "increase --max-recv-msg-size")
+
+	// The chain's grpc-max-recv-msg-size applies, and the flag overrides it.
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-max-recv-msg-size", "4194304")
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers")
+	require.Error(t, res.Err)
+	require.Contains(t, res.Stderr.String(), "increase --max-recv-msg-size")
+	_ = sys.MustRun(t, "dynamic", "query", "cosmoshub", "grpc.channelz.v1.Channelz", "GetServers", "--max-recv-msg-size", "8388608")
+
+	res = sys.Run(zaptest.NewLogger(t), "chains", "edit", "--", "cosmoshub", "grpc-keepalive-time", "-1s")
+	require.ErrorContains(t, res.Err, "must not be negative")
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--max-recv-msg-size", "0")
+	require.ErrorContains(t, res.Err, "invalid --max-recv-msg-size 0: must be positive")
+}
+
 func TestDynamicQuery_InputVariations(t *testing.T) {
 	// This test is NOT using parallel
 	// because querying the servers will pick up a server ID
@@ -209,19 +1261,279 @@ func TestDynamicQuery_InputVariations(t *testing.T) {
 	})
 }
 
-func runGRPCReflectionServer(t *testing.T) string {
+func TestDynamicCall_InputVariations(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	// The server has no sockets yet, so any of these input forms
+	// should produce the same pretty-printed response.
+	const input = `{"max_results":10}`
+	const wantResp = "{\n  \"end\": true\n}\n"
+
+	t.Run("no input", func(t *testing.T) {
+		res := sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServerSockets")
+		require.Equal(t, wantResp, res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+
+	t.Run("slash separator", func(t *testing.T) {
+		res := sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz/GetServerSockets", input)
+		require.Equal(t, wantResp, res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+
+	t.Run("file flag", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "")
+		require.NoError(t, err)
+		f.Close()
+		require.NoError(t, os.WriteFile(f.Name(), []byte(input), 0600))
+
+		res := sys.MustRun(t, "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServerSockets", "--file", f.Name())
+		require.Equal(t, wantResp, res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+
+	t.Run("stdin dash", func(t *testing.T) {
+		res := sys.MustRunWithInput(t, strings.NewReader(input), "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServerSockets", "-")
+		require.Equal(t, wantResp, res.Stdout.String())
+		require.Empty(t, res.Stderr.String())
+	})
+}
+
+func TestDynamicCall_Errors(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	t.Run("unknown service", func(t *testing.T) {
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Nope.GetServers")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Stderr.String(), `no service "grpc.channelz.v1.Nope" found`)
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.Nope")
+		require.Error(t, res.Err)
+		require.Contains(t, res.Stderr.String(), `service "grpc.channelz.v1.Channelz" has no method with name "Nope"`)
+	})
+
+	t.Run("rpc status", func(t *testing.T) {
+		res := sys.Run(zaptest.NewLogger(t), "dynamic", "call", gRPCAddr, "grpc.channelz.v1.Channelz.GetServer", `{"server_id":999999999}`)
+		require.Error(t, res.Err)
+		require.Empty(t, res.Stdout.String())
+		require.Contains(t, res.Stderr.String(), "rpc grpc.channelz.v1.Channelz.GetServer failed with status NotFound")
+	})
+}
+
+func runGRPCReflectionServer(t *testing.T, opts ...grpc.ServerOption) string {
 	t.Helper()
 
 	ln, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
+	serveGRPCReflection(t, ln, opts...)
 
-	srv := grpc.NewServer()
+	return ln.Addr().String()
+}
+
+// serveGRPCReflection serves the services of runGRPCReflectionServer on ln until the test ends.
+func serveGRPCReflection(t *testing.T, ln net.Listener, opts ...grpc.ServerOption) {
+	t.Helper()
+
+	srv := grpc.NewServer(opts...)
 	reflection.Register(srv)                         // Required for reflection.
 	channelzsvc.RegisterChannelzServiceToServer(srv) // Arbitrary other built-in gRPC service to confirm reflection behavior.
 	go func() {
 		srv.Serve(ln)
 	}()
 	t.Cleanup(srv.Stop)
+}
+
+func TestDynamicInspect_Targets(t *testing.T) {
+	t.Parallel()
+
+	const services = "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n"
+
+	// A server on a unix socket, given directly or as the chain's gRPC address.
+	socket := filepath.Join(t.TempDir(), "grpc.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	serveGRPCReflection(t, ln)
+
+	sys := NewSystem(t)
+	res := sys.MustRun(t, "dynamic", "inspect", "unix://"+socket)
+	require.Equal(t, services, res.Stdout.String())
+
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", "unix://"+socket)
+	res = sys.MustRun(t, "dynamic", "inspect", "cosmoshub", "--no-cache")
+	require.Equal(t, services, res.Stdout.String())
+
+	// Targets with a scheme are resolved by gRPC.
+	gRPCAddr := runGRPCReflectionServer(t)
+	_, port, err := net.SplitHostPort(gRPCAddr)
+	require.NoError(t, err)
+	for _, target := range []string{"passthrough:///" + gRPCAddr, "dns:///localhost:" + port} {
+		res = sys.MustRun(t, "dynamic", "inspect", target)
+		require.Equal(t, services, res.Stdout.String(), target)
+	}
+
+	// An IPv6 literal is given in brackets, if the system supports IPv6.
+	if ln, err := net.Listen("tcp", "[::1]:0"); err == nil {
+		serveGRPCReflection(t, ln)
+		res = sys.MustRun(t, "dynamic", "inspect", ln.Addr().String())
+		require.Equal(t, services, res.Stdout.String())
+	}
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "xds:///example.com:9090")
+	require.ErrorContains(t, res.Err, `unsupported gRPC address scheme "xds"`)
+}
+
+// runGRPCReflectionV1Server is like runGRPCReflectionServer,
+// but the server only implements version v1 of the reflection service, instead of v1alpha.
+func runGRPCReflectionV1Server(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	rpbv1.RegisterServerReflectionServer(srv, reflectionV1Server{v1alpha: reflection.NewServer(reflection.ServerOptions{Services: srv})})
+	channelzsvc.RegisterChannelzServiceToServer(srv)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
 
 	return ln.Addr().String()
 }
+
+// reflectionV1Server serves version v1 of the reflection service through the v1alpha implementation,
+// as the messages of both versions are identical on the wire.
+type reflectionV1Server struct {
+	rpbv1.UnimplementedServerReflectionServer
+	v1alpha rpb.ServerReflectionServer
+}
+
+func (s reflectionV1Server) ServerReflectionInfo(stream rpbv1.ServerReflection_ServerReflectionInfoServer) error {
+	return s.v1alpha.ServerReflectionInfo(reflectionV1Stream{stream})
+}
+
+// reflectionV1Stream adapts a v1 reflection stream to the v1alpha messages.
+type reflectionV1Stream struct {
+	rpbv1.ServerReflection_ServerReflectionInfoServer
+}
+
+func (s reflectionV1Stream) Send(res *rpb.ServerReflectionResponse) error {
+	b, err := protov2.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var v1 rpbv1.ServerReflectionResponse
+	if err := protov2.Unmarshal(b, &v1); err != nil {
+		return err
+	}
+	return s.ServerReflection_ServerReflectionInfoServer.Send(&v1)
+}
+
+func (s reflectionV1Stream) Recv() (*rpb.ServerReflectionRequest, error) {
+	req, err := s.ServerReflection_ServerReflectionInfoServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	b, err := protov2.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var v1alpha rpb.ServerReflectionRequest
+	if err := protov2.Unmarshal(b, &v1alpha); err != nil {
+		return nil, err
+	}
+	return &v1alpha, nil
+}
+
+func TestDynamicInspect_ReflectionVersions(t *testing.T) {
+	t.Parallel()
+
+	// A server only implementing v1 is inspected through v1.
+	sys := NewSystem(t)
+	res := sys.MustRun(t, "dynamic", "inspect", runGRPCReflectionV1Server(t), "--no-cache", "--log-level", "debug", "--log-format", "logfmt")
+	require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1.ServerReflection\n", res.Stdout.String())
+	require.Contains(t, res.Stderr.String(), `msg="Using gRPC reflection" version=v1`+"\n")
+	require.NotContains(t, res.Stderr.String(), "v1alpha")
+
+	// A server only implementing v1alpha is inspected through v1alpha, once v1 has been found to be unimplemented.
+	sys = NewSystem(t)
+	res = sys.MustRun(t, "dynamic", "inspect", runGRPCReflectionServer(t), "--no-cache", "--log-level", "debug", "--log-format", "logfmt")
+	require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+	logs := res.Stderr.String()
+	unimplemented := strings.Index(logs, `msg="Server does not implement gRPC reflection version" version=v1`+"\n")
+	used := strings.Index(logs, `msg="Using gRPC reflection" version=v1alpha`+"\n")
+	require.NotEqual(t, -1, unimplemented, logs)
+	require.Greater(t, used, unimplemented, logs)
+}
+
+func TestDynamicInspect_Proxy(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	// Nothing listens at the proxy, so the connections through it fail.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadProxy := "socks5://" + ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	sys := NewSystem(t)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", gRPCAddr)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "proxy", deadProxy)
+
+	res := sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--timeout", "500ms")
+	require.Error(t, res.Err)
+
+	// --proxy overrides the chain's proxy.
+	res = sys.MustRun(t, "dynamic", "inspect", "cosmoshub", "--proxy", "direct")
+	require.Equal(t, "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n", res.Stdout.String())
+
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "proxy", "direct")
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--timeout", "500ms", "--proxy", deadProxy)
+	require.Error(t, res.Err)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", "cosmoshub", "--proxy", "tor")
+	require.ErrorContains(t, res.Err, `invalid --proxy "tor"`)
+}
+
+func TestDynamic_DefaultChain(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	gRPCAddr := runGRPCReflectionServer(t)
+	_ = sys.MustRun(t, "chains", "edit", "osmosis", "grpc-addr", gRPCAddr)
+	_ = sys.MustRun(t, "config", "set-default-chain", "osmosis")
+
+	// The chain argument may be omitted.
+	want := "grpc.channelz.v1.Channelz\ngrpc.reflection.v1alpha.ServerReflection\n"
+	res := sys.MustRun(t, "dynamic", "list-services")
+	require.Equal(t, want, res.Stdout.String())
+	res = sys.MustRun(t, "dynamic", "list-services", "osmosis")
+	require.Equal(t, want, res.Stdout.String())
+	res = sys.MustRun(t, "dynamic", "list-services", gRPCAddr)
+	require.Equal(t, want, res.Stdout.String())
+
+	// An argument that is not a chain is the next argument.
+	res = sys.MustRun(t, "dynamic", "list-methods", "grpc.channelz.v1.Channelz")
+	require.Contains(t, res.Stdout.String(), "GetTopChannels")
+	res = sys.MustRun(t, "dynamic", "inspect", "grpc.channelz.v1.Channelz", "GetServer")
+	require.Contains(t, res.Stdout.String(), "server_id")
+
+	// Unless the other arguments need it to be a chain.
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "list-services", "osmosiss")
+	require.ErrorContains(t, res.Err, `"osmosiss" did not look like host:port and no chain exists by that name`)
+
+	res = sys.MustRun(t, "dynamic", "list-services", "--help")
+	require.Contains(t, res.Stdout.String(), "list-services [CHAIN_NAME_OR_GRPC_ADDR]")
+}
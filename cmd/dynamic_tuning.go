@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gRPCTuningFromFlags returns the message size and keepalive settings of the connection:
+// those of the chain if chain is not nil, each overridden by its flag if set.
+func gRPCTuningFromFlags(cmd *cobra.Command, chain *client.ChainClientConfig) (client.GRPCTuning, error) {
+	var t client.GRPCTuning
+	if chain != nil {
+		var err error
+		if t, err = chain.GRPCTuning(); err != nil {
+			return client.GRPCTuning{}, err
+		}
+	}
+
+	flags := cmd.Flags()
+	if chain == nil || flags.Changed(gRPCMaxRecvMsgSizeFlag) {
+		size, err := flags.GetInt(gRPCMaxRecvMsgSizeFlag)
+		if err != nil {
+			return client.GRPCTuning{}, err
+		}
+		if size <= 0 {
+			return client.GRPCTuning{}, fmt.Errorf("invalid --%s %d: must be positive", gRPCMaxRecvMsgSizeFlag, size)
+		}
+		t.MaxRecvMsgSize = size
+	}
+	if flags.Changed(gRPCKeepaliveTimeFlag) {
+		d, err := flags.GetDuration(gRPCKeepaliveTimeFlag)
+		if err != nil {
+			return client.GRPCTuning{}, err
+		}
+		if d < 0 {
+			return client.GRPCTuning{}, fmt.Errorf("invalid --%s %s: must not be negative", gRPCKeepaliveTimeFlag, d)
+		}
+		t.KeepaliveTime = d
+	}
+	if flags.Changed(gRPCKeepaliveTimeoutFlag) {
+		d, err := flags.GetDuration(gRPCKeepaliveTimeoutFlag)
+		if err != nil {
+			return client.GRPCTuning{}, err
+		}
+		if d < 0 {
+			return client.GRPCTuning{}, fmt.Errorf("invalid --%s %s: must not be negative", gRPCKeepaliveTimeoutFlag, d)
+		}
+		t.KeepaliveTimeout = d
+	}
+	if flags.Changed(gRPCKeepaliveWithoutStreamFlag) {
+		b, err := flags.GetBool(gRPCKeepaliveWithoutStreamFlag)
+		if err != nil {
+			return client.GRPCTuning{}, err
+		}
+		t.KeepalivePermitWithoutStream = b
+	}
+	return t, nil
+}
+
+// maxRecvMsgSizeHintDialOptions returns the options adding to the errors of responses exceeding the maximum message size
+// a hint to raise it, as the error of gRPC only states the sizes.
+func maxRecvMsgSizeHintDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return withMaxRecvMsgSizeHint(invoker(ctx, method, req, reply, cc, opts...))
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			s, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				return nil, withMaxRecvMsgSizeHint(err)
+			}
+			return maxRecvMsgSizeHintStream{ClientStream: s}, nil
+		}),
+	}
+}
+
+// maxRecvMsgSizeHintStream adds the hint of maxRecvMsgSizeHintDialOptions to the errors of the messages it receives.
+type maxRecvMsgSizeHintStream struct {
+	grpc.ClientStream
+}
+
+func (s maxRecvMsgSizeHintStream) RecvMsg(m interface{}) error {
+	return withMaxRecvMsgSizeHint(s.ClientStream.RecvMsg(m))
+}
+
+// withMaxRecvMsgSizeHint returns err with a hint to raise the maximum message size,
+// if it is the error of a response exceeding it, or else err unchanged.
+func withMaxRecvMsgSizeHint(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted || !strings.Contains(st.Message(), "received message larger than max") {
+		return err
+	}
+	return status.Errorf(
+		codes.ResourceExhausted,
+		"%s; increase --%s, or the chain's grpc-max-recv-msg-size, to accept larger responses",
+		st.Message(), gRPCMaxRecvMsgSizeFlag,
+	)
+}
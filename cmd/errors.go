@@ -1,22 +1,101 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jhump/protoreflect/desc"
+	"github.com/strangelove-ventures/lens/client"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-var _ error = ChainNotFoundError{}
+// Process exit statuses for errors, so that scripts can tell failures apart without matching messages.
+const (
+	ErrCodeGeneric         = 1
+	ErrCodeChainNotFound   = 2
+	ErrCodeConnection      = 3
+	ErrCodeServiceNotFound = 4
+	ErrCodeTxFailed        = 5
+
+	// ErrCodeSurfaceDiff is returned by dynamic compare when the servers differ,
+	// by query params --diff when the params of the chains do,
+	// and by dynamic replay when replayed calls differ from their recording.
+	// As with diff(1), it is 2, and can only be confused with ErrCodeChainNotFound
+	// if the compared chains are not configured.
+	ErrCodeSurfaceDiff = 2
+)
+
+// ExitCoder is implemented by errors requesting a specific process exit status.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// errorDetailer is implemented by errors with structured details,
+// included in the output of --errors-json.
+type errorDetailer interface {
+	ErrorDetails() map[string]interface{}
+}
+
+// errorReport is the serialization of an error written with --errors-json.
+type errorReport struct {
+	Type    string                 `json:"type"`
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// newErrorReport classifies err, using the outermost error in its chain
+// that requests an exit status, or failing that, a failed transaction.
+func newErrorReport(err error) errorReport {
+	r := errorReport{
+		Type:    "Error",
+		Code:    ErrCodeGeneric,
+		Message: err.Error(),
+	}
+
+	var exitCoder ExitCoder
+	var txErr client.TxFailedError
+	switch {
+	case errors.As(err, &exitCoder):
+		r.Type = reflect.TypeOf(exitCoder).Name()
+		r.Code = exitCoder.ExitCode()
+		if d, ok := exitCoder.(errorDetailer); ok {
+			r.Details = d.ErrorDetails()
+		}
+	case errors.As(err, &txErr):
+		r.Type = reflect.TypeOf(txErr).Name()
+		r.Code = ErrCodeTxFailed
+		r.Details = map[string]interface{}{
+			"code":      txErr.Code,
+			"codespace": txErr.Codespace,
+			"txhash":    txErr.TxHash,
+		}
+	}
+	return r
+}
+
+var _ ExitCoder = ChainNotFoundError{}
 
 // ChainNotFoundError is used when a requested chain does not exist.
-// Its error message includes the list of known chains.
+// Its error message suggests the known chain closest to the requested one,
+// and includes the first few known chains.
 type ChainNotFoundError struct {
 	Requested string
 	Config    *Config
 }
 
+// maxListedChains is the most chain names included in a ChainNotFoundError message.
+const maxListedChains = 10
+
 func (e ChainNotFoundError) Error() string {
 	available := make([]string, 0, len(e.Config.Chains))
 	for chainName := range e.Config.Chains {
@@ -24,36 +103,108 @@ func (e ChainNotFoundError) Error() string {
 	}
 	sort.Strings(available)
 
-	return fmt.Sprintf(
-		"no chain %q found (available chains: %s)",
-		e.Requested,
-		strings.Join(available, ", "),
-	)
+	var b strings.Builder
+	fmt.Fprintf(&b, "no chain %q found", e.Requested)
+	if matches := closestMatches(e.Requested, available, 1); len(matches) > 0 {
+		fmt.Fprintf(&b, "; did you mean %q?", matches[0])
+	}
+
+	if len(available) > maxListedChains {
+		fmt.Fprintf(
+			&b,
+			" (available chains: %s, ...and %d more; run `%s chains list`)",
+			strings.Join(available[:maxListedChains], ", "),
+			len(available)-maxListedChains,
+			appName,
+		)
+	} else {
+		fmt.Fprintf(&b, " (available chains: %s)", strings.Join(available, ", "))
+	}
+
+	return b.String()
+}
+
+func (e ChainNotFoundError) ExitCode() int {
+	return ErrCodeChainNotFound
 }
 
-var _ error = GRPCServiceNotFoundError{}
+func (e ChainNotFoundError) ErrorDetails() map[string]interface{} {
+	available := make([]string, 0, len(e.Config.Chains))
+	for chainName := range e.Config.Chains {
+		available = append(available, chainName)
+	}
+	sort.Strings(available)
+
+	return map[string]interface{}{
+		"requested": e.Requested,
+		"available": available,
+	}
+}
+
+var _ ExitCoder = NoDefaultChainError{}
+
+// NoDefaultChainError is used when a command's chain argument is omitted,
+// but no default chain is configured, set with --chain, or set in LENS_CHAIN.
+type NoDefaultChainError struct{}
+
+func (e NoDefaultChainError) Error() string {
+	return "no chain specified and no default chain configured"
+}
+
+func (e NoDefaultChainError) ExitCode() int {
+	return ErrCodeChainNotFound
+}
+
+var _ ExitCoder = GRPCServiceNotFoundError{}
 
 // GRPCServiceNotFoundError is used when a requested gRPC service does not exist.
-// Its error message includes the provided available services.
+// Its error message suggests the available services closest to the requested one,
+// and includes every available service if Verbose is set.
 type GRPCServiceNotFoundError struct {
 	Requested string
 	Available []string
+	Verbose   bool
 }
 
 func (e GRPCServiceNotFoundError) Error() string {
-	sort.Strings(e.Available)
-	// TODO: would be nice to suggest close matches here.
-	return fmt.Sprintf(
-		"no service %q found (available services: %s)",
-		e.Requested,
-		strings.Join(e.Available, ", "),
-	)
+	// Sort a copy: the value receiver still shares the backing array of the caller's slice.
+	available := append([]string(nil), e.Available...)
+	sort.Strings(available)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "no service %q found", e.Requested)
+	if matches := closestMatches(e.Requested, available, maxSuggestions); len(matches) > 0 {
+		fmt.Fprintf(&b, "; did you mean %s?", strings.Join(matches, ", "))
+	}
+
+	if e.Verbose {
+		fmt.Fprintf(&b, " (available services: %s)", strings.Join(available, ", "))
+	} else {
+		fmt.Fprintf(&b, " (use --verbose to list all %d available services)", len(available))
+	}
+
+	return b.String()
+}
+
+func (e GRPCServiceNotFoundError) ExitCode() int {
+	return ErrCodeServiceNotFound
+}
+
+func (e GRPCServiceNotFoundError) ErrorDetails() map[string]interface{} {
+	available := append([]string(nil), e.Available...)
+	sort.Strings(available)
+
+	return map[string]interface{}{
+		"requested": e.Requested,
+		"available": available,
+	}
 }
 
-var _ error = GRPCMethodNotFoundError{}
+var _ ExitCoder = GRPCMethodNotFoundError{}
 
 // GRPCMethodNotFoundError is used when a requested gRPC method does not exist.
-// Its error message includes the provided available services.
+// Its error message suggests the available methods closest to the requested one,
+// and includes every available method.
 type GRPCMethodNotFoundError struct {
 	TargetService string
 	Requested     string
@@ -67,10 +218,627 @@ func (e GRPCMethodNotFoundError) Error() string {
 	}
 	sort.Strings(methodNames)
 
+	var b strings.Builder
+	fmt.Fprintf(&b, "service %q has no method with name %q", e.TargetService, e.Requested)
+	if matches := closestMatches(e.Requested, methodNames, maxSuggestions); len(matches) > 0 {
+		fmt.Fprintf(&b, "; did you mean %s?", strings.Join(matches, ", "))
+	}
+	fmt.Fprintf(&b, " (available methods: %s)", strings.Join(methodNames, ", "))
+
+	return b.String()
+}
+
+func (e GRPCMethodNotFoundError) ExitCode() int {
+	return ErrCodeServiceNotFound
+}
+
+func (e GRPCMethodNotFoundError) ErrorDetails() map[string]interface{} {
+	available := make([]string, len(e.Available))
+	for i, md := range e.Available {
+		available[i] = md.GetName()
+	}
+	sort.Strings(available)
+
+	return map[string]interface{}{
+		"service":   e.TargetService,
+		"requested": e.Requested,
+		"available": available,
+	}
+}
+
+var _ ExitCoder = GRPCMessageNotFoundError{}
+
+// GRPCMessageNotFoundError is used when a requested message type is not known to a gRPC server.
+type GRPCMessageNotFoundError struct {
+	Requested string
+}
+
+func (e GRPCMessageNotFoundError) Error() string {
+	return fmt.Sprintf("no message %q found (expected a fully qualified name, such as cosmos.bank.v1beta1.MsgSend)", e.Requested)
+}
+
+func (e GRPCMessageNotFoundError) ExitCode() int {
+	return ErrCodeServiceNotFound
+}
+
+func (e GRPCMessageNotFoundError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"requested": e.Requested,
+	}
+}
+
+var _ ExitCoder = WasmNotSupportedError{}
+
+// WasmNotSupportedError is used when a chain's gRPC endpoint does not serve the services of the CosmWasm module.
+type WasmNotSupportedError struct {
+	Chain   string
+	Service string
+}
+
+func (e WasmNotSupportedError) Error() string {
+	return fmt.Sprintf("chain %s does not support wasm: its gRPC endpoint does not serve %s", e.Chain, e.Service)
+}
+
+func (e WasmNotSupportedError) ExitCode() int {
+	return ErrCodeServiceNotFound
+}
+
+func (e WasmNotSupportedError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"chain":   e.Chain,
+		"service": e.Service,
+	}
+}
+
+var _ ExitCoder = GRPCCallError{}
+
+// GRPCCallError is used when a dynamically invoked gRPC method
+// returns an error status from the remote server.
+type GRPCCallError struct {
+	Method  string
+	Code    codes.Code
+	Message string
+}
+
+func (e GRPCCallError) Error() string {
 	return fmt.Sprintf(
-		"service %q has no method with name %q (available methods: %s)",
-		e.TargetService,
-		e.Requested,
-		strings.Join(methodNames, ", "),
+		"rpc %s failed with status %s: %s",
+		e.Method,
+		e.Code,
+		e.Message,
 	)
 }
+
+// ExitCode distinguishes statuses meaning the server could not be reached,
+// or does not implement the method, from other failures.
+func (e GRPCCallError) ExitCode() int {
+	switch e.Code {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ErrCodeConnection
+	case codes.Unimplemented:
+		return ErrCodeServiceNotFound
+	default:
+		return ErrCodeGeneric
+	}
+}
+
+func (e GRPCCallError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"method":  e.Method,
+		"status":  e.Code.String(),
+		"message": e.Message,
+	}
+}
+
+var _ ExitCoder = TxSimulationError{}
+
+// TxSimulationError is used when the chain rejects the simulation of a transaction,
+// with an explanation of the failure if it is a common one.
+type TxSimulationError struct {
+	client.SimulationError
+	Hint string
+}
+
+func (e TxSimulationError) Error() string {
+	if e.Hint == "" {
+		return e.SimulationError.Error()
+	}
+	return fmt.Sprintf("%s\n%s", e.SimulationError.Error(), e.Hint)
+}
+
+// ExitCode is that of a failed transaction, which the simulated transaction would be.
+func (e TxSimulationError) ExitCode() int {
+	return ErrCodeTxFailed
+}
+
+func (e TxSimulationError) ErrorDetails() map[string]interface{} {
+	d := map[string]interface{}{
+		"code":      e.Code,
+		"codespace": e.Codespace,
+		"log":       e.Log,
+	}
+	if e.Hint != "" {
+		d["hint"] = e.Hint
+	}
+	return d
+}
+
+var _ ExitCoder = PrunedHeightError{}
+
+// PrunedHeightError is used when a dynamically invoked gRPC method fails
+// because the node no longer has the state of the requested block height.
+type PrunedHeightError struct {
+	GRPCCallError
+
+	// Height is the requested height, or 0 if the node did not tell it.
+	Height int64
+
+	// EarliestHeight is the earliest height whose state the node has, or 0 if the node did not tell it.
+	EarliestHeight int64
+}
+
+var (
+	prunedMessageRE   = regexp.MustCompile(`pruned|version does not exist|is not available, lowest height is`)
+	requestedHeightRE = regexp.MustCompile(`height (\d+)`)
+	latestHeightRE    = regexp.MustCompile(`latest height: (\d+)`)
+	lowestHeightRE    = regexp.MustCompile(`lowest height is (\d+)`)
+)
+
+// newPrunedHeightError returns the PrunedHeightError for the error status st of a call,
+// or false if st does not report pruned state.
+// The heights are read from the messages of the Cosmos SDK and CometBFT,
+// and the earliest height from the earliest_height metadata of an ErrorInfo detail.
+func newPrunedHeightError(callErr GRPCCallError, st *status.Status) (PrunedHeightError, bool) {
+	if !prunedMessageRE.MatchString(st.Message()) {
+		return PrunedHeightError{}, false
+	}
+	e := PrunedHeightError{
+		GRPCCallError:  callErr,
+		Height:         matchHeight(requestedHeightRE, st.Message()),
+		EarliestHeight: matchHeight(lowestHeightRE, st.Message()),
+	}
+	// The state of heights after the latest one does not exist either, but was not pruned.
+	if latest := matchHeight(latestHeightRE, st.Message()); latest > 0 && e.Height > latest {
+		return PrunedHeightError{}, false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			if h, err := strconv.ParseInt(info.GetMetadata()["earliest_height"], 10, 64); err == nil {
+				e.EarliestHeight = h
+			}
+		}
+	}
+	return e, true
+}
+
+// matchHeight returns the height captured by re in s, or 0 if re does not match.
+func matchHeight(re *regexp.Regexp, s string) int64 {
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	h, _ := strconv.ParseInt(m[1], 10, 64)
+	return h
+}
+
+func (e PrunedHeightError) Error() string {
+	var b strings.Builder
+	b.WriteString("the node no longer has the state")
+	if e.Height > 0 {
+		fmt.Fprintf(&b, " at height %d", e.Height)
+	}
+	b.WriteString(", which was pruned")
+	if e.EarliestHeight > 0 {
+		fmt.Fprintf(&b, "; its earliest available height is %d", e.EarliestHeight)
+	} else {
+		b.WriteString("; query a later height, or an archive node")
+	}
+	fmt.Fprintf(&b, " (%s)", e.GRPCCallError.Error())
+	return b.String()
+}
+
+func (e PrunedHeightError) ErrorDetails() map[string]interface{} {
+	details := e.GRPCCallError.ErrorDetails()
+	if e.Height > 0 {
+		details["height"] = e.Height
+	}
+	if e.EarliestHeight > 0 {
+		details["earliest_height"] = e.EarliestHeight
+	}
+	return details
+}
+
+var _ ExitCoder = SurfaceDiffError{}
+
+// SurfaceDiffError is returned by dynamic compare when the compared servers differ,
+// after the differences have been written to the output.
+// It causes the process to exit with a distinct status, for use in scripts.
+type SurfaceDiffError struct {
+	// Services is the number of services that differ.
+	Services int
+}
+
+func (e SurfaceDiffError) Error() string {
+	return fmt.Sprintf("%d services differ between the compared servers", e.Services)
+}
+
+// ExitCode returns the process exit status to use for this error.
+func (e SurfaceDiffError) ExitCode() int {
+	return ErrCodeSurfaceDiff
+}
+
+func (e SurfaceDiffError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"services": e.Services}
+}
+
+var _ ExitCoder = ParamsDiffError{}
+
+// ParamsDiffError is returned by query params --diff when the params of the compared chains differ,
+// after the differences have been written to the output.
+// Like SurfaceDiffError, it causes the process to exit with a distinct status, for use in scripts.
+type ParamsDiffError struct {
+	// Params is the number of params that differ.
+	Params int
+}
+
+func (e ParamsDiffError) Error() string {
+	return fmt.Sprintf("%d params differ between the compared chains", e.Params)
+}
+
+// ExitCode returns the process exit status to use for this error.
+func (e ParamsDiffError) ExitCode() int {
+	return ErrCodeSurfaceDiff
+}
+
+func (e ParamsDiffError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"params": e.Params}
+}
+
+var _ ExitCoder = ReplayDiffError{}
+
+// ReplayDiffError is returned by dynamic replay when replayed calls differ from their recording, or fail,
+// after the results of the calls have been written to the output.
+// Like SurfaceDiffError, it causes the process to exit with a distinct status, for use in scripts.
+type ReplayDiffError struct {
+	// Calls is the number of calls that differ or fail, out of Replayed.
+	Calls, Replayed int
+}
+
+func (e ReplayDiffError) Error() string {
+	return fmt.Sprintf("%d of %d replayed calls differ from their recording", e.Calls, e.Replayed)
+}
+
+// ExitCode returns the process exit status to use for this error.
+func (e ReplayDiffError) ExitCode() int {
+	return ErrCodeSurfaceDiff
+}
+
+func (e ReplayDiffError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"calls": e.Calls, "replayed": e.Replayed}
+}
+
+var _ ExitCoder = TxCodeError{}
+
+// TxCodeError is returned by query wait-tx when the awaited transaction failed, after it has been written.
+// Unlike a plain client.TxFailedError, it causes the process to exit with the code of the transaction,
+// or with ErrCodeTxFailed if the code does not fit in an exit status.
+type TxCodeError struct {
+	client.TxFailedError
+}
+
+func (e TxCodeError) Error() string {
+	if e.Codespace != "" {
+		return fmt.Sprintf("transaction %s failed with code %d (%s)", e.TxHash, e.Code, e.Codespace)
+	}
+	return fmt.Sprintf("transaction %s failed with code %d", e.TxHash, e.Code)
+}
+
+// ExitCode returns the process exit status to use for this error.
+func (e TxCodeError) ExitCode() int {
+	if e.Code > 255 {
+		return ErrCodeTxFailed
+	}
+	return int(e.Code)
+}
+
+func (e TxCodeError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"code":      e.Code,
+		"codespace": e.Codespace,
+		"txhash":    e.TxHash,
+	}
+}
+
+var _ ExitCoder = TxConditionError{}
+
+// TxConditionError is returned by query wait-tx when the awaited transaction does not meet the given conditions,
+// after it has been written.
+type TxConditionError struct {
+	TxHash string
+	// Failed lists the conditions not met, each with the actual value of its field.
+	Failed []string
+}
+
+func (e TxConditionError) Error() string {
+	return fmt.Sprintf("transaction %s does not meet the condition %s", e.TxHash, strings.Join(e.Failed, ", nor "))
+}
+
+// ExitCode returns the process exit status to use for this error.
+func (e TxConditionError) ExitCode() int {
+	return ErrCodeTxFailed
+}
+
+func (e TxConditionError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"txhash": e.TxHash,
+		"failed": e.Failed,
+	}
+}
+
+var _ ExitCoder = UnreachableEndpointsError{}
+
+// UnreachableEndpointsError is returned by chains status when any probed endpoint is unreachable,
+// after the status of every endpoint has been written to the output.
+type UnreachableEndpointsError struct {
+	// Endpoints is the number of unreachable endpoints.
+	Endpoints int
+}
+
+func (e UnreachableEndpointsError) Error() string {
+	return fmt.Sprintf("%d chain endpoints are unreachable", e.Endpoints)
+}
+
+func (e UnreachableEndpointsError) ExitCode() int {
+	return ErrCodeConnection
+}
+
+func (e UnreachableEndpointsError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"endpoints": e.Endpoints}
+}
+
+var _ ExitCoder = ConnectionError{}
+
+// ConnectionError is used when a remote endpoint cannot be connected to.
+type ConnectionError struct {
+	Addr string
+	Err  error
+}
+
+func (e ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+func (e ConnectionError) ExitCode() int {
+	return ErrCodeConnection
+}
+
+func (e ConnectionError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"addr": e.Addr}
+}
+
+var _ ExitCoder = TimeoutError{}
+
+// TimeoutError is returned when a command does not complete within its --timeout.
+type TimeoutError struct {
+	Timeout time.Duration
+
+	// Op describes what the command was waiting for when it timed out, such as "waiting for tx inclusion".
+	Op string
+
+	Err error
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s %s", e.Timeout, e.Op)
+}
+
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e TimeoutError) ExitCode() int {
+	return ErrCodeConnection
+}
+
+func (e TimeoutError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"timeout": e.Timeout.String(), "operation": e.Op}
+}
+
+var _ ExitCoder = InvalidConfigError{}
+
+// InvalidConfigError is returned by config validate when the configuration has any problems,
+// after every problem has been written to the output.
+type InvalidConfigError struct {
+	// Problems is the number of problems found.
+	Problems int
+}
+
+func (e InvalidConfigError) Error() string {
+	return fmt.Sprintf("%d problems found in the configuration", e.Problems)
+}
+
+func (e InvalidConfigError) ExitCode() int {
+	return ErrCodeGeneric
+}
+
+func (e InvalidConfigError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"problems": e.Problems}
+}
+
+var _ ExitCoder = CorruptConfigError{}
+
+// CorruptConfigError is returned when the configuration file cannot be parsed.
+type CorruptConfigError struct {
+	Path string
+
+	// Backup is the path of the backup of the previous version of the file, or empty if there is none.
+	Backup string
+
+	Err error
+}
+
+func (e CorruptConfigError) Error() string {
+	if e.Backup == "" {
+		return fmt.Sprintf("configuration file %s is corrupt: %v; fix it with %s chains editor, or remove it to recreate the default configuration", e.Path, e.Err, appName)
+	}
+	return fmt.Sprintf("configuration file %s is corrupt: %v; its previous version is backed up at %s, restore it with: cp %s %s", e.Path, e.Err, e.Backup, e.Backup, e.Path)
+}
+
+func (e CorruptConfigError) Unwrap() error {
+	return e.Err
+}
+
+func (e CorruptConfigError) ExitCode() int {
+	return ErrCodeGeneric
+}
+
+func (e CorruptConfigError) ErrorDetails() map[string]interface{} {
+	details := map[string]interface{}{"path": e.Path}
+	if e.Backup != "" {
+		details["backup"] = e.Backup
+	}
+	return details
+}
+
+var _ ExitCoder = FailedChainsError{}
+
+// FailedChainsError is returned by a query run on several chains with --chains or --all-chains
+// when it fails on any of them, after the result or error of every chain has been written to the output.
+type FailedChainsError struct {
+	// Chains are the names of the chains the query failed on, sorted.
+	Chains []string
+
+	// Queried is the number of chains queried.
+	Queried int
+}
+
+func (e FailedChainsError) Error() string {
+	return fmt.Sprintf("query failed on %d of %d chains: %s", len(e.Chains), e.Queried, strings.Join(e.Chains, ", "))
+}
+
+func (e FailedChainsError) ExitCode() int {
+	return ErrCodeGeneric
+}
+
+func (e FailedChainsError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"chains": e.Chains, "queried": e.Queried}
+}
+
+// maxSuggestions is the most "did you mean" candidates included in an error message.
+const maxSuggestions = 3
+
+// closestMatches returns up to n candidates that look like likely typos of target,
+// closest first.
+// Candidates containing target, or contained in it, are ranked ahead of the others,
+// which are ranked by edit distance and only included if that distance is small
+// relative to the length of target.
+// Comparisons are case-insensitive.
+func closestMatches(target string, candidates []string, n int) []string {
+	type match struct {
+		name string
+
+		// Substring matches always rank ahead of edit distance matches.
+		substring bool
+
+		// Difference in length for substring matches,
+		// or edit distance otherwise.
+		score int
+	}
+
+	lowerTarget := strings.ToLower(target)
+	maxDistance := len(target) / 4
+	switch {
+	case maxDistance < 1:
+		maxDistance = 1
+	case maxDistance > 3:
+		maxDistance = 3
+	}
+
+	var matches []match
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if strings.Contains(lc, lowerTarget) || strings.Contains(lowerTarget, lc) {
+			matches = append(matches, match{name: c, substring: true, score: abs(len(c) - len(target))})
+			continue
+		}
+		if d := editDistance(lowerTarget, lc); d <= maxDistance {
+			matches = append(matches, match{name: c, score: d})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].substring != matches[j].substring {
+			return matches[i].substring
+		}
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of single-byte insertions, deletions, substitutions,
+// or transpositions of adjacent bytes needed to turn a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(
+				d[i-1][j]+1,      // Deletion.
+				d[i][j-1]+1,      // Insertion.
+				d[i-1][j-1]+cost, // Substitution.
+			)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if t := d[i-2][j-2] + 1; t < d[i][j] {
+					d[i][j] = t // Transposition.
+				}
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
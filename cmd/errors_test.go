@@ -1,6 +1,12 @@
 package cmd_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/jhump/protoreflect/desc"
@@ -8,6 +14,12 @@ import (
 	"github.com/strangelove-ventures/lens/client"
 	"github.com/strangelove-ventures/lens/cmd"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestChainNotFoundError(t *testing.T) {
@@ -32,17 +44,126 @@ func TestChainNotFoundError(t *testing.T) {
 	)
 }
 
+func TestChainNotFoundError_Suggestions(t *testing.T) {
+	cfg := &cmd.Config{
+		Chains: map[string]*client.ChainClientConfig{
+			"cosmoshub": nil,
+			"osmosis":   nil,
+			"juno":      nil,
+		},
+	}
+
+	for _, tc := range []struct {
+		requested string
+		want      string
+	}{
+		{
+			// Missing letter.
+			requested: "cosmohub",
+			want:      `no chain "cosmohub" found; did you mean "cosmoshub"? (available chains: cosmoshub, juno, osmosis)`,
+		},
+		{
+			// Transposed letters.
+			requested: "osmsois",
+			want:      `no chain "osmsois" found; did you mean "osmosis"? (available chains: cosmoshub, juno, osmosis)`,
+		},
+		{
+			// Exact prefix.
+			requested: "cosmos",
+			want:      `no chain "cosmos" found; did you mean "cosmoshub"? (available chains: cosmoshub, juno, osmosis)`,
+		},
+	} {
+		e := cmd.ChainNotFoundError{
+			Requested: tc.requested,
+			Config:    cfg,
+		}
+		require.Equal(t, tc.want, e.Error(), tc.requested)
+	}
+}
+
+func TestChainNotFoundError_Truncated(t *testing.T) {
+	cfg := &cmd.Config{
+		Chains: map[string]*client.ChainClientConfig{},
+	}
+	for i := 0; i < 15; i++ {
+		cfg.Chains[fmt.Sprintf("chain%02d", i)] = nil
+	}
+
+	e := cmd.ChainNotFoundError{
+		Requested: "x",
+		Config:    cfg,
+	}
+
+	require.Equal(
+		t,
+		`no chain "x" found (available chains: chain00, chain01, chain02, chain03, chain04, chain05, chain06, chain07, chain08, chain09, ...and 5 more; run `+"`lens chains list`"+`)`,
+		e.Error(),
+	)
+}
+
 func TestGRPCServiceNotFoundError(t *testing.T) {
 	e := cmd.GRPCServiceNotFoundError{
 		Requested: "svc1",
 		Available: []string{"svc2", "svc3"},
+		Verbose:   true,
 	}
 
 	require.Equal(
 		t,
-		`no service "svc1" found (available services: svc2, svc3)`,
+		`no service "svc1" found; did you mean svc2, svc3? (available services: svc2, svc3)`,
 		e.Error(),
 	)
+
+	// The message lists the services sorted, without sorting those of the error.
+	e.Available = []string{"svc3", "svc2"}
+	require.Contains(t, e.Error(), "(available services: svc2, svc3)")
+	require.Equal(t, []string{"svc3", "svc2"}, e.Available)
+}
+
+func TestGRPCServiceNotFoundError_Suggestions(t *testing.T) {
+	available := []string{
+		"cosmos.auth.v1beta1.Query",
+		"cosmos.bank.v1beta1.Msg",
+		"cosmos.bank.v1beta1.Query",
+		"cosmos.staking.v1beta1.Query",
+		"grpc.reflection.v1alpha.ServerReflection",
+	}
+
+	for _, tc := range []struct {
+		requested string
+		want      string
+	}{
+		{
+			// Typo.
+			requested: "cosmos.bank.v1beta1.Qeury",
+			want:      `no service "cosmos.bank.v1beta1.Qeury" found; did you mean cosmos.bank.v1beta1.Query? (use --verbose to list all 5 available services)`,
+		},
+		{
+			// Case differences are ignored.
+			requested: "cosmos.bank.v1beta1.query",
+			want:      `no service "cosmos.bank.v1beta1.query" found; did you mean cosmos.bank.v1beta1.Query? (use --verbose to list all 5 available services)`,
+		},
+		{
+			// Partial names match the services containing them, closest length first, at most three.
+			requested: "Query",
+			want:      `no service "Query" found; did you mean cosmos.auth.v1beta1.Query, cosmos.bank.v1beta1.Query, cosmos.staking.v1beta1.Query? (use --verbose to list all 5 available services)`,
+		},
+		{
+			requested: "bank",
+			want:      `no service "bank" found; did you mean cosmos.bank.v1beta1.Msg, cosmos.bank.v1beta1.Query? (use --verbose to list all 5 available services)`,
+		},
+		{
+			// Nothing close.
+			requested: "foo.Bar",
+			want:      `no service "foo.Bar" found (use --verbose to list all 5 available services)`,
+		},
+	} {
+		e := cmd.GRPCServiceNotFoundError{
+			Requested: tc.requested,
+			Available: available,
+		}
+		require.Equal(t, tc.want, e.Error(), tc.requested)
+	}
 }
 
 func TestGRPCMethodNotFoundError(t *testing.T) {
@@ -80,4 +201,144 @@ func TestGRPCMethodNotFoundError(t *testing.T) {
 		`service "farm" has no method with name "Ribbit" (available methods: Baa, Moo)`,
 		e.Error(),
 	)
+
+	e.Requested = "Mooo"
+	require.Equal(
+		t,
+		`service "farm" has no method with name "Mooo"; did you mean Moo? (available methods: Baa, Moo)`,
+		e.Error(),
+	)
+}
+
+func TestSurfaceDiffError(t *testing.T) {
+	e := cmd.SurfaceDiffError{Services: 3}
+
+	require.Equal(t, "3 services differ between the compared servers", e.Error())
+	require.Equal(t, 2, e.ExitCode())
+}
+
+func TestParamsDiffError(t *testing.T) {
+	e := cmd.ParamsDiffError{Params: 4}
+
+	require.Equal(t, "4 params differ between the compared chains", e.Error())
+	require.Equal(t, 2, e.ExitCode())
+}
+
+func TestReplayDiffError(t *testing.T) {
+	e := cmd.ReplayDiffError{Calls: 1, Replayed: 3}
+
+	require.Equal(t, "1 of 3 replayed calls differ from their recording", e.Error())
+	require.Equal(t, 2, e.ExitCode())
+}
+
+func TestGRPCCallError_ExitCode(t *testing.T) {
+	for c, want := range map[codes.Code]int{
+		codes.Unavailable:      cmd.ErrCodeConnection,
+		codes.DeadlineExceeded: cmd.ErrCodeConnection,
+		codes.Unimplemented:    cmd.ErrCodeServiceNotFound,
+		codes.InvalidArgument:  cmd.ErrCodeGeneric,
+	} {
+		e := cmd.GRPCCallError{Method: "foo.Bar.Baz", Code: c, Message: "oops"}
+		require.Equal(t, want, e.ExitCode(), c.String())
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	refused := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	sys := NewSystem(t)
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "chain not found", args: []string{"chains", "status", "nope"}, code: cmd.ErrCodeChainNotFound},
+		{name: "connection", args: []string{"dynamic", "list-services", refused}, code: cmd.ErrCodeConnection},
+		{name: "service not found", args: []string{"dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Nope"}, code: cmd.ErrCodeServiceNotFound},
+		{name: "generic", args: []string{"chains", "show"}, code: cmd.ErrCodeGeneric},
+	} {
+		res := sys.Run(zaptest.NewLogger(t), tc.args...)
+		require.Error(t, res.Err, tc.name)
+		require.Equal(t, tc.code, res.ExitCode, tc.name)
+		require.True(t, strings.HasPrefix(res.Stderr.String(), "Error: "), tc.name)
+	}
+}
+
+func TestErrorsJSON(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t)
+	sys := NewSystem(t)
+
+	type report struct {
+		Type    string                 `json:"type"`
+		Code    int                    `json:"code"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	}
+
+	res := sys.Run(zaptest.NewLogger(t), "--errors-json", "dynamic", "inspect", gRPCAddr, "grpc.channelz.v1.Nope")
+	require.Equal(t, cmd.ErrCodeServiceNotFound, res.ExitCode)
+	var r report
+	require.NoError(t, json.Unmarshal(res.Stderr.Bytes(), &r))
+	require.Equal(t, "GRPCServiceNotFoundError", r.Type)
+	require.Equal(t, cmd.ErrCodeServiceNotFound, r.Code)
+	require.Equal(t, res.Err.Error(), r.Message)
+	require.Equal(t, map[string]interface{}{
+		"requested": "grpc.channelz.v1.Nope",
+		"available": []interface{}{"grpc.channelz.v1.Channelz", "grpc.reflection.v1alpha.ServerReflection"},
+	}, r.Details)
+
+	// Errors without a type of their own are still reported.
+	res = sys.Run(zaptest.NewLogger(t), "--errors-json", "chains", "show")
+	require.Equal(t, cmd.ErrCodeGeneric, res.ExitCode)
+	r = report{}
+	require.NoError(t, json.Unmarshal(res.Stderr.Bytes(), &r))
+	require.Equal(t, "Error", r.Type)
+	require.Contains(t, r.Message, "available names are: cosmoshub, osmosis")
+	require.Nil(t, r.Details)
+}
+
+func TestPrunedHeightError(t *testing.T) {
+	t.Parallel()
+
+	gRPCAddr := runGRPCReflectionServer(t,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			switch md.Get("x-cosmos-block-height")[0] {
+			case "5":
+				// The state of the Cosmos SDK was pruned, and the node tells its earliest height in the details.
+				st, err := status.New(codes.InvalidArgument, "failed to load state at height 5; version does not exist (latest height: 16): invalid request").
+					WithDetails(&errdetails.ErrorInfo{Reason: "PRUNED", Metadata: map[string]string{"earliest_height": "10"}})
+				require.NoError(t, err)
+				return nil, st.Err()
+			default:
+				// Future heights do not exist either.
+				return nil, status.Error(codes.InvalidArgument, "failed to load state at height 20; version does not exist (latest height: 16): invalid request")
+			}
+		}),
+	)
+
+	sys := NewSystem(t)
+
+	res := sys.Run(zaptest.NewLogger(t), "--errors-json", "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--height", "5")
+	var pruned cmd.PrunedHeightError
+	require.ErrorAs(t, res.Err, &pruned)
+	require.Equal(t, int64(5), pruned.Height)
+	require.Equal(t, int64(10), pruned.EarliestHeight)
+	require.Contains(t, res.Err.Error(), "the node no longer has the state at height 5, which was pruned; its earliest available height is 10")
+	require.Contains(t, res.Stderr.String(), `"earliest_height":10`)
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "query", gRPCAddr, "grpc.channelz.v1.Channelz", "GetServers", "--height", "20")
+	require.False(t, errors.As(res.Err, &pruned))
+	var callErr cmd.GRPCCallError
+	require.ErrorAs(t, res.Err, &callErr)
 }
@@ -0,0 +1,537 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"go.uber.org/zap"
+)
+
+func exportCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the history of an account to a file",
+	}
+	cmd.AddCommand(exportTxsCmd(a))
+	return cmd
+}
+
+const (
+	exportFromHeightFlag = "from-height"
+	exportToHeightFlag   = "to-height"
+	exportFormatFlag     = "format"
+	exportOutFlag        = "out"
+	exportResumeFromFlag = "resume-from"
+	exportWindowFlag     = "window"
+	exportLimitFlag      = "limit"
+)
+
+// Formats of export txs.
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// exportTxsColumns are the columns of the rows written by export txs, in order.
+var exportTxsColumns = []string{"height", "timestamp", "hash", "code", "msg_index", "msg_type", "counterparty", "amount", "fee", "memo"}
+
+func exportTxsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "txs [chain-name] [key-or-address]",
+		Short: "export the transactions of an account as CSV or newline delimited JSON, one row per message",
+		Long: `Export the transactions sent or received by a key or address on the given chain, or on the default chain,
+between --from-height and --to-height (the latest block by default).
+
+` + chainAndAddressArgsHelp + `
+
+The transactions are searched by the message.sender and transfer.recipient events naming the address,
+--window blocks at a time, and each transaction is written once, in the order of the chain.
+Each message is written as a row with its height, block time, transaction hash and code, index and type,
+the counterparties of the transfers it made to or from the address, and the amount of those transfers,
+negative when sent, followed by its share of the fee, if the address paid it, and the memo.
+
+With --resume-from, the height reached is saved to the given cursor file after each window is written,
+and a later run with the same cursor file appends to --out from there, so that an export interrupted by
+the rate limits of a public node, or by Ctrl-C, is completed without duplicate rows.`,
+		Args: cobra.RangeArgs(0, 2),
+		Example: fmt.Sprintf(`$ %s export txs cosmoshub cosmos1... --from-height 15000000 --out txs.csv
+$ %s export txs osmosis mykey --format ndjson --out txs.ndjson --resume-from txs.cursor`,
+			appName, appName),
+		ValidArgsFunction: completeChainThenKey(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := txExportOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			cl, encodedAddr, err := chainClientAndAddress(a, args)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			cursor := txExportCursor{
+				ChainID:    cl.Config.ChainID,
+				Address:    encodedAddr,
+				Format:     opts.format,
+				NextHeight: opts.fromHeight,
+				ToHeight:   opts.toHeight,
+			}
+			resuming := false
+			if opts.cursorPath != "" {
+				saved, err := loadTxExportCursor(opts.cursorPath)
+				switch {
+				case err == nil:
+					if saved.ChainID != cursor.ChainID || saved.Address != cursor.Address || saved.Format != cursor.Format {
+						return fmt.Errorf("cursor file %s is for the %s export of %s on %s, not of %s on %s",
+							opts.cursorPath, saved.Format, saved.Address, saved.ChainID, cursor.Address, cursor.ChainID)
+					}
+					cursor, resuming = saved, true
+					a.Log.Info("Resuming export", zap.String("cursor", opts.cursorPath), zap.Int64("height", cursor.NextHeight), zap.Int64("to_height", cursor.ToHeight))
+				case errors.Is(err, os.ErrNotExist):
+				default:
+					return err
+				}
+			}
+			if cursor.ToHeight == 0 {
+				status, err := cl.RPCClient.Status(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to query the latest height: %w", err)
+				}
+				cursor.ToHeight = status.SyncInfo.LatestBlockHeight
+			}
+
+			w := cmd.OutOrStdout()
+			var f *os.File
+			if opts.outPath != "" {
+				flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+				if resuming {
+					flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+				}
+				if f, err = os.OpenFile(opts.outPath, flag, 0644); err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			rw, err := newTxExportWriter(w, opts.format, !resuming || fileIsEmpty(f))
+			if err != nil {
+				return err
+			}
+
+			e := txExporter{cl: cl, address: encodedAddr, limit: opts.limit}
+			for cursor.NextHeight <= cursor.ToHeight {
+				lo := cursor.NextHeight
+				hi := lo + opts.window - 1
+				if hi > cursor.ToHeight {
+					hi = cursor.ToHeight
+				}
+				rows, err := e.window(ctx, lo, hi)
+				if err != nil {
+					if ctx.Err() != nil {
+						err = errors.New("interrupted")
+					}
+					return txExportStopped(cursor, opts.cursorPath, err)
+				}
+				if err := rw.write(rows); err != nil {
+					return err
+				}
+				if f != nil {
+					if err := f.Sync(); err != nil {
+						return err
+					}
+				}
+
+				cursor.NextHeight = hi + 1
+				cursor.Rows += len(rows)
+				if opts.cursorPath != "" {
+					if err := saveTxExportCursor(opts.cursorPath, cursor); err != nil {
+						return fmt.Errorf("failed to save cursor file: %w", err)
+					}
+				}
+				a.Log.Debug("Exported blocks", zap.Int64("from_height", lo), zap.Int64("to_height", hi), zap.Int("rows", len(rows)))
+			}
+			a.Log.Info("Export complete", zap.Int64("to_height", cursor.ToHeight), zap.Int("rows", cursor.Rows))
+			return nil
+		},
+	}
+	cmd.Flags().Int64(exportFromHeightFlag, 1, "export the transactions from this height")
+	cmd.Flags().Int64(exportToHeightFlag, 0, "export the transactions up to this height (0 for the latest block)")
+	cmd.Flags().String(exportFormatFlag, exportFormatCSV, "format of the rows: csv or ndjson")
+	cmd.Flags().String(exportOutFlag, "", "write the rows to this file instead of stdout")
+	cmd.Flags().String(exportResumeFromFlag, "", "save the height reached to this cursor file, and resume from it if it exists (requires --out)")
+	cmd.Flags().Int64(exportWindowFlag, 10000, "search this many blocks at a time")
+	cmd.Flags().Int(exportLimitFlag, 100, "request this many transactions per page of search results")
+	return cmd
+}
+
+// txExportOptions are the flags of export txs.
+type txExportOptions struct {
+	fromHeight, toHeight int64
+	format               string
+	outPath, cursorPath  string
+	window               int64
+	limit                int
+}
+
+// txExportOptionsFromFlags reads and checks the flags of export txs.
+func txExportOptionsFromFlags(cmd *cobra.Command) (opts txExportOptions, err error) {
+	flags := cmd.Flags()
+	if opts.fromHeight, err = flags.GetInt64(exportFromHeightFlag); err != nil {
+		return opts, err
+	}
+	if opts.toHeight, err = flags.GetInt64(exportToHeightFlag); err != nil {
+		return opts, err
+	}
+	if opts.format, err = flags.GetString(exportFormatFlag); err != nil {
+		return opts, err
+	}
+	if opts.outPath, err = flags.GetString(exportOutFlag); err != nil {
+		return opts, err
+	}
+	if opts.cursorPath, err = flags.GetString(exportResumeFromFlag); err != nil {
+		return opts, err
+	}
+	if opts.window, err = flags.GetInt64(exportWindowFlag); err != nil {
+		return opts, err
+	}
+	if opts.limit, err = flags.GetInt(exportLimitFlag); err != nil {
+		return opts, err
+	}
+
+	switch {
+	case opts.format != exportFormatCSV && opts.format != exportFormatNDJSON:
+		return opts, fmt.Errorf("invalid --%s %q: must be %s or %s", exportFormatFlag, opts.format, exportFormatCSV, exportFormatNDJSON)
+	case opts.fromHeight < 1:
+		return opts, fmt.Errorf("--%s must be at least 1", exportFromHeightFlag)
+	case opts.toHeight != 0 && opts.toHeight < opts.fromHeight:
+		return opts, fmt.Errorf("--%s must not be below --%s", exportToHeightFlag, exportFromHeightFlag)
+	case opts.window < 1 || opts.limit < 1:
+		return opts, fmt.Errorf("--%s and --%s must be at least 1", exportWindowFlag, exportLimitFlag)
+	case opts.cursorPath != "" && opts.outPath == "":
+		return opts, fmt.Errorf("--%s requires --%s, to append to the rows already exported", exportResumeFromFlag, exportOutFlag)
+	}
+	return opts, nil
+}
+
+// txExportStopped returns err, which stopped the export at cursor, with how to resume the export.
+func txExportStopped(cursor txExportCursor, cursorPath string, err error) error {
+	if cursorPath == "" {
+		return fmt.Errorf("export stopped at height %d after %d rows: %w (export with --%s to resume it)", cursor.NextHeight, cursor.Rows, err, exportResumeFromFlag)
+	}
+	return fmt.Errorf("export stopped at height %d after %d rows: %w (run the same command again to resume it from %s)", cursor.NextHeight, cursor.Rows, err, cursorPath)
+}
+
+// fileIsEmpty reports whether f is nil or empty, so that the header of a CSV export is written to it.
+func fileIsEmpty(f *os.File) bool {
+	if f == nil {
+		return true
+	}
+	info, err := f.Stat()
+	return err == nil && info.Size() == 0
+}
+
+// txExportCursor is how far export txs went, saved to the --resume-from file.
+type txExportCursor struct {
+	ChainID    string `json:"chain_id"`
+	Address    string `json:"address"`
+	Format     string `json:"format"`
+	ToHeight   int64  `json:"to_height"`
+	NextHeight int64  `json:"next_height"`
+	Rows       int    `json:"rows"`
+}
+
+// loadTxExportCursor reads the cursor file at path.
+func loadTxExportCursor(path string) (txExportCursor, error) {
+	var cursor txExportCursor
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return cursor, fmt.Errorf("failed to decode cursor file %s: %w", path, err)
+	}
+	return cursor, nil
+}
+
+// saveTxExportCursor replaces the cursor file at path with cursor.
+func saveTxExportCursor(path string, cursor txExportCursor) error {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+// txExporter finds the transactions of an account and turns them into rows.
+type txExporter struct {
+	cl      *client.ChainClient
+	address string
+	limit   int
+}
+
+// window returns the rows of the transactions of the account from height lo to hi, in the order of the chain.
+func (e txExporter) window(ctx context.Context, lo, hi int64) ([]txExportRow, error) {
+	var txs []*coretypes.ResultTx
+	seen := make(map[string]bool)
+	feePayer := make(map[string]bool)
+	for _, key := range []string{"message.sender", "transfer.recipient"} {
+		query := fmt.Sprintf("%s='%s' AND tx.height>=%d AND tx.height<=%d", key, e.address, lo, hi)
+		found, err := e.search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range found {
+			hash := res.Hash.String()
+			if key == "message.sender" {
+				feePayer[hash] = true
+			}
+			if !seen[hash] {
+				seen[hash] = true
+				txs = append(txs, res)
+			}
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Height != txs[j].Height {
+			return txs[i].Height < txs[j].Height
+		}
+		return txs[i].Index < txs[j].Index
+	})
+
+	var rows []txExportRow
+	blockTimes := make(map[int64]time.Time)
+	for _, res := range txs {
+		blockTime, ok := blockTimes[res.Height]
+		if !ok {
+			height := res.Height
+			header, err := e.cl.RPCClient.Header(ctx, &height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query the time of block %d: %w", height, err)
+			}
+			blockTime = header.Header.Time.UTC()
+			blockTimes[height] = blockTime
+		}
+
+		tx, err := decodeTxResult(e.cl, res)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", res.Hash, err)
+		}
+		paid := feePayer[tx.Hash]
+		if payer, ok := txEventAttribute(res, "tx", "fee_payer"); ok {
+			paid = payer == e.address
+		}
+		rows = append(rows, e.rows(tx, blockTime, paid)...)
+	}
+	return rows, nil
+}
+
+// search returns every transaction matching query, requesting every page of the results in turn.
+func (e txExporter) search(ctx context.Context, query string) ([]*coretypes.ResultTx, error) {
+	var txs []*coretypes.ResultTx
+	for page := 1; ; page++ {
+		res, err := e.cl.SearchTxs(ctx, query, page, e.limit)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, res.Txs...)
+		if len(res.Txs) == 0 || len(txs) >= res.TotalCount {
+			return txs, nil
+		}
+	}
+}
+
+// rows returns a row for each message of tx, included at blockTime,
+// with a share of the fee if the account paid it.
+func (e txExporter) rows(tx decodedTx, blockTime time.Time, feePaid bool) []txExportRow {
+	rows := make([]txExportRow, len(tx.Messages))
+	for i, m := range tx.Messages {
+		row := txExportRow{
+			Height:    tx.Height,
+			Timestamp: blockTime,
+			Hash:      tx.Hash,
+			Code:      tx.Code,
+			MsgIndex:  m.Index,
+			MsgType:   m.Message.Type,
+			Memo:      tx.Memo,
+		}
+		row.Counterparty, row.Amount = e.transfers(m.Events)
+		if feePaid {
+			row.Fee = feeShare(tx.Fee, len(tx.Messages), i).String()
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// transfers returns the counterparties of the transfers to or from the account among events,
+// separated by semicolons, and their amounts, negative for the amounts sent.
+func (e txExporter) transfers(events sdk.StringEvents) (counterparty, amount string) {
+	var counterparties, amounts []string
+	addCounterparty := func(addr string) {
+		for _, c := range counterparties {
+			if c == addr {
+				return
+			}
+		}
+		counterparties = append(counterparties, addr)
+	}
+
+	for _, ev := range events {
+		if ev.Type != "transfer" {
+			continue
+		}
+		// The attributes of the transfers a message made are listed one transfer after another.
+		var recipient, sender string
+		for _, attr := range ev.Attributes {
+			switch attr.Key {
+			case "recipient":
+				recipient = attr.Value
+			case "sender":
+				sender = attr.Value
+			case "amount":
+				switch {
+				case sender == e.address && recipient == e.address:
+				case sender == e.address:
+					addCounterparty(recipient)
+					amounts = append(amounts, negateCoins(attr.Value))
+				case recipient == e.address:
+					addCounterparty(sender)
+					amounts = append(amounts, attr.Value)
+				}
+				recipient, sender = "", ""
+			}
+		}
+	}
+	return strings.Join(counterparties, ";"), strings.Join(amounts, ",")
+}
+
+// negateCoins prefixes each coin of the comma separated coins with a minus sign.
+func negateCoins(coins string) string {
+	parts := strings.Split(coins, ",")
+	for i, p := range parts {
+		parts[i] = "-" + p
+	}
+	return strings.Join(parts, ",")
+}
+
+// feeShare returns the share of fee of message i of a transaction of n messages:
+// the fee is divided evenly, and the first message bears the remainder.
+func feeShare(fee sdk.Coins, n, i int) sdk.Coins {
+	if n <= 1 {
+		return fee
+	}
+	var share sdk.Coins
+	for _, c := range fee {
+		amount := c.Amount.QuoRaw(int64(n))
+		if i == 0 {
+			amount = amount.Add(c.Amount.ModRaw(int64(n)))
+		}
+		share = share.Add(sdk.NewCoin(c.Denom, amount))
+	}
+	return share
+}
+
+// txEventAttribute returns the value of the first attribute key of an event of type eventType emitted by res.
+func txEventAttribute(res *coretypes.ResultTx, eventType, key string) (string, bool) {
+	for _, ev := range res.TxResult.Events {
+		if ev.Type != eventType {
+			continue
+		}
+		for _, attr := range ev.Attributes {
+			if attr.Key == key {
+				return attr.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// txExportRow is a message of a transaction exported by export txs.
+type txExportRow struct {
+	Height       int64     `json:"height"`
+	Timestamp    time.Time `json:"timestamp"`
+	Hash         string    `json:"hash"`
+	Code         uint32    `json:"code"`
+	MsgIndex     int       `json:"msg_index"`
+	MsgType      string    `json:"msg_type"`
+	Counterparty string    `json:"counterparty"`
+	Amount       string    `json:"amount"`
+	Fee          string    `json:"fee"`
+	Memo         string    `json:"memo"`
+}
+
+// record returns the row as CSV fields, in the order of exportTxsColumns.
+func (r txExportRow) record() []string {
+	return []string{
+		strconv.FormatInt(r.Height, 10),
+		r.Timestamp.Format(time.RFC3339),
+		r.Hash,
+		strconv.FormatUint(uint64(r.Code), 10),
+		strconv.Itoa(r.MsgIndex),
+		r.MsgType,
+		r.Counterparty,
+		r.Amount,
+		r.Fee,
+		r.Memo,
+	}
+}
+
+// txExportWriter writes rows in the format of export txs.
+type txExportWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+// newTxExportWriter returns a writer of rows to w in format,
+// writing the CSV header first if header is set.
+func newTxExportWriter(w io.Writer, format string, header bool) (*txExportWriter, error) {
+	if format == exportFormatNDJSON {
+		return &txExportWriter{json: json.NewEncoder(w)}, nil
+	}
+	rw := &txExportWriter{csv: csv.NewWriter(w)}
+	if header {
+		if err := rw.csv.Write(exportTxsColumns); err != nil {
+			return nil, err
+		}
+		rw.csv.Flush()
+		return rw, rw.csv.Error()
+	}
+	return rw, nil
+}
+
+// write writes rows, flushing them to the underlying writer.
+func (rw *txExportWriter) write(rows []txExportRow) error {
+	for _, r := range rows {
+		if rw.json != nil {
+			if err := rw.json.Encode(r); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := rw.csv.Write(r.record()); err != nil {
+			return err
+		}
+	}
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package cmd_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// exportTestTx returns the result of a tx of a MsgSend per transfer, each emitting its transfer event,
+// paid by feePayer.
+func exportTestTx(t *testing.T, height int64, feePayer string, fee int64, memo string, transfers ...banktypes.MsgSend) *coretypes.ResultTx {
+	t.Helper()
+
+	body := txtypes.TxBody{Memo: memo}
+	var logs sdk.ABCIMessageLogs
+	for i := range transfers {
+		msg, err := codectypes.NewAnyWithValue(&transfers[i])
+		require.NoError(t, err)
+		body.Messages = append(body.Messages, msg)
+		logs = append(logs, sdk.ABCIMessageLog{MsgIndex: uint32(i), Events: sdk.StringEvents{{
+			Type: "transfer",
+			Attributes: []sdk.Attribute{
+				{Key: "recipient", Value: transfers[i].ToAddress},
+				{Key: "sender", Value: transfers[i].FromAddress},
+				{Key: "amount", Value: transfers[i].Amount.String()},
+			},
+		}}})
+	}
+	bodyBytes, err := body.Marshal()
+	require.NoError(t, err)
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", fee))}}).Marshal()
+	require.NoError(t, err)
+	txBytes, err := (&txtypes.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfo}).Marshal()
+	require.NoError(t, err)
+
+	tx := tmtypes.Tx(txBytes)
+	return &coretypes.ResultTx{
+		Hash:   tx.Hash(),
+		Height: height,
+		Tx:     tx,
+		TxResult: abci.ResponseDeliverTx{
+			Log:    logs.String(),
+			Events: []abci.Event{{Type: "tx", Attributes: []abci.EventAttribute{{Key: "fee_payer", Value: feePayer}}}},
+		},
+	}
+}
+
+func TestExportTxs(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	const other = "cosmos1r5v5srda7xfth3hn2s26txvrcrntldjujh4x4m"
+	sent := exportTestTx(t, 10, ZeroCosmosAddr, 11, "rent, march",
+		banktypes.MsgSend{FromAddress: ZeroCosmosAddr, ToAddress: other, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 5))},
+		banktypes.MsgSend{FromAddress: ZeroCosmosAddr, ToAddress: other, Amount: sdk.NewCoins(sdk.NewInt64Coin("uosmo", 3))},
+	)
+	received := exportTestTx(t, 60, other, 7, "",
+		banktypes.MsgSend{FromAddress: other, ToAddress: ZeroCosmosAddr, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 9))},
+	)
+
+	mc := new(mocks.Client)
+	mockBlockStatus(mc)
+	search := func(query string) *mock.Call {
+		return mc.On("TxSearch", mock.Anything, query, false, mock.Anything, mock.Anything, "")
+	}
+	// The sent tx is found by both searches, and written once.
+	search("message.sender='"+ZeroCosmosAddr+"' AND tx.height>=1 AND tx.height<=50").
+		Return(&coretypes.ResultTxSearch{Txs: []*coretypes.ResultTx{sent}, TotalCount: 1}, nil)
+	search("transfer.recipient='"+ZeroCosmosAddr+"' AND tx.height>=1 AND tx.height<=50").
+		Return(&coretypes.ResultTxSearch{Txs: []*coretypes.ResultTx{sent}, TotalCount: 1}, nil)
+	// The node rate-limits the first search of the second window once.
+	search("message.sender='"+ZeroCosmosAddr+"' AND tx.height>=51 AND tx.height<=100").
+		Return(nil, errors.New("429 Too Many Requests")).Once()
+	search("message.sender='"+ZeroCosmosAddr+"' AND tx.height>=51 AND tx.height<=100").
+		Return(&coretypes.ResultTxSearch{TotalCount: 0}, nil)
+	search("transfer.recipient='"+ZeroCosmosAddr+"' AND tx.height>=51 AND tx.height<=100").
+		Return(&coretypes.ResultTxSearch{Txs: []*coretypes.ResultTx{received}, TotalCount: 1}, nil)
+	blockTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, height := range []int64{10, 60} {
+		mc.On("Header", mock.Anything, matchHeight(height)).Return(&coretypes.ResultHeader{
+			Header: &tmtypes.Header{Height: height, Time: blockTime.Add(time.Duration(height) * time.Minute)},
+		}, nil)
+	}
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	out := filepath.Join(sys.HomeDir, "txs.csv")
+	cursor := filepath.Join(sys.HomeDir, "txs.cursor")
+	args := []string{"export", "txs", "cosmoshub", ZeroCosmosAddr, "--window", "50", "--out", out, "--resume-from", cursor}
+
+	// The first window is written before the export stops, and the second run appends the rest.
+	res := sys.Run(zaptest.NewLogger(t), args...)
+	require.ErrorContains(t, res.Err, "export stopped at height 51 after 2 rows: 429 Too Many Requests")
+	b, err := os.ReadFile(cursor)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"next_height":51`)
+	sys.MustRun(t, args...)
+
+	f, err := os.Open(out)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"height", "timestamp", "hash", "code", "msg_index", "msg_type", "counterparty", "amount", "fee", "memo"},
+		{"10", "2026-03-01T12:10:00Z", sent.Hash.String(), "0", "0", "/cosmos.bank.v1beta1.MsgSend", other, "-5uatom", "6uatom", "rent, march"},
+		{"10", "2026-03-01T12:10:00Z", sent.Hash.String(), "0", "1", "/cosmos.bank.v1beta1.MsgSend", other, "-3uosmo", "5uatom", "rent, march"},
+		{"60", "2026-03-01T13:00:00Z", received.Hash.String(), "0", "0", "/cosmos.bank.v1beta1.MsgSend", other, "9uatom", "", ""},
+	}, records)
+
+	// A completed export is not written again.
+	sys.MustRun(t, args...)
+	b, err = os.ReadFile(out)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(b)), "\n"), 4)
+
+	res = sys.MustRun(t, "export", "txs", ZeroCosmosAddr, "--to-height", "50", "--format", "ndjson")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	var row struct {
+		Height int64
+		Amount string
+		Fee    string
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	require.Equal(t, int64(10), row.Height)
+	require.Equal(t, "-3uosmo", row.Amount)
+	require.Equal(t, "5uatom", row.Fee)
+
+	res = sys.Run(zaptest.NewLogger(t), "export", "txs", "--resume-from", cursor)
+	require.ErrorContains(t, res.Err, "--resume-from requires --out")
+	res = sys.Run(zaptest.NewLogger(t), "export", "txs", "--format", "xlsx")
+	require.ErrorContains(t, res.Err, `invalid --format "xlsx": must be csv or ndjson`)
+}
@@ -0,0 +1,681 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const (
+	faucetKeyFlag           = "key"
+	faucetAmountFlag        = "amount"
+	faucetListenFlag        = "listen"
+	faucetBatchIntervalFlag = "batch-interval"
+	faucetMaxBatchFlag      = "max-batch"
+	faucetAddressLimitFlag  = "address-limit"
+	faucetIPLimitFlag       = "ip-limit"
+	faucetStateFileFlag     = "state-file"
+)
+
+// faucetRecentSends is how many of the latest batches the faucet reports on /status.
+const faucetRecentSends = 20
+
+// faucetShutdownTimeout is how long in-flight requests have to complete when the faucet stops.
+const faucetShutdownTimeout = 5 * time.Second
+
+// faucetMaxRequestBytes bounds the size of the body of a credit request.
+const faucetMaxRequestBytes = 4096
+
+func faucetCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "faucet",
+		Short: "run a token faucet backed by a key",
+	}
+	cmd.AddCommand(faucetStartCmd(a))
+	return cmd
+}
+
+func faucetStartCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start [chain-name]",
+		Short: "serve an HTTP faucet sending tokens from a key of the given chain, or of the default chain",
+		Long: `Serve an HTTP faucet sending --amount from a key in the keyring of the given chain, or of the default chain,
+to the addresses that ask for it. The faucet runs until interrupted, or until --timeout has passed.
+
+  POST /credit {"address": "<address>"}  sends --amount to the address, which must have the chain's account prefix,
+                                         and responds with the hash of the transaction that sent it
+  GET /status                            responds with the address and balance of the faucet, and its latest sends
+
+The credits asked for within --batch-interval are sent together, as the outputs of a multi-send transaction
+of at most --max-batch outputs, so that the faucet signs one transaction per interval rather than one per credit.
+The transactions are given consecutive sequences, as with tx batch.
+
+An address may be credited at most --address-limit times, and the addresses asked for from an IP address
+at most --ip-limit times, each given as COUNT/WINDOW (e.g. 1/24h), or 0 for no limit. A credit whose
+transaction fails does not count, but one whose transaction was not confirmed to be included in time does,
+since it may still be included: it is answered with 202 Accepted and the status pending. The credits are counted in memory, and with --state-file they are also
+saved to the file after each transaction, and read from it on start, so that the limits outlast a restart.
+
+With --dry-run, the messages of each batch are written instead of being sent.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s faucet start cosmoshub --key faucet --amount 10uatom --listen :8080
+$ %s faucet start --amount 1000000uosmo --address-limit 1/24h --ip-limit 5/24h --state-file faucet.json
+$ curl -X POST localhost:8080/credit -d '{"address": "cosmos1..."}'`,
+			appName, appName),
+		Args:              withUsage(cobra.RangeArgs(0, 1)),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			amountStr, err := f.GetString(faucetAmountFlag)
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoinsNormalized(amountStr)
+			if err != nil || amount.Empty() {
+				return fmt.Errorf("invalid --%s %q: must be a positive amount of coins (e.g. 10uatom)", faucetAmountFlag, amountStr)
+			}
+			listen, err := f.GetString(faucetListenFlag)
+			if err != nil {
+				return err
+			}
+			interval, err := f.GetDuration(faucetBatchIntervalFlag)
+			if err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("invalid --%s %s: must be positive", faucetBatchIntervalFlag, interval)
+			}
+			maxBatch, err := f.GetInt(faucetMaxBatchFlag)
+			if err != nil {
+				return err
+			}
+			if maxBatch < 1 {
+				return fmt.Errorf("invalid --%s %d: must be positive", faucetMaxBatchFlag, maxBatch)
+			}
+			limits := make(map[string]faucetLimit, 2)
+			for _, flag := range []string{faucetAddressLimitFlag, faucetIPLimitFlag} {
+				s, err := f.GetString(flag)
+				if err != nil {
+					return err
+				}
+				if limits[flag], err = parseFaucetLimit(s); err != nil {
+					return fmt.Errorf("invalid --%s %q: %w", flag, s, err)
+				}
+			}
+			statePath, err := f.GetString(faucetStateFileFlag)
+			if err != nil {
+				return err
+			}
+			key, err := f.GetString(faucetKeyFlag)
+			if err != nil {
+				return err
+			}
+			dryRun, err := f.GetBool(dryRunFlag)
+			if err != nil {
+				return err
+			}
+			generateOnly, err := f.GetBool(txGenerateOnlyFlag)
+			if err != nil {
+				return err
+			}
+			if generateOnly {
+				return fmt.Errorf("--%s cannot be used by the faucet, which signs and broadcasts its transactions", txGenerateOnlyFlag)
+			}
+
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			cl, from, err := txChainClient(cmd, a, chainName, key)
+			if err != nil {
+				return err
+			}
+			opts, err := txOptionsFromFlags(cmd, cl)
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := checkFeeOptions(cmd, a, cl, opts, false); err != nil {
+					return err
+				}
+			}
+
+			limiter := &faucetLimiter{
+				addressLimit: limits[faucetAddressLimitFlag],
+				ipLimit:      limits[faucetIPLimitFlag],
+				path:         statePath,
+			}
+			if err := limiter.load(); err != nil {
+				return err
+			}
+			fc := &faucet{
+				log:      a.Log,
+				cl:       cl,
+				from:     from,
+				amount:   amount,
+				opts:     opts,
+				maxBatch: maxBatch,
+				limiter:  limiter,
+			}
+			if dryRun {
+				fc.dryRun = func(msgs []sdk.Msg) error { return writeMsgs(cmd, a, cl, msgs) }
+			}
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen for --%s: %w", faucetListenFlag, err)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			srv := &http.Server{Handler: fc.handler(), ReadHeaderTimeout: 10 * time.Second}
+			serveErr := make(chan error, 1)
+			go func() {
+				// A server failing to serve stops the faucet too.
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					serveErr <- err
+					cancel()
+				}
+			}()
+			a.Log.Info("Serving faucet",
+				zap.String("addr", "http://"+ln.Addr().String()),
+				zap.String("chain_id", cl.Config.ChainID),
+				zap.String("address", cl.MustEncodeAccAddr(from)),
+				zap.String("amount", amount.String()),
+			)
+
+			fc.run(ctx, interval)
+
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), faucetShutdownTimeout)
+			defer cancelShutdown()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				a.Log.Warn("Failed to stop the faucet server", zap.Error(err))
+			}
+			select {
+			case err := <-serveErr:
+				return fmt.Errorf("faucet server stopped: %w", err)
+			default:
+			}
+			a.Log.Info("Stopped faucet")
+			return nil
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(faucetKeyFlag, "", "the key sending the tokens (default: the chain's key)")
+	cmd.Flags().String(faucetAmountFlag, "", "the coins sent to each address asking for a credit (e.g. 10uatom)")
+	cmd.Flags().String(faucetListenFlag, ":8080", "the address to serve the faucet at")
+	cmd.Flags().Duration(faucetBatchIntervalFlag, 5*time.Second, "how often to send the credits asked for, together in a transaction")
+	cmd.Flags().Int(faucetMaxBatchFlag, 100, "the most credits sent in one transaction")
+	cmd.Flags().String(faucetAddressLimitFlag, "1/24h", "how many times an address may be credited, as COUNT/WINDOW, or 0 for no limit")
+	cmd.Flags().String(faucetIPLimitFlag, "10/24h", "how many credits may be asked for from an IP address, as COUNT/WINDOW, or 0 for no limit")
+	cmd.Flags().String(faucetStateFileFlag, "", "the file to save the credits counted by the limits to, and to read them from on start")
+	if err := cmd.MarkFlagRequired(faucetAmountFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// faucet sends the credits asked for over HTTP, in batches.
+type faucet struct {
+	log      *zap.Logger
+	cl       *client.ChainClient
+	from     sdk.AccAddress
+	amount   sdk.Coins
+	opts     client.TxOptions
+	maxBatch int
+	limiter  *faucetLimiter
+
+	// dryRun, if set, is given the messages of each batch instead of them being sent.
+	dryRun func(msgs []sdk.Msg) error
+
+	mu      sync.Mutex
+	stopped bool
+	pending []*faucetCredit
+	recent  []faucetSend
+}
+
+// faucetCredit is a credit waiting to be sent.
+type faucetCredit struct {
+	address string
+	// release undoes the counting of the credit by the limits, if it is not sent.
+	release func()
+	done    chan faucetCreditResult
+}
+
+// faucetCreditResult is the outcome of a credit, sent or not.
+// A pending credit was sent in a transaction whose inclusion was not confirmed.
+type faucetCreditResult struct {
+	txHash  string
+	pending bool
+	status  int
+	err     error
+}
+
+// faucetSend is a batch of credits sent by the faucet.
+type faucetSend struct {
+	Time       time.Time `json:"time"`
+	TxHash     string    `json:"tx_hash,omitempty"`
+	Code       uint32    `json:"code"`
+	Pending    bool      `json:"pending,omitempty"`
+	Recipients []string  `json:"recipients"`
+}
+
+// errFaucetStopped is the error of the credits that were not sent before the faucet stopped.
+var errFaucetStopped = errors.New("the faucet is stopping")
+
+// run sends the pending credits every interval, until ctx is done,
+// when the credits still pending fail with errFaucetStopped.
+func (f *faucet) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			f.mu.Lock()
+			f.stopped = true
+			pending := f.pending
+			f.pending = nil
+			f.mu.Unlock()
+			for _, c := range pending {
+				c.release()
+				c.done <- faucetCreditResult{status: http.StatusServiceUnavailable, err: errFaucetStopped}
+			}
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			pending := f.pending
+			f.pending = nil
+			f.mu.Unlock()
+			for len(pending) > 0 {
+				n := f.maxBatch
+				if n > len(pending) {
+					n = len(pending)
+				}
+				f.send(ctx, pending[:n])
+				pending = pending[n:]
+			}
+		}
+	}
+}
+
+// enqueue adds c to the credits sent at the next interval.
+func (f *faucet) enqueue(c *faucetCredit) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopped {
+		return false
+	}
+	f.pending = append(f.pending, c)
+	return true
+}
+
+// send sends the credits of batch in a single multi-send transaction, and reports the outcome to each of them.
+func (f *faucet) send(ctx context.Context, batch []*faucetCredit) {
+	total := sdk.NewCoins()
+	outputs := make([]banktypes.Output, len(batch))
+	recipients := make([]string, len(batch))
+	for i, c := range batch {
+		total = total.Add(f.amount...)
+		outputs[i] = banktypes.Output{Address: c.address, Coins: f.amount}
+		recipients[i] = c.address
+	}
+	msgs := []sdk.Msg{&banktypes.MsgMultiSend{
+		Inputs:  []banktypes.Input{{Address: f.cl.MustEncodeAccAddr(f.from), Coins: total}},
+		Outputs: outputs,
+	}}
+
+	fail := func(status int, err error) {
+		f.log.Warn("Failed to send faucet credits", zap.Strings("recipients", recipients), zap.Error(err))
+		for _, c := range batch {
+			c.release()
+			c.done <- faucetCreditResult{status: status, err: err}
+		}
+	}
+
+	if f.dryRun != nil {
+		if err := f.dryRun(msgs); err != nil {
+			fail(http.StatusInternalServerError, err)
+			return
+		}
+		f.record(faucetSend{Time: time.Now().UTC(), Recipients: recipients})
+		for _, c := range batch {
+			c.done <- faucetCreditResult{status: http.StatusOK}
+		}
+		return
+	}
+
+	res, err := f.cl.SendMsgsWithOptions(ctx, msgs, f.opts)
+	var unconfirmed client.UnconfirmedTxError
+	if errors.As(err, &unconfirmed) {
+		// The transaction may still be included, so its credits stay counted by the limits.
+		f.record(faucetSend{Time: time.Now().UTC(), TxHash: unconfirmed.TxHash, Pending: true, Recipients: recipients})
+		f.log.Warn("Sent faucet credits, but their inclusion was not confirmed",
+			zap.String("tx_hash", unconfirmed.TxHash), zap.Strings("recipients", recipients), zap.Error(err))
+		if err := f.limiter.save(); err != nil {
+			f.log.Warn("Failed to save the faucet state", zap.String("file", f.limiter.path), zap.Error(err))
+		}
+		for _, c := range batch {
+			c.done <- faucetCreditResult{txHash: unconfirmed.TxHash, pending: true, status: http.StatusAccepted}
+		}
+		return
+	}
+	if res == nil {
+		fail(http.StatusBadGateway, fmt.Errorf("failed to send transaction: %w", err))
+		return
+	}
+	f.record(faucetSend{Time: time.Now().UTC(), TxHash: res.TxHash, Code: res.Code, Recipients: recipients})
+	if err != nil {
+		fail(http.StatusBadGateway, fmt.Errorf("transaction %s failed: %w", res.TxHash, err))
+		return
+	}
+	f.log.Info("Sent faucet credits", zap.String("tx_hash", res.TxHash), zap.Strings("recipients", recipients))
+	if err := f.limiter.save(); err != nil {
+		f.log.Warn("Failed to save the faucet state", zap.String("file", f.limiter.path), zap.Error(err))
+	}
+	for _, c := range batch {
+		c.done <- faucetCreditResult{txHash: res.TxHash, status: http.StatusOK}
+	}
+}
+
+// record adds s to the latest sends reported on /status.
+func (f *faucet) record(s faucetSend) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recent = append(f.recent, s)
+	if len(f.recent) > faucetRecentSends {
+		f.recent = f.recent[len(f.recent)-faucetRecentSends:]
+	}
+}
+
+// handler returns the HTTP handler of the faucet's endpoints.
+func (f *faucet) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/credit", f.handleCredit)
+	mux.HandleFunc("/status", f.handleStatus)
+	return mux
+}
+
+func (f *faucet) handleCredit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeFaucetError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed: use POST", r.Method))
+		return
+	}
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, faucetMaxRequestBytes)).Decode(&req); err != nil {
+		writeFaucetError(w, http.StatusBadRequest, fmt.Errorf(`expected a JSON object such as {"address": "<address>"}: %w`, err))
+		return
+	}
+	addr, err := f.cl.DecodeBech32AccAddr(req.Address)
+	if err != nil {
+		writeFaucetError(w, http.StatusBadRequest, fmt.Errorf("invalid address %q for account prefix %q: %w", req.Address, f.cl.Config.AccountPrefix, err))
+		return
+	}
+	address := f.cl.MustEncodeAccAddr(addr)
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	release, err := f.limiter.reserve(address, ip, time.Now())
+	if err != nil {
+		var limited faucetLimitError
+		if errors.As(err, &limited) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(limited.RetryAfter.Round(time.Second)/time.Second)))
+		}
+		writeFaucetError(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	c := &faucetCredit{address: address, release: release, done: make(chan faucetCreditResult, 1)}
+	if !f.enqueue(c) {
+		release()
+		writeFaucetError(w, http.StatusServiceUnavailable, errFaucetStopped)
+		return
+	}
+	// The credit is sent even if the client stops waiting for it.
+	res := <-c.done
+	if res.err != nil {
+		writeFaucetError(w, res.status, res.err)
+		return
+	}
+	var status string
+	if res.pending {
+		status = "pending"
+	}
+	writeFaucetJSON(w, res.status, struct {
+		Address string `json:"address"`
+		Amount  string `json:"amount"`
+		TxHash  string `json:"tx_hash,omitempty"`
+		Status  string `json:"status,omitempty"`
+	}{address, f.amount.String(), res.txHash, status})
+}
+
+func (f *faucet) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeFaucetError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed: use GET", r.Method))
+		return
+	}
+	address := f.cl.MustEncodeAccAddr(f.from)
+	q := query.Query{Ctx: r.Context(), Client: f.cl, Options: query.DefaultOptions()}
+	balance, err := q.Bank_AllBalances(address)
+	if err != nil {
+		writeFaucetError(w, http.StatusBadGateway, fmt.Errorf("failed to query the balance of the faucet: %w", err))
+		return
+	}
+
+	f.mu.Lock()
+	pending := len(f.pending)
+	recent := make([]faucetSend, len(f.recent))
+	// Latest first.
+	for i, s := range f.recent {
+		recent[len(recent)-1-i] = s
+	}
+	f.mu.Unlock()
+
+	writeFaucetJSON(w, http.StatusOK, struct {
+		ChainID string       `json:"chain_id"`
+		Address string       `json:"address"`
+		Amount  string       `json:"amount"`
+		Balance sdk.Coins    `json:"balance"`
+		Pending int          `json:"pending"`
+		Recent  []faucetSend `json:"recent"`
+	}{f.cl.Config.ChainID, address, f.amount.String(), balance, pending, recent})
+}
+
+func writeFaucetJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFaucetError(w http.ResponseWriter, status int, err error) {
+	writeFaucetJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+// faucetLimit is how many credits may be counted within a sliding window.
+// A zero Count is no limit.
+type faucetLimit struct {
+	Count  int
+	Window time.Duration
+}
+
+// parseFaucetLimit parses a limit given as COUNT/WINDOW, such as 1/24h, or 0 for no limit.
+func parseFaucetLimit(s string) (faucetLimit, error) {
+	if s == "0" || s == "" {
+		return faucetLimit{}, nil
+	}
+	countStr, windowStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return faucetLimit{}, errors.New("must be COUNT/WINDOW, such as 1/24h, or 0 for no limit")
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 1 {
+		return faucetLimit{}, errors.New("count must be a positive number of credits")
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return faucetLimit{}, errors.New("window must be a positive duration, such as 24h")
+	}
+	return faucetLimit{Count: count, Window: window}, nil
+}
+
+// faucetLimitError is the error of a credit exceeding a limit of the faucet.
+type faucetLimitError struct {
+	// What exceeded the limit: "address" or "IP address".
+	What, Key string
+	Limit     faucetLimit
+	// RetryAfter is how long until a credit is no longer counted by the limit.
+	RetryAfter time.Duration
+}
+
+func (e faucetLimitError) Error() string {
+	return fmt.Sprintf("%s %s has reached the limit of %d credits per %s: try again in %s",
+		e.What, e.Key, e.Limit.Count, e.Limit.Window, e.RetryAfter.Round(time.Second))
+}
+
+// faucetLimiter counts the credits of each address and IP address against the limits of the faucet.
+type faucetLimiter struct {
+	addressLimit, ipLimit faucetLimit
+	// path is the file the counted credits are saved to, if any.
+	path string
+
+	mu    sync.Mutex
+	state faucetState
+}
+
+// faucetState is the credits counted by a faucetLimiter, as saved to its file.
+type faucetState struct {
+	Addresses map[string][]time.Time `json:"addresses"`
+	IPs       map[string][]time.Time `json:"ips"`
+}
+
+// load reads the counted credits from the file of l, if it has one and it exists.
+func (l *faucetLimiter) load() error {
+	l.state = faucetState{Addresses: map[string][]time.Time{}, IPs: map[string][]time.Time{}}
+	if l.path == "" {
+		return nil
+	}
+	bz, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bz, &l.state); err != nil {
+		return fmt.Errorf("invalid faucet state file %s: %w", l.path, err)
+	}
+	if l.state.Addresses == nil {
+		l.state.Addresses = map[string][]time.Time{}
+	}
+	if l.state.IPs == nil {
+		l.state.IPs = map[string][]time.Time{}
+	}
+	return nil
+}
+
+// save writes the counted credits to the file of l, if it has one, dropping those past their windows.
+func (l *faucetLimiter) save() error {
+	if l.path == "" {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	for key := range l.state.Addresses {
+		pruneFaucetCredits(l.state.Addresses, key, l.addressLimit, now)
+	}
+	for key := range l.state.IPs {
+		pruneFaucetCredits(l.state.IPs, key, l.ipLimit, now)
+	}
+	bz, err := json.MarshalIndent(l.state, "", "  ")
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(l.path, bz)
+}
+
+// reserve counts a credit of address asked for from ip at now, unless either already reached its limit,
+// and returns a function undoing it.
+func (l *faucetLimiter) reserve(address, ip string, now time.Time) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range []struct {
+		what, key string
+		limit     faucetLimit
+		times     map[string][]time.Time
+	}{
+		{"address", address, l.addressLimit, l.state.Addresses},
+		{"IP address", ip, l.ipLimit, l.state.IPs},
+	} {
+		times := pruneFaucetCredits(c.times, c.key, c.limit, now)
+		if c.limit.Count > 0 && len(times) >= c.limit.Count {
+			return nil, faucetLimitError{What: c.what, Key: c.key, Limit: c.limit, RetryAfter: times[0].Add(c.limit.Window).Sub(now)}
+		}
+	}
+	l.state.Addresses[address] = append(l.state.Addresses[address], now)
+	l.state.IPs[ip] = append(l.state.IPs[ip], now)
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		unreserveFaucetCredit(l.state.Addresses, address, now)
+		unreserveFaucetCredit(l.state.IPs, ip, now)
+	}, nil
+}
+
+// pruneFaucetCredits drops the credits of key in times that are past the window of limit, and returns the remaining ones, oldest first.
+func pruneFaucetCredits(times map[string][]time.Time, key string, limit faucetLimit, now time.Time) []time.Time {
+	kept := times[key][:0]
+	for _, t := range times[key] {
+		if limit.Count > 0 && now.Sub(t) < limit.Window {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(times, key)
+		return nil
+	}
+	times[key] = kept
+	return kept
+}
+
+// unreserveFaucetCredit drops the credit of key counted at t.
+func unreserveFaucetCredit(times map[string][]time.Time, key string, t time.Time) {
+	for i, u := range times[key] {
+		if u.Equal(t) {
+			times[key] = append(times[key][:i], times[key][i+1:]...)
+			break
+		}
+	}
+	if len(times[key]) == 0 {
+		delete(times, key)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// startFaucet runs faucet start with args in the background, and returns the URL it serves at,
+// and a channel receiving the result of the command once it stops.
+func startFaucet(t *testing.T, sys *System, args ...string) (string, <-chan RunResult) {
+	t.Helper()
+
+	core, logs := observer.New(zap.InfoLevel)
+	done := make(chan RunResult, 1)
+	go func() {
+		done <- sys.Run(zap.New(core), append([]string{"faucet", "start", "--listen", "127.0.0.1:0"}, args...)...)
+	}()
+	require.Eventually(t, func() bool { return logs.FilterMessage("Serving faucet").Len() == 1 }, 10*time.Second, 10*time.Millisecond)
+	return logs.FilterMessage("Serving faucet").All()[0].ContextMap()["addr"].(string), done
+}
+
+// postCredit asks the faucet at url for a credit of address, and returns the status and body of the response.
+func postCredit(t *testing.T, url, address string) (int, map[string]string) {
+	t.Helper()
+
+	res, err := http.Post(url+"/credit", "application/json", strings.NewReader(`{"address": "`+address+`"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	return res.StatusCode, body
+}
+
+func TestFaucet(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "faucet")
+
+	txConfig := client.MakeCodec(client.ModuleBasics, nil).TxConfig
+	var mu sync.Mutex
+	var sent []sdk.Tx
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/AllBalances", func(bytes.HexBytes) bool { return true },
+		&banktypes.QueryAllBalancesResponse{Balances: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000)), Pagination: &query.PageResponse{}})
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx, err := txConfig.TxDecoder()(args.Get(1).(tmtypes.Tx))
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, tx)
+	}).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	statePath := filepath.Join(t.TempDir(), "faucet.json")
+	url, done := startFaucet(t, sys, "cosmoshub", "--key", "faucet", "--amount", "10uatom", "--batch-interval", "500ms",
+		"--ip-limit", "2/24h", "--state-file", statePath, "--broadcast-mode", "sync", "--timeout", "3s")
+
+	// The credits asked for within an interval are sent in the same transaction.
+	var wg sync.WaitGroup
+	for _, addr := range []string{ZeroCosmosAddr, testGroupMemberAddr} {
+		addr := addr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, body := postCredit(t, url, addr)
+			require.Equal(t, http.StatusOK, status, body)
+			require.Equal(t, map[string]string{"address": addr, "amount": "10uatom", "tx_hash": "AB"}, body)
+		}()
+	}
+	wg.Wait()
+
+	status, body := postCredit(t, url, ZeroCosmosAddr)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Contains(t, body["error"], "address "+ZeroCosmosAddr+" has reached the limit of 1 credits per 24h0m0s: try again in 2")
+	status, body = postCredit(t, url, testContractAddr)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Contains(t, body["error"], "IP address 127.0.0.1 has reached the limit of 2 credits per 24h0m0s")
+	status, body = postCredit(t, url, "osmo1r5v5srda7xfth3hn2s26txvrcrntldjumt8mhl")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body["error"], `invalid address "osmo1r5v5srda7xfth3hn2s26txvrcrntldjumt8mhl" for account prefix "cosmos"`)
+
+	res, err := http.Get(url + "/status")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var faucetStatus struct {
+		Address string
+		Balance sdk.Coins
+		Pending int
+		Recent  []struct {
+			TxHash     string `json:"tx_hash"`
+			Recipients []string
+		}
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&faucetStatus))
+	require.Equal(t, ZeroCosmosAddr, faucetStatus.Address)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000)), faucetStatus.Balance)
+	require.Len(t, faucetStatus.Recent, 1)
+	require.Equal(t, "AB", faucetStatus.Recent[0].TxHash)
+	require.ElementsMatch(t, []string{ZeroCosmosAddr, testGroupMemberAddr}, faucetStatus.Recent[0].Recipients)
+
+	// The faucet stops once --timeout has passed.
+	run := <-done
+	require.NoError(t, run.Err)
+	require.Len(t, sent, 1)
+	require.Len(t, sent[0].GetMsgs(), 1)
+	multiSend := sent[0].GetMsgs()[0].(*banktypes.MsgMultiSend)
+	require.Equal(t, ZeroCosmosAddr, multiSend.Inputs[0].Address)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 20)), multiSend.Inputs[0].Coins)
+	require.Len(t, multiSend.Outputs, 2)
+
+	// The limits outlast a restart with the same state file.
+	bz, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	require.Contains(t, string(bz), testGroupMemberAddr)
+	url, done = startFaucet(t, sys, "--key", "faucet", "--amount", "10uatom", "--batch-interval", "100ms",
+		"--state-file", statePath, "--dry-run", "--timeout", "1s")
+	status, _ = postCredit(t, url, ZeroCosmosAddr)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	status, body = postCredit(t, url, testContractAddr)
+	require.Equal(t, http.StatusOK, status, body)
+	require.Empty(t, body["tx_hash"])
+	run = <-done
+	require.NoError(t, run.Err)
+	var dryRun []map[string]interface{}
+	require.NoError(t, json.Unmarshal(run.Stdout.Bytes(), &dryRun))
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgMultiSend", dryRun[0]["@type"])
+
+	for args, msg := range map[string]string{
+		"--amount 0uatom":                   `invalid --amount "0uatom"`,
+		"--amount 1uatom --address-limit 1": `invalid --address-limit "1": must be COUNT/WINDOW`,
+		"--amount 1uatom --ip-limit 0/1h":   `invalid --ip-limit "0/1h": count must be a positive number of credits`,
+		"--amount 1uatom --generate-only":   "--generate-only cannot be used by the faucet",
+		"--amount 1uatom --listen nowhere":  "failed to listen for --listen",
+	} {
+		res := sys.Run(zaptest.NewLogger(t), append([]string{"faucet", "start", "--key", "faucet", "--dry-run"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestFaucet_Unconfirmed(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "faucet")
+
+	// The transaction is accepted, but never included.
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil)
+	mc.On("Tx", mock.Anything, mock.Anything, false).Return(nil, errors.New("tx not found"))
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	url, done := startFaucet(t, sys, "cosmoshub", "--key", "faucet", "--amount", "10uatom", "--batch-interval", "100ms",
+		"--block-timeout", "200ms", "--timeout", "2s")
+
+	// The credit is pending, and still counted by the limits, since the transaction may be included later.
+	status, body := postCredit(t, url, ZeroCosmosAddr)
+	require.Equal(t, http.StatusAccepted, status, body)
+	require.Equal(t, map[string]string{"address": ZeroCosmosAddr, "amount": "10uatom", "tx_hash": "AB", "status": "pending"}, body)
+	status, body = postCredit(t, url, ZeroCosmosAddr)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Contains(t, body["error"], "address "+ZeroCosmosAddr+" has reached the limit of 1 credits per 24h0m0s")
+
+	run := <-done
+	require.NoError(t, run.Err)
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const (
+	feegrantSpendLimitFlag  = "spend-limit"
+	feegrantPeriodFlag      = "period"
+	feegrantPeriodLimitFlag = "period-limit"
+)
+
+// feegrantGranterArgsHelp describes the [chain-name] <granter-key> <grantee-address> arguments, for use in command help.
+const feegrantGranterArgsHelp = `If only the granter and the grantee are given, the default chain is used.
+The granter must be a key of the chain, which signs the transaction.`
+
+// feegrantGranterArgs returns the chain, granter, and grantee named by the [chain-name] <granter-key> <grantee-address> arguments.
+func feegrantGranterArgs(a *appState, args []string) (chainName, granter, grantee string) {
+	if len(args) == 3 {
+		return args[0], args[1], args[2]
+	}
+	return a.Config.DefaultChain, args[0], args[1]
+}
+
+func feegrantGrantsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grants [chain-name] <grantee>",
+		Short: "query the fee allowances granted to an account",
+		Long: `Query the fee allowances granted to the grantee on the given chain, or on the default chain,
+listing the granter, allowance type, spend limit, and expiration of each.
+The grantee may be a key name or an address.
+
+The allowance is decoded if its type is known to the chain's codec; otherwise only its type URL is shown.
+Expired allowances, which the chain no longer honors, are marked as such.
+Every page of allowances is requested in turn.`,
+		Example: fmt.Sprintf(`$ %s query feegrant grants cosmoshub cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
+$ %s q feegrant grants mykey -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			granteeAddr, err := cl.AccountFromKeyOrAddress(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			grants, err := query.Feegrant_AllAllowances(cl.MustEncodeAccAddr(granteeAddr))
+			if err != nil {
+				return err
+			}
+
+			result := make(feeAllowancesResult, len(grants))
+			now := time.Now()
+			for i, g := range grants {
+				result[i] = summarizeFeeAllowance(cl, g, now)
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	return cmd
+}
+
+// summarizeFeeAllowance returns the summary of the fee allowance grant g, checking its expiration against now.
+// The limits are only known if the allowance type is known to the codec.
+func summarizeFeeAllowance(cl *client.ChainClient, g *feegrant.Grant, now time.Time) feeAllowanceSummary {
+	summary := feeAllowanceSummary{Granter: g.Granter, Grantee: g.Grantee}
+	var allowance feegrant.FeeAllowanceI
+	var ok bool
+	if summary.Allowance, ok = decodeAny(cl, g.Allowance, &allowance); ok {
+		summary.setLimits(allowance)
+	}
+	summary.Expired = summary.Expiration != nil && !summary.Expiration.After(now)
+	return summary
+}
+
+// setLimits sets the limits of s from those of allowance, and of the allowance it wraps if any.
+func (s *feeAllowanceSummary) setLimits(allowance feegrant.FeeAllowanceI) {
+	switch allowance := allowance.(type) {
+	case *feegrant.BasicAllowance:
+		s.SpendLimit = allowance.SpendLimit
+		s.Expiration = allowance.Expiration
+	case *feegrant.PeriodicAllowance:
+		s.setLimits(&allowance.Basic)
+		s.Period = allowance.Period.String()
+		s.PeriodSpendLimit = allowance.PeriodSpendLimit
+	case *feegrant.AllowedMsgAllowance:
+		s.AllowedMessages = allowance.AllowedMessages
+		if wrapped, err := allowance.GetAllowance(); err == nil {
+			s.setLimits(wrapped)
+		}
+	}
+}
+
+// feeAllowanceSummary is one fee allowance listed by query feegrant grants.
+type feeAllowanceSummary struct {
+	Granter          string     `json:"granter"`
+	Grantee          string     `json:"grantee"`
+	Allowance        decodedAny `json:"allowance"`
+	SpendLimit       sdk.Coins  `json:"spend_limit,omitempty"`
+	Expiration       *time.Time `json:"expiration"`
+	Period           string     `json:"period,omitempty"`
+	PeriodSpendLimit sdk.Coins  `json:"period_spend_limit,omitempty"`
+	AllowedMessages  []string   `json:"allowed_messages,omitempty"`
+	Expired          bool       `json:"expired"`
+}
+
+// feeAllowancesResult is the result of query feegrant grants.
+type feeAllowancesResult []feeAllowanceSummary
+
+var _ fmt.Stringer = feeAllowancesResult{}
+
+// String returns the fee allowances as a table with aligned columns.
+func (r feeAllowancesResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GRANTER\tALLOWANCE\tSPEND LIMIT\tPERIOD\tEXPIRATION")
+	for _, g := range r {
+		period := "-"
+		if g.Period != "" {
+			period = fmt.Sprintf("%s per %s", orDash(g.PeriodSpendLimit.String()), g.Period)
+		}
+		expiration := "never"
+		if g.Expiration != nil {
+			expiration = g.Expiration.UTC().Format(time.RFC3339)
+		}
+		if g.Expired {
+			expiration += " (expired)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g.Granter, orDash(g.Allowance.Type), orDash(g.SpendLimit.String()), period, expiration)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func feegrantGrantCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant [chain-name] <granter-key> <grantee-address>",
+		Short: "grant a fee allowance to an account",
+		Long: `Grant the grantee an allowance to pay transaction fees from the account of the granter.
+
+` + feegrantGranterArgsHelp + `
+
+With --period and --period-limit, a periodic allowance is granted, allowing the grantee to spend
+up to the period limit in each period; otherwise a basic allowance is granted.
+With --spend-limit, the total the grantee may spend is limited, and with --expiration, the allowance expires at that time.
+With --dry-run, the message of the transaction is written without signing or broadcasting it.`,
+		Example: fmt.Sprintf(`$ %s tx feegrant grant cosmoshub mykey cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p --spend-limit 100uatom
+$ %s tx feegrant grant mykey cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p --spend-limit 100uatom --expiration 2030-01-01 --period 24h --period-limit 10uatom`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, granterKey, granteeArg := feegrantGranterArgs(a, args)
+
+			basic := feegrant.BasicAllowance{}
+			spendLimit, err := cmd.Flags().GetString(feegrantSpendLimitFlag)
+			if err != nil {
+				return err
+			}
+			if spendLimit != "" {
+				if basic.SpendLimit, err = sdk.ParseCoinsNormalized(spendLimit); err != nil {
+					return fmt.Errorf("invalid spend limit %q: %w", spendLimit, err)
+				}
+			}
+			expiration, err := cmd.Flags().GetString(expirationFlag)
+			if err != nil {
+				return err
+			}
+			if basic.Expiration, err = parseExpiration(expiration); err != nil {
+				return err
+			}
+
+			period, err := cmd.Flags().GetDuration(feegrantPeriodFlag)
+			if err != nil {
+				return err
+			}
+			periodLimit, err := cmd.Flags().GetString(feegrantPeriodLimitFlag)
+			if err != nil {
+				return err
+			}
+			var allowance feegrant.FeeAllowanceI = &basic
+			switch {
+			case period == 0 && periodLimit == "":
+			case period <= 0 || periodLimit == "":
+				return fmt.Errorf("--%s and --%s must be given together, with a positive period", feegrantPeriodFlag, feegrantPeriodLimitFlag)
+			default:
+				limit, err := sdk.ParseCoinsNormalized(periodLimit)
+				if err != nil {
+					return fmt.Errorf("invalid period limit %q: %w", periodLimit, err)
+				}
+				if !basic.SpendLimit.Empty() && limit.IsAnyGT(basic.SpendLimit) {
+					return fmt.Errorf("the period limit %s cannot exceed the spend limit %s", limit, basic.SpendLimit)
+				}
+				reset := time.Now().Add(period).UTC()
+				if basic.Expiration != nil && reset.After(*basic.Expiration) {
+					return fmt.Errorf("the first period of %s would end after the expiration %s", period, basic.Expiration.UTC().Format(time.RFC3339))
+				}
+				allowance = &feegrant.PeriodicAllowance{
+					Basic:            basic,
+					Period:           period,
+					PeriodSpendLimit: limit,
+					PeriodCanSpend:   limit,
+					PeriodReset:      reset,
+				}
+			}
+			if err := allowance.ValidateBasic(); err != nil {
+				return err
+			}
+
+			cl, granter, err := txChainClient(cmd, a, chainName, granterKey)
+			if err != nil {
+				return err
+			}
+			grantee, err := cl.DecodeBech32AccAddr(granteeArg)
+			if err != nil {
+				return fmt.Errorf("invalid grantee address %q: %w", granteeArg, err)
+			}
+
+			// Not feegrant.NewMsgGrantAllowance, which encodes the addresses with the global bech32 prefix.
+			allowanceAny, err := codectypes.NewAnyWithValue(allowance.(proto.Message))
+			if err != nil {
+				return err
+			}
+			msg := &feegrant.MsgGrantAllowance{
+				Granter:   cl.MustEncodeAccAddr(granter),
+				Grantee:   cl.MustEncodeAccAddr(grantee),
+				Allowance: allowanceAny,
+			}
+			return sendOrWriteMsgs(cmd, a, cl, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(feegrantSpendLimitFlag, "", "total the grantee may spend on fees, such as 100uatom (no limit if empty)")
+	cmd.Flags().String(expirationFlag, "", "time at which the allowance expires, "+expirationHelp)
+	cmd.Flags().Duration(feegrantPeriodFlag, 0, "length of the periods of a periodic allowance, such as 24h")
+	cmd.Flags().String(feegrantPeriodLimitFlag, "", "total the grantee may spend on fees in each period, such as 10uatom")
+	addDryRunFlag(cmd)
+	memoFlag(a.Viper, cmd)
+	return cmd
+}
+
+func feegrantRevokeCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [chain-name] <granter-key> <grantee-address>",
+		Short: "revoke the fee allowance granted to an account",
+		Long: `Revoke the fee allowance granted by the granter to the grantee.
+
+` + feegrantGranterArgsHelp + `
+
+With --dry-run, the message of the transaction is written without signing or broadcasting it.`,
+		Example: fmt.Sprintf(`$ %s tx feegrant revoke cosmoshub mykey cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p`,
+			appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, granterKey, granteeArg := feegrantGranterArgs(a, args)
+			cl, granter, err := txChainClient(cmd, a, chainName, granterKey)
+			if err != nil {
+				return err
+			}
+			grantee, err := cl.DecodeBech32AccAddr(granteeArg)
+			if err != nil {
+				return fmt.Errorf("invalid grantee address %q: %w", granteeArg, err)
+			}
+
+			msg := &feegrant.MsgRevokeAllowance{
+				Granter: cl.MustEncodeAccAddr(granter),
+				Grantee: cl.MustEncodeAccAddr(grantee),
+			}
+			return sendOrWriteMsgs(cmd, a, cl, []sdk.Msg{msg})
+		},
+	}
+	addDryRunFlag(cmd)
+	memoFlag(a.Viper, cmd)
+	return cmd
+}
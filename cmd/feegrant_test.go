@@ -0,0 +1,214 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFeegrantGrants(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	basic, err := codectypes.NewAnyWithValue(&feegrant.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)),
+		Expiration: &past,
+	})
+	require.NoError(t, err)
+	periodic, err := codectypes.NewAnyWithValue(&feegrant.PeriodicAllowance{
+		Period:           24 * time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)),
+	})
+	require.NoError(t, err)
+	unknown := &codectypes.Any{TypeUrl: "/example.v1.CustomAllowance", Value: []byte{1}}
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.feegrant.v1beta1.Query/Allowances", func(bytes.HexBytes) bool { return true },
+		&feegrant.QueryAllowancesResponse{
+			Allowances: []*feegrant.Grant{
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Allowance: basic},
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Allowance: periodic},
+				{Granter: ZeroCosmosAddr, Grantee: ZeroCosmosAddr, Allowance: unknown},
+			},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "feegrant", "grants", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"GRANTER", "ALLOWANCE", "SPEND", "LIMIT", "PERIOD", "EXPIRATION"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{ZeroCosmosAddr, "/cosmos.feegrant.v1beta1.BasicAllowance", "100uatom", "-", "2020-01-01T00:00:00Z", "(expired)"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{ZeroCosmosAddr, "/cosmos.feegrant.v1beta1.PeriodicAllowance", "-", "10uatom", "per", "24h0m0s", "never"}, strings.Fields(lines[2]))
+	// The allowance of an unknown type does not fail the command.
+	require.Equal(t, []string{ZeroCosmosAddr, "/example.v1.CustomAllowance", "-", "-", "never"}, strings.Fields(lines[3]))
+
+	res = sys.MustRun(t, "query", "feegrant", "grants", ZeroCosmosAddr, "-o", "json")
+	var grants []struct {
+		Allowance struct {
+			Type    string `json:"@type"`
+			Payload []byte
+		}
+		Period  string
+		Expired bool
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &grants))
+	require.Len(t, grants, 3)
+	require.True(t, grants[0].Expired)
+	require.Equal(t, "24h0m0s", grants[1].Period)
+	require.Equal(t, []byte{1}, grants[2].Allowance.Payload)
+}
+
+func TestFeegrantGrant(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	var msgs []struct {
+		Type      string `json:"@type"`
+		Granter   string
+		Grantee   string
+		Allowance map[string]interface{}
+	}
+
+	res := sys.MustRun(t, "tx", "feegrant", "grant", "cosmoshub", ZeroCosmosAddr, ZeroCosmosAddr, "--dry-run",
+		"--spend-limit", "100uatom", "--expiration", "2999-01-01")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.feegrant.v1beta1.MsgGrantAllowance", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Granter)
+	require.Equal(t, "/cosmos.feegrant.v1beta1.BasicAllowance", msgs[0].Allowance["@type"])
+	require.Equal(t, "2999-01-01T00:00:00Z", msgs[0].Allowance["expiration"])
+
+	res = sys.MustRun(t, "tx", "feegrant", "grant", ZeroCosmosAddr, ZeroCosmosAddr, "--dry-run",
+		"--spend-limit", "100uatom", "--period", "24h", "--period-limit", "10uatom")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.feegrant.v1beta1.PeriodicAllowance", msgs[0].Allowance["@type"])
+	require.Equal(t, "86400s", msgs[0].Allowance["period"])
+
+	soon := time.Now().AddDate(0, 0, 2).UTC().Truncate(24 * time.Hour)
+	for args, msg := range map[string]string{
+		"--expiration " + soon.Format("2006-01-02") + " --period 1000h --period-limit 1uatom": "the first period of 1000h0m0s would end after the expiration " + soon.Format(time.RFC3339),
+		"--period 24h": "--period and --period-limit must be given together",
+		"--spend-limit 1uatom --period-limit 10uatom":             "--period and --period-limit must be given together",
+		"--spend-limit 1uatom --period 1h --period-limit 10uatom": "the period limit 10uatom cannot exceed the spend limit 1uatom",
+		"--spend-limit 1uatom --expiration 2020-01-01":            `invalid expiration "2020-01-01": must be in the future`,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "feegrant", "grant", ZeroCosmosAddr, ZeroCosmosAddr, "--dry-run"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg)
+	}
+
+	res = sys.MustRun(t, "tx", "feegrant", "revoke", ZeroCosmosAddr, ZeroCosmosAddr, "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.feegrant.v1beta1.MsgRevokeAllowance", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Grantee)
+
+	// Without --dry-run, the granter must be a key to sign with.
+	res = sys.Run(zaptest.NewLogger(t), "tx", "feegrant", "revoke", ZeroCosmosAddr, ZeroCosmosAddr)
+	require.ErrorContains(t, res.Err, "a key is needed to sign the transaction")
+}
+
+func TestFeegrant_TxFeeGranter(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	sys.MustRun(t, "keys", "add", "granter")
+	res := sys.MustRun(t, "keys", "show", "granter")
+	granter := strings.TrimSpace(res.Stdout.String())
+
+	// The transaction is simulated without the fee granter.
+	var simulatedGranters []string
+	mc := new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mockABCIQuery(t, mc, "/cosmos.tx.v1beta1.Service/Simulate", func(data bytes.HexBytes) bool {
+		// The matcher is also given the requests of other queries.
+		var req txtypes.SimulateRequest
+		if req.Unmarshal(data) != nil || req.Tx == nil {
+			return false
+		}
+		simulatedGranters = append(simulatedGranters, req.Tx.AuthInfo.Fee.Granter)
+		return true
+	}, &txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100000}})
+	mockABCIQuery(t, mc, "/cosmos.feegrant.v1beta1.Query/Allowances", func(bytes.HexBytes) bool { return true },
+		&feegrant.QueryAllowancesResponse{Pagination: &query.PageResponse{}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	var tx struct {
+		AuthInfo struct {
+			Fee struct {
+				Payer   string
+				Granter string
+			}
+		} `json:"auth_info"`
+	}
+	send := []string{"tx", "bank", "send", "mykey", ZeroCosmosAddr, "1uatom", "--generate-only"}
+
+	// Without an allowance from the granter, a warning is logged.
+	core, logs := observer.New(zap.WarnLevel)
+	res = sys.Run(zap.New(core), append(send, "--fee-granter", granter)...)
+	require.NoError(t, res.Err)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, granter, tx.AuthInfo.Fee.Granter)
+	require.Equal(t, []string{""}, simulatedGranters)
+	require.Equal(t, 1, logs.FilterField(zap.String("granter", granter)).FilterField(zap.String("grantee", ZeroCosmosAddr)).Len())
+
+	// The chain's fee-granter is the default, and the check can be skipped.
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "fee-granter", granter)
+	core, logs = observer.New(zap.WarnLevel)
+	res = sys.Run(zap.New(core), append(send, "--skip-feegrant-check")...)
+	require.NoError(t, res.Err)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, granter, tx.AuthInfo.Fee.Granter)
+	require.Zero(t, logs.Len())
+
+	// With an allowance, there is no warning.
+	allowance, err := codectypes.NewAnyWithValue(&feegrant.BasicAllowance{})
+	require.NoError(t, err)
+	mc = new(mocks.Client)
+	mockSendLookups(t, mc)
+	mockABCIQuery(t, mc, "/cosmos.feegrant.v1beta1.Query/Allowances", func(bytes.HexBytes) bool { return true },
+		&feegrant.QueryAllowancesResponse{
+			Allowances: []*feegrant.Grant{{Granter: granter, Grantee: ZeroCosmosAddr, Allowance: allowance}},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	core, logs = observer.New(zap.WarnLevel)
+	res = sys.Run(zap.New(core), send...)
+	require.NoError(t, res.Err)
+	require.Zero(t, logs.Len())
+
+	// A fee payer other than the key must also sign, so the transaction can only be generated.
+	res = sys.MustRun(t, append(send, "--fee-payer", granter)...)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, granter, tx.AuthInfo.Fee.Payer)
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", ZeroCosmosAddr, "1uatom", "--fee-payer", granter)
+	require.ErrorContains(t, res.Err, "the fee payer "+granter+" must also sign the transaction")
+
+	res = sys.Run(zaptest.NewLogger(t), append(send, "--fee-granter", "osmo1xyz")...)
+	require.ErrorContains(t, res.Err, `invalid --fee-granter "osmo1xyz" for account prefix "cosmos"`)
+	res = sys.Run(zaptest.NewLogger(t), "chains", "edit", "cosmoshub", "fee-granter", "osmo1xyz")
+	require.ErrorContains(t, res.Err, `invalid fee-granter "osmo1xyz"`)
+}
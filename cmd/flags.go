@@ -1,18 +1,43 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	tmquery "github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/strangelove-ventures/lens/client"
 	"github.com/strangelove-ventures/lens/client/query"
 )
 
 const (
 	gRPCSecureOnlyFlag = "secure-only"
+	gRPCNoCacheFlag    = "no-cache"
+	gRPCTLSFlag        = "tls"
+	gRPCTLSCAFlag      = "tls-ca"
+	gRPCTLSCertFlag    = "tls-cert"
+	gRPCTLSKeyFlag     = "tls-key"
+	gRPCTLSServerFlag  = "tls-server-name"
+	gRPCTimeoutFlag    = "timeout"
+	gRPCRetriesFlag    = "retries"
+	gRPCVerboseFlag    = "verbose"
+	gRPCHeaderFlag     = "header"
+	gRPCHeightFlag     = "height"
 	flagMemo           = "memo"
+	proxyFlag          = "proxy"
+	rateLimitFlag      = "rate-limit"
+	retriesFlag        = "retries"
+)
+
+// Flags tuning the gRPC connections of the dynamic commands.
+const (
+	gRPCMaxRecvMsgSizeFlag         = "max-recv-msg-size"
+	gRPCKeepaliveTimeFlag          = "keepalive-time"
+	gRPCKeepaliveTimeoutFlag       = "keepalive-timeout"
+	gRPCKeepaliveWithoutStreamFlag = "keepalive-permit-without-stream"
 )
 
 func peersFlag(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
@@ -44,6 +69,32 @@ func gRPCFlags(cmd *cobra.Command, v *viper.Viper) *cobra.Command {
 	if err := v.BindPFlag(gRPCSecureOnlyFlag, cmd.Flags().Lookup(gRPCSecureOnlyFlag)); err != nil {
 		panic(err)
 	}
+	cmd.Flags().Bool(gRPCNoCacheFlag, false, "ignore cached descriptors and fetch them from the server again")
+	if err := v.BindPFlag(gRPCNoCacheFlag, cmd.Flags().Lookup(gRPCNoCacheFlag)); err != nil {
+		panic(err)
+	}
+	cmd.Flags().Bool(gRPCTLSFlag, false, "connect with TLS, overriding the chain's grpc-tls setting (implied by the other --tls-* flags)")
+	cmd.Flags().String(gRPCTLSCAFlag, "", "PEM file of CA certificates used to verify the server, instead of the system pool")
+	cmd.Flags().String(gRPCTLSCertFlag, "", "PEM file of the client certificate to present to the server (requires --"+gRPCTLSKeyFlag+")")
+	cmd.Flags().String(gRPCTLSKeyFlag, "", "PEM file of the client private key (requires --"+gRPCTLSCertFlag+")")
+	cmd.Flags().String(gRPCTLSServerFlag, "", "server name to verify the server certificate against, instead of the dialed host")
+	cmd.Flags().Duration(gRPCTimeoutFlag, 10*time.Second, "how long to wait for the connection to the server to be established")
+	cmd.Flags().Uint(gRPCRetriesFlag, 3, "how many times to retry reflection requests and calls that fail because the server is unavailable")
+	cmd.Flags().Bool(gRPCVerboseFlag, false, "list every available service when a requested service is not found")
+	cmd.Flags().Int64(gRPCHeightFlag, 0, "query the state at this block height, sent as the x-cosmos-block-height header, overriding --header and the chain's grpc-headers (0 for the latest)")
+	cmd.Flags().StringArray(gRPCHeaderFlag, nil, "send this key=value header with every call, overriding the chain's grpc-headers (repeatable; a value of env:VARNAME is read from $VARNAME)")
+	cmd.Flags().Int(gRPCMaxRecvMsgSizeFlag, client.DefaultGRPCMaxRecvMsgSize, "largest response to accept from the server, in bytes, overriding the chain's grpc-max-recv-msg-size")
+	cmd.Flags().Duration(gRPCKeepaliveTimeFlag, 0, "ping the server after the connection is idle this long, such as 30s, overriding the chain's grpc-keepalive-time (0 to never ping)")
+	cmd.Flags().Duration(gRPCKeepaliveTimeoutFlag, 0, "close the connection if a keepalive ping is not answered within this long, overriding the chain's grpc-keepalive-timeout (0 for 20s)")
+	cmd.Flags().Bool(gRPCKeepaliveWithoutStreamFlag, false, "send keepalive pings even when no call is in progress, overriding the chain's grpc-keepalive-permit-without-stream")
+	for _, f := range []string{
+		gRPCTLSFlag, gRPCTLSCAFlag, gRPCTLSCertFlag, gRPCTLSKeyFlag, gRPCTLSServerFlag, gRPCTimeoutFlag, gRPCRetriesFlag, gRPCVerboseFlag, gRPCHeaderFlag, gRPCHeightFlag,
+		gRPCMaxRecvMsgSizeFlag, gRPCKeepaliveTimeFlag, gRPCKeepaliveTimeoutFlag, gRPCKeepaliveWithoutStreamFlag,
+	} {
+		if err := v.BindPFlag(f, cmd.Flags().Lookup(f)); err != nil {
+			panic(err)
+		}
+	}
 
 	return cmd
 }
@@ -126,6 +177,12 @@ func ReadHeight(flagSet *pflag.FlagSet) (int64, error) {
 	}
 }
 
+// addQueryHeightFlag adds the --height flag of the queries at a past height.
+// It is used instead of flags.AddQueryFlagsToCmd, whose local --output flag would shadow the root --output flag.
+func addQueryHeightFlag(cmd *cobra.Command) {
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+}
+
 func queryOptionsFromFlags(flags *pflag.FlagSet) (*query.QueryOptions, error) {
 	// Query options
 	pr, err := ReadPageRequest(flags)
@@ -0,0 +1,554 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func genesisCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "genesis",
+		Short: "inspect a genesis file, without loading it into memory",
+	}
+	cmd.AddCommand(
+		genesisAccountsCmd(a),
+		genesisParamsCmd(a),
+	)
+	return cmd
+}
+
+const (
+	genesisMinBalanceFlag  = "min-balance"
+	genesisPrefixFlag      = "prefix"
+	genesisAccountTypeFlag = "account-type"
+)
+
+// genesisSourceHelp describes the sources of the genesis commands.
+const genesisSourceHelp = `The genesis file is read from a path, from an http:// or https:// URL, or from standard input if "-",
+and may be compressed with gzip. It may also be the response of the genesis endpoint of a node's RPC.
+It is read as it is downloaded, without ever holding the whole file in memory.`
+
+// genesisAccountsColumns are the columns of the rows written by genesis accounts, in order.
+var genesisAccountsColumns = []string{"address", "account_type", "account_number", "balance"}
+
+func genesisAccountsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accounts <file-or-url>",
+		Short: "extract the accounts of a genesis file and their balances, as CSV or newline delimited JSON",
+		Long: `Extract the accounts of the auth module of a genesis file with their balances in the bank module,
+one row per account, with its address, account type, account number, and balance.
+Balances without an account are written with an empty type and number, and accounts without a balance
+with an empty balance.
+
+--min-balance keeps only the accounts holding at least the given amount of each of its denoms.
+--account-type keeps only the accounts of the given types, such as BaseAccount or ContinuousVestingAccount.
+--prefix writes the addresses with the given account prefix, such as that of another chain.
+
+` + genesisSourceHelp + `
+The rows are written as the balances are read, once the accounts, which precede them in exported genesis files, are.`,
+		Example: fmt.Sprintf(`$ %s genesis accounts genesis.json --min-balance 1000000uatom --out accounts.csv
+$ %s genesis accounts https://example.com/genesis.json.gz --prefix osmo --format ndjson
+$ %s genesis accounts genesis.json --account-type ContinuousVestingAccount,DelayedVestingAccount`,
+			appName, appName, appName),
+		Args: withUsage(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString(exportFormatFlag)
+			if err != nil {
+				return err
+			}
+			if format != exportFormatCSV && format != exportFormatNDJSON {
+				return fmt.Errorf("invalid --%s %q: must be %s or %s", exportFormatFlag, format, exportFormatCSV, exportFormatNDJSON)
+			}
+			f := genesisAccountsFilter{types: make(map[string]bool)}
+			minBalance, err := cmd.Flags().GetString(genesisMinBalanceFlag)
+			if err != nil {
+				return err
+			}
+			if minBalance != "" {
+				if f.minBalance, err = sdk.ParseCoinsNormalized(minBalance); err != nil {
+					return fmt.Errorf("invalid --%s %q: %w", genesisMinBalanceFlag, minBalance, err)
+				}
+			}
+			types, err := cmd.Flags().GetStringSlice(genesisAccountTypeFlag)
+			if err != nil {
+				return err
+			}
+			for _, t := range types {
+				f.types[t] = true
+			}
+			if f.prefix, err = cmd.Flags().GetString(genesisPrefixFlag); err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			outPath, err := cmd.Flags().GetString(exportOutFlag)
+			if err != nil {
+				return err
+			}
+			if outPath != "" {
+				out, err := os.Create(outPath)
+				if err != nil {
+					return err
+				}
+				defer out.Close()
+				w = out
+			}
+			rw, err := newGenesisAccountsWriter(w, format)
+			if err != nil {
+				return err
+			}
+
+			r, err := openGenesis(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			n, err := extractGenesisAccounts(r, f, rw.write)
+			if err != nil {
+				return fmt.Errorf("failed to read genesis %s: %w", args[0], err)
+			}
+			if err := rw.flush(); err != nil {
+				return err
+			}
+			a.Log.Info("Extracted genesis accounts", zap.Int("rows", n))
+			return nil
+		},
+	}
+	cmd.Flags().String(genesisMinBalanceFlag, "", "keep only the accounts holding at least these coins (e.g. 1000uatom)")
+	cmd.Flags().StringSlice(genesisAccountTypeFlag, nil, "keep only the accounts of these types (e.g. BaseAccount)")
+	cmd.Flags().String(genesisPrefixFlag, "", "write the addresses with this account prefix instead of that of the genesis (e.g. osmo)")
+	cmd.Flags().String(exportFormatFlag, exportFormatCSV, "format of the rows: csv or ndjson")
+	cmd.Flags().String(exportOutFlag, "", "write the rows to this file instead of stdout")
+	return cmd
+}
+
+func genesisParamsCmd(a *appState) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params <file-or-url> <module>",
+		Short: "print the params of a module in a genesis file",
+		Long: `Print the params of the genesis of a module, such as staking or gov, in a genesis file, as indented JSON.
+Modules whose genesis has several params fields, such as the deposit_params, voting_params, and tally_params
+of older versions of gov, have them printed together.
+
+` + genesisSourceHelp,
+		Example: fmt.Sprintf(`$ %s genesis params genesis.json staking
+$ %s genesis params https://rpc.cosmos.directory/cosmoshub/genesis gov`,
+			appName, appName),
+		Args: withUsage(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := openGenesis(cmd, a, args[0])
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			params, err := genesisModuleParams(r, args[1])
+			if err != nil {
+				return fmt.Errorf("failed to read genesis %s: %w", args[0], err)
+			}
+			out, err := json.MarshalIndent(params, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+}
+
+// openGenesis opens the genesis file at source, a path, a URL, or "-" for standard input,
+// decompressing it if it is compressed with gzip.
+func openGenesis(cmd *cobra.Command, a *appState, source string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	switch {
+	case source == "-":
+		rc = io.NopCloser(cmd.InOrStdin())
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := a.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, fmt.Errorf("response code: %d: GET failed: %s", res.StatusCode, source)
+		}
+		rc = res.Body
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		rc = f
+	}
+
+	br := bufio.NewReader(rc)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return readCloser{Reader: zr, Closer: rc}, nil
+	}
+	return readCloser{Reader: br, Closer: rc}, nil
+}
+
+// readCloser reads from a Reader and closes a Closer, such as the file or response body it reads from.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// genesisAppState calls fn with each module of the app_state of the genesis read by dec,
+// which must consume its genesis, as by skipJSONValue.
+// The response of the genesis endpoint of a node's RPC, whose result holds the genesis, is read too.
+func genesisAppState(dec *json.Decoder, fn func(module string) error) error {
+	found := false
+	var genesis func(key string) error
+	genesis = func(key string) error {
+		switch key {
+		case "app_state":
+			found = true
+			return jsonObjectFields(dec, fn)
+		case "result", "genesis":
+			return jsonObjectFields(dec, genesis)
+		default:
+			return skipJSONValue(dec)
+		}
+	}
+	if err := jsonObjectFields(dec, genesis); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no app_state found")
+	}
+	return nil
+}
+
+// genesisAccount is an account of the auth module in a genesis file.
+type genesisAccount struct {
+	Type          string `json:"@type"`
+	Address       string `json:"address"`
+	AccountNumber string `json:"account_number"`
+	// BaseAccount and BaseVestingAccount hold the address of module and vesting accounts.
+	BaseAccount        *genesisAccount `json:"base_account"`
+	BaseVestingAccount *genesisAccount `json:"base_vesting_account"`
+}
+
+// base returns the account holding the address and number of the account.
+func (acc genesisAccount) base() genesisAccount {
+	switch {
+	case acc.Address != "":
+		return acc
+	case acc.BaseAccount != nil:
+		return acc.BaseAccount.base()
+	case acc.BaseVestingAccount != nil:
+		return acc.BaseVestingAccount.base()
+	default:
+		return acc
+	}
+}
+
+// genesisBalance is a balance of the bank module in a genesis file.
+type genesisBalance struct {
+	Address string    `json:"address"`
+	Coins   sdk.Coins `json:"coins"`
+}
+
+// genesisAccountRow is an account extracted by genesis accounts.
+type genesisAccountRow struct {
+	Address       string `json:"address"`
+	AccountType   string `json:"account_type"`
+	AccountNumber string `json:"account_number"`
+	Balance       string `json:"balance"`
+}
+
+// record returns the row as CSV fields, in the order of genesisAccountsColumns.
+func (r genesisAccountRow) record() []string {
+	return []string{r.Address, r.AccountType, r.AccountNumber, r.Balance}
+}
+
+// genesisAccountsFilter selects the accounts written by genesis accounts, and the prefix of their addresses.
+type genesisAccountsFilter struct {
+	minBalance sdk.Coins
+	types      map[string]bool
+	prefix     string
+}
+
+// row returns the row of the account with address and balance, of type and number from acc if known,
+// and whether it is selected by f.
+func (f genesisAccountsFilter) row(address string, acc *genesisAccount, balance sdk.Coins) (genesisAccountRow, bool, error) {
+	if !f.minBalance.Empty() && !balance.IsAllGTE(f.minBalance) {
+		return genesisAccountRow{}, false, nil
+	}
+	row := genesisAccountRow{Address: address, Balance: balance.String()}
+	if acc != nil {
+		// The type of the account is the name of its message, without its package.
+		row.AccountType = acc.Type[strings.LastIndex(acc.Type, ".")+1:]
+		row.AccountNumber = acc.base().AccountNumber
+	}
+	if len(f.types) > 0 && !f.types[row.AccountType] {
+		return genesisAccountRow{}, false, nil
+	}
+	if f.prefix != "" {
+		_, bz, err := bech32.DecodeAndConvert(address)
+		if err != nil {
+			return genesisAccountRow{}, false, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		if row.Address, err = bech32.ConvertAndEncode(f.prefix, bz); err != nil {
+			return genesisAccountRow{}, false, err
+		}
+	}
+	return row, true, nil
+}
+
+// extractGenesisAccounts reads the accounts and balances of the genesis read from r,
+// and calls write with the row of each account selected by f, returning the number of rows written.
+// Only the accounts are held in memory, until their balance is read,
+// and the balances too if they precede the accounts in the genesis.
+func extractGenesisAccounts(r io.Reader, f genesisAccountsFilter, write func(genesisAccountRow) error) (int, error) {
+	accounts := make(map[string]*genesisAccount)
+	accountsRead := false
+	var pending []genesisBalance
+	n := 0
+	writeBalance := func(b genesisBalance) error {
+		acc := accounts[b.Address]
+		delete(accounts, b.Address)
+		row, ok, err := f.row(b.Address, acc, b.Coins)
+		if err != nil || !ok {
+			return err
+		}
+		n++
+		return write(row)
+	}
+
+	dec := json.NewDecoder(r)
+	err := genesisAppState(dec, func(module string) error {
+		switch module {
+		case "auth":
+			err := jsonObjectFields(dec, func(key string) error {
+				if key != "accounts" {
+					return skipJSONValue(dec)
+				}
+				return jsonArrayElements(dec, func() error {
+					var acc genesisAccount
+					if err := dec.Decode(&acc); err != nil {
+						return fmt.Errorf("invalid account: %w", err)
+					}
+					accounts[acc.base().Address] = &acc
+					return nil
+				})
+			})
+			accountsRead = true
+			return err
+		case "bank":
+			return jsonObjectFields(dec, func(key string) error {
+				if key != "balances" {
+					return skipJSONValue(dec)
+				}
+				return jsonArrayElements(dec, func() error {
+					var b genesisBalance
+					if err := dec.Decode(&b); err != nil {
+						return fmt.Errorf("invalid balance: %w", err)
+					}
+					if !accountsRead {
+						pending = append(pending, b)
+						return nil
+					}
+					return writeBalance(b)
+				})
+			})
+		default:
+			return skipJSONValue(dec)
+		}
+	})
+	if err != nil {
+		return n, err
+	}
+
+	for _, b := range pending {
+		if err := writeBalance(b); err != nil {
+			return n, err
+		}
+	}
+	// The accounts left have no balance, and are written in the order of their address.
+	addrs := make([]string, 0, len(accounts))
+	for addr := range accounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	for _, addr := range addrs {
+		row, ok, err := f.row(addr, accounts[addr], nil)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			continue
+		}
+		n++
+		if err := write(row); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// genesisAccountsWriter writes rows in the format of genesis accounts.
+type genesisAccountsWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+// newGenesisAccountsWriter returns a writer of rows to w in format, having written the CSV header.
+func newGenesisAccountsWriter(w io.Writer, format string) (*genesisAccountsWriter, error) {
+	if format == exportFormatNDJSON {
+		return &genesisAccountsWriter{json: json.NewEncoder(w)}, nil
+	}
+	rw := &genesisAccountsWriter{csv: csv.NewWriter(w)}
+	return rw, rw.csv.Write(genesisAccountsColumns)
+}
+
+func (rw *genesisAccountsWriter) write(r genesisAccountRow) error {
+	if rw.json != nil {
+		return rw.json.Encode(r)
+	}
+	return rw.csv.Write(r.record())
+}
+
+// flush writes the buffered rows to the underlying writer.
+func (rw *genesisAccountsWriter) flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	return nil
+}
+
+// genesisModuleParams returns the params of module in the genesis read from r:
+// its params field, or else its fields named *_params, as in older versions of gov.
+func genesisModuleParams(r io.Reader, module string) (interface{}, error) {
+	var modules []string
+	found := false
+	params := make(map[string]json.RawMessage)
+
+	dec := json.NewDecoder(r)
+	err := genesisAppState(dec, func(name string) error {
+		modules = append(modules, name)
+		if name != module {
+			return skipJSONValue(dec)
+		}
+		found = true
+		return jsonObjectFields(dec, func(key string) error {
+			if key != "params" && !strings.HasSuffix(key, "_params") {
+				return skipJSONValue(dec)
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			if string(raw) != "null" {
+				params[key] = raw
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !found:
+		sort.Strings(modules)
+		return nil, fmt.Errorf("module %q not found (modules: %s)", module, strings.Join(modules, ", "))
+	case params["params"] != nil:
+		return params["params"], nil
+	case len(params) == 0:
+		return nil, fmt.Errorf("the genesis of module %q has no params", module)
+	default:
+		return params, nil
+	}
+}
+
+// jsonObjectFields reads a JSON object from dec, calling fn with the key of each of its fields,
+// which must consume the value of the field, as by skipJSONValue.
+func jsonObjectFields(dec *json.Decoder, fn func(key string) error) error {
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected an object key at offset %d, got %v", dec.InputOffset(), tok)
+		}
+		if err := fn(key); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return expectJSONDelim(dec, '}')
+}
+
+// jsonArrayElements reads a JSON array from dec, calling fn before each of its elements,
+// which must consume the element, as by dec.Decode.
+func jsonArrayElements(dec *json.Decoder, fn func() error) error {
+	if err := expectJSONDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(dec, ']')
+}
+
+// expectJSONDelim reads the delimiter want from dec.
+func expectJSONDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %v at offset %d, got %v", want, dec.InputOffset(), tok)
+	}
+	return nil
+}
+
+// skipJSONValue reads the next JSON value from dec, token by token, so that a large value is never held in memory.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
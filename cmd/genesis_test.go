@@ -0,0 +1,134 @@
+package cmd_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const testDistrModuleAddr = "cosmos1jv65s3grqf6v6jl3dp4t6c9t9rk99cd88lyufl"
+
+// testGenesisModules are the auth, bank, gov, and staking modules of a genesis, in this order.
+var testGenesisModules = []string{
+	fmt.Sprintf(`"auth": {"params": {"max_memo_characters": "256"}, "accounts": [
+		{"@type": "/cosmos.auth.v1beta1.BaseAccount", "address": %q, "pub_key": null, "account_number": "0", "sequence": "0"},
+		{"@type": "/cosmos.auth.v1beta1.ModuleAccount", "base_account": {"address": %q, "account_number": "1", "sequence": "0"}, "name": "distribution", "permissions": []},
+		{"@type": "/cosmos.vesting.v1beta1.ContinuousVestingAccount", "base_vesting_account": {"base_account": {"address": %q, "account_number": "2", "sequence": "0"}, "original_vesting": [{"denom": "uatom", "amount": "100"}]}, "start_time": "0"}
+	]}`, ZeroCosmosAddr, testDistrModuleAddr, testGroupMemberAddr),
+	fmt.Sprintf(`"bank": {"params": {"default_send_enabled": true}, "balances": [
+		{"address": %q, "coins": [{"denom": "uatom", "amount": "5000"}]},
+		{"address": %q, "coins": [{"denom": "stake", "amount": "7"}, {"denom": "uatom", "amount": "10"}]}
+	], "supply": [{"denom": "uatom", "amount": "5010"}]}`, ZeroCosmosAddr, testDistrModuleAddr),
+	`"gov": {"starting_proposal_id": "1", "deposit_params": {"max_deposit_period": "172800s"}, "voting_params": {"voting_period": "172800s"}, "params": null}`,
+	`"staking": {"params": {"unbonding_time": "1814400s", "max_validators": 100, "bond_denom": "uatom"}, "validators": []}`,
+}
+
+func TestGenesisAccounts(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	genesis := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(genesis, []byte(`{"genesis_time": "2019-12-11T16:11:34Z", "chain_id": "cosmoshub-4",
+		"app_state": {`+strings.Join(testGenesisModules, ",")+`}, "consensus_params": {}}`), 0o600))
+
+	// The accounts with a balance are written as their balances are read, followed by those without.
+	res := sys.MustRun(t, "genesis", "accounts", genesis)
+	rows, err := csv.NewReader(&res.Stdout).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"address", "account_type", "account_number", "balance"},
+		{ZeroCosmosAddr, "BaseAccount", "0", "5000uatom"},
+		{testDistrModuleAddr, "ModuleAccount", "1", "7stake,10uatom"},
+		{testGroupMemberAddr, "ContinuousVestingAccount", "2", ""},
+	}, rows)
+
+	res = sys.MustRun(t, "genesis", "accounts", genesis, "--min-balance", "10uatom", "--prefix", "osmo", "--format", "ndjson")
+	var accounts []map[string]string
+	dec := json.NewDecoder(&res.Stdout)
+	for dec.More() {
+		var acc map[string]string
+		require.NoError(t, dec.Decode(&acc))
+		accounts = append(accounts, acc)
+	}
+	require.Len(t, accounts, 2)
+	require.Equal(t, "osmo1r5v5srda7xfth3hn2s26txvrcrntldjuns5tpd", accounts[0]["address"])
+	require.Equal(t, "ModuleAccount", accounts[1]["account_type"])
+
+	out := filepath.Join(t.TempDir(), "accounts.csv")
+	sys.MustRun(t, "genesis", "accounts", genesis, "--account-type", "ContinuousVestingAccount,BaseAccount", "--min-balance", "1uatom", "--out", out)
+	bz, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "address,account_type,account_number,balance\n"+ZeroCosmosAddr+",BaseAccount,0,5000uatom\n", string(bz))
+
+	// A genesis may be downloaded, compressed, or be the response of the genesis RPC endpoint,
+	// whose balances may precede the accounts.
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	fmt.Fprintf(zw, `{"jsonrpc": "2.0", "id": -1, "result": {"genesis": {"app_state": {%s, %s}}}}`, testGenesisModules[1], testGenesisModules[0])
+	require.NoError(t, zw.Close())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/genesis" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(gz.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+	res = sys.MustRun(t, "genesis", "accounts", srv.URL+"/genesis", "--min-balance", "8uatom")
+	require.Equal(t, "address,account_type,account_number,balance\n"+
+		ZeroCosmosAddr+",BaseAccount,0,5000uatom\n"+
+		testDistrModuleAddr+",ModuleAccount,1,\"7stake,10uatom\"\n", res.Stdout.String())
+
+	for args, msg := range map[string]string{
+		srv.URL + "/missing":               "response code: 404",
+		genesis + " --min-balance 10":      `invalid --min-balance "10"`,
+		genesis + " --format xml":          `invalid --format "xml"`,
+		filepath.Join(t.TempDir(), "none"): "no such file",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"genesis", "accounts"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+	res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(`{"app_state": {"bank": {"balances": [{"address": 1}]}}}`), "genesis", "accounts", "-")
+	require.ErrorContains(t, res.Err, "failed to read genesis -: app_state: bank: balances: invalid balance")
+}
+
+func TestGenesisParams(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	genesis := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(genesis, []byte(`{"app_state": {`+strings.Join(testGenesisModules, ",")+`}}`), 0o600))
+
+	res := sys.MustRun(t, "genesis", "params", genesis, "staking")
+	require.Equal(t, `{
+  "unbonding_time": "1814400s",
+  "max_validators": 100,
+  "bond_denom": "uatom"
+}
+`, res.Stdout.String())
+
+	// The params of older versions of gov are in several fields.
+	res = sys.MustRun(t, "genesis", "params", genesis, "gov")
+	var params map[string]map[string]string
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &params))
+	require.Equal(t, map[string]map[string]string{
+		"deposit_params": {"max_deposit_period": "172800s"},
+		"voting_params":  {"voting_period": "172800s"},
+	}, params)
+
+	res = sys.Run(zaptest.NewLogger(t), "genesis", "params", genesis, "mint")
+	require.ErrorContains(t, res.Err, `module "mint" not found (modules: auth, bank, gov, staking)`)
+	res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(`{"chain_id": "test"}`), "genesis", "params", "-", "staking")
+	require.ErrorContains(t, res.Err, "no app_state found")
+}
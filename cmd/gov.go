@@ -0,0 +1,514 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const (
+	govStatusFlag = "status"
+	govSortFlag   = "sort"
+)
+
+// Values of the --sort flag of query gov proposals.
+const (
+	govSortID      = "id"
+	govSortEndTime = "end-time"
+)
+
+// proposalStatusNames maps the proposal statuses to the names used by the --status flag and in output.
+var proposalStatusNames = map[govtypes.ProposalStatus]string{
+	govtypes.StatusDepositPeriod: "deposit",
+	govtypes.StatusVotingPeriod:  "voting",
+	govtypes.StatusPassed:        "passed",
+	govtypes.StatusRejected:      "rejected",
+	govtypes.StatusFailed:        "failed",
+}
+
+// proposalStatusName returns the short name of s, or its full name if it has none.
+func proposalStatusName(s govtypes.ProposalStatus) string {
+	if name, ok := proposalStatusNames[s]; ok {
+		return name
+	}
+	return s.String()
+}
+
+// parseProposalStatus returns the status named name, as accepted by the --status flag.
+// The empty name is the unspecified status, matching every proposal.
+func parseProposalStatus(name string) (govtypes.ProposalStatus, error) {
+	if name == "" {
+		return govtypes.StatusNil, nil
+	}
+	var names []string
+	for s, n := range proposalStatusNames {
+		if n == name {
+			return s, nil
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return govtypes.StatusNil, fmt.Errorf("unknown proposal status %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+func govProposalsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "proposals [chain-name]",
+		Aliases: []string{"props"},
+		Short:   "query the governance proposals of a chain",
+		Long: `Query the governance proposals of the given chain, or of the default chain,
+listing the ID, title, status, and voting end time of each.
+
+` + paginationHelp + `
+The proposals of the pages read are sorted by ID, or by voting end time with --sort end-time.`,
+		Example: fmt.Sprintf(`$ %s query gov proposals cosmoshub
+$ %s q gov proposals osmosis --status voting --sort end-time
+$ %s q gov proposals cosmoshub --limit 20 --reverse`,
+			appName, appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			statusName, err := cmd.Flags().GetString(govStatusFlag)
+			if err != nil {
+				return err
+			}
+			status, err := parseProposalStatus(statusName)
+			if err != nil {
+				return err
+			}
+			sortBy, err := cmd.Flags().GetString(govSortFlag)
+			if err != nil {
+				return err
+			}
+			if sortBy != govSortID && sortBy != govSortEndTime {
+				return fmt.Errorf("unknown sort order %q (must be %s or %s)", sortBy, govSortID, govSortEndTime)
+			}
+
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			proposals, err := query.Gov_AllProposals(status)
+			if err != nil {
+				return err
+			}
+
+			result := make(proposalsResult, len(proposals))
+			for i, p := range proposals {
+				_, title := decodeProposalContent(cl, p.Content)
+				result[i] = proposalSummary{
+					ID:            p.ProposalId,
+					Title:         title,
+					Status:        proposalStatusName(p.Status),
+					VotingEndTime: p.VotingEndTime,
+				}
+			}
+			sort.SliceStable(result, func(i, j int) bool {
+				if sortBy == govSortEndTime && !result[i].VotingEndTime.Equal(result[j].VotingEndTime) {
+					return result[i].VotingEndTime.Before(result[j].VotingEndTime)
+				}
+				return result[i].ID < result[j].ID
+			})
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	cmd.Flags().String(govStatusFlag, "", "only list proposals with this status (deposit, voting, passed, rejected, or failed)")
+	cmd.Flags().String(govSortFlag, govSortID, "sort the proposals by id or by end-time")
+	addPaginationFlags(cmd, "proposals")
+	return cmd
+}
+
+func govProposalCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "proposal [chain-name] <proposal-id>",
+		Aliases: []string{"prop"},
+		Short:   "query the details of a governance proposal",
+		Long: `Query a governance proposal of the given chain, or of the default chain,
+with its current vote tally, its total deposit, and its content.
+
+The content is decoded if its type is known to the chain's codec;
+otherwise its type URL and base64 encoded payload are shown.`,
+		Example: fmt.Sprintf(`$ %s query gov proposal cosmoshub 82
+$ %s q gov proposal 82 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			id, err := parseProposalID(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Gov_Proposal(id)
+			if err != nil {
+				return err
+			}
+			// The tally is only final once voting ended; this query returns the current one otherwise.
+			tally, err := query.Gov_TallyResult(id)
+			if err != nil {
+				return fmt.Errorf("failed to query tally of proposal %d: %w", id, err)
+			}
+
+			p := res.Proposal
+			content, title := decodeProposalContent(cl, p.Content)
+			return writeOutput(cmd, a, proposalDetail{
+				ID:              p.ProposalId,
+				Title:           title,
+				Status:          proposalStatusName(p.Status),
+				SubmitTime:      p.SubmitTime,
+				DepositEndTime:  p.DepositEndTime,
+				VotingStartTime: p.VotingStartTime,
+				VotingEndTime:   p.VotingEndTime,
+				TotalDeposit:    p.TotalDeposit,
+				Tally: proposalTally{
+					Yes:        tally.Tally.Yes,
+					Abstain:    tally.Tally.Abstain,
+					No:         tally.Tally.No,
+					NoWithVeto: tally.Tally.NoWithVeto,
+				},
+				Content: content,
+			})
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	return cmd
+}
+
+// decodeProposalContent decodes the packed content of a proposal with the codec of cl,
+// returning it with its title.
+// Content of a type unknown to the codec is returned undecoded, without a title.
+func decodeProposalContent(cl *client.ChainClient, any *codectypes.Any) (decodedAny, string) {
+	var content govtypes.Content
+	decoded, ok := decodeAny(cl, any, &content)
+	if !ok {
+		return decoded, ""
+	}
+	return decoded, content.GetTitle()
+}
+
+// proposalSummary is one proposal listed by query gov proposals.
+type proposalSummary struct {
+	ID            uint64    `json:"id"`
+	Title         string    `json:"title"`
+	Status        string    `json:"status"`
+	VotingEndTime time.Time `json:"voting_end_time"`
+}
+
+// proposalsResult is the result of query gov proposals.
+type proposalsResult []proposalSummary
+
+var _ fmt.Stringer = proposalsResult{}
+
+// String returns the proposals as a table with aligned columns.
+func (r proposalsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tVOTING END\tTITLE")
+	for _, p := range r {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", p.ID, p.Status, formatProposalTime(p.VotingEndTime), orDash(p.Title))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// proposalTally is the tally of the votes on a proposal.
+type proposalTally struct {
+	Yes        sdk.Int `json:"yes"`
+	Abstain    sdk.Int `json:"abstain"`
+	No         sdk.Int `json:"no"`
+	NoWithVeto sdk.Int `json:"no_with_veto"`
+}
+
+// proposalDetail is the result of query gov proposal.
+type proposalDetail struct {
+	ID              uint64        `json:"id"`
+	Title           string        `json:"title"`
+	Status          string        `json:"status"`
+	SubmitTime      time.Time     `json:"submit_time"`
+	DepositEndTime  time.Time     `json:"deposit_end_time"`
+	VotingStartTime time.Time     `json:"voting_start_time"`
+	VotingEndTime   time.Time     `json:"voting_end_time"`
+	TotalDeposit    sdk.Coins     `json:"total_deposit"`
+	Tally           proposalTally `json:"tally"`
+	Content         decodedAny    `json:"content"`
+}
+
+var _ fmt.Stringer = proposalDetail{}
+
+// String returns the fields of the proposal one per line, followed by its content.
+func (p proposalDetail) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", p.ID)
+	fmt.Fprintf(w, "Title:\t%s\n", orDash(p.Title))
+	fmt.Fprintf(w, "Status:\t%s\n", p.Status)
+	fmt.Fprintf(w, "Submitted:\t%s\n", formatProposalTime(p.SubmitTime))
+	fmt.Fprintf(w, "Deposit end:\t%s\n", formatProposalTime(p.DepositEndTime))
+	fmt.Fprintf(w, "Voting start:\t%s\n", formatProposalTime(p.VotingStartTime))
+	fmt.Fprintf(w, "Voting end:\t%s\n", formatProposalTime(p.VotingEndTime))
+	fmt.Fprintf(w, "Total deposit:\t%s\n", orDash(p.TotalDeposit.String()))
+	fmt.Fprintf(w, "Tally:\tyes %s, abstain %s, no %s, no with veto %s\n", p.Tally.Yes, p.Tally.Abstain, p.Tally.No, p.Tally.NoWithVeto)
+	fmt.Fprintf(w, "Content type:\t%s\n", orDash(p.Content.Type))
+	w.Flush()
+
+	p.Content.writeBody(&b, "Content")
+	return b.String()
+}
+
+// formatProposalTime formats t for text output, as "-" if it is not set.
+func formatProposalTime(t time.Time) string {
+	if t.IsZero() || t.Unix() <= 0 {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ========== Transaction Functions ==========
+
+const (
+	govWeightedFlag = "weighted"
+	govForceFlag    = "force"
+)
+
+// voteOptionNames maps the names of vote options accepted by tx gov vote to the options.
+var voteOptionNames = map[string]govtypes.VoteOption{
+	"yes":          govtypes.OptionYes,
+	"no":           govtypes.OptionNo,
+	"abstain":      govtypes.OptionAbstain,
+	"no_with_veto": govtypes.OptionNoWithVeto,
+}
+
+// parseVoteOption returns the vote option named name.
+func parseVoteOption(name string) (govtypes.VoteOption, error) {
+	if option, ok := voteOptionNames[strings.ToLower(name)]; ok {
+		return option, nil
+	}
+	names := make([]string, 0, len(voteOptionNames))
+	for n := range voteOptionNames {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return govtypes.OptionEmpty, fmt.Errorf("unknown vote option %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// parseWeightedVote returns the weighted vote options of s, formatted as "yes=0.7,abstain=0.3".
+// Each option may be given once, and the weights must sum to 1.
+func parseWeightedVote(s string) (govtypes.WeightedVoteOptions, error) {
+	var options govtypes.WeightedVoteOptions
+	seen := make(map[govtypes.VoteOption]bool)
+	total := sdk.ZeroDec()
+	for _, part := range strings.Split(s, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid weighted vote %q: %q is not of the form option=weight", s, part)
+		}
+		option, err := parseVoteOption(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weighted vote %q: %w", s, err)
+		}
+		if seen[option] {
+			return nil, fmt.Errorf("invalid weighted vote %q: option %s is given more than once", s, name)
+		}
+		seen[option] = true
+		w, err := sdk.NewDecFromStr(weight)
+		if err != nil || !w.IsPositive() {
+			return nil, fmt.Errorf("invalid weighted vote %q: weight %q of %s must be a positive decimal", s, weight, name)
+		}
+		total = total.Add(w)
+		options = append(options, govtypes.WeightedVoteOption{Option: option, Weight: w})
+	}
+	if !total.Equal(sdk.OneDec()) {
+		return nil, fmt.Errorf("invalid weighted vote %q: the weights sum to %s, not 1", s, total)
+	}
+	return options, nil
+}
+
+// checkProposalStatus queries the proposal id, and returns an error with its status unless it is one of allowed,
+// or if --force is set.
+func checkProposalStatus(cmd *cobra.Command, cl *client.ChainClient, id uint64, allowed ...govtypes.ProposalStatus) error {
+	force, err := cmd.Flags().GetBool(govForceFlag)
+	if err != nil {
+		return err
+	}
+	if force {
+		return nil
+	}
+
+	q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+	res, err := q.Gov_Proposal(id)
+	if err != nil {
+		return fmt.Errorf("failed to query proposal %d (use --%s to skip this check): %w", id, govForceFlag, err)
+	}
+	for _, s := range allowed {
+		if res.Proposal.Status == s {
+			return nil
+		}
+	}
+	names := make([]string, len(allowed))
+	for i, s := range allowed {
+		names[i] = proposalStatusName(s)
+	}
+	return fmt.Errorf("proposal %d has status %s, not %s (use --%s to skip this check)",
+		id, proposalStatusName(res.Proposal.Status), strings.Join(names, " or "), govForceFlag)
+}
+
+// parseProposalID parses the proposal ID argument s.
+func parseProposalID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid proposal ID %q: %w", s, err)
+	}
+	return id, nil
+}
+
+func govVoteCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vote [chain-name] <from-key> <proposal-id> [yes|no|abstain|no_with_veto]",
+		Short: "vote on a governance proposal",
+		Long: `Vote with a key in the keyring of the given chain, or of the default chain, on a governance proposal.
+
+With --weighted, the vote is split among several options, whose weights must sum to 1,
+and no option argument is given.
+
+The proposal is queried first, and the command fails unless it is in its voting period;
+--force skips this check.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx gov vote cosmoshub mykey 82 yes
+$ %s tx gov vote mykey 82 --weighted "yes=0.7,abstain=0.3"`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			weighted, err := cmd.Flags().GetString(govWeightedFlag)
+			if err != nil {
+				return err
+			}
+			n := 3
+			if weighted != "" {
+				n = 2
+				// Without a chain, the last argument would be taken for the proposal ID.
+				if _, err := parseProposalID(args[len(args)-1]); err != nil || len(args) == 4 {
+					return fmt.Errorf("a vote option cannot be given with --%s", govWeightedFlag)
+				}
+			} else if len(args) == 2 {
+				return fmt.Errorf("a vote option or --%s is required", govWeightedFlag)
+			}
+
+			chainName, args := txArgs(a, args, n)
+			id, err := parseProposalID(args[1])
+			if err != nil {
+				return err
+			}
+
+			cl, voterAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			voter := cl.MustEncodeAccAddr(voterAddr)
+
+			var msg sdk.Msg
+			if weighted != "" {
+				options, err := parseWeightedVote(weighted)
+				if err != nil {
+					return err
+				}
+				msg = &govtypes.MsgVoteWeighted{ProposalId: id, Voter: voter, Options: options}
+			} else {
+				option, err := parseVoteOption(args[2])
+				if err != nil {
+					return err
+				}
+				msg = &govtypes.MsgVote{ProposalId: id, Voter: voter, Option: option}
+			}
+
+			if err := checkProposalStatus(cmd, cl, id, govtypes.StatusVotingPeriod); err != nil {
+				return err
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(govWeightedFlag, "", `split the vote among options, with weights summing to 1 (e.g. "yes=0.7,abstain=0.3")`)
+	cmd.Flags().Bool(govForceFlag, false, "do not check that the proposal is in its voting period")
+	return cmd
+}
+
+func govDepositCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit [chain-name] <from-key> <proposal-id> <amount>",
+		Short: "deposit tokens on a governance proposal",
+		Long: `Deposit tokens from a key in the keyring of the given chain, or of the default chain, on a governance proposal.
+
+The proposal is queried first, and the command fails unless it is in its deposit or voting period;
+--force skips this check.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx gov deposit cosmoshub mykey 82 1000000uatom`, appName),
+		Args:    withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 3)
+			id, err := parseProposalID(args[1])
+			if err != nil {
+				return err
+			}
+			amount, err := sdk.ParseCoinsNormalized(args[2])
+			if err != nil {
+				return fmt.Errorf("invalid amount %q: %w", args[2], err)
+			}
+			if amount.IsZero() {
+				return fmt.Errorf("invalid amount %q: must be positive", args[2])
+			}
+
+			cl, depositor, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkProposalStatus(cmd, cl, id, govtypes.StatusDepositPeriod, govtypes.StatusVotingPeriod); err != nil {
+				return err
+			}
+
+			msg := &govtypes.MsgDeposit{
+				ProposalId: id,
+				Depositor:  cl.MustEncodeAccAddr(depositor),
+				Amount:     amount,
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().Bool(govForceFlag, false, "do not check that the proposal is in its deposit or voting period")
+	return cmd
+}
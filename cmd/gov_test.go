@@ -0,0 +1,238 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mockGovProposals makes mc answer the queries of proposals with a text proposal,
+// and with a proposal whose content is of a type unknown to the codec, on separate pages.
+func mockGovProposals(t *testing.T, mc *mocks.Client) {
+	t.Helper()
+
+	text, err := codectypes.NewAnyWithValue(&govtypes.TextProposal{Title: "Hello", Description: "World"})
+	require.NoError(t, err)
+	unknown := &codectypes.Any{TypeUrl: "/example.v1.CustomProposal", Value: []byte{1, 2, 3}}
+
+	early := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(48 * time.Hour)
+	proposals := []govtypes.Proposal{
+		{
+			ProposalId:    1,
+			Content:       text,
+			Status:        govtypes.StatusVotingPeriod,
+			VotingEndTime: late,
+			TotalDeposit:  sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)),
+		},
+		{
+			ProposalId:    2,
+			Content:       unknown,
+			Status:        govtypes.StatusPassed,
+			VotingEndTime: early,
+		},
+	}
+
+	pages := map[string]*govtypes.QueryProposalsResponse{
+		"": {
+			Proposals:  proposals[:1],
+			Pagination: &query.PageResponse{NextKey: []byte("next")},
+		},
+		"next": {
+			Proposals:  proposals[1:],
+			Pagination: &query.PageResponse{},
+		},
+	}
+	for key, page := range pages {
+		key := key
+		mockABCIQuery(t, mc, "/cosmos.gov.v1beta1.Query/Proposals", func(data bytes.HexBytes) bool {
+			var req govtypes.QueryProposalsRequest
+			if err := req.Unmarshal(data); err != nil {
+				return false
+			}
+			return string(req.Pagination.Key) == key
+		}, page)
+	}
+
+	for _, p := range proposals {
+		p := p
+		mockABCIQuery(t, mc, "/cosmos.gov.v1beta1.Query/Proposal", func(data bytes.HexBytes) bool {
+			var req govtypes.QueryProposalRequest
+			return req.Unmarshal(data) == nil && req.ProposalId == p.ProposalId
+		}, &govtypes.QueryProposalResponse{Proposal: p})
+	}
+
+	mockABCIQuery(t, mc, "/cosmos.gov.v1beta1.Query/TallyResult", func(bytes.HexBytes) bool { return true },
+		&govtypes.QueryTallyResultResponse{Tally: govtypes.TallyResult{
+			Yes: sdk.NewInt(30), Abstain: sdk.NewInt(1), No: sdk.NewInt(2), NoWithVeto: sdk.NewInt(0),
+		}})
+}
+
+func TestGovProposals(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGovProposals(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "gov", "proposals", "cosmoshub", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"ID", "STATUS", "VOTING", "END", "TITLE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"1", "voting", "2026-01-03T00:00:00Z", "Hello"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"2", "passed", "2026-01-01T00:00:00Z", "-"}, strings.Fields(lines[2]))
+
+	res = sys.MustRun(t, "query", "gov", "proposals", "--sort", "end-time", "-o", "json")
+	var proposals []struct {
+		ID     uint64
+		Status string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &proposals))
+	require.Equal(t, []uint64{2, 1}, []uint64{proposals[0].ID, proposals[1].ID})
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "gov", "proposals", "--status", "bogus")
+	require.ErrorContains(t, res.Err, `unknown proposal status "bogus"`)
+}
+
+func TestGovProposal(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGovProposals(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "gov", "proposal", "cosmoshub", "1", "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Hello")
+	require.Contains(t, out, "500uatom")
+	require.Contains(t, out, "yes 30, abstain 1, no 2, no with veto 0")
+	require.Contains(t, out, `"description": "World"`)
+
+	res = sys.MustRun(t, "query", "gov", "proposal", "1", "-o", "json")
+	var detail struct {
+		Title string
+		Tally map[string]string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &detail))
+	require.Equal(t, "Hello", detail.Title)
+	require.Equal(t, "30", detail.Tally["yes"])
+
+	// Content of an unknown type is shown as its type URL and payload, rather than failing.
+	res = sys.MustRun(t, "query", "gov", "proposal", "cosmoshub", "2", "-o", "json")
+	var unknown struct {
+		Content struct {
+			Type    string `json:"@type"`
+			Payload []byte
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &unknown))
+	require.Equal(t, "/example.v1.CustomProposal", unknown.Content.Type)
+	require.Equal(t, []byte{1, 2, 3}, unknown.Content.Payload)
+
+	res = sys.MustRun(t, "query", "gov", "proposal", "cosmoshub", "2", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Content (base64): AQID")
+}
+
+func TestGovVote(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGovProposals(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	var msgs []struct {
+		Type       string `json:"@type"`
+		ProposalID string `json:"proposal_id"`
+		Voter      string
+		Option     string
+		Options    []struct {
+			Option string
+			Weight sdk.Dec
+		}
+	}
+
+	res := sys.MustRun(t, "tx", "gov", "vote", "cosmoshub", ZeroCosmosAddr, "1", "no_with_veto", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.gov.v1beta1.MsgVote", msgs[0].Type)
+	require.Equal(t, "1", msgs[0].ProposalID)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Voter)
+	require.Equal(t, "VOTE_OPTION_NO_WITH_VETO", msgs[0].Option)
+
+	res = sys.MustRun(t, "tx", "gov", "vote", ZeroCosmosAddr, "1", "--weighted", "yes=0.7,abstain=0.3", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.gov.v1beta1.MsgVoteWeighted", msgs[0].Type)
+	require.Len(t, msgs[0].Options, 2)
+	require.Equal(t, "VOTE_OPTION_ABSTAIN", msgs[0].Options[1].Option)
+	require.Equal(t, sdk.MustNewDecFromStr("0.3"), msgs[0].Options[1].Weight)
+
+	// --force skips the query of the proposal.
+	res = sys.MustRun(t, "tx", "gov", "vote", ZeroCosmosAddr, "99", "yes", "--force", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "99", msgs[0].ProposalID)
+
+	for args, msg := range map[string]string{
+		"2 yes":                        "proposal 2 has status passed, not voting (use --force to skip this check)",
+		"1 maybe":                      `unknown vote option "maybe" (must be one of abstain, no, no_with_veto, yes)`,
+		"1 yes --weighted yes=1":       "a vote option cannot be given with --weighted",
+		"1":                            "a vote option or --weighted is required",
+		"1 --weighted yes=0.7,no=0.2":  "the weights sum to 0.900000000000000000, not 1",
+		"1 --weighted yes=0.5,yes=0.5": "option yes is given more than once",
+		"1 --weighted yes=1.2,no=-0.2": `weight "-0.2" of no must be a positive decimal`,
+		"1 --weighted yes":             `"yes" is not of the form option=weight`,
+		"one yes":                      `invalid proposal ID "one"`,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "gov", "vote", ZeroCosmosAddr, "--dry-run"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestGovDeposit(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGovProposals(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "tx", "gov", "deposit", ZeroCosmosAddr, "1", "100uatom", "--dry-run")
+	var msgs []struct {
+		Type      string `json:"@type"`
+		Depositor string
+		Amount    sdk.Coins
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.gov.v1beta1.MsgDeposit", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Depositor)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), msgs[0].Amount)
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "gov", "deposit", "cosmoshub", ZeroCosmosAddr, "2", "100uatom", "--dry-run")
+	require.ErrorContains(t, res.Err, "proposal 2 has status passed, not deposit or voting")
+}
@@ -0,0 +1,616 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const (
+	groupTitleFlag    = "title"
+	groupSummaryFlag  = "summary"
+	groupMetadataFlag = "metadata"
+	groupExecFlag     = "exec"
+	groupForceFlag    = "force"
+)
+
+// groupQueryCmd returns the group query commands
+func groupQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "Querying commands for the group module",
+	}
+
+	cmd.AddCommand(
+		groupGroupsByMemberCmd(a),
+		groupPoliciesCmd(a),
+		groupProposalsCmd(a),
+	)
+
+	return cmd
+}
+
+// groupTxCmd returns the group tx commands
+func groupTxCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "group transaction commands",
+	}
+
+	cmd.AddCommand(
+		groupSubmitProposalCmd(a),
+		groupVoteCmd(a),
+		groupExecCmd(a),
+	)
+
+	return cmd
+}
+
+func groupGroupsByMemberCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "groups-by-member [chain-name] <member>",
+		Aliases: []string{"groups"},
+		Short:   "query the groups of which an account is a member",
+		Long: `Query the groups of the given chain, or of the default chain, of which an account is a member,
+listing the ID, admin, total weight, and metadata of each.
+The member is a key in the keyring of the chain, or an address.
+
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query group groups-by-member cosmoshub mykey
+$ %s q group groups cosmos1... -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			memberAddr, err := cl.AccountFromKeyOrAddress(args[0])
+			if err != nil {
+				return err
+			}
+
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			groups, err := query.Group_AllGroupsByMember(cl.MustEncodeAccAddr(memberAddr))
+			if err != nil {
+				return err
+			}
+
+			result := make(groupsResult, len(groups))
+			for i, g := range groups {
+				result[i] = groupSummary{
+					ID:          g.Id,
+					Admin:       g.Admin,
+					TotalWeight: g.TotalWeight,
+					Metadata:    g.Metadata,
+				}
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	addPaginationFlags(cmd, "groups")
+	return cmd
+}
+
+// groupSummary is one group listed by query group groups-by-member.
+type groupSummary struct {
+	ID          uint64 `json:"id"`
+	Admin       string `json:"admin"`
+	TotalWeight string `json:"total_weight"`
+	Metadata    string `json:"metadata"`
+}
+
+// groupsResult is the result of query group groups-by-member.
+type groupsResult []groupSummary
+
+var _ fmt.Stringer = groupsResult{}
+
+// String returns the groups as a table with aligned columns.
+func (r groupsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tADMIN\tTOTAL WEIGHT\tMETADATA")
+	for _, g := range r {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", g.ID, g.Admin, g.TotalWeight, orDash(g.Metadata))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func groupPoliciesCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "group-policies [chain-name] <group-id>",
+		Aliases: []string{"policies"},
+		Short:   "query the policy accounts of a group",
+		Long: `Query the group policy accounts of a group of the given chain, or of the default chain,
+listing the address, decision policy, admin, and metadata of each.
+
+Decision policies of types unknown to the chain's codec are shown by their type URL,
+and with -o json or -o yaml, by their base64 encoded payload.
+
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query group group-policies cosmoshub 1
+$ %s q group policies 1 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			groupID, err := parseGroupID(args[0])
+			if err != nil {
+				return err
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			policies, err := query.Group_AllGroupPoliciesByGroup(groupID)
+			if err != nil {
+				return err
+			}
+
+			result := make(groupPoliciesResult, len(policies))
+			for i, p := range policies {
+				decisionPolicy, summary := decodeDecisionPolicy(cl, p.DecisionPolicy)
+				result[i] = groupPolicySummary{
+					Address:        p.Address,
+					GroupID:        p.GroupId,
+					Admin:          p.Admin,
+					Metadata:       p.Metadata,
+					DecisionPolicy: decisionPolicy,
+					summary:        summary,
+				}
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	addPaginationFlags(cmd, "group policies")
+	return cmd
+}
+
+// decodeDecisionPolicy decodes the packed decision policy of a group policy account with the codec of cl,
+// returning it with a short description of it, such as "threshold 2, voting period 24h0m0s".
+// A decision policy of a type unknown to the codec is returned undecoded, described by its type URL.
+func decodeDecisionPolicy(cl *client.ChainClient, any *codectypes.Any) (decodedAny, string) {
+	var policy group.DecisionPolicy
+	decoded, ok := decodeAny(cl, any, &policy)
+	if !ok {
+		return decoded, orDash(decoded.Type)
+	}
+	var summary string
+	switch p := policy.(type) {
+	case *group.ThresholdDecisionPolicy:
+		summary = "threshold " + p.Threshold
+	case *group.PercentageDecisionPolicy:
+		summary = "percentage " + p.Percentage
+	default:
+		summary = any.TypeUrl
+	}
+	if window := policy.GetVotingPeriod(); window > 0 {
+		summary += fmt.Sprintf(", voting period %s", window)
+	}
+	return decoded, summary
+}
+
+// groupPolicySummary is one policy account listed by query group group-policies.
+type groupPolicySummary struct {
+	Address        string     `json:"address"`
+	GroupID        uint64     `json:"group_id"`
+	Admin          string     `json:"admin"`
+	Metadata       string     `json:"metadata"`
+	DecisionPolicy decodedAny `json:"decision_policy"`
+
+	summary string
+}
+
+// groupPoliciesResult is the result of query group group-policies.
+type groupPoliciesResult []groupPolicySummary
+
+var _ fmt.Stringer = groupPoliciesResult{}
+
+// String returns the policy accounts as a table with aligned columns.
+func (r groupPoliciesResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tDECISION POLICY\tADMIN\tMETADATA")
+	for _, p := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Address, p.summary, orDash(p.Admin), orDash(p.Metadata))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func groupProposalsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "proposals [chain-name] <policy-address>",
+		Aliases: []string{"props"},
+		Short:   "query the proposals of a group policy account",
+		Long: `Query the proposals of a group policy account of the given chain, or of the default chain,
+listing the ID, status, voting end time, message types, and title of each, sorted by ID.
+
+Messages of types unknown to the chain's codec are shown by their type URL,
+and with -o json or -o yaml, by their base64 encoded payload, rather than failing the query.
+
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query group proposals cosmoshub cosmos1...
+$ %s q group props cosmos1... -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			policy, err := cl.ResolveAccAddr(args[0])
+			if err != nil {
+				return err
+			}
+			proposals, err := query.Group_AllProposalsByGroupPolicy(policy)
+			if err != nil {
+				return err
+			}
+
+			result := make(groupProposalsResult, len(proposals))
+			for i, p := range proposals {
+				summary := groupProposalSummary{
+					ID:              p.Id,
+					Title:           p.Title,
+					Status:          groupProposalStatusName(p.Status),
+					Proposers:       p.Proposers,
+					VotingPeriodEnd: p.VotingPeriodEnd,
+					Tally: groupTally{
+						Yes:        p.FinalTallyResult.YesCount,
+						Abstain:    p.FinalTallyResult.AbstainCount,
+						No:         p.FinalTallyResult.NoCount,
+						NoWithVeto: p.FinalTallyResult.NoWithVetoCount,
+					},
+					ExecutorResult: groupExecutorResultName(p.ExecutorResult),
+					Messages:       make([]decodedAny, len(p.Messages)),
+				}
+				for j, msg := range p.Messages {
+					var m sdk.Msg
+					summary.Messages[j], _ = decodeAny(cl, msg, &m)
+				}
+				result[i] = summary
+			}
+			sort.SliceStable(result, func(i, j int) bool {
+				return result[i].ID < result[j].ID
+			})
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	addPaginationFlags(cmd, "proposals")
+	return cmd
+}
+
+// groupProposalStatusName returns the short name of s, such as "submitted", for output.
+func groupProposalStatusName(s group.ProposalStatus) string {
+	return strings.ToLower(strings.TrimPrefix(s.String(), "PROPOSAL_STATUS_"))
+}
+
+// groupExecutorResultName returns the short name of r, such as "success", for output.
+func groupExecutorResultName(r group.ProposalExecutorResult) string {
+	return strings.ToLower(strings.TrimPrefix(r.String(), "PROPOSAL_EXECUTOR_RESULT_"))
+}
+
+// groupTally is the final tally of the votes on a group proposal, by weight.
+// It is only set once the voting period ended, or once the proposal was executed.
+type groupTally struct {
+	Yes        string `json:"yes"`
+	Abstain    string `json:"abstain"`
+	No         string `json:"no"`
+	NoWithVeto string `json:"no_with_veto"`
+}
+
+// groupProposalSummary is one proposal listed by query group proposals.
+type groupProposalSummary struct {
+	ID              uint64       `json:"id"`
+	Title           string       `json:"title"`
+	Status          string       `json:"status"`
+	Proposers       []string     `json:"proposers"`
+	VotingPeriodEnd time.Time    `json:"voting_period_end"`
+	Tally           groupTally   `json:"final_tally"`
+	ExecutorResult  string       `json:"executor_result"`
+	Messages        []decodedAny `json:"messages"`
+}
+
+// groupProposalsResult is the result of query group proposals.
+type groupProposalsResult []groupProposalSummary
+
+var _ fmt.Stringer = groupProposalsResult{}
+
+// String returns the proposals as a table with aligned columns.
+func (r groupProposalsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tVOTING END\tMESSAGES\tTITLE")
+	for _, p := range r {
+		types := make([]string, len(p.Messages))
+		for i, m := range p.Messages {
+			types[i] = m.Type
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", p.ID, p.Status, formatProposalTime(p.VotingPeriodEnd), orDash(strings.Join(types, ",")), orDash(p.Title))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// parseGroupID parses the group ID argument s.
+func parseGroupID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid group ID %q: %w", s, err)
+	}
+	return id, nil
+}
+
+// ========== Transaction Functions ==========
+
+// groupExecFromFlags returns the execution mode set by the --exec flag of cmd.
+func groupExecFromFlags(cmd *cobra.Command) (group.Exec, error) {
+	exec, err := cmd.Flags().GetString(groupExecFlag)
+	if err != nil {
+		return group.Exec_EXEC_UNSPECIFIED, err
+	}
+	switch exec {
+	case "":
+		return group.Exec_EXEC_UNSPECIFIED, nil
+	case "try":
+		return group.Exec_EXEC_TRY, nil
+	}
+	return group.Exec_EXEC_UNSPECIFIED, fmt.Errorf("invalid --%s %q (must be try, or empty)", groupExecFlag, exec)
+}
+
+func groupSubmitProposalCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit-proposal [chain-name] <from-key> <policy-address> <msgs-json-file>",
+		Short: "submit a proposal to a group policy account",
+		Long: `Submit a proposal executing the messages of a JSON file with a group policy account,
+proposed by a key in the keyring of the given chain, or of the default chain, which must be a member of the group.
+A file of "-" is read from standard input.
+
+The file holds a list of messages, a single message, or a transaction such as one written by --generate-only,
+each message having its type URL in an @type field; their signer must be the group policy account.
+
+With --exec try, the proposal is executed on submission if the proposer's vote is enough for it to pass.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx group submit-proposal cosmoshub mykey cosmos1... msgs.json --title "Pay the team"
+$ %s tx group submit-proposal mykey cosmos1... msgs.json --title "Pay the team" --exec try --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			title, err := f.GetString(groupTitleFlag)
+			if err != nil {
+				return err
+			}
+			summary, err := f.GetString(groupSummaryFlag)
+			if err != nil {
+				return err
+			}
+			metadata, err := f.GetString(groupMetadataFlag)
+			if err != nil {
+				return err
+			}
+			exec, err := groupExecFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 3)
+			cl, proposer, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			policyAddr, err := cl.DecodeBech32AccAddr(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid group policy address %q: %w", args[1], err)
+			}
+			bz, err := readFileOrStdin(cmd, args[2])
+			if err != nil {
+				return err
+			}
+			msgs, err := readMsgsJSON(cl, bz)
+			if err != nil {
+				return fmt.Errorf("failed to read messages from %s: %w", args[2], err)
+			}
+
+			msg := &group.MsgSubmitProposal{
+				GroupPolicyAddress: cl.MustEncodeAccAddr(policyAddr),
+				Proposers:          []string{cl.MustEncodeAccAddr(proposer)},
+				Metadata:           metadata,
+				Exec:               exec,
+				Title:              title,
+				Summary:            summary,
+			}
+			if err := msg.SetMsgs(msgs); err != nil {
+				return err
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(groupTitleFlag, "", "the title of the proposal")
+	cmd.Flags().String(groupSummaryFlag, "", "the summary of the proposal")
+	cmd.Flags().String(groupMetadataFlag, "", "the metadata of the proposal")
+	cmd.Flags().String(groupExecFlag, "", `"try" to execute the proposal on submission, if the proposer's vote passes it`)
+	return cmd
+}
+
+// groupVoteOptionNames maps the names of vote options accepted by tx group vote to the options.
+var groupVoteOptionNames = map[string]group.VoteOption{
+	"yes":          group.VOTE_OPTION_YES,
+	"no":           group.VOTE_OPTION_NO,
+	"abstain":      group.VOTE_OPTION_ABSTAIN,
+	"no_with_veto": group.VOTE_OPTION_NO_WITH_VETO,
+}
+
+// parseGroupVoteOption returns the group vote option named name.
+func parseGroupVoteOption(name string) (group.VoteOption, error) {
+	if option, ok := groupVoteOptionNames[strings.ToLower(name)]; ok {
+		return option, nil
+	}
+	names := make([]string, 0, len(groupVoteOptionNames))
+	for n := range groupVoteOptionNames {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return group.VOTE_OPTION_UNSPECIFIED, fmt.Errorf("unknown vote option %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// checkGroupMember queries the group of the proposal id, and returns an error unless voter is one of its members,
+// or if --force is set.
+func checkGroupMember(cmd *cobra.Command, cl *client.ChainClient, id uint64, voter string) error {
+	force, err := cmd.Flags().GetBool(groupForceFlag)
+	if err != nil {
+		return err
+	}
+	if force {
+		return nil
+	}
+
+	q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+	proposal, err := q.Group_Proposal(id)
+	if err != nil {
+		return fmt.Errorf("failed to query group proposal %d (use --%s to skip this check): %w", id, groupForceFlag, err)
+	}
+	policy, err := q.Group_GroupPolicyInfo(proposal.Proposal.GroupPolicyAddress)
+	if err != nil {
+		return fmt.Errorf("failed to query group policy %s (use --%s to skip this check): %w", proposal.Proposal.GroupPolicyAddress, groupForceFlag, err)
+	}
+	groupID := policy.Info.GroupId
+	members, err := q.Group_AllGroupMembers(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to query the members of group %d (use --%s to skip this check): %w", groupID, groupForceFlag, err)
+	}
+	for _, m := range members {
+		if m.Member != nil && m.Member.Address == voter {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a member of group %d, which proposal %d was submitted to (use --%s to skip this check)",
+		voter, groupID, id, groupForceFlag)
+}
+
+func groupVoteCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vote [chain-name] <from-key> <proposal-id> <yes|no|abstain|no_with_veto>",
+		Short: "vote on a group proposal",
+		Long: `Vote with a key in the keyring of the given chain, or of the default chain, on a group proposal.
+
+The members of the group of the proposal are queried first, and the command fails unless the key is one of them;
+--force skips this check. With --exec try, the proposal is executed after the vote if the vote passes it.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx group vote cosmoshub mykey 4 yes
+$ %s tx group vote mykey 4 no --metadata "too expensive"`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metadata, err := cmd.Flags().GetString(groupMetadataFlag)
+			if err != nil {
+				return err
+			}
+			exec, err := groupExecFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 3)
+			id, err := parseProposalID(args[1])
+			if err != nil {
+				return err
+			}
+			option, err := parseGroupVoteOption(args[2])
+			if err != nil {
+				return err
+			}
+
+			cl, voterAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			voter := cl.MustEncodeAccAddr(voterAddr)
+			if err := checkGroupMember(cmd, cl, id, voter); err != nil {
+				return err
+			}
+
+			msg := &group.MsgVote{
+				ProposalId: id,
+				Voter:      voter,
+				Option:     option,
+				Metadata:   metadata,
+				Exec:       exec,
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(groupMetadataFlag, "", "the metadata of the vote")
+	cmd.Flags().String(groupExecFlag, "", `"try" to execute the proposal after the vote, if it passes it`)
+	cmd.Flags().Bool(groupForceFlag, false, "do not check that the voter is a member of the group")
+	return cmd
+}
+
+func groupExecCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [chain-name] <from-key> <proposal-id>",
+		Short: "execute an accepted group proposal",
+		Long: `Execute the messages of an accepted group proposal, with a key in the keyring of the given chain,
+or of the default chain, which need not be a member of the group.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx group exec cosmoshub mykey 4`, appName),
+		Args:    withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 2)
+			id, err := parseProposalID(args[1])
+			if err != nil {
+				return err
+			}
+			cl, executor, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			msg := &group.MsgExec{
+				ProposalId: id,
+				Executor:   cl.MustEncodeAccAddr(executor),
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	return cmd
+}
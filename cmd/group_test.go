@@ -0,0 +1,210 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// testGroupPolicyAddr is the address of the group policy account of the group tests.
+const testGroupPolicyAddr = testContractAddr
+
+// testGroupMemberAddr is the address of the other member of group 1 in the group tests.
+const testGroupMemberAddr = "cosmos1qyqszqgpqyqszqgpqyqszqgpqyqszqgpjnp7du"
+
+// mockGroups makes mc answer the queries of group 1, of which ZeroCosmosAddr is not a member,
+// of its policy account testGroupPolicyAddr, and of its proposals 1, holding a message of a type unknown to the codec,
+// and 2, holding a MsgSend.
+func mockGroups(t *testing.T, mc *mocks.Client) {
+	t.Helper()
+
+	mockABCIQuery(t, mc, "/cosmos.group.v1.Query/GroupsByMember", func(data bytes.HexBytes) bool {
+		var req group.QueryGroupsByMemberRequest
+		return req.Unmarshal(data) == nil && req.Address == testGroupMemberAddr
+	}, &group.QueryGroupsByMemberResponse{
+		Groups:     []*group.GroupInfo{{Id: 1, Admin: testGroupPolicyAddr, TotalWeight: "3", Metadata: "team"}},
+		Pagination: &query.PageResponse{},
+	})
+	mockABCIQuery(t, mc, "/cosmos.group.v1.Query/GroupMembers", func(data bytes.HexBytes) bool {
+		var req group.QueryGroupMembersRequest
+		return req.Unmarshal(data) == nil && req.GroupId == 1
+	}, &group.QueryGroupMembersResponse{
+		Members:    []*group.GroupMember{{GroupId: 1, Member: &group.Member{Address: testGroupMemberAddr, Weight: "3"}}},
+		Pagination: &query.PageResponse{},
+	})
+
+	threshold, err := codectypes.NewAnyWithValue(group.NewThresholdDecisionPolicy("2", 24*time.Hour, 0))
+	require.NoError(t, err)
+	policy := &group.GroupPolicyInfo{Address: testGroupPolicyAddr, GroupId: 1, Admin: testGroupPolicyAddr, DecisionPolicy: threshold}
+	mockABCIQuery(t, mc, "/cosmos.group.v1.Query/GroupPoliciesByGroup", func(data bytes.HexBytes) bool {
+		var req group.QueryGroupPoliciesByGroupRequest
+		return req.Unmarshal(data) == nil && req.GroupId == 1
+	}, &group.QueryGroupPoliciesByGroupResponse{
+		GroupPolicies: []*group.GroupPolicyInfo{
+			policy,
+			{Address: testGroupMemberAddr, GroupId: 1, DecisionPolicy: &codectypes.Any{TypeUrl: "/example.v1.CustomPolicy", Value: []byte{1}}},
+		},
+		Pagination: &query.PageResponse{},
+	})
+	mockABCIQuery(t, mc, "/cosmos.group.v1.Query/GroupPolicyInfo", func(data bytes.HexBytes) bool {
+		var req group.QueryGroupPolicyInfoRequest
+		return req.Unmarshal(data) == nil && req.Address == testGroupPolicyAddr
+	}, &group.QueryGroupPolicyInfoResponse{Info: policy})
+
+	send, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: testGroupPolicyAddr,
+		ToAddress:   testGroupMemberAddr,
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)),
+	})
+	require.NoError(t, err)
+	proposals := []*group.Proposal{
+		{
+			Id:                 2,
+			GroupPolicyAddress: testGroupPolicyAddr,
+			Title:              "Pay",
+			Status:             group.PROPOSAL_STATUS_ACCEPTED,
+			VotingPeriodEnd:    time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			FinalTallyResult:   group.TallyResult{YesCount: "3", NoCount: "0", AbstainCount: "0", NoWithVetoCount: "0"},
+			Messages:           []*codectypes.Any{send},
+		},
+		{
+			Id:                 1,
+			GroupPolicyAddress: testGroupPolicyAddr,
+			Status:             group.PROPOSAL_STATUS_SUBMITTED,
+			VotingPeriodEnd:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Messages:           []*codectypes.Any{{TypeUrl: "/example.v1.MsgCustom", Value: []byte{1, 2, 3}}},
+		},
+	}
+	mockABCIQuery(t, mc, "/cosmos.group.v1.Query/ProposalsByGroupPolicy", func(data bytes.HexBytes) bool {
+		var req group.QueryProposalsByGroupPolicyRequest
+		return req.Unmarshal(data) == nil && req.Address == testGroupPolicyAddr
+	}, &group.QueryProposalsByGroupPolicyResponse{Proposals: proposals, Pagination: &query.PageResponse{}})
+	for _, p := range proposals {
+		p := p
+		mockABCIQuery(t, mc, "/cosmos.group.v1.Query/Proposal", func(data bytes.HexBytes) bool {
+			var req group.QueryProposalRequest
+			return req.Unmarshal(data) == nil && req.ProposalId == p.Id
+		}, &group.QueryProposalResponse{Proposal: p})
+	}
+}
+
+func TestGroupQueries(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGroups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "group", "groups-by-member", "cosmoshub", testGroupMemberAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"ID", "ADMIN", "TOTAL", "WEIGHT", "METADATA"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"1", testGroupPolicyAddr, "3", "team"}, strings.Fields(lines[1]))
+
+	// Decision policies of unknown types are listed by their type URL.
+	res = sys.MustRun(t, "query", "group", "group-policies", "1", "-o", "text")
+	lines = strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[1], testGroupPolicyAddr+"  threshold 2, voting period 24h0m0s")
+	require.Equal(t, []string{testGroupMemberAddr, "/example.v1.CustomPolicy", "-", "-"}, strings.Fields(lines[2]))
+
+	// Messages of unknown types are listed by their type URL, rather than failing the query.
+	res = sys.MustRun(t, "query", "group", "proposals", testGroupPolicyAddr, "-o", "text")
+	lines = strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"ID", "STATUS", "VOTING", "END", "MESSAGES", "TITLE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"1", "submitted", "2026-01-01T00:00:00Z", "/example.v1.MsgCustom", "-"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"2", "accepted", "2026-01-02T00:00:00Z", "/cosmos.bank.v1beta1.MsgSend", "Pay"}, strings.Fields(lines[2]))
+
+	res = sys.MustRun(t, "query", "group", "proposals", "cosmoshub", testGroupPolicyAddr, "-o", "json")
+	var proposals []struct {
+		ID         uint64
+		FinalTally map[string]string `json:"final_tally"`
+		Messages   []struct {
+			Type    string `json:"@type"`
+			Value   map[string]interface{}
+			Payload []byte
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &proposals))
+	require.Len(t, proposals, 2)
+	require.Equal(t, []byte{1, 2, 3}, proposals[0].Messages[0].Payload)
+	require.Equal(t, testGroupMemberAddr, proposals[1].Messages[0].Value["to_address"])
+	require.Equal(t, "3", proposals[1].FinalTally["yes"])
+}
+
+func TestGroupTx(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGroups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	file := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "`+testGroupPolicyAddr+`", "to_address": "`+testGroupMemberAddr+`", "amount": [{"denom": "uatom", "amount": "1"}]}
+	]`), 0o600))
+	res := sys.MustRun(t, "tx", "group", "submit-proposal", testGroupMemberAddr, testGroupPolicyAddr, file, "--title", "Pay", "--exec", "try", "--dry-run")
+	var proposals []struct {
+		Type      string `json:"@type"`
+		Proposers []string
+		Exec      string
+		Title     string
+		Messages  []map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &proposals))
+	require.Len(t, proposals, 1)
+	require.Equal(t, "/cosmos.group.v1.MsgSubmitProposal", proposals[0].Type)
+	require.Equal(t, []string{testGroupMemberAddr}, proposals[0].Proposers)
+	require.Equal(t, "EXEC_TRY", proposals[0].Exec)
+	require.Equal(t, "Pay", proposals[0].Title)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", proposals[0].Messages[0]["@type"])
+
+	// The voter must be a member of the group of the proposal, unless --force is given.
+	res = sys.MustRun(t, "tx", "group", "vote", "cosmoshub", testGroupMemberAddr, "1", "yes", "--dry-run")
+	var votes []struct {
+		Type       string `json:"@type"`
+		ProposalID string `json:"proposal_id"`
+		Voter      string
+		Option     string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &votes))
+	require.Len(t, votes, 1)
+	require.Equal(t, "/cosmos.group.v1.MsgVote", votes[0].Type)
+	require.Equal(t, "1", votes[0].ProposalID)
+	require.Equal(t, "VOTE_OPTION_YES", votes[0].Option)
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "group", "vote", ZeroCosmosAddr, "1", "no", "--dry-run")
+	require.ErrorContains(t, res.Err, ZeroCosmosAddr+" is not a member of group 1, which proposal 1 was submitted to (use --force to skip this check)")
+	res = sys.MustRun(t, "tx", "group", "vote", ZeroCosmosAddr, "1", "no", "--force", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &votes))
+	require.Equal(t, ZeroCosmosAddr, votes[0].Voter)
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "group", "vote", ZeroCosmosAddr, "1", "maybe", "--dry-run")
+	require.ErrorContains(t, res.Err, `unknown vote option "maybe" (must be one of abstain, no, no_with_veto, yes)`)
+
+	res = sys.MustRun(t, "tx", "group", "exec", ZeroCosmosAddr, "2", "--dry-run")
+	require.JSONEq(t, `[{"@type": "/cosmos.group.v1.MsgExec", "proposal_id": "2", "executor": "`+ZeroCosmosAddr+`"}]`, res.Stdout.String())
+}
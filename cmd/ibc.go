@@ -0,0 +1,439 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/gogoproto/proto"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/query"
+)
+
+const (
+	ibcPortFlag  = "port"
+	ibcStateFlag = "state"
+)
+
+// channelStateNames maps the channel states to the names used by the --state flag and in output.
+var channelStateNames = map[channeltypes.State]string{
+	channeltypes.INIT:    "init",
+	channeltypes.TRYOPEN: "tryopen",
+	channeltypes.OPEN:    "open",
+	channeltypes.CLOSED:  "closed",
+}
+
+// channelStateName returns the short name of s, or its full name if it has none.
+func channelStateName(s channeltypes.State) string {
+	if name, ok := channelStateNames[s]; ok {
+		return name
+	}
+	return s.String()
+}
+
+// parseChannelState returns the state named name, as accepted by the --state flag.
+func parseChannelState(name string) (channeltypes.State, error) {
+	var names []string
+	for s, n := range channelStateNames {
+		if n == name {
+			return s, nil
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return channeltypes.UNINITIALIZED, fmt.Errorf("unknown channel state %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// connectionStateName returns the short name of s, as for the channel states.
+func connectionStateName(s connectiontypes.State) string {
+	switch s {
+	case connectiontypes.INIT:
+		return "init"
+	case connectiontypes.TRYOPEN:
+		return "tryopen"
+	case connectiontypes.OPEN:
+		return "open"
+	}
+	return s.String()
+}
+
+// ibcChainArg returns the chain named by the optional [chain-name] argument, or the default chain.
+func ibcChainArg(a *appState, args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return a.Config.DefaultChain
+}
+
+func ibcClientsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clients [chain-name]",
+		Short: "query the IBC light clients of a chain",
+		Long: `Query the IBC light clients of the given chain, or of the default chain,
+listing the ID and type of each.
+
+The chain ID and latest height of the counterparty chain are shown for tendermint clients;
+they are left empty for clients of other types.
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query ibc clients cosmoshub
+$ %s q ibc clients osmosis -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := chainClientByName(a, ibcChainArg(a, args))
+			if err != nil {
+				return err
+			}
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			states, err := query.Ibc_AllClientStates()
+			if err != nil {
+				return err
+			}
+
+			result := make(clientsResult, len(states))
+			for i, s := range states {
+				result[i] = summarizeClientState(s.ClientId, s.ClientState)
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	addPaginationFlags(cmd, "clients")
+	return cmd
+}
+
+// summarizeClientState returns the summary of the client with the given ID and packed state.
+// Only the states of tendermint clients are decoded.
+func summarizeClientState(id string, state *codectypes.Any) clientSummary {
+	summary := clientSummary{ID: id}
+	if clientType, _, err := clienttypes.ParseClientIdentifier(id); err == nil {
+		summary.Type = clientType
+	}
+	if state == nil {
+		return summary
+	}
+	summary.StateType = state.TypeUrl
+
+	var tm ibctm.ClientState
+	if state.TypeUrl == "/"+proto.MessageName(&tm) && tm.Unmarshal(state.Value) == nil {
+		summary.ChainID = tm.ChainId
+		summary.LatestHeight = tm.LatestHeight.String()
+	}
+	return summary
+}
+
+// clientSummary is one client listed by query ibc clients.
+type clientSummary struct {
+	ID           string `json:"client_id"`
+	Type         string `json:"client_type"`
+	StateType    string `json:"client_state_type"`
+	ChainID      string `json:"chain_id,omitempty"`
+	LatestHeight string `json:"latest_height,omitempty"`
+}
+
+// clientsResult is the result of query ibc clients.
+type clientsResult []clientSummary
+
+var _ fmt.Stringer = clientsResult{}
+
+// String returns the clients as a table with aligned columns.
+func (r clientsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT ID\tTYPE\tCHAIN ID\tLATEST HEIGHT")
+	for _, c := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.ID, orDash(c.Type), orDash(c.ChainID), orDash(c.LatestHeight))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func ibcConnectionsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "connections [chain-name]",
+		Short: "query the IBC connections of a chain",
+		Long: `Query the IBC connections of the given chain, or of the default chain,
+listing the client, state, and counterparty of each.
+
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query ibc connections cosmoshub
+$ %s q ibc connections osmosis -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := chainClientByName(a, ibcChainArg(a, args))
+			if err != nil {
+				return err
+			}
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			connections, err := query.Ibc_AllConnections()
+			if err != nil {
+				return err
+			}
+
+			result := make(connectionsResult, len(connections))
+			for i, c := range connections {
+				result[i] = connectionSummary{
+					ID:                       c.Id,
+					ClientID:                 c.ClientId,
+					State:                    connectionStateName(c.State),
+					CounterpartyClientID:     c.Counterparty.ClientId,
+					CounterpartyConnectionID: c.Counterparty.ConnectionId,
+					DelayPeriod:              c.DelayPeriod,
+				}
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	addPaginationFlags(cmd, "connections")
+	return cmd
+}
+
+// connectionSummary is one connection listed by query ibc connections.
+type connectionSummary struct {
+	ID                       string `json:"connection_id"`
+	ClientID                 string `json:"client_id"`
+	State                    string `json:"state"`
+	CounterpartyClientID     string `json:"counterparty_client_id"`
+	CounterpartyConnectionID string `json:"counterparty_connection_id"`
+	DelayPeriod              uint64 `json:"delay_period"`
+}
+
+// connectionsResult is the result of query ibc connections.
+type connectionsResult []connectionSummary
+
+var _ fmt.Stringer = connectionsResult{}
+
+// String returns the connections as a table with aligned columns.
+func (r connectionsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CONNECTION ID\tCLIENT ID\tSTATE\tCOUNTERPARTY CLIENT\tCOUNTERPARTY CONNECTION")
+	for _, c := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ID, c.ClientID, c.State, orDash(c.CounterpartyClientID), orDash(c.CounterpartyConnectionID))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func ibcChannelsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "channels [chain-name]",
+		Short: "query the IBC channels of a chain",
+		Long: `Query the IBC channels of the given chain, or of the default chain,
+listing the state, counterparty, and connection hops of each.
+
+With --port and --state, only the channels of the pages read bound to that port or in that state are listed.
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query ibc channels cosmoshub
+$ %s q ibc channels osmosis --port transfer --state open`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			port, err := cmd.Flags().GetString(ibcPortFlag)
+			if err != nil {
+				return err
+			}
+			stateName, err := cmd.Flags().GetString(ibcStateFlag)
+			if err != nil {
+				return err
+			}
+			var state channeltypes.State
+			if stateName != "" {
+				if state, err = parseChannelState(stateName); err != nil {
+					return err
+				}
+			}
+
+			cl, err := chainClientByName(a, ibcChainArg(a, args))
+			if err != nil {
+				return err
+			}
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			channels, err := query.Ibc_AllChannels()
+			if err != nil {
+				return err
+			}
+
+			result := channelsResult{}
+			for _, c := range channels {
+				if port != "" && c.PortId != port {
+					continue
+				}
+				if stateName != "" && c.State != state {
+					continue
+				}
+				result = append(result, channelSummary{
+					PortID:                c.PortId,
+					ChannelID:             c.ChannelId,
+					State:                 channelStateName(c.State),
+					Ordering:              strings.ToLower(strings.TrimPrefix(c.Ordering.String(), "ORDER_")),
+					CounterpartyPortID:    c.Counterparty.PortId,
+					CounterpartyChannelID: c.Counterparty.ChannelId,
+					ConnectionHops:        c.ConnectionHops,
+					Version:               c.Version,
+				})
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	cmd.Flags().String(ibcPortFlag, "", "only list the channels bound to this port")
+	cmd.Flags().String(ibcStateFlag, "", "only list the channels in this state (init, tryopen, open, or closed)")
+	addPaginationFlags(cmd, "channels")
+	return cmd
+}
+
+// channelSummary is one channel listed by query ibc channels.
+type channelSummary struct {
+	PortID                string   `json:"port_id"`
+	ChannelID             string   `json:"channel_id"`
+	State                 string   `json:"state"`
+	Ordering              string   `json:"ordering"`
+	CounterpartyPortID    string   `json:"counterparty_port_id"`
+	CounterpartyChannelID string   `json:"counterparty_channel_id"`
+	ConnectionHops        []string `json:"connection_hops"`
+	Version               string   `json:"version"`
+}
+
+// channelsResult is the result of query ibc channels.
+type channelsResult []channelSummary
+
+var _ fmt.Stringer = channelsResult{}
+
+// String returns the channels as a table with aligned columns.
+func (r channelsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PORT\tCHANNEL\tSTATE\tCOUNTERPARTY PORT\tCOUNTERPARTY CHANNEL\tCONNECTION HOPS")
+	for _, c := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.PortID, c.ChannelID, c.State, orDash(c.CounterpartyPortID), orDash(c.CounterpartyChannelID), orDash(strings.Join(c.ConnectionHops, ",")))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func ibcDenomTraceCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "denom-trace [chain-name] <hash>",
+		Short: "query the denom trace of an IBC denom",
+		Long: `Query the path and base denom of an IBC denom of the given chain, or of the default chain.
+
+The denom may be given as its hash, or as ibc/<hash>.`,
+		Example: fmt.Sprintf(`$ %s query ibc denom-trace osmosis 27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2
+$ %s q ibc denom-trace ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash := strings.TrimPrefix(args[len(args)-1], transfertypes.DenomPrefix+"/")
+			if _, err := transfertypes.ParseHexHash(hash); err != nil {
+				return fmt.Errorf("invalid denom hash %q: %w", args[len(args)-1], err)
+			}
+			cl, err := chainClientByName(a, ibcChainArg(a, args[:len(args)-1]))
+			if err != nil {
+				return err
+			}
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Transfer_DenomTrace(hash)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, denomTracesResult{summarizeDenomTrace(*res.DenomTrace)})
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	return cmd
+}
+
+func ibcDenomTracesCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "denom-traces [chain-name]",
+		Short: "query the denom traces of all IBC denoms of a chain",
+		Long: `Query the path and base denom of every IBC denom of the given chain, or of the default chain.
+
+` + paginationHelp,
+		Example: fmt.Sprintf(`$ %s query ibc denom-traces osmosis
+$ %s q ibc denom-traces -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := chainClientByName(a, ibcChainArg(a, args))
+			if err != nil {
+				return err
+			}
+			pages, opts, err := pagedQueryOptions(cmd)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			traces, err := query.Transfer_AllDenomTraces()
+			if err != nil {
+				return err
+			}
+
+			result := make(denomTracesResult, len(traces))
+			for i, t := range traces {
+				result[i] = summarizeDenomTrace(t)
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
+		},
+	}
+	cmd.Flags().Int64(flags.FlagHeight, 0, "use a specific height to query state at (this can error if the node is pruning state)")
+	addPaginationFlags(cmd, "denom traces")
+	return cmd
+}
+
+// summarizeDenomTrace returns the summary of the IBC denom with trace t.
+func summarizeDenomTrace(t transfertypes.DenomTrace) denomTraceSummary {
+	return denomTraceSummary{Denom: t.IBCDenom(), Path: t.Path, BaseDenom: t.BaseDenom}
+}
+
+// denomTraceSummary is the trace of one IBC denom.
+type denomTraceSummary struct {
+	Denom     string `json:"denom"`
+	Path      string `json:"path"`
+	BaseDenom string `json:"base_denom"`
+}
+
+// denomTracesResult is the result of query ibc denom-trace and denom-traces.
+type denomTracesResult []denomTraceSummary
+
+var _ fmt.Stringer = denomTracesResult{}
+
+// String returns the denom traces as a table with aligned columns.
+func (r denomTracesResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DENOM\tPATH\tBASE DENOM")
+	for _, t := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Denom, orDash(t.Path), t.BaseDenom)
+	}
+	w.Flush()
+	return b.String()
+}
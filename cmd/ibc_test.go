@@ -0,0 +1,132 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIBCClients(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	tmState, err := (&ibctm.ClientState{ChainId: "osmosis-1", LatestHeight: clienttypes.NewHeight(1, 42)}).Marshal()
+	require.NoError(t, err)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/ibc.core.client.v1.Query/ClientStates", func(bytes.HexBytes) bool { return true },
+		&clienttypes.QueryClientStatesResponse{
+			ClientStates: clienttypes.IdentifiedClientStates{
+				{ClientId: "07-tendermint-0", ClientState: &codectypes.Any{TypeUrl: "/ibc.lightclients.tendermint.v1.ClientState", Value: tmState}},
+				// A client of a type unknown to the codec is still listed.
+				{ClientId: "08-wasm-1", ClientState: &codectypes.Any{TypeUrl: "/ibc.lightclients.wasm.v1.ClientState", Value: []byte{1, 2, 3}}},
+			},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "ibc", "clients", "cosmoshub", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"07-tendermint-0", "07-tendermint", "osmosis-1", "1-42"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"08-wasm-1", "08-wasm", "-", "-"}, strings.Fields(lines[2]))
+
+	res = sys.MustRun(t, "query", "ibc", "clients", "-o", "json")
+	var clients []map[string]string
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &clients))
+	require.Equal(t, "/ibc.lightclients.wasm.v1.ClientState", clients[1]["client_state_type"])
+}
+
+func TestIBCChannels(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/ibc.core.channel.v1.Query/Channels", func(bytes.HexBytes) bool { return true },
+		&channeltypes.QueryChannelsResponse{
+			Channels: []*channeltypes.IdentifiedChannel{
+				{
+					PortId: "transfer", ChannelId: "channel-0", State: channeltypes.OPEN, Ordering: channeltypes.UNORDERED,
+					Counterparty:   channeltypes.Counterparty{PortId: "transfer", ChannelId: "channel-141"},
+					ConnectionHops: []string{"connection-0"},
+				},
+				{
+					PortId: "transfer", ChannelId: "channel-1", State: channeltypes.CLOSED, Ordering: channeltypes.UNORDERED,
+					Counterparty:   channeltypes.Counterparty{PortId: "transfer", ChannelId: "channel-7"},
+					ConnectionHops: []string{"connection-1"},
+				},
+				{
+					PortId: "icahost", ChannelId: "channel-2", State: channeltypes.OPEN, Ordering: channeltypes.ORDERED,
+					Counterparty:   channeltypes.Counterparty{PortId: "icacontroller-x", ChannelId: "channel-9"},
+					ConnectionHops: []string{"connection-0"},
+				},
+			},
+			Pagination: &query.PageResponse{},
+			Height:     clienttypes.NewHeight(0, 1),
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "ibc", "channels", "cosmoshub", "--port", "transfer", "--state", "open", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"transfer", "channel-0", "open", "transfer", "channel-141", "connection-0"}, strings.Fields(lines[1]))
+
+	res = sys.MustRun(t, "query", "ibc", "channels", "--state", "open", "-o", "json")
+	var channels []struct {
+		ChannelID string `json:"channel_id"`
+		Ordering  string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &channels))
+	require.Len(t, channels, 2)
+	require.Equal(t, "channel-2", channels[1].ChannelID)
+	require.Equal(t, "ordered", channels[1].Ordering)
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "ibc", "channels", "--state", "opened")
+	require.ErrorContains(t, res.Err, `unknown channel state "opened" (must be one of closed, init, open, tryopen)`)
+}
+
+func TestIBCDenomTraces(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	trace := transfertypes.DenomTrace{Path: "transfer/channel-0", BaseDenom: "uosmo"}
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/ibc.applications.transfer.v1.Query/DenomTrace", func(bytes.HexBytes) bool { return true },
+		&transfertypes.QueryDenomTraceResponse{DenomTrace: &trace})
+	mockABCIQuery(t, mc, "/ibc.applications.transfer.v1.Query/DenomTraces", func(bytes.HexBytes) bool { return true },
+		&transfertypes.QueryDenomTracesResponse{DenomTraces: transfertypes.Traces{trace}, Pagination: &query.PageResponse{}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "ibc", "denom-trace", "cosmoshub", trace.IBCDenom(), "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Equal(t, []string{trace.IBCDenom(), "transfer/channel-0", "uosmo"}, strings.Fields(lines[1]))
+
+	res = sys.MustRun(t, "query", "ibc", "denom-traces", "-o", "json")
+	var traces []map[string]string
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &traces))
+	require.Equal(t, []map[string]string{{"denom": trace.IBCDenom(), "path": "transfer/channel-0", "base_denom": "uosmo"}}, traces)
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "ibc", "denom-trace", "ibc/xyz")
+	require.ErrorContains(t, res.Err, `invalid denom hash "ibc/xyz"`)
+}
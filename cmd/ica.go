@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	icacontrollertypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/controller/types"
+	icatypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const (
+	icaVersionFlag       = "version"
+	icaPacketTimeoutFlag = "packet-timeout"
+	icaPacketMemoFlag    = "packet-memo"
+	icaHostChainFlag     = "host-chain"
+	icaWaitAckFlag       = "wait-ack"
+	icaAckTimeoutFlag    = "ack-timeout"
+
+	// icaAckPollInterval is how often --wait-ack searches for the acknowledgement of the packet.
+	icaAckPollInterval = 2 * time.Second
+
+	// icaAckGrace is how long after the timeout of the packet --wait-ack keeps waiting by default,
+	// for a relayer to relay the timeout.
+	icaAckGrace = time.Minute
+)
+
+// icaQueryCmd returns the interchain accounts query commands
+func icaQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ica",
+		Aliases: []string{"interchain-accounts"},
+		Short:   "Querying commands for the interchain accounts module",
+	}
+
+	cmd.AddCommand(
+		icaInterchainAccountCmd(a),
+	)
+
+	return cmd
+}
+
+// icaTxCmd returns the interchain accounts tx commands
+func icaTxCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ica",
+		Aliases: []string{"interchain-accounts"},
+		Short:   "interchain accounts transaction commands",
+	}
+
+	cmd.AddCommand(
+		icaRegisterCmd(a),
+		icaSubmitCmd(a),
+	)
+
+	return cmd
+}
+
+func icaInterchainAccountCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "interchain-account [chain-name] <owner> <connection-id>",
+		Aliases: []string{"account"},
+		Short:   "query the address of an interchain account on its host chain",
+		Long: `Query the address, on the host chain, of the interchain account that the owner registered
+over a connection of the given chain, or of the default chain.
+The owner is a key in the keyring of the chain, or an address.`,
+		Example: fmt.Sprintf(`$ %s query ica interchain-account cosmoshub mykey connection-0
+$ %s q ica account cosmos1... connection-0 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 2)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			ownerAddr, err := cl.AccountFromKeyOrAddress(args[0])
+			if err != nil {
+				return err
+			}
+			owner := cl.MustEncodeAccAddr(ownerAddr)
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Ica_InterchainAccount(owner, args[1])
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, interchainAccount{Owner: owner, ConnectionID: args[1], Address: res.Address})
+		},
+	}
+	addQueryHeightFlag(cmd)
+	return cmd
+}
+
+// interchainAccount is the result of query ica interchain-account.
+type interchainAccount struct {
+	Owner        string `json:"owner"`
+	ConnectionID string `json:"connection_id"`
+	Address      string `json:"address"`
+}
+
+var _ fmt.Stringer = interchainAccount{}
+
+// String returns the address of the interchain account.
+func (ia interchainAccount) String() string {
+	return ia.Address + "\n"
+}
+
+func icaRegisterCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register [chain-name] <from-key> <connection-id>",
+		Short: "register an interchain account over a connection",
+		Long: `Register an interchain account owned by a key in the keyring of the given chain, or of the default chain,
+on the host chain at the other end of the connection. Once a relayer completes the handshake of its channel,
+query ica interchain-account returns its address on the host chain.
+
+The version of the channel is negotiated by the chains, unless --version is given,
+as the JSON metadata of the interchain accounts version.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx ica register cosmoshub mykey connection-0
+$ %s tx ica register mykey connection-0 --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := cmd.Flags().GetString(icaVersionFlag)
+			if err != nil {
+				return err
+			}
+			chainName, args := txArgs(a, args, 2)
+			cl, ownerAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			msg := &icacontrollertypes.MsgRegisterInterchainAccount{
+				Owner:        cl.MustEncodeAccAddr(ownerAddr),
+				ConnectionId: args[1],
+				Version:      version,
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(icaVersionFlag, "", "the version of the channel of the interchain account (default: negotiated by the chains)")
+	return cmd
+}
+
+func icaSubmitCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit [chain-name] <from-key> <connection-id> <file>",
+		Short: "execute messages with an interchain account on its host chain",
+		Long: `Send a packet executing the messages in the file with the interchain account of a key in the keyring
+of the given chain, or of the default chain, over a connection. A file of "-" is read from standard input.
+
+The file holds a JSON list of messages of the host chain, each with its type URL, as in
+  [{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "<interchain account>", ...}]
+They are decoded with the message types of the chain given by --host-chain, or else of the given chain,
+including those of its extra-msg-descriptors, and encoded in the packet with protobuf.
+
+The packet times out --packet-timeout after the block time of the transaction on the host chain.
+With --wait-ack, the acknowledgement relayed back is waited for, and its result or error is shown;
+the command fails if the host chain failed to execute the messages, or if the packet timed out.
+The acknowledgement is waited for until the packet times out and a minute more, unless --ack-timeout is given.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx ica submit cosmoshub mykey connection-0 msgs.json
+$ %s tx ica submit mykey connection-0 msgs.json --host-chain osmosis --wait-ack
+$ cat msgs.json | %s tx ica submit mykey connection-0 - --packet-timeout 1h --dry-run`,
+			appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			timeout, err := f.GetDuration(icaPacketTimeoutFlag)
+			if err != nil {
+				return err
+			}
+			if timeout <= 0 {
+				return fmt.Errorf("--%s must be positive", icaPacketTimeoutFlag)
+			}
+			memo, err := f.GetString(icaPacketMemoFlag)
+			if err != nil {
+				return err
+			}
+			hostChain, err := f.GetString(icaHostChainFlag)
+			if err != nil {
+				return err
+			}
+			waitAck, err := f.GetBool(icaWaitAckFlag)
+			if err != nil {
+				return err
+			}
+			ackTimeout, err := f.GetDuration(icaAckTimeoutFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 3)
+			cl, ownerAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			hostCodec := cl.Codec.Marshaler
+			if hostChain != "" {
+				host, err := chainClientByName(a, hostChain)
+				if err != nil {
+					return err
+				}
+				hostCodec = host.Codec.Marshaler
+			}
+
+			bz, err := readFileOrStdin(cmd, args[2])
+			if err != nil {
+				return err
+			}
+			data, err := icaPacketData(hostCodec, bz, memo)
+			if err != nil {
+				return fmt.Errorf("failed to read messages from %s: %w", args[2], err)
+			}
+			msg := &icacontrollertypes.MsgSendTx{
+				Owner:           cl.MustEncodeAccAddr(ownerAddr),
+				ConnectionId:    args[1],
+				PacketData:      data,
+				RelativeTimeout: uint64(timeout.Nanoseconds()),
+			}
+			if !waitAck {
+				return sendTx(cmd, a, cl, msg)
+			}
+			return sendTxAndWaitAck(cmd, a, cl, hostCodec, msg, ackTimeout)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().Duration(icaPacketTimeoutFlag, time.Duration(icatypes.DefaultRelativePacketTimeoutTimestamp), "how long after the transaction the packet times out")
+	cmd.Flags().String(icaPacketMemoFlag, "", "the memo of the packet, distinct from the memo of the transaction")
+	cmd.Flags().String(icaHostChainFlag, "", "a configured chain whose message types decode the messages (default: the given chain)")
+	cmd.Flags().Bool(icaWaitAckFlag, false, "wait for the acknowledgement of the packet, and show its result")
+	cmd.Flags().Duration(icaAckTimeoutFlag, 0, "how long to wait for the acknowledgement with --wait-ack (default: until the packet times out, and a minute more)")
+	return cmd
+}
+
+// icaPacketData returns the packet data executing the messages of the JSON list bz, decoded with cdc, on the host chain.
+func icaPacketData(cdc codec.Codec, bz []byte, memo string) (icatypes.InterchainAccountPacketData, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(bz, &raws); err != nil {
+		return icatypes.InterchainAccountPacketData{}, fmt.Errorf("expected a JSON list of messages: %w", err)
+	}
+	if len(raws) == 0 {
+		return icatypes.InterchainAccountPacketData{}, errors.New("no messages")
+	}
+	msgs := make([]proto.Message, len(raws))
+	for i, raw := range raws {
+		var msg sdk.Msg
+		if err := cdc.UnmarshalInterfaceJSON(raw, &msg); err != nil {
+			return icatypes.InterchainAccountPacketData{}, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		msgs[i] = msg
+	}
+	data, err := icatypes.SerializeCosmosTx(cdc, msgs)
+	if err != nil {
+		return icatypes.InterchainAccountPacketData{}, err
+	}
+	return icatypes.InterchainAccountPacketData{Type: icatypes.EXECUTE_TX, Data: data, Memo: memo}, nil
+}
+
+// sendTxAndWaitAck sends msg as sendTx does, then waits for the acknowledgement of the packet it sends,
+// and writes the result of the transaction with the acknowledgement, whose message responses are decoded with hostCodec.
+func sendTxAndWaitAck(cmd *cobra.Command, a *appState, cl *client.ChainClient, hostCodec codec.Codec, msg sdk.Msg, ackTimeout time.Duration) error {
+	opts, err := txOptionsFromFlags(cmd, cl)
+	if err != nil {
+		return err
+	}
+	for _, flag := range []string{dryRunFlag, txGenerateOnlyFlag} {
+		if set, err := cmd.Flags().GetBool(flag); err != nil {
+			return err
+		} else if set {
+			return fmt.Errorf("--%s cannot be given with --%s, as no packet is sent", flag, icaWaitAckFlag)
+		}
+	}
+	if opts.BroadcastMode != client.BroadcastBlock {
+		return fmt.Errorf("--%s requires the %s broadcast mode, to find the packet sent", icaWaitAckFlag, client.BroadcastBlock)
+	}
+	if err := checkFeeOptions(cmd, a, cl, opts, false); err != nil {
+		return err
+	}
+
+	res, err := cl.SendMsgsWithOptions(cmd.Context(), []sdk.Msg{msg}, opts)
+	if res == nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	if err != nil {
+		if werr := writeOutput(cmd, a, txResultWithEvents(cmd, res)); werr != nil {
+			return werr
+		}
+		return err
+	}
+	packets, err := client.SentPackets(res)
+	if err != nil {
+		return err
+	}
+	if len(packets) != 1 {
+		return fmt.Errorf("expected transaction %s to send 1 packet, but it sent %d", res.TxHash, len(packets))
+	}
+	packet := packets[0]
+
+	ctx := cmd.Context()
+	var cancel context.CancelFunc
+	switch {
+	case ackTimeout > 0:
+		ctx, cancel = context.WithTimeout(ctx, ackTimeout)
+	case !packet.TimeoutTimestamp.IsZero():
+		ctx, cancel = context.WithDeadline(ctx, packet.TimeoutTimestamp.Add(icaAckGrace))
+	default:
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	a.Log.Info("Waiting for the acknowledgement of the packet", zap.Stringer("packet", packet), zap.Time("timeout", packet.TimeoutTimestamp))
+	ack, err := cl.WaitForPacketAcknowledgement(ctx, packet, icaAckPollInterval)
+	if err != nil {
+		if werr := writeOutput(cmd, a, txResultWithEvents(cmd, res)); werr != nil {
+			return werr
+		}
+		return err
+	}
+
+	result := icaSubmitResult{Tx: txResultWithEvents(cmd, res), Ack: decodeICAAck(hostCodec, packet, ack)}
+	if err := writeOutput(cmd, a, result); err != nil {
+		return err
+	}
+	if !result.Ack.Success {
+		return fmt.Errorf("the host chain failed to execute the messages of packet %s: %s", packet, result.Ack.Error)
+	}
+	return nil
+}
+
+// icaAck is the acknowledgement of a packet of interchain account messages.
+type icaAck struct {
+	Packet string `json:"packet"`
+
+	// TxHash and Height are those of the transaction relaying the acknowledgement.
+	TxHash string `json:"txhash"`
+	Height int64  `json:"height"`
+
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+
+	// MsgResponses are the responses of the messages executed, as JSON,
+	// or as objects with only their type URL if their types are not known.
+	MsgResponses []json.RawMessage `json:"msg_responses,omitempty"`
+}
+
+// decodeICAAck decodes the acknowledgement of packet, whose message responses are decoded with cdc.
+func decodeICAAck(cdc codec.Codec, packet client.SentPacket, ack client.PacketAcknowledgement) icaAck {
+	res := icaAck{Packet: packet.String(), TxHash: ack.TxHash, Height: ack.Height}
+
+	var acknowledgement channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(ack.Acknowledgement, &acknowledgement); err != nil {
+		res.Error = fmt.Sprintf("undecodable acknowledgement %q: %v", ack.Acknowledgement, err)
+		return res
+	}
+	if !acknowledgement.Success() {
+		res.Error = acknowledgement.GetError()
+		return res
+	}
+	res.Success = true
+
+	var msgData sdk.TxMsgData
+	if err := proto.Unmarshal(acknowledgement.GetResult(), &msgData); err != nil {
+		res.Error = fmt.Sprintf("undecodable result: %v", err)
+		return res
+	}
+	for _, any := range msgData.MsgResponses {
+		bz, err := cdc.MarshalJSON(any)
+		if err != nil {
+			bz, _ = json.Marshal(map[string]string{"@type": any.TypeUrl})
+		}
+		res.MsgResponses = append(res.MsgResponses, bz)
+	}
+	return res
+}
+
+// icaSubmitResult is the result of tx ica submit with --wait-ack.
+type icaSubmitResult struct {
+	Tx  txResult `json:"tx"`
+	Ack icaAck   `json:"ack"`
+}
+
+var _ fmt.Stringer = icaSubmitResult{}
+
+// String returns the result of the transaction, followed by the acknowledgement and the message responses.
+func (r icaSubmitResult) String() string {
+	var b strings.Builder
+	b.WriteString(r.Tx.String())
+	b.WriteString("\n")
+
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Packet:\t%s\n", r.Ack.Packet)
+	fmt.Fprintf(w, "Acknowledged by:\t%s (height %d)\n", r.Ack.TxHash, r.Ack.Height)
+	if r.Ack.Success {
+		fmt.Fprintf(w, "Result:\tsuccess\n")
+	} else {
+		fmt.Fprintf(w, "Result:\terror: %s\n", r.Ack.Error)
+	}
+	w.Flush()
+	for i, res := range r.Ack.MsgResponses {
+		fmt.Fprintf(&b, "Response %d: %s\n", i+1, res)
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package cmd_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/gogoproto/proto"
+	icacontrollertypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/controller/types"
+	icatypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// testICAAddr is the address of the interchain account of ZeroCosmosAddr in the ICA tests.
+const testICAAddr = "cosmos1qyqszqgpqyqszqgpqyqszqgpqyqszqgpjnp7du"
+
+func TestICAInterchainAccount(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/ibc.applications.interchain_accounts.controller.v1.Query/InterchainAccount", func(data bytes.HexBytes) bool {
+		var req icacontrollertypes.QueryInterchainAccountRequest
+		return req.Unmarshal(data) == nil && req.Owner == ZeroCosmosAddr && req.ConnectionId == "connection-0"
+	}, &icacontrollertypes.QueryInterchainAccountResponse{Address: testICAAddr})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The owner is a key or an address.
+	res := sys.MustRun(t, "query", "ica", "interchain-account", "cosmoshub", "mykey", "connection-0", "-o", "text")
+	require.Equal(t, testICAAddr+"\n", res.Stdout.String())
+	res = sys.MustRun(t, "query", "ica", "account", ZeroCosmosAddr, "connection-0", "-o", "json")
+	require.JSONEq(t, `{"owner":"`+ZeroCosmosAddr+`","connection_id":"connection-0","address":"`+testICAAddr+`"}`, res.Stdout.String())
+}
+
+func TestICARegisterAndSubmit_DryRun(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	res := sys.MustRun(t, "tx", "ica", "register", "mykey", "connection-0", "--dry-run")
+	require.JSONEq(t, `[{
+		"@type": "/ibc.applications.interchain_accounts.controller.v1.MsgRegisterInterchainAccount",
+		"owner": "`+ZeroCosmosAddr+`",
+		"connection_id": "connection-0",
+		"version": ""
+	}]`, res.Stdout.String())
+
+	file := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "`+testICAAddr+`", "to_address": "`+ZeroCosmosAddr+`", "amount": [{"denom": "uatom", "amount": "1"}]}
+	]`), 0o600))
+	var msgs []struct {
+		PacketData struct {
+			Type string
+			Data string
+			Memo string
+		} `json:"packet_data"`
+		RelativeTimeout string `json:"relative_timeout"`
+	}
+	res = sys.MustRun(t, "tx", "ica", "submit", "cosmoshub", "mykey", "connection-0", file, "--dry-run", "--packet-memo", "hi")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "TYPE_EXECUTE_TX", msgs[0].PacketData.Type)
+	require.Equal(t, "hi", msgs[0].PacketData.Memo)
+	require.Equal(t, "600000000000", msgs[0].RelativeTimeout)
+
+	// The messages are encoded with protobuf in the packet.
+	data, err := base64.StdEncoding.DecodeString(msgs[0].PacketData.Data)
+	require.NoError(t, err)
+	var cosmosTx icatypes.CosmosTx
+	require.NoError(t, proto.Unmarshal(data, &cosmosTx))
+	require.Len(t, cosmosTx.Messages, 1)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", cosmosTx.Messages[0].TypeUrl)
+	var send banktypes.MsgSend
+	require.NoError(t, proto.Unmarshal(cosmosTx.Messages[0].Value, &send))
+	require.Equal(t, testICAAddr, send.FromAddress)
+
+	res = sys.MustRun(t, "tx", "ica", "submit", "mykey", "connection-0", file, "--dry-run", "--packet-timeout", "1m")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "60000000000", msgs[0].RelativeTimeout)
+
+	for msgs, msg := range map[string]string{
+		`[]`: "no messages",
+		`[{"@type": "/osmosis.gamm.v1beta1.MsgSwapExactAmountIn"}]`: "message 1: unable to resolve type URL /osmosis.gamm.v1beta1.MsgSwapExactAmountIn",
+	} {
+		res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(msgs), "tx", "ica", "submit", "mykey", "connection-0", "-", "--dry-run")
+		require.ErrorContains(t, res.Err, msg, msgs)
+	}
+	res = sys.Run(zaptest.NewLogger(t), "tx", "ica", "submit", "mykey", "connection-0", file, "--dry-run", "--wait-ack")
+	require.ErrorContains(t, res.Err, "--dry-run cannot be given with --wait-ack")
+}
+
+func TestICASubmit_WaitAck(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	file := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "`+testICAAddr+`", "to_address": "`+ZeroCosmosAddr+`", "amount": [{"denom": "uatom", "amount": "1"}]}
+	]`), 0o600))
+
+	response, err := codectypes.NewAnyWithValue(&banktypes.MsgSendResponse{})
+	require.NoError(t, err)
+	result, err := proto.Marshal(&sdk.TxMsgData{MsgResponses: []*codectypes.Any{response}})
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		name string
+		ack  channeltypes.Acknowledgement
+		err  string
+	}{
+		{name: "success", ack: channeltypes.NewResultAcknowledgement(result)},
+		{name: "error", ack: channeltypes.NewErrorAcknowledgement(banktypes.ErrSendDisabled), err: "the host chain failed to execute the messages of packet icacontroller-" + ZeroCosmosAddr + "/channel-7/3: ABCI code: 5"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hash := bytes.HexBytes{0xab, 0xcd}
+			mc := new(mocks.Client)
+			mockSendLookups(t, mc)
+			resTx := &coretypes.ResultTx{
+				Hash:   hash,
+				Height: 42,
+				TxResult: abci.ResponseDeliverTx{Events: []abci.Event{{
+					Type: channeltypes.EventTypeSendPacket,
+					Attributes: []abci.EventAttribute{
+						{Key: channeltypes.AttributeKeySrcPort, Value: "icacontroller-" + ZeroCosmosAddr},
+						{Key: channeltypes.AttributeKeySrcChannel, Value: "channel-7"},
+						{Key: channeltypes.AttributeKeySequence, Value: "3"},
+						{Key: channeltypes.AttributeKeyTimeoutTimestamp, Value: "4102444800000000000"},
+					},
+				}}},
+			}
+			mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				resTx.Tx = args.Get(1).(tmtypes.Tx)
+			}).Return(&coretypes.ResultBroadcastTx{Hash: hash}, nil)
+			mc.On("Tx", mock.Anything, []byte(hash), false).Return(resTx, nil)
+
+			// The acknowledgement is relayed by a transaction also acknowledging another packet.
+			txConfig := client.MakeCodec(client.ModuleBasics, nil).TxConfig
+			txb := txConfig.NewTxBuilder()
+			require.NoError(t, txb.SetMsgs(
+				&channeltypes.MsgAcknowledgement{Packet: channeltypes.Packet{Sequence: 2, SourcePort: "icacontroller-" + ZeroCosmosAddr, SourceChannel: "channel-7"}},
+				&channeltypes.MsgAcknowledgement{
+					Packet:          channeltypes.Packet{Sequence: 3, SourcePort: "icacontroller-" + ZeroCosmosAddr, SourceChannel: "channel-7"},
+					Acknowledgement: tc.ack.Acknowledgement(),
+				},
+			))
+			relayed, err := txConfig.TxEncoder()(txb.GetTx())
+			require.NoError(t, err)
+			query := "acknowledge_packet.packet_src_port='icacontroller-" + ZeroCosmosAddr + "' AND acknowledge_packet.packet_src_channel='channel-7' AND acknowledge_packet.packet_sequence=3"
+			mc.On("TxSearch", mock.Anything, query, false, mock.Anything, mock.Anything, "").
+				Return(&coretypes.ResultTxSearch{Txs: []*coretypes.ResultTx{{Hash: bytes.HexBytes{0xef}, Height: 50, Tx: relayed}}, TotalCount: 1}, nil)
+			mc.On("TxSearch", mock.Anything, strings.ReplaceAll(query, "acknowledge_packet", "timeout_packet"), false, mock.Anything, mock.Anything, "").
+				Return(&coretypes.ResultTxSearch{}, nil)
+			sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+				RPCClient: mc,
+			})
+
+			res := sys.Run(zaptest.NewLogger(t), "tx", "ica", "submit", "mykey", "connection-0", file, "--wait-ack", "-o", "json")
+			if tc.err != "" {
+				require.ErrorContains(t, res.Err, tc.err)
+			} else {
+				require.NoError(t, res.Err)
+			}
+			var out struct {
+				Tx struct {
+					TxHash string
+				}
+				Ack struct {
+					TxHash       string
+					Height       int64
+					Success      bool
+					Error        string
+					MsgResponses []map[string]interface{} `json:"msg_responses"`
+				}
+			}
+			require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+			require.Equal(t, "ABCD", out.Tx.TxHash)
+			require.Equal(t, "EF", out.Ack.TxHash)
+			require.Equal(t, int64(50), out.Ack.Height)
+			if tc.err != "" {
+				require.False(t, out.Ack.Success)
+				require.Contains(t, out.Ack.Error, "ABCI code: 5")
+				return
+			}
+			require.True(t, out.Ack.Success)
+			require.Equal(t, []map[string]interface{}{{"@type": "/cosmos.bank.v1beta1.MsgSendResponse"}}, out.Ack.MsgResponses)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// jsonDiff is a value that differs between two JSON documents.
+type jsonDiff struct {
+	// Path is the path of the value, its object keys and list indexes separated by dots.
+	Path string
+	// Values are the value in each document, nil in a document without it.
+	Values [2]json.RawMessage
+}
+
+// compareJSON returns the differences between the values a and b at path, decoded by decodeJSONValue, sorted by path.
+// The fields of objects and the elements of lists of the same length are compared one by one,
+// and other values as a whole.
+func compareJSON(path string, a, b interface{}) []jsonDiff {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			var diffs []jsonDiff
+			for _, key := range unionKeys(a, b) {
+				va, inA := a[key]
+				vb, inB := b[key]
+				if !inA || !inB {
+					diffs = append(diffs, jsonDiff{Path: joinJSONPath(path, key), Values: [2]json.RawMessage{encodeJSONValue(va, inA), encodeJSONValue(vb, inB)}})
+					continue
+				}
+				diffs = append(diffs, compareJSON(joinJSONPath(path, key), va, vb)...)
+			}
+			return diffs
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok && len(a) == len(b) {
+			var diffs []jsonDiff
+			for i := range a {
+				diffs = append(diffs, compareJSON(joinJSONPath(path, strconv.Itoa(i)), a[i], b[i])...)
+			}
+			return diffs
+		}
+	}
+
+	ja, jb := encodeJSONValue(a, a != nil), encodeJSONValue(b, b != nil)
+	if bytes.Equal(ja, jb) {
+		return nil
+	}
+	return []jsonDiff{{Path: path, Values: [2]json.RawMessage{ja, jb}}}
+}
+
+// joinJSONPath returns the path of key within the value at path.
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// decodeJSONValue decodes the JSON value j, keeping its numbers as written, or returns nil if there is none.
+func decodeJSONValue(j json.RawMessage) interface{} {
+	if j == nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return string(j)
+	}
+	return v
+}
+
+// encodeJSONValue returns the value v as JSON, with the keys of its objects sorted, or nil if there is none.
+func encodeJSONValue(v interface{}, ok bool) json.RawMessage {
+	if !ok {
+		return nil
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return j
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"golang.org/x/term"
+)
+
+const (
+	keyringBackendFlag        = "keyring-backend"
+	keyringPassphraseFileFlag = "keyring-passphrase-file"
+
+	// keyringPassphraseEnv is the environment variable holding the passphrase of file keyrings.
+	keyringPassphraseEnv = "LENS_KEYRING_PASSPHRASE"
+)
+
+// keyringInput returns the input that keyrings read their passphrase from.
+// The passphrase is prompted for if stdin is a terminal,
+// or else taken from $LENS_KEYRING_PASSPHRASE, or else from --keyring-passphrase-file.
+// Without any of them, it is read from stdin.
+func keyringInput(cmd *cobra.Command, a *appState) (io.Reader, error) {
+	type fder interface {
+		Fd() uintptr
+	}
+	if f, ok := cmd.InOrStdin().(fder); ok && term.IsTerminal(int(f.Fd())) {
+		return cmd.InOrStdin(), nil
+	}
+
+	if passphrase, ok := os.LookupEnv(keyringPassphraseEnv); ok {
+		return &passphraseReader{line: []byte(passphrase + "\n")}, nil
+	}
+	if a.KeyringPassphraseFile != "" {
+		bz, err := os.ReadFile(a.KeyringPassphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring passphrase: %w", err)
+		}
+		return &passphraseReader{line: append(bytes.TrimRight(bz, "\r\n"), '\n')}, nil
+	}
+	return cmd.InOrStdin(), nil
+}
+
+// passphraseReader endlessly repeats a passphrase line,
+// to answer every passphrase prompt of a keyring, including the confirmation of a new passphrase.
+type passphraseReader struct {
+	line []byte
+	off  int
+}
+
+func (r *passphraseReader) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		c := copy(p[n:], r.line[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.line)
+	}
+	return n, nil
+}
+
+// keyringHint returns a hint about the keyring backends, other than the one of cl,
+// that hold keys of the chain, or the empty string if there are none.
+func keyringHint(cl *client.ChainClient, chainName string) string {
+	backends := cl.OtherKeyringBackends()
+	if len(backends) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"chain %s has keys in its %s keyring, but uses the %s keyring backend: "+
+			"select it with --%s %s or `%s chains edit %s keyring-backend %s`, "+
+			"or move the keys with keys export and keys import",
+		chainName, strings.Join(backends, " and "), cl.Config.KeyringBackend,
+		keyringBackendFlag, backends[0], appName, chainName, backends[0],
+	)
+}
+
+// errKeyNotFound returns errKeyDoesntExist(name), with a hint about other keyring backends of the chain of cl.
+func errKeyNotFound(cl *client.ChainClient, chainName, name string) error {
+	if hint := keyringHint(cl, chainName); hint != "" {
+		return fmt.Errorf("%w; %s", errKeyDoesntExist(name), hint)
+	}
+	return errKeyDoesntExist(name)
+}
+
+// errKeyToSignNotFound returns the error for the key of cl, needed to sign a transaction, not being found.
+func errKeyToSignNotFound(cl *client.ChainClient, chainName string) error {
+	err := fmt.Errorf("key %q not found on chain %s: a key is needed to sign the transaction", cl.Config.Key, chainName)
+	if hint := keyringHint(cl, chainName); hint != "" {
+		return fmt.Errorf("%w; %s", err, hint)
+	}
+	return err
+}
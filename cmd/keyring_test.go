@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestKeyringBackend_File(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	passFile := filepath.Join(t.TempDir(), "pass.txt")
+	require.NoError(t, os.WriteFile(passFile, []byte("correct horse\n"), 0o600))
+	fileArgs := []string{"--keyring-backend", "file", "--keyring-passphrase-file", passFile}
+
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), append([]string{"keys", "restore", "mykey"}, fileArgs...)...)
+	res := sys.MustRun(t, append([]string{"keys", "list"}, fileArgs...)...)
+	require.Equal(t, "key(mykey) -> "+ZeroCosmosAddr+"\n", res.Stdout.String())
+
+	// The keys are not in the chain's test keyring, which hints at the file keyring.
+	res = sys.MustRun(t, "keys", "list")
+	require.Empty(t, res.Stdout.String())
+	require.Contains(t, res.Stderr.String(), "chain cosmoshub has keys in its file keyring, but uses the test keyring backend")
+	require.Contains(t, res.Stderr.String(), "--keyring-backend file")
+	res = sys.Run(zaptest.NewLogger(t), "keys", "show", "mykey")
+	require.ErrorContains(t, res.Err, "a key with name mykey doesn't exist; chain cosmoshub has keys in its file keyring")
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom")
+	require.ErrorContains(t, res.Err, `key "mykey" not found on chain cosmoshub: a key is needed to sign the transaction; chain cosmoshub has keys in its file keyring`)
+
+	// Once selected in the configuration, the file keyring is used without the flag.
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "keyring-backend", "file")
+	res = sys.MustRun(t, "keys", "show", "mykey", "--keyring-passphrase-file", passFile)
+	require.Equal(t, ZeroCosmosAddr+"\n", res.Stdout.String())
+
+	// A wrong passphrase does not open the keyring.
+	require.NoError(t, os.WriteFile(passFile, []byte("wrong\n"), 0o600))
+	res = sys.Run(zaptest.NewLogger(t), "keys", "show", "mykey", "--keyring-passphrase-file", passFile)
+	require.Error(t, res.Err)
+}
+
+func TestKeyringBackend_PassphraseEnv(t *testing.T) {
+	// Not parallel, since the passphrase is in the environment.
+	sys := NewSystem(t)
+	passFile := filepath.Join(t.TempDir(), "pass.txt")
+	require.NoError(t, os.WriteFile(passFile, []byte("wrong\n"), 0o600))
+
+	t.Setenv("LENS_KEYRING_PASSPHRASE", "correct horse")
+	sys.MustRun(t, "keys", "add", "mykey", "--keyring-backend", "file")
+	// The environment takes precedence over the passphrase file.
+	res := sys.MustRun(t, "keys", "list", "--keyring-backend", "file", "--keyring-passphrase-file", passFile)
+	require.Contains(t, res.Stdout.String(), "key(mykey) -> cosmos1")
+}
+
+func TestKeyringBackend_Invalid(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	res := sys.Run(zaptest.NewLogger(t), "keys", "list", "--keyring-backend", "vault")
+	require.ErrorContains(t, res.Err, `unknown keyring backend "vault" (must be one of os, file, kwallet, pass, test, memory)`)
+
+	res = sys.Run(zaptest.NewLogger(t), "chains", "edit", "cosmoshub", "keyring-backend", "vault")
+	require.ErrorContains(t, res.Err, `unknown keyring backend "vault"`)
+}
@@ -1,22 +1,44 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/codecs/ethermint"
+	"github.com/strangelove-ventures/lens/client/query"
 	"go.uber.org/zap"
 	"golang.org/x/term"
 )
 
 const (
-	flagCoinType           = "coin-type"
-	defaultCoinType uint32 = sdk.CoinType
+	flagCoinType              = "coin-type"
+	flagAccount               = "account"
+	flagIndex                 = "index"
+	flagRecover               = "recover"
+	flagNoBackup              = "no-backup"
+	flagThreshold             = "threshold"
+	flagPassphraseFile        = "passphrase-file"
+	flagUnarmoredHex          = "unarmored-hex"
+	flagUnsafe                = "unsafe"
+	flagKeyType               = "key-type"
+	flagForce                 = "force"
+	flagKeys                  = "keys"
+	defaultCoinType    uint32 = sdk.CoinType
 )
 
 // keysCmd represents the keys command
@@ -33,12 +55,15 @@ func keysCmd(a *appState) *cobra.Command {
 
 	cmd.AddCommand(
 		keysAddCmd(a),
+		keysAddMultisigCmd(a),
 		keysRestoreCmd(a),
 		keysDeleteCmd(a),
 		keysListCmd(a),
 		keysShowCmd(a, &flagAccountPrefix),
 		keysEnumerateCmd(a),
 		keysExportCmd(a),
+		keysImportCmd(a),
+		keysConvertCmd(a),
 	)
 
 	return cmd
@@ -50,33 +75,81 @@ func keysAddCmd(a *appState) *cobra.Command {
 		Use:     "add [name]",
 		Aliases: []string{"a"},
 		Short:   "adds a key to the keychain associated with a particular chain",
-		Long:    "if no name is passed, 'default' is used",
-		Args:    cobra.RangeArgs(0, 1),
+		Long: `Add a key, derived from a new mnemonic, or with --recover from a mnemonic read from standard input,
+and print its name, address and public key as JSON. If no name is passed, 'default' is used.
+
+The key is derived along the HD path m/44'/<coin-type>'/<account>'/0/<index>,
+where the coin type defaults to the slip44 of the chain, or 118 if it is not set.
+Keys of coin type 60 are Ethereum keys, as used by Ethermint chains.
+
+The mnemonic of a new key is printed once, and is the only way to recover the key:
+write it down in a safe place, or pass --no-backup to not print it.
+With --dry-run, the key is derived and printed, but not stored.`,
+		Args: withUsage(cobra.RangeArgs(0, 1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
 $ %s keys add
 $ %s keys add test_key
-$ %s k a osmo_key --chain osmosis`, appName, appName, appName)),
+$ %s keys add test_key --recover --account 1 --index 2
+$ %s k a osmo_key --chain osmosis`, appName, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			var keyName string
 			if len(args) == 0 {
 				keyName = cl.Config.Key
 			} else {
 				keyName = args[0]
 			}
-			if cl.KeyExists(keyName) {
+
+			var opts client.KeyOptions
+			if opts.DryRun, err = cmd.Flags().GetBool(dryRunFlag); err != nil {
+				return err
+			}
+			if !opts.DryRun && cl.KeyExists(keyName) {
 				return errKeyExists(keyName)
 			}
-
-			coinType, err := cmd.Flags().GetUint32(flagCoinType)
+			if opts.CoinType, err = coinTypeFromFlags(cmd, cl); err != nil {
+				return err
+			}
+			if opts.Account, err = cmd.Flags().GetUint32(flagAccount); err != nil {
+				return err
+			}
+			if opts.Index, err = cmd.Flags().GetUint32(flagIndex); err != nil {
+				return err
+			}
+			recoverKey, err := cmd.Flags().GetBool(flagRecover)
 			if err != nil {
 				return err
 			}
+			noBackup, err := cmd.Flags().GetBool(flagNoBackup)
+			if err != nil {
+				return err
+			}
+
+			if recoverKey {
+				mnemonic, err := readMnemonic(cmd.InOrStdin(), cmd.ErrOrStderr())
+				if err != nil {
+					// Can happen when there is an issue with the terminal.
+					return fmt.Errorf("failed to read mnemonic: %w", err)
+				}
+				if len(bytes.TrimSpace(mnemonic)) == 0 {
+					return fmt.Errorf("no mnemonic was given to recover the key from")
+				}
+				opts.Mnemonic = string(mnemonic)
+			}
 
-			ko, err := cl.AddKey(keyName, coinType)
+			ko, err := cl.AddKeyWithOptions(keyName, opts)
 			if err != nil {
 				return err
 			}
+			if recoverKey || noBackup {
+				// The mnemonic is only printed for a new one, that cannot be recovered otherwise.
+				ko.Mnemonic = ""
+			} else {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Important: write this mnemonic phrase down in a safe place. It is the only way to recover the key.")
+			}
 
 			// Not calling writeJSON because this is one case that does not use indentation.
 			// (Was that intentional?)
@@ -89,7 +162,77 @@ $ %s k a osmo_key --chain osmosis`, appName, appName, appName)),
 			return nil
 		},
 	}
-	cmd.Flags().Uint32(flagCoinType, defaultCoinType, "coin type number for HD derivation")
+	cmd.Flags().Uint32(flagCoinType, 0, "coin type number for HD derivation (default: the slip44 of the chain, or 118)")
+	cmd.Flags().Uint32(flagAccount, 0, "account number for HD derivation")
+	cmd.Flags().Uint32(flagIndex, 0, "address index number for HD derivation")
+	cmd.Flags().Bool(flagRecover, false, "recover the key from a mnemonic read from standard input, instead of creating a new one")
+	cmd.Flags().Bool(flagNoBackup, false, "do not print the mnemonic of a new key")
+	cmd.Flags().Bool(dryRunFlag, false, "derive and print the key, without storing it")
+	return cmd
+}
+
+// coinTypeFromFlags returns the coin type given by --coin-type,
+// or if it is not given, the slip44 of the chain of cl, or 118 if it is not set.
+func coinTypeFromFlags(cmd *cobra.Command, cl *client.ChainClient) (uint32, error) {
+	if cmd.Flags().Changed(flagCoinType) {
+		return cmd.Flags().GetUint32(flagCoinType)
+	}
+	if cl.Config.Slip44 > 0 {
+		return uint32(cl.Config.Slip44), nil
+	}
+	return defaultCoinType, nil
+}
+
+// keysAddMultisigCmd represents the `keys add-multisig` command
+func keysAddMultisigCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-multisig <name>",
+		Short: "adds a multisig key, of keys in the keychain, to the keychain associated with a particular chain",
+		Long: `Add a multisig key, whose account needs signatures by --threshold of the --keys to sign a transaction,
+and print its address. Only the public keys of the keys are stored, sorted by address,
+so that the address does not depend on the order they are given in.
+
+Transactions of the multisig account are signed by each key with tx sign --multisig-of,
+and the signatures combined with tx multisign.`,
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys add-multisig team --threshold 2 --keys alice,bob,carol
+$ %s keys add-multisig team --threshold 2 --keys alice,bob,carol --chain osmosis`, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
+			keyName := args[0]
+			if cl.KeyExists(keyName) {
+				return errKeyExists(keyName)
+			}
+
+			threshold, err := cmd.Flags().GetInt(flagThreshold)
+			if err != nil {
+				return err
+			}
+			keys, err := cmd.Flags().GetStringSlice(flagKeys)
+			if err != nil {
+				return err
+			}
+
+			address, err := cl.AddMultisigKey(keyName, threshold, keys)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), address)
+			return nil
+		},
+	}
+	cmd.Flags().Int(flagThreshold, 0, "the number of the keys that must sign a transaction of the multisig account")
+	cmd.Flags().StringSlice(flagKeys, nil, "the comma separated names of the keys of the multisig key")
+	for _, flag := range []string{flagThreshold, flagKeys} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			panic(err)
+		}
+	}
 	return cmd
 }
 
@@ -104,7 +247,10 @@ func keysRestoreCmd(a *appState) *cobra.Command {
 $ %s keys restore --chain ibc-0 testkey
 $ %s k r --chain ibc-1 faucet-key`, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			keyName := args[0]
 			if cl.KeyExists(keyName) {
 				return errKeyExists(keyName)
@@ -116,7 +262,7 @@ $ %s k r --chain ibc-1 faucet-key`, appName, appName)),
 				return fmt.Errorf("failed to read mnemonic: %w", err)
 			}
 
-			coinType, err := cmd.Flags().GetUint32(flagCoinType)
+			coinType, err := coinTypeFromFlags(cmd, cl)
 			if err != nil {
 				return err
 			}
@@ -130,7 +276,7 @@ $ %s k r --chain ibc-1 faucet-key`, appName, appName)),
 			return nil
 		},
 	}
-	cmd.Flags().Uint32(flagCoinType, defaultCoinType, "coin type number for HD derivation")
+	cmd.Flags().Uint32(flagCoinType, 0, "coin type number for HD derivation (default: the slip44 of the chain, or 118)")
 	return cmd
 }
 
@@ -168,11 +314,14 @@ $ %s keys delete ibc-0 -y
 $ %s keys delete ibc-1 key2 -y
 $ %s k d ibc-2 testkey`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			chainName := cl.Config.ChainID
 			keyName := args[0]
 			if !cl.KeyExists(keyName) {
-				return errKeyDoesntExist(keyName)
+				return errKeyNotFound(cl, a.Config.DefaultChain, keyName)
 			}
 
 			if skip, _ := cmd.Flags().GetBool("skip"); !skip {
@@ -213,39 +362,234 @@ func askForConfirmation(log *zap.Logger, cmd *cobra.Command) bool {
 	}
 }
 
+// keysListWorkers is the most balances queried concurrently by keys list --with-balance.
+const keysListWorkers = 8
+
 // keysListCmd respresents the `keys list` command
 func keysListCmd(a *appState) *cobra.Command {
+	const (
+		allChainsFlag   = "all-chains"
+		withBalanceFlag = "with-balance"
+		timeoutFlag     = "timeout"
+	)
+
 	cmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"l"},
 		Short:   "lists keys from the keychain associated with a particular chain",
-		Args:    cobra.NoArgs,
+		Long: `List the keys of the keychain of the default chain, or with --all-chains,
+of every configured chain, as a table of chain, key name and address.
+The output of --all-chains in JSON or YAML nests the keys under the chain names.
+
+With --with-balance, the spendable balance of every key is queried, a few chains at a time.
+A chain that does not answer within --timeout has its balances shown as n/a,
+instead of failing the whole listing.`,
+		Args: cobra.NoArgs,
 		Example: strings.TrimSpace(fmt.Sprintf(`
-$ %s keys list ibc-0
-$ %s k l ibc-1`, appName, appName)),
+$ %s keys list --chain ibc-0
+$ %s k l --all-chains
+$ %s keys list --all-chains --with-balance --timeout 3s -o json`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			info, err := cl.ListAddresses()
+			allChains, err := cmd.Flags().GetBool(allChainsFlag)
+			if err != nil {
+				return err
+			}
+			withBalance, err := cmd.Flags().GetBool(withBalanceFlag)
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration(timeoutFlag)
 			if err != nil {
 				return err
 			}
 
-			if len(info) == 0 {
-				fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: no keys found")
+			if !allChains && !withBalance {
+				cl, err := defaultChainClient(a)
+				if err != nil {
+					return err
+				}
+				info, err := cl.ListAddresses()
+				if err != nil {
+					return err
+				}
+
+				if len(info) == 0 {
+					fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: no keys found")
+					if hint := keyringHint(cl, a.Config.DefaultChain); hint != "" {
+						fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: "+hint)
+					}
+					return nil
+				}
+
+				for key, val := range info {
+					fmt.Fprintf(cmd.OutOrStdout(), "key(%s) -> %s\n", key, val)
+				}
+
 				return nil
 			}
 
-			for key, val := range info {
-				fmt.Fprintf(cmd.OutOrStdout(), "key(%s) -> %s\n", key, val)
+			names := []string{a.Config.DefaultChain}
+			if allChains {
+				names = names[:0]
+				for name := range a.Config.Chains {
+					names = append(names, name)
+				}
+				sort.Strings(names)
 			}
 
-			return nil
+			res := make(chainKeys, len(names))
+			var keys []*chainKey
+			for _, name := range names {
+				cl := a.Config.GetClient(name)
+				if cl == nil {
+					a.Log.Warn("Skipping misconfigured chain", zap.String("chain_name", name))
+					continue
+				}
+				addresses, err := cl.ListAddresses()
+				if err != nil {
+					return fmt.Errorf("failed to list keys of chain %s: %w", name, err)
+				}
+				if len(addresses) == 0 {
+					if hint := keyringHint(cl, name); hint != "" {
+						fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: "+hint)
+					}
+				}
+				res[name] = make([]*chainKey, 0, len(addresses))
+				for key, address := range addresses {
+					k := &chainKey{chain: name, Name: key, Address: address}
+					res[name] = append(res[name], k)
+					keys = append(keys, k)
+				}
+				sort.Slice(res[name], func(i, j int) bool { return res[name][i].Name < res[name][j].Name })
+			}
+			if len(keys) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: no keys found")
+			}
+
+			if withBalance {
+				queryBalances(cmd.Context(), a, keys, timeout)
+			}
+			return writeOutput(cmd, a, res)
 		},
 	}
-
+	cmd.Flags().Bool(allChainsFlag, false, "list the keys of every configured chain")
+	cmd.Flags().Bool(withBalanceFlag, false, "query the spendable balance of every key")
+	cmd.Flags().Duration(timeoutFlag, 5*time.Second, "how long to wait for the balance queries of each chain")
 	return cmd
 }
 
+// chainKey is a key listed by keys list --all-chains.
+type chainKey struct {
+	chain   string
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// Balance is only set by --with-balance.
+	Balance *sdk.Coins `json:"balance,omitempty"`
+	// BalanceError is set if the balance could not be queried.
+	BalanceError string `json:"balance_error,omitempty"`
+}
+
+// chainKeys are the keys of each chain, listed by keys list --all-chains.
+type chainKeys map[string][]*chainKey
+
+var _ fmt.Stringer = chainKeys(nil)
+
+// String returns the keys of every chain as a table with aligned columns.
+func (r chainKeys) String() string {
+	names := make([]string, 0, len(r))
+	withBalances := false
+	for name, keys := range r {
+		names = append(names, name)
+		for _, k := range keys {
+			withBalances = withBalances || k.Balance != nil || k.BalanceError != ""
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	if withBalances {
+		fmt.Fprintln(w, "CHAIN\tKEY\tADDRESS\tBALANCE")
+	} else {
+		fmt.Fprintln(w, "CHAIN\tKEY\tADDRESS")
+	}
+	for _, name := range names {
+		for _, k := range r[name] {
+			if !withBalances {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, k.Name, k.Address)
+				continue
+			}
+			balance := "n/a"
+			if k.Balance != nil {
+				balance = orDash(k.Balance.String())
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, k.Name, k.Address, balance)
+		}
+	}
+	w.Flush()
+	return b.String()
+}
+
+// queryBalances queries the spendable balance of every key, the keys of at most keysListWorkers chains at a time,
+// waiting at most timeout for the queries of each chain.
+// The balances of the keys of a chain whose queries fail are left unset, with the error recorded instead.
+func queryBalances(ctx context.Context, a *appState, keys []*chainKey, timeout time.Duration) {
+	byChain := make(map[string][]*chainKey)
+	var names []string
+	for _, k := range keys {
+		if _, ok := byChain[k.chain]; !ok {
+			names = append(names, k.chain)
+		}
+		byChain[k.chain] = append(byChain[k.chain], k)
+	}
+
+	chains := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < keysListWorkers && i < len(names); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range chains {
+				queryChainBalances(ctx, a, name, byChain[name], timeout)
+			}
+		}()
+	}
+
+	for _, name := range names {
+		chains <- name
+	}
+	close(chains)
+	wg.Wait()
+}
+
+// queryChainBalances queries the spendable balance of each of keys, all of the named chain,
+// until the first failure, or until timeout has passed.
+func queryChainBalances(ctx context.Context, a *appState, name string, keys []*chainKey, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cl := a.Config.GetClient(name)
+	q := query.Query{Ctx: ctx, Client: cl, Options: query.DefaultOptions()}
+
+	var err error
+	for _, k := range keys {
+		if err == nil {
+			var balance sdk.Coins
+			if balance, err = q.Bank_SpendableBalances(k.Address); err == nil {
+				k.Balance = &balance
+			} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s: %w", timeout, err)
+			}
+		}
+		if err != nil {
+			k.Balance = nil
+			k.BalanceError = err.Error()
+		}
+	}
+	if err != nil {
+		a.Log.Debug("Failed to query balances", zap.String("chain_name", name), zap.Error(err))
+	}
+}
+
 // keysShowCmd respresents the `keys show` command
 func keysShowCmd(a *appState, flagAccountPrefix *string) *cobra.Command {
 	cmd := &cobra.Command{
@@ -259,7 +603,10 @@ $ %s keys show ibc-0
 $ %s keys show ibc-1 key2
 $ %s k s ibc-2 testkey`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			var keyName string
 			if len(args) == 0 {
 				keyName = cl.Config.Key
@@ -267,7 +614,7 @@ $ %s k s ibc-2 testkey`, appName, appName, appName)),
 				keyName = args[0]
 			}
 			if !cl.KeyExists(keyName) {
-				return errKeyDoesntExist(keyName)
+				return errKeyNotFound(cl, a.Config.DefaultChain, keyName)
 			}
 
 			if *flagAccountPrefix != "" {
@@ -307,7 +654,10 @@ $ %s keys enumerate
 $ %s keys enumerate key2
 $ %s k e key2`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			var keyName string
 			if len(args) == 0 {
 				keyName = cl.Config.Key
@@ -335,7 +685,7 @@ $ %s k e key2`, appName, appName, appName)),
 				addresses[chain] = address
 			}
 
-			return cl.PrintObject(addresses)
+			return writeOutput(cmd, a, addresses)
 		},
 	}
 
@@ -350,30 +700,206 @@ func keysExportCmd(a *appState) *cobra.Command {
 		Use:     "export [name]",
 		Aliases: []string{"e"},
 		Short:   "exports a privkey from the keychain associated with a particular chain",
-		Args:    cobra.ExactArgs(1),
+		Long: `Export the private key of a key, ASCII armored and encrypted with a passphrase,
+read from --passphrase-file, or else prompted for.
+
+With --unarmored-hex --unsafe, the private key is exported unencrypted, hex encoded,
+after confirming it on standard input. Anyone who sees it controls the key's account.`,
+		Args: withUsage(cobra.ExactArgs(1)),
 		Example: strings.TrimSpace(fmt.Sprintf(`
-$ %s keys export ibc-0 testkey
-$ %s k e ibc-2 testkey`, appName, appName)),
+$ %s keys export testkey > testkey.armor
+$ %s keys export testkey --passphrase-file pass.txt --chain osmosis
+$ %s k e testkey --unarmored-hex --unsafe`, appName, appName, appName)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			keyName := args[0]
 			if !cl.KeyExists(keyName) {
-				return errKeyDoesntExist(keyName)
+				return errKeyNotFound(cl, a.Config.DefaultChain, keyName)
 			}
 
-			info, err := cl.ExportPrivKeyArmor(keyName)
+			unarmoredHex, err := cmd.Flags().GetBool(flagUnarmoredHex)
+			if err != nil {
+				return err
+			}
+			allowUnsafe, err := cmd.Flags().GetBool(flagUnsafe)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println(info)
+			if unarmoredHex {
+				if !allowUnsafe {
+					return fmt.Errorf("--%s exports the private key unencrypted, and requires --%s", flagUnarmoredHex, flagUnsafe)
+				}
+				ok, err := confirm(cmd, fmt.Sprintf("Export the unencrypted private key of key %s? Anyone who sees it controls its account. (y/N)", keyName))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("export of key %s was not confirmed", keyName)
+				}
+				hexKey, err := cl.ExportPrivKeyHex(keyName)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), hexKey)
+				return nil
+			}
+
+			passphrase, err := readPassphrase(cmd, "Enter passphrase to encrypt the exported key: ")
+			if err != nil {
+				return err
+			}
+			if passphrase == "" {
+				return fmt.Errorf("the passphrase to encrypt the exported key must not be empty")
+			}
+			armor, err := cl.ExportPrivKeyArmorWithPassphrase(keyName, passphrase)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), armor)
 			return nil
 		},
 	}
+	cmd.Flags().String(flagPassphraseFile, "", "file holding the passphrase, instead of prompting for it")
+	cmd.Flags().Bool(flagUnarmoredHex, false, "export the private key unencrypted and hex encoded; requires --unsafe")
+	cmd.Flags().Bool(flagUnsafe, false, "confirm that the private key may be exported unencrypted")
+	return cmd
+}
 
+// keysImportCmd respresents the `keys import` command
+func keysImportCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <name> <file>",
+		Short: "imports a privkey to the keychain associated with a particular chain",
+		Long: `Import a private key, as written by keys export, and print its address.
+The file holds either an ASCII armored key, encrypted with a passphrase read from --passphrase-file,
+or else prompted for, or an unencrypted hex encoded key, of the algorithm given by --key-type.
+A file of "-" is read from standard input.
+
+A key whose address is already that of another key is not imported, unless --force is set.`,
+		Args: withUsage(cobra.ExactArgs(2)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys import testkey testkey.armor
+$ %s keys import testkey testkey.armor --passphrase-file pass.txt --chain osmosis
+$ %s keys import ethkey ethkey.hex --key-type eth_secp256k1`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
+			keyName := args[0]
+			if cl.KeyExists(keyName) {
+				return errKeyExists(keyName)
+			}
+
+			force, err := cmd.Flags().GetBool(flagForce)
+			if err != nil {
+				return err
+			}
+			keyType, err := cmd.Flags().GetString(flagKeyType)
+			if err != nil {
+				return err
+			}
+			if keyType == "" {
+				keyType = string(hd.Secp256k1Type)
+				if cl.Config.Slip44 == 60 {
+					keyType = string(ethermint.EthSecp256k1Type)
+				}
+			}
+
+			bz, err := readFileOrStdin(cmd, args[1])
+			if err != nil {
+				return err
+			}
+			var address string
+			if key := strings.TrimSpace(string(bz)); strings.HasPrefix(key, "-----BEGIN") {
+				passphrase, err := readPassphrase(cmd, "Enter passphrase to decrypt the key: ")
+				if err != nil {
+					return err
+				}
+				address, err = cl.ImportPrivKeyArmor(keyName, key, passphrase, force)
+				if err != nil {
+					return keyImportError(err)
+				}
+			} else {
+				address, err = cl.ImportPrivKeyHex(keyName, key, keyType, force)
+				if err != nil {
+					return keyImportError(err)
+				}
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), address)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagPassphraseFile, "", "file holding the passphrase of an armored key, instead of prompting for it")
+	cmd.Flags().String(flagKeyType, "", "the algorithm of a hex encoded key, secp256k1 or eth_secp256k1 (default: eth_secp256k1 if the slip44 of the chain is 60)")
+	cmd.Flags().Bool(flagForce, false, "import the key even if its address is already that of another key")
 	return cmd
 }
 
+// keyImportError adds a hint about --force to err, if it is a client.KeyAddressExistsError.
+func keyImportError(err error) error {
+	var exists client.KeyAddressExistsError
+	if errors.As(err, &exists) {
+		return fmt.Errorf("%w (use --%s to import it anyway)", err, flagForce)
+	}
+	return err
+}
+
+// readPassphrase returns the content of the file given by --passphrase-file, without its trailing newline.
+// Otherwise it prompts for a passphrase, read without echo if stdin is a terminal,
+// or else as a line of stdin.
+func readPassphrase(cmd *cobra.Command, prompt string) (string, error) {
+	file, err := cmd.Flags().GetString(flagPassphraseFile)
+	if err != nil {
+		return "", err
+	}
+	if file != "" {
+		bz, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return strings.TrimRight(string(bz), "\r\n"), nil
+	}
+
+	type fder interface {
+		Fd() uintptr
+	}
+	if f, ok := cmd.InOrStdin().(fder); ok {
+		fmt.Fprint(cmd.ErrOrStderr(), prompt)
+		passphrase, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return string(passphrase), nil
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// confirm writes question to stderr, and reports whether the answer read from stdin is yes.
+func confirm(cmd *cobra.Command, question string) (bool, error) {
+	fmt.Fprintln(cmd.ErrOrStderr(), question)
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 func errKeyExists(name string) error {
 	return fmt.Errorf("a key with name %s already exists", name)
 }
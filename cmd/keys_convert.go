@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cosmos/btcutil/bech32"
+	sdkbech32 "github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagToPrefix = "to-prefix"
+	flagHex      = "hex"
+)
+
+// convertedAddress is an address encoded with the account prefix of the named chains.
+type convertedAddress struct {
+	Chains  []string `json:"chains"`
+	Prefix  string   `json:"prefix"`
+	Address string   `json:"address"`
+}
+
+// convertResult is the result of keys convert without --to-prefix.
+type convertResult struct {
+	Hex       string             `json:"hex"`
+	Addresses []convertedAddress `json:"addresses"`
+}
+
+var _ fmt.Stringer = convertResult{}
+
+// String returns the addresses as a table with aligned columns, after the hex form.
+func (r convertResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAINS\tADDRESS")
+	fmt.Fprintf(w, "%s\t%s\n", "(hex)", r.Hex)
+	for _, addr := range r.Addresses {
+		fmt.Fprintf(w, "%s\t%s\n", strings.Join(addr.Chains, ","), addr.Address)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// keysConvertCmd represents the `keys convert` command
+func keysConvertCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "convert <address>",
+		Aliases: []string{"c"},
+		Short:   "converts an account address to the account prefixes of other chains",
+		Long: `Convert a bech32 account address, with the account prefix of a configured chain,
+to the given --to-prefix, or else to the account prefix of every configured chain,
+each labeled by the chains with that prefix, along with its hex form.
+
+With --hex, the address is given in hex, as used by Ethermint chains, with or without a 0x prefix.
+A mixed case hex address must have a valid EIP-55 checksum.`,
+		Args: withUsage(cobra.ExactArgs(1)),
+		Example: strings.TrimSpace(fmt.Sprintf(`
+$ %s keys convert cosmos1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu
+$ %s keys convert cosmos1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu --to-prefix osmo
+$ %s keys convert 0x0102030405060708090A0B0c0d0e0f1011121314 --hex`, appName, appName, appName)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isHex, err := cmd.Flags().GetBool(flagHex)
+			if err != nil {
+				return err
+			}
+			toPrefix, err := cmd.Flags().GetString(flagToPrefix)
+			if err != nil {
+				return err
+			}
+
+			// The chains with each account prefix.
+			chains := make(map[string][]string)
+			for name, chain := range a.Config.Chains {
+				chains[chain.AccountPrefix] = append(chains[chain.AccountPrefix], name)
+			}
+			prefixes := make([]string, 0, len(chains))
+			for prefix := range chains {
+				prefixes = append(prefixes, prefix)
+				sort.Strings(chains[prefix])
+			}
+			sort.Strings(prefixes)
+
+			var bz []byte
+			if isHex {
+				bz, err = decodeHexAddress(args[0])
+			} else {
+				bz, err = decodeAccAddress(args[0], prefixes)
+			}
+			if err != nil {
+				return err
+			}
+
+			if toPrefix != "" {
+				address, err := sdkbech32.ConvertAndEncode(toPrefix, bz)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), address)
+				return nil
+			}
+
+			res := convertResult{Hex: encodeHexAddress(bz)}
+			for _, prefix := range prefixes {
+				address, err := sdkbech32.ConvertAndEncode(prefix, bz)
+				if err != nil {
+					return err
+				}
+				res.Addresses = append(res.Addresses, convertedAddress{
+					Chains:  chains[prefix],
+					Prefix:  prefix,
+					Address: address,
+				})
+			}
+			return writeOutput(cmd, a, res)
+		},
+	}
+	cmd.Flags().String(flagToPrefix, "", "the account prefix to convert the address to, instead of those of every configured chain")
+	cmd.Flags().Bool(flagHex, false, "the address is given in hex, instead of bech32")
+	return cmd
+}
+
+// decodeAccAddress decodes the bech32 account address s, whose prefix must be one of prefixes.
+func decodeAccAddress(s string, prefixes []string) ([]byte, error) {
+	hrp, bz, err := sdkbech32.DecodeAndConvert(s)
+	if err != nil {
+		var checksumErr bech32.ErrInvalidChecksum
+		if errors.As(err, &checksumErr) {
+			return nil, fmt.Errorf("invalid address %s: bad bech32 checksum, so a character is mistyped", s)
+		}
+		return nil, fmt.Errorf("invalid bech32 address %s: %w", s, err)
+	}
+	for _, prefix := range prefixes {
+		if hrp == prefix {
+			return bz, nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"invalid address %s: wrong prefix %q, which is not the account prefix of a configured chain (one of %s)",
+		s, hrp, strings.Join(prefixes, ", "),
+	)
+}
+
+// decodeHexAddress decodes the hex address s, with or without a 0x prefix.
+// A 20 byte address in mixed case must have a valid EIP-55 checksum.
+func decodeHexAddress(s string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	bz, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex address %s: %w", s, err)
+	}
+	if len(bz) != common.AddressLength && len(bz) != 32 {
+		return nil, fmt.Errorf("invalid hex address %s: it has %d bytes, but must have 20 or 32", s, len(bz))
+	}
+	mixedCase := strings.ToLower(digits) != digits && strings.ToUpper(digits) != digits
+	if mixedCase && len(bz) == common.AddressLength && common.BytesToAddress(bz).Hex() != "0x"+digits {
+		return nil, fmt.Errorf("invalid hex address %s: bad EIP-55 checksum, so a character is mistyped", s)
+	}
+	return bz, nil
+}
+
+// encodeHexAddress encodes the address bz in hex, with an EIP-55 checksum if it has 20 bytes.
+func encodeHexAddress(bz []byte) string {
+	if len(bz) == common.AddressLength {
+		return common.BytesToAddress(bz).Hex()
+	}
+	return "0x" + hex.EncodeToString(bz)
+}
@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestKeysConvert(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	addr, err := sdk.GetFromBech32(ZeroCosmosAddr, "cosmos")
+	require.NoError(t, err)
+	osmoAddr, err := sdk.Bech32ifyAddressBytes("osmo", addr)
+	require.NoError(t, err)
+	hexAddr := common.BytesToAddress(addr).Hex()
+
+	res := sys.MustRun(t, "keys", "convert", ZeroCosmosAddr, "--to-prefix", "osmo")
+	require.Equal(t, osmoAddr+"\n", res.Stdout.String())
+
+	// Without --to-prefix, the address is converted to every account prefix of the config.
+	res = sys.MustRun(t, "keys", "convert", ZeroCosmosAddr, "-o", "text")
+	require.Contains(t, res.Stdout.String(), hexAddr)
+	require.Regexp(t, `cosmoshub\s+`+ZeroCosmosAddr, res.Stdout.String())
+	require.Regexp(t, `osmosis\s+`+osmoAddr, res.Stdout.String())
+
+	var out struct {
+		Hex       string
+		Addresses []struct {
+			Chains  []string
+			Prefix  string
+			Address string
+		}
+	}
+	res = sys.MustRun(t, "keys", "convert", strings.ToLower(hexAddr), "--hex", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Equal(t, hexAddr, out.Hex)
+	require.Len(t, out.Addresses, 2)
+	require.Equal(t, []string{"cosmoshub"}, out.Addresses[0].Chains)
+	require.Equal(t, ZeroCosmosAddr, out.Addresses[0].Address)
+	require.Equal(t, "osmo", out.Addresses[1].Prefix)
+	require.Equal(t, osmoAddr, out.Addresses[1].Address)
+
+	// Flip the case of one letter of the checksummed hex address.
+	i := strings.IndexAny(hexAddr[2:], "abcdefABCDEF") + 2
+	badHex := hexAddr[:i] + string(hexAddr[i]^0x20) + hexAddr[i+1:]
+	valoper, err := sdk.Bech32ifyAddressBytes("cosmosvaloper", addr)
+	require.NoError(t, err)
+	badChecksum := ZeroCosmosAddr[:len(ZeroCosmosAddr)-1] + "q"
+	if badChecksum == ZeroCosmosAddr {
+		badChecksum = ZeroCosmosAddr[:len(ZeroCosmosAddr)-1] + "p"
+	}
+	for args, msg := range map[string]string{
+		badChecksum:               "invalid address " + badChecksum + ": bad bech32 checksum",
+		valoper:                   `invalid address ` + valoper + `: wrong prefix "cosmosvaloper", which is not the account prefix of a configured chain (one of cosmos, osmo)`,
+		badHex + " --hex":         "invalid hex address " + badHex + ": bad EIP-55 checksum",
+		"0x0102 --hex":            "invalid hex address 0x0102: it has 2 bytes, but must have 20 or 32",
+		ZeroCosmosAddr + " --hex": "invalid hex address " + ZeroCosmosAddr,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"keys", "convert"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
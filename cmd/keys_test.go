@@ -1,10 +1,19 @@
 package cmd_test
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestKeysList_EmptyKeys(t *testing.T) {
@@ -57,3 +66,125 @@ func TestKeys_Restore(t *testing.T) {
 	res = sys.MustRun(t, "keys", "list")
 	require.Equal(t, res.Stdout.String(), "key(mykey) -> "+ZeroCosmosAddr+"\n")
 }
+
+func TestKeysAdd_Options(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	type keyOutput struct {
+		Name     string
+		Address  string
+		PubKey   string
+		Mnemonic string
+	}
+	addKey := func(in string, args ...string) (keyOutput, *RunResult) {
+		t.Helper()
+		res := sys.MustRunWithInput(t, strings.NewReader(in), append([]string{"keys", "add"}, args...)...)
+		var ko keyOutput
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &ko))
+		return ko, &res
+	}
+
+	// A new key is printed with its mnemonic, and a warning to back it up.
+	ko, res := addKey("", "fresh")
+	require.Equal(t, "fresh", ko.Name)
+	require.True(t, strings.HasPrefix(ko.Address, "cosmos1"), ko.Address)
+	require.Contains(t, ko.PubKey, "/cosmos.crypto.secp256k1.PubKey")
+	require.Len(t, strings.Fields(ko.Mnemonic), 24)
+	require.Contains(t, res.Stderr.String(), "It is the only way to recover the key")
+
+	ko, res = addKey("", "nobackup", "--no-backup")
+	require.Empty(t, ko.Mnemonic)
+	require.NotContains(t, res.Stderr.String(), "It is the only way to recover the key")
+
+	// A recovered key is not printed with its mnemonic.
+	ko, _ = addKey(ZeroMnemonic+"\n", "mykey", "--recover")
+	require.Equal(t, ZeroCosmosAddr, ko.Address)
+	require.Empty(t, ko.Mnemonic)
+
+	// With --dry-run, the key is derived but not stored, so the name can be reused.
+	dry, _ := addKey(ZeroMnemonic+"\n", "mykey", "--recover", "--dry-run", "--account", "1")
+	require.NotEqual(t, ZeroCosmosAddr, dry.Address)
+	ko, _ = addKey(ZeroMnemonic+"\n", "account1", "--recover", "--account", "1")
+	require.Equal(t, dry.Address, ko.Address)
+	index, _ := addKey(ZeroMnemonic+"\n", "index1", "--recover", "--dry-run", "--index", "1")
+	require.NotEqual(t, ZeroCosmosAddr, index.Address)
+	require.NotEqual(t, dry.Address, index.Address)
+	res2 := sys.MustRun(t, "keys", "list")
+	require.NotContains(t, res2.Stdout.String(), "index1")
+
+	// The coin type defaults to the slip44 of the chain.
+	eth, _ := addKey(ZeroMnemonic+"\n", "eth", "--recover", "--dry-run", "--coin-type", "60")
+	require.Contains(t, eth.PubKey, "ethsecp256k1")
+	secret, _ := addKey(ZeroMnemonic+"\n", "secret", "--recover", "--dry-run", "--coin-type", "529")
+	require.NotEqual(t, ZeroCosmosAddr, secret.Address)
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "slip44", "529")
+	ko, _ = addKey(ZeroMnemonic+"\n", "secret", "--recover", "--dry-run")
+	require.Equal(t, secret.Address, ko.Address)
+
+	words := strings.Fields(ZeroMnemonic)
+	for in, msg := range map[string]string{
+		strings.Join(words[:23], " "):                       "invalid mnemonic: it has 23 words, but must have 12, 15, 18, 21 or 24",
+		strings.Join(append(words[:23:23], "zzz"), " "):     `invalid mnemonic: word 24, "zzz", is not in the BIP39 English word list`,
+		strings.Join(append(words[:23:23], "abandon"), " "): "invalid mnemonic: its checksum is wrong",
+		"": "no mnemonic was given",
+	} {
+		res := sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(in+"\n"), "keys", "add", "bad", "--recover")
+		require.ErrorContains(t, res.Err, msg, in)
+	}
+	res3 := sys.Run(zaptest.NewLogger(t), "keys", "add", "mykey")
+	require.ErrorContains(t, res3.Err, "a key with name mykey already exists")
+}
+
+func TestKeysList_AllChains(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "osmokey", "--chain", "osmosis")
+	res := sys.MustRun(t, "keys", "show", "osmokey", "--chain", "osmosis")
+	osmoAddr := strings.TrimSpace(res.Stdout.String())
+
+	res = sys.MustRun(t, "keys", "list", "--all-chains", "-o", "text")
+	require.Regexp(t, `cosmoshub\s+mykey\s+`+ZeroCosmosAddr, res.Stdout.String())
+	require.Regexp(t, `osmosis\s+osmokey\s+`+osmoAddr, res.Stdout.String())
+	require.NotContains(t, res.Stdout.String(), "BALANCE")
+
+	// The balances of an unreachable chain are n/a, without failing the listing.
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/SpendableBalances", func(bytes.HexBytes) bool { return true },
+		&banktypes.QuerySpendableBalancesResponse{Balances: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	unreachable := new(mocks.Client)
+	unreachable.On("ABCIQueryWithOptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+	sys.OverrideClients("osmosis", cmd.ClientOverrides{
+		RPCClient: unreachable,
+	})
+
+	res = sys.MustRun(t, "keys", "list", "--all-chains", "--with-balance", "-o", "text")
+	require.Regexp(t, `cosmoshub\s+mykey\s+`+ZeroCosmosAddr+`\s+10uatom`, res.Stdout.String())
+	require.Regexp(t, `osmosis\s+osmokey\s+`+osmoAddr+`\s+n/a`, res.Stdout.String())
+
+	var out map[string][]struct {
+		Name         string
+		Address      string
+		Balance      []map[string]string
+		BalanceError string `json:"balance_error"`
+	}
+	res = sys.MustRun(t, "keys", "list", "--all-chains", "--with-balance", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Len(t, out["cosmoshub"], 1)
+	require.Equal(t, "mykey", out["cosmoshub"][0].Name)
+	require.Equal(t, []map[string]string{{"denom": "uatom", "amount": "10"}}, out["cosmoshub"][0].Balance)
+	require.Len(t, out["osmosis"], 1)
+	require.Nil(t, out["osmosis"][0].Balance)
+	require.Contains(t, out["osmosis"][0].BalanceError, "connection refused")
+
+	// Without --all-chains, only the default chain is listed.
+	res = sys.MustRun(t, "keys", "list", "--with-balance")
+	require.Contains(t, res.Stdout.String(), "mykey")
+	require.NotContains(t, res.Stdout.String(), "osmokey")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	zaplogfmt "github.com/jsternberg/zap-logfmt"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
+)
+
+const (
+	logLevelFlag  = "log-level"
+	logFormatFlag = "log-format"
+	logFileFlag   = "log-file"
+)
+
+// Formats of the log, as selected by --log-format.
+const (
+	logFormatConsole = "console"
+	logFormatJSON    = "json"
+	logFormatLogfmt  = "logfmt"
+)
+
+// logLevels are the levels accepted by --log-level.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// logFormats are the formats accepted by --log-format.
+var logFormats = []string{logFormatConsole, logFormatJSON, logFormatLogfmt}
+
+// newLogger returns a logger writing to w, in the given format, at the level of atom.
+// Without a format, the console format is used if w is a terminal,
+// or else logfmt, for simplistic machine processing.
+// Console output is colorized only if w is a terminal.
+func newLogger(w io.Writer, format string, atom zap.AtomicLevel) (*zap.Logger, error) {
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeTime = func(ts time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+		encoder.AppendString(ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+	}
+	config.LevelKey = "lvl"
+
+	f, ok := w.(*os.File)
+	tty := ok && term.IsTerminal(int(f.Fd()))
+	if format == "" {
+		format = logFormatLogfmt
+		if tty {
+			// When a user runs lens in the foreground, use easier to read output.
+			format = logFormatConsole
+		}
+	}
+
+	var enc zapcore.Encoder
+	switch format {
+	case logFormatConsole:
+		if tty {
+			config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		enc = zapcore.NewConsoleEncoder(config)
+	case logFormatJSON:
+		enc = zapcore.NewJSONEncoder(config)
+	case logFormatLogfmt:
+		enc = zaplogfmt.NewEncoder(config)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (must be one of %s)", format, strings.Join(logFormats, ", "))
+	}
+
+	return zap.New(zapcore.NewCore(enc, zapcore.AddSync(w), atom)), nil
+}
+
+// configureLogger replaces the logger of a with one following the --log-level, --log-format, and --log-file flags,
+// if any of them is set, so that the logger given to NewRootCmd is kept otherwise.
+// The logger writes to the error output of cmd, unless --log-file is set.
+func (a *appState) configureLogger(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	if !flags.Changed(logLevelFlag) && !flags.Changed(logFormatFlag) && !flags.Changed(logFileFlag) {
+		return nil
+	}
+
+	levelName, err := flags.GetString(logLevelFlag)
+	if err != nil {
+		return err
+	}
+	level := zapcore.InfoLevel
+	if trace, _ := tracing(cmd); a.Debug || trace {
+		level = zapcore.DebugLevel
+	}
+	if flags.Changed(logLevelFlag) {
+		switch levelName {
+		case "debug", "info", "warn", "error":
+			if err := level.UnmarshalText([]byte(levelName)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown log level %q (must be one of %s)", levelName, strings.Join(logLevels, ", "))
+		}
+	}
+
+	format, err := flags.GetString(logFormatFlag)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.ErrOrStderr()
+	path, err := flags.GetString(logFileFlag)
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		// The file stays open for as long as the process runs, as the logger may be used until it exits.
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+
+	log, err := newLogger(w, format, zap.NewAtomicLevelAt(level))
+	if err != nil {
+		return err
+	}
+	a.Log = log
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLogFlags(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	gRPCAddr := runGRPCReflectionServer(t)
+
+	// Debug logs are hidden by default.
+	res := sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--log-level", "info")
+	require.NotContains(t, res.Stderr.String(), "Opening remote gRPC connection")
+
+	res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--log-level", "debug", "--log-format", "json")
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(res.Stderr.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		if entry["msg"] == "Opening remote gRPC connection" {
+			found = true
+			require.Equal(t, "debug", entry["lvl"])
+			require.Equal(t, gRPCAddr, entry["addr"])
+		}
+	}
+	require.True(t, found, res.Stderr.String())
+
+	// The console format is not colorized when stderr is not a terminal.
+	res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--log-level", "debug", "--log-format", "console")
+	require.Contains(t, res.Stderr.String(), "\tdebug\tOpening remote gRPC connection\t")
+	require.NotContains(t, res.Stderr.String(), "\x1b[")
+
+	// --debug selects the debug level too.
+	res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--debug", "--log-format", "logfmt")
+	require.Contains(t, res.Stderr.String(), `msg="Opening remote gRPC connection"`)
+
+	// --log-file writes the log to the file instead of stderr.
+	logFile := filepath.Join(t.TempDir(), "lens.log")
+	res = sys.MustRun(t, "dynamic", "inspect", gRPCAddr, "--log-level", "debug", "--log-file", logFile)
+	require.Empty(t, res.Stderr.String())
+	logs, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "Opening remote gRPC connection")
+
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "--log-level", "verbose")
+	require.ErrorContains(t, res.Err, `unknown log level "verbose" (must be one of debug, info, warn, error)`)
+	res = sys.Run(zaptest.NewLogger(t), "dynamic", "inspect", gRPCAddr, "--log-format", "xml")
+	require.ErrorContains(t, res.Err, `unknown log format "xml" (must be one of console, json, logfmt)`)
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const (
+	mempoolLimitFlag  = "limit"
+	mempoolWatchFlag  = "watch"
+	mempoolSenderFlag = "sender"
+
+	// mempoolMaxLimit is the most unconfirmed transactions the RPC endpoint returns at once.
+	mempoolMaxLimit = 100
+
+	// clearScreen moves the cursor of a terminal to its top left corner and clears it.
+	clearScreen = "\033[H\033[2J"
+)
+
+func queryMempoolCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mempool [chain-name]",
+		Short: "query the unconfirmed transactions in the mempool of a node",
+		Long: `Query the unconfirmed transactions in the mempool of the node serving the RPC endpoint of the given chain,
+or of the default chain, up to --limit of them in the order they were received, listing the hash, sender, message types,
+fee, gas limit, and memo of each, with the number and size of all the unconfirmed transactions of the node.
+The sender is the first signer of the transaction.
+
+With --sender, only the listed transactions of that address or key are shown, to find out why they are not included.
+With --watch, the mempool is queried again at that interval, clearing the screen, until interrupted.`,
+		Example: fmt.Sprintf(`$ %s query mempool cosmoshub
+$ %s q mempool cosmoshub --sender mykey --watch 5s
+$ %s q mempool --limit 10 -o json`,
+			appName, appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			limit, err := f.GetInt(mempoolLimitFlag)
+			if err != nil {
+				return err
+			}
+			if limit < 1 || limit > mempoolMaxLimit {
+				return fmt.Errorf("invalid --%s %d: must be between 1 and %d, the most the RPC endpoint returns", mempoolLimitFlag, limit, mempoolMaxLimit)
+			}
+			interval, err := f.GetDuration(mempoolWatchFlag)
+			if err != nil {
+				return err
+			}
+			if interval < 0 {
+				return fmt.Errorf("invalid --%s %s: must not be negative", mempoolWatchFlag, interval)
+			}
+			senderArg, err := f.GetString(mempoolSenderFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName, _ := txArgs(a, args, 0)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			var sender string
+			if senderArg != "" {
+				addr, err := cl.AccountFromKeyOrAddress(senderArg)
+				if err != nil {
+					return fmt.Errorf("invalid --%s %q: %w", mempoolSenderFlag, senderArg, err)
+				}
+				sender = cl.MustEncodeAccAddr(addr)
+			}
+
+			if interval == 0 {
+				res, err := queryMempool(cmd.Context(), cl, limit, sender)
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd, a, res)
+			}
+
+			// Watching stops, without an error, when interrupted or once --timeout has passed.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				res, err := queryMempool(ctx, cl, limit, sender)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if a.OutputFormat == "" || a.OutputFormat == outputText {
+					fmt.Fprint(cmd.OutOrStdout(), clearScreen)
+					fmt.Fprintf(cmd.OutOrStdout(), "Every %s: %s\n\n", interval, time.Now().Format(time.RFC3339))
+				}
+				if err := writeOutput(cmd, a, res); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	cmd.Flags().Int(mempoolLimitFlag, mempoolMaxLimit, fmt.Sprintf("how many unconfirmed transactions to list, at most %d", mempoolMaxLimit))
+	cmd.Flags().Duration(mempoolWatchFlag, 0, "query the mempool again at this interval (e.g. 5s) until interrupted, clearing the screen")
+	cmd.Flags().String(mempoolSenderFlag, "", "only show the transactions whose first signer is this address or key")
+	return cmd
+}
+
+// queryMempool returns up to limit unconfirmed transactions of the node of cl, only those of sender if it is not empty.
+func queryMempool(ctx context.Context, cl *client.ChainClient, limit int, sender string) (mempoolResult, error) {
+	res, err := cl.RPCClient.UnconfirmedTxs(ctx, &limit)
+	if err != nil {
+		return mempoolResult{}, fmt.Errorf("failed to query the unconfirmed transactions: %w", err)
+	}
+	num, err := cl.RPCClient.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return mempoolResult{}, fmt.Errorf("failed to query the number of unconfirmed transactions: %w", err)
+	}
+
+	result := mempoolResult{Listed: len(res.Txs), Sender: sender, Total: num.Total, TotalBytes: num.TotalBytes, Txs: []mempoolTx{}}
+	for _, bz := range res.Txs {
+		tx := decodeMempoolTx(cl, bz)
+		if sender == "" || tx.Sender == sender {
+			result.Txs = append(result.Txs, tx)
+		}
+	}
+	return result, nil
+}
+
+// decodeMempoolTx decodes the unconfirmed transaction bz with the codec of cl.
+// A transaction that cannot be decoded is listed by its hash and size only.
+func decodeMempoolTx(cl *client.ChainClient, bz tmtypes.Tx) mempoolTx {
+	result := mempoolTx{Hash: fmt.Sprintf("%X", bz.Hash()), Bytes: len(bz), Messages: []string{}}
+	tx, err := unmarshalTx(bz)
+	if err != nil {
+		return result
+	}
+	result.Memo = tx.Body.Memo
+	if fee := tx.AuthInfo.Fee; fee != nil {
+		result.Fee = fee.Amount
+		result.Gas = fee.GasLimit
+	}
+	for _, any := range tx.Body.Messages {
+		result.Messages = append(result.Messages, any.TypeUrl)
+	}
+
+	// The signers of messages are decoded with the account prefix of the chain.
+	done := cl.SetSDKContext()
+	defer done()
+	if len(tx.Body.Messages) > 0 {
+		var msg sdk.Msg
+		if _, ok := decodeAny(cl, tx.Body.Messages[0], &msg); ok {
+			if signers := msg.GetSigners(); len(signers) > 0 {
+				result.Sender = cl.MustEncodeAccAddr(signers[0])
+				return result
+			}
+		}
+	}
+	// The first signer of a message of a type unknown to the codec is that of the first signature,
+	// unless its public key is not known yet.
+	if len(tx.AuthInfo.SignerInfos) > 0 {
+		var pubKey cryptotypes.PubKey
+		if _, ok := decodeAny(cl, tx.AuthInfo.SignerInfos[0].PublicKey, &pubKey); ok {
+			result.Sender = cl.MustEncodeAccAddr(sdk.AccAddress(pubKey.Address()))
+		}
+	}
+	return result
+}
+
+// mempoolTx is an unconfirmed transaction listed by query mempool.
+type mempoolTx struct {
+	Hash     string    `json:"hash"`
+	Sender   string    `json:"sender"`
+	Messages []string  `json:"messages"`
+	Fee      sdk.Coins `json:"fee"`
+	Gas      uint64    `json:"gas"`
+	Memo     string    `json:"memo,omitempty"`
+	Bytes    int       `json:"bytes"`
+}
+
+// mempoolResult is the result of query mempool.
+type mempoolResult struct {
+	// Listed is how many unconfirmed transactions were listed, before they were filtered by Sender.
+	Listed     int         `json:"listed"`
+	Sender     string      `json:"sender,omitempty"`
+	Total      int         `json:"total"`
+	TotalBytes int64       `json:"total_bytes"`
+	Txs        []mempoolTx `json:"txs"`
+}
+
+var _ fmt.Stringer = mempoolResult{}
+
+// String returns the transactions as a table with aligned columns, followed by a summary line.
+func (r mempoolResult) String() string {
+	var b strings.Builder
+	if len(r.Txs) > 0 {
+		w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "HASH\tSENDER\tMESSAGES\tFEE\tGAS\tMEMO")
+		for _, tx := range r.Txs {
+			messages := make([]string, len(tx.Messages))
+			for i, m := range tx.Messages {
+				messages[i] = strings.TrimPrefix(m, "/")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				tx.Hash, orDash(tx.Sender), orDash(strings.Join(messages, ",")), orDash(tx.Fee.String()), tx.Gas, orDash(tx.Memo))
+		}
+		w.Flush()
+	}
+	if r.Sender != "" {
+		fmt.Fprintf(&b, "%d of the %d listed transactions are from %s; ", len(r.Txs), r.Listed, r.Sender)
+	} else if r.Listed < r.Total {
+		fmt.Fprintf(&b, "Listed %d; ", r.Listed)
+	}
+	fmt.Fprintf(&b, "%d unconfirmed transactions, %d bytes in total.\n", r.Total, r.TotalBytes)
+	return b.String()
+}
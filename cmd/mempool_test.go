@@ -0,0 +1,124 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mempoolTestTx returns an encoded transaction of msgs, with the public key of the first signature if it is not nil.
+func mempoolTestTx(t *testing.T, memo string, pubKey *secp256k1.PubKey, msgs ...*codectypes.Any) tmtypes.Tx {
+	t.Helper()
+
+	body, err := (&txtypes.TxBody{Messages: msgs, Memo: memo}).Marshal()
+	require.NoError(t, err)
+	authInfo := txtypes.AuthInfo{Fee: &txtypes.Fee{Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)), GasLimit: 200000}}
+	if pubKey != nil {
+		pk, err := codectypes.NewAnyWithValue(pubKey)
+		require.NoError(t, err)
+		authInfo.SignerInfos = []*txtypes.SignerInfo{{PublicKey: pk}}
+	}
+	authInfoBytes, err := authInfo.Marshal()
+	require.NoError(t, err)
+	bz, err := (&txtypes.TxRaw{BodyBytes: body, AuthInfoBytes: authInfoBytes, Signatures: [][]byte{{}}}).Marshal()
+	require.NoError(t, err)
+	return bz
+}
+
+func TestQueryMempool(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	send := func(from string) *codectypes.Any {
+		msg, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{FromAddress: from, ToAddress: ZeroCosmosAddr, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 5))})
+		require.NoError(t, err)
+		return msg
+	}
+	unknown := &codectypes.Any{TypeUrl: "/example.v1.MsgCustom", Value: []byte{1, 2, 3}}
+	pubKey := secp256k1.GenPrivKey().PubKey().(*secp256k1.PubKey)
+	unknownSender := sdk.MustBech32ifyAddressBytes("cosmos", pubKey.Address())
+
+	txs := []tmtypes.Tx{
+		mempoolTestTx(t, "mine", nil, send(ZeroCosmosAddr), unknown),
+		mempoolTestTx(t, "", nil, send(testGroupMemberAddr)),
+		mempoolTestTx(t, "", pubKey, unknown),
+		tmtypes.Tx("garbage"),
+	}
+	mc := new(mocks.Client)
+	mc.On("UnconfirmedTxs", mock.Anything, mock.Anything).Return(&coretypes.ResultUnconfirmedTxs{Count: len(txs), Total: 7, Txs: txs}, nil)
+	mc.On("NumUnconfirmedTxs", mock.Anything).Return(&coretypes.ResultUnconfirmedTxs{Total: 7, TotalBytes: 4096}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "q", "mempool", "cosmoshub", "--limit", "4", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 6)
+	require.Equal(t, []string{"HASH", "SENDER", "MESSAGES", "FEE", "GAS", "MEMO"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{ZeroCosmosAddr, "cosmos.bank.v1beta1.MsgSend,example.v1.MsgCustom", "500uatom", "200000", "mine"}, strings.Fields(lines[1])[1:])
+	require.Equal(t, testGroupMemberAddr, strings.Fields(lines[2])[1])
+	require.Equal(t, unknownSender, strings.Fields(lines[3])[1])
+	require.Equal(t, []string{"-", "-", "-", "0", "-"}, strings.Fields(lines[4])[1:])
+	require.Equal(t, "Listed 4; 7 unconfirmed transactions, 4096 bytes in total.", lines[5])
+	limit := 4
+	mc.AssertCalled(t, "UnconfirmedTxs", mock.Anything, &limit)
+
+	// Only the transactions of the sender are shown.
+	var result struct {
+		Listed     int
+		Sender     string
+		Total      int
+		TotalBytes int64 `json:"total_bytes"`
+		Txs        []struct {
+			Hash     string
+			Sender   string
+			Messages []string
+			Fee      sdk.Coins
+			Gas      uint64
+			Memo     string
+		}
+	}
+	res = sys.MustRun(t, "q", "mempool", "--sender", "mykey", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &result))
+	require.Equal(t, 4, result.Listed)
+	require.Equal(t, ZeroCosmosAddr, result.Sender)
+	require.Equal(t, int64(4096), result.TotalBytes)
+	require.Len(t, result.Txs, 1)
+	require.Equal(t, "mine", result.Txs[0].Memo)
+	require.Equal(t, []string{"/cosmos.bank.v1beta1.MsgSend", "/example.v1.MsgCustom"}, result.Txs[0].Messages)
+	require.Equal(t, fmt.Sprintf("%X", txs[0].Hash()), result.Txs[0].Hash)
+
+	res = sys.MustRun(t, "q", "mempool", "--sender", testGroupMemberAddr, "-o", "text")
+	require.Contains(t, res.Stdout.String(), "1 of the 4 listed transactions are from "+testGroupMemberAddr+"; 7 unconfirmed transactions, 4096 bytes in total.")
+
+	// Watching clears the screen before each refresh, until --timeout.
+	res = sys.MustRun(t, "q", "mempool", "--watch", "10ms", "--timeout", "100ms", "-o", "text")
+	require.GreaterOrEqual(t, strings.Count(res.Stdout.String(), "\033[H\033[2J"), 2)
+	require.Contains(t, res.Stdout.String(), "Every 10ms: ")
+
+	for args, msg := range map[string]string{
+		"--limit 0":         "invalid --limit 0: must be between 1 and 100",
+		"--limit 101":       "invalid --limit 101: must be between 1 and 100",
+		"--watch -1s":       "invalid --watch -1s: must not be negative",
+		"--sender cosmos1x": `invalid --sender "cosmos1x"`,
+	} {
+		res := sys.Run(zaptest.NewLogger(t), append([]string{"q", "mempool"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
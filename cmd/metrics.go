@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/strangelove-ventures/lens/client"
+	"go.uber.org/zap"
+)
+
+// metricsListenFlag is the name of the root flag serving the metrics of the chain clients.
+const metricsListenFlag = "metrics-listen"
+
+// metricsShutdownTimeout is how long in-flight scrapes have to complete when the command exits.
+const metricsShutdownTimeout = 5 * time.Second
+
+// serveMetrics sets a.Metrics to metrics registered in a new registry, served on /metrics at addr,
+// and returns a function stopping the server.
+func serveMetrics(a *appState, addr string) (func(), error) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	m, err := client.NewMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for --%s: %w", metricsListenFlag, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Log.Warn("Metrics server stopped", zap.Error(err))
+		}
+	}()
+	a.Log.Info("Serving metrics", zap.String("addr", "http://"+ln.Addr().String()+"/metrics"))
+
+	a.Metrics = m
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			a.Log.Warn("Failed to stop the metrics server", zap.Error(err))
+		}
+	}, nil
+}
@@ -0,0 +1,61 @@
+package cmd_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMetricsListen(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	core, logs := observer.New(zap.InfoLevel)
+	metricsURL := func() string {
+		served := logs.FilterMessage("Serving metrics").All()
+		require.Len(t, served, 1)
+		return served[0].ContextMap()["addr"].(string)
+	}
+
+	// The metrics are scraped while the transaction is broadcast.
+	var scraped string
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		res, err := http.Get(metricsURL())
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		bz, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		scraped = string(bz)
+	}).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.Run(zap.New(core), "tx", "bank", "send", "mykey", ZeroCosmosAddr, "1uatom",
+		"--broadcast-mode", "sync", "--metrics-listen", "127.0.0.1:0")
+	require.NoError(t, res.Err)
+	require.Contains(t, scraped, `lens_abci_queries_total{chain="cosmoshub-4",path="/cosmos.auth.v1beta1.Query/Account",status="OK"}`)
+	require.Contains(t, scraped, "go_goroutines")
+
+	// The server stops with the command.
+	_, err := http.Get(metricsURL())
+	require.Error(t, err)
+
+	res = sys.Run(zap.New(core), "chains", "list", "--metrics-listen", "not an address")
+	require.ErrorContains(t, res.Err, "failed to listen for --metrics-listen")
+}
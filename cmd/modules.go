@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/vesting"
 	authz "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/capability"
@@ -11,6 +12,7 @@ import (
 	feegrant "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 	"github.com/cosmos/cosmos-sdk/x/gov"
 	"github.com/cosmos/cosmos-sdk/x/gov/client"
+	group "github.com/cosmos/cosmos-sdk/x/group/module"
 	"github.com/cosmos/cosmos-sdk/x/mint"
 	"github.com/cosmos/cosmos-sdk/x/params"
 	paramsclient "github.com/cosmos/cosmos-sdk/x/params/client"
@@ -18,6 +20,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/upgrade"
 	upgradeclient "github.com/cosmos/cosmos-sdk/x/upgrade/client"
+	ica "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts"
 	"github.com/cosmos/ibc-go/v7/modules/apps/transfer"
 	ibc "github.com/cosmos/ibc-go/v7/modules/core"
 )
@@ -27,6 +30,7 @@ import (
 
 var ModuleBasics = []module.AppModuleBasic{
 	auth.AppModuleBasic{},
+	vesting.AppModuleBasic{},
 	authz.AppModuleBasic{},
 	bank.AppModuleBasic{},
 	capability.AppModuleBasic{},
@@ -42,11 +46,13 @@ var ModuleBasics = []module.AppModuleBasic{
 	crisis.AppModuleBasic{},
 	distribution.AppModuleBasic{},
 	feegrant.AppModuleBasic{},
+	group.AppModuleBasic{},
 	mint.AppModuleBasic{},
 	params.AppModuleBasic{},
 	slashing.AppModuleBasic{},
 	staking.AppModuleBasic{},
 	upgrade.AppModuleBasic{},
 	transfer.AppModuleBasic{},
+	ica.AppModuleBasic{},
 	ibc.AppModuleBasic{},
 }
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const (
+	chainsFlag       = "chains"
+	allChainsFlag    = "all-chains"
+	chainTimeoutFlag = "chain-timeout"
+)
+
+// multiChainHelp describes the multi-chain flags of the query commands, for use in command help.
+const multiChainHelp = `With --chains or --all-chains, the query is run on each of those chains concurrently instead of on the default chain,
+and the result or error of each chain is printed under its name; with --output json or yaml, as an object keyed by chain name.
+The other chains are still queried when the query fails on one, but the command then fails after printing.
+Key names are resolved in the keyring of each chain, and an address of another chain is converted to the same account on each chain.`
+
+// addMultiChainFlags adds the --chains, --all-chains, and --chain-timeout flags to the query command tree cmd,
+// and makes every query command in it run on each of the chains they select.
+func addMultiChainFlags(a *appState, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSlice(chainsFlag, nil, "run the query on each of these chains, instead of on the default chain")
+	cmd.PersistentFlags().Bool(allChainsFlag, false, "run the query on every configured chain, instead of on the default chain")
+	cmd.PersistentFlags().Duration(chainTimeoutFlag, client.DefaultMultiChainTimeout, "with --chains or --all-chains, how long to wait for the query of each chain")
+
+	walkLeafCommands(cmd, func(leaf *cobra.Command) {
+		runE := leaf.RunE
+		leaf.RunE = func(cmd *cobra.Command, args []string) error {
+			// The copies of the command run on each chain run the query itself.
+			if a.MultiChain {
+				return runE(cmd, args)
+			}
+			chains, err := multiChainNames(cmd, a)
+			if err != nil {
+				return err
+			}
+			if chains == nil {
+				return runE(cmd, args)
+			}
+			return runMultiChain(cmd, a, chains, args)
+		}
+	})
+}
+
+// walkLeafCommands calls fn with every runnable command in the tree cmd without subcommands.
+func walkLeafCommands(cmd *cobra.Command, fn func(*cobra.Command)) {
+	if !cmd.HasSubCommands() {
+		if cmd.RunE != nil {
+			fn(cmd)
+		}
+		return
+	}
+	for _, c := range cmd.Commands() {
+		walkLeafCommands(c, fn)
+	}
+}
+
+// multiChainNames returns the chains selected by --chains or --all-chains, or nil if neither flag is set.
+func multiChainNames(cmd *cobra.Command, a *appState) ([]string, error) {
+	chains, err := cmd.Flags().GetStringSlice(chainsFlag)
+	if err != nil {
+		return nil, err
+	}
+	all, err := cmd.Flags().GetBool(allChainsFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case all && len(chains) > 0:
+		return nil, fmt.Errorf("--%s and --%s cannot be used together", chainsFlag, allChainsFlag)
+	case all:
+		chains = make([]string, 0, len(a.Config.Chains))
+		for name := range a.Config.Chains {
+			chains = append(chains, name)
+		}
+		sort.Strings(chains)
+		return chains, nil
+	case len(chains) == 0:
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(chains))
+	unique := chains[:0]
+	for _, name := range chains {
+		if _, ok := a.Config.Chains[name]; !ok {
+			return nil, ChainNotFoundError{Requested: name, Config: a.Config}
+		}
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique, nil
+}
+
+// multiChainEntry is the result of a query on one chain, or its error.
+type multiChainEntry struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+
+	// text is the output of the query in text mode.
+	text string
+}
+
+// multiChainOutput is the output of a query run on several chains,
+// as an object keyed by chain name, or in text mode, as the output of each chain under its name.
+type multiChainOutput struct {
+	chains  []string
+	entries map[string]multiChainEntry
+}
+
+func (o multiChainOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.entries)
+}
+
+func (o multiChainOutput) String() string {
+	var sb strings.Builder
+	for _, name := range o.chains {
+		e := o.entries[name]
+		fmt.Fprintf(&sb, "%s:\n", name)
+		if e.Error != "" {
+			fmt.Fprintf(&sb, "  error: %s\n", e.Error)
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimRight(e.text, "\n"), "\n") {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(&sb, "  %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+// runMultiChain runs the query command cmd with args on each of chains concurrently,
+// and writes the result or error of each chain.
+func runMultiChain(cmd *cobra.Command, a *appState, chains []string, args []string) error {
+	if len(args) > 0 {
+		if _, ok := a.Config.Chains[args[0]]; ok {
+			return fmt.Errorf("the chain name argument %q cannot be combined with --%s or --%s", args[0], chainsFlag, allChainsFlag)
+		}
+	}
+	timeout, err := cmd.Flags().GetDuration(chainTimeoutFlag)
+	if err != nil {
+		return err
+	}
+
+	structured := a.OutputFormat != "" && a.OutputFormat != outputText
+	clients := make(client.ChainClients, len(chains))
+	names := make(map[*client.ChainClient]string, len(chains))
+	for _, name := range chains {
+		if cl := a.Config.GetClient(name); cl != nil {
+			clients[name] = cl
+			names[cl] = name
+		}
+	}
+
+	// The flags are collected once, as visiting them may sort them.
+	var flags []*pflag.Flag
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, f)
+	})
+
+	results := clients.MultiChainQuery(cmd.Context(), chains, func(ctx context.Context, cl *client.ChainClient) (interface{}, error) {
+		return runOnChain(ctx, cmd, flags, a, names[cl], cl, args, structured)
+	}, client.WithMultiChainTimeout(timeout))
+
+	out := multiChainOutput{chains: chains, entries: make(map[string]multiChainEntry, len(chains))}
+	var failed []string
+	for _, name := range chains {
+		res := results[name]
+		if res.Err != nil {
+			out.entries[name] = multiChainEntry{Error: res.Err.Error()}
+			failed = append(failed, name)
+			continue
+		}
+		bz := res.Value.([]byte)
+		if !structured {
+			out.entries[name] = multiChainEntry{text: string(bz)}
+			continue
+		}
+		bz = bytes.TrimSpace(bz)
+		if !json.Valid(bz) {
+			// Output that is not JSON is kept as a string.
+			bz, err = json.Marshal(string(bz))
+			if err != nil {
+				return err
+			}
+		}
+		out.entries[name] = multiChainEntry{Result: bz}
+	}
+
+	if err := writeOutput(cmd, a, out); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return FailedChainsError{Chains: failed, Queried: len(chains)}
+	}
+	return nil
+}
+
+// runOnChain runs a copy of the query command cmd with args on chain, whose client is cl,
+// with copies of flags, the parsed flags of cmd, and returns its output.
+// In structured mode, the output is JSON.
+func runOnChain(ctx context.Context, cmd *cobra.Command, flags []*pflag.Flag, a *appState, chain string, cl *client.ChainClient, args []string, structured bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// The copy of the client writes to the buffer.
+	clCopy := *cl
+	clCopy.Output = &buf
+	if structured {
+		ccc := *cl.Config
+		ccc.OutputFormat = outputJSON
+		clCopy.Config = &ccc
+	}
+
+	cfg := *a.Config
+	cfg.DefaultChain = chain
+	cfg.cl = map[string]*client.ChainClient{chain: &clCopy}
+
+	aCopy := *a
+	aCopy.Config = &cfg
+	aCopy.MultiChain = true
+	if structured {
+		aCopy.OutputFormat = outputJSON
+	}
+
+	// The query commands are bound to their app state, so a new command tree is built for the copy.
+	leaf, err := findCommandCopy(cmd, queryCmd(&aCopy))
+	if err != nil {
+		return nil, err
+	}
+	leaf.ResetFlags()
+	for _, f := range flags {
+		// Copies, as adding a flag to a set may modify it; the copies share the parsed values.
+		f := *f
+		leaf.Flags().AddFlag(&f)
+	}
+	leaf.SetOut(&buf)
+	leaf.SetErr(cmd.ErrOrStderr())
+	leaf.SetIn(cmd.InOrStdin())
+	leaf.SetContext(ctx)
+
+	if err := leaf.RunE(leaf, args); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// findCommandCopy returns the command in the query command tree root at the same path as cmd.
+func findCommandCopy(cmd *cobra.Command, root *cobra.Command) (*cobra.Command, error) {
+	var path []string
+	for c := cmd; c.HasParent() && c.Name() != root.Name(); c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+
+	found := root
+	for _, name := range path {
+		var next *cobra.Command
+		for _, c := range found.Commands() {
+			if c.Name() == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("command %q not found", cmd.CommandPath())
+		}
+		found = next
+	}
+	return found, nil
+}
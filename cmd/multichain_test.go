@@ -0,0 +1,72 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQuery_MultiChain(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// The address is queried on each chain with the prefix of that chain.
+	const osmoAddr = "osmo1r5v5srda7xfth3hn2s26txvrcrntldjuns5tpd"
+	hub := new(mocks.Client)
+	mockABCIQuery(t, hub, "/cosmos.bank.v1beta1.Query/Balance", func(data bytes.HexBytes) bool {
+		return strings.Contains(string(data), ZeroCosmosAddr)
+	}, &banktypes.QueryBalanceResponse{Balance: &sdk.Coin{Denom: "uatom", Amount: sdk.NewInt(42)}})
+	mockABCIQuery(t, hub, "/cosmos.bank.v1beta1.Query/DenomsMetadata", func(bytes.HexBytes) bool { return true },
+		&banktypes.QueryDenomsMetadataResponse{Pagination: &query.PageResponse{}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{RPCClient: hub})
+	osmo := new(mocks.Client)
+	osmo.On("ABCIQueryWithOptions", mock.Anything, "/cosmos.bank.v1beta1.Query/Balance", mock.MatchedBy(func(data bytes.HexBytes) bool {
+		return strings.Contains(string(data), osmoAddr)
+	}), rpcclient.ABCIQueryOptions{}).Return(nil, errors.New("node unavailable"))
+	sys.OverrideClients("osmosis", cmd.ClientOverrides{RPCClient: osmo})
+
+	res := sys.Run(zaptest.NewLogger(t), "q", "bank", "balances", ZeroCosmosAddr, "--denom", "uatom", "--all-chains", "-o", "text")
+	var failed cmd.FailedChainsError
+	require.ErrorAs(t, res.Err, &failed)
+	require.Equal(t, cmd.FailedChainsError{Chains: []string{"osmosis"}, Queried: 2}, failed)
+	require.Equal(t, cmd.ErrCodeGeneric, res.ExitCode)
+	lines := strings.Split(res.Stdout.String(), "\n")
+	require.Equal(t, "cosmoshub:", lines[0])
+	require.Equal(t, []string{"DENOM", "AMOUNT"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"uatom", "42"}, strings.Fields(lines[2]))
+	require.Equal(t, "osmosis:", lines[3])
+	require.Contains(t, lines[4], "  error: ")
+	require.Contains(t, lines[4], "node unavailable")
+
+	var out map[string]struct {
+		Result sdk.Coins
+		Error  string
+	}
+	res = sys.Run(zaptest.NewLogger(t), "q", "bank", "balances", ZeroCosmosAddr, "--denom", "uatom", "--chains", "cosmoshub,osmosis", "-o", "json")
+	require.Error(t, res.Err)
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Len(t, out, 2)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 42)), out["cosmoshub"].Result)
+	require.Empty(t, out["cosmoshub"].Error)
+	require.Contains(t, out["osmosis"].Error, "node unavailable")
+
+	res = sys.Run(zaptest.NewLogger(t), "q", "bank", "balances", ZeroCosmosAddr, "--chains", "cosmoshub,junox")
+	var notFound cmd.ChainNotFoundError
+	require.ErrorAs(t, res.Err, &notFound)
+	require.Equal(t, "junox", notFound.Requested)
+	res = sys.Run(zaptest.NewLogger(t), "q", "bank", "balances", "osmosis", "--chains", "cosmoshub")
+	require.ErrorContains(t, res.Err, `the chain name argument "osmosis" cannot be combined with --chains or --all-chains`)
+}
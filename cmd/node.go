@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// nodeQueryCmd returns the commands inspecting the node of a chain through its Tendermint RPC endpoint.
+func nodeQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Querying commands for the node serving a chain's RPC endpoint",
+		Long: `Querying commands for the node serving the RPC endpoint of a chain, for node operators.
+They only use the Tendermint RPC endpoint, with a single client per command, and not the gRPC endpoint.`,
+	}
+
+	cmd.AddCommand(
+		nodePeersCmd(a),
+		nodeNetInfoCmd(a),
+		nodeConsensusStateCmd(a),
+		nodeSyncingCmd(a),
+	)
+
+	return cmd
+}
+
+func nodePeersCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "peers [chain-name]",
+		Short: "query the peers of a node",
+		Long: `Query the peers of the node serving the RPC endpoint of the given chain, or of the default chain,
+listing the ID, address, moniker, direction, and connection duration of each.
+The address is the peer's IP with the port it listens on, as in a persistent_peers entry.`,
+		Example: fmt.Sprintf(`$ %s query node peers cosmoshub
+$ %s q node peers -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, _ := txArgs(a, args, 0)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			res, err := cl.RPCClient.NetInfo(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			peers := make(nodePeers, len(res.Peers))
+			for i, p := range res.Peers {
+				peers[i] = nodePeer{
+					ID:         string(p.NodeInfo.ID()),
+					Address:    peerAddress(p.RemoteIP, p.NodeInfo.ListenAddr),
+					Moniker:    p.NodeInfo.Moniker,
+					IsOutbound: p.IsOutbound,
+					Duration:   p.ConnectionStatus.Duration,
+				}
+			}
+			return writeOutput(cmd, a, peers)
+		},
+	}
+	return cmd
+}
+
+// peerAddress returns the address of a peer from its remote IP and the port of its listen address,
+// or its listen address as is if it cannot be parsed.
+func peerAddress(remoteIP, listenAddr string) string {
+	u, err := url.Parse(listenAddr)
+	if err != nil || u.Port() == "" {
+		// Listen addresses without a scheme, such as "0.0.0.0:26656", do not parse as URLs with a port.
+		_, port, err := net.SplitHostPort(listenAddr)
+		if err != nil {
+			return listenAddr
+		}
+		return net.JoinHostPort(remoteIP, port)
+	}
+	return net.JoinHostPort(remoteIP, u.Port())
+}
+
+// nodePeer is one peer listed by query node peers.
+type nodePeer struct {
+	ID         string        `json:"id"`
+	Address    string        `json:"address"`
+	Moniker    string        `json:"moniker"`
+	IsOutbound bool          `json:"is_outbound"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// nodePeers is the result of query node peers.
+type nodePeers []nodePeer
+
+var _ fmt.Stringer = nodePeers{}
+
+// String returns the peers as a table with aligned columns.
+func (ps nodePeers) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tADDRESS\tMONIKER\tDIRECTION\tDURATION")
+	for _, p := range ps {
+		direction := "inbound"
+		if p.IsOutbound {
+			direction = "outbound"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.ID, p.Address, orDash(p.Moniker), direction, p.Duration.Round(time.Second))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func nodeNetInfoCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "net-info [chain-name]",
+		Short: "query a summary of the p2p network of a node",
+		Long: `Query whether the node serving the RPC endpoint of the given chain, or of the default chain,
+is listening for peers, on which addresses, and how many inbound and outbound peers it has.
+query node peers lists the peers.`,
+		Example: fmt.Sprintf(`$ %s query node net-info cosmoshub
+$ %s q node net-info -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, _ := txArgs(a, args, 0)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			res, err := cl.RPCClient.NetInfo(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			info := nodeNetInfo{
+				Listening: res.Listening,
+				Listeners: res.Listeners,
+				Peers:     res.NPeers,
+			}
+			for _, p := range res.Peers {
+				if p.IsOutbound {
+					info.Outbound++
+				} else {
+					info.Inbound++
+				}
+			}
+			return writeOutput(cmd, a, info)
+		},
+	}
+	return cmd
+}
+
+// nodeNetInfo is the result of query node net-info.
+type nodeNetInfo struct {
+	Listening bool     `json:"listening"`
+	Listeners []string `json:"listeners"`
+	Peers     int      `json:"n_peers"`
+	Inbound   int      `json:"inbound"`
+	Outbound  int      `json:"outbound"`
+}
+
+var _ fmt.Stringer = nodeNetInfo{}
+
+func (ni nodeNetInfo) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Listening:\t%t\n", ni.Listening)
+	fmt.Fprintf(w, "Listeners:\t%s\n", orDash(strings.Join(ni.Listeners, ", ")))
+	fmt.Fprintf(w, "Peers:\t%d (%d inbound, %d outbound)\n", ni.Peers, ni.Inbound, ni.Outbound)
+	w.Flush()
+	return b.String()
+}
+
+func nodeConsensusStateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "consensus-state [chain-name]",
+		Aliases: []string{"cs"},
+		Short:   "query the progress of consensus on the next block",
+		Long: `Query the height, round, and step of consensus on the node serving the RPC endpoint of the given chain,
+or of the default chain, with the share of the voting power that prevoted and precommitted in the current round.
+A round stuck below 2/3 of the voting power shows which share of the validators is missing.`,
+		Example: fmt.Sprintf(`$ %s query node consensus-state cosmoshub
+$ watch -n1 %s q node cs -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, _ := txArgs(a, args, 0)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			res, err := cl.RPCClient.ConsensusState(cmd.Context())
+			if err != nil {
+				return err
+			}
+			state, err := parseRoundState(res.RoundState)
+			if err != nil {
+				return fmt.Errorf("failed to parse the consensus state of %s: %w", chainName, err)
+			}
+			return writeOutput(cmd, a, state)
+		},
+	}
+	return cmd
+}
+
+// roundStepNames are the names of the steps of a consensus round, by number.
+var roundStepNames = map[int64]string{
+	1: "NewHeight",
+	2: "NewRound",
+	3: "Propose",
+	4: "Prevote",
+	5: "PrevoteWait",
+	6: "Precommit",
+	7: "PrecommitWait",
+	8: "Commit",
+}
+
+// consensusState is the result of query node consensus-state.
+type consensusState struct {
+	Height     int64              `json:"height"`
+	Round      int64              `json:"round"`
+	Step       string             `json:"step"`
+	StartTime  *time.Time         `json:"start_time,omitempty"`
+	Proposer   string             `json:"proposer,omitempty"`
+	Prevotes   *voteParticipation `json:"prevotes"`
+	Precommits *voteParticipation `json:"precommits"`
+}
+
+// voteParticipation is the participation of the validators in the votes of a round.
+type voteParticipation struct {
+	Validators int `json:"validators"`
+	Voted      int `json:"voted"`
+
+	// VotedPower and TotalPower are only known if the vote set reports them.
+	VotedPower int64 `json:"voted_power,omitempty"`
+	TotalPower int64 `json:"total_power,omitempty"`
+
+	// Percent is the share of the voting power that voted, or else of the validators.
+	Percent float64 `json:"percent"`
+}
+
+func (v *voteParticipation) String() string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%% (%d/%d validators)", v.Percent, v.Voted, v.Validators)
+}
+
+var _ fmt.Stringer = consensusState{}
+
+func (cs consensusState) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Height:\t%d\n", cs.Height)
+	fmt.Fprintf(w, "Round:\t%d\n", cs.Round)
+	fmt.Fprintf(w, "Step:\t%s\n", orDash(cs.Step))
+	if cs.StartTime != nil {
+		fmt.Fprintf(w, "Start time:\t%s\n", cs.StartTime.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Proposer:\t%s\n", orDash(cs.Proposer))
+	fmt.Fprintf(w, "Prevotes:\t%s\n", cs.Prevotes)
+	fmt.Fprintf(w, "Precommits:\t%s\n", cs.Precommits)
+	w.Flush()
+	return b.String()
+}
+
+// parseRoundState parses the round state returned by the consensus_state or dump_consensus_state RPC methods.
+// Their JSON is loosely typed, and varies across Tendermint and CometBFT versions:
+// the height, round, and step are either a single "height/round/step" string or separate fields,
+// numbers are either JSON numbers or strings, and the step is either a number or a name such as "RoundStepPropose".
+// Fields that cannot be read are left unset rather than failing.
+func parseRoundState(raw json.RawMessage) (consensusState, error) {
+	var rs map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rs); err != nil {
+		return consensusState{}, err
+	}
+	// dump_consensus_state nests the round state.
+	if nested, ok := rs["round_state"]; ok {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			rs = inner
+		}
+	}
+
+	var cs consensusState
+	if hrs, ok := looseString(rs["height/round/step"]); ok {
+		parts := strings.Split(hrs, "/")
+		if len(parts) != 3 {
+			return consensusState{}, fmt.Errorf("invalid height/round/step %q", hrs)
+		}
+		var err error
+		if cs.Height, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return consensusState{}, fmt.Errorf("invalid height/round/step %q: %w", hrs, err)
+		}
+		if cs.Round, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return consensusState{}, fmt.Errorf("invalid height/round/step %q: %w", hrs, err)
+		}
+		cs.Step = roundStepName(parts[2])
+	} else {
+		height, ok := looseInt(rs["height"])
+		if !ok {
+			return consensusState{}, fmt.Errorf("no height in the round state")
+		}
+		cs.Height = height
+		cs.Round, _ = looseInt(rs["round"])
+		step, _ := looseString(rs["step"])
+		cs.Step = roundStepName(step)
+	}
+
+	if s, ok := looseString(rs["start_time"]); ok {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			cs.StartTime = &t
+		}
+	}
+	var proposer struct {
+		Address string `json:"address"`
+	}
+	if json.Unmarshal(rs["proposer"], &proposer) == nil {
+		cs.Proposer = proposer.Address
+	}
+
+	// The votes are in height_vote_set, or in votes for dump_consensus_state, with a vote set per round.
+	votes, ok := rs["height_vote_set"]
+	if !ok {
+		votes = rs["votes"]
+	}
+	var sets []map[string]json.RawMessage
+	if json.Unmarshal(votes, &sets) == nil && len(sets) > 0 {
+		set := sets[len(sets)-1]
+		for _, s := range sets {
+			if r, ok := looseInt(s["round"]); ok && r == cs.Round {
+				set = s
+				break
+			}
+		}
+		cs.Prevotes = parseVoteSet(set, "prevotes")
+		cs.Precommits = parseVoteSet(set, "precommits")
+	}
+	return cs, nil
+}
+
+// roundStepName returns the name of a round step given as a number or as a name, without its "RoundStep" prefix.
+func roundStepName(step string) string {
+	if n, err := strconv.ParseInt(step, 10, 64); err == nil {
+		if name, ok := roundStepNames[n]; ok {
+			return name
+		}
+	}
+	return strings.TrimPrefix(step, "RoundStep")
+}
+
+// bitArrayRE matches the summary of a vote set, as in "BA{4:xx_x} 30/40 = 0.75",
+// where the voting power that voted and the total power may be missing.
+var bitArrayRE = regexp.MustCompile(`BA\{(\d+):([x_]*)\}(?:\s*(\d+)/(\d+))?`)
+
+// parseVoteSet returns the participation in the votes of a vote set of the given kind, prevotes or precommits,
+// from the summary of its bit array, or else from its list of votes, in which validators that did not vote are "nil-Vote".
+func parseVoteSet(set map[string]json.RawMessage, kind string) *voteParticipation {
+	if summary, ok := looseString(set[kind+"_bit_array"]); ok {
+		if m := bitArrayRE.FindStringSubmatch(summary); m != nil {
+			var v voteParticipation
+			v.Validators, _ = strconv.Atoi(m[1])
+			v.Voted = strings.Count(m[2], "x")
+			if m[3] != "" {
+				v.VotedPower, _ = strconv.ParseInt(m[3], 10, 64)
+				v.TotalPower, _ = strconv.ParseInt(m[4], 10, 64)
+			}
+			v.Percent = participationPercent(v)
+			return &v
+		}
+	}
+
+	var votes []string
+	if err := json.Unmarshal(set[kind], &votes); err != nil || len(votes) == 0 {
+		return nil
+	}
+	v := voteParticipation{Validators: len(votes)}
+	for _, vote := range votes {
+		if vote != "nil-Vote" {
+			v.Voted++
+		}
+	}
+	v.Percent = participationPercent(v)
+	return &v
+}
+
+// participationPercent returns the share of the voting power that voted, or else of the validators, as a percentage.
+func participationPercent(v voteParticipation) float64 {
+	if v.TotalPower > 0 {
+		return float64(v.VotedPower) * 100 / float64(v.TotalPower)
+	}
+	if v.Validators > 0 {
+		return float64(v.Voted) * 100 / float64(v.Validators)
+	}
+	return 0
+}
+
+// looseString returns the value of raw, a JSON string or number, as a string.
+func looseString(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String(), true
+	}
+	return "", false
+}
+
+// looseInt returns the value of raw, a JSON number or a string holding one, as an integer.
+func looseInt(raw json.RawMessage) (int64, bool) {
+	s, ok := looseString(raw)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+func nodeSyncingCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "syncing [chain-name]",
+		Short: "query whether a node is catching up, with its earliest and latest blocks",
+		Long: `Query whether the node serving the RPC endpoint of the given chain, or of the default chain,
+is catching up with the chain, with the heights and times of the earliest block it stores and of its latest block.
+The earliest height is above 1 on pruned nodes and on nodes started from a snapshot.`,
+		Example: fmt.Sprintf(`$ %s query node syncing cosmoshub
+$ %s q node syncing -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, _ := txArgs(a, args, 0)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			res, err := cl.RPCClient.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			si := res.SyncInfo
+			return writeOutput(cmd, a, nodeSyncing{
+				CatchingUp:     si.CatchingUp,
+				EarliestHeight: si.EarliestBlockHeight,
+				EarliestTime:   si.EarliestBlockTime,
+				LatestHeight:   si.LatestBlockHeight,
+				LatestTime:     si.LatestBlockTime,
+			})
+		},
+	}
+	return cmd
+}
+
+// nodeSyncing is the result of query node syncing.
+type nodeSyncing struct {
+	CatchingUp     bool      `json:"catching_up"`
+	EarliestHeight int64     `json:"earliest_height"`
+	EarliestTime   time.Time `json:"earliest_time"`
+	LatestHeight   int64     `json:"latest_height"`
+	LatestTime     time.Time `json:"latest_time"`
+}
+
+var _ fmt.Stringer = nodeSyncing{}
+
+func (s nodeSyncing) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Catching up:\t%t\n", s.CatchingUp)
+	fmt.Fprintf(w, "Earliest block:\t%d at %s\n", s.EarliestHeight, formatProposalTime(s.EarliestTime))
+	fmt.Fprintf(w, "Latest block:\t%d at %s\n", s.LatestHeight, formatProposalTime(s.LatestTime))
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodePeersAndNetInfo(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mc.On("NetInfo", mock.Anything).Return(&coretypes.ResultNetInfo{
+		Listening: true,
+		Listeners: []string{"Listener(@tcp://0.0.0.0:26656)"},
+		NPeers:    2,
+		Peers: []coretypes.Peer{
+			{
+				NodeInfo:         p2p.DefaultNodeInfo{DefaultNodeID: "aaaa", ListenAddr: "tcp://0.0.0.0:26656", Moniker: "alpha"},
+				IsOutbound:       true,
+				ConnectionStatus: p2p.ConnectionStatus{Duration: 90 * time.Minute},
+				RemoteIP:         "1.2.3.4",
+			},
+			{
+				NodeInfo:         p2p.DefaultNodeInfo{DefaultNodeID: "bbbb", ListenAddr: "0.0.0.0:36656"},
+				ConnectionStatus: p2p.ConnectionStatus{Duration: 5 * time.Second},
+				RemoteIP:         "5.6.7.8",
+			},
+		},
+	}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "node", "peers", "cosmoshub", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"ID", "ADDRESS", "MONIKER", "DIRECTION", "DURATION"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"aaaa", "1.2.3.4:26656", "alpha", "outbound", "1h30m0s"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"bbbb", "5.6.7.8:36656", "-", "inbound", "5s"}, strings.Fields(lines[2]))
+
+	res = sys.MustRun(t, "query", "node", "peers", "-o", "json")
+	var peers []struct {
+		ID         string
+		IsOutbound bool `json:"is_outbound"`
+		Duration   time.Duration
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &peers))
+	require.Len(t, peers, 2)
+	require.True(t, peers[0].IsOutbound)
+	require.Equal(t, 5*time.Second, peers[1].Duration)
+
+	res = sys.MustRun(t, "query", "node", "net-info", "-o", "json")
+	require.JSONEq(t, `{
+		"listening": true,
+		"listeners": ["Listener(@tcp://0.0.0.0:26656)"],
+		"n_peers": 2,
+		"inbound": 1,
+		"outbound": 1
+	}`, res.Stdout.String())
+	res = sys.MustRun(t, "query", "node", "net-info", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "2 (1 inbound, 1 outbound)")
+}
+
+func TestNodeConsensusState(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		roundState string
+		want       string
+	}{
+		{
+			name: "consensus_state",
+			roundState: `{
+				"height/round/step": "120/1/6",
+				"start_time": "2026-01-01T00:00:00.5Z",
+				"proposer": {"address": "ABCD", "index": 0},
+				"height_vote_set": [
+					{"round": 0, "prevotes": ["nil-Vote"], "prevotes_bit_array": "BA{4:____} 0/40 = 0.00", "precommits": [], "precommits_bit_array": "BA{4:____} 0/40 = 0.00"},
+					{"round": 1, "prevotes": [], "prevotes_bit_array": "BA{4:xxx_} 30/40 = 0.75", "precommits": [], "precommits_bit_array": "BA{4:x___} 25/40 = 0.62"}
+				]
+			}`,
+			want: `{
+				"height": 120, "round": 1, "step": "Precommit", "start_time": "2026-01-01T00:00:00.5Z", "proposer": "ABCD",
+				"prevotes": {"validators": 4, "voted": 3, "voted_power": 30, "total_power": 40, "percent": 75},
+				"precommits": {"validators": 4, "voted": 1, "voted_power": 25, "total_power": 40, "percent": 62.5}
+			}`,
+		},
+		{
+			// Older and dumped round states have separate fields, loosely typed, and may lack the voting power.
+			name: "dump_consensus_state",
+			roundState: `{"round_state": {
+				"height": "120", "round": "0", "step": "RoundStepPrevote",
+				"votes": [{"round": "0", "prevotes": ["Vote{...}", "nil-Vote"], "precommits": ["nil-Vote", "nil-Vote"]}]
+			}}`,
+			want: `{
+				"height": 120, "round": 0, "step": "Prevote",
+				"prevotes": {"validators": 2, "voted": 1, "percent": 50},
+				"precommits": {"validators": 2, "voted": 0, "percent": 0}
+			}`,
+		},
+		{
+			name:       "no votes",
+			roundState: `{"height": 7, "round": 0, "step": 1}`,
+			want:       `{"height": 7, "round": 0, "step": "NewHeight", "prevotes": null, "precommits": null}`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sys := NewSystem(t)
+			mc := new(mocks.Client)
+			mc.On("ConsensusState", mock.Anything).Return(&coretypes.ResultConsensusState{RoundState: json.RawMessage(tc.roundState)}, nil)
+			sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+				RPCClient: mc,
+			})
+
+			res := sys.MustRun(t, "query", "node", "consensus-state", "-o", "json")
+			require.JSONEq(t, tc.want, res.Stdout.String())
+		})
+	}
+
+	sys := NewSystem(t)
+	mc := new(mocks.Client)
+	mc.On("ConsensusState", mock.Anything).Return(&coretypes.ResultConsensusState{RoundState: json.RawMessage(`{
+		"height/round/step": "120/1/6",
+		"height_vote_set": [{"round": 1, "prevotes_bit_array": "BA{4:xxx_} 30/40 = 0.75", "precommits_bit_array": "BA{4:x___} 25/40 = 0.62"}]
+	}`)}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res := sys.MustRun(t, "query", "node", "cs", "cosmoshub", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Prevotes:    75.00% (3/4 validators)\n")
+	require.Contains(t, res.Stdout.String(), "Precommits:  62.50% (1/4 validators)\n")
+}
+
+func TestNodeSyncing(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	earliest := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mc := new(mocks.Client)
+	mc.On("Status", mock.Anything).Return(&coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{
+		EarliestBlockHeight: 1000,
+		EarliestBlockTime:   earliest,
+		LatestBlockHeight:   5000,
+		LatestBlockTime:     latest,
+		CatchingUp:          true,
+	}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "node", "syncing", "-o", "text")
+	require.Equal(t, "Catching up:     true\nEarliest block:  1000 at 2025-01-01T00:00:00Z\nLatest block:    5000 at 2026-01-01T00:00:00Z\n", res.Stdout.String())
+	res = sys.MustRun(t, "query", "node", "syncing", "cosmoshub", "-o", "json")
+	require.JSONEq(t, `{
+		"catching_up": true,
+		"earliest_height": 1000,
+		"earliest_time": "2025-01-01T00:00:00Z",
+		"latest_height": 5000,
+		"latest_time": "2026-01-01T00:00:00Z"
+	}`, res.Stdout.String())
+}
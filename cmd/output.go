@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"google.golang.org/protobuf/encoding/protowire"
+	"sigs.k8s.io/yaml"
+)
+
+// Formats accepted by the --output flag.
+const (
+	outputText       = "text"
+	outputJSON       = "json"
+	outputJSONIndent = "json-indent"
+	outputYAML       = "yaml"
+
+	// outputIndent is the original name of outputJSONIndent,
+	// still accepted because it may be set as a chain's output-format.
+	outputIndent = "indent"
+)
+
+// validateOutputFormat returns an error if format is not a recognized --output value.
+// The empty string, meaning no format was selected, is valid.
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", outputText, outputJSON, outputJSONIndent, outputIndent, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown output format %q (must be one of %s)",
+			format,
+			strings.Join([]string{outputText, outputJSON, outputJSONIndent, outputYAML}, ", "),
+		)
+	}
+}
+
+// defaultOutputFormat returns the output format of cmd when --output is not set:
+// the text format for the dynamic commands, whose targets need not be configured chains,
+// and otherwise the output-format of the chain in use, or compact JSON if it has none.
+func defaultOutputFormat(cmd *cobra.Command, a *appState) string {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Parent().Name() == appName && c.Name() == "dynamic" {
+			return ""
+		}
+	}
+	if c, ok := a.Config.Chains[a.Config.DefaultChain]; ok && c.OutputFormat != "" {
+		return c.OutputFormat
+	}
+	return outputJSON
+}
+
+// writeOutput writes v to the command's output, in the format selected by --output,
+// or else by defaultOutputFormat.
+// If no format was selected, the text format is used.
+//
+// In the text format, a json.RawMessage is written as is,
+// a []string is written one element per line,
+// a fmt.Stringer is written as its String value,
+// and anything else is written as indented JSON.
+// The other formats write the JSON serialization of v, or its YAML equivalent.
+func writeOutput(cmd *cobra.Command, a *appState, v interface{}) error {
+	w := cmd.OutOrStdout()
+
+	switch a.OutputFormat {
+	case "", outputText:
+		return writeText(w, v)
+
+	case outputJSON:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+
+	case outputJSONIndent, outputIndent:
+		return writeJSON(w, v)
+
+	case outputYAML:
+		// Go through JSON first, so that types with custom JSON serialization,
+		// such as json.RawMessage, are represented correctly.
+		j, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		y, err := yaml.JSONToYAML(j)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+
+	default:
+		return validateOutputFormat(a.OutputFormat)
+	}
+}
+
+// writeTextOr writes text to the command's output in the text format,
+// and v in the other formats selected by --output.
+// It is used by commands whose text output summarizes a structure that is otherwise written as is.
+func writeTextOr(cmd *cobra.Command, a *appState, text fmt.Stringer, v interface{}) error {
+	if a.OutputFormat == "" || a.OutputFormat == outputText {
+		return writeText(cmd.OutOrStdout(), text)
+	}
+	return writeOutput(cmd, a, v)
+}
+
+func writeText(w io.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case json.RawMessage:
+		// Checked before fmt.Stringer, which newer versions of json.RawMessage implement.
+		_, err := fmt.Fprintln(w, string(v))
+		return err
+	case []string:
+		for _, s := range v {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fmt.Stringer:
+		_, err := io.WriteString(w, v.String())
+		return err
+	default:
+		return writeJSON(w, v)
+	}
+}
+
+// codecJSON returns msg for writeOutput, serialized with the JSON codec of cl as the chain's REST API does,
+// rather than with encoding/json, which writes its packed values as bytes and its enums as numbers.
+func codecJSON(cl *client.ChainClient, msg proto.Message) json.Marshaler {
+	return codecJSONMessage{cdc: cl.Codec.Marshaler, msg: msg}
+}
+
+type codecJSONMessage struct {
+	cdc codec.JSONCodec
+	msg proto.Message
+}
+
+func (m codecJSONMessage) MarshalJSON() ([]byte, error) {
+	return m.cdc.MarshalJSON(m.msg)
+}
+
+// decodedAny is a packed value for output.
+// Value holds the value decoded as JSON, if its type is known to the codec;
+// otherwise Payload holds the encoded value.
+type decodedAny struct {
+	Type    string          `json:"@type"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Payload []byte          `json:"payload,omitempty"`
+}
+
+// decodeAny decodes any with the codec of cl, as an implementation of the interface iface points to,
+// which is set to the decoded value.
+// It reports whether the value was decoded; if not, its type and payload are returned as is,
+// so that values of types unknown to the codec can still be shown.
+func decodeAny(cl *client.ChainClient, any *codectypes.Any, iface interface{}) (decodedAny, bool) {
+	if any == nil {
+		return decodedAny{}, false
+	}
+	undecoded := decodedAny{Type: any.TypeUrl, Payload: any.Value}
+
+	if err := cl.Codec.InterfaceRegistry.UnpackAny(any, iface); err != nil {
+		return undecoded, false
+	}
+	msg, ok := any.GetCachedValue().(codec.ProtoMarshaler)
+	if !ok {
+		return undecoded, true
+	}
+	value, err := cl.Codec.Marshaler.MarshalJSON(msg)
+	if err != nil {
+		return undecoded, true
+	}
+	return decodedAny{Type: any.TypeUrl, Value: value}, true
+}
+
+// writeBody writes the value to w in the text format, as indented JSON if it was decoded,
+// or else as its base64 encoded payload on a line labeled with name.
+func (d decodedAny) writeBody(w io.Writer, name string) {
+	switch {
+	case d.Value != nil:
+		if err := writeJSON(w, d.Value); err != nil {
+			fmt.Fprintln(w, string(d.Value))
+		}
+	case d.Payload != nil:
+		fmt.Fprintf(w, "%s (base64): %s\n", name, base64.StdEncoding.EncodeToString(d.Payload))
+	}
+}
+
+// decodeRawProto decodes bz, the encoding of a protobuf message of unknown type, as protoc --decode_raw does.
+// The fields are keyed by their numbers; repeated fields hold a list of their values.
+// Length-delimited values are decoded as nested messages if possible, and otherwise as strings if they are valid UTF-8,
+// or else left as bytes.
+func decodeRawProto(bz []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(bz)
+		case protowire.Fixed32Type:
+			v, n = protowire.ConsumeFixed32(bz)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(bz)
+		case protowire.BytesType:
+			var b []byte
+			b, n = protowire.ConsumeBytes(bz)
+			if nested, err := decodeRawProto(b); err == nil && len(nested) > 0 {
+				v = nested
+			} else if utf8.Valid(b) {
+				v = string(b)
+			} else {
+				v = b
+			}
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d of field %d", typ, num)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		key := strconv.Itoa(int(num))
+		switch prev := fields[key].(type) {
+		case nil:
+			fields[key] = v
+		case []interface{}:
+			fields[key] = append(prev, v)
+		default:
+			fields[key] = []interface{}{prev, v}
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	tmquery "github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const (
+	allPagesFlag = "all"
+	maxPagesFlag = "max-pages"
+
+	// defaultPageLimit is how many results are requested per page by default.
+	defaultPageLimit = 100
+	// defaultMaxPages is how many pages are read at most by default when following every page of results.
+	defaultMaxPages = 1000
+)
+
+// paginationHelp describes the flags added by addPaginationFlags, for the long help of the commands.
+const paginationHelp = `Every page of results is requested in turn, up to --max-pages pages.
+With --limit, --page-key, or --offset, a single page is requested instead, unless --all is set;
+when results are left, text output ends with the --page-key flag requesting the next page.`
+
+// addPaginationFlags adds the flags paging through the results of cmd, which lists noun.
+func addPaginationFlags(cmd *cobra.Command, noun string) {
+	cmd.Flags().Uint64(flags.FlagLimit, defaultPageLimit, fmt.Sprintf("number of %s to request per page", noun))
+	cmd.Flags().String(flags.FlagPageKey, "", "base64 key of the page to request, as printed after truncated output")
+	cmd.Flags().Uint64(flags.FlagOffset, 0, fmt.Sprintf("number of %s to skip before the page (not with --%s)", noun, flags.FlagPageKey))
+	cmd.Flags().Bool(flags.FlagCountTotal, false, fmt.Sprintf("count the total number of %s", noun))
+	cmd.Flags().Bool(flags.FlagReverse, false, fmt.Sprintf("list the %s in reverse order", noun))
+	cmd.Flags().Bool(allPagesFlag, false, fmt.Sprintf("request every page of %s, even with --%s, --%s, or --%s", noun, flags.FlagLimit, flags.FlagPageKey, flags.FlagOffset))
+	cmd.Flags().Int(maxPagesFlag, defaultMaxPages, "most pages to request when requesting every page")
+}
+
+// pagination is the paging through results set by the flags of addPaginationFlags.
+type pagination struct {
+	request tmquery.PageRequest
+
+	// all is whether every page is requested, up to maxPages, rather than a single one.
+	all      bool
+	maxPages int
+}
+
+// paginationFromFlags returns the pagination set by the flags of cmd.
+func paginationFromFlags(cmd *cobra.Command) (*pagination, error) {
+	f := cmd.Flags()
+	var p pagination
+	var err error
+	if p.request.Limit, err = f.GetUint64(flags.FlagLimit); err != nil {
+		return nil, err
+	}
+	if p.request.Offset, err = f.GetUint64(flags.FlagOffset); err != nil {
+		return nil, err
+	}
+	if p.request.CountTotal, err = f.GetBool(flags.FlagCountTotal); err != nil {
+		return nil, err
+	}
+	if p.request.Reverse, err = f.GetBool(flags.FlagReverse); err != nil {
+		return nil, err
+	}
+	if p.all, err = f.GetBool(allPagesFlag); err != nil {
+		return nil, err
+	}
+	if p.maxPages, err = f.GetInt(maxPagesFlag); err != nil {
+		return nil, err
+	}
+	pageKey, err := f.GetString(flags.FlagPageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.request.Limit == 0 {
+		return nil, fmt.Errorf("--%s must be positive", flags.FlagLimit)
+	}
+	if p.maxPages < 1 {
+		return nil, fmt.Errorf("--%s must be positive", maxPagesFlag)
+	}
+	if pageKey != "" {
+		if p.request.Offset > 0 {
+			return nil, fmt.Errorf("cannot use both --%s and --%s", flags.FlagPageKey, flags.FlagOffset)
+		}
+		if p.request.Key, err = base64.StdEncoding.DecodeString(pageKey); err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: %w", flags.FlagPageKey, pageKey, err)
+		}
+	}
+
+	// Without any of the flags selecting a page, every page is requested.
+	if !f.Changed(flags.FlagLimit) && !f.Changed(flags.FlagPageKey) && !f.Changed(flags.FlagOffset) {
+		p.all = true
+	}
+	return &p, nil
+}
+
+// queryOptions returns the options of the queries following every page of results,
+// reading a single one unless every page is requested.
+func (p *pagination) queryOptions(height int64) *query.QueryOptions {
+	pr := p.request
+	maxPages := 1
+	if p.all {
+		maxPages = p.maxPages
+	}
+	return &query.QueryOptions{Pagination: &pr, Height: height, MaxPages: maxPages}
+}
+
+// pagedQueryOptions returns the query options set by the height and pagination flags of cmd.
+func pagedQueryOptions(cmd *cobra.Command) (*pagination, *query.QueryOptions, error) {
+	p, err := paginationFromFlags(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	height, err := ReadHeight(cmd.Flags())
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, p.queryOptions(height), nil
+}
+
+// logNextPage logs the key of the next page of results, if results were left after the pages read:
+// as a warning if every page was requested, as --max-pages stopped the query before the last one.
+func (p *pagination) logNextPage(a *appState, pages query.PageInfo) {
+	if len(pages.NextKey) == 0 {
+		return
+	}
+	key := zap.String("next_page_key", base64.StdEncoding.EncodeToString(pages.NextKey))
+	if p.all {
+		a.Log.Warn("Stopped requesting pages at --max-pages; the results are incomplete", zap.Int("pages", pages.Read), key)
+		return
+	}
+	a.Log.Info("More results are available", key)
+}
+
+// writeOutput writes v like writeOutput, followed in text output by the total number of results if counted,
+// and by the flag requesting the next page of results if results were left after the pages read.
+// Other output keeps its shape, so those are logged instead.
+func (p *pagination) writeOutput(cmd *cobra.Command, a *appState, v interface{}, pages query.PageInfo) error {
+	text := a.OutputFormat == "" || a.OutputFormat == outputText
+	if !text || p.all {
+		p.logNextPage(a, pages)
+	}
+	if err := writeOutput(cmd, a, v); err != nil {
+		return err
+	}
+
+	if !text {
+		if p.request.CountTotal {
+			a.Log.Info("Counted the results", zap.Uint64("total", pages.Total))
+		}
+		return nil
+	}
+	w := cmd.OutOrStdout()
+	if p.request.CountTotal {
+		if _, err := fmt.Fprintf(w, "Total results: %d\n", pages.Total); err != nil {
+			return err
+		}
+	}
+	if len(pages.NextKey) > 0 {
+		if _, err := fmt.Fprintf(w, "More results: --%s %s\n", flags.FlagPageKey, base64.StdEncoding.EncodeToString(pages.NextKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
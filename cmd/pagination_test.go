@@ -0,0 +1,65 @@
+package cmd_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPagination(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockGovProposals(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	ids := func(out string) []string {
+		var ids []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			ids = append(ids, strings.Fields(line)[0])
+		}
+		return ids
+	}
+	nextKey := base64.StdEncoding.EncodeToString([]byte("next"))
+
+	// A single page is requested with --limit, and text output ends with the key of the next one.
+	res := sys.MustRun(t, "query", "gov", "proposals", "--limit", "1", "-o", "text")
+	require.Equal(t, []string{"1", "More"}, ids(res.Stdout.String()))
+	require.Contains(t, res.Stdout.String(), "More results: --page-key "+nextKey+"\n")
+
+	res = sys.MustRun(t, "query", "gov", "proposals", "--page-key", nextKey, "-o", "text")
+	require.Equal(t, []string{"2"}, ids(res.Stdout.String()))
+	require.NotContains(t, res.Stdout.String(), "More results")
+
+	// --all follows every page whatever the page flags, up to --max-pages.
+	res = sys.MustRun(t, "query", "gov", "proposals", "--limit", "1", "--all", "-o", "text")
+	require.Equal(t, []string{"1", "2"}, ids(res.Stdout.String()))
+	res = sys.MustRun(t, "query", "gov", "proposals", "--max-pages", "1", "-o", "text")
+	require.Equal(t, []string{"1", "More"}, ids(res.Stdout.String()))
+
+	// Other output keeps its shape.
+	res = sys.MustRun(t, "query", "gov", "proposals", "--limit", "1", "-o", "json")
+	var proposals []struct{ ID uint64 }
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &proposals))
+	require.Len(t, proposals, 1)
+
+	for flags, want := range map[string]string{
+		"--page-key " + nextKey + " --offset 5": "cannot use both --page-key and --offset",
+		"--page-key not-base64!":                `invalid --page-key "not-base64!"`,
+		"--max-pages 0":                         "--max-pages must be positive",
+		"--limit 0":                             "--limit must be positive",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"query", "gov", "proposals"}, strings.Fields(flags)...)...)
+		require.ErrorContains(t, res.Err, want, flags)
+	}
+}
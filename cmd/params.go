@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const paramsDiffFlag = "diff"
+
+func queryParamsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "params CHAIN_NAME_OR_GRPC_ADDR [module]",
+		Short: "query the params of every module of a chain, or compare them with those of another chain",
+		Long: `Query the params of every module of a chain, or of the given module, and print them keyed by module.
+
+The modules are discovered with gRPC reflection: every Query service with a Params method is called,
+and its params are keyed by the name of its package without its version, such as staking for cosmos.staking.v1beta1,
+or by its whole package when several share a name. A module is given by that name, or by its package.
+Services whose Params call fails, as for modules that are not enabled, are skipped with a note.
+
+With --diff, the params of the chain are compared with those of another chain, or gRPC address,
+and only the params whose values differ are printed, with their value on each chain.
+The params of nested objects are compared one by one, named by their path, such as mint.inflation_max.
+Identical params produce no output; if any differ, the command exits with status 2.`,
+		Example: fmt.Sprintf(`$ %s query params cosmoshub
+$ %s query params cosmoshub staking -o yaml
+$ %s query params cosmoshub-testnet --diff cosmoshub
+$ %s query params localhost:9090 gov --diff cosmoshub --height 15000000`,
+			appName, appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			module := ""
+			if len(args) == 2 {
+				module = args[1]
+			}
+			other, err := cmd.Flags().GetString(paramsDiffFlag)
+			if err != nil {
+				return err
+			}
+
+			snapshot, err := queryParamsSnapshot(cmd, a, args[0], module)
+			if err != nil {
+				return err
+			}
+			if other == "" {
+				return writeOutput(cmd, a, snapshot)
+			}
+
+			otherSnapshot, err := queryParamsSnapshot(cmd, a, other, module)
+			if err != nil {
+				return err
+			}
+			diff := paramsDiff{Chains: [2]string{args[0], other}, Params: compareParams(snapshot, otherSnapshot)}
+			if len(diff.Params) == 0 {
+				return nil
+			}
+			if err := writeOutput(cmd, a, diff); err != nil {
+				return err
+			}
+			return ParamsDiffError{Params: len(diff.Params)}
+		},
+	}
+	cmd.Flags().String(paramsDiffFlag, "", "compare the params with those of this chain or gRPC address, printing only those that differ")
+	return withDefaultChainArg(a, gRPCFlags(cmd, a.Viper))
+}
+
+// paramsSnapshot holds the params of the modules of a chain, keyed by module name.
+type paramsSnapshot map[string]json.RawMessage
+
+// paramsService is a Query service with a Params method.
+type paramsService struct {
+	// pkg is the package of the service, and name that of the package without its version.
+	pkg, name string
+	method    *desc.MethodDescriptor
+}
+
+// protoVersionRegexp matches the version components of protobuf packages, such as v1 or v1beta1.
+var protoVersionRegexp = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+
+// newParamsService returns the Params method of the Query service svcDesc, if it has one.
+func newParamsService(svcDesc *desc.ServiceDescriptor) (paramsService, bool) {
+	m := svcDesc.FindMethodByName("Params")
+	if m == nil || m.IsClientStreaming() || m.IsServerStreaming() {
+		return paramsService{}, false
+	}
+	pkg := svcDesc.GetFile().GetPackage()
+	parts := strings.Split(pkg, ".")
+	for len(parts) > 1 && protoVersionRegexp.MatchString(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	return paramsService{pkg: pkg, name: parts[len(parts)-1], method: m}, true
+}
+
+// matches reports whether module names the service, by name or package, with or without its version.
+func (s paramsService) matches(module string) bool {
+	return module == s.name || module == s.pkg || strings.HasPrefix(s.pkg, module+".")
+}
+
+// queryParamsSnapshot returns the params of the modules of the chain or gRPC address addrOrChainName,
+// or only those of module if it is set.
+func queryParamsSnapshot(cmd *cobra.Command, a *appState, addrOrChainName, module string) (paramsSnapshot, error) {
+	gRPCAddr, err := chooseGRPCAddr(cmd, a, addrOrChainName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+
+	c, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		return nil, err
+	}
+	svcDescs, err := resolveQueryServices(a, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var services, matched []paramsService
+	for _, svcDesc := range svcDescs {
+		if s, ok := newParamsService(svcDesc); ok {
+			services = append(services, s)
+			if module == "" || s.matches(module) {
+				matched = append(matched, s)
+			}
+		}
+	}
+	switch {
+	case len(services) == 0:
+		return nil, fmt.Errorf("no Query service with a Params method found on %s", addrOrChainName)
+	case len(matched) == 0:
+		var names []string
+		for _, s := range services {
+			if !stringsContain(names, s.name) {
+				names = append(names, s.name)
+			}
+		}
+		return nil, fmt.Errorf("no module %q with params on %s (modules: %s)", module, addrOrChainName, strings.Join(names, ", "))
+	}
+
+	var called []paramsService
+	results := make(map[string]json.RawMessage, len(matched))
+	for _, s := range matched {
+		a.Log.Debug("Querying params", zap.String("addr", gRPCAddr), zap.String("method", s.method.GetFullyQualifiedName()))
+		j, err := invokeDynamic(cmd.Context(), conn, c, s.method, []byte("{}"))
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Note: skipped %s, whose Params call failed on %s: %v\n", s.pkg, addrOrChainName, err)
+			continue
+		}
+		called = append(called, s)
+		results[s.pkg] = unwrapParams(j)
+	}
+
+	// The services are keyed by the name of their module, unless several share it.
+	count := make(map[string]int, len(called))
+	for _, s := range called {
+		count[s.name]++
+	}
+	snapshot := make(paramsSnapshot, len(called))
+	for _, s := range called {
+		key := s.name
+		if count[s.name] > 1 {
+			key = s.pkg
+		}
+		snapshot[key] = results[s.pkg]
+	}
+	return snapshot, nil
+}
+
+// unwrapParams returns the params field of the Params response j, if it is its only field, or else j.
+func unwrapParams(j []byte) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(j, &fields); err == nil && len(fields) == 1 && fields["params"] != nil {
+		return fields["params"]
+	}
+	return j
+}
+
+// paramsDiff is the result of comparing the params of two chains.
+type paramsDiff struct {
+	// Chains are the compared chains or gRPC addresses, in the order of Values.
+	Chains [2]string   `json:"chains"`
+	Params []paramDiff `json:"params"`
+}
+
+// paramDiff is a param whose values differ between two chains.
+type paramDiff struct {
+	// Param is the path of the param, its module followed by its fields, separated by dots.
+	Param string `json:"param"`
+	// Values are the values of the param on each chain, null on a chain without it.
+	Values [2]json.RawMessage `json:"values"`
+}
+
+var _ fmt.Stringer = paramsDiff{}
+
+// String returns a table of the params that differ, with their value on each chain.
+func (d paramsDiff) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "PARAM\t%s\t%s\n", strings.ToUpper(d.Chains[0]), strings.ToUpper(d.Chains[1]))
+	for _, p := range d.Params {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Param, paramValueText(p.Values[0]), paramValueText(p.Values[1]))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// paramValueText returns the value v, without the quotes of a string, or a dash if there is none.
+func paramValueText(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return orDash(s)
+	}
+	if v == nil {
+		return "-"
+	}
+	return string(v)
+}
+
+// compareParams returns the params whose values differ between a and b, sorted by path.
+func compareParams(a, b paramsSnapshot) []paramDiff {
+	var diffs []paramDiff
+	for _, module := range unionKeys(a, b) {
+		for _, d := range compareJSON(module, decodeJSONValue(a[module]), decodeJSONValue(b[module])) {
+			diffs = append(diffs, paramDiff{Param: d.Path, Values: d.Values})
+		}
+	}
+	return diffs
+}
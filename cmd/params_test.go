@@ -0,0 +1,157 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc/builder"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// runParamsServer runs a server whose reflection service describes a Query service with a Params method
+// in each package of params, answering with the params, given as JSON, or failing if they are empty.
+func runParamsServer(t *testing.T, params map[string]string) string {
+	t.Helper()
+
+	local := new(protoregistry.Files)
+	services := make(staticServices)
+	srv := grpc.NewServer()
+	for pkg, p := range params {
+		limits := builder.NewMessage("Limits").
+			AddField(builder.NewField("rate", builder.FieldTypeString()))
+		paramsMsg := builder.NewMessage("Params").
+			AddField(builder.NewField("denom", builder.FieldTypeString())).
+			AddField(builder.NewField("max", builder.FieldTypeUInt32())).
+			AddField(builder.NewField("limits", builder.FieldTypeMessage(limits)))
+		req := builder.NewMessage("QueryParamsRequest")
+		res := builder.NewMessage("QueryParamsResponse").
+			AddField(builder.NewField("params", builder.FieldTypeMessage(paramsMsg)))
+		svc := builder.NewService("Query").
+			AddMethod(builder.NewMethod("Params", builder.RpcTypeMessage(req, false), builder.RpcTypeMessage(res, false)))
+		fd, err := builder.NewFile(strings.ReplaceAll(pkg, ".", "/") + "/query.proto").
+			SetPackageName(pkg).
+			SetProto3(true).
+			AddMessage(limits).
+			AddMessage(paramsMsg).
+			AddMessage(req).
+			AddMessage(res).
+			AddService(svc).
+			Build()
+		require.NoError(t, err)
+		file, err := protodesc.NewFile(fd.AsFileDescriptorProto(), protoregistry.GlobalFiles)
+		require.NoError(t, err)
+		require.NoError(t, local.RegisterFile(file))
+
+		reqDesc := file.Messages().ByName("QueryParamsRequest")
+		resDesc := file.Messages().ByName("QueryParamsResponse")
+		p := p
+		name := pkg + ".Query"
+		services[name] = grpc.ServiceInfo{}
+		srv.RegisterService(&grpc.ServiceDesc{
+			ServiceName: name,
+			HandlerType: (*interface{})(nil),
+			Methods: []grpc.MethodDesc{{
+				MethodName: "Params",
+				Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+					if err := dec(dynamicpb.NewMessage(reqDesc)); err != nil {
+						return nil, err
+					}
+					if p == "" {
+						return nil, status.Error(codes.Unimplemented, "module disabled")
+					}
+					res := dynamicpb.NewMessage(resDesc)
+					return res, protojson.Unmarshal([]byte(`{"params": `+p+`}`), res)
+				},
+			}},
+		}, struct{}{})
+	}
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{
+		Services:           services,
+		DescriptorResolver: fallbackResolver{local: local},
+	}))
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+	return ln.Addr().String()
+}
+
+func TestQueryParams(t *testing.T) {
+	t.Parallel()
+
+	mainnet := runParamsServer(t, map[string]string{
+		"test.staking.v1beta1": `{"denom": "uatom", "max": 100, "limits": {"rate": "0.1"}}`,
+		"test.gov.v1":          `{"denom": "uatom", "max": 2}`,
+		"test.gov.v1beta1":     `{"denom": "uatom", "max": 1}`,
+		"test.wasm.v1":         "",
+	})
+	testnet := runParamsServer(t, map[string]string{
+		"test.staking.v1beta1": `{"denom": "uatom", "max": 175, "limits": {"rate": "0.2"}}`,
+		"test.gov.v1":          `{"denom": "uatom", "max": 2}`,
+		"test.gov.v1beta1":     `{"denom": "uatom", "max": 1}`,
+		"test.mint.v1":         `{"denom": "uatom"}`,
+	})
+
+	sys := NewSystem(t)
+
+	// The params are keyed by module, or by package when modules share a name,
+	// and the services whose Params call fails are skipped.
+	res := sys.MustRun(t, "query", "params", mainnet, "-o", "json")
+	var snapshot map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &snapshot))
+	require.Equal(t, map[string]map[string]interface{}{
+		"staking":          {"denom": "uatom", "max": 100.0, "limits": map[string]interface{}{"rate": "0.1"}},
+		"test.gov.v1":      {"denom": "uatom", "max": 2.0},
+		"test.gov.v1beta1": {"denom": "uatom", "max": 1.0},
+	}, snapshot)
+	require.Contains(t, res.Stderr.String(), "Note: skipped test.wasm.v1, whose Params call failed on "+mainnet)
+	require.Contains(t, res.Stderr.String(), "module disabled")
+
+	res = sys.MustRun(t, "query", "params", mainnet, "staking", "-o", "json")
+	require.JSONEq(t, `{"staking": {"denom": "uatom", "max": 100, "limits": {"rate": "0.1"}}}`, res.Stdout.String())
+	res = sys.MustRun(t, "query", "params", mainnet, "test.gov.v1", "-o", "json")
+	require.JSONEq(t, `{"gov": {"denom": "uatom", "max": 2}}`, res.Stdout.String())
+
+	// With --diff, only the params that differ are printed, and the command fails.
+	res = sys.Run(zaptest.NewLogger(t), "query", "params", mainnet, "--diff", testnet, "-o", "text")
+	var diffErr cmd.ParamsDiffError
+	require.ErrorAs(t, res.Err, &diffErr)
+	require.Equal(t, cmd.ParamsDiffError{Params: 3}, diffErr)
+	require.Equal(t, 2, diffErr.ExitCode())
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"PARAM", strings.ToUpper(mainnet), strings.ToUpper(testnet)}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"mint", "-", `{"denom":"uatom"}`}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"staking.limits.rate", "0.1", "0.2"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"staking.max", "100", "175"}, strings.Fields(lines[3]))
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "params", mainnet, "staking", "--diff", testnet, "-o", "json")
+	require.Error(t, res.Err)
+	require.JSONEq(t, `{"chains": [`+strings.Join([]string{`"` + mainnet + `"`, `"` + testnet + `"`}, ",")+`], "params": [
+		{"param": "staking.limits.rate", "values": ["0.1", "0.2"]},
+		{"param": "staking.max", "values": [100, 175]}
+	]}`, res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "params", mainnet, "gov", "--diff", testnet)
+	require.Empty(t, res.Stdout.String())
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "params", mainnet, "bank")
+	require.ErrorContains(t, res.Err, `no module "bank" with params on `+mainnet+" (modules: gov, staking, wasm)")
+}
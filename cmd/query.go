@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+
+	sdkbech32 "github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
 )
 
 // queryCmd represents the query command tree.
@@ -10,24 +14,38 @@ func queryCmd(a *appState) *cobra.Command {
 		Use:     "query",
 		Aliases: []string{"q"},
 		Short:   "query things about a chain",
+		Long: `Query things about a chain, or about several chains at once.
+
+` + multiChainHelp,
+		Example: fmt.Sprintf(`$ %s query bank balances mykey --chains cosmoshub,osmosis,juno
+$ %s q bank balances cosmos1... --all-chains --chain-timeout 5s -o json`, appName, appName),
 	}
 
 	cmd.AddCommand(
+		queryAccountCmd(a),
 		authQueryCmd(a),
 		authzQueryCmd(a),
 		bankQueryCmd(a),
 		distributionQueryCmd(a),
+		feegrantQueryCmd(a),
+		govQueryCmd(a),
+		groupQueryCmd(a),
+		ibcQueryCmd(a),
+		icaQueryCmd(a),
+		wasmQueryCmd(a),
+		nodeQueryCmd(a),
+		slashingQueryCmd(a),
 		stakingQueryCmd(a),
+		upgradeQueryCmd(a),
+		queryTxByHashCmd(a),
+		queryWaitTxCmd(a),
+		queryTxsCmd(a),
+		queryBlockCmd(a),
+		queryBlockResultsCmd(a),
+		queryMempoolCmd(a),
+		queryParamsCmd(a),
 	)
-
-	if false {
-		// TODO: enable these when commands are available
-		cmd.AddCommand(
-			feegrantQueryCmd(),
-			govQueryCmd(),
-			slashingQueryCmd(),
-		)
-	}
+	addMultiChainFlags(a, cmd)
 
 	return cmd
 }
@@ -102,8 +120,26 @@ func distributionQueryCmd(a *appState) *cobra.Command {
 	return cmd
 }
 
+// ibcQueryCmd returns the IBC query commands
+func ibcQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ibc",
+		Short: "Querying commands for the IBC modules",
+	}
+
+	cmd.AddCommand(
+		ibcClientsCmd(a),
+		ibcConnectionsCmd(a),
+		ibcChannelsCmd(a),
+		ibcDenomTraceCmd(a),
+		ibcDenomTracesCmd(a),
+	)
+
+	return cmd
+}
+
 // feegrantQueryCmd returns the fee grant query commands for this module
-func feegrantQueryCmd() *cobra.Command {
+func feegrantQueryCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "feegrant",
 		Aliases: []string{"feegrant"},
@@ -111,15 +147,14 @@ func feegrantQueryCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-	// feegrantGrantsCmd(),
-	// feegrantFeeGrantsCmd(),
+		feegrantGrantsCmd(a),
 	)
 
 	return cmd
 }
 
 // govQueryCmd returns the gov query commands for this module
-func govQueryCmd() *cobra.Command {
+func govQueryCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "governance",
 		Aliases: []string{"gov", "g"},
@@ -127,23 +162,23 @@ func govQueryCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-	// govProposalCmd(),
-	// govProposalsCmd(),
-	// govVoteCmd(),
-	// govVotesCmd(),
-	// govParamCmd(),
-	// govParamsCmd(),
-	// govProposerCmd(),
-	// govDepositCmd(),
-	// govDepositsCmd(),
-	// govTallyCmd(),
+		govProposalCmd(a),
+		govProposalsCmd(a),
+		// govVoteCmd(),
+		// govVotesCmd(),
+		// govParamCmd(),
+		// govParamsCmd(),
+		// govProposerCmd(),
+		// govDepositCmd(),
+		// govDepositsCmd(),
+		// govTallyCmd(),
 	)
 
 	return cmd
 }
 
 // slashingQueryCmd returns the slashing query commands for this module
-func slashingQueryCmd() *cobra.Command {
+func slashingQueryCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "slashing",
 		Aliases: []string{"sl", "slash"},
@@ -151,9 +186,8 @@ func slashingQueryCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-	// slashingSigningInfoCmd(),
-	// slashingParamsCmd(),
-	// slashingSigningInfosCmd(),
+		slashingSigningInfoCmd(a),
+		slashingParamsCmd(a),
 	)
 
 	return cmd
@@ -182,7 +216,70 @@ func stakingQueryCmd(a *appState) *cobra.Command {
 		// stakingHistoricalInfoCmd(),
 		stakingParamsCmd(a),
 		stakingPoolCmd(a),
+		stakingAPRCmd(a),
 	)
 
 	return cmd
 }
+
+// chainAndAddressArgsHelp describes the arguments resolved by chainClientAndAddress, for use in command help.
+const chainAndAddressArgsHelp = `If a single argument is given and it names a configured chain, the query is for that chain's key;
+otherwise it is taken as a key or address on the default chain.`
+
+// chainClientAndAddress resolves the optional [chain-name] [key-or-address] arguments of a query command
+// to the client of the chain, and the encoded address of the key or address.
+// The chain defaults to the default chain, and the key to the chain's configured key.
+func chainClientAndAddress(a *appState, args []string) (*client.ChainClient, string, error) {
+	chainName, keyNameOrAddress := a.Config.DefaultChain, ""
+	switch len(args) {
+	case 1:
+		if _, ok := a.Config.Chains[args[0]]; ok {
+			chainName = args[0]
+		} else {
+			keyNameOrAddress = args[0]
+		}
+	case 2:
+		chainName, keyNameOrAddress = args[0], args[1]
+	}
+
+	cl, err := chainClientByName(a, chainName)
+	if err != nil {
+		return nil, "", err
+	}
+	if keyNameOrAddress == "" {
+		keyNameOrAddress = cl.Config.Key
+	}
+	// Run on several chains, an address of any chain stands for the same account on each of them.
+	if a.MultiChain && !cl.KeyExists(keyNameOrAddress) {
+		if _, bz, err := sdkbech32.DecodeAndConvert(keyNameOrAddress); err == nil {
+			keyNameOrAddress = cl.MustEncodeAccAddr(bz)
+		}
+	}
+
+	address, err := cl.AccountFromKeyOrAddress(keyNameOrAddress)
+	if err != nil {
+		return nil, "", err
+	}
+	return cl, cl.MustEncodeAccAddr(address), nil
+}
+
+// defaultChainClient returns the client of the default chain.
+func defaultChainClient(a *appState) (*client.ChainClient, error) {
+	return chainClientByName(a, a.Config.DefaultChain)
+}
+
+// chainClientByName returns the client of the configured chain chainName.
+// An empty chainName, as when a chain argument is omitted without a default chain, is a NoDefaultChainError.
+func chainClientByName(a *appState, chainName string) (*client.ChainClient, error) {
+	if chainName == "" {
+		return nil, NoDefaultChainError{}
+	}
+	if _, ok := a.Config.Chains[chainName]; !ok {
+		return nil, ChainNotFoundError{Requested: chainName, Config: a.Config}
+	}
+	cl := a.Config.GetClient(chainName)
+	if cl == nil {
+		return nil, fmt.Errorf("chain %s is misconfigured (run `%s config validate`)", chainName, appName)
+	}
+	return cl, nil
+}
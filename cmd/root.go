@@ -17,19 +17,20 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
-	"time"
+	"strconv"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
-	zaplogfmt "github.com/jsternberg/zap-logfmt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	provtypes "github.com/cometbft/cometbft/light/provider"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/strangelove-ventures/lens/client"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"golang.org/x/term"
 )
 
 const appName = "lens"
@@ -43,17 +44,35 @@ type ClientOverrides struct {
 	LightProvider provtypes.Provider
 }
 
+// RootCmdOption customizes the application state of a root command.
+//
+// This should only be set during tests.
+type RootCmdOption func(*appState)
+
+// WithHTTPClient makes commands that fetch remote documents,
+// such as chain registry files, send their requests through c.
+func WithHTTPClient(c *http.Client) RootCmdOption {
+	return func(a *appState) {
+		a.HTTPClient = c
+	}
+}
+
 // NewRootCmd returns the root command for relayer.
 //
 // o is used to override rpc clients and light providers for test.
 // If o is nil, reasonable default values are used.
-func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverrides) *cobra.Command {
+func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverrides, opts ...RootCmdOption) *cobra.Command {
 	// Use a local app state instance scoped to the new root command,
 	// so that tests don't concurrently access the state.
 	a := &appState{
 		Log: log,
 
 		Viper: viper.New(),
+
+		HTTPClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
 
 	defaultHome := os.ExpandEnv("$HOME/.lens")
@@ -64,20 +83,81 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		Short: "This is my lens, there are many like it, but this one is mine.",
 	}
 
+	// Errors are written by HandleError, in the format selected by --errors-json.
+	rootCmd.SilenceErrors = true
+
+	// stopMetrics stops the server started by --metrics-listen, once the command has run.
+	var stopMetrics func()
+
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		// Completion requests parse the flags of the command being completed only after this runs,
+		// so they read what they need themselves, rather than loading the configuration of the default home.
+		if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			return nil
+		}
+
 		// Inside persistent pre-run because this takes effect after flags are parsed.
-		if a.Viper.GetBool("debug") {
+		// The gRPC calls are traced at debug level, which --trace lowers the level to.
+		trace, traceBodies := tracing(cmd)
+		if a.Viper.GetBool("debug") || trace {
 			atom.SetLevel(zapcore.DebugLevel)
 		}
+		if err := a.configureLogger(cmd); err != nil {
+			return err
+		}
+		if trace {
+			a.Tracer = client.NewTracer(a.Log, traceBodies)
+		}
 
-		// reads `homeDir/config.yaml` into `var config *Config` before each command
-		if err := initConfig(rootCmd, a, o); err != nil {
+		a.outputSet = cmd.Flags().Changed("output")
+		if err := validateOutputFormat(a.OutputFormat); err != nil {
+			return err
+		}
+
+		// The --retries flag of dynamic commands, which also retries their reflection requests, shadows that of the root.
+		if f := cmd.Flags().Lookup(retriesFlag); f != nil && f.Changed {
+			n, err := strconv.Atoi(f.Value.String())
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --%s %q: must be a number of retries", retriesFlag, f.Value.String())
+			}
+			a.Retries = &n
+		}
+
+		// Set before loading the configuration, which may select endpoints over the network.
+		if err := a.startTimeout(cmd); err != nil {
 			return err
 		}
 
+		if addr, _ := cmd.Flags().GetString(metricsListenFlag); addr != "" {
+			stop, err := serveMetrics(a, addr)
+			if err != nil {
+				return err
+			}
+			stopMetrics = stop
+		}
+
+		// reads the configuration file of homeDir, such as `homeDir/config.yaml`, into `var config *Config` before each command
+		if err := a.lockConfigFor(cmd, a.HomePath); err != nil {
+			return err
+		}
+		if err := initConfig(rootCmd, a, o); err != nil {
+			a.unlockConfig()
+			a.stopTimeout()
+			return a.timeoutError(cmd, err)
+		}
+		if !a.outputSet {
+			a.OutputFormat = defaultOutputFormat(cmd, a)
+		}
+
 		return nil
 	}
 
+	rootCmd.PersistentPostRun = func(*cobra.Command, []string) {
+		if stopMetrics != nil {
+			stopMetrics()
+		}
+	}
+
 	// --home flag
 	rootCmd.PersistentFlags().StringVar(&a.HomePath, flags.FlagHome, defaultHome, "set home directory")
 	if err := a.Viper.BindPFlag(flags.FlagHome, rootCmd.PersistentFlags().Lookup(flags.FlagHome)); err != nil {
@@ -90,7 +170,11 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		panic(err)
 	}
 
-	rootCmd.PersistentFlags().StringP("output", "o", "json", "output format (json, indent, yaml)")
+	rootCmd.PersistentFlags().String(logLevelFlag, "info", "log messages of this level and above (debug, info, warn, error); --debug sets debug")
+	rootCmd.PersistentFlags().String(logFormatFlag, "", "log format (console, json, logfmt); defaults to console on a terminal, or else logfmt")
+	rootCmd.PersistentFlags().String(logFileFlag, "", "append the log to this file instead of writing it to stderr")
+
+	rootCmd.PersistentFlags().StringVarP(&a.OutputFormat, "output", "o", "", "output format (text, json, json-indent, yaml); defaults to the chain's output-format, or text for the dynamic and config commands")
 	if err := a.Viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output")); err != nil {
 		panic(err)
 	}
@@ -100,17 +184,55 @@ func NewRootCmd(log *zap.Logger, atom zap.AtomicLevel, o map[string]ClientOverri
 		panic(err)
 	}
 
+	rootCmd.PersistentFlags().Bool(errorsJSONFlag, false, "write errors to stderr as JSON objects with their type, exit code, message, and details")
+
+	rootCmd.PersistentFlags().StringVar(&a.Endpoint, "endpoint", "", "use only this endpoint of the chain, given as an address or as an index into its endpoint lists")
+	if err := a.Viper.BindPFlag("endpoint", rootCmd.PersistentFlags().Lookup("endpoint")); err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&a.KeyringBackend, keyringBackendFlag, "", "use this keyring backend (os, file, kwallet, pass, test, memory) instead of the chain's keyring-backend")
+	rootCmd.PersistentFlags().StringVar(&a.KeyringPassphraseFile, keyringPassphraseFileFlag, "",
+		"file holding the passphrase of file keyrings, used if stdin is not a terminal and $"+keyringPassphraseEnv+" is not set")
+
+	rootCmd.PersistentFlags().StringVar(&a.Proxy, proxyFlag, "",
+		"connect to the RPC and gRPC endpoints of every chain through this proxy (e.g. socks5://127.0.0.1:9050, http://proxy:3128, or direct for none), instead of the chain's proxy settings")
+
+	rootCmd.PersistentFlags().StringVar(&a.RateLimit, rateLimitFlag, "",
+		"send at most this many requests per second to each endpoint of every chain, as RATE or RATE:BURST (e.g. 5 or 10:20), instead of the chain's rate-limit")
+
+	rootCmd.PersistentFlags().Int(retriesFlag, 0,
+		"retry the requests reading every chain that fail transiently, such as with HTTP 502, this many times, instead of the chain's retries (0 for none)")
+
+	rootCmd.PersistentFlags().String(metricsListenFlag, "", "serve Prometheus metrics of the chain clients on /metrics at this address (e.g. 127.0.0.1:9100) while the command runs")
+
+	rootCmd.PersistentFlags().Bool(traceFlag, false, "log each gRPC call of the command at debug level, with its method, request and response sizes, duration, and status code")
+	rootCmd.PersistentFlags().Bool(traceBodiesFlag, false, "like --trace, also logging the request and response bodies of the gRPC calls as JSON, and their metadata, with secrets such as authorization redacted")
+
+	rootCmd.PersistentFlags().Duration(timeoutFlag, 0, "abandon the network operations of the command after this long (e.g. 30s); 0 waits indefinitely, and commands with a more specific --timeout use theirs instead")
+
 	rootCmd.AddCommand(
 		chainsCmd(a),
 		keysCmd(a),
+		contactsCmd(a),
 		queryCmd(a),
 		tendermintCmd(a),
 		crosschainCmd(a),
 		txCmd(a),
+		exportCmd(a),
+		genesisCmd(a),
 		versionCmd(),
 		airdropCmd(a),
+		faucetCmd(a),
 		dynamicCmd(a),
+		configCmd(a),
+		completionCmd(),
 	)
+	withTimeoutErrors(a, rootCmd)
+
+	if err := registerFlagCompletions(rootCmd, a); err != nil {
+		panic(err)
+	}
 
 	return rootCmd
 }
@@ -125,36 +247,44 @@ func Execute() {
 
 	rootCmd := NewRootCmd(log, atom, nil)
 	rootCmd.SilenceUsage = true
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Sync()
-		os.Exit(1)
+		os.Exit(HandleError(rootCmd, err))
 	}
 }
 
-func rootLogger() (*zap.Logger, zap.AtomicLevel) {
-	config := zap.NewProductionEncoderConfig()
-	config.EncodeTime = func(ts time.Time, encoder zapcore.PrimitiveArrayEncoder) {
-		encoder.AppendString(ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
-	}
-	config.LevelKey = "lvl"
+// errorsJSONFlag is the name of the root flag selecting JSON error output.
+const errorsJSONFlag = "errors-json"
 
-	var enc zapcore.Encoder
-	if term.IsTerminal(int(os.Stderr.Fd())) {
-		// When a user runs relayer in the foreground, use easier to read output.
-		enc = zapcore.NewConsoleEncoder(config)
-	} else {
-		// Otherwise, use consistent logfmt format for simplistic machine processing.
-		enc = zaplogfmt.NewEncoder(config)
+// HandleError writes err, returned from executing rootCmd, to the command's error output,
+// as a JSON errorReport if --errors-json was set, or else as a plain message.
+// It returns the process exit status to use for err.
+func HandleError(rootCmd *cobra.Command, err error) int {
+	r := newErrorReport(err)
+	w := rootCmd.ErrOrStderr()
+
+	// The flag may not have been parsed if parsing failed, in which case plain text is used.
+	if asJSON, _ := rootCmd.PersistentFlags().GetBool(errorsJSONFlag); asJSON {
+		if jsonErr := json.NewEncoder(w).Encode(r); jsonErr == nil {
+			return r.Code
+		}
 	}
 
+	fmt.Fprintln(w, "Error:", r.Message)
+	return r.Code
+}
+
+// rootLogger returns the logger of the application, writing to stderr until the log flags are parsed,
+// and its level, which --debug lowers.
+func rootLogger() (*zap.Logger, zap.AtomicLevel) {
 	atom := zap.NewAtomicLevel()
-	return zap.New(zapcore.NewCore(
-		enc,
-		os.Stderr,
-		atom,
-	)), atom
+	log, err := newLogger(os.Stderr, "", atom)
+	if err != nil {
+		// The default format is always valid.
+		panic(err)
+	}
+	return log, atom
 }
 
 // writeJSON encodes the given object to the given writer.
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const (
+	slashingAllFlag    = "all"
+	slashingWarnAtFlag = "warn-at"
+
+	// defaultSlashingWarnAt is the percentage of the blocks a validator may miss before being jailed
+	// above which it is shown as at risk.
+	defaultSlashingWarnAt = 50
+)
+
+// Statuses of the validators listed by query slashing signing-info.
+const (
+	signingStatusOK         = "ok"
+	signingStatusAtRisk     = "at-risk"
+	signingStatusJailed     = "jailed"
+	signingStatusTombstoned = "tombstoned"
+)
+
+func slashingSigningInfoCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "signing-info [chain-name] [validator]",
+		Aliases: []string{"si"},
+		Short:   "query the missed blocks of a validator, or of every validator",
+		Long: `Query the signing info of a validator of the given chain, or of the default chain,
+named by its consensus address (valcons), its operator address (valoper), or its moniker.
+With --all, the signing infos of every validator are listed instead, by most blocks missed.
+
+The blocks missed are shown with their percentage of the current signed blocks window.
+A validator is at risk once it missed --warn-at percent of the blocks it may miss in the window before being jailed;
+text output shows its status in capitals, with a warning.`,
+		Example: fmt.Sprintf(`$ %s query slashing signing-info cosmoshub cosmosvaloper1...
+$ %s q slashing signing-info cosmosvalcons1... -o json
+$ %s q slashing signing-info osmosis --all --warn-at 25`,
+			appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(0, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool(slashingAllFlag)
+			if err != nil {
+				return err
+			}
+			warnAt, err := cmd.Flags().GetFloat64(slashingWarnAtFlag)
+			if err != nil {
+				return err
+			}
+			if warnAt <= 0 || warnAt > 100 {
+				return fmt.Errorf("--%s must be a percentage above 0 and at most 100", slashingWarnAtFlag)
+			}
+
+			chainName, validator := a.Config.DefaultChain, ""
+			switch {
+			case all && len(args) > 1:
+				return errors.New("only a chain name is accepted with --all")
+			case all:
+				if len(args) == 1 {
+					chainName = args[0]
+				}
+			case len(args) == 0:
+				return errors.New("a validator is required, unless --all is set")
+			case len(args) == 1:
+				validator = args[0]
+			default:
+				chainName, validator = args[0], args[1]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			params, err := query.Slashing_Params()
+			if err != nil {
+				return fmt.Errorf("failed to query slashing params: %w", err)
+			}
+			// The validators only name the signing infos, so those are still shown if they cannot be queried.
+			validators, err := validatorsByConsAddress(cl, query)
+			if err != nil {
+				if validator != "" {
+					return err
+				}
+				a.Log.Warn("Failed to query the validators to resolve their monikers", zap.Error(err))
+			}
+
+			if !all {
+				consAddr, err := slashingResolveConsAddress(cl, query, validators, validator)
+				if err != nil {
+					return err
+				}
+				res, err := query.Slashing_SigningInfo(consAddr)
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd, a, newSigningInfoSummary(res.ValSigningInfo, params.Params, validators, warnAt))
+			}
+
+			infos, err := query.Slashing_AllSigningInfos()
+			if err != nil {
+				return err
+			}
+			result := make(signingInfosResult, len(infos))
+			for i, info := range infos {
+				result[i] = newSigningInfoSummary(info, params.Params, validators, warnAt)
+			}
+			sort.SliceStable(result, func(i, j int) bool {
+				if result[i].MissedBlocks != result[j].MissedBlocks {
+					return result[i].MissedBlocks > result[j].MissedBlocks
+				}
+				return result[i].ConsAddress < result[j].ConsAddress
+			})
+			return writeOutput(cmd, a, result)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	cmd.Flags().Bool(slashingAllFlag, false, "list the signing infos of every validator, by most blocks missed")
+	cmd.Flags().Float64(slashingWarnAtFlag, defaultSlashingWarnAt, "percentage of the blocks a validator may miss before being jailed above which it is at risk")
+	return cmd
+}
+
+// validatorsByConsAddress returns every validator of the chain by consensus address.
+func validatorsByConsAddress(cl *client.ChainClient, q query.Query) (map[string]stakingtypes.Validator, error) {
+	validators, err := q.Staking_AllValidators("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query validators: %w", err)
+	}
+	// The consensus keys are left packed by the query.
+	if err := validators.UnpackInterfaces(cl.Codec.InterfaceRegistry); err != nil {
+		return nil, err
+	}
+	byConsAddr := make(map[string]stakingtypes.Validator, len(validators))
+	for _, v := range validators {
+		consAddr, err := v.GetConsAddr()
+		if err != nil {
+			continue
+		}
+		encoded, err := cl.EncodeBech32ConsAddr(sdk.AccAddress(consAddr))
+		if err != nil {
+			return nil, err
+		}
+		byConsAddr[encoded] = v
+	}
+	return byConsAddr, nil
+}
+
+// slashingResolveConsAddress returns the consensus address of the validator named by arg,
+// which is either its consensus address, or its operator address or moniker as resolved by stakingResolveValidator.
+func slashingResolveConsAddress(cl *client.ChainClient, q query.Query, validators map[string]stakingtypes.Validator, arg string) (string, error) {
+	if consAddr, err := cl.DecodeBech32ConsAddr(arg); err == nil {
+		return cl.EncodeBech32ConsAddr(consAddr)
+	}
+	operator, err := stakingResolveValidator(cl, q, arg)
+	if err != nil {
+		return "", err
+	}
+	for consAddr, v := range validators {
+		if v.OperatorAddress == operator {
+			return consAddr, nil
+		}
+	}
+	return "", fmt.Errorf("no validator with operator address %s on chain %s", operator, cl.Config.ChainID)
+}
+
+// signingInfoSummary is the signing info of a validator shown by query slashing signing-info.
+type signingInfoSummary struct {
+	ConsAddress     string    `json:"cons_address"`
+	OperatorAddress string    `json:"operator_address,omitempty"`
+	Moniker         string    `json:"moniker,omitempty"`
+	MissedBlocks    int64     `json:"missed_blocks"`
+	Window          int64     `json:"signed_blocks_window"`
+	MissedPercent   float64   `json:"missed_percent"`
+	MaxMissedBlocks int64     `json:"max_missed_blocks"`
+	StartHeight     int64     `json:"start_height"`
+	JailedUntil     time.Time `json:"jailed_until"`
+	Tombstoned      bool      `json:"tombstoned"`
+	Status          string    `json:"status"`
+}
+
+// newSigningInfoSummary returns the summary of info under params, naming its validator if it is one of validators.
+// The validator is at risk once it missed warnAt percent of the blocks it may miss in the window.
+func newSigningInfoSummary(info slashingtypes.ValidatorSigningInfo, params slashingtypes.Params, validators map[string]stakingtypes.Validator, warnAt float64) signingInfoSummary {
+	window := params.SignedBlocksWindow
+	// As computed by the slashing module, a validator is jailed once it missed more blocks than this.
+	maxMissed := window - params.MinSignedPerWindow.MulInt64(window).RoundInt64()
+
+	s := signingInfoSummary{
+		ConsAddress:     info.Address,
+		MissedBlocks:    info.MissedBlocksCounter,
+		Window:          window,
+		MaxMissedBlocks: maxMissed,
+		StartHeight:     info.StartHeight,
+		JailedUntil:     info.JailedUntil,
+		Tombstoned:      info.Tombstoned,
+		Status:          signingStatusOK,
+	}
+	if v, ok := validators[info.Address]; ok {
+		s.OperatorAddress = v.OperatorAddress
+		s.Moniker = v.Description.Moniker
+	}
+	if window > 0 {
+		s.MissedPercent = float64(info.MissedBlocksCounter) * 100 / float64(window)
+	}
+	switch {
+	case info.Tombstoned:
+		s.Status = signingStatusTombstoned
+	case info.JailedUntil.After(time.Now()):
+		s.Status = signingStatusJailed
+	case float64(info.MissedBlocksCounter)*100 >= warnAt*float64(maxMissed):
+		s.Status = signingStatusAtRisk
+	}
+	return s
+}
+
+// statusText returns the status for text output, in capitals unless it is ok so that it stands out.
+func (s signingInfoSummary) statusText() string {
+	if s.Status == signingStatusOK {
+		return s.Status
+	}
+	return strings.ToUpper(strings.ReplaceAll(s.Status, "-", " "))
+}
+
+var _ fmt.Stringer = signingInfoSummary{}
+
+// String returns the fields of the signing info one per line, followed by a warning if the validator is at risk.
+func (s signingInfoSummary) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Consensus address:\t%s\n", s.ConsAddress)
+	fmt.Fprintf(w, "Operator address:\t%s\n", orDash(s.OperatorAddress))
+	fmt.Fprintf(w, "Moniker:\t%s\n", orDash(s.Moniker))
+	fmt.Fprintf(w, "Missed blocks:\t%d of %d (%.2f%%)\n", s.MissedBlocks, s.Window, s.MissedPercent)
+	fmt.Fprintf(w, "Jailed above:\t%d missed\n", s.MaxMissedBlocks)
+	fmt.Fprintf(w, "Jailed until:\t%s\n", formatProposalTime(s.JailedUntil))
+	fmt.Fprintf(w, "Tombstoned:\t%t\n", s.Tombstoned)
+	fmt.Fprintf(w, "Status:\t%s\n", s.statusText())
+	w.Flush()
+
+	if s.Status == signingStatusAtRisk {
+		fmt.Fprintf(&b, "\nWARNING: the validator missed %d of the %d blocks it may miss in the window before being jailed.\n",
+			s.MissedBlocks, s.MaxMissedBlocks)
+	}
+	return b.String()
+}
+
+// signingInfosResult is the result of query slashing signing-info --all.
+type signingInfosResult []signingInfoSummary
+
+var _ fmt.Stringer = signingInfosResult(nil)
+
+// String returns the signing infos as a table with aligned columns, followed by the number of validators at risk if any.
+func (r signingInfosResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MONIKER\tCONSENSUS ADDRESS\tMISSED\tWINDOW\tJAILED UNTIL\tSTATUS")
+	atRisk := 0
+	for _, s := range r {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f%%\t%s\t%s\n",
+			orDash(s.Moniker), s.ConsAddress, s.MissedBlocks, s.MissedPercent, formatProposalTime(s.JailedUntil), s.statusText())
+		if s.Status == signingStatusAtRisk {
+			atRisk++
+		}
+	}
+	w.Flush()
+	if atRisk > 0 {
+		fmt.Fprintf(&b, "\nWARNING: %d validator(s) at risk of being jailed for downtime.\n", atRisk)
+	}
+	return b.String()
+}
+
+func slashingParamsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "params [chain-name]",
+		Aliases: []string{"parameters"},
+		Short:   "query the slashing params of a chain",
+		Long: `Query the slashing params of the given chain, or of the default chain:
+the signed blocks window, the minimum share of it to sign, the downtime jail duration, and the slash fractions.`,
+		Example: fmt.Sprintf(`$ %s query slashing params cosmoshub
+$ %s q slashing params -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Slashing_Params()
+			if err != nil {
+				return err
+			}
+			p := res.Params
+			return writeOutput(cmd, a, slashingParams{
+				SignedBlocksWindow:      p.SignedBlocksWindow,
+				MinSignedPerWindow:      p.MinSignedPerWindow.String(),
+				DowntimeJailDuration:    p.DowntimeJailDuration.String(),
+				SlashFractionDoubleSign: p.SlashFractionDoubleSign.String(),
+				SlashFractionDowntime:   p.SlashFractionDowntime.String(),
+			})
+		},
+	}
+	addQueryHeightFlag(cmd)
+	return cmd
+}
+
+// slashingParams is the result of query slashing params.
+type slashingParams struct {
+	SignedBlocksWindow      int64  `json:"signed_blocks_window"`
+	MinSignedPerWindow      string `json:"min_signed_per_window"`
+	DowntimeJailDuration    string `json:"downtime_jail_duration"`
+	SlashFractionDoubleSign string `json:"slash_fraction_double_sign"`
+	SlashFractionDowntime   string `json:"slash_fraction_downtime"`
+}
+
+var _ fmt.Stringer = slashingParams{}
+
+// String returns the params one per line.
+func (p slashingParams) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Signed blocks window:\t%d\n", p.SignedBlocksWindow)
+	fmt.Fprintf(w, "Min signed per window:\t%s\n", p.MinSignedPerWindow)
+	fmt.Fprintf(w, "Downtime jail duration:\t%s\n", p.DowntimeJailDuration)
+	fmt.Fprintf(w, "Slash fraction double sign:\t%s\n", p.SlashFractionDoubleSign)
+	fmt.Fprintf(w, "Slash fraction downtime:\t%s\n", p.SlashFractionDowntime)
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSlashingSigningInfo(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	var validators []stakingtypes.Validator
+	var consAddrs []string
+	for _, v := range []struct{ operator, moniker string }{{testValoperA, "Alpha"}, {testValoperB, "beta"}} {
+		pk := ed25519.GenPrivKey().PubKey()
+		pkAny, err := codectypes.NewAnyWithValue(pk)
+		require.NoError(t, err)
+		validators = append(validators, stakingtypes.Validator{
+			OperatorAddress: v.operator,
+			ConsensusPubkey: pkAny,
+			Description:     stakingtypes.Description{Moniker: v.moniker},
+		})
+		consAddr, err := sdk.Bech32ifyAddressBytes("cosmosvalcons", pk.Address())
+		require.NoError(t, err)
+		consAddrs = append(consAddrs, consAddr)
+	}
+	const unknown = "cosmosvalcons1qqqsyqcyq5rqwzqfpg9scrgwpugpzysn0h4ql0"
+	infos := map[string]slashingtypes.ValidatorSigningInfo{
+		consAddrs[0]: {Address: consAddrs[0], MissedBlocksCounter: 4750, StartHeight: 5},
+		consAddrs[1]: {Address: consAddrs[1], MissedBlocksCounter: 10},
+		unknown:      {Address: unknown, Tombstoned: true, JailedUntil: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	mockABCIQuery(t, mc, "/cosmos.slashing.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&slashingtypes.QueryParamsResponse{Params: slashingtypes.Params{
+			SignedBlocksWindow:      10000,
+			MinSignedPerWindow:      sdk.NewDecWithPrec(5, 2),
+			DowntimeJailDuration:    10 * time.Minute,
+			SlashFractionDoubleSign: sdk.NewDecWithPrec(5, 2),
+			SlashFractionDowntime:   sdk.NewDecWithPrec(1, 4),
+		}})
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validators", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorsResponse{Validators: validators})
+	for addr, info := range infos {
+		addr := addr
+		mockABCIQuery(t, mc, "/cosmos.slashing.v1beta1.Query/SigningInfo", func(data bytes.HexBytes) bool {
+			var req slashingtypes.QuerySigningInfoRequest
+			return req.Unmarshal(data) == nil && req.ConsAddress == addr
+		}, &slashingtypes.QuerySigningInfoResponse{ValSigningInfo: info})
+	}
+	mockABCIQuery(t, mc, "/cosmos.slashing.v1beta1.Query/SigningInfos", func(bytes.HexBytes) bool { return true },
+		&slashingtypes.QuerySigningInfosResponse{Info: []slashingtypes.ValidatorSigningInfo{
+			infos[consAddrs[1]], infos[unknown], infos[consAddrs[0]],
+		}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// 4750 missed blocks are half of the 9500 a validator may miss before being jailed.
+	res := sys.MustRun(t, "query", "slashing", "signing-info", "cosmoshub", testValoperA, "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Consensus address:  "+consAddrs[0]+"\n")
+	require.Contains(t, out, "Moniker:            Alpha\n")
+	require.Contains(t, out, "Missed blocks:      4750 of 10000 (47.50%)\n")
+	require.Contains(t, out, "Jailed until:       -\n")
+	require.Contains(t, out, "Status:             AT RISK\n")
+	require.Contains(t, out, "WARNING: the validator missed 4750 of the 9500 blocks")
+
+	res = sys.MustRun(t, "query", "slashing", "signing-info", "beta", "-o", "json")
+	var info struct {
+		ConsAddress   string  `json:"cons_address"`
+		MissedPercent float64 `json:"missed_percent"`
+		Status        string
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &info))
+	require.Equal(t, consAddrs[1], info.ConsAddress)
+	require.Equal(t, 0.1, info.MissedPercent)
+	require.Equal(t, "ok", info.Status)
+
+	// The threshold of the warning is a flag, and consensus addresses are queried as is.
+	res = sys.MustRun(t, "query", "slashing", "signing-info", consAddrs[0], "--warn-at", "60", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Status:             ok\n")
+	require.NotContains(t, res.Stdout.String(), "WARNING")
+
+	res = sys.MustRun(t, "query", "slashing", "signing-info", "--all", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 6)
+	require.Equal(t, []string{"MONIKER", "CONSENSUS", "ADDRESS", "MISSED", "WINDOW", "JAILED", "UNTIL", "STATUS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"Alpha", consAddrs[0], "4750", "47.50%", "-", "AT", "RISK"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"beta", consAddrs[1], "10", "0.10%", "-", "ok"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"-", unknown, "0", "0.00%", "9999-01-01T00:00:00Z", "TOMBSTONED"}, strings.Fields(lines[3]))
+	require.Equal(t, "WARNING: 1 validator(s) at risk of being jailed for downtime.", lines[5])
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "slashing", "signing-info")
+	require.ErrorContains(t, res.Err, "a validator is required, unless --all is set")
+	res = sys.Run(zaptest.NewLogger(t), "query", "slashing", "signing-info", "gamma")
+	require.ErrorContains(t, res.Err, `no validator with operator address or moniker "gamma"`)
+
+	res = sys.MustRun(t, "query", "slashing", "params", "-o", "json")
+	require.JSONEq(t, `{
+		"signed_blocks_window": 10000,
+		"min_signed_per_window": "0.050000000000000000",
+		"downtime_jail_duration": "10m0s",
+		"slash_fraction_double_sign": "0.050000000000000000",
+		"slash_fraction_downtime": "0.000100000000000000"
+	}`, res.Stdout.String())
+}
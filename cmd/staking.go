@@ -1,152 +1,269 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
 	"github.com/strangelove-ventures/lens/client/query"
 )
 
+// stakingValidatorArgHelp describes the validator arguments resolved by stakingResolveValidator.
+const stakingValidatorArgHelp = `A validator may be given by its operator address, or by its moniker, matched case-insensitively
+among all validators of the chain.`
+
 func stakingDelegateCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delegate [validator-addr] [amount] ",
-		Args:  cobra.ExactArgs(2),
-		Short: "Delegate liquid tokens to a validator",
-		Long: strings.TrimSpace(
-			`Delegate an amount of liquid coins to a validator from your wallet.
-Example:
-$ lens tx staking delegate cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0 1000stake --from mykey`,
-		),
+		Use:   "delegate [chain-name] <from-key> <validator> <amount>",
+		Short: "delegate liquid tokens to a validator",
+		Long: `Delegate an amount of liquid tokens from a key in the keyring of the given chain, or of the default chain, to a validator.
 
-		RunE: func(cmd *cobra.Command, args []string) error {
-			var (
-				delAddr sdk.AccAddress
-				err     error
-			)
+` + stakingValidatorArgHelp + `
 
-			cl := a.Config.GetDefaultClient()
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx staking delegate cosmoshub mykey cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0 1000uatom
+$ %s tx staking delegate mykey "My Validator" 1000uatom --generate-only`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 3)
+			cl, delAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
 
-			if args[2] != cl.Config.Key {
-				cl.Config.Key = args[2]
+			amount, err := sdk.ParseCoinNormalized(args[2])
+			if err != nil {
+				return fmt.Errorf("invalid amount %q: %w", args[2], err)
 			}
 
-			amount, err := sdk.ParseCoinNormalized(args[1])
+			q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+			validator, err := stakingResolveValidator(cl, q, args[1])
 			if err != nil {
 				return err
 			}
 
-			key, _ := cmd.Flags().GetString(FlagFrom)
-			if key != "" {
-				if key != cl.Config.Key {
-					cl.Config.Key = key
-				}
+			msg := &types.MsgDelegate{
+				DelegatorAddress: cl.MustEncodeAccAddr(delAddr),
+				ValidatorAddress: validator,
+				Amount:           amount,
 			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	return cmd
+}
 
-			if cl.KeyExists(cl.Config.Key) {
-				delAddr, err = cl.GetKeyAddress()
-			} else {
-				delAddr, err = cl.DecodeBech32AccAddr(key)
-			}
+func stakingUndelegateCmd(a *appState) *cobra.Command {
+	const maxFlag = "max"
+
+	cmd := &cobra.Command{
+		Use:     "undelegate [chain-name] <from-key> <validator> [amount]",
+		Aliases: []string{"unbond"},
+		Short:   "undelegate tokens from a validator",
+		Long: `Undelegate an amount of tokens delegated by a key in the keyring of the given chain, or of the default chain, to a validator.
+With --max, the whole delegation is undelegated, as found by a query of the delegations of the key.
+
+` + stakingValidatorArgHelp + `
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx staking undelegate cosmoshub mykey cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0 1000uatom
+$ %s tx staking undelegate mykey "My Validator" --max`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			undelegateMax, err := cmd.Flags().GetBool(maxFlag)
 			if err != nil {
 				return err
 			}
+			n := 3
+			if undelegateMax {
+				n = 2
+				// Without a chain, the last argument would be taken for the validator.
+				if _, err := sdk.ParseCoinNormalized(args[len(args)-1]); err == nil || len(args) == 4 {
+					return fmt.Errorf("the amount cannot be given with --%s", maxFlag)
+				}
+			} else if len(args) == 2 {
+				return fmt.Errorf("an amount or --%s is required", maxFlag)
+			}
 
-			valAddr, err := cl.DecodeBech32ValAddr(args[0])
+			chainName, args := txArgs(a, args, n)
+			cl, delAddr, err := txChainClient(cmd, a, chainName, args[0])
 			if err != nil {
 				return err
 			}
-			memo, err := cmd.Flags().GetString(flagMemo)
+			delegator := cl.MustEncodeAccAddr(delAddr)
+
+			q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+			validator, err := stakingResolveValidator(cl, q, args[1])
 			if err != nil {
 				return err
 			}
-			// msg := types.NewMsgDelegate(delAddr, valAddr, amount)
-			msg := &types.MsgDelegate{
-				DelegatorAddress: cl.MustEncodeAccAddr(delAddr),
-				ValidatorAddress: cl.MustEncodeValAddr(valAddr),
-				Amount:           amount,
+
+			var amount sdk.Coin
+			if undelegateMax {
+				delegations, err := q.Staking_AllDelegatorDelegations(delegator)
+				if err != nil {
+					return fmt.Errorf("failed to query delegations: %w", err)
+				}
+				for _, d := range delegations {
+					if d.Delegation.ValidatorAddress == validator {
+						amount = d.Balance
+						break
+					}
+				}
+				if amount.IsNil() || amount.IsZero() {
+					return fmt.Errorf("%s has no delegation to validator %s", delegator, validator)
+				}
+			} else if amount, err = sdk.ParseCoinNormalized(args[2]); err != nil {
+				return fmt.Errorf("invalid amount %q: %w", args[2], err)
 			}
-			return cl.HandleAndPrintMsgSend(cl.SendMsg(cmd.Context(), msg, memo))
 
+			msg := &types.MsgUndelegate{
+				DelegatorAddress: delegator,
+				ValidatorAddress: validator,
+				Amount:           amount,
+			}
+			return sendTx(cmd, a, cl, msg)
 		},
 	}
-	flags.AddTxFlagsToCmd(cmd)
-	memoFlag(a.Viper, cmd)
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().Bool(maxFlag, false, "undelegate the whole delegation, instead of an amount")
 	return cmd
 }
 
 func stakingRedelegateCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "redelegate [from] [src-validator-addr] [dst-validator-addr] [amount]",
+		Use:   "redelegate [chain-name] <from-key> <src-validator> <dst-validator> <amount>",
 		Short: "redelegate tokens from one validator to another",
-		Args:  cobra.ExactArgs(3),
-		Long: strings.TrimSpace(
-			`Redelegate an amount of illiquid staking tokens from one validator to another.
-Example:
-$ lens tx staking redelegate cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0 cosmosvaloper1a3yjj7d3qnx4spgvjcwjq9cw9snrrrhu5h6jll 100stake --from mykey
-`,
-		),
+		Long: `Redelegate an amount of tokens delegated by a key in the keyring of the given chain, or of the default chain,
+from one validator to another.
+
+` + stakingValidatorArgHelp + `
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx staking redelegate cosmoshub mykey cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0 cosmosvaloper1a3yjj7d3qnx4spgvjcwjq9cw9snrrrhu5h6jll 100uatom
+$ %s tx staking redelegate mykey "Old Validator" "New Validator" 100uatom --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(4, 5)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			key, _ := cmd.Flags().GetString(FlagFrom)
-			delAddr, err := cl.AccountFromKeyOrAddress(key)
+			chainName, args := txArgs(a, args, 4)
+			cl, delAddr, err := txChainClient(cmd, a, chainName, args[0])
 			if err != nil {
 				return err
 			}
 
-			valSrcAddr, err := cl.DecodeBech32ValAddr(args[0])
+			amount, err := sdk.ParseCoinNormalized(args[3])
 			if err != nil {
-				return err
+				return fmt.Errorf("invalid amount %q: %w", args[3], err)
 			}
 
-			valDstAddr, err := cl.DecodeBech32ValAddr(args[1])
+			q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+			src, err := stakingResolveValidator(cl, q, args[1])
 			if err != nil {
 				return err
 			}
-
-			amount, err := sdk.ParseCoinNormalized(args[2])
+			dst, err := stakingResolveValidator(cl, q, args[2])
 			if err != nil {
 				return err
 			}
-			memo, err := cmd.Flags().GetString(flagMemo)
-			if err != nil {
-				return err
+			if src == dst {
+				return fmt.Errorf("cannot redelegate from validator %s to itself", src)
 			}
+
 			msg := &types.MsgBeginRedelegate{
 				DelegatorAddress:    cl.MustEncodeAccAddr(delAddr),
-				ValidatorSrcAddress: cl.MustEncodeValAddr(sdk.ValAddress(valSrcAddr)),
-				ValidatorDstAddress: cl.MustEncodeValAddr(sdk.ValAddress(valDstAddr)),
+				ValidatorSrcAddress: src,
+				ValidatorDstAddress: dst,
 				Amount:              amount,
 			}
-
-			return cl.HandleAndPrintMsgSend(cl.SendMsg(cmd.Context(), msg, memo))
+			return sendTx(cmd, a, cl, msg)
 		},
 	}
-
-	flags.AddTxFlagsToCmd(cmd)
-	memoFlag(a.Viper, cmd)
+	addTxOptionsFlags(a, cmd)
 	return cmd
 }
 
+// stakingResolveValidator returns the operator address of the validator named by arg,
+// which is either its operator address or its moniker, as described by stakingValidatorArgHelp.
+// A moniker shared by several validators, or matching none, is an error listing the closest monikers.
+func stakingResolveValidator(cl *client.ChainClient, q query.Query, arg string) (string, error) {
+	if valAddr, err := cl.DecodeBech32ValAddr(arg); err == nil {
+		return cl.MustEncodeValAddr(valAddr), nil
+	}
+
+	validators, err := q.Staking_AllValidators("")
+	if err != nil {
+		return "", fmt.Errorf("failed to query validators to resolve %q: %w", arg, err)
+	}
+
+	var matches []types.Validator
+	byMoniker := make(map[string][]types.Validator, len(validators))
+	monikers := make([]string, 0, len(validators))
+	for _, v := range validators {
+		if v.OperatorAddress == arg {
+			return arg, nil
+		}
+		if strings.EqualFold(v.Description.Moniker, arg) {
+			matches = append(matches, v)
+		}
+		if _, ok := byMoniker[v.Description.Moniker]; !ok {
+			monikers = append(monikers, v.Description.Moniker)
+		}
+		byMoniker[v.Description.Moniker] = append(byMoniker[v.Description.Moniker], v)
+	}
+	if len(matches) == 1 {
+		return matches[0].OperatorAddress, nil
+	}
+
+	describe := func(vs []types.Validator) string {
+		descs := make([]string, len(vs))
+		for i, v := range vs {
+			descs[i] = fmt.Sprintf("%q (%s)", v.Description.Moniker, v.OperatorAddress)
+		}
+		return strings.Join(descs, ", ")
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("moniker %q is ambiguous, give the operator address of one of: %s", arg, describe(matches))
+	}
+
+	var closest []types.Validator
+	for _, m := range closestMatches(arg, monikers, maxSuggestions) {
+		closest = append(closest, byMoniker[m]...)
+	}
+	if len(closest) == 0 {
+		return "", fmt.Errorf("no validator with operator address or moniker %q on chain %s", arg, cl.Config.ChainID)
+	}
+	return "", fmt.Errorf("no validator with operator address or moniker %q on chain %s; did you mean %s?", arg, cl.Config.ChainID, describe(closest))
+}
+
 func stakingParamsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "parameters",
 		Aliases: []string{"params"},
 		Short:   "query things about a chain's staking params",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			params, err := query.Staking_Params()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(params.Params)
+			return writeOutput(cmd, a, codecJSON(cl, &params.Params))
 		},
 	}
 
@@ -158,17 +275,20 @@ func stakingPoolCmd(a *appState) *cobra.Command {
 		Use:   "pool",
 		Short: "query things about a chain's staking pool",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 			pool, err := query.Staking_Pool()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(pool.Pool)
+			return writeOutput(cmd, a, codecJSON(cl, &pool.Pool))
 		},
 	}
 
@@ -177,34 +297,139 @@ func stakingPoolCmd(a *appState) *cobra.Command {
 
 func stakingDelegationsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "delegations [delegator-addr]",
+		Use:     "delegations [chain-name] [delegator-key-or-addr]",
 		Aliases: []string{"dels"},
 		Short:   "query all delegations for a delegator address",
-		Long: strings.TrimSpace(`query delegations for an individual delegator on all validators.
+		Long: `Query the delegations of a delegator on all validators, with the total delegated tokens.
 
-Example:
-$ lens query staking delegations cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
-`),
-		Args: cobra.ExactArgs(1),
+` + chainAndAddressArgsHelp + `
+
+` + paginationHelp + `
+Validator monikers are resolved with a query of all validators, unless --no-resolve is set.`,
+		Example: fmt.Sprintf(`$ %s query staking delegations cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
+$ %s q staking delegations osmosis --no-resolve -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgsFunction: completeChainThenKey(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			opts, err := queryOptionsFromFlags(cmd.Flags())
+			cl, delegator, err := chainClientAndAddress(a, args)
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			response, err := query.Staking_DelegatorDelegations(args[0])
+			pages, opts, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response.DelegationResponses)
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+
+			bondDenom, monikers, err := stakingLookups(cmd, query)
+			if err != nil {
+				return err
+			}
+
+			delegations, err := query.Staking_AllDelegatorDelegations(delegator)
+			if err != nil {
+				return err
+			}
+
+			result := delegationsResult{
+				Delegations: make([]delegationSummary, len(delegations)),
+				Total:       sdk.NewCoin(bondDenom, sdk.ZeroInt()),
+			}
+			for i, d := range delegations {
+				result.Delegations[i] = delegationSummary{
+					Validator: d.Delegation.ValidatorAddress,
+					Moniker:   monikers[d.Delegation.ValidatorAddress],
+					Shares:    d.Delegation.Shares.String(),
+					Balance:   d.Balance,
+				}
+				result.Total.Amount = result.Total.Amount.Add(d.Balance.Amount)
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "delegations")
+	stakingDelegatorQueryFlags(cmd, "delegations")
 	return cmd
 }
 
+// stakingDelegatorQueryFlags adds the flags shared by the queries of a delegator's delegations.
+func stakingDelegatorQueryFlags(cmd *cobra.Command, noun string) {
+	addQueryHeightFlag(cmd)
+	cmd.Flags().Bool(noResolveFlag, false, "do not query the validators to resolve their monikers")
+	addPaginationFlags(cmd, noun)
+}
+
+// noResolveFlag is the name of the flag disabling the resolution of validator monikers.
+const noResolveFlag = "no-resolve"
+
+// stakingLookups returns the staking bond denom,
+// and the moniker of every validator by operator address unless --no-resolve is set.
+func stakingLookups(cmd *cobra.Command, q query.Query) (string, map[string]string, error) {
+	params, err := q.Staking_Params()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query staking params: %w", err)
+	}
+
+	noResolve, err := cmd.Flags().GetBool(noResolveFlag)
+	if err != nil {
+		return "", nil, err
+	}
+	if noResolve {
+		return params.Params.BondDenom, nil, nil
+	}
+
+	// The validators are listed from the start, whatever page the delegations start at.
+	vq := q
+	vq.Options = &query.QueryOptions{Height: q.Options.Height}
+	validators, err := vq.Staking_AllValidators("")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query validators to resolve monikers (use --%s to skip): %w", noResolveFlag, err)
+	}
+
+	monikers := make(map[string]string, len(validators))
+	for _, v := range validators {
+		monikers[v.OperatorAddress] = v.Description.Moniker
+	}
+	return params.Params.BondDenom, monikers, nil
+}
+
+// delegationSummary is one delegation listed by query staking delegations.
+type delegationSummary struct {
+	Validator string   `json:"validator"`
+	Moniker   string   `json:"moniker,omitempty"`
+	Shares    string   `json:"shares"`
+	Balance   sdk.Coin `json:"balance"`
+}
+
+// delegationsResult is the result of query staking delegations.
+type delegationsResult struct {
+	Delegations []delegationSummary `json:"delegations"`
+	Total       sdk.Coin            `json:"total"`
+}
+
+var _ fmt.Stringer = delegationsResult{}
+
+// String returns the delegations as a table with aligned columns, followed by the total.
+func (r delegationsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VALIDATOR\tMONIKER\tSHARES\tAMOUNT")
+	for _, d := range r.Delegations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Validator, orDash(d.Moniker), d.Shares, d.Balance)
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "Total: %s\n", r.Total)
+	return b.String()
+}
+
+// orDash returns s, or "-" if s is empty, so that table cells are never blank.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func stakingDelegationCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "delegation [delegator-addr] [validator-addr]",
@@ -217,23 +442,32 @@ $ lens query staking delegation cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p co
 `),
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			delegator := args[0]
-			validator := args[1]
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			delegator, err := cl.ResolveAccAddr(args[0])
+			if err != nil {
+				return err
+			}
+			validator, err := cl.ResolveValAddr(args[1])
+			if err != nil {
+				return err
+			}
 			response, err := query.Staking_Delegation(delegator, validator)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response.DelegationResponse)
+			return writeOutput(cmd, a, codecJSON(cl, response.DelegationResponse))
 		},
 	}
 
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 
 	return cmd
 }
@@ -250,57 +484,127 @@ $ lens query staking unbonding-delegation cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9l
 `),
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			delegator := args[0]
-			validator := args[1]
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			delegator, err := cl.ResolveAccAddr(args[0])
+			if err != nil {
+				return err
+			}
+			validator, err := cl.ResolveValAddr(args[1])
+			if err != nil {
+				return err
+			}
 			response, err := query.Staking_UnbondingDelegation(delegator, validator)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response.Unbond)
+			return writeOutput(cmd, a, codecJSON(cl, &response.Unbond))
 		},
 	}
 
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 
 	return cmd
 }
 
 func stakingUnbondingDelegationsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "unbonding-delegations [delegator-addr]",
+		Use:     "unbonding-delegations [chain-name] [delegator-key-or-addr]",
 		Aliases: []string{"unbondings", "ubds"},
 		Short:   "query all unbonding delegations for a delegator address",
-		Long: strings.TrimSpace(`query delegations for an individual delegator on all validators.
-
-Example:
-$ lens query staking unbonding-delegations cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
-`),
-		Args: cobra.ExactArgs(1),
+		Long: `Query the unbonding delegations of a delegator on all validators, with the total unbonding tokens.
+Each entry of an unbonding delegation is listed separately.
+
+` + chainAndAddressArgsHelp + `
+
+` + paginationHelp + `
+Validator monikers are resolved with a query of all validators, unless --no-resolve is set.`,
+		Example: fmt.Sprintf(`$ %s query staking unbonding-delegations cosmos1gghjut3ccd8ay0zduzj64hwre2fxs9ld75ru9p
+$ %s q staking unbonding-delegations osmosis --no-resolve -o json`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgsFunction: completeChainThenKey(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			opts, err := queryOptionsFromFlags(cmd.Flags())
+			cl, delegator, err := chainClientAndAddress(a, args)
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			response, err := query.Staking_DelegatorUnbondingDelegations(args[0])
+			pages, opts, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response.UnbondingResponses)
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+
+			bondDenom, monikers, err := stakingLookups(cmd, query)
+			if err != nil {
+				return err
+			}
+
+			unbondings, err := query.Staking_AllDelegatorUnbondingDelegations(delegator)
+			if err != nil {
+				return err
+			}
+
+			result := unbondingDelegationsResult{
+				UnbondingDelegations: []unbondingSummary{},
+				Total:                sdk.NewCoin(bondDenom, sdk.ZeroInt()),
+			}
+			for _, u := range unbondings {
+				for _, e := range u.Entries {
+					result.UnbondingDelegations = append(result.UnbondingDelegations, unbondingSummary{
+						Validator:      u.ValidatorAddress,
+						Moniker:        monikers[u.ValidatorAddress],
+						Balance:        sdk.NewCoin(bondDenom, e.Balance),
+						CreationHeight: e.CreationHeight,
+						CompletionTime: e.CompletionTime,
+					})
+					result.Total.Amount = result.Total.Amount.Add(e.Balance)
+				}
+			}
+			return pages.writeOutput(cmd, a, result, query.Pages)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "unbonding-delegations")
+	stakingDelegatorQueryFlags(cmd, "unbonding delegations")
 	return cmd
 }
 
+// unbondingSummary is one unbonding delegation entry listed by query staking unbonding-delegations.
+type unbondingSummary struct {
+	Validator      string    `json:"validator"`
+	Moniker        string    `json:"moniker,omitempty"`
+	Balance        sdk.Coin  `json:"balance"`
+	CreationHeight int64     `json:"creation_height"`
+	CompletionTime time.Time `json:"completion_time"`
+}
+
+// unbondingDelegationsResult is the result of query staking unbonding-delegations.
+type unbondingDelegationsResult struct {
+	UnbondingDelegations []unbondingSummary `json:"unbonding_delegations"`
+	Total                sdk.Coin           `json:"total"`
+}
+
+var _ fmt.Stringer = unbondingDelegationsResult{}
+
+// String returns the unbonding delegations as a table with aligned columns, followed by the total.
+func (r unbondingDelegationsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VALIDATOR\tMONIKER\tAMOUNT\tCOMPLETION")
+	for _, u := range r.UnbondingDelegations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Validator, orDash(u.Moniker), u.Balance, u.CompletionTime.UTC().Format(time.RFC3339))
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "Total: %s\n", r.Total)
+	return b.String()
+}
+
 func stakingValidatorDelegationsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "validator-delegations [validator-addr]",
@@ -308,70 +612,231 @@ func stakingValidatorDelegationsCmd(a *appState) *cobra.Command {
 		Short:   "query all delegations for a validator address",
 		Long: strings.TrimSpace(`query delegations for an individual validator.
 
+` + paginationHelp + `
+
 Example:
 $ lens query staking validator-delegations [validator address (valoper)]
 `),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			opts, err := queryOptionsFromFlags(cmd.Flags())
+			cl, err := defaultChainClient(a)
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
-			response, err := query.Staking_ValidatorDelegations(args[0])
+			pages, opts, err := pagedQueryOptions(cmd)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response.DelegationResponses)
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			validator, err := cl.ResolveValAddr(args[0])
+			if err != nil {
+				return err
+			}
+			delegations, err := query.Staking_AllValidatorDelegations(validator)
+			if err != nil {
+				return err
+			}
+			pages.logNextPage(a, query.Pages)
+			return writeOutput(cmd, a, delegations)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "validator-delegations")
+	addQueryHeightFlag(cmd)
+	addPaginationFlags(cmd, "delegations")
 	return cmd
 }
 
+// Flags of query staking validators.
+const (
+	validatorsStatusFlag = "status"
+	validatorsSortFlag   = "sort"
+	validatorsLimitFlag  = "limit"
+	validatorsJailedFlag = "jailed"
+)
+
+// Values of the --sort flag of query staking validators.
+const (
+	validatorsSortTokens     = "tokens"
+	validatorsSortCommission = "commission"
+	validatorsSortMoniker    = "moniker"
+)
+
+// bondStatusNames maps the names accepted by the --status flag of query staking validators to bond statuses.
+var bondStatusNames = map[string]types.BondStatus{
+	"bonded":    types.Bonded,
+	"unbonding": types.Unbonding,
+	"unbonded":  types.Unbonded,
+}
+
 func stakingValidatorsCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "validators [status]",
+		Use:     "validators [chain-name]",
 		Aliases: []string{"vals", "vs"},
-		Short:   "query all validators for a status",
-		Long: strings.TrimSpace(`query validators.
-
-Example:
-$ lens query staking validators <bonded|unbonded|unbonding>
-`),
-		Args: cobra.ExactArgs(1),
+		Short:   "query the validators of a chain",
+		Long: `Query the validators of the given chain, or of the default chain,
+showing the moniker, operator address, bonded tokens, commission rate, and jailed status of each,
+with its share of the voting power of all bonded tokens.
+
+Every page of validators is requested in turn.
+The validators are sorted by decreasing tokens, by increasing commission rate, or by moniker.`,
+		Example: fmt.Sprintf(`$ %s query staking validators cosmoshub
+$ %s q staking validators osmosis --status bonded --sort commission --limit 20 --jailed=false`,
+			appName, appName),
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeChainNames(a),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			opts, err := queryOptionsFromFlags(cmd.Flags())
+			statusName, err := cmd.Flags().GetString(validatorsStatusFlag)
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				_, isChain := a.Config.Chains[args[0]]
+				if _, isStatus := bondStatusNames[args[0]]; isStatus && !isChain && statusName == "" {
+					// The status used to be the argument of this command.
+					statusName = args[0]
+				} else {
+					chainName = args[0]
+				}
+			}
+
 			var status string
-			switch args[0] {
-			case "bonded":
-				status = "BOND_STATUS_BONDED"
-			case "unbonded":
-				status = "BOND_STATUS_UNBONDED"
-			case "unbonding":
-				status = "BOND_STATUS_UNBONDING"
+			if statusName != "" {
+				s, ok := bondStatusNames[statusName]
+				if !ok {
+					return fmt.Errorf("unknown validator status %q (must be bonded, unbonding, or unbonded)", statusName)
+				}
+				status = s.String()
+			}
+			sortBy, err := cmd.Flags().GetString(validatorsSortFlag)
+			if err != nil {
+				return err
+			}
+			switch sortBy {
+			case validatorsSortTokens, validatorsSortCommission, validatorsSortMoniker:
 			default:
-				status = "BOND_STATUS_BONDED"
+				return fmt.Errorf(
+					"unknown sort order %q (must be %s, %s, or %s)",
+					sortBy, validatorsSortTokens, validatorsSortCommission, validatorsSortMoniker,
+				)
 			}
-			response, err := query.Staking_Validators(status)
+			limit, err := cmd.Flags().GetInt(validatorsLimitFlag)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response)
+			includeJailed, err := cmd.Flags().GetBool(validatorsJailedFlag)
+			if err != nil {
+				return err
+			}
+
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			height, err := ReadHeight(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{Height: height}}
+
+			validators, err := query.Staking_AllValidators(status)
+			if err != nil {
+				return err
+			}
+			// The pool is queried once, for the voting power of every validator.
+			pool, err := query.Staking_Pool()
+			if err != nil {
+				return fmt.Errorf("failed to query staking pool: %w", err)
+			}
+			bonded := pool.Pool.BondedTokens
+
+			result := validatorsResult{}
+			for _, v := range validators {
+				if v.Jailed && !includeJailed {
+					continue
+				}
+				summary := validatorSummary{
+					Moniker:         v.Description.Moniker,
+					OperatorAddress: v.OperatorAddress,
+					Status:          bondStatusName(v.Status),
+					Tokens:          v.Tokens,
+					CommissionRate:  v.Commission.Rate,
+					Jailed:          v.Jailed,
+					VotingPower:     sdk.ZeroDec(),
+				}
+				// Only bonded tokens count towards the voting power.
+				if v.IsBonded() && bonded.IsPositive() {
+					summary.VotingPower = sdk.NewDecFromInt(v.Tokens).MulInt64(100).QuoInt(bonded)
+				}
+				result = append(result, summary)
+			}
+
+			sort.SliceStable(result, func(i, j int) bool {
+				switch sortBy {
+				case validatorsSortCommission:
+					return result[i].CommissionRate.LT(result[j].CommissionRate)
+				case validatorsSortMoniker:
+					return strings.ToLower(result[i].Moniker) < strings.ToLower(result[j].Moniker)
+				default:
+					return result[i].Tokens.GT(result[j].Tokens)
+				}
+			})
+			if limit > 0 && len(result) > limit {
+				result = result[:limit]
+			}
+			return writeOutput(cmd, a, result)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
-	flags.AddPaginationFlagsToCmd(cmd, "validators")
+	addQueryHeightFlag(cmd)
+	cmd.Flags().String(validatorsStatusFlag, "", "only list validators with this status (bonded, unbonding, or unbonded)")
+	cmd.Flags().String(validatorsSortFlag, validatorsSortTokens, "sort the validators by tokens, commission, or moniker")
+	cmd.Flags().Int(validatorsLimitFlag, 0, "list at most this many validators, after sorting (0 lists them all)")
+	cmd.Flags().Bool(validatorsJailedFlag, true, "include jailed validators (--jailed=false excludes them)")
 	return cmd
 }
 
+// bondStatusName returns the name of s accepted by the --status flag of query staking validators.
+func bondStatusName(s types.BondStatus) string {
+	for name, status := range bondStatusNames {
+		if status == s {
+			return name
+		}
+	}
+	return s.String()
+}
+
+// validatorSummary is one validator listed by query staking validators.
+type validatorSummary struct {
+	Moniker         string  `json:"moniker"`
+	OperatorAddress string  `json:"operator_address"`
+	Status          string  `json:"status"`
+	Tokens          sdk.Int `json:"tokens"`
+	CommissionRate  sdk.Dec `json:"commission_rate"`
+	Jailed          bool    `json:"jailed"`
+	// VotingPower is the percentage of all bonded tokens bonded to the validator.
+	VotingPower sdk.Dec `json:"voting_power_percent"`
+}
+
+// validatorsResult is the result of query staking validators.
+type validatorsResult []validatorSummary
+
+var _ fmt.Stringer = validatorsResult{}
+
+// String returns the validators as a table with aligned columns.
+func (r validatorsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MONIKER\tOPERATOR\tSTATUS\tTOKENS\tCOMMISSION\tJAILED\tVOTING POWER")
+	for _, v := range r {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%.2f%%\t%t\t%.2f%%\n",
+			orDash(v.Moniker), v.OperatorAddress, v.Status, v.Tokens,
+			v.CommissionRate.MulInt64(100).MustFloat64(), v.Jailed, v.VotingPower.MustFloat64(),
+		)
+	}
+	w.Flush()
+	return b.String()
+}
+
 func stakingValidatorCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "validator [address]",
@@ -384,20 +849,27 @@ $ lens query staking validator [valoper_address]
 `),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			opts, err := queryOptionsFromFlags(cmd.Flags())
 			if err != nil {
 				return err
 			}
-			query := query.Query{Client: cl, Options: opts}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
 
-			response, err := query.Staking_Validator(args[0])
+			validator, err := cl.ResolveValAddr(args[0])
+			if err != nil {
+				return err
+			}
+			response, err := query.Staking_Validator(validator)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(response)
+			return writeOutput(cmd, a, codecJSON(cl, response))
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 	return cmd
 }
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const stakingAPRValidatorFlag = "validator"
+
+func stakingAPRCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apr [chain-name]",
+		Short: "estimate the nominal staking APR of a chain",
+		Long: `Estimate the nominal APR of staking on the given chain, or on the default chain, as
+  annual provisions × staking share × (1 - community tax) / bonded tokens
+where the annual provisions and the share of them distributed to stakers come from the chain's mint module,
+the bonded tokens from the staking pool, and the community tax from the distribution params.
+With --validator, given as an operator address or a moniker, the APR of its delegators after its commission is estimated too.
+
+Every input is shown, so the estimate can be checked. The mint modules known are those of the Cosmos SDK and of Osmosis,
+detected by listing the services of the chain's gRPC address with reflection;
+on chains with neither, or whose services cannot be listed, the other inputs are shown without an APR.`,
+		Example: fmt.Sprintf(`$ %s query staking apr cosmoshub
+$ %s q staking apr osmosis --validator cosmosvaloper1... -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			validator, err := cmd.Flags().GetString(stakingAPRValidatorFlag)
+			if err != nil {
+				return err
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			params, err := query.Staking_Params()
+			if err != nil {
+				return err
+			}
+			pool, err := query.Staking_Pool()
+			if err != nil {
+				return err
+			}
+			supply, err := query.Bank_SupplyOf(params.Params.BondDenom)
+			if err != nil {
+				return err
+			}
+			distribution, err := query.Distribution_Params()
+			if err != nil {
+				return err
+			}
+
+			result := stakingAPR{
+				ChainID:      cl.Config.ChainID,
+				BondDenom:    params.Params.BondDenom,
+				TotalSupply:  supply.Amount.Amount,
+				BondedTokens: pool.Pool.BondedTokens,
+				CommunityTax: distribution.Params.CommunityTax,
+			}
+			if result.TotalSupply.IsPositive() {
+				ratio := sdk.NewDecFromInt(result.BondedTokens).QuoInt(result.TotalSupply)
+				result.BondedRatio = &ratio
+			}
+			if validator != "" {
+				valoper, err := stakingResolveValidator(cl, query, validator)
+				if err != nil {
+					return err
+				}
+				res, err := query.Staking_Validator(valoper)
+				if err != nil {
+					return err
+				}
+				result.Validator = valoper
+				result.Commission = &res.Validator.Commission.Rate
+			}
+
+			services, err := listChainServices(cmd, a, chainName)
+			if err != nil {
+				err = fmt.Errorf("cannot detect the mint module of the chain with gRPC reflection: %w", err)
+				a.Log.Warn("Cannot estimate the staking APR", zap.String("chain_name", chainName), zap.Error(err))
+				result.Unavailable = err.Error()
+				return writeOutput(cmd, a, result)
+			}
+			inflation, err := cl.QueryInflation(cmd.Context(), opts.Height, services)
+			if err != nil {
+				var noProvider client.NoInflationProviderError
+				if !errors.As(err, &noProvider) {
+					return err
+				}
+				a.Log.Warn("Cannot estimate the staking APR", zap.String("chain_name", chainName), zap.Error(err))
+				result.Unavailable = err.Error()
+				return writeOutput(cmd, a, result)
+			}
+			result.InflationSource = inflation.Source
+			result.MintDenom = inflation.Denom
+			result.Inflation = &inflation.Rate
+			result.AnnualProvisions = &inflation.AnnualProvisions
+			result.StakingShare = &inflation.StakingShare
+			if err := result.estimate(); err != nil {
+				a.Log.Warn("Cannot estimate the staking APR", zap.String("chain_name", chainName), zap.Error(err))
+				result.Unavailable = err.Error()
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	cmd.Flags().String(stakingAPRValidatorFlag, "", "operator address or moniker of a validator whose commission is deducted from the APR")
+	// The gRPC flags include --height, which is also that of the queries of the inputs.
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// listChainServices returns the services of the chain named chainName, listed with gRPC reflection.
+func listChainServices(cmd *cobra.Command, a *appState, chainName string) ([]string, error) {
+	gRPCAddr, err := chooseGRPCAddr(cmd, a, chainName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	defer rc.Reset()
+	return rc.ListServices()
+}
+
+// stakingAPR is the result of query staking apr, with the inputs of the estimate.
+type stakingAPR struct {
+	ChainID      string   `json:"chain_id"`
+	BondDenom    string   `json:"bond_denom"`
+	TotalSupply  sdk.Int  `json:"total_supply"`
+	BondedTokens sdk.Int  `json:"bonded_tokens"`
+	BondedRatio  *sdk.Dec `json:"bonded_ratio,omitempty"`
+	CommunityTax sdk.Dec  `json:"community_tax"`
+	Validator    string   `json:"validator,omitempty"`
+	Commission   *sdk.Dec `json:"commission,omitempty"`
+
+	// The inflation, unless the chain has none of the known mint modules.
+	InflationSource  string   `json:"inflation_source,omitempty"`
+	MintDenom        string   `json:"mint_denom,omitempty"`
+	Inflation        *sdk.Dec `json:"inflation,omitempty"`
+	AnnualProvisions *sdk.Dec `json:"annual_provisions,omitempty"`
+	StakingShare     *sdk.Dec `json:"staking_share,omitempty"`
+
+	// APR is the estimated nominal APR, and ValidatorAPR the same after the commission of Validator.
+	APR          *sdk.Dec `json:"apr"`
+	ValidatorAPR *sdk.Dec `json:"validator_apr,omitempty"`
+
+	// Unavailable is the reason the APR could not be estimated, if it was not.
+	Unavailable string `json:"unavailable,omitempty"`
+}
+
+// estimate sets the APR from the inputs, or returns why they do not allow an estimate.
+func (r *stakingAPR) estimate() error {
+	if r.MintDenom != r.BondDenom {
+		return fmt.Errorf("chain %s mints %s, not its bond denom %s", r.ChainID, r.MintDenom, r.BondDenom)
+	}
+	if !r.BondedTokens.IsPositive() {
+		return fmt.Errorf("chain %s has no bonded tokens", r.ChainID)
+	}
+	apr := r.AnnualProvisions.Mul(*r.StakingShare).Mul(sdk.OneDec().Sub(r.CommunityTax)).QuoInt(r.BondedTokens)
+	r.APR = &apr
+	if r.Commission != nil {
+		validatorAPR := apr.Mul(sdk.OneDec().Sub(*r.Commission))
+		r.ValidatorAPR = &validatorAPR
+	}
+	return nil
+}
+
+var _ fmt.Stringer = stakingAPR{}
+
+// String returns the inputs of the estimate one per line, followed by the APR.
+func (r stakingAPR) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Chain:\t%s\n", r.ChainID)
+	fmt.Fprintf(w, "Total supply:\t%s%s\n", r.TotalSupply, r.BondDenom)
+	fmt.Fprintf(w, "Bonded tokens:\t%s%s\n", r.BondedTokens, r.BondDenom)
+	if r.BondedRatio != nil {
+		fmt.Fprintf(w, "Bonded ratio:\t%s\n", formatDecPercent(*r.BondedRatio))
+	}
+	if r.Inflation != nil {
+		fmt.Fprintf(w, "Inflation:\t%s (from %s)\n", formatDecPercent(*r.Inflation), r.InflationSource)
+		fmt.Fprintf(w, "Annual provisions:\t%s%s\n", r.AnnualProvisions.TruncateInt(), r.MintDenom)
+		fmt.Fprintf(w, "Staking share:\t%s\n", formatDecPercent(*r.StakingShare))
+	}
+	fmt.Fprintf(w, "Community tax:\t%s\n", formatDecPercent(r.CommunityTax))
+	if r.Validator != "" {
+		fmt.Fprintf(w, "Commission:\t%s (%s)\n", formatDecPercent(*r.Commission), r.Validator)
+	}
+	if r.APR == nil {
+		fmt.Fprintf(w, "APR:\t- (%s)\n", r.Unavailable)
+		w.Flush()
+		return b.String()
+	}
+	fmt.Fprintf(w, "APR:\t%s\n", formatDecPercent(*r.APR))
+	if r.ValidatorAPR != nil {
+		fmt.Fprintf(w, "APR after commission:\t%s\n", formatDecPercent(*r.ValidatorAPR))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// formatDecPercent formats the fraction d as a percentage with two decimals.
+func formatDecPercent(d sdk.Dec) string {
+	return fmt.Sprintf("%.2f%%", d.MustFloat64()*100)
+}
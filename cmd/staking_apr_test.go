@@ -0,0 +1,231 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rawResponse is a response of a module whose types are not known to lens, already encoded.
+type rawResponse []byte
+
+func (r rawResponse) Marshal() ([]byte, error) { return r, nil }
+
+// runServicesServer runs a gRPC server whose reflection service lists services, without serving them,
+// and makes it the gRPC address of cosmoshub on sys.
+func runServicesServer(t *testing.T, sys *System, services ...string) {
+	t.Helper()
+
+	srv := grpc.NewServer()
+	listed := make(staticServices, len(services))
+	for _, s := range services {
+		listed[s] = grpc.ServiceInfo{}
+	}
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{Services: listed}))
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", ln.Addr().String())
+}
+
+// mockAPRInputs makes mc answer the staking, bank, and distribution queries of query staking apr,
+// and reject the queries of modules it does not answer otherwise.
+func mockAPRInputs(t *testing.T, mc *mocks.Client, denom string, supply, bonded int64, tax sdk.Dec) {
+	t.Helper()
+
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryParamsResponse{Params: stakingtypes.Params{BondDenom: denom}})
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Pool", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryPoolResponse{Pool: stakingtypes.Pool{BondedTokens: sdk.NewInt(bonded), NotBondedTokens: sdk.ZeroInt()}})
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/SupplyOf", func(bytes.HexBytes) bool { return true },
+		&banktypes.QuerySupplyOfResponse{Amount: sdk.NewInt64Coin(denom, supply)})
+	mockABCIQuery(t, mc, "/cosmos.distribution.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&distributiontypes.QueryParamsResponse{Params: distributiontypes.Params{
+			CommunityTax:        tax,
+			BaseProposerReward:  sdk.ZeroDec(),
+			BonusProposerReward: sdk.ZeroDec(),
+		}})
+	mockStakingLookups(t, mc)
+	mc.On("ABCIQueryWithOptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 6, Log: "unknown query path"}}, nil)
+}
+
+func TestStakingAPR(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	runServicesServer(t, sys, "cosmos.staking.v1beta1.Query", "cosmos.mint.v1beta1.Query")
+
+	valoper, err := sdk.Bech32ifyAddressBytes("cosmosvaloper", make([]byte, 20))
+	require.NoError(t, err)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.mint.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&minttypes.QueryParamsResponse{Params: minttypes.DefaultParams()})
+	mockABCIQuery(t, mc, "/cosmos.mint.v1beta1.Query/Inflation", func(bytes.HexBytes) bool { return true },
+		&minttypes.QueryInflationResponse{Inflation: sdk.NewDecWithPrec(10, 2)})
+	mockABCIQuery(t, mc, "/cosmos.mint.v1beta1.Query/AnnualProvisions", func(bytes.HexBytes) bool { return true },
+		&minttypes.QueryAnnualProvisionsResponse{AnnualProvisions: sdk.NewDec(100_000_000)})
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validator", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorResponse{Validator: stakingtypes.Validator{
+			OperatorAddress: valoper,
+			Commission:      stakingtypes.NewCommission(sdk.NewDecWithPrec(5, 2), sdk.OneDec(), sdk.OneDec()),
+		}})
+	mockAPRInputs(t, mc, "stake", 1_000_000_000, 600_000_000, sdk.NewDecWithPrec(2, 2))
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// 100000000 tokens minted a year, less 2% of community tax, are shared by 600000000 bonded tokens.
+	res := sys.MustRun(t, "query", "staking", "apr", "cosmoshub", "--validator", valoper, "-o", "text")
+	require.Equal(t, `Chain:                 cosmoshub-4
+Total supply:          1000000000stake
+Bonded tokens:         600000000stake
+Bonded ratio:          60.00%
+Inflation:             10.00% (from /cosmos.mint.v1beta1.Query/Inflation)
+Annual provisions:     100000000stake
+Staking share:         100.00%
+Community tax:         2.00%
+Commission:            5.00% (`+valoper+`)
+APR:                   16.33%
+APR after commission:  15.52%
+`, res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "staking", "apr", "-o", "json")
+	var apr struct {
+		BondedRatio      sdk.Dec `json:"bonded_ratio"`
+		Inflation        sdk.Dec
+		AnnualProvisions sdk.Dec `json:"annual_provisions"`
+		CommunityTax     sdk.Dec `json:"community_tax"`
+		APR              sdk.Dec
+		ValidatorAPR     *sdk.Dec `json:"validator_apr"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &apr))
+	require.Equal(t, "0.600000000000000000", apr.BondedRatio.String())
+	require.Equal(t, "0.100000000000000000", apr.Inflation.String())
+	require.Equal(t, "0.020000000000000000", apr.CommunityTax.String())
+	require.Equal(t, "0.163333333333333333", apr.APR.String())
+	require.Nil(t, apr.ValidatorAPR)
+}
+
+func TestStakingAPR_OsmosisMint(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	runServicesServer(t, sys, "cosmos.staking.v1beta1.Query", "osmosis.mint.v1beta1.Query")
+
+	// Params{mint_denom: "uosmo", epoch_identifier: "day", distribution_proportions: {staking: 0.25}}.
+	share, err := sdk.NewDecWithPrec(25, 2).Marshal()
+	require.NoError(t, err)
+	proportions := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), share)
+	params := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "uosmo")
+	params = protowire.AppendString(protowire.AppendTag(params, 3, protowire.BytesType), "day")
+	params = protowire.AppendBytes(protowire.AppendTag(params, 6, protowire.BytesType), proportions)
+	provisions, err := sdk.NewDec(100_000).Marshal()
+	require.NoError(t, err)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/osmosis.mint.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		rawResponse(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), params)))
+	mockABCIQuery(t, mc, "/osmosis.mint.v1beta1.Query/EpochProvisions", func(bytes.HexBytes) bool { return true },
+		rawResponse(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), provisions)))
+	mockAPRInputs(t, mc, "uosmo", 1_000_000_000, 300_000_000, sdk.ZeroDec())
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The 36500000 uosmo minted in 365 daily epochs are 3.65% of the supply, and a quarter of them goes to stakers.
+	res := sys.MustRun(t, "query", "staking", "apr", "-o", "json")
+	var apr struct {
+		InflationSource string `json:"inflation_source"`
+		Inflation       sdk.Dec
+		StakingShare    sdk.Dec `json:"staking_share"`
+		APR             sdk.Dec
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &apr))
+	require.Equal(t, "/osmosis.mint.v1beta1.Query/EpochProvisions", apr.InflationSource)
+	require.Equal(t, "0.036500000000000000", apr.Inflation.String())
+	require.Equal(t, "0.250000000000000000", apr.StakingShare.String())
+	require.Equal(t, "0.030416666666666666", apr.APR.String())
+}
+
+func TestStakingAPR_NoMintModule(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	runServicesServer(t, sys, "cosmos.staking.v1beta1.Query")
+
+	// The mint queries fail as unknown services would on some nodes, other than with an unknown query path,
+	// but the mint modules are detected by the services listed, so they are not queried.
+	mc := new(mocks.Client)
+	mc.On("ABCIQueryWithOptions", mock.Anything, mock.MatchedBy(func(path string) bool { return strings.Contains(path, ".mint.") }), mock.Anything, mock.Anything).
+		Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 1, Log: "service not registered"}}, nil)
+	mockAPRInputs(t, mc, "uatom", 1_000_000_000, 600_000_000, sdk.NewDecWithPrec(2, 2))
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The inputs other than the inflation are still shown.
+	res := sys.MustRun(t, "query", "staking", "apr", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Bonded ratio:   60.00%\n")
+	require.Contains(t, res.Stdout.String(),
+		"APR:            - (chain cosmoshub-4 has none of the known mint modules (cosmos-sdk mint, osmosis mint))\n")
+
+	res = sys.MustRun(t, "query", "staking", "apr", "-o", "json")
+	var apr map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &apr))
+	require.Nil(t, apr["apr"])
+	require.NotContains(t, apr, "inflation")
+	require.Contains(t, apr["unavailable"], "none of the known mint modules")
+	mc.AssertNotCalled(t, "ABCIQueryWithOptions", mock.Anything, "/cosmos.mint.v1beta1.Query/Inflation", mock.Anything, mock.Anything)
+}
+
+func TestStakingAPR_NoReflection(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// A server without the reflection service cannot list the services of the chain.
+	srv := grpc.NewServer()
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+	_ = sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", ln.Addr().String())
+
+	mc := new(mocks.Client)
+	mockAPRInputs(t, mc, "uatom", 1_000_000_000, 600_000_000, sdk.NewDecWithPrec(2, 2))
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "staking", "apr", "-o", "json")
+	var apr map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &apr))
+	require.Nil(t, apr["apr"])
+	require.Equal(t, "0.600000000000000000", apr["bonded_ratio"])
+	require.Contains(t, apr["unavailable"], "cannot detect the mint module of the chain with gRPC reflection")
+}
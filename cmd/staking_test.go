@@ -0,0 +1,357 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	testValoperA = "cosmosvaloper1aaaa"
+	testValoperB = "cosmosvaloper1bbbb"
+)
+
+// mockABCIQuery makes mc answer the query at path with res,
+// for requests accepted by match.
+func mockABCIQuery(t *testing.T, mc *mocks.Client, path string, match func(data bytes.HexBytes) bool, res interface{ Marshal() ([]byte, error) }) {
+	t.Helper()
+
+	value, err := res.Marshal()
+	require.NoError(t, err)
+	mc.On(
+		"ABCIQueryWithOptions",
+		mock.Anything,
+		path,
+		mock.MatchedBy(match),
+		rpcclient.ABCIQueryOptions{},
+	).Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value}}, nil)
+}
+
+// mockStakingLookups makes mc answer the queries of the staking params and of all validators.
+func mockStakingLookups(t *testing.T, mc *mocks.Client) {
+	t.Helper()
+
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Params", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryParamsResponse{Params: stakingtypes.Params{BondDenom: "uatom"}})
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validators", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorsResponse{
+			Validators: []stakingtypes.Validator{
+				{OperatorAddress: testValoperA, Description: stakingtypes.Description{Moniker: "alpha"}},
+				{OperatorAddress: testValoperB, Description: stakingtypes.Description{Moniker: "beta"}},
+			},
+			Pagination: &query.PageResponse{},
+		})
+}
+
+func TestStakingDelegations(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockStakingLookups(t, mc)
+
+	// The delegations are split across two pages, which must both be requested.
+	pages := map[string]*stakingtypes.QueryDelegatorDelegationsResponse{
+		"": {
+			DelegationResponses: stakingtypes.DelegationResponses{{
+				Delegation: stakingtypes.Delegation{DelegatorAddress: ZeroCosmosAddr, ValidatorAddress: testValoperA, Shares: sdk.NewDec(100)},
+				Balance:    sdk.NewInt64Coin("uatom", 100),
+			}},
+			Pagination: &query.PageResponse{NextKey: []byte("next")},
+		},
+		"next": {
+			DelegationResponses: stakingtypes.DelegationResponses{{
+				Delegation: stakingtypes.Delegation{DelegatorAddress: ZeroCosmosAddr, ValidatorAddress: testValoperB, Shares: sdk.NewDec(50)},
+				Balance:    sdk.NewInt64Coin("uatom", 50),
+			}},
+			Pagination: &query.PageResponse{},
+		},
+	}
+	for key, page := range pages {
+		key := key
+		mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/DelegatorDelegations", func(data bytes.HexBytes) bool {
+			var req stakingtypes.QueryDelegatorDelegationsRequest
+			if err := req.Unmarshal(data); err != nil {
+				return false
+			}
+			return req.DelegatorAddr == ZeroCosmosAddr && string(req.Pagination.Key) == key
+		}, page)
+	}
+
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "staking", "delegations", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"VALIDATOR", "MONIKER", "SHARES", "AMOUNT"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{testValoperA, "alpha", "100.000000000000000000", "100uatom"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{testValoperB, "beta", "50.000000000000000000", "50uatom"}, strings.Fields(lines[2]))
+	require.Equal(t, "Total: 150uatom", lines[3])
+
+	// Without resolution, the monikers are omitted.
+	res = sys.MustRun(t, "query", "staking", "delegations", ZeroCosmosAddr, "--no-resolve", "-o", "json")
+	var out struct {
+		Delegations []map[string]interface{}
+		Total       sdk.Coin
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Len(t, out.Delegations, 2)
+	require.Equal(t, testValoperA, out.Delegations[0]["validator"])
+	require.NotContains(t, out.Delegations[0], "moniker")
+	require.Equal(t, sdk.NewInt64Coin("uatom", 150), out.Total)
+}
+
+func TestStakingUnbondingDelegations(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockStakingLookups(t, mc)
+
+	completion := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/DelegatorUnbondingDelegations", func(data bytes.HexBytes) bool {
+		var req stakingtypes.QueryDelegatorUnbondingDelegationsRequest
+		return req.Unmarshal(data) == nil && req.DelegatorAddr == ZeroCosmosAddr
+	}, &stakingtypes.QueryDelegatorUnbondingDelegationsResponse{
+		UnbondingResponses: []stakingtypes.UnbondingDelegation{{
+			DelegatorAddress: ZeroCosmosAddr,
+			ValidatorAddress: testValoperA,
+			Entries: []stakingtypes.UnbondingDelegationEntry{
+				{CreationHeight: 10, CompletionTime: completion, InitialBalance: sdk.NewInt(7), Balance: sdk.NewInt(7)},
+				{CreationHeight: 20, CompletionTime: completion, InitialBalance: sdk.NewInt(3), Balance: sdk.NewInt(3)},
+			},
+		}},
+		Pagination: &query.PageResponse{},
+	})
+
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "staking", "unbonding-delegations", "cosmoshub", ZeroCosmosAddr, "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"VALIDATOR", "MONIKER", "AMOUNT", "COMPLETION"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{testValoperA, "alpha", "7uatom", "2026-01-02T03:04:05Z"}, strings.Fields(lines[1]))
+	require.Equal(t, "Total: 10uatom", lines[3])
+
+	res = sys.MustRun(t, "query", "staking", "unbonding-delegations", "cosmoshub", ZeroCosmosAddr, "-o", "json")
+	var out struct {
+		Total sdk.Coin
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Equal(t, sdk.NewInt64Coin("uatom", 10), out.Total)
+}
+
+func TestStakingValidators(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	validator := func(moniker, operator string, status stakingtypes.BondStatus, tokens int64, commission string, jailed bool) stakingtypes.Validator {
+		return stakingtypes.Validator{
+			OperatorAddress: operator,
+			Description:     stakingtypes.Description{Moniker: moniker},
+			Status:          status,
+			Tokens:          sdk.NewInt(tokens),
+			Commission:      stakingtypes.Commission{CommissionRates: stakingtypes.CommissionRates{Rate: sdk.MustNewDecFromStr(commission)}},
+			Jailed:          jailed,
+		}
+	}
+
+	// The validators are split across two pages, which must both be requested.
+	pages := map[string]*stakingtypes.QueryValidatorsResponse{
+		"": {
+			Validators: []stakingtypes.Validator{
+				validator("beta", testValoperB, stakingtypes.Bonded, 250, "0.10", false),
+				validator("gamma", "cosmosvaloper1cccc", stakingtypes.Unbonded, 10, "0.01", true),
+			},
+			Pagination: &query.PageResponse{NextKey: []byte("next")},
+		},
+		"next": {
+			Validators: []stakingtypes.Validator{
+				validator("Alpha", testValoperA, stakingtypes.Bonded, 750, "0.05", false),
+			},
+			Pagination: &query.PageResponse{},
+		},
+	}
+
+	mc := new(mocks.Client)
+	for key, page := range pages {
+		key := key
+		mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validators", func(data bytes.HexBytes) bool {
+			var req stakingtypes.QueryValidatorsRequest
+			if err := req.Unmarshal(data); err != nil {
+				return false
+			}
+			return req.Status == "" && string(req.Pagination.Key) == key
+		}, page)
+	}
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Pool", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryPoolResponse{Pool: stakingtypes.Pool{BondedTokens: sdk.NewInt(1000), NotBondedTokens: sdk.NewInt(10)}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "staking", "validators", "cosmoshub", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"MONIKER", "OPERATOR", "STATUS", "TOKENS", "COMMISSION", "JAILED", "VOTING", "POWER"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"Alpha", testValoperA, "bonded", "750", "5.00%", "false", "75.00%"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"beta", testValoperB, "bonded", "250", "10.00%", "false", "25.00%"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"gamma", "cosmosvaloper1cccc", "unbonded", "10", "1.00%", "true", "0.00%"}, strings.Fields(lines[3]))
+
+	monikers := func(args ...string) []string {
+		res := sys.MustRun(t, append([]string{"query", "staking", "validators", "-o", "json"}, args...)...)
+		var validators []struct {
+			Moniker string
+		}
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &validators))
+		var out []string
+		for _, v := range validators {
+			out = append(out, v.Moniker)
+		}
+		return out
+	}
+	require.Equal(t, []string{"gamma", "Alpha", "beta"}, monikers("--sort", "commission"))
+	require.Equal(t, []string{"Alpha", "beta", "gamma"}, monikers("--sort", "moniker"))
+	require.Equal(t, []string{"Alpha", "beta"}, monikers("--jailed=false"))
+	require.Equal(t, []string{"Alpha"}, monikers("--limit", "1"))
+
+	// Only one Pool query is made per command, whatever the number of validators.
+	mc.AssertNumberOfCalls(t, "ABCIQueryWithOptions", 5*(2+1))
+}
+
+func TestStakingDelegateTx(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockStakingLookups(t, mc)
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/DelegatorDelegations", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryDelegatorDelegationsResponse{
+			DelegationResponses: stakingtypes.DelegationResponses{
+				{
+					Delegation: stakingtypes.Delegation{DelegatorAddress: ZeroCosmosAddr, ValidatorAddress: testValoperB},
+					Balance:    sdk.NewInt64Coin("uatom", 250),
+				},
+			},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	var msgs []struct {
+		Type                string `json:"@type"`
+		DelegatorAddress    string `json:"delegator_address"`
+		ValidatorAddress    string `json:"validator_address"`
+		ValidatorSrcAddress string `json:"validator_src_address"`
+		ValidatorDstAddress string `json:"validator_dst_address"`
+		Amount              sdk.Coin
+	}
+
+	// Validators are resolved by moniker, case-insensitively, or by operator address.
+	res := sys.MustRun(t, "tx", "staking", "delegate", "cosmoshub", ZeroCosmosAddr, "Alpha", "100uatom", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.staking.v1beta1.MsgDelegate", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].DelegatorAddress)
+	require.Equal(t, testValoperA, msgs[0].ValidatorAddress)
+	require.Equal(t, sdk.NewInt64Coin("uatom", 100), msgs[0].Amount)
+
+	res = sys.MustRun(t, "tx", "staking", "redelegate", ZeroCosmosAddr, "alpha", testValoperB, "100uatom", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.staking.v1beta1.MsgBeginRedelegate", msgs[0].Type)
+	require.Equal(t, testValoperA, msgs[0].ValidatorSrcAddress)
+	require.Equal(t, testValoperB, msgs[0].ValidatorDstAddress)
+
+	// With --max, the whole delegation is undelegated.
+	res = sys.MustRun(t, "tx", "staking", "undelegate", ZeroCosmosAddr, "beta", "--max", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "/cosmos.staking.v1beta1.MsgUndelegate", msgs[0].Type)
+	require.Equal(t, testValoperB, msgs[0].ValidatorAddress)
+	require.Equal(t, sdk.NewInt64Coin("uatom", 250), msgs[0].Amount)
+
+	res = sys.MustRun(t, "tx", "staking", "undelegate", "cosmoshub", ZeroCosmosAddr, "alpha", "5uatom", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, sdk.NewInt64Coin("uatom", 5), msgs[0].Amount)
+
+	for args, msg := range map[string]string{
+		"delegate " + ZeroCosmosAddr + " alpah 1uatom":         `no validator with operator address or moniker "alpah" on chain cosmoshub-4; did you mean "alpha" (` + testValoperA + `)?`,
+		"delegate " + ZeroCosmosAddr + " zzzzzzzz 1uatom":      `no validator with operator address or moniker "zzzzzzzz" on chain cosmoshub-4`,
+		"redelegate " + ZeroCosmosAddr + " alpha alpha 1uatom": "cannot redelegate from validator " + testValoperA + " to itself",
+		"undelegate " + ZeroCosmosAddr + " alpha --max":        ZeroCosmosAddr + " has no delegation to validator " + testValoperA,
+		"undelegate " + ZeroCosmosAddr + " alpha 1uatom --max": "the amount cannot be given with --max",
+		"undelegate " + ZeroCosmosAddr + " alpha":              "an amount or --max is required",
+		"delegate " + ZeroCosmosAddr + " alpha lots":           `invalid amount "lots"`,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append(append([]string{"tx", "staking"}, strings.Fields(args)...), "--dry-run")...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestStakingDelegateTx_AmbiguousMoniker(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.staking.v1beta1.Query/Validators", func(bytes.HexBytes) bool { return true },
+		&stakingtypes.QueryValidatorsResponse{
+			Validators: []stakingtypes.Validator{
+				{OperatorAddress: testValoperA, Description: stakingtypes.Description{Moniker: "twin"}},
+				{OperatorAddress: testValoperB, Description: stakingtypes.Description{Moniker: "Twin"}},
+			},
+			Pagination: &query.PageResponse{},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.Run(zaptest.NewLogger(t), "tx", "staking", "delegate", ZeroCosmosAddr, "twin", "1uatom", "--dry-run")
+	require.ErrorContains(t, res.Err, `moniker "twin" is ambiguous, give the operator address of one of: "twin" (`+testValoperA+`), "Twin" (`+testValoperB+`)`)
+}
+
+func TestStakingDelegateTx_GenerateOnly(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockStakingLookups(t, mc)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "tx", "staking", "delegate", "mykey", "beta", "100uatom", "--generate-only")
+	var tx struct {
+		Body struct {
+			Messages []map[string]interface{}
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Len(t, tx.Body.Messages, 1)
+	require.Equal(t, testValoperB, tx.Body.Messages[0]["validator_address"])
+}
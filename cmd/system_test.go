@@ -3,6 +3,7 @@ package cmd_test
 import (
 	"bytes"
 	"io"
+	"net/http"
 	"testing"
 
 	"github.com/strangelove-ventures/lens/cmd"
@@ -14,6 +15,9 @@ import (
 type System struct {
 	HomeDir string
 
+	// HTTPClient, if set, is used by commands to fetch remote documents.
+	HTTPClient *http.Client
+
 	clientOverrides map[string]cmd.ClientOverrides
 }
 
@@ -42,11 +46,12 @@ func (s *System) OverrideClients(name string, o cmd.ClientOverrides) {
 }
 
 // RunResult is the stdout and stderr resulting from a call to (*System).Run,
-// and any error that was returned.
+// and any error that was returned, with the process exit status it would cause.
 type RunResult struct {
 	Stdout, Stderr bytes.Buffer
 
-	Err error
+	Err      error
+	ExitCode int
 }
 
 // Run calls s.RunWithInput with an empty stdin.
@@ -58,7 +63,12 @@ func (s *System) Run(log *zap.Logger, args ...string) RunResult {
 // providing in as the command's standard input,
 // and returns a RunResult that has its Stdout and Stderr populated.
 func (s *System) RunWithInput(log *zap.Logger, in io.Reader, args ...string) RunResult {
-	rootCmd := cmd.NewRootCmd(log, zap.NewAtomicLevel(), s.clientOverrides)
+	var opts []cmd.RootCmdOption
+	if s.HTTPClient != nil {
+		opts = append(opts, cmd.WithHTTPClient(s.HTTPClient))
+	}
+
+	rootCmd := cmd.NewRootCmd(log, zap.NewAtomicLevel(), s.clientOverrides, opts...)
 	rootCmd.SetIn(in)
 	// cmd.Execute also sets SilenceUsage,
 	// so match that here for more correct assertions.
@@ -73,6 +83,9 @@ func (s *System) RunWithInput(log *zap.Logger, in io.Reader, args ...string) Run
 	rootCmd.SetArgs(args)
 
 	res.Err = rootCmd.Execute()
+	if res.Err != nil {
+		res.ExitCode = cmd.HandleError(rootCmd, res.Err)
+	}
 	return res
 }
 
@@ -21,7 +21,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/spf13/cobra"
 	"github.com/strangelove-ventures/lens/client"
 	"github.com/strangelove-ventures/lens/client/query"
@@ -61,14 +60,17 @@ func abciInfoCmd(a *appState) *cobra.Command {
 		Short:   "queries for block height, app name and app hash",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			query := query.Query{Client: cl, Options: query.DefaultOptions()}
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: query.DefaultOptions()}
 
 			res, err := query.ABCIInfo()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, res)
 		},
 	}
 	return cmd
@@ -81,7 +83,10 @@ func abciQueryCmd(a *appState) *cobra.Command {
 		Short:   "query the abci interface for tendermint directly",
 		Args:    cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			path := args[0]
 			data := args[1]
 			prove := false // TODO: Hookup to a flag
@@ -90,12 +95,12 @@ func abciQueryCmd(a *appState) *cobra.Command {
 				return err
 			}
 			options := query.QueryOptions{Pagination: client.DefaultPageRequest(), Height: height}
-			query := query.Query{Client: cl, Options: &options}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &options}
 			res, err := query.ABCIQuery(path, data, prove)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, res)
 		},
 	}
 	// TODO: add prove flag
@@ -109,22 +114,25 @@ func blockCmd(a *appState) *cobra.Command {
 		Short:   "query tendermint data for a block at a given height",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			height, err := ReadHeight(cmd.Flags())
 			if err != nil {
 				return err
 			}
 			options := query.QueryOptions{Pagination: client.DefaultPageRequest(), Height: height}
-			query := query.Query{Client: cl, Options: &options}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &options}
 
 			block, err := query.Block()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(block)
+			return writeOutput(cmd, a, block)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 	return cmd
 }
 
@@ -135,14 +143,17 @@ func blockByHashCmd(a *appState) *cobra.Command {
 		Short:   "query tendermint for a given block by hash",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			query := query.Query{Client: cl, Options: query.DefaultOptions()}
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: query.DefaultOptions()}
 			hash := args[0]
 			res, err := query.BlockByHash(hash)
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(res)
+			return writeOutput(cmd, a, res)
 		},
 	}
 	return cmd
@@ -155,22 +166,25 @@ func blockResultsCmd(a *appState) *cobra.Command {
 		Short:   "query tendermint tx results for a given block by height",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			height, err := ReadHeight(cmd.Flags())
 			if err != nil {
 				return err
 			}
 			options := query.QueryOptions{Pagination: client.DefaultPageRequest(), Height: height}
-			query := query.Query{Client: cl, Options: &options}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: &options}
 
 			block, err := query.BlockResults()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(block)
+			return writeOutput(cmd, a, block)
 		},
 	}
-	flags.AddQueryFlagsToCmd(cmd)
+	addQueryHeightFlag(cmd)
 	return cmd
 }
 
@@ -198,7 +212,10 @@ func consensusParamsCmd(a *appState) *cobra.Command {
 		Short:   "query tendermint consensus params at a given height",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			height, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return err
@@ -227,7 +244,10 @@ func consensusStateCmd(a *appState) *cobra.Command {
 		Short:   "query current tendermint consensus state",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			block, err := cl.RPCClient.ConsensusState(cmd.Context())
 			if err != nil {
 				return err
@@ -248,7 +268,10 @@ func dumpConsensusStateCmd(a *appState) *cobra.Command {
 		Short:   "query detailed version of current tendermint consensus state",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			block, err := cl.RPCClient.DumpConsensusState(cmd.Context())
 			if err != nil {
 				return err
@@ -269,7 +292,10 @@ func healthCmd(a *appState) *cobra.Command {
 		Short:   "query to see if node server is online",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			block, err := cl.RPCClient.Health(cmd.Context())
 			if err != nil {
 				return err
@@ -293,7 +319,10 @@ func netInfoCmd(a *appState) *cobra.Command {
 		Short:   "query for p2p network connection information",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			peers, err := cmd.Flags().GetBool("peers")
 			if err != nil {
 				return err
@@ -337,7 +366,10 @@ func numUnconfirmedTxs(a *appState) *cobra.Command {
 		Short:   "query for number of unconfirmed txs",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			limit, err := cmd.Flags().GetInt("limit")
 			if err != nil {
 				return err
@@ -365,14 +397,17 @@ func statusCmd(a *appState) *cobra.Command {
 		Short:   "query the status of a node",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
-			query := query.Query{Client: cl, Options: query.DefaultOptions()}
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: query.DefaultOptions()}
 
 			status, err := query.Status()
 			if err != nil {
 				return err
 			}
-			return cl.PrintObject(status)
+			return writeOutput(cmd, a, status)
 		},
 	}
 	return cmd
@@ -384,7 +419,10 @@ func queryTxCmd(a *appState) *cobra.Command {
 		Short: "query for a transaction by hash",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl := a.Config.GetDefaultClient()
+			cl, err := defaultChainClient(a)
+			if err != nil {
+				return err
+			}
 			prove, err := cmd.Flags().GetBool("prove")
 			if err != nil {
 				return err
@@ -395,7 +433,7 @@ func queryTxCmd(a *appState) *cobra.Command {
 				return err
 			}
 
-			return cl.PrintObject(block)
+			return writeOutput(cmd, a, block)
 		},
 	}
 	return proveFlag(cmd, a.Viper)
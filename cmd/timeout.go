@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timeoutFlag is the name of the root flag limiting how long a command may run.
+// Commands with a more specific --timeout flag of their own, such as the gRPC dial timeout of the dynamic commands,
+// shadow it, so that their flag applies instead.
+const timeoutFlag = "timeout"
+
+// startTimeout sets the deadline of --timeout on the context of cmd, if the flag is set,
+// so that the network operations of cmd are abandoned once it has passed.
+func (a *appState) startTimeout(cmd *cobra.Command) error {
+	timeout, err := cmd.Root().PersistentFlags().GetDuration(timeoutFlag)
+	if err != nil {
+		return err
+	}
+	if timeout < 0 {
+		return fmt.Errorf("invalid --%s %s: must not be negative", timeoutFlag, timeout)
+	}
+	if timeout == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	cmd.SetContext(ctx)
+	a.timeout = timeout
+	a.timeoutCtx = ctx
+	a.cancelTimeout = cancel
+	return nil
+}
+
+// stopTimeout releases the deadline set by startTimeout, if any.
+func (a *appState) stopTimeout() {
+	if a.cancelTimeout != nil {
+		a.cancelTimeout()
+		a.cancelTimeout = nil
+	}
+}
+
+// timeoutError returns err as a TimeoutError stating the operation that timed out,
+// if err was caused by the deadline of --timeout passing, or else err unchanged.
+func (a *appState) timeoutError(cmd *cobra.Command, err error) error {
+	if err == nil || a.timeoutCtx == nil || !errors.Is(a.timeoutCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if errors.As(err, new(TimeoutError)) {
+		return err
+	}
+	// gRPC status errors do not wrap the context error.
+	if !errors.Is(err, context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+		return err
+	}
+
+	op := "running " + cmd.CommandPath()
+	var interrupted client.InterruptedError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &interrupted):
+		op = interrupted.Op
+	case errors.As(err, &urlErr):
+		op = "waiting for a response from " + urlErr.URL
+	}
+	return TimeoutError{Timeout: a.timeout, Op: op, Err: err}
+}
+
+// withTimeoutErrors makes every command of the tree of cmd report the errors caused by --timeout as TimeoutErrors,
+// and release the deadline once it returns.
+func withTimeoutErrors(a *appState, cmd *cobra.Command) {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			defer a.stopTimeout()
+			return a.timeoutError(cmd, runE(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		withTimeoutErrors(a, sub)
+	}
+}
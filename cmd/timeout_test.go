@@ -0,0 +1,101 @@
+package cmd_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTimeout_RPC(t *testing.T) {
+	t.Parallel()
+
+	// An RPC endpoint that does not answer before the test ends.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	sys := NewSystem(t)
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "rpc-addr", srv.URL)
+
+	start := time.Now()
+	res := sys.Run(zaptest.NewLogger(t), "tendermint", "health", "--timeout", "200ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	var timeoutErr cmd.TimeoutError
+	require.ErrorAs(t, res.Err, &timeoutErr)
+	require.Equal(t, "timed out after 200ms waiting for a response from "+srv.URL, res.Err.Error())
+	require.Equal(t, cmd.ErrCodeConnection, res.ExitCode)
+
+	res = sys.Run(zaptest.NewLogger(t), "tendermint", "health", "--timeout", "-1s")
+	require.ErrorContains(t, res.Err, "invalid --timeout -1s: must not be negative")
+}
+
+func TestTimeout_Query(t *testing.T) {
+	t.Parallel()
+
+	// An RPC endpoint that does not answer before the test ends.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	sys := NewSystem(t)
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "rpc-addr", srv.URL)
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "timeout", "10s")
+
+	// The queries are bounded by --timeout, rather than only by the longer timeout of the chain.
+	start := time.Now()
+	res := sys.Run(zaptest.NewLogger(t), "query", "bank", "total-supply", "--timeout", "200ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	var timeoutErr cmd.TimeoutError
+	require.ErrorAs(t, res.Err, &timeoutErr)
+	require.Equal(t, 200*time.Millisecond, timeoutErr.Timeout)
+}
+
+func TestTimeout_TxInclusion(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	file := filepath.Join(t.TempDir(), "signed.b64")
+	require.NoError(t, os.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(signedSendTx(t, sys))), 0o600))
+
+	// The transaction is accepted, but never included.
+	mc := new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab, 0xcd}}, nil)
+	mc.On("Tx", mock.Anything, mock.Anything, false).Return(nil, errors.New("tx not found"))
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.Run(zaptest.NewLogger(t), "tx", "broadcast", file, "--encoding", "base64", "--timeout", "300ms")
+	require.EqualError(t, res.Err, "timed out after 300ms waiting for tx inclusion")
+	require.Equal(t, cmd.ErrCodeConnection, res.ExitCode)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	traceFlag       = "trace"
+	traceBodiesFlag = "trace-bodies"
+)
+
+// tracing reports whether the gRPC calls of the command are traced, by --trace or --trace-bodies,
+// and whether their bodies are, by --trace-bodies.
+func tracing(cmd *cobra.Command) (trace, bodies bool) {
+	flags := cmd.Root().PersistentFlags()
+	trace, _ = flags.GetBool(traceFlag)
+	bodies, _ = flags.GetBool(traceBodiesFlag)
+	return trace || bodies, bodies
+}
@@ -0,0 +1,53 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/Balance", func(data bytes.HexBytes) bool {
+		return strings.Contains(string(data), ZeroCosmosAddr)
+	}, &banktypes.QueryBalanceResponse{Balance: &sdk.Coin{Denom: "uatom", Amount: sdk.NewInt(42)}})
+	mockABCIQuery(t, mc, "/cosmos.bank.v1beta1.Query/DenomsMetadata", func(bytes.HexBytes) bool { return true },
+		&banktypes.QueryDenomsMetadataResponse{Pagination: &query.PageResponse{}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{RPCClient: mc})
+
+	// Without --trace, the queries of the chain client are not logged.
+	core, logs := observer.New(zap.DebugLevel)
+	res := sys.Run(zap.New(core), "q", "bank", "balances", ZeroCosmosAddr, "--denom", "uatom")
+	require.NoError(t, res.Err)
+	require.Zero(t, logs.FilterMessage("gRPC call").Len())
+
+	core, logs = observer.New(zap.DebugLevel)
+	res = sys.Run(zap.New(core), "q", "bank", "balances", ZeroCosmosAddr, "--denom", "uatom", "--trace")
+	require.NoError(t, res.Err)
+	calls := logs.FilterMessage("gRPC call").FilterField(zap.String("method", "/cosmos.bank.v1beta1.Query/Balance")).AllUntimed()
+	require.Len(t, calls, 1)
+	fields := calls[0].ContextMap()
+	require.Equal(t, "OK", fields["code"])
+	require.Positive(t, fields["response_bytes"])
+	require.NotContains(t, fields, "response")
+
+	// --trace-bodies implies --trace.
+	core, logs = observer.New(zap.DebugLevel)
+	res = sys.Run(zap.New(core), "q", "bank", "balances", ZeroCosmosAddr, "--denom", "uatom", "--trace-bodies")
+	require.NoError(t, res.Err)
+	fields = logs.FilterMessage("gRPC call").FilterField(zap.String("method", "/cosmos.bank.v1beta1.Query/Balance")).AllUntimed()[0].ContextMap()
+	require.Contains(t, fields["request"], ZeroCosmosAddr)
+	require.Contains(t, fields["response"], `"amount":"42"`)
+}
@@ -1,9 +1,452 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const dryRunFlag = "dry-run"
+
+// addDryRunFlag adds the flag read by txChainClient and sendOrWriteMsgs to cmd.
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool(dryRunFlag, false, "write the messages of the transaction without signing or broadcasting it")
+}
+
+// txChainClient returns the client of the chain named chainName, set to sign with the given key,
+// or with the chain's key if it is empty, and the address of the signer.
+// Unless --dry-run is set, the key must be in the keyring of the chain;
+// otherwise it may also be an address.
+func txChainClient(cmd *cobra.Command, a *appState, chainName, key string) (*client.ChainClient, sdk.AccAddress, error) {
+	cl, err := chainClientByName(a, chainName)
+	if err != nil {
+		return nil, nil, err
+	}
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key != "" {
+		cl.Config.Key = key
+	}
+	if !dryRun && !cl.KeyExists(cl.Config.Key) {
+		return nil, nil, errKeyToSignNotFound(cl, chainName)
+	}
+	addr, err := cl.AccountFromKeyOrAddress(cl.Config.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cl, addr, nil
+}
+
+// txArgs splits the optional [chain-name] argument of a tx command from its n other arguments,
+// returning the default chain if it is omitted.
+func txArgs(a *appState, args []string, n int) (string, []string) {
+	if len(args) > n {
+		return args[0], args[1:]
+	}
+	return a.Config.DefaultChain, args
+}
+
+// sendOrWriteMsgs signs and broadcasts a transaction of msgs with the key of cl, and prints its response.
+// With --dry-run, the messages are written instead.
+func sendOrWriteMsgs(cmd *cobra.Command, a *appState, cl *client.ChainClient, msgs []sdk.Msg) error {
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return writeMsgs(cmd, a, cl, msgs)
+	}
+
+	memo, err := cmd.Flags().GetString(flagMemo)
+	if err != nil {
+		return err
+	}
+	return cl.HandleAndPrintMsgSend(cl.SendMsgs(cmd.Context(), msgs, memo))
+}
+
+// writeMsgs writes msgs as a JSON list, each with its type URL.
+func writeMsgs(cmd *cobra.Command, a *appState, cl *client.ChainClient, msgs []sdk.Msg) error {
+	out := make([]json.RawMessage, len(msgs))
+	for i, msg := range msgs {
+		bz, err := cl.Codec.Marshaler.MarshalInterfaceJSON(msg)
+		if err != nil {
+			return err
+		}
+		out[i] = bz
+	}
+	return writeOutput(cmd, a, out)
+}
+
+// Flags added by addTxOptionsFlags.
+const (
+	txNoteFlag          = "note"
+	txGasFlag           = "gas"
+	txFeesFlag          = "fees"
+	txGasPricesFlag     = "gas-prices"
+	txBroadcastModeFlag = "broadcast-mode"
+	txBlockTimeoutFlag  = "block-timeout"
+	txGenerateOnlyFlag  = "generate-only"
+	txNoSeqCacheFlag    = "no-sequence-cache"
+	txMaxGasPriceFlag   = "max-gas-price"
+	txFeeGranterFlag    = "fee-granter"
+	txFeePayerFlag      = "fee-payer"
+	txSkipFeegrantFlag  = "skip-feegrant-check"
+	txShowEventsFlag    = "show-events"
 )
 
+// txOptionsHelp describes the flags added by addTxOptionsFlags, for the long help of commands using sendTx.
+const txOptionsHelp = `The gas of the transaction is estimated by simulating it, multiplied by the chain's gas adjustment,
+unless --gas is given. The fees are computed from the gas and the chain's gas prices,
+unless --fees or --gas-prices is given. With --gas-prices auto, or the chain's auto-gas-prices,
+the gas prices are discovered from the chain's fee market module, or else from the node's minimum gas prices,
+or else are the chain's gas-prices; --max-gas-price then fails the command if they are higher.
+
+With --fee-granter, or the chain's fee-granter, the fees are paid by the granter through a fee allowance
+to the key, which is checked first unless --skip-feegrant-check is given.
+With --fee-payer, the fees are paid by another account, which must also sign the transaction:
+write it with --generate-only, and have both sign it with tx sign --append --sign-mode amino-json.
+
+In the default broadcast mode, block, the inclusion of the transaction is waited for,
+and the command fails if it is not included before --block-timeout, or if its execution fails.
+With --generate-only, the unsigned transaction is written instead of being broadcast,
+and with --dry-run, only its messages are written.
+
+The memo is a template, in which {{.ChainID}}, {{.Timestamp}} and {{.Hostname}} are replaced
+when the transaction is built, and the command fails if it is longer than the chain's max_memo_characters.
+With --show-events, the events of the included transaction of the given types, or of all types, are written with it.
+
+A transaction rejected for its account sequence, as when it is sent before a previous one is included,
+is signed again with the sequence the chain expects.`
+
+// addTxOptionsFlags adds the flags read by sendTx to cmd.
+func addTxOptionsFlags(a *appState, cmd *cobra.Command) {
+	addDryRunFlag(cmd)
+	memoFlag(a.Viper, cmd)
+	cmd.Flags().String(txNoteFlag, "", "alias of --memo")
+	cmd.Flags().String(txGasFlag, "auto", `the gas limit of the transaction, or "auto" to estimate it by simulating the transaction`)
+	cmd.Flags().String(txFeesFlag, "", "the fees to pay, instead of the fees computed from the gas prices (e.g. 5000uatom)")
+	cmd.Flags().String(txGasPricesFlag, "", `the gas prices to compute the fees with, instead of the chain's gas prices (e.g. 0.025uatom), or "auto" to discover them from the chain`)
+	cmd.Flags().String(txMaxGasPriceFlag, "", "the highest discovered gas price to accept (e.g. 0.1uatom)")
+	cmd.Flags().String(txFeeGranterFlag, "", "the address paying the fees through a fee allowance to the key (default: the chain's fee-granter)")
+	cmd.Flags().String(txFeePayerFlag, "", "the address paying the fees, which must also sign the transaction")
+	cmd.Flags().Bool(txSkipFeegrantFlag, false, "do not check that the fee granter has granted a fee allowance to the key")
+	addBroadcastFlags(cmd)
+	cmd.Flags().Bool(txGenerateOnlyFlag, false, "write the unsigned transaction as JSON instead of signing and broadcasting it")
+	cmd.Flags().Bool(txNoSeqCacheFlag, false, "always query the account number and sequence, instead of caching them between transactions")
+}
+
+// addBroadcastFlags adds the flags read by broadcastOptionsFromFlags to cmd.
+func addBroadcastFlags(cmd *cobra.Command) {
+	cmd.Flags().String(txBroadcastModeFlag, client.BroadcastBlock, "how long to wait for the transaction (block: until it is included, sync: until it passes CheckTx, async: not at all)")
+	cmd.Flags().Duration(txBlockTimeoutFlag, 0, "how long to wait for the transaction to be included, in block broadcast mode (default: the chain's block-timeout)")
+	cmd.Flags().StringSlice(txShowEventsFlag, nil, `the types of the events of the included transaction to write (e.g. transfer,message), or "all"`)
+}
+
+// broadcastOptionsFromFlags returns the broadcast mode and block timeout set by the flags of addBroadcastFlags.
+func broadcastOptionsFromFlags(cmd *cobra.Command) (mode string, blockTimeout time.Duration, err error) {
+	if mode, err = cmd.Flags().GetString(txBroadcastModeFlag); err != nil {
+		return "", 0, err
+	}
+	switch mode {
+	case client.BroadcastBlock, client.BroadcastSync, client.BroadcastAsync:
+	default:
+		return "", 0, fmt.Errorf("unknown broadcast mode %q (must be one of %s, %s, %s)", mode, client.BroadcastBlock, client.BroadcastSync, client.BroadcastAsync)
+	}
+	if blockTimeout, err = cmd.Flags().GetDuration(txBlockTimeoutFlag); err != nil {
+		return "", 0, err
+	}
+	if blockTimeout < 0 {
+		return "", 0, fmt.Errorf("invalid --%s %s: must not be negative", txBlockTimeoutFlag, blockTimeout)
+	}
+	return mode, blockTimeout, nil
+}
+
+// txOptionsFromFlags returns the transaction options set by the flags of addTxOptionsFlags,
+// whose addresses are those of the chain of cl.
+func txOptionsFromFlags(cmd *cobra.Command, cl *client.ChainClient) (client.TxOptions, error) {
+	var opts client.TxOptions
+	f := cmd.Flags()
+
+	memo, err := f.GetString(flagMemo)
+	if err != nil {
+		return opts, err
+	}
+	note, err := f.GetString(txNoteFlag)
+	if err != nil {
+		return opts, err
+	}
+	if memo != "" && note != "" && memo != note {
+		return opts, fmt.Errorf("--memo and --note are aliases, and cannot be given different values")
+	}
+	opts.Memo = memo
+	if opts.Memo == "" {
+		opts.Memo = note
+	}
+	opts.MemoTemplate = true
+
+	gas, err := f.GetString(txGasFlag)
+	if err != nil {
+		return opts, err
+	}
+	if gas != "auto" && gas != "" {
+		opts.Gas, err = strconv.ParseUint(gas, 10, 64)
+		if err != nil || opts.Gas == 0 {
+			return opts, fmt.Errorf(`invalid gas %q: must be "auto" or a positive integer`, gas)
+		}
+	}
+
+	if opts.Fees, err = f.GetString(txFeesFlag); err != nil {
+		return opts, err
+	}
+	if opts.GasPrices, err = f.GetString(txGasPricesFlag); err != nil {
+		return opts, err
+	}
+	if opts.Fees != "" && opts.GasPrices != "" {
+		return opts, fmt.Errorf("--fees and --gas-prices cannot both be given")
+	}
+	if opts.MaxGasPrice, err = f.GetString(txMaxGasPriceFlag); err != nil {
+		return opts, err
+	}
+	if opts.MaxGasPrice != "" {
+		if opts.GasPrices != client.GasPricesAuto && !(opts.GasPrices == "" && opts.Fees == "" && cl.Config.AutoGasPrices) {
+			return opts, fmt.Errorf("--%s only applies to discovered gas prices, with --%s %s", txMaxGasPriceFlag, txGasPricesFlag, client.GasPricesAuto)
+		}
+		if _, err := sdk.ParseDecCoins(opts.MaxGasPrice); err != nil {
+			return opts, fmt.Errorf("invalid --%s %q: %w", txMaxGasPriceFlag, opts.MaxGasPrice, err)
+		}
+	}
+
+	if opts.BroadcastMode, opts.BlockTimeout, err = broadcastOptionsFromFlags(cmd); err != nil {
+		return opts, err
+	}
+	if opts.NoSequenceCache, err = f.GetBool(txNoSeqCacheFlag); err != nil {
+		return opts, err
+	}
+
+	for flag, addr := range map[string]*sdk.AccAddress{txFeeGranterFlag: &opts.FeeGranter, txFeePayerFlag: &opts.FeePayer} {
+		s, err := f.GetString(flag)
+		if err != nil {
+			return opts, err
+		}
+		if s == "" {
+			continue
+		}
+		if *addr, err = cl.DecodeBech32AccAddr(s); err != nil {
+			return opts, fmt.Errorf("invalid --%s %q for account prefix %q: %w", flag, s, cl.Config.AccountPrefix, err)
+		}
+	}
+	return opts, nil
+}
+
+// sendTx simulates, signs, and broadcasts a transaction of msgs with the key of cl, as set by the flags of addTxOptionsFlags,
+// and writes the result.
+// With --generate-only, the unsigned transaction is written instead, and with --dry-run, only msgs are.
+func sendTx(cmd *cobra.Command, a *appState, cl *client.ChainClient, msgs ...sdk.Msg) error {
+	opts, err := txOptionsFromFlags(cmd, cl)
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return writeMsgs(cmd, a, cl, msgs)
+	}
+
+	generateOnly, err := cmd.Flags().GetBool(txGenerateOnlyFlag)
+	if err != nil {
+		return err
+	}
+	if err := checkFeeOptions(cmd, a, cl, opts, generateOnly); err != nil {
+		return err
+	}
+	if generateOnly {
+		_, txb, err := cl.BuildUnsignedTx(cmd.Context(), msgs, opts)
+		if err != nil {
+			return err
+		}
+		bz, err := cl.Codec.TxConfig.TxJSONEncoder()(txb.GetTx())
+		if err != nil {
+			return err
+		}
+		return writeOutput(cmd, a, json.RawMessage(bz))
+	}
+
+	res, err := cl.SendMsgsWithOptions(cmd.Context(), msgs, opts)
+	if res == nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	if werr := writeOutput(cmd, a, txResultWithEvents(cmd, res)); werr != nil {
+		return werr
+	}
+	// A failed transaction is written before its error, which sets the exit code.
+	return err
+}
+
+// checkFeeOptions checks the fee payer and fee granter of the transaction of opts, signed by the key of cl.
+// A fee payer other than the key must also sign the transaction, so it can only be generated.
+// Unless --skip-feegrant-check is given, a warning is logged if the fee granter has not granted
+// an unexpired fee allowance to the key.
+func checkFeeOptions(cmd *cobra.Command, a *appState, cl *client.ChainClient, opts client.TxOptions, generateOnly bool) error {
+	signer, err := cl.GetKeyAddress()
+	if err != nil {
+		return err
+	}
+	if opts.FeePayer != nil && !opts.FeePayer.Equals(signer) && !generateOnly {
+		return fmt.Errorf(
+			"the fee payer %s must also sign the transaction: write it with --%s, and sign it with tx sign --%s --%s amino-json",
+			cl.MustEncodeAccAddr(opts.FeePayer), txGenerateOnlyFlag, txAppendFlag, txSignModeFlag,
+		)
+	}
+
+	skip, err := cmd.Flags().GetBool(txSkipFeegrantFlag)
+	if err != nil {
+		return err
+	}
+	granter := opts.FeeGranter
+	if granter == nil && cl.Config.FeeGranter != "" {
+		if granter, err = cl.DecodeBech32AccAddr(cl.Config.FeeGranter); err != nil {
+			return fmt.Errorf("invalid fee-granter %q of chain %s: %w", cl.Config.FeeGranter, cl.Config.ChainID, err)
+		}
+	}
+	if skip || granter == nil || granter.Equals(signer) {
+		return nil
+	}
+
+	granterAddr, granteeAddr := cl.MustEncodeAccAddr(granter), cl.MustEncodeAccAddr(signer)
+	q := query.Query{Ctx: cmd.Context(), Client: cl, Options: &query.QueryOptions{}}
+	grants, err := q.Feegrant_AllAllowances(granteeAddr)
+	if err != nil {
+		a.Log.Warn(
+			"Failed to check the fee allowance of the fee granter",
+			zap.String("granter", granterAddr),
+			zap.String("grantee", granteeAddr),
+			zap.Error(err),
+		)
+		return nil
+	}
+	now := time.Now()
+	for _, g := range grants {
+		if g.Granter == granterAddr && !summarizeFeeAllowance(cl, g, now).Expired {
+			return nil
+		}
+	}
+	a.Log.Warn(
+		"The fee granter has not granted a fee allowance to the key, so the transaction will likely fail; skip this check with --"+txSkipFeegrantFlag,
+		zap.String("granter", granterAddr),
+		zap.String("grantee", granteeAddr),
+	)
+	return nil
+}
+
+// txResult summarizes the response of a broadcast transaction.
+type txResult struct {
+	TxHash    string `json:"txhash"`
+	Height    int64  `json:"height"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	GasWanted int64  `json:"gas_wanted"`
+	GasUsed   int64  `json:"gas_used"`
+	RawLog    string `json:"raw_log"`
+	// Events are the events shown with --show-events.
+	Events []txEvent `json:"events,omitempty"`
+}
+
+// txEvent is an event emitted by a transaction.
+type txEvent struct {
+	Type       string        `json:"type"`
+	Attributes []txEventAttr `json:"attributes"`
+}
+
+type txEventAttr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func newTxResult(res *sdk.TxResponse) txResult {
+	return txResult{
+		TxHash:    res.TxHash,
+		Height:    res.Height,
+		Code:      res.Code,
+		Codespace: res.Codespace,
+		GasWanted: res.GasWanted,
+		GasUsed:   res.GasUsed,
+		RawLog:    res.RawLog,
+	}
+}
+
+// txResultWithEvents returns the result of res, with the events of the types given by the --show-events flag of cmd.
+// Commands without the flag show no events.
+func txResultWithEvents(cmd *cobra.Command, res *sdk.TxResponse) txResult {
+	r := newTxResult(res)
+	types, _ := cmd.Flags().GetStringSlice(txShowEventsFlag)
+	if len(types) == 0 {
+		return r
+	}
+	all := false
+	show := make(map[string]bool, len(types))
+	for _, t := range types {
+		if t == "all" {
+			all = true
+		}
+		show[t] = true
+	}
+	for _, e := range res.Events {
+		if !all && !show[e.Type] {
+			continue
+		}
+		event := txEvent{Type: e.Type, Attributes: []txEventAttr{}}
+		for _, attr := range e.Attributes {
+			event.Attributes = append(event.Attributes, txEventAttr{Key: attr.Key, Value: attr.Value})
+		}
+		r.Events = append(r.Events, event)
+	}
+	return r
+}
+
+var _ fmt.Stringer = txResult{}
+
+// String returns the fields of the result one per line.
+// The height and gas are omitted when the transaction was not included in a block.
+func (r txResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "TxHash:\t%s\n", r.TxHash)
+	if r.Height != 0 {
+		fmt.Fprintf(w, "Height:\t%d\n", r.Height)
+		fmt.Fprintf(w, "Gas used:\t%d / %d\n", r.GasUsed, r.GasWanted)
+	}
+	if r.Codespace != "" {
+		fmt.Fprintf(w, "Code:\t%d (%s)\n", r.Code, r.Codespace)
+	} else {
+		fmt.Fprintf(w, "Code:\t%d\n", r.Code)
+	}
+	fmt.Fprintf(w, "Raw log:\t%s\n", orDash(r.RawLog))
+	if len(r.Events) > 0 {
+		fmt.Fprintln(w, "Events:")
+		for _, e := range r.Events {
+			for _, attr := range e.Attributes {
+				fmt.Fprintf(w, "  %s.%s\t%s\n", e.Type, attr.Key, attr.Value)
+			}
+		}
+	}
+	w.Flush()
+	return b.String()
+}
+
 // TxCommand registers a new tx command.
 func txCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
@@ -16,10 +459,20 @@ func txCmd(a *appState) *cobra.Command {
 		authzTxCmd(a),
 		bankTxCmd(a),
 		distributionTxCmd(a),
-		feegrantTxCmd(),
-		govTxCmd(),
+		feegrantTxCmd(a),
+		govTxCmd(a),
+		groupTxCmd(a),
+		icaTxCmd(a),
+		wasmTxCmd(a),
 		stakingTxCmd(a),
+		vestingTxCmd(a),
 		slashingTxCmd(),
+		txBroadcastCmd(a),
+		txMultisignCmd(a),
+		txBatchCmd(a),
+		txSignCmd(a),
+		txSignDocCmd(a),
+		txSimulateCmd(a),
 	)
 
 	return cmd
@@ -64,7 +517,10 @@ func bankTxCmd(a *appState) *cobra.Command {
 		Short:   "bank transaction commands",
 	}
 
-	cmd.AddCommand(bankSendCmd(a))
+	cmd.AddCommand(
+		bankSendCmd(a),
+		bankMultisendCmd(a),
+	)
 	memoFlag(a.Viper, cmd)
 	return cmd
 }
@@ -79,6 +535,7 @@ func distributionTxCmd(a *appState) *cobra.Command {
 
 	cmd.AddCommand(
 		distributionWithdrawRewardsCmd(a),
+		distributionWithdrawAllRewardsCmd(a),
 		// distributionSetWithdrawAddressCmd(),
 		// distributionFundCommunityPoolCmd(),
 	)
@@ -87,7 +544,7 @@ func distributionTxCmd(a *appState) *cobra.Command {
 }
 
 // feegrantTxCmd returns the fee grant tx commands for this module
-func feegrantTxCmd() *cobra.Command {
+func feegrantTxCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "feegrant",
 		Aliases: []string{"f", "fee"},
@@ -95,8 +552,8 @@ func feegrantTxCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-	// feegrantFeeGrantCmd(),
-	// feegrantRevoteFeeGrantCmd(),
+		feegrantGrantCmd(a),
+		feegrantRevokeCmd(a),
 	)
 
 	return cmd
@@ -112,17 +569,17 @@ func stakingTxCmd(a *appState) *cobra.Command {
 
 	cmd.AddCommand(
 		stakingDelegateCmd(a),
+		stakingUndelegateCmd(a),
 		stakingRedelegateCmd(a),
 		// stakingCreateValidatorCmd(),
 		// stakingEditValidatorCmd(),
-		// stakingUnbondCmd(),
 	)
 
 	return cmd
 }
 
 // govTxCmd returns the gov tx commands for this module
-func govTxCmd() *cobra.Command {
+func govTxCmd(a *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "governance",
 		Aliases: []string{"gov", "g"},
@@ -130,10 +587,9 @@ func govTxCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-	// govSubmitProposalCmd(),
-	// govDepositCmd(),
-	// govVoteCmd(),
-	// govWeightedVoteCmd(),
+		// govSubmitProposalCmd(),
+		govDepositCmd(a),
+		govVoteCmd(a),
 	)
 
 	return cmd
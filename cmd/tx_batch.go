@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const txMaxMsgsPerTxFlag = "max-msgs-per-tx"
+
+func txBatchCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch [chain-name] <from-key> <file>",
+		Short: "send the messages in a file in a single transaction",
+		Long: `Sign and broadcast a transaction of the messages in the file, with a key in the keyring of the given chain,
+or of the default chain. A file of "-" is read from standard input.
+
+The file holds a JSON list of messages, each either a message with its type URL, as written by --dry-run,
+or a shorthand object with a single field naming the message:
+
+  {"send": {"to": "<address>", "amount": "<coins>"}}
+  {"delegate": {"validator": "<operator-address>", "amount": "<coin>"}}
+  {"undelegate": {"validator": "<operator-address>", "amount": "<coin>"}}
+  {"vote": {"proposal": <proposal-id>, "option": "yes|no|abstain|no_with_veto"}}
+
+Every message must be signed by the key, which is the sender of the shorthand messages.
+
+The gas is estimated for the whole batch. With --max-msgs-per-tx, the messages are split into
+transactions of at most that many messages, sent one after another with consecutive sequences,
+and one line is printed for each transaction. The first failing transaction stops the batch.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx batch cosmoshub mykey msgs.json
+$ %s tx bank send mykey cosmos1... 1000uatom --dry-run > msgs.json
+$ echo '[{"send": {"to": "cosmos1...", "amount": "1uatom"}}]' | %s tx batch mykey -
+$ %s tx batch osmosis mykey msgs.json --max-msgs-per-tx 50 --broadcast-mode sync`,
+			appName, appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxMsgs, err := cmd.Flags().GetInt(txMaxMsgsPerTxFlag)
+			if err != nil {
+				return err
+			}
+			if maxMsgs < 0 {
+				return fmt.Errorf("invalid --%s %d: must not be negative", txMaxMsgsPerTxFlag, maxMsgs)
+			}
+
+			chainName, args := txArgs(a, args, 2)
+			cl, fromAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			bz, err := readFileOrStdin(cmd, args[1])
+			if err != nil {
+				return err
+			}
+			msgs, err := parseBatchMsgs(cl, fromAddr, bz)
+			if err != nil {
+				return fmt.Errorf("failed to read messages from %s: %w", args[1], err)
+			}
+
+			if maxMsgs == 0 || len(msgs) <= maxMsgs {
+				return sendTx(cmd, a, cl, msgs...)
+			}
+			return sendBatches(cmd, a, cl, msgs, maxMsgs)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().Int(txMaxMsgsPerTxFlag, 0, "split the messages into transactions of at most this many messages (default: a single transaction)")
+	return cmd
+}
+
+// sendBatches sends msgs in consecutive transactions of at most maxMsgs messages, as set by the flags of addTxOptionsFlags,
+// and writes the result of each transaction, stopping at the first one that fails.
+// With --dry-run, msgs are written instead.
+func sendBatches(cmd *cobra.Command, a *appState, cl *client.ChainClient, msgs []sdk.Msg, maxMsgs int) error {
+	opts, err := txOptionsFromFlags(cmd, cl)
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return writeMsgs(cmd, a, cl, msgs)
+	}
+	generateOnly, err := cmd.Flags().GetBool(txGenerateOnlyFlag)
+	if err != nil {
+		return err
+	}
+	if err := checkFeeOptions(cmd, a, cl, opts, generateOnly); err != nil {
+		return err
+	}
+	if generateOnly {
+		return fmt.Errorf("--%s cannot split a batch into several transactions: remove --%s", txGenerateOnlyFlag, txMaxMsgsPerTxFlag)
+	}
+
+	n := (len(msgs) + maxMsgs - 1) / maxMsgs
+	res := batchResult{Txs: n}
+	var sendErr error
+	for i := 0; i < n; i++ {
+		end := (i + 1) * maxMsgs
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		batch := msgs[i*maxMsgs : end]
+		txRes, err := cl.SendMsgsWithOptions(cmd.Context(), batch, opts)
+		if txRes == nil {
+			sendErr = fmt.Errorf("failed to send transaction %d of %d: %w", i+1, n, err)
+			break
+		}
+		res.Results = append(res.Results, batchTxResult{Msgs: len(batch), txResult: txResultWithEvents(cmd, txRes)})
+		if err != nil {
+			sendErr = err
+			break
+		}
+	}
+	if len(res.Results) > 0 {
+		if err := writeOutput(cmd, a, res); err != nil {
+			return err
+		}
+	}
+	return sendErr
+}
+
+// batchResult is the result of the transactions of a batch split by --max-msgs-per-tx.
+type batchResult struct {
+	// Txs is the number of transactions of the batch,
+	// of which Results holds those that were sent, up to the first failing one.
+	Txs     int             `json:"txs"`
+	Results []batchTxResult `json:"results"`
+}
+
+// batchTxResult is the result of one transaction of a batch.
+type batchTxResult struct {
+	Msgs int `json:"msgs"`
+	txResult
+}
+
+var _ fmt.Stringer = batchResult{}
+
+func (r batchResult) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	for i, tx := range r.Results {
+		fmt.Fprintf(w, "%d/%d\t%s\t%d msgs\tcode %d\t%s\n", i+1, r.Txs, tx.TxHash, tx.Msgs, tx.Code, orDash(tx.RawLog))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// parseBatchMsgs parses the JSON list of messages of tx batch, each signed by from.
+func parseBatchMsgs(cl *client.ChainClient, from sdk.AccAddress, bz []byte) ([]sdk.Msg, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(bz, &raws); err != nil {
+		return nil, fmt.Errorf("expected a JSON list of messages: %w", err)
+	}
+	if len(raws) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+
+	sender := cl.MustEncodeAccAddr(from)
+	msgs := make([]sdk.Msg, len(raws))
+	for i, raw := range raws {
+		msg, err := parseBatchMsg(cl, sender, raw)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		msgs[i] = msg
+	}
+
+	done := cl.SetSDKContext()
+	defer done()
+	for i, msg := range msgs {
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("message %d (%s): %w", i+1, sdk.MsgTypeURL(msg), err)
+		}
+		var signed bool
+		for _, signer := range msg.GetSigners() {
+			signed = signed || signer.Equals(from)
+		}
+		if !signed {
+			return nil, fmt.Errorf("message %d (%s) is not signed by %s", i+1, sdk.MsgTypeURL(msg), sender)
+		}
+	}
+	return msgs, nil
+}
+
+// batchStake holds the fields of the delegate and undelegate shorthand messages of tx batch.
+type batchStake struct {
+	Validator string
+	Amount    string
+}
+
+// parseBatchMsg parses one message of tx batch, either a message with its type URL or a shorthand message sent by sender.
+func parseBatchMsg(cl *client.ChainClient, sender string, raw json.RawMessage) (sdk.Msg, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("expected a JSON object: %w", err)
+	}
+	if _, ok := fields["@type"]; ok {
+		var msg sdk.Msg
+		if err := cl.Codec.Marshaler.UnmarshalInterfaceJSON(raw, &msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+	if len(fields) != 1 {
+		return nil, fmt.Errorf(`expected an "@type" field, or a single field naming a shorthand message (send, delegate, undelegate, or vote)`)
+	}
+
+	var name string
+	var value json.RawMessage
+	for name, value = range fields {
+	}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.DisallowUnknownFields()
+	switch name {
+	case "send":
+		var send struct {
+			To     string
+			Amount string
+		}
+		if err := dec.Decode(&send); err != nil {
+			return nil, fmt.Errorf("invalid send: %w", err)
+		}
+		to, err := cl.DecodeBech32AccAddr(send.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination address %q for account prefix %q: %w", send.To, cl.Config.AccountPrefix, err)
+		}
+		coins, err := sdk.ParseCoinsNormalized(send.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q: %w", send.Amount, err)
+		}
+		return &banktypes.MsgSend{FromAddress: sender, ToAddress: cl.MustEncodeAccAddr(to), Amount: coins}, nil
+	case "delegate", "undelegate":
+		var d batchStake
+		if err := dec.Decode(&d); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		valAddr, err := cl.DecodeBech32ValAddr(d.Validator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator operator address %q: %w", d.Validator, err)
+		}
+		amount, err := sdk.ParseCoinNormalized(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q: %w", d.Amount, err)
+		}
+		if name == "delegate" {
+			return &stakingtypes.MsgDelegate{DelegatorAddress: sender, ValidatorAddress: cl.MustEncodeValAddr(valAddr), Amount: amount}, nil
+		}
+		return &stakingtypes.MsgUndelegate{DelegatorAddress: sender, ValidatorAddress: cl.MustEncodeValAddr(valAddr), Amount: amount}, nil
+	case "vote":
+		var vote struct {
+			Proposal uint64
+			Option   string
+		}
+		if err := dec.Decode(&vote); err != nil {
+			return nil, fmt.Errorf("invalid vote: %w", err)
+		}
+		option, err := parseVoteOption(vote.Option)
+		if err != nil {
+			return nil, err
+		}
+		return &govtypes.MsgVote{ProposalId: vote.Proposal, Voter: sender, Option: option}, nil
+	default:
+		return nil, fmt.Errorf("unknown shorthand message %q (must be send, delegate, undelegate, or vote)", name)
+	}
+}
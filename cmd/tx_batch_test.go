@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTxBatch(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	msgs := `[
+		{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "` + ZeroCosmosAddr + `", "to_address": "` + ZeroCosmosAddr + `", "amount": [{"denom": "uatom", "amount": "1"}]},
+		{"send": {"to": "` + ZeroCosmosAddr + `", "amount": "2uatom"}},
+		{"vote": {"proposal": 82, "option": "yes"}}
+	]`
+	file := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(file, []byte(msgs), 0o600))
+
+	var dryRun []map[string]interface{}
+	res := sys.MustRun(t, "tx", "batch", "mykey", file, "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &dryRun))
+	require.Len(t, dryRun, 3)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", dryRun[1]["@type"])
+	require.Equal(t, ZeroCosmosAddr, dryRun[1]["from_address"])
+	require.Equal(t, "/cosmos.gov.v1beta1.MsgVote", dryRun[2]["@type"])
+
+	txConfig := client.MakeCodec(client.ModuleBasics, nil).TxConfig
+	var sent []authsigning.SigVerifiableTx
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx, err := txConfig.TxDecoder()(args.Get(1).(tmtypes.Tx))
+		require.NoError(t, err)
+		sent = append(sent, tx.(authsigning.SigVerifiableTx))
+	}).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{0xab}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// All the messages are sent in a single transaction.
+	sys.MustRun(t, "tx", "batch", "cosmoshub", "mykey", file, "--broadcast-mode", "sync")
+	require.Len(t, sent, 1)
+	require.Len(t, sent[0].GetMsgs(), 3)
+
+	// Split, the transactions are given consecutive sequences.
+	sent = nil
+	res = sys.MustRun(t, "tx", "batch", "mykey", file, "--broadcast-mode", "sync", "--max-msgs-per-tx", "2", "-o", "text")
+	require.Len(t, sent, 2)
+	require.Len(t, sent[0].GetMsgs(), 2)
+	require.Len(t, sent[1].GetMsgs(), 1)
+	for i, tx := range sent {
+		sigs, err := tx.GetSignaturesV2()
+		require.NoError(t, err)
+		require.EqualValues(t, 3+i, sigs[0].Sequence)
+	}
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "1/2"), lines[0])
+	require.Contains(t, lines[0], "AB")
+	require.Contains(t, lines[1], "1 msgs")
+
+	for msgs, msg := range map[string]string{
+		`{"send": {}}`:                         "expected a JSON list of messages",
+		`[]`:                                   "no messages",
+		`[{"swap": {}}]`:                       `message 1: unknown shorthand message "swap"`,
+		`[{"send": {"to": "x", "amout": ""}}]`: `message 1: invalid send: json: unknown field "amout"`,
+		`[{"send": {}, "vote": {}}]`:           `expected an "@type" field`,
+		`[{"delegate": {"validator": "cosmosvaloper1xyz", "amount": "1uatom"}}]`: `invalid validator operator address "cosmosvaloper1xyz"`,
+		`[{"@type": "/cosmos.bank.v1beta1.MsgSend", "from_address": "cosmos1qyqszqgpqyqszqgpqyqszqgpqyqszqgpjnp7du", "to_address": "` + ZeroCosmosAddr + `", "amount": [{"denom": "uatom", "amount": "1"}]}]`: "message 1 (/cosmos.bank.v1beta1.MsgSend) is not signed by " + ZeroCosmosAddr,
+	} {
+		res = sys.RunWithInput(zaptest.NewLogger(t), strings.NewReader(msgs), "tx", "batch", "mykey", "-")
+		require.ErrorContains(t, res.Err, msg, msgs)
+	}
+	res = sys.Run(zaptest.NewLogger(t), "tx", "batch", "mykey", file, "--max-msgs-per-tx", "2", "--generate-only")
+	require.ErrorContains(t, res.Err, "--generate-only cannot split a batch into several transactions")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"go.uber.org/zap"
+)
+
+const txEncodingFlag = "encoding"
+
+// Values of the --encoding flag.
+const (
+	txEncodingJSON   = "json"
+	txEncodingBase64 = "base64"
+)
+
+func txBroadcastCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast [chain-name] <file>",
+		Short: "broadcast a signed transaction",
+		Long: `Broadcast a transaction signed outside of this chain client to the given chain, or to the default chain,
+and write its result.
+
+The file holds the signed transaction as JSON, as written by --generate-only and signed,
+or with --encoding base64, as base64 encoded transaction bytes. A file of "-" is read from standard input.
+
+Every signature is verified against the chain ID of the chain and the account number of its signer,
+so that a transaction signed for another chain is not broadcast.
+A signature with another sequence than the current one of its account is only warned about,
+since the account may send other transactions first.
+
+In the default broadcast mode, block, the inclusion of the transaction is waited for,
+and the command fails if it is not included before --block-timeout, or if its execution fails.`,
+		Example: fmt.Sprintf(`$ %s tx broadcast cosmoshub signed.json
+$ cat signed.b64 | %s tx broadcast - --encoding base64 --broadcast-mode sync`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			encoding, err := cmd.Flags().GetString(txEncodingFlag)
+			if err != nil {
+				return err
+			}
+			mode, blockTimeout, err := broadcastOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			bz, err := readFileOrStdin(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTx(cl, bz, encoding)
+			if err != nil {
+				return fmt.Errorf("failed to decode transaction from %s: %w", args[0], err)
+			}
+
+			checks, err := cl.VerifyTxSignatures(tx)
+			if err != nil {
+				return err
+			}
+			for _, c := range checks {
+				if c.SignedSequence != c.AccountSequence {
+					a.Log.Warn(
+						"Transaction was signed with another sequence than the current one of the signer's account, and may be rejected",
+						zap.String("signer", c.Signer),
+						zap.Uint64("signed_sequence", c.SignedSequence),
+						zap.Uint64("account_sequence", c.AccountSequence),
+					)
+				}
+			}
+
+			txBytes, err := cl.Codec.TxConfig.TxEncoder()(tx)
+			if err != nil {
+				return err
+			}
+			res, err := cl.BroadcastTxWithMode(cmd.Context(), txBytes, mode, blockTimeout)
+			if err != nil {
+				return fmt.Errorf("failed to broadcast transaction: %w", err)
+			}
+			if err := writeOutput(cmd, a, txResultWithEvents(cmd, res)); err != nil {
+				return err
+			}
+			if res.Code != 0 {
+				return client.TxFailedError{Code: res.Code, Codespace: res.Codespace, TxHash: res.TxHash}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String(txEncodingFlag, txEncodingJSON, "the encoding of the transaction in the file (json or base64)")
+	addBroadcastFlags(cmd)
+	return cmd
+}
+
+// readFileOrStdin returns the content of the named file, or of the standard input of cmd if name is "-".
+func readFileOrStdin(cmd *cobra.Command, name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(name)
+}
+
+// decodeTx decodes the transaction bz, in the given encoding, with the codec of cl.
+func decodeTx(cl *client.ChainClient, bz []byte, encoding string) (sdk.Tx, error) {
+	switch encoding {
+	case txEncodingJSON:
+		return cl.Codec.TxConfig.TxJSONDecoder()(bz)
+	case txEncodingBase64:
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(bz)))
+		if err != nil {
+			return nil, err
+		}
+		return cl.Codec.TxConfig.TxDecoder()(raw)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q (must be %s or %s)", encoding, txEncodingJSON, txEncodingBase64)
+	}
+}
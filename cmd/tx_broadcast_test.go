@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// signedSendTx returns a transaction sending 10uatom from ZeroCosmosAddr to itself,
+// signed by the key of sys with account number 7 and sequence 3, as broadcast by tx bank send.
+func signedSendTx(t *testing.T, sys *System) []byte {
+	t.Helper()
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	var signed []byte
+	mc.On("BroadcastTxAsync", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		signed = args.Get(1).(tmtypes.Tx)
+	}).Return(&coretypes.ResultBroadcastTx{}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--broadcast-mode", "async")
+	require.NotEmpty(t, signed)
+	return signed
+}
+
+// mockAccount makes mc answer the query of the account of ZeroCosmosAddr with the given sequence.
+func mockAccount(t *testing.T, mc *mocks.Client, sequence uint64) {
+	t.Helper()
+
+	addr, err := sdk.GetFromBech32(ZeroCosmosAddr, "cosmos")
+	require.NoError(t, err)
+	account, err := codectypes.NewAnyWithValue(authtypes.NewBaseAccount(addr, nil, 7, sequence))
+	require.NoError(t, err)
+	mockABCIQuery(t, mc, "/cosmos.auth.v1beta1.Query/Account", func(bytes.HexBytes) bool { return true },
+		&authtypes.QueryAccountResponse{Account: account})
+}
+
+func TestTxBroadcast(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	signed := signedSendTx(t, sys)
+
+	dir := t.TempDir()
+	b64File := filepath.Join(dir, "signed.b64")
+	require.NoError(t, os.WriteFile(b64File, []byte(base64.StdEncoding.EncodeToString(signed)+"\n"), 0o600))
+	txConfig := client.MakeCodec(client.ModuleBasics, nil).TxConfig
+	tx, err := txConfig.TxDecoder()(signed)
+	require.NoError(t, err)
+	txJSON, err := txConfig.TxJSONEncoder()(tx)
+	require.NoError(t, err)
+	jsonFile := filepath.Join(dir, "signed.json")
+	require.NoError(t, os.WriteFile(jsonFile, txJSON, 0o600))
+
+	hash := bytes.HexBytes{0xab, 0xcd}
+	mc := new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mc.On("BroadcastTxSync", mock.Anything, tmtypes.Tx(signed)).Return(&coretypes.ResultBroadcastTx{Hash: hash}, nil)
+	mc.On("Tx", mock.Anything, []byte(hash), false).Return(&coretypes.ResultTx{
+		Hash:     hash,
+		Height:   42,
+		TxResult: abci.ResponseDeliverTx{GasUsed: 90000, GasWanted: 120000},
+		Tx:       signed,
+	}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The same transaction is broadcast from either encoding.
+	var out map[string]interface{}
+	res := sys.MustRun(t, "tx", "broadcast", "cosmoshub", b64File, "--encoding", "base64", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Equal(t, "ABCD", out["txhash"])
+	require.EqualValues(t, 42, out["height"])
+
+	res = sys.MustRunWithInput(t, strings.NewReader(string(txJSON)), "tx", "broadcast", "-", "--broadcast-mode", "sync", "-o", "json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &out))
+	require.Equal(t, "ABCD", out["txhash"])
+	mc.AssertNumberOfCalls(t, "Tx", 1)
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "broadcast", jsonFile, "--encoding", "base64")
+	require.ErrorContains(t, res.Err, "failed to decode transaction from "+jsonFile)
+}
+
+func TestTxBroadcast_Checks(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	file := filepath.Join(t.TempDir(), "signed.b64")
+	require.NoError(t, os.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(signedSendTx(t, sys))), 0o600))
+
+	// A sequence other than the current one of the account is warned about.
+	mc := new(mocks.Client)
+	mockAccount(t, mc, 4)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Code: 32, Codespace: "sdk", Log: "account sequence mismatch"}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	core, logs := observer.New(zap.WarnLevel)
+	res := sys.Run(zap.New(core), "tx", "broadcast", file, "--encoding", "base64", "--broadcast-mode", "sync")
+	require.Equal(t, cmd.ErrCodeTxFailed, res.ExitCode)
+	require.Equal(t, 1, logs.FilterField(zap.Uint64("account_sequence", 4)).FilterField(zap.Uint64("signed_sequence", 3)).Len())
+	require.Contains(t, res.Stdout.String(), "account sequence mismatch")
+
+	// A transaction signed for another chain is not broadcast.
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "chain-id", "othernet-1")
+	res = sys.Run(zaptest.NewLogger(t), "tx", "broadcast", file, "--encoding", "base64")
+	require.ErrorContains(t, res.Err, "signature of "+ZeroCosmosAddr+" does not verify for chain ID othernet-1 and account number 7")
+	mc.AssertNumberOfCalls(t, "BroadcastTxSync", 1)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/spf13/cobra"
+)
+
+func txMultisignCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multisign <chain-name> <file> <multisig-name> <signature-file>...",
+		Short: "combine the signatures of the keys of a multisig key into a signed transaction",
+		Long: `Combine the signatures in the signature files, each written by tx sign --multisig-of with one of the keys
+of the multisig key in the keyring of the given chain, into the signature of the transaction in the file
+by the multisig account, and write the signed transaction as JSON, to be broadcast with tx broadcast.
+A file of "-" is read from standard input.
+
+Every signature is verified, and there must be at least as many as the threshold of the multisig key.
+The account number and sequence of the multisig account are queried, unless given by --account-number and --sequence.
+With --offline, nothing is queried, and both must be given.`,
+		Example: fmt.Sprintf(`$ %s tx multisign cosmoshub unsigned.json team alice.json bob.json > signed.json
+$ %s tx multisign cosmoshub unsigned.json team alice.json carol.json --offline --account-number 7 --sequence 3`,
+			appName, appName),
+		Args: withUsage(cobra.MinimumNArgs(4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cl, err := chainClientByName(a, args[0])
+			if err != nil {
+				return err
+			}
+			file, multisigName, sigFiles := args[1], args[2], args[3:]
+			multisigPub, err := cl.MultisigKey(multisigName)
+			if err != nil {
+				return err
+			}
+
+			bz, err := readFileOrStdin(cmd, file)
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTx(cl, bz, txEncodingJSON)
+			if err != nil {
+				return fmt.Errorf("failed to decode transaction from %s: %w", file, err)
+			}
+			txb, err := cl.Codec.TxConfig.WrapTxBuilder(tx)
+			if err != nil {
+				return err
+			}
+
+			var sigs []signing.SignatureV2
+			for _, sigFile := range sigFiles {
+				bz, err := readFileOrStdin(cmd, sigFile)
+				if err != nil {
+					return err
+				}
+				fileSigs, err := cl.Codec.TxConfig.UnmarshalSignatureJSON(bz)
+				if err != nil {
+					return fmt.Errorf("failed to decode signatures from %s: %w", sigFile, err)
+				}
+				sigs = append(sigs, fileSigs...)
+			}
+
+			accountNumber, sequence, err := signerAccountFromFlags(cmd, cl, sdk.AccAddress(multisigPub.Address()), multisigName)
+			if err != nil {
+				return err
+			}
+			if err := cl.MultisignTx(txb, multisigName, sigs, accountNumber, sequence); err != nil {
+				return err
+			}
+			signed, err := cl.Codec.TxConfig.TxJSONEncoder()(txb.GetTx())
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, json.RawMessage(signed))
+		},
+	}
+	addSignerAccountFlags(cmd)
+	return cmd
+}
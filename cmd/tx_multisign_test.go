@@ -0,0 +1,93 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTxMultisign(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	for _, key := range []string{"alice", "bob", "carol", "dave"} {
+		sys.MustRun(t, "keys", "add", key)
+	}
+	res := sys.MustRun(t, "keys", "add-multisig", "team", "--threshold", "2", "--keys", "alice,bob,carol")
+	teamAddr := strings.TrimSpace(res.Stdout.String())
+	require.True(t, strings.HasPrefix(teamAddr, "cosmos1"), teamAddr)
+	res = sys.MustRun(t, "keys", "show", "team")
+	require.Equal(t, teamAddr, strings.TrimSpace(res.Stdout.String()))
+
+	// The address of a multisig key does not depend on the order of its keys.
+	res = sys.MustRun(t, "keys", "add-multisig", "team2", "--threshold", "2", "--keys", "carol,alice,bob")
+	require.Equal(t, teamAddr, strings.TrimSpace(res.Stdout.String()))
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res = sys.MustRun(t, "tx", "bank", "send", "team", ZeroCosmosAddr, "10uatom", "--generate-only")
+	dir := t.TempDir()
+	unsigned := filepath.Join(dir, "unsigned.json")
+	require.NoError(t, os.WriteFile(unsigned, res.Stdout.Bytes(), 0o600))
+
+	sigFiles := make(map[string]string)
+	for _, key := range []string{"alice", "bob"} {
+		res = sys.MustRun(t, "tx", "sign", unsigned, "--from", key, "--multisig-of", "team", "--offline", "--account-number", "7", "--sequence", "3")
+		sigFiles[key] = filepath.Join(dir, key+".json")
+		require.NoError(t, os.WriteFile(sigFiles[key], res.Stdout.Bytes(), 0o600))
+	}
+	// Signed for another sequence of the multisig account.
+	res = sys.MustRun(t, "tx", "sign", unsigned, "--from", "carol", "--multisig-of", "team", "--offline", "--account-number", "7", "--sequence", "4")
+	sigFiles["carol"] = filepath.Join(dir, "carol.json")
+	require.NoError(t, os.WriteFile(sigFiles["carol"], res.Stdout.Bytes(), 0o600))
+
+	res = sys.Run(zaptest.NewLogger(t), "tx", "multisign", "cosmoshub", unsigned, "team", sigFiles["alice"], "--offline", "--account-number", "7", "--sequence", "3")
+	require.ErrorContains(t, res.Err, `multisig key "team" needs 1 more signature(s): 1 of its threshold of 2 were given`)
+
+	res = sys.MustRun(t, "tx", "multisign", "cosmoshub", unsigned, "team", sigFiles["alice"], sigFiles["bob"], "--offline", "--account-number", "7", "--sequence", "3")
+	signed := filepath.Join(dir, "signed.json")
+	require.NoError(t, os.WriteFile(signed, res.Stdout.Bytes(), 0o600))
+
+	// The multisig signature verifies, so the transaction is broadcast.
+	mc = new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{1}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	sys.MustRun(t, "tx", "broadcast", signed, "--broadcast-mode", "sync")
+	mc.AssertNumberOfCalls(t, "BroadcastTxSync", 1)
+
+	for args, msg := range map[string]string{
+		"sign " + unsigned + " --from dave --multisig-of team --offline --account-number 7 --sequence 3":                                `key "dave" is not one of the keys of multisig key "team"`,
+		"sign " + unsigned + " --from alice --multisig-of dave --offline --account-number 7 --sequence 3":                               `key "dave" is not a multisig key`,
+		"sign " + unsigned + " --from alice --multisig-of team --sign-mode direct":                                                      "multisig accounts only support --sign-mode amino-json",
+		"multisign cosmoshub " + unsigned + " team " + sigFiles["alice"] + " " + sigFiles["carol"] + " --account-number 7 --sequence 3": "does not verify for multisig key \"team\" with chain ID cosmoshub-4, account number 7 and sequence 3",
+		"multisign cosmoshub " + unsigned + " team " + sigFiles["alice"] + " " + sigFiles["alice"] + " --account-number 7 --sequence 3": "signed more than once",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+
+	for args, msg := range map[string]string{
+		"team3 --threshold 4 --keys alice,bob,carol": "threshold 4 must be between 1 and the number of keys, 3",
+		"team3 --threshold 1 --keys alice,alice":     `key "alice" is given more than once`,
+		"team3 --threshold 1 --keys alice,erin":      `key "erin" not found`,
+		"team --threshold 1 --keys alice":            "a key with name team already exists",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"keys", "add-multisig"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
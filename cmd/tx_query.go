@@ -0,0 +1,439 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"go.uber.org/zap"
+)
+
+const txRawFlag = "raw"
+
+func queryTxByHashCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx [chain-name] <hash>",
+		Short: "query a transaction by hash and decode it",
+		Long: `Query a transaction of the given chain, or of the default chain, by its hex encoded hash,
+and decode it with the chain's codec, including the types registered by extra codecs.
+
+The height, gas, fee, and memo of the transaction are shown,
+followed by the type and content of each of its messages, with the events each message emitted.
+Messages of a type unknown to the codec are shown as their type URL and base64 encoded value,
+unless the chain's reflect-msgs is set: their types are then read from the chain's gRPC endpoint,
+and cached in the lens home directory (refreshed with --no-cache).
+
+With --raw, the transaction response is written as JSON instead.`,
+		Example: fmt.Sprintf(`$ %s query tx cosmoshub 1F0B3C...
+$ %s q tx 1F0B3C... --raw`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			if err := registerReflectedMsgs(cmd, a, chainName, cl); err != nil {
+				// The messages are still shown, as their type URL and value.
+				a.Log.Warn("Failed to read the message types of the chain", zap.String("chain", chainName), zap.Error(err))
+			}
+
+			res, err := cl.QueryTx(cmd.Context(), args[len(args)-1], false)
+			if err != nil {
+				return err
+			}
+
+			rawOutput, err := cmd.Flags().GetBool(txRawFlag)
+			if err != nil {
+				return err
+			}
+			if rawOutput {
+				tx, err := unmarshalTx(res.Tx)
+				if err != nil {
+					return err
+				}
+				bz, err := marshalTxResponse(cl, res, tx)
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd, a, bz)
+			}
+
+			result, err := decodeTxResult(cl, res)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	cmd.Flags().Bool(txRawFlag, false, "write the transaction response as JSON instead of the decoded transaction")
+	cmd = gRPCFlags(cmd, a.Viper)
+	// The gRPC endpoint only serves the descriptors of the messages, which do not depend on the height.
+	if err := cmd.Flags().MarkHidden(gRPCHeightFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// registerReflectedMsgs registers the messages of the chain not compiled into lens in the codec of cl,
+// from the descriptors served by the chain's gRPC endpoint, if its reflect-msgs is set.
+func registerReflectedMsgs(cmd *cobra.Command, a *appState, chainName string, cl *client.ChainClient) error {
+	if !cl.Config.ReflectMsgs {
+		return nil
+	}
+
+	gRPCAddr, err := chooseGRPCAddr(cmd, a, chainName)
+	if err != nil {
+		return err
+	}
+	m, err := newReflectedMsgsModule(cmd, a, gRPCAddr)
+	if err != nil {
+		return err
+	}
+	m.RegisterInterfaces(cl.Codec.InterfaceRegistry)
+	return nil
+}
+
+const (
+	txsEventsFlag = "events"
+	txsPageFlag   = "page"
+	txsAllFlag    = "all"
+)
+
+func queryTxsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "txs [chain-name] --events <clauses>",
+		Short: "search for transactions by the events they emitted",
+		Long: `Search the transactions of the given chain, or of the default chain, by the events they emitted.
+
+Each clause of --events compares an event attribute, named as type.attribute, to a value,
+with one of =, <, <=, >, or >=; the ordering operators require a numeric value.
+Clauses are separated by commas or given in repeated --events flags, and must all match.
+
+A page of --limit transactions is requested, or every page with --all.
+Each transaction is summarized on one line, or decoded in full with --output json or yaml.`,
+		Example: fmt.Sprintf(`$ %s query txs cosmoshub --events "transfer.recipient=cosmos1...,tx.height>1000000"
+$ %s q txs --events message.action=/cosmos.bank.v1beta1.MsgSend --events tx.height>=100 --all -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exprs, err := cmd.Flags().GetStringArray(txsEventsFlag)
+			if err != nil {
+				return err
+			}
+			query, err := parseEventQuery(exprs)
+			if err != nil {
+				return err
+			}
+			page, err := cmd.Flags().GetInt(txsPageFlag)
+			if err != nil {
+				return err
+			}
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+			all, err := cmd.Flags().GetBool(txsAllFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			result := txsResult{Txs: []decodedTx{}}
+			for {
+				res, err := cl.SearchTxs(cmd.Context(), query, page, limit)
+				if err != nil {
+					return err
+				}
+				result.TotalCount = res.TotalCount
+				for _, tx := range res.Txs {
+					decoded, err := decodeTxResult(cl, tx)
+					if err != nil {
+						return fmt.Errorf("transaction %s: %w", tx.Hash, err)
+					}
+					result.Txs = append(result.Txs, decoded)
+				}
+
+				if !all || len(res.Txs) == 0 || page*limit >= res.TotalCount {
+					break
+				}
+				page++
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	cmd.Flags().StringArray(txsEventsFlag, nil, "event clauses to match, such as transfer.recipient=<address> or tx.height>100, separated by commas")
+	cmd.Flags().Int(txsPageFlag, 1, "page of results to request, starting at 1")
+	cmd.Flags().Bool(txsAllFlag, false, "request every page of results, starting at --page")
+	if err := cmd.MarkFlagRequired(txsEventsFlag); err != nil {
+		panic(err)
+	}
+	return limitFlag(cmd, a.Viper)
+}
+
+// eventQueryOperators are the comparison operators accepted in event clauses,
+// with the longer ones first so that they are matched before their prefixes.
+var eventQueryOperators = []string{"<=", ">=", "<", ">", "="}
+
+// parseEventQuery parses the event clauses of exprs, each holding one or more comma separated clauses,
+// into a Tendermint query matching all of them.
+// Values are quoted as strings, unless they are integers.
+func parseEventQuery(exprs []string) (string, error) {
+	var conditions []string
+	for _, expr := range exprs {
+		for _, clause := range strings.Split(expr, ",") {
+			clause = strings.TrimSpace(clause)
+			if clause == "" {
+				continue
+			}
+			condition, err := parseEventClause(clause)
+			if err != nil {
+				return "", fmt.Errorf("invalid event clause %q: %w", clause, err)
+			}
+			conditions = append(conditions, condition)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", errors.New("must declare at least one event to search")
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// parseEventClause parses a single key-operator-value clause into a Tendermint query condition.
+func parseEventClause(clause string) (string, error) {
+	i := strings.IndexAny(clause, "<>=")
+	if i < 0 {
+		return "", errors.New("expected key=value, or a comparison such as key>value")
+	}
+	var op string
+	for _, o := range eventQueryOperators {
+		if strings.HasPrefix(clause[i:], o) {
+			op = o
+			break
+		}
+	}
+	key := strings.TrimSpace(clause[:i])
+	value := strings.TrimSpace(clause[i+len(op):])
+
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("key %q must be an event type and attribute, such as transfer.recipient", key)
+	}
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, " \t'\"") {
+			return "", fmt.Errorf("key %q must be an event type and attribute, such as transfer.recipient", key)
+		}
+	}
+	if value == "" {
+		return "", errors.New("missing value")
+	}
+	if strings.ContainsAny(value, "<>='") {
+		return "", fmt.Errorf("value %q must not contain <, >, =, or '", value)
+	}
+
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return key + op + value, nil
+	}
+	if op != "=" {
+		return "", fmt.Errorf("value %q must be an integer to compare with %s", value, op)
+	}
+	return key + op + "'" + value + "'", nil
+}
+
+// txsResult is the result of query txs.
+type txsResult struct {
+	TotalCount int         `json:"total_count"`
+	Txs        []decodedTx `json:"txs"`
+}
+
+var _ fmt.Stringer = txsResult{}
+
+// String returns one line per transaction, with its height, hash, first message type, and fee,
+// followed by the number of matching transactions if they were not all listed.
+func (r txsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "HEIGHT\tHASH\tMESSAGE\tFEE")
+	for _, tx := range r.Txs {
+		msgType := "-"
+		if len(tx.Messages) > 0 {
+			msgType = tx.Messages[0].Message.Type
+			if len(tx.Messages) > 1 {
+				msgType += fmt.Sprintf(" (+%d)", len(tx.Messages)-1)
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", tx.Height, tx.Hash, msgType, orDash(tx.Fee.String()))
+	}
+	w.Flush()
+	if len(r.Txs) < r.TotalCount {
+		fmt.Fprintf(&b, "Listed %d of %d matching transactions.\n", len(r.Txs), r.TotalCount)
+	}
+	return b.String()
+}
+
+// unmarshalTx unmarshals the parts of an encoded tx.
+// The parts are unmarshaled on their own rather than with the codec,
+// which would fail on the first message of an unknown type.
+func unmarshalTx(bz []byte) (*txtypes.Tx, error) {
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(bz); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	var body txtypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction body: %w", err)
+	}
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction auth info: %w", err)
+	}
+	return &txtypes.Tx{Body: &body, AuthInfo: &authInfo, Signatures: raw.Signatures}, nil
+}
+
+// decodeTxResult decodes the tx of res with the codec of cl,
+// with the events of each message parsed from the result log.
+func decodeTxResult(cl *client.ChainClient, res *coretypes.ResultTx) (decodedTx, error) {
+	tx, err := unmarshalTx(res.Tx)
+	if err != nil {
+		return decodedTx{}, err
+	}
+
+	result := decodedTx{
+		Hash:      res.Hash.String(),
+		Height:    res.Height,
+		Code:      res.TxResult.Code,
+		Codespace: res.TxResult.Codespace,
+		GasWanted: res.TxResult.GasWanted,
+		GasUsed:   res.TxResult.GasUsed,
+		Memo:      tx.Body.Memo,
+		Messages:  make([]decodedTxMessage, len(tx.Body.Messages)),
+	}
+	if tx.AuthInfo.Fee != nil {
+		result.Fee = tx.AuthInfo.Fee.Amount
+	}
+
+	// Successful transactions log the events of each message;
+	// the log of failed ones is the error instead.
+	events := make(map[uint32]sdk.StringEvents)
+	if logs, err := sdk.ParseABCILogs(res.TxResult.Log); err == nil {
+		for _, l := range logs {
+			events[l.MsgIndex] = append(events[l.MsgIndex], l.Events...)
+		}
+	} else {
+		result.Log = res.TxResult.Log
+	}
+
+	for i, any := range tx.Body.Messages {
+		var msg sdk.Msg
+		decoded, _ := decodeAny(cl, any, &msg)
+		result.Messages[i] = decodedTxMessage{
+			Index:   i,
+			Message: decoded,
+			Events:  events[uint32(i)],
+		}
+	}
+	return result, nil
+}
+
+// marshalTxResponse returns the JSON encoding of the transaction response for res, including tx.
+// If tx holds messages of types unknown to the codec, so that it cannot be encoded as JSON,
+// the response is encoded without it.
+func marshalTxResponse(cl *client.ChainClient, res *coretypes.ResultTx, tx *txtypes.Tx) (json.RawMessage, error) {
+	txAny, err := codectypes.NewAnyWithValue(tx)
+	if err != nil {
+		return nil, err
+	}
+	// The block time is not known without querying the block, so it is left empty.
+	if bz, err := cl.Codec.Marshaler.MarshalJSON(sdk.NewResponseResultTx(res, txAny, "")); err == nil {
+		return bz, nil
+	}
+	return cl.Codec.Marshaler.MarshalJSON(sdk.NewResponseResultTx(res, nil, ""))
+}
+
+// decodedTxMessage is a message of a transaction, with the events it emitted.
+type decodedTxMessage struct {
+	Index   int              `json:"index"`
+	Message decodedAny       `json:"message"`
+	Events  sdk.StringEvents `json:"events,omitempty"`
+}
+
+// decodedTx is the result of query tx.
+type decodedTx struct {
+	Hash      string             `json:"hash"`
+	Height    int64              `json:"height"`
+	Code      uint32             `json:"code"`
+	Codespace string             `json:"codespace,omitempty"`
+	Log       string             `json:"log,omitempty"`
+	GasWanted int64              `json:"gas_wanted"`
+	GasUsed   int64              `json:"gas_used"`
+	Fee       sdk.Coins          `json:"fee"`
+	Memo      string             `json:"memo,omitempty"`
+	Messages  []decodedTxMessage `json:"messages"`
+}
+
+var _ fmt.Stringer = decodedTx{}
+
+// String returns the fields of the transaction one per line,
+// followed by each message and its events.
+func (t decodedTx) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Hash:\t%s\n", t.Hash)
+	fmt.Fprintf(w, "Height:\t%d\n", t.Height)
+	if t.Codespace != "" {
+		fmt.Fprintf(w, "Code:\t%d (%s)\n", t.Code, t.Codespace)
+	} else {
+		fmt.Fprintf(w, "Code:\t%d\n", t.Code)
+	}
+	if t.Log != "" {
+		fmt.Fprintf(w, "Log:\t%s\n", t.Log)
+	}
+	fmt.Fprintf(w, "Gas used/wanted:\t%d/%d\n", t.GasUsed, t.GasWanted)
+	fmt.Fprintf(w, "Fee:\t%s\n", orDash(t.Fee.String()))
+	fmt.Fprintf(w, "Memo:\t%s\n", orDash(t.Memo))
+	w.Flush()
+
+	for _, m := range t.Messages {
+		fmt.Fprintf(&b, "\nMessage %d: %s\n", m.Index, m.Message.Type)
+		m.Message.writeBody(&b, "Value")
+		writeStringEvents(&b, m.Events)
+	}
+	return b.String()
+}
+
+// writeStringEvents writes events to w, one per line with their attributes.
+func writeStringEvents(w io.Writer, events sdk.StringEvents) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Events:")
+	for _, e := range events {
+		attrs := make([]string, len(e.Attributes))
+		for i, attr := range e.Attributes {
+			attrs[i] = attr.Key + "=" + attr.Value
+		}
+		fmt.Fprintf(w, "  %s: %s\n", e.Type, strings.Join(attrs, ", "))
+	}
+}
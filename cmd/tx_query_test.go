@@ -0,0 +1,332 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	reflectionv2 "github.com/cosmos/cosmos-sdk/server/grpc/reflection/v2alpha1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestQueryTx(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	send, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: ZeroCosmosAddr,
+		ToAddress:   ZeroCosmosAddr,
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 5)),
+	})
+	require.NoError(t, err)
+	unknown := &codectypes.Any{TypeUrl: "/example.v1.MsgCustom", Value: []byte{1, 2, 3}}
+
+	body, err := (&txtypes.TxBody{Messages: []*codectypes.Any{send, unknown}, Memo: "hello"}).Marshal()
+	require.NoError(t, err)
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{
+		Amount:   sdk.NewCoins(sdk.NewInt64Coin("uatom", 500)),
+		GasLimit: 200000,
+	}}).Marshal()
+	require.NoError(t, err)
+	txBytes, err := (&txtypes.TxRaw{BodyBytes: body, AuthInfoBytes: authInfo, Signatures: [][]byte{{}}}).Marshal()
+	require.NoError(t, err)
+
+	logs, err := json.Marshal(sdk.ABCIMessageLogs{
+		{MsgIndex: 0, Events: sdk.StringEvents{{
+			Type:       "transfer",
+			Attributes: []sdk.Attribute{{Key: "amount", Value: "5uatom"}},
+		}}},
+		{MsgIndex: 1, Events: sdk.StringEvents{{
+			Type:       "custom",
+			Attributes: []sdk.Attribute{{Key: "done", Value: "true"}},
+		}}},
+	})
+	require.NoError(t, err)
+
+	tx := tmtypes.Tx(txBytes)
+	mc := new(mocks.Client)
+	mc.On("Tx", mock.Anything, []byte(tx.Hash()), false).Return(&coretypes.ResultTx{
+		Hash:   tx.Hash(),
+		Height: 42,
+		Tx:     tx,
+		TxResult: abci.ResponseDeliverTx{
+			Log:       string(logs),
+			GasWanted: 200000,
+			GasUsed:   80000,
+		},
+	}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	hash := fmt.Sprintf("%X", tx.Hash())
+	res := sys.MustRun(t, "query", "tx", "cosmoshub", hash, "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Gas used/wanted:  80000/200000")
+	require.Contains(t, out, "Fee:              500uatom")
+	require.Contains(t, out, "Memo:             hello")
+	require.Contains(t, out, "Message 0: /cosmos.bank.v1beta1.MsgSend")
+	require.Contains(t, out, `"to_address": "`+ZeroCosmosAddr+`"`)
+	require.Contains(t, out, "  transfer: amount=5uatom")
+	// The message of an unknown type does not fail the command.
+	require.Contains(t, out, "Message 1: /example.v1.MsgCustom\nValue (base64): AQID\nEvents:\n  custom: done=true\n")
+
+	res = sys.MustRun(t, "query", "tx", hash, "-o", "json")
+	var decoded struct {
+		Height   int64
+		Messages []struct {
+			Message struct {
+				Type    string `json:"@type"`
+				Payload []byte
+			}
+			Events sdk.StringEvents
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &decoded))
+	require.Equal(t, int64(42), decoded.Height)
+	require.Len(t, decoded.Messages, 2)
+	require.Equal(t, "/example.v1.MsgCustom", decoded.Messages[1].Message.Type)
+	require.Equal(t, []byte{1, 2, 3}, decoded.Messages[1].Message.Payload)
+	require.Equal(t, "custom", decoded.Messages[1].Events[0].Type)
+
+	res = sys.MustRun(t, "query", "tx", hash, "--raw")
+	var raw struct {
+		Height  string
+		GasUsed string `json:"gas_used"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &raw))
+	require.Equal(t, "42", raw.Height)
+	require.Equal(t, "80000", raw.GasUsed)
+}
+
+// testTxResult returns the result of a tx with a single bank send message, paying fee, included at height.
+func testTxResult(t *testing.T, height int64, fee int64) *coretypes.ResultTx {
+	t.Helper()
+
+	send, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: ZeroCosmosAddr,
+		ToAddress:   ZeroCosmosAddr,
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 5)),
+	})
+	require.NoError(t, err)
+	body, err := (&txtypes.TxBody{Messages: []*codectypes.Any{send}}).Marshal()
+	require.NoError(t, err)
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", fee))}}).Marshal()
+	require.NoError(t, err)
+	txBytes, err := (&txtypes.TxRaw{BodyBytes: body, AuthInfoBytes: authInfo}).Marshal()
+	require.NoError(t, err)
+
+	tx := tmtypes.Tx(txBytes)
+	return &coretypes.ResultTx{Hash: tx.Hash(), Height: height, Tx: tx}
+}
+
+func TestQueryTxs(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	const query = "transfer.recipient='" + ZeroCosmosAddr + "' AND tx.height>100 AND message.module='bank'"
+	pages := map[int]*coretypes.ResultTxSearch{
+		1: {Txs: []*coretypes.ResultTx{testTxResult(t, 101, 10), testTxResult(t, 102, 20)}, TotalCount: 3},
+		2: {Txs: []*coretypes.ResultTx{testTxResult(t, 103, 30)}, TotalCount: 3},
+	}
+	mc := new(mocks.Client)
+	for n, page := range pages {
+		n := n
+		mc.On("TxSearch", mock.Anything, query, false, mock.MatchedBy(func(p *int) bool { return *p == n }), mock.MatchedBy(func(l *int) bool { return *l == 2 }), "").
+			Return(page, nil)
+	}
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// Clauses may be comma separated, or given in repeated flags.
+	events := []string{"--events", "transfer.recipient=" + ZeroCosmosAddr + ",tx.height>100", "--events", "message.module=bank"}
+
+	res := sys.MustRun(t, append(append([]string{"query", "txs", "cosmoshub", "--limit", "2"}, events...), "-o", "text")...)
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, []string{"HEIGHT", "HASH", "MESSAGE", "FEE"}, strings.Fields(lines[0]))
+	fields := strings.Fields(lines[1])
+	require.Equal(t, "101", fields[0])
+	require.Equal(t, []string{"/cosmos.bank.v1beta1.MsgSend", "10uatom"}, fields[2:])
+	require.Equal(t, "Listed 2 of 3 matching transactions.", lines[3])
+
+	res = sys.MustRun(t, append([]string{"query", "txs", "--limit", "2", "--all", "-o", "json"}, events...)...)
+	var all struct {
+		TotalCount int `json:"total_count"`
+		Txs        []struct {
+			Height int64
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &all))
+	require.Equal(t, 3, all.TotalCount)
+	require.Len(t, all.Txs, 3)
+	require.Equal(t, int64(103), all.Txs[2].Height)
+
+	// Invalid clauses are reported before any query.
+	for clause, msg := range map[string]string{
+		"transfer.recipient": `invalid event clause "transfer.recipient": expected key=value`,
+		"recipient=cosmos1":  `invalid event clause "recipient=cosmos1": key "recipient" must be an event type and attribute`,
+		"tx.height>abc":      `invalid event clause "tx.height>abc": value "abc" must be an integer to compare with >`,
+		"transfer.amount=":   `invalid event clause "transfer.amount=": missing value`,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), "query", "txs", "--events", "tx.height>1,"+clause)
+		require.ErrorContains(t, res.Err, msg)
+	}
+}
+
+func TestQueryTx_ExtraMsgDescriptors(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	fds := customMsgDescriptors()
+	b, err := proto.Marshal(fds)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sys.HomeDir, "custom.pb"), b, 0o600))
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "extra-msg-descriptors", "custom.pb")
+
+	tx := customMsgTx(t)
+	mc := new(mocks.Client)
+	mc.On("Tx", mock.Anything, []byte(tx.Hash()), false).Return(&coretypes.ResultTx{Hash: tx.Hash(), Height: 42, Tx: tx}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "tx", fmt.Sprintf("%X", tx.Hash()), "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Message 0: /lens.test.v1.MsgCustom\n{\n  \"note\": \"hi\"\n}\n")
+
+	// Descriptors that cannot be read fail the command.
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "extra-msg-descriptors", "missing.pb")
+	res = sys.Run(zaptest.NewLogger(t), "query", "tx", fmt.Sprintf("%X", tx.Hash()))
+	require.Error(t, res.Err)
+}
+
+// customMsgDescriptors returns the descriptors of a lens.test.v1.Msg service, whose MsgCustom is not compiled into lens.
+func customMsgDescriptors() *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("lens/test/v1/tx.proto"),
+		Package: proto.String("lens.test.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("MsgCustom"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:     proto.String("note"),
+					JsonName: proto.String("note"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				}},
+			},
+			{Name: proto.String("MsgCustomResponse")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Msg"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Custom"),
+				InputType:  proto.String(".lens.test.v1.MsgCustom"),
+				OutputType: proto.String(".lens.test.v1.MsgCustomResponse"),
+			}},
+		}},
+	}}}
+}
+
+// customMsgTx returns a tx holding a lens.test.v1.MsgCustom whose note is "hi".
+func customMsgTx(t *testing.T) tmtypes.Tx {
+	t.Helper()
+
+	custom := &codectypes.Any{TypeUrl: "/lens.test.v1.MsgCustom", Value: []byte{0x0a, 0x02, 'h', 'i'}}
+	body, err := (&txtypes.TxBody{Messages: []*codectypes.Any{custom}}).Marshal()
+	require.NoError(t, err)
+	authInfo, err := (&txtypes.AuthInfo{Fee: &txtypes.Fee{}}).Marshal()
+	require.NoError(t, err)
+	txBytes, err := (&txtypes.TxRaw{BodyBytes: body, AuthInfoBytes: authInfo}).Marshal()
+	require.NoError(t, err)
+	return tmtypes.Tx(txBytes)
+}
+
+// txDescriptorServer lists msgTypeURLs as the messages of the chain.
+type txDescriptorServer struct {
+	reflectionv2.UnimplementedReflectionServiceServer
+	msgTypeURLs []string
+}
+
+func (s *txDescriptorServer) GetTxDescriptor(context.Context, *reflectionv2.GetTxDescriptorRequest) (*reflectionv2.GetTxDescriptorResponse, error) {
+	tx := &reflectionv2.TxDescriptor{Fullname: "cosmos.tx.v1beta1.Tx"}
+	for _, typeURL := range s.msgTypeURLs {
+		tx.Msgs = append(tx.Msgs, &reflectionv2.MsgDescriptor{MsgTypeUrl: typeURL})
+	}
+	return &reflectionv2.GetTxDescriptorResponse{Tx: tx}, nil
+}
+
+func TestQueryTx_ReflectMsgs(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	// A chain listing MsgCustom, and serving its descriptors through reflection.
+	files, err := protodesc.NewFiles(customMsgDescriptors())
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	reflectionv2.RegisterReflectionServiceServer(srv, &txDescriptorServer{
+		msgTypeURLs: []string{"/cosmos.bank.v1beta1.MsgSend", "/lens.test.v1.MsgCustom"},
+	})
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{
+		Services:           srv,
+		DescriptorResolver: fallbackResolver{local: files},
+	}))
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", ln.Addr().String())
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "reflect-msgs", "true")
+
+	tx := customMsgTx(t)
+	mc := new(mocks.Client)
+	mc.On("Tx", mock.Anything, []byte(tx.Hash()), false).Return(&coretypes.ResultTx{Hash: tx.Hash(), Height: 42, Tx: tx}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "tx", fmt.Sprintf("%X", tx.Hash()), "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Message 0: /lens.test.v1.MsgCustom\n{\n  \"note\": \"hi\"\n}\n")
+
+	// The descriptors are cached, so that the chain is not asked again.
+	srv.Stop()
+	res = sys.MustRun(t, "query", "tx", fmt.Sprintf("%X", tx.Hash()), "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Message 0: /lens.test.v1.MsgCustom\n{\n  \"note\": \"hi\"\n}\n")
+
+	// Failing to read the descriptors only leaves the message undecoded.
+	res = sys.MustRun(t, "query", "tx", fmt.Sprintf("%X", tx.Hash()), "--no-cache", "--timeout", "1s", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Message 0: /lens.test.v1.MsgCustom\n")
+	require.NotContains(t, res.Stdout.String(), "\"note\"")
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const (
+	txOfflineFlag       = "offline"
+	txAccountNumberFlag = "account-number"
+	txSequenceFlag      = "sequence"
+	txSignModeFlag      = "sign-mode"
+	txAppendFlag        = "append"
+	txMultisigOfFlag    = "multisig-of"
+)
+
+// signModeNames maps the values of the --sign-mode flag to the sign modes.
+var signModeNames = map[string]signing.SignMode{
+	"direct":     signing.SignMode_SIGN_MODE_DIRECT,
+	"amino-json": signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+}
+
+func txSignCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign [chain-name] <file>",
+		Short: "sign a transaction generated with --generate-only",
+		Long: `Sign the transaction in the file, as written by --generate-only, with a key in the keyring of the given chain,
+or of the default chain, and write the signed transaction as JSON, to be broadcast with tx broadcast.
+A file of "-" is read from standard input.
+
+The account number and sequence of the key's account are queried, unless given by --account-number and --sequence.
+With --offline, nothing is queried, and both must be given.
+
+The signature replaces the signatures of the transaction, unless --append is set,
+which adds it to them, for transactions with several signers.
+Since a signature in direct sign mode covers the signer infos of the transaction,
+which appending a signature changes, every signer should then use --sign-mode amino-json.
+
+With --multisig-of, the key signs as one of the keys of the named multisig key, in amino-json sign mode,
+for the account number and sequence of the multisig account, and only the signature is written,
+to be combined with those of the other keys by tx multisign.`,
+		Example: fmt.Sprintf(`$ %s tx sign cosmoshub unsigned.json --from mykey > signed.json
+$ %s tx sign unsigned.json --from mykey --offline --account-number 7 --sequence 3
+$ %s tx sign signed-by-alice.json --from bob --append --sign-mode amino-json
+$ %s tx sign unsigned.json --from alice --multisig-of team > alice.json`,
+			appName, appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			key, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			if key != "" {
+				cl.Config.Key = key
+			}
+			if !cl.KeyExists(cl.Config.Key) {
+				return errKeyToSignNotFound(cl, chainName)
+			}
+
+			signModeName, err := cmd.Flags().GetString(txSignModeFlag)
+			if err != nil {
+				return err
+			}
+			signMode := signing.SignMode_SIGN_MODE_UNSPECIFIED
+			if signModeName != "" {
+				var ok bool
+				if signMode, ok = signModeNames[signModeName]; !ok {
+					return fmt.Errorf("unknown sign mode %q (must be direct or amino-json)", signModeName)
+				}
+			}
+			appendSig, err := cmd.Flags().GetBool(txAppendFlag)
+			if err != nil {
+				return err
+			}
+			multisigName, err := cmd.Flags().GetString(txMultisigOfFlag)
+			if err != nil {
+				return err
+			}
+			if multisigName != "" {
+				if appendSig {
+					return fmt.Errorf("--%s cannot be given with --%s", txAppendFlag, txMultisigOfFlag)
+				}
+				if signMode == signing.SignMode_SIGN_MODE_DIRECT {
+					return fmt.Errorf("multisig accounts only support --%s amino-json", txSignModeFlag)
+				}
+			}
+
+			bz, err := readFileOrStdin(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTx(cl, bz, txEncodingJSON)
+			if err != nil {
+				return fmt.Errorf("failed to decode transaction from %s: %w", args[0], err)
+			}
+			txb, err := cl.Codec.TxConfig.WrapTxBuilder(tx)
+			if err != nil {
+				return err
+			}
+
+			if multisigName != "" {
+				multisigPub, err := cl.MultisigKey(multisigName)
+				if err != nil {
+					return err
+				}
+				accountNumber, sequence, err := signerAccountFromFlags(cmd, cl, sdk.AccAddress(multisigPub.Address()), multisigName)
+				if err != nil {
+					return err
+				}
+				sig, err := cl.SignMultisigPart(txb, multisigName, accountNumber, sequence)
+				if err != nil {
+					return err
+				}
+				sigJSON, err := cl.Codec.TxConfig.MarshalSignatureJSON([]signing.SignatureV2{sig})
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd, a, json.RawMessage(sigJSON))
+			}
+
+			address, err := cl.GetKeyAddress()
+			if err != nil {
+				return err
+			}
+			accountNumber, sequence, err := signerAccountFromFlags(cmd, cl, address, cl.Config.Key)
+			if err != nil {
+				return err
+			}
+			if err := cl.SignTx(txb, accountNumber, sequence, signMode, appendSig); err != nil {
+				return err
+			}
+			signed, err := cl.Codec.TxConfig.TxJSONEncoder()(txb.GetTx())
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, a, json.RawMessage(signed))
+		},
+	}
+	AddTxFlagsToCmd(cmd)
+	addSignerAccountFlags(cmd)
+	cmd.Flags().String(txSignModeFlag, "", "the sign mode, direct or amino-json (default: the chain's sign-mode)")
+	cmd.Flags().Bool(txAppendFlag, false, "add the signature to the signatures of the transaction, instead of replacing them")
+	cmd.Flags().String(txMultisigOfFlag, "", "sign as one of the keys of the named multisig key, and write the signature only, for tx multisign")
+	return cmd
+}
+
+// addSignerAccountFlags adds the flags giving the account number and sequence of the signing account to cmd.
+func addSignerAccountFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(txOfflineFlag, false, "do not query the chain for the account; --account-number and --sequence must be given")
+	cmd.Flags().Uint64(txAccountNumberFlag, 0, "the account number of the signing account, instead of querying it")
+	cmd.Flags().Uint64(txSequenceFlag, 0, "the sequence of the signing account, instead of querying it")
+}
+
+// signerAccountFromFlags returns the account number and sequence of the account at address, of the named key,
+// as given by the flags added by addSignerAccountFlags, querying the chain for those not given.
+func signerAccountFromFlags(cmd *cobra.Command, cl *client.ChainClient, address sdk.AccAddress, keyName string) (accountNumber, sequence uint64, err error) {
+	offline, err := cmd.Flags().GetBool(txOfflineFlag)
+	if err != nil {
+		return 0, 0, err
+	}
+	if accountNumber, err = cmd.Flags().GetUint64(txAccountNumberFlag); err != nil {
+		return 0, 0, err
+	}
+	if sequence, err = cmd.Flags().GetUint64(txSequenceFlag); err != nil {
+		return 0, 0, err
+	}
+	haveAccountNumber, haveSequence := cmd.Flags().Changed(txAccountNumberFlag), cmd.Flags().Changed(txSequenceFlag)
+	if haveAccountNumber && haveSequence {
+		return accountNumber, sequence, nil
+	}
+	if offline {
+		return 0, 0, fmt.Errorf("--%s and --%s are required with --%s", txAccountNumberFlag, txSequenceFlag, txOfflineFlag)
+	}
+
+	acc, err := cl.QueryAccount(cmd.Context(), address)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query account of key %q (use --%s to sign without querying): %w", keyName, txOfflineFlag, err)
+	}
+	if !haveAccountNumber {
+		accountNumber = acc.GetAccountNumber()
+	}
+	if !haveSequence {
+		sequence = acc.GetSequence()
+	}
+	return accountNumber, sequence, nil
+}
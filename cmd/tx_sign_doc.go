@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/spf13/cobra"
+)
+
+const txVerifyFlag = "verify"
+
+func txSignDocCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-doc [chain-name] <file>",
+		Short: "show the exact bytes a key signs to sign a transaction",
+		Long: `Show the sign doc that tx sign would have a key of the given chain, or of the default chain, sign
+to sign the transaction in the file, as written by --generate-only, without signing it:
+the bytes signed, in hex and base64, and their SHA-256 digest, which secp256k1 keys sign,
+so that external signers and hardware wallet integrations can be checked byte for byte.
+A file of "-" is read from standard input.
+
+In direct sign mode, the sign doc covers the signer info of the key, which is set as tx sign sets it;
+in amino-json sign mode, the bytes are the sorted JSON of the sign doc, which is also shown.
+
+The account number and sequence of the key's account are queried, unless given by --account-number and --sequence;
+with both, or with --offline, nothing is sent over the network.
+
+With --verify, the hex encoded signature is checked against the public key of the key,
+and the command fails if it is not a signature of the sign doc.`,
+		Example: fmt.Sprintf(`$ %s tx sign-doc cosmoshub unsigned.json --from mykey --account-number 7 --sequence 3
+$ %s tx sign-doc unsigned.json --from mykey --offline --account-number 7 --sequence 3 --sign-mode amino-json -o json
+$ %s tx sign-doc unsigned.json --from mykey --account-number 7 --sequence 3 --verify 3045...`,
+			appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			key, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			if key != "" {
+				cl.Config.Key = key
+			}
+			if !cl.KeyExists(cl.Config.Key) {
+				return errKeyToSignNotFound(cl, chainName)
+			}
+
+			signModeName, err := cmd.Flags().GetString(txSignModeFlag)
+			if err != nil {
+				return err
+			}
+			signMode := signing.SignMode_SIGN_MODE_UNSPECIFIED
+			if signModeName != "" {
+				var ok bool
+				if signMode, ok = signModeNames[signModeName]; !ok {
+					return fmt.Errorf("unknown sign mode %q (must be direct or amino-json)", signModeName)
+				}
+			}
+			verifyHex, err := cmd.Flags().GetString(txVerifyFlag)
+			if err != nil {
+				return err
+			}
+			var sig []byte
+			if verifyHex != "" {
+				if sig, err = hex.DecodeString(strings.TrimPrefix(verifyHex, "0x")); err != nil {
+					return fmt.Errorf("invalid --%s signature: must be hex encoded: %w", txVerifyFlag, err)
+				}
+			}
+
+			bz, err := readFileOrStdin(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTx(cl, bz, txEncodingJSON)
+			if err != nil {
+				return fmt.Errorf("failed to decode transaction from %s: %w", args[0], err)
+			}
+			txb, err := cl.Codec.TxConfig.WrapTxBuilder(tx)
+			if err != nil {
+				return err
+			}
+
+			address, err := cl.GetKeyAddress()
+			if err != nil {
+				return err
+			}
+			accountNumber, sequence, err := signerAccountFromFlags(cmd, cl, address, cl.Config.Key)
+			if err != nil {
+				return err
+			}
+			doc, err := cl.SignDoc(txb, accountNumber, sequence, signMode)
+			if err != nil {
+				return err
+			}
+
+			digest := doc.Digest()
+			res := signDocResult{
+				SignMode:      signModeName,
+				ChainID:       cl.Config.ChainID,
+				Signer:        cl.MustEncodeAccAddr(address),
+				PubKey:        hex.EncodeToString(doc.PubKey.Bytes()),
+				AccountNumber: accountNumber,
+				Sequence:      sequence,
+				SignBytesHex:  hex.EncodeToString(doc.Bytes),
+				SignBytes:     base64.StdEncoding.EncodeToString(doc.Bytes),
+				SHA256:        hex.EncodeToString(digest[:]),
+			}
+			for name, mode := range signModeNames {
+				if mode == doc.SignMode {
+					res.SignMode = name
+				}
+			}
+			switch doc.SignMode {
+			case signing.SignMode_SIGN_MODE_DIRECT:
+				var signDoc txtypes.SignDoc
+				if err := signDoc.Unmarshal(doc.Bytes); err != nil {
+					return err
+				}
+				res.BodyBytes = hex.EncodeToString(signDoc.BodyBytes)
+				res.AuthInfoBytes = hex.EncodeToString(signDoc.AuthInfoBytes)
+			case signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON:
+				res.JSON = string(doc.Bytes)
+			}
+			if sig != nil {
+				valid := doc.Verify(sig)
+				res.SignatureValid = &valid
+			}
+
+			if err := writeOutput(cmd, a, res); err != nil {
+				return err
+			}
+			if res.SignatureValid != nil && !*res.SignatureValid {
+				return fmt.Errorf("the --%s signature is not a signature of the sign doc by key %q (%s)", txVerifyFlag, cl.Config.Key, res.Signer)
+			}
+			return nil
+		},
+	}
+	AddTxFlagsToCmd(cmd)
+	addSignerAccountFlags(cmd)
+	cmd.Flags().String(txSignModeFlag, "", "the sign mode, direct or amino-json (default: the chain's sign-mode)")
+	cmd.Flags().String(txVerifyFlag, "", "a hex encoded signature to check against the public key of the key")
+	return cmd
+}
+
+// signDocResult is the result of tx sign-doc.
+type signDocResult struct {
+	SignMode      string `json:"sign_mode"`
+	ChainID       string `json:"chain_id"`
+	Signer        string `json:"signer"`
+	PubKey        string `json:"pub_key"`
+	AccountNumber uint64 `json:"account_number"`
+	Sequence      uint64 `json:"sequence"`
+	// BodyBytes and AuthInfoBytes are the fields of the sign doc in direct sign mode, hex encoded.
+	BodyBytes     string `json:"body_bytes,omitempty"`
+	AuthInfoBytes string `json:"auth_info_bytes,omitempty"`
+	// JSON is the sign doc in amino-json sign mode, as signed.
+	JSON           string `json:"json,omitempty"`
+	SignBytesHex   string `json:"sign_bytes_hex"`
+	SignBytes      string `json:"sign_bytes_base64"`
+	SHA256         string `json:"sha256"`
+	SignatureValid *bool  `json:"signature_valid,omitempty"`
+}
+
+var _ fmt.Stringer = signDocResult{}
+
+// String returns the fields of the result one per line.
+func (r signDocResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Sign mode:\t%s\n", r.SignMode)
+	fmt.Fprintf(w, "Chain ID:\t%s\n", r.ChainID)
+	fmt.Fprintf(w, "Signer:\t%s\n", r.Signer)
+	fmt.Fprintf(w, "Public key (hex):\t%s\n", r.PubKey)
+	fmt.Fprintf(w, "Account number:\t%d\n", r.AccountNumber)
+	fmt.Fprintf(w, "Sequence:\t%d\n", r.Sequence)
+	if r.BodyBytes != "" {
+		fmt.Fprintf(w, "Body bytes (hex):\t%s\n", r.BodyBytes)
+		fmt.Fprintf(w, "Auth info bytes (hex):\t%s\n", r.AuthInfoBytes)
+	}
+	if r.JSON != "" {
+		fmt.Fprintf(w, "JSON:\t%s\n", r.JSON)
+	}
+	fmt.Fprintf(w, "Sign bytes (hex):\t%s\n", r.SignBytesHex)
+	fmt.Fprintf(w, "Sign bytes (base64):\t%s\n", r.SignBytes)
+	fmt.Fprintf(w, "SHA-256:\t%s\n", r.SHA256)
+	if r.SignatureValid != nil {
+		fmt.Fprintf(w, "Signature valid:\t%t\n", *r.SignatureValid)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package cmd_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTxSignDoc(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res := sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only")
+	unsigned := filepath.Join(t.TempDir(), "unsigned.json")
+	require.NoError(t, os.WriteFile(unsigned, res.Stdout.Bytes(), 0o600))
+
+	// With the account number and sequence, the chain is not queried: any call to the mock client would fail.
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: new(mocks.Client),
+	})
+	var doc struct {
+		SignMode      string `json:"sign_mode"`
+		ChainID       string `json:"chain_id"`
+		Signer        string
+		AccountNumber uint64 `json:"account_number"`
+		Sequence      uint64
+		BodyBytes     string `json:"body_bytes"`
+		JSON          string
+		SignBytesHex  string `json:"sign_bytes_hex"`
+		SignBytes     string `json:"sign_bytes_base64"`
+		SHA256        string
+		Valid         *bool `json:"signature_valid"`
+	}
+	for _, mode := range []string{"direct", "amino-json"} {
+		signDocArgs := []string{"tx", "sign-doc", "cosmoshub", unsigned, "--from", "mykey", "--account-number", "7", "--sequence", "3", "--sign-mode", mode}
+		res = sys.MustRun(t, append(signDocArgs, "-o", "json")...)
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &doc))
+		require.Equal(t, mode, doc.SignMode)
+		require.Equal(t, "cosmoshub-4", doc.ChainID)
+		require.Equal(t, ZeroCosmosAddr, doc.Signer)
+		require.Equal(t, uint64(7), doc.AccountNumber)
+		require.Equal(t, uint64(3), doc.Sequence)
+		bz, err := hex.DecodeString(doc.SignBytesHex)
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString(bz), doc.SignBytes)
+		digest := sha256.Sum256(bz)
+		require.Equal(t, hex.EncodeToString(digest[:]), doc.SHA256)
+		require.Nil(t, doc.Valid)
+		if mode == "direct" {
+			require.NotEmpty(t, doc.BodyBytes)
+			require.Empty(t, doc.JSON)
+		} else {
+			require.Contains(t, doc.JSON, `"account_number":"7"`)
+			require.Contains(t, doc.JSON, `"sequence":"3"`)
+			require.Equal(t, doc.JSON, string(bz))
+		}
+
+		// The signature of tx sign is a signature of the sign doc.
+		res = sys.MustRun(t, "tx", "sign", "cosmoshub", unsigned, "--from", "mykey", "--offline", "--account-number", "7", "--sequence", "3", "--sign-mode", mode)
+		var tx signedTx
+		require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+		sig, err := base64.StdEncoding.DecodeString(tx.Signatures[0])
+		require.NoError(t, err)
+		res = sys.MustRun(t, append(signDocArgs, "--verify", hex.EncodeToString(sig), "-o", "text")...)
+		require.Contains(t, res.Stdout.String(), "Signature valid:")
+		require.Contains(t, res.Stdout.String(), "true")
+
+		// It is not one for another sequence.
+		signDocArgs[9] = "4"
+		res = sys.Run(zaptest.NewLogger(t), append(signDocArgs, "--verify", hex.EncodeToString(sig))...)
+		require.ErrorContains(t, res.Err, `the --verify signature is not a signature of the sign doc by key "mykey" (`+ZeroCosmosAddr+")")
+		require.Contains(t, res.Stdout.String(), "false")
+	}
+
+	sys.MustRun(t, "keys", "add", "other")
+	for args, msg := range map[string]string{
+		unsigned + " --from mykey --offline --sequence 3":                        "--account-number and --sequence are required with --offline",
+		unsigned + " --from mykey --sign-mode textual":                           `unknown sign mode "textual" (must be direct or amino-json)`,
+		unsigned + " --from nokey":                                               `key "nokey" not found on chain cosmoshub`,
+		unsigned + " --from other --account-number 1 --sequence 0":               `key "other" (cosmos1`,
+		unsigned + " --from mykey --account-number 1 --sequence 0 --verify 0xzz": "invalid --verify signature: must be hex encoded",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "sign-doc"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
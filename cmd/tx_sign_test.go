@@ -0,0 +1,140 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// signedTx is the part of a signed transaction in JSON checked by the tests.
+type signedTx struct {
+	Body struct {
+		Messages []json.RawMessage
+	}
+	AuthInfo struct {
+		SignerInfos []struct {
+			ModeInfo struct {
+				Single struct {
+					Mode string
+				}
+			} `json:"mode_info"`
+			Sequence string
+		} `json:"signer_infos"`
+	} `json:"auth_info"`
+	Signatures []string
+}
+
+func TestTxSign(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res := sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only")
+	dir := t.TempDir()
+	unsigned := filepath.Join(dir, "unsigned.json")
+	require.NoError(t, os.WriteFile(unsigned, res.Stdout.Bytes(), 0o600))
+
+	// Offline, the chain is not queried: any call to the mock client would fail.
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: new(mocks.Client),
+	})
+	res = sys.MustRun(t, "tx", "sign", "cosmoshub", unsigned, "--from", "mykey", "--offline", "--account-number", "7", "--sequence", "3")
+	var tx signedTx
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Len(t, tx.Signatures, 1)
+	require.Equal(t, "3", tx.AuthInfo.SignerInfos[0].Sequence)
+	signed := filepath.Join(dir, "signed.json")
+	require.NoError(t, os.WriteFile(signed, res.Stdout.Bytes(), 0o600))
+
+	// The signed transaction is broadcast, since its signature verifies.
+	mc = new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mc.On("BroadcastTxSync", mock.Anything, mock.Anything).Return(&coretypes.ResultBroadcastTx{Hash: bytes.HexBytes{1}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	sys.MustRun(t, "tx", "broadcast", signed, "--broadcast-mode", "sync")
+
+	// Online, the account number and sequence are queried.
+	mc = new(mocks.Client)
+	mockAccount(t, mc, 5)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res = sys.MustRun(t, "tx", "sign", unsigned, "--from", "mykey", "--sign-mode", "amino-json")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Equal(t, "5", tx.AuthInfo.SignerInfos[0].Sequence)
+	require.Equal(t, "SIGN_MODE_LEGACY_AMINO_JSON", tx.AuthInfo.SignerInfos[0].ModeInfo.Single.Mode)
+
+	sys.MustRun(t, "keys", "add", "other")
+	for args, msg := range map[string]string{
+		unsigned + " --from mykey --offline --sequence 3":                    "--account-number and --sequence are required with --offline",
+		unsigned + " --from mykey --sign-mode textual":                       `unknown sign mode "textual" (must be direct or amino-json)`,
+		unsigned + " --from nokey":                                           `key "nokey" not found on chain cosmoshub`,
+		unsigned + " --from other --offline --account-number 1 --sequence 0": `key "other" (cosmos1`,
+		signed + " --from mykey --append":                                    "transaction is already signed by " + ZeroCosmosAddr,
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "sign"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestTxSign_Append(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	sys.MustRun(t, "keys", "add", "other")
+	res := sys.MustRun(t, "keys", "show", "other")
+	otherAddr := strings.TrimSpace(res.Stdout.String())
+
+	mc := new(mocks.Client)
+	mockSendLookups(t, mc)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res = sys.MustRun(t, "tx", "bank", "send", "mykey", ZeroCosmosAddr, "10uatom", "--generate-only")
+
+	// Add a message from the other key, so that the transaction has two signers.
+	var unsigned map[string]interface{}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &unsigned))
+	body := unsigned["body"].(map[string]interface{})
+	body["messages"] = append(body["messages"].([]interface{}), map[string]interface{}{
+		"@type":        "/cosmos.bank.v1beta1.MsgSend",
+		"from_address": otherAddr,
+		"to_address":   ZeroCosmosAddr,
+		"amount":       []map[string]string{{"denom": "uatom", "amount": "1"}},
+	})
+	bz, err := json.Marshal(unsigned)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "unsigned.json")
+	require.NoError(t, os.WriteFile(file, bz, 0o600))
+
+	res = sys.MustRun(t, "tx", "sign", file, "--from", "mykey", "--offline", "--account-number", "7", "--sequence", "3", "--sign-mode", "amino-json")
+	require.NoError(t, os.WriteFile(file, res.Stdout.Bytes(), 0o600))
+	res = sys.MustRun(t, "tx", "sign", file, "--from", "other", "--offline", "--account-number", "8", "--sequence", "0", "--sign-mode", "amino-json", "--append")
+
+	var tx signedTx
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &tx))
+	require.Len(t, tx.Body.Messages, 2)
+	require.Len(t, tx.Signatures, 2)
+	require.Len(t, tx.AuthInfo.SignerInfos, 2)
+	require.Equal(t, "3", tx.AuthInfo.SignerInfos[0].Sequence)
+	require.Equal(t, "0", tx.AuthInfo.SignerInfos[1].Sequence)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+func txSimulateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate [chain-name] <file>",
+		Short: "simulate a transaction to estimate its gas and fees, without broadcasting it",
+		Long: `Simulate a transaction of the messages in the file, signed by the key given by --from,
+or by the chain's key, on the given chain, or on the default chain, without signing or broadcasting it,
+to check that it would succeed and what it would cost.
+The file is an unsigned transaction, as written by --generate-only, whose messages are simulated,
+a JSON list of messages, or a single message. A file of "-" is read from standard input.
+
+The gas used by the simulation is printed with the gas limit a transaction of the messages would be given:
+the gas used multiplied by the chain's gas adjustment. The fees are computed for that gas limit
+from the chain's gas prices, or from --gas-prices. The log and events of the simulation are printed too.
+
+If the simulation fails, its log is printed as written by the chain, with an explanation of the common failures.`,
+		Example: fmt.Sprintf(`$ %s tx simulate cosmoshub unsigned.json --from mykey
+$ %s tx wasm execute mykey juno1... '{"claim": {}}' --dry-run | %s tx simulate juno - --from mykey
+$ %s tx simulate msgs.json --gas-prices 0.05uatom -o json`,
+			appName, appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 1)
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			key, err := cmd.Flags().GetString(FlagFrom)
+			if err != nil {
+				return err
+			}
+			if key != "" {
+				cl.Config.Key = key
+			}
+			if !cl.KeyExists(cl.Config.Key) {
+				return errKeyToSignNotFound(cl, chainName)
+			}
+
+			gasPrices, err := cmd.Flags().GetString(txGasPricesFlag)
+			if err != nil {
+				return err
+			}
+			if gasPrices == "" {
+				gasPrices = cl.Config.GasPrices
+			}
+			prices, err := sdk.ParseDecCoins(gasPrices)
+			if err != nil {
+				return fmt.Errorf("invalid gas prices %q: %w", gasPrices, err)
+			}
+
+			bz, err := readFileOrStdin(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			msgs, err := readMsgsJSON(cl, bz)
+			if err != nil {
+				return fmt.Errorf("failed to read messages from %s: %w", args[0], err)
+			}
+
+			txf, err := cl.PrepareFactory(cmd.Context(), cl.TxFactory())
+			if err != nil {
+				return err
+			}
+			res, err := cl.Simulate(cmd.Context(), txf, msgs...)
+			var simErr client.SimulationError
+			if errors.As(err, &simErr) {
+				return TxSimulationError{SimulationError: simErr, Hint: simulationHint(simErr)}
+			}
+			if err != nil {
+				return err
+			}
+
+			result := simulateResult{
+				GasAdjustment: txf.GasAdjustment(),
+				GasPrices:     gasPrices,
+				Events:        []txEvent{},
+			}
+			if res.GasInfo != nil {
+				result.GasUsed = res.GasInfo.GasUsed
+			}
+			result.Gas = client.AdjustGas(result.GasUsed, result.GasAdjustment)
+			result.Fee = client.FeeForGas(prices, result.Gas)
+			if res.Result != nil {
+				result.Log = res.Result.Log
+				for _, e := range res.Result.Events {
+					event := txEvent{Type: e.Type, Attributes: []txEventAttr{}}
+					for _, attr := range e.Attributes {
+						event.Attributes = append(event.Attributes, txEventAttr{Key: attr.Key, Value: attr.Value})
+					}
+					result.Events = append(result.Events, event)
+				}
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	AddTxFlagsToCmd(cmd)
+	cmd.Flags().String(txGasPricesFlag, "", "the gas prices to compute the fees with, instead of the chain's gas prices (e.g. 0.025uatom)")
+	return cmd
+}
+
+// simulationHints explain the common failures of simulations, by codespace and code.
+var simulationHints = map[string]map[uint32]string{
+	sdkerrors.RootCodespace: {
+		sdkerrors.ErrUnauthorized.ABCICode():      "A message is not signed by its signer: the --from key must be the sender, or hold an authz grant or permission for the message.",
+		sdkerrors.ErrInsufficientFunds.ABCICode(): "The account cannot pay the amounts of the messages: check its spendable balances with query bank balances.",
+		sdkerrors.ErrInvalidAddress.ABCICode():    "An address of a message is invalid, or has the account prefix of another chain.",
+		sdkerrors.ErrUnknownAddress.ABCICode():    "The account of the key does not exist on the chain yet: it must first receive funds.",
+		sdkerrors.ErrInvalidCoins.ABCICode():      "An amount of a message is invalid, such as zero or of an unknown denom.",
+		sdkerrors.ErrWrongSequence.ABCICode():     "The account sequence changed while simulating, as when a transaction of the key was just included: try again.",
+		sdkerrors.ErrUnknownRequest.ABCICode():    "The chain does not know a message type: check that its module is enabled on the chain.",
+	},
+	"wasm": {
+		5: "The contract failed to execute the message: its error is in the log.",
+	},
+}
+
+// simulationHint returns a one-line explanation of the failed simulation err, if it is a common failure.
+func simulationHint(err client.SimulationError) string {
+	return simulationHints[err.Codespace][err.Code]
+}
+
+// simulateResult is the result of tx simulate.
+type simulateResult struct {
+	GasUsed       uint64  `json:"gas_used"`
+	GasAdjustment float64 `json:"gas_adjustment"`
+	// Gas is the gas limit to set on the transaction: GasUsed multiplied by GasAdjustment.
+	Gas       uint64    `json:"gas"`
+	GasPrices string    `json:"gas_prices"`
+	Fee       sdk.Coins `json:"fee"`
+	Log       string    `json:"log"`
+	Events    []txEvent `json:"events"`
+}
+
+var _ fmt.Stringer = simulateResult{}
+
+// String returns the fields of the result one per line, followed by the events.
+func (r simulateResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Gas used:\t%d\n", r.GasUsed)
+	fmt.Fprintf(w, "Gas limit:\t%d (gas adjustment %g)\n", r.Gas, r.GasAdjustment)
+	fmt.Fprintf(w, "Fee:\t%s (at %s)\n", orDash(r.Fee.String()), orDash(r.GasPrices))
+	fmt.Fprintf(w, "Log:\t%s\n", orDash(r.Log))
+	if len(r.Events) > 0 {
+		fmt.Fprintln(w, "Events:")
+		for _, e := range r.Events {
+			for _, attr := range e.Attributes {
+				fmt.Fprintf(w, "  %s.%s\t%s\n", e.Type, attr.Key, attr.Value)
+			}
+		}
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTxSimulate(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	msgs := filepath.Join(t.TempDir(), "msgs.json")
+	require.NoError(t, os.WriteFile(msgs, []byte(fmt.Sprintf(
+		`[{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":%q,"to_address":%q,"amount":[{"denom":"uatom","amount":"10"}]}]`,
+		ZeroCosmosAddr, testGroupMemberAddr)), 0o600))
+
+	mc := new(mocks.Client)
+	mockAccount(t, mc, 3)
+	mockABCIQuery(t, mc, "/cosmos.tx.v1beta1.Service/Simulate", func(bytes.HexBytes) bool { return true },
+		&txtypes.SimulateResponse{
+			GasInfo: &sdk.GasInfo{GasUsed: 100000},
+			Result: &sdk.Result{
+				Log: "sent",
+				Events: []abci.Event{{Type: "transfer", Attributes: []abci.EventAttribute{
+					{Key: "recipient", Value: testGroupMemberAddr},
+					{Key: "amount", Value: "10uatom"},
+				}}},
+			},
+		})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// The gas limit is the simulated gas multiplied by the gas adjustment of the chain, 1.2,
+	// and the fee is computed from it at the gas prices of the chain, 0.01uatom.
+	res := sys.MustRun(t, "tx", "simulate", "cosmoshub", msgs, "--from", "mykey", "-o", "json")
+	var result struct {
+		GasUsed       uint64  `json:"gas_used"`
+		GasAdjustment float64 `json:"gas_adjustment"`
+		Gas           uint64
+		GasPrices     string `json:"gas_prices"`
+		Fee           sdk.Coins
+		Log           string
+		Events        []struct {
+			Type       string
+			Attributes []struct{ Key, Value string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &result))
+	require.Equal(t, uint64(100000), result.GasUsed)
+	require.Equal(t, 1.2, result.GasAdjustment)
+	require.Equal(t, uint64(120000), result.Gas)
+	require.Equal(t, "0.01uatom", result.GasPrices)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1200)), result.Fee)
+	require.Equal(t, "sent", result.Log)
+	require.Len(t, result.Events, 1)
+	require.Equal(t, "transfer", result.Events[0].Type)
+
+	res = sys.MustRun(t, "tx", "simulate", msgs, "--from", "mykey", "--gas-prices", "0.05uatom", "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Gas limit:  120000 (gas adjustment 1.2)")
+	require.Contains(t, out, "Fee:        6000uatom")
+	require.Contains(t, out, "  transfer.recipient  "+testGroupMemberAddr)
+
+	// A failed simulation shows the log of the chain, with an explanation of common failures.
+	mc = new(mocks.Client)
+	mockAccount(t, mc, 3)
+	const log = "spendable balance 1uatom is smaller than 10uatom: insufficient funds"
+	mc.On("ABCIQueryWithOptions", mock.Anything, "/cosmos.tx.v1beta1.Service/Simulate", mock.Anything, rpcclient.ABCIQueryOptions{}).
+		Return(&coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 5, Codespace: "sdk", Log: log}}, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+	res = sys.Run(zaptest.NewLogger(t), "tx", "simulate", msgs, "--from", "mykey")
+	require.ErrorContains(t, res.Err, "simulation failed with code 5 (sdk): "+log+"\nThe account cannot pay the amounts of the messages")
+	var simErr cmd.TxSimulationError
+	require.ErrorAs(t, res.Err, &simErr)
+	require.Equal(t, cmd.ErrCodeTxFailed, simErr.ExitCode())
+
+	for args, msg := range map[string]string{
+		msgs + " --from nokey":                                       `key "nokey" not found on chain cosmoshub`,
+		msgs + " --from mykey --gas-prices 0.05":                     `invalid gas prices "0.05"`,
+		filepath.Join(t.TempDir(), "missing.json") + " --from mykey": "no such file",
+	} {
+		res = sys.Run(zaptest.NewLogger(t), append([]string{"tx", "simulate"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
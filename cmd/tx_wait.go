@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+)
+
+const (
+	waitTxTimeoutFlag      = "timeout"
+	waitTxPollIntervalFlag = "poll-interval"
+	waitTxConditionFlag    = "condition"
+	waitTxNoSubscribeFlag  = "no-subscribe"
+)
+
+func queryWaitTxCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait-tx [chain-name] <hash>",
+		Short: "wait for a transaction to be included in a block, and decode it",
+		Long: `Wait for the transaction of the given hex encoded hash to be included in a block of the given chain,
+or of the default chain, as after broadcasting it with --broadcast-mode async or from another tool,
+then decode it as query tx does.
+
+The transaction is awaited through a subscription over the websocket of the chain's RPC endpoint,
+and queried every --poll-interval as well, so nodes without a websocket endpoint are polled instead;
+with --no-subscribe, it is only polled.
+If it is not included within --timeout, which defaults to the chain's block-timeout, the command fails.
+
+The process exits with the code of the transaction: 0 if it succeeded, or else its non-zero code
+(or 5 if the code does not fit in an exit status).
+With --condition, the transaction must instead meet each condition, comparing one of its fields
+(code, codespace, height, gas_used, or gas_wanted) to a value with =, !=, <, <=, >, or >=;
+the process exits with 5 if it does not.`,
+		Example: fmt.Sprintf(`$ %s query wait-tx cosmoshub 1F0B3C... --timeout 60s
+$ %s q wait-tx 1F0B3C... --condition code=0 --condition "gas_used<200000" -o json
+$ %s q wait-tx 1F0B3C... --no-subscribe --poll-interval 5s`,
+			appName, appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exprs, err := cmd.Flags().GetStringArray(waitTxConditionFlag)
+			if err != nil {
+				return err
+			}
+			conditions, err := parseTxConditions(exprs)
+			if err != nil {
+				return err
+			}
+			var opts client.WaitTxOptions
+			if opts.Timeout, err = cmd.Flags().GetDuration(waitTxTimeoutFlag); err != nil {
+				return err
+			}
+			if opts.PollInterval, err = cmd.Flags().GetDuration(waitTxPollIntervalFlag); err != nil {
+				return err
+			}
+			if opts.Timeout < 0 || opts.PollInterval < 0 {
+				return fmt.Errorf("--%s and --%s must not be negative", waitTxTimeoutFlag, waitTxPollIntervalFlag)
+			}
+			noSubscribe, err := cmd.Flags().GetBool(waitTxNoSubscribeFlag)
+			if err != nil {
+				return err
+			}
+			opts.Subscribe = !noSubscribe
+
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+			hashHex := args[len(args)-1]
+			hash, err := hex.DecodeString(hashHex)
+			if err != nil {
+				return fmt.Errorf("invalid transaction hash %q: must be hex encoded", hashHex)
+			}
+
+			res, err := cl.WaitForTx(cmd.Context(), hash, opts)
+			if err != nil {
+				return err
+			}
+			result, err := decodeTxResult(cl, res)
+			if err != nil {
+				return err
+			}
+			if err := writeOutput(cmd, a, result); err != nil {
+				return err
+			}
+
+			// The transaction is written before its error, which sets the exit code.
+			if len(conditions) > 0 {
+				return checkTxConditions(res, conditions)
+			}
+			if res.TxResult.Code != 0 {
+				return TxCodeError{client.TxFailedError{
+					Code:      res.TxResult.Code,
+					Codespace: res.TxResult.Codespace,
+					TxHash:    strings.ToUpper(hex.EncodeToString(hash)),
+				}}
+			}
+			return nil
+		},
+	}
+	// Shadows the root --timeout, so that the wait fails with the timeout error of the client.
+	cmd.Flags().Duration(waitTxTimeoutFlag, 0, "how long to wait for the transaction to be included (default: the chain's block-timeout)")
+	cmd.Flags().Duration(waitTxPollIntervalFlag, client.DefaultTxPollInterval, "how often to query the transaction while waiting for it")
+	cmd.Flags().Bool(waitTxNoSubscribeFlag, false, "only poll for the transaction, without subscribing to it over the RPC websocket")
+	cmd.Flags().StringArray(waitTxConditionFlag, nil, "require the transaction to meet this condition, such as code=0, instead of succeeding (repeatable)")
+	return cmd
+}
+
+// txCondition is a condition on a field of an included transaction, given to query wait-tx.
+type txCondition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+func (c txCondition) String() string {
+	return c.Field + c.Op + c.Value
+}
+
+// txConditionRE matches a condition, with the longer operators tried first.
+var txConditionRE = regexp.MustCompile(`^\s*([a-z_]+)\s*(!=|<=|>=|=|<|>)\s*(.*?)\s*$`)
+
+// parseTxConditions parses the --condition expressions of query wait-tx.
+func parseTxConditions(exprs []string) ([]txCondition, error) {
+	conditions := make([]txCondition, 0, len(exprs))
+	for _, expr := range exprs {
+		m := txConditionRE.FindStringSubmatch(expr)
+		if m == nil {
+			return nil, fmt.Errorf("invalid --%s %q: must be a field, an operator, and a value, such as code=0", waitTxConditionFlag, expr)
+		}
+		c := txCondition{Field: m[1], Op: m[2], Value: m[3]}
+		switch c.Field {
+		case "codespace":
+			if c.Op != "=" && c.Op != "!=" {
+				return nil, fmt.Errorf("invalid --%s %q: codespace can only be compared with = or !=", waitTxConditionFlag, expr)
+			}
+		case "code", "height", "gas_used", "gas_wanted":
+			if _, err := strconv.ParseInt(c.Value, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid --%s %q: %s must be compared to an integer", waitTxConditionFlag, expr, c.Field)
+			}
+		default:
+			return nil, fmt.Errorf("invalid --%s %q: unknown field %q (must be one of code, codespace, height, gas_used, gas_wanted)", waitTxConditionFlag, expr, c.Field)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// checkTxConditions returns a TxConditionError listing the conditions res does not meet, if any.
+func checkTxConditions(res *coretypes.ResultTx, conditions []txCondition) error {
+	var failed []string
+	for _, c := range conditions {
+		var actual string
+		var ok bool
+		switch c.Field {
+		case "codespace":
+			actual = res.TxResult.Codespace
+			ok = (actual == c.Value) == (c.Op == "=")
+		default:
+			n := map[string]int64{
+				"code":       int64(res.TxResult.Code),
+				"height":     res.Height,
+				"gas_used":   res.TxResult.GasUsed,
+				"gas_wanted": res.TxResult.GasWanted,
+			}[c.Field]
+			actual = strconv.FormatInt(n, 10)
+			ok = compareInt(n, c.Op, c.Value)
+		}
+		if !ok {
+			failed = append(failed, fmt.Sprintf("%s (%s is %s)", c, c.Field, orDash(actual)))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return TxConditionError{TxHash: strings.ToUpper(hex.EncodeToString(res.Hash)), Failed: failed}
+}
+
+// compareInt reports whether n compares to the integer value as op says.
+// The value has been checked by parseTxConditions.
+func compareInt(n int64, op, value string) bool {
+	v, _ := strconv.ParseInt(value, 10, 64)
+	switch op {
+	case "=":
+		return n == v
+	case "!=":
+		return n != v
+	case "<":
+		return n < v
+	case "<=":
+		return n <= v
+	case ">":
+		return n > v
+	default:
+		return n >= v
+	}
+}
@@ -0,0 +1,60 @@
+package cmd_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQueryWaitTx(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	included := testTxResult(t, 101, 10)
+	failed := testTxResult(t, 102, 20)
+	failed.TxResult.Code = 5
+	failed.TxResult.Codespace = "sdk"
+	failed.TxResult.GasUsed = 300000
+
+	// The node has no websocket endpoint, so the transactions are polled for until they are found.
+	mc := new(mocks.Client)
+	mc.On("IsRunning").Return(false)
+	mc.On("Start").Return(errors.New("websocket: bad handshake"))
+	mc.On("Tx", mock.Anything, []byte(included.Hash), false).Return(nil, errors.New("tx not found")).Once()
+	mc.On("Tx", mock.Anything, []byte(included.Hash), false).Return(included, nil)
+	mc.On("Tx", mock.Anything, []byte(failed.Hash), false).Return(failed, nil)
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "wait-tx", "cosmoshub", included.Hash.String(), "--poll-interval", "10ms", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Height:           101\n")
+	require.Contains(t, res.Stdout.String(), "Message 0: /cosmos.bank.v1beta1.MsgSend")
+	mc.AssertNumberOfCalls(t, "Tx", 2)
+
+	// The failed transaction is written, and its code is the exit status.
+	res = sys.Run(zaptest.NewLogger(t), "query", "wait-tx", strings.ToLower(failed.Hash.String()), "--no-subscribe", "-o", "text")
+	require.Contains(t, res.Stdout.String(), "Height:           102\n")
+	require.Equal(t, 5, res.ExitCode)
+	require.EqualError(t, res.Err, "transaction "+failed.Hash.String()+" failed with code 5 (sdk)")
+
+	// Conditions replace the check of the code.
+	sys.MustRun(t, "query", "wait-tx", failed.Hash.String(), "--condition", "code=5", "--condition", "codespace = sdk")
+	res = sys.Run(zaptest.NewLogger(t), "query", "wait-tx", failed.Hash.String(), "--condition", "code!=5", "--condition", "gas_used<200000", "--condition", "height>=100")
+	require.Equal(t, cmd.ErrCodeTxFailed, res.ExitCode)
+	require.EqualError(t, res.Err, "transaction "+failed.Hash.String()+" does not meet the condition code!=5 (code is 5), nor gas_used<200000 (gas_used is 300000)")
+
+	for _, c := range []string{"code", "fee=5", "code=abc", "codespace<sdk"} {
+		res = sys.Run(zaptest.NewLogger(t), "query", "wait-tx", failed.Hash.String(), "--condition", c)
+		require.ErrorContains(t, res.Err, "invalid --condition", c)
+	}
+	res = sys.Run(zaptest.NewLogger(t), "query", "wait-tx", "xyz")
+	require.EqualError(t, res.Err, `invalid transaction hash "xyz": must be hex encoded`)
+}
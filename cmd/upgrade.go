@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/query"
+	"go.uber.org/zap"
+)
+
+const upgradeSamplesFlag = "samples"
+
+// upgradeQueryCmd returns the upgrade query commands for this module
+func upgradeQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "upgrade",
+		Aliases: []string{"up"},
+		Short:   "Querying commands for the upgrade module",
+	}
+
+	cmd.AddCommand(
+		upgradePlanCmd(a),
+		upgradeModuleVersionsCmd(a),
+		upgradeAppliedCmd(a),
+	)
+
+	return cmd
+}
+
+func upgradePlanCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan [chain-name]",
+		Short: "query the upgrade scheduled on a chain",
+		Long: `Query the upgrade scheduled on the given chain, or on the default chain,
+with its name, height, and info.
+
+The time the chain reaches the upgrade height is estimated from the latest block
+and the average time between the last --samples blocks.`,
+		Example: fmt.Sprintf(`$ %s query upgrade plan cosmoshub
+$ %s q upgrade plan osmosis --samples 1000 -o json`,
+			appName, appName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 1 {
+				chainName = args[0]
+			}
+			samples, err := cmd.Flags().GetInt64(upgradeSamplesFlag)
+			if err != nil {
+				return err
+			}
+			if samples < 1 {
+				return fmt.Errorf("--%s must be positive", upgradeSamplesFlag)
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Upgrade_CurrentPlan()
+			if err != nil {
+				return err
+			}
+			if res.Plan == nil {
+				return writeOutput(cmd, a, upgradePlanResult{ChainID: cl.Config.ChainID})
+			}
+
+			result := upgradePlanResult{
+				ChainID:   cl.Config.ChainID,
+				Scheduled: true,
+				Name:      res.Plan.Name,
+				Height:    res.Plan.Height,
+				Info:      upgradeInfo(res.Plan.Info),
+			}
+			// The plan is still shown if the block time cannot be sampled, only without its estimated time.
+			sample, err := cl.SampleBlockTime(cmd.Context(), samples)
+			if err != nil {
+				a.Log.Warn("Failed to estimate the upgrade time", zap.String("chain_name", chainName), zap.Error(err))
+				return writeOutput(cmd, a, result)
+			}
+			result.CurrentHeight = sample.LatestHeight
+			result.BlocksLeft = res.Plan.Height - sample.LatestHeight
+			result.AverageBlockTime = sample.Average.String()
+			estimate := sample.Estimate(res.Plan.Height).UTC()
+			result.EstimatedTime = &estimate
+			return writeOutput(cmd, a, result)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	cmd.Flags().Int64(upgradeSamplesFlag, client.DefaultBlockTimeSamples, "number of recent blocks to average the block time over")
+	return cmd
+}
+
+// upgradeInfo returns the info of an upgrade plan as is if it is JSON, such as the binaries of the upgrade,
+// or as a JSON string otherwise.
+func upgradeInfo(info string) json.RawMessage {
+	if info == "" {
+		return nil
+	}
+	if json.Valid([]byte(info)) {
+		return json.RawMessage(info)
+	}
+	b, _ := json.Marshal(info)
+	return b
+}
+
+// upgradePlanResult is the result of query upgrade plan.
+type upgradePlanResult struct {
+	ChainID          string          `json:"chain_id"`
+	Scheduled        bool            `json:"scheduled"`
+	Name             string          `json:"name,omitempty"`
+	Height           int64           `json:"height,omitempty"`
+	Info             json.RawMessage `json:"info,omitempty"`
+	CurrentHeight    int64           `json:"current_height,omitempty"`
+	BlocksLeft       int64           `json:"blocks_left,omitempty"`
+	AverageBlockTime string          `json:"average_block_time,omitempty"`
+	EstimatedTime    *time.Time      `json:"estimated_time,omitempty"`
+}
+
+var _ fmt.Stringer = upgradePlanResult{}
+
+// String returns the fields of the plan one per line, followed by its info.
+func (r upgradePlanResult) String() string {
+	if !r.Scheduled {
+		return fmt.Sprintf("No upgrade is scheduled on chain %s.\n", r.ChainID)
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", r.Name)
+	fmt.Fprintf(w, "Height:\t%d\n", r.Height)
+	if r.EstimatedTime != nil {
+		fmt.Fprintf(w, "Current height:\t%d\n", r.CurrentHeight)
+		if r.BlocksLeft > 0 {
+			fmt.Fprintf(w, "Blocks left:\t%d\n", r.BlocksLeft)
+		}
+		fmt.Fprintf(w, "Average block time:\t%s\n", r.AverageBlockTime)
+		fmt.Fprintf(w, "Estimated time:\t%s\n", r.EstimatedTime.Format(time.RFC3339))
+	}
+	w.Flush()
+
+	if len(r.Info) > 0 {
+		b.WriteString("\nInfo:\n")
+		var s string
+		if json.Unmarshal(r.Info, &s) == nil {
+			fmt.Fprintln(&b, s)
+		} else if err := writeJSON(&b, r.Info); err != nil {
+			fmt.Fprintln(&b, string(r.Info))
+		}
+	}
+	return b.String()
+}
+
+func upgradeModuleVersionsCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "module-versions [chain-name] [module]",
+		Aliases: []string{"versions", "mv"},
+		Short:   "query the consensus versions of the modules of a chain",
+		Long: `Query the consensus version of every module of the given chain, or of the default chain,
+or of a single module.
+
+If a single argument is given and it names a configured chain, every module of that chain is listed;
+otherwise it is taken as a module of the default chain.`,
+		Example: fmt.Sprintf(`$ %s query upgrade module-versions cosmoshub
+$ %s q upgrade module-versions osmosis bank
+$ %s q upgrade module-versions staking -o json`,
+			appName, appName, appName),
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, module := a.Config.DefaultChain, ""
+			switch len(args) {
+			case 1:
+				if _, ok := a.Config.Chains[args[0]]; ok {
+					chainName = args[0]
+				} else {
+					module = args[0]
+				}
+			case 2:
+				chainName, module = args[0], args[1]
+			}
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Upgrade_ModuleVersions(module)
+			if err != nil {
+				return err
+			}
+
+			result := make(moduleVersionsResult, len(res.ModuleVersions))
+			for i, v := range res.ModuleVersions {
+				result[i] = moduleVersion{Name: v.Name, Version: v.Version}
+			}
+			return writeOutput(cmd, a, result)
+		},
+	}
+	addQueryHeightFlag(cmd)
+	return cmd
+}
+
+// moduleVersion is one module listed by query upgrade module-versions.
+type moduleVersion struct {
+	Name    string `json:"name"`
+	Version uint64 `json:"version"`
+}
+
+// moduleVersionsResult is the result of query upgrade module-versions.
+type moduleVersionsResult []moduleVersion
+
+var _ fmt.Stringer = moduleVersionsResult(nil)
+
+// String returns the module versions as a table with aligned columns.
+func (r moduleVersionsResult) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tVERSION")
+	for _, v := range r {
+		fmt.Fprintf(w, "%s\t%d\n", v.Name, v.Version)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func upgradeAppliedCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "applied [chain-name] <upgrade-name>",
+		Short: "query the height at which a past upgrade was applied",
+		Long: `Query the height at which the upgrade of the given name was applied on the given chain, or on the default chain.
+It is an error if the upgrade was never applied.`,
+		Example: fmt.Sprintf(`$ %s query upgrade applied cosmoshub v10
+$ %s q upgrade applied v10 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName := a.Config.DefaultChain
+			if len(args) == 2 {
+				chainName = args[0]
+			}
+			name := args[len(args)-1]
+			cl, err := chainClientByName(a, chainName)
+			if err != nil {
+				return err
+			}
+
+			opts, err := queryOptionsFromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			query := query.Query{Ctx: cmd.Context(), Client: cl, Options: opts}
+			res, err := query.Upgrade_AppliedPlan(name)
+			if err != nil {
+				return err
+			}
+			if res.Height == 0 {
+				return fmt.Errorf("upgrade %q has not been applied on chain %s", name, cl.Config.ChainID)
+			}
+			return writeOutput(cmd, a, appliedUpgrade{Name: name, Height: res.Height})
+		},
+	}
+	addQueryHeightFlag(cmd)
+	return cmd
+}
+
+// appliedUpgrade is the result of query upgrade applied.
+type appliedUpgrade struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+}
+
+var _ fmt.Stringer = appliedUpgrade{}
+
+// String returns the height at which the upgrade was applied.
+func (u appliedUpgrade) String() string {
+	return fmt.Sprintf("Upgrade %s was applied at height %d.\n", u.Name, u.Height)
+}
@@ -0,0 +1,140 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/rpc/client/mocks"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// mockBlockTimes makes mc return the headers of blocks produced every interval from start, at height 0.
+func mockBlockTimes(mc *mocks.Client, start time.Time, interval time.Duration) {
+	mc.On("BlockchainInfo", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, min, max int64) *coretypes.ResultBlockchainInfo {
+			res := &coretypes.ResultBlockchainInfo{LastHeight: max}
+			for h := max; h >= min; h-- {
+				res.BlockMetas = append(res.BlockMetas, &tmtypes.BlockMeta{
+					Header: tmtypes.Header{Height: h, Time: start.Add(time.Duration(h) * interval)},
+				})
+			}
+			return res
+		},
+		nil,
+	)
+}
+
+func TestUpgradePlan(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mc := new(mocks.Client)
+	mockBlockStatus(mc)
+	mockBlockTimes(mc, start, 6*time.Second)
+	mockABCIQuery(t, mc, "/cosmos.upgrade.v1beta1.Query/CurrentPlan", func(bytes.HexBytes) bool { return true },
+		&upgradetypes.QueryCurrentPlanResponse{Plan: &upgradetypes.Plan{
+			Name:   "v15",
+			Height: 200,
+			Info:   `{"binaries":{"linux/amd64":"https://example.com/gaiad"}}`,
+		}})
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	res := sys.MustRun(t, "query", "upgrade", "plan", "cosmoshub", "--samples", "30", "-o", "text")
+	out := res.Stdout.String()
+	require.Contains(t, out, "Blocks left:         100\n")
+	require.Contains(t, out, "Average block time:  6s\n")
+	require.Contains(t, out, "Estimated time:      2026-01-01T00:20:00Z\n")
+	require.Contains(t, out, `"linux/amd64": "https://example.com/gaiad"`)
+
+	// The 31 headers sampled are fetched in two requests.
+	mc.AssertNumberOfCalls(t, "BlockchainInfo", 2)
+	mc.AssertCalled(t, "BlockchainInfo", mock.Anything, int64(81), int64(100))
+	mc.AssertCalled(t, "BlockchainInfo", mock.Anything, int64(70), int64(80))
+
+	// The blocks sampled stop at the earliest block kept by the node.
+	res = sys.MustRun(t, "query", "upgrade", "plan", "-o", "json")
+	var plan struct {
+		Name             string
+		Height           int64
+		CurrentHeight    int64     `json:"current_height"`
+		AverageBlockTime string    `json:"average_block_time"`
+		EstimatedTime    time.Time `json:"estimated_time"`
+		Info             struct {
+			Binaries map[string]string
+		}
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &plan))
+	require.Equal(t, "v15", plan.Name)
+	require.Equal(t, int64(100), plan.CurrentHeight)
+	require.Equal(t, "6s", plan.AverageBlockTime)
+	require.Equal(t, start.Add(200*6*time.Second), plan.EstimatedTime)
+	require.Equal(t, "https://example.com/gaiad", plan.Info.Binaries["linux/amd64"])
+	mc.AssertCalled(t, "BlockchainInfo", mock.Anything, int64(10), int64(20))
+
+	res = sys.Run(zaptest.NewLogger(t), "query", "upgrade", "plan", "--samples", "0")
+	require.ErrorContains(t, res.Err, "--samples must be positive")
+}
+
+func TestUpgradeNoPlan(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	mc := new(mocks.Client)
+	mockABCIQuery(t, mc, "/cosmos.upgrade.v1beta1.Query/CurrentPlan", func(bytes.HexBytes) bool { return true },
+		&upgradetypes.QueryCurrentPlanResponse{})
+	mockABCIQuery(t, mc, "/cosmos.upgrade.v1beta1.Query/ModuleVersions", func(data bytes.HexBytes) bool {
+		var req upgradetypes.QueryModuleVersionsRequest
+		return req.Unmarshal(data) == nil && req.ModuleName == ""
+	}, &upgradetypes.QueryModuleVersionsResponse{ModuleVersions: []*upgradetypes.ModuleVersion{
+		{Name: "bank", Version: 4},
+		{Name: "staking", Version: 4},
+	}})
+	mockABCIQuery(t, mc, "/cosmos.upgrade.v1beta1.Query/ModuleVersions", func(data bytes.HexBytes) bool {
+		var req upgradetypes.QueryModuleVersionsRequest
+		return req.Unmarshal(data) == nil && req.ModuleName == "bank"
+	}, &upgradetypes.QueryModuleVersionsResponse{ModuleVersions: []*upgradetypes.ModuleVersion{
+		{Name: "bank", Version: 4},
+	}})
+	for name, height := range map[string]int64{"v10": 12345, "v99": 0} {
+		name, height := name, height
+		mockABCIQuery(t, mc, "/cosmos.upgrade.v1beta1.Query/AppliedPlan", func(data bytes.HexBytes) bool {
+			var req upgradetypes.QueryAppliedPlanRequest
+			return req.Unmarshal(data) == nil && req.Name == name
+		}, &upgradetypes.QueryAppliedPlanResponse{Height: height})
+	}
+	sys.OverrideClients("cosmoshub", cmd.ClientOverrides{
+		RPCClient: mc,
+	})
+
+	// Without a plan, no block time is sampled.
+	res := sys.MustRun(t, "query", "upgrade", "plan", "-o", "text")
+	require.Equal(t, "No upgrade is scheduled on chain cosmoshub-4.\n", res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "upgrade", "module-versions", "cosmoshub", "-o", "text")
+	lines := strings.Split(strings.TrimSpace(res.Stdout.String()), "\n")
+	require.Equal(t, []string{"MODULE", "VERSION"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"bank", "4"}, strings.Fields(lines[1]))
+	require.Len(t, lines, 3)
+	res = sys.MustRun(t, "query", "upgrade", "module-versions", "bank", "-o", "text")
+	require.Len(t, strings.Split(strings.TrimSpace(res.Stdout.String()), "\n"), 2)
+
+	res = sys.MustRun(t, "query", "upgrade", "applied", "cosmoshub", "v10", "-o", "json")
+	require.JSONEq(t, `{"name":"v10","height":12345}`, res.Stdout.String())
+	res = sys.Run(zaptest.NewLogger(t), "query", "upgrade", "applied", "v99")
+	require.ErrorContains(t, res.Err, `upgrade "v99" has not been applied on chain cosmoshub-4`)
+}
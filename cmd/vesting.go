@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	vestingEndTimeFlag   = "end-time"
+	vestingStartTimeFlag = "start-time"
+	vestingDelayedFlag   = "delayed"
+	vestingPreviewFlag   = "preview"
+)
+
+// vestingTimeHelp describes the formats accepted by parseVestingTime, for use in flag usages.
+const vestingTimeHelp = "as a date (2006-01-02), an RFC 3339 time (2006-01-02T15:04:05Z), or a duration from now (+180d, +2w, +36h)"
+
+// parseVestingTime parses the value of a time flag of the vesting commands, relative to now if it starts with +.
+// Besides the units of time.ParseDuration, relative times may be given in days (d) or weeks (w).
+func parseVestingTime(value string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(value, "+") {
+		rel := value[1:]
+		var d time.Duration
+		var err error
+		switch {
+		case strings.HasSuffix(rel, "d"), strings.HasSuffix(rel, "w"):
+			unit := 24 * time.Hour
+			if strings.HasSuffix(rel, "w") {
+				unit *= 7
+			}
+			var n int64
+			n, err = strconv.ParseInt(rel[:len(rel)-1], 10, 64)
+			if err == nil && n > math.MaxInt64/int64(unit) {
+				return time.Time{}, fmt.Errorf("invalid time %q: too far in the future", value)
+			}
+			d = time.Duration(n) * unit
+		default:
+			d, err = time.ParseDuration(rel)
+		}
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid time %q: must be given %s", value, vestingTimeHelp)
+		}
+		return now.Add(d), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: must be given %s", value, vestingTimeHelp)
+		}
+	}
+	return t, nil
+}
+
+// vestingTxCmd returns the vesting tx commands.
+func vestingTxCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vesting",
+		Short: "vesting transaction commands",
+	}
+	cmd.AddCommand(
+		vestingCreateCmd(a),
+		vestingCreatePeriodicCmd(a),
+	)
+	return cmd
+}
+
+func vestingCreateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [chain-name] <from-key> <to-address> <amount>",
+		Short: "create a continuous or delayed vesting account funded by a key",
+		Long: `Create a vesting account at the address, which must not exist yet, funded with the amount
+by a key in the keyring of the given chain, or of the default chain.
+
+The amount vests continuously from the time the transaction is included until --end-time,
+or with --delayed, all at once at --end-time. --end-time is given ` + vestingTimeHelp + `,
+and must be in the future. With --preview, the schedule is written to standard error before the transaction is sent.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx vesting create cosmoshub mykey cosmos1... 1000000uatom --end-time 2026-01-01T00:00:00Z
+$ %s tx vesting create mykey cosmos1... 1000000uatom --end-time +180d --delayed --preview --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endArg, err := cmd.Flags().GetString(vestingEndTimeFlag)
+			if err != nil {
+				return err
+			}
+			delayed, err := cmd.Flags().GetBool(vestingDelayedFlag)
+			if err != nil {
+				return err
+			}
+			preview, err := cmd.Flags().GetBool(vestingPreviewFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 3)
+			cl, fromAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			toAddr, err := cl.DecodeBech32AccAddr(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid destination address %q for account prefix %q: %w", args[1], cl.Config.AccountPrefix, err)
+			}
+			amount, err := sdk.ParseCoinsNormalized(args[2])
+			if err != nil || amount.Empty() {
+				return fmt.Errorf("invalid amount %q: must be a positive amount of coins (e.g. 1000uatom)", args[2])
+			}
+
+			now := time.Now()
+			end, err := parseVestingTime(endArg, now)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %w", vestingEndTimeFlag, err)
+			}
+			if !end.After(now) {
+				return fmt.Errorf("invalid --%s %q: must be in the future", vestingEndTimeFlag, endArg)
+			}
+
+			if preview {
+				var steps vestingSchedule
+				if delayed {
+					steps = vestingSchedule{{Time: end, Amount: amount, Vested: amount}}
+				} else {
+					steps = continuousVestingSchedule(amount, now, end)
+				}
+				fmt.Fprint(cmd.ErrOrStderr(), steps.String())
+			}
+
+			return sendTx(cmd, a, cl, &vestingtypes.MsgCreateVestingAccount{
+				FromAddress: cl.MustEncodeAccAddr(fromAddr),
+				ToAddress:   cl.MustEncodeAccAddr(toAddr),
+				Amount:      amount,
+				EndTime:     end.Unix(),
+				Delayed:     delayed,
+			})
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(vestingEndTimeFlag, "", "the time the amount has fully vested, "+vestingTimeHelp)
+	cmd.Flags().Bool(vestingDelayedFlag, false, "vest the whole amount at --end-time, instead of continuously until then")
+	cmd.Flags().Bool(vestingPreviewFlag, false, "write the vesting schedule to standard error before sending the transaction")
+	if err := cmd.MarkFlagRequired(vestingEndTimeFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func vestingCreatePeriodicCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-periodic [chain-name] <from-key> <to-address> <total> <schedule-file>",
+		Short: "create a periodic vesting account funded by a key",
+		Long: `Create a periodic vesting account at the address, which must not exist yet, funded with the total
+by a key in the keyring of the given chain, or of the default chain. A schedule file of "-" is read from standard input.
+
+The schedule is a list of periods, each vesting an amount once its length has passed after the end of the previous one,
+starting at --start-time (default: now), which is given ` + vestingTimeHelp + `.
+The amounts of the periods must add up to the total. The schedule is either a JSON list:
+
+  [{"length_seconds": 2592000, "amount": "1000uatom"}, {"length_seconds": 2592000, "amount": "1000uatom"}]
+
+or, as written by the SDK's own command, a JSON object with "start_time" in Unix seconds and "periods"
+whose amounts are named "coins", or CSV rows of length_seconds,amount, with an optional header row.
+With --preview, the schedule is written to standard error before the transaction is sent.
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx vesting create-periodic cosmoshub mykey cosmos1... 2000uatom schedule.json
+$ %s tx vesting create-periodic mykey cosmos1... 2000uatom schedule.csv --start-time 2026-01-01 --preview --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(4, 5)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startArg, err := cmd.Flags().GetString(vestingStartTimeFlag)
+			if err != nil {
+				return err
+			}
+			preview, err := cmd.Flags().GetBool(vestingPreviewFlag)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 4)
+			cl, fromAddr, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			toAddr, err := cl.DecodeBech32AccAddr(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid destination address %q for account prefix %q: %w", args[1], cl.Config.AccountPrefix, err)
+			}
+			total, err := sdk.ParseCoinsNormalized(args[2])
+			if err != nil || total.Empty() {
+				return fmt.Errorf("invalid total %q: must be a positive amount of coins (e.g. 1000uatom)", args[2])
+			}
+			bz, err := readFileOrStdin(cmd, args[3])
+			if err != nil {
+				return err
+			}
+			start, periods, err := parseVestingPeriods(bz)
+			if err != nil {
+				return fmt.Errorf("failed to read the schedule from %s: %w", args[3], err)
+			}
+			if sum := periods.TotalAmount(); !sum.IsEqual(total) {
+				return fmt.Errorf("the amounts of the periods add up to %s, not to the total %s", sum, total)
+			}
+
+			now := time.Now()
+			if startArg != "" {
+				if start, err = parseVestingTime(startArg, now); err != nil {
+					return fmt.Errorf("invalid --%s: %w", vestingStartTimeFlag, err)
+				}
+			} else if start.IsZero() {
+				start = now
+			}
+
+			if preview {
+				fmt.Fprint(cmd.ErrOrStderr(), periodicVestingSchedule(start, periods).String())
+			}
+
+			return sendTx(cmd, a, cl, &vestingtypes.MsgCreatePeriodicVestingAccount{
+				FromAddress:    cl.MustEncodeAccAddr(fromAddr),
+				ToAddress:      cl.MustEncodeAccAddr(toAddr),
+				StartTime:      start.Unix(),
+				VestingPeriods: periods,
+			})
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(vestingStartTimeFlag, "", "the time the first period starts, "+vestingTimeHelp+" (default: the schedule's start_time, or now)")
+	cmd.Flags().Bool(vestingPreviewFlag, false, "write the vesting schedule to standard error before sending the transaction")
+	return cmd
+}
+
+// vestingPeriodJSON is a period of a JSON schedule of create-periodic.
+// Amount and Coins are aliases, the latter used by the schedules of the SDK's own command.
+type vestingPeriodJSON struct {
+	LengthSeconds int64  `json:"length_seconds"`
+	Amount        string `json:"amount"`
+	Coins         string `json:"coins"`
+}
+
+// parseVestingPeriods parses the schedule file of create-periodic,
+// returning its start time, if it has one, and its periods, each of which is checked.
+func parseVestingPeriods(bz []byte) (time.Time, vestingtypes.Periods, error) {
+	var start time.Time
+	var rows []vestingPeriodJSON
+	trimmed := bytes.TrimSpace(bz)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &rows); err != nil {
+			return start, nil, err
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var data struct {
+			StartTime int64               `json:"start_time"`
+			Periods   []vestingPeriodJSON `json:"periods"`
+		}
+		if err := json.Unmarshal(trimmed, &data); err != nil {
+			return start, nil, err
+		}
+		if data.StartTime != 0 {
+			start = time.Unix(data.StartTime, 0).UTC()
+		}
+		rows = data.Periods
+	default:
+		r := csv.NewReader(bytes.NewReader(trimmed))
+		r.TrimLeadingSpace = true
+		for {
+			rec, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return start, nil, err
+			}
+			line, _ := r.FieldPos(0)
+			if len(rows) == 0 && strings.EqualFold(rec[0], "length_seconds") {
+				continue
+			}
+			if len(rec) != 2 {
+				return start, nil, fmt.Errorf("line %d: expected length_seconds,amount, got %d fields", line, len(rec))
+			}
+			length, err := strconv.ParseInt(strings.TrimSpace(rec[0]), 10, 64)
+			if err != nil {
+				return start, nil, fmt.Errorf("line %d: invalid length %q: must be a number of seconds", line, rec[0])
+			}
+			rows = append(rows, vestingPeriodJSON{LengthSeconds: length, Amount: strings.TrimSpace(rec[1])})
+		}
+	}
+	if len(rows) == 0 {
+		return start, nil, errors.New("no periods")
+	}
+
+	periods := make(vestingtypes.Periods, len(rows))
+	for i, row := range rows {
+		if row.Amount != "" && row.Coins != "" {
+			return start, nil, fmt.Errorf("period %d: amount and coins are aliases, and cannot both be given", i+1)
+		}
+		amountStr := row.Amount + row.Coins
+		amount, err := sdk.ParseCoinsNormalized(amountStr)
+		if err != nil || amount.Empty() {
+			return start, nil, fmt.Errorf("period %d: invalid amount %q: must be a positive amount of coins (e.g. 1000uatom)", i+1, amountStr)
+		}
+		if row.LengthSeconds < 1 {
+			return start, nil, fmt.Errorf("period %d: invalid length %d: must be a positive number of seconds", i+1, row.LengthSeconds)
+		}
+		periods[i] = vestingtypes.Period{Length: row.LengthSeconds, Amount: amount}
+	}
+	return start, periods, nil
+}
+
+// vestingStep is a time of a vesting schedule, the amount vesting at it, and the amount vested by then.
+type vestingStep struct {
+	Time   time.Time `json:"time"`
+	Amount sdk.Coins `json:"amount"`
+	Vested sdk.Coins `json:"vested"`
+}
+
+// vestingSchedule is the preview of the vesting schedule of a vesting account.
+type vestingSchedule []vestingStep
+
+// continuousVestingSchedule returns the schedule of amount vesting continuously from start to end,
+// at every quarter of the vesting time.
+func continuousVestingSchedule(amount sdk.Coins, start, end time.Time) vestingSchedule {
+	const steps = 4
+	// The times are computed in seconds, as the chain keeps them: a time.Duration overflows past 292 years, and its multiples sooner.
+	total := end.Unix() - start.Unix()
+	var s vestingSchedule
+	vested := sdk.NewCoins()
+	for i := int64(1); i <= steps; i++ {
+		at := time.Unix(start.Unix()+total*i/steps, 0).In(start.Location())
+		// As the chain computes it, each coin vests in proportion to the time passed, truncated.
+		var now sdk.Coins
+		for _, c := range amount {
+			now = now.Add(sdk.NewCoin(c.Denom, c.Amount.MulRaw(i).QuoRaw(steps)))
+		}
+		s = append(s, vestingStep{Time: at, Amount: now.Sub(vested...), Vested: now})
+		vested = now
+	}
+	return s
+}
+
+// periodicVestingSchedule returns the schedule of periods starting at start.
+func periodicVestingSchedule(start time.Time, periods vestingtypes.Periods) vestingSchedule {
+	var s vestingSchedule
+	at := start
+	vested := sdk.NewCoins()
+	for _, p := range periods {
+		at = at.Add(p.Duration())
+		vested = vested.Add(p.Amount...)
+		s = append(s, vestingStep{Time: at, Amount: p.Amount, Vested: vested})
+	}
+	return s
+}
+
+var _ fmt.Stringer = vestingSchedule{}
+
+func (s vestingSchedule) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tVESTS\tVESTED")
+	for _, step := range s {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", step.Time.UTC().Format(time.RFC3339), step.Amount, step.Vested)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestVestingCreate(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+
+	var msgs []struct {
+		Type        string `json:"@type"`
+		FromAddress string `json:"from_address"`
+		ToAddress   string `json:"to_address"`
+		Amount      []map[string]string
+		EndTime     string `json:"end_time"`
+		Delayed     bool
+	}
+	res := sys.MustRun(t, "tx", "vesting", "create", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", "--end-time", "2100-01-01T00:00:00Z", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.vesting.v1beta1.MsgCreateVestingAccount", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].FromAddress)
+	require.Equal(t, testGroupMemberAddr, msgs[0].ToAddress)
+	require.Equal(t, "4102444800", msgs[0].EndTime)
+	require.False(t, msgs[0].Delayed)
+	require.Empty(t, res.Stderr.String())
+
+	// The continuous schedule is previewed at every quarter of the vesting time.
+	res = sys.MustRun(t, "tx", "vesting", "create", "cosmoshub", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom,3uosmo", "--end-time", "+4d", "--preview", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	end, err := strconv.ParseInt(msgs[0].EndTime, 10, 64)
+	require.NoError(t, err)
+	require.InDelta(t, time.Now().Add(4*24*time.Hour).Unix(), end, 60)
+	lines := strings.Split(strings.TrimSpace(res.Stderr.String()), "\n")
+	require.Len(t, lines, 5)
+	require.Equal(t, []string{"TIME", "VESTS", "VESTED"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"250uatom", "250uatom"}, strings.Fields(lines[1])[1:])
+	require.Equal(t, []string{"250uatom,1uosmo", "500uatom,1uosmo"}, strings.Fields(lines[2])[1:])
+	require.Equal(t, []string{"250uatom,1uosmo", "750uatom,2uosmo"}, strings.Fields(lines[3])[1:])
+	require.Equal(t, []string{"250uatom,1uosmo", "1000uatom,3uosmo"}, strings.Fields(lines[4])[1:])
+
+	res = sys.MustRun(t, "tx", "vesting", "create", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", "--end-time", "2100-01-01", "--delayed", "--preview", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.True(t, msgs[0].Delayed)
+	lines = strings.Split(strings.TrimSpace(res.Stderr.String()), "\n")
+	require.Equal(t, []string{"2100-01-01T00:00:00Z", "1000uatom", "1000uatom"}, strings.Fields(lines[1]))
+
+	// The quarters of a vesting time too long for a time.Duration are still in order.
+	res = sys.MustRun(t, "tx", "vesting", "create", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", "--end-time", "2150-01-01", "--preview", "--dry-run")
+	lines = strings.Split(strings.TrimSpace(res.Stderr.String()), "\n")
+	require.Len(t, lines, 5)
+	var prev time.Time
+	for _, line := range lines[1:] {
+		at, err := time.Parse(time.RFC3339, strings.Fields(line)[0])
+		require.NoError(t, err, line)
+		require.True(t, at.After(prev), line)
+		prev = at
+	}
+	require.Equal(t, "2150-01-01T00:00:00Z", prev.UTC().Format(time.RFC3339))
+
+	for args, msg := range map[string]string{
+		"--end-time 2020-01-01":   `invalid --end-time "2020-01-01": must be in the future`,
+		"--end-time +soon":        `invalid --end-time: invalid time "+soon": must be given as a date`,
+		"--end-time +-3d":         `invalid time "+-3d"`,
+		"--end-time +300000d":     `invalid time "+300000d": too far in the future`,
+		"--end-time +40000w":      `invalid time "+40000w": too far in the future`,
+		"--end-time 01/02/2100":   `invalid time "01/02/2100"`,
+		"--delayed":               `required flag(s) "end-time" not set`,
+		"--end-time +1d --amount": "unknown flag: --amount",
+	} {
+		res := sys.Run(zaptest.NewLogger(t), append([]string{"tx", "vesting", "create", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", "--dry-run"}, strings.Fields(args)...)...)
+		require.ErrorContains(t, res.Err, msg, args)
+	}
+}
+
+func TestVestingCreatePeriodic(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	dir := t.TempDir()
+	schedule := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	var msgs []struct {
+		Type           string `json:"@type"`
+		StartTime      string `json:"start_time"`
+		VestingPeriods []struct {
+			Length string
+			Amount []map[string]string
+		} `json:"vesting_periods"`
+	}
+	jsonList := schedule("schedule.json", `[{"length_seconds": 2592000, "amount": "1000uatom"}, {"length_seconds": 86400, "amount": "500uatom"}]`)
+	res := sys.MustRun(t, "tx", "vesting", "create-periodic", ZeroCosmosAddr, testGroupMemberAddr, "1500uatom", jsonList,
+		"--start-time", "2026-01-01", "--preview", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount", msgs[0].Type)
+	require.Equal(t, "1767225600", msgs[0].StartTime)
+	require.Len(t, msgs[0].VestingPeriods, 2)
+	require.Equal(t, "2592000", msgs[0].VestingPeriods[0].Length)
+	require.Equal(t, "500", msgs[0].VestingPeriods[1].Amount[0]["amount"])
+	lines := strings.Split(strings.TrimSpace(res.Stderr.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"2026-01-31T00:00:00Z", "1000uatom", "1000uatom"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"2026-02-01T00:00:00Z", "500uatom", "1500uatom"}, strings.Fields(lines[2]))
+
+	// The schedules of the SDK's command carry their start time, which --start-time overrides.
+	sdkData := schedule("vesting.json", `{"start_time": 1767225600, "periods": [{"coins": "1000uatom", "length_seconds": 60}]}`)
+	res = sys.MustRun(t, "tx", "vesting", "create-periodic", "cosmoshub", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", sdkData, "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "1767225600", msgs[0].StartTime)
+	res = sys.MustRun(t, "tx", "vesting", "create-periodic", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", sdkData, "--start-time", "2026-02-01T00:00:00Z", "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Equal(t, "1769904000", msgs[0].StartTime)
+
+	csvRows := schedule("schedule.csv", "length_seconds,amount\n60,\"10uatom,1uosmo\"\n120,5uatom\n")
+	res = sys.MustRun(t, "tx", "vesting", "create-periodic", ZeroCosmosAddr, testGroupMemberAddr, "15uatom,1uosmo", csvRows, "--dry-run")
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs[0].VestingPeriods, 2)
+	require.Len(t, msgs[0].VestingPeriods[0].Amount, 2)
+	start, err := strconv.ParseInt(msgs[0].StartTime, 10, 64)
+	require.NoError(t, err)
+	require.InDelta(t, time.Now().Unix(), start, 60)
+
+	for file, msg := range map[string]string{
+		jsonList: "the amounts of the periods add up to 1500uatom, not to the total 1000uatom",
+		schedule("zero.json", `[{"length_seconds": 0, "amount": "1000uatom"}]`):                       "period 1: invalid length 0: must be a positive number of seconds",
+		schedule("both.json", `[{"length_seconds": 1, "amount": "1000uatom", "coins": "1000uatom"}]`): "period 1: amount and coins are aliases",
+		schedule("empty.json", `[]`):                                         "no periods",
+		schedule("bad.csv", "60,1000uatom\nsoon,1uatom\n"):                   `line 2: invalid length "soon"`,
+		schedule("amount.json", `[{"length_seconds": 1, "amount": "lots"}]`): `period 1: invalid amount "lots"`,
+	} {
+		res := sys.Run(zaptest.NewLogger(t), "tx", "vesting", "create-periodic", ZeroCosmosAddr, testGroupMemberAddr, "1000uatom", file, "--dry-run")
+		require.ErrorContains(t, res.Err, msg, file)
+	}
+}
@@ -0,0 +1,843 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmquery "github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/gogoproto/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/spf13/cobra"
+	"github.com/strangelove-ventures/lens/byop"
+	"github.com/strangelove-ventures/lens/client"
+	"github.com/strangelove-ventures/lens/client/grpcdynamic"
+	"github.com/strangelove-ventures/lens/client/query"
+	"google.golang.org/grpc"
+)
+
+const (
+	wasmKeyFormatFlag = "key-format"
+	wasmAmountFlag    = "amount"
+	wasmLabelFlag     = "label"
+	wasmAdminFlag     = "admin"
+	wasmNoAdminFlag   = "no-admin"
+
+	// wasmPackage is the protobuf package of the services of the CosmWasm module.
+	wasmPackage = "cosmwasm.wasm.v1"
+
+	// wasmMaxCodeSize is the largest bytecode accepted by MsgStoreCode, once compressed, in bytes,
+	// as set by the MaxWasmSize of wasmd, which chains rarely change.
+	wasmMaxCodeSize = 800 * 1024
+)
+
+// wasmClientHelp describes how the wasm commands reach the chain, for their long help.
+const wasmClientHelp = `Lens does not compile in the types of the CosmWasm module: they are read through the gRPC reflection
+service of the chain's gRPC endpoint, and cached as the dynamic commands cache them.
+A chain whose endpoint does not serve the cosmwasm.wasm.v1 services does not support wasm.`
+
+// wasmQueryCmd returns the CosmWasm query commands
+func wasmQueryCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "wasm",
+		Aliases: []string{"cosmwasm"},
+		Short:   "Querying commands for the CosmWasm module",
+	}
+
+	cmd.AddCommand(
+		wasmContractStateCmd(a),
+		wasmCodeListCmd(a),
+		wasmContractListByCodeCmd(a),
+	)
+
+	return cmd
+}
+
+// wasmTxCmd returns the CosmWasm tx commands
+func wasmTxCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "wasm",
+		Aliases: []string{"cosmwasm"},
+		Short:   "CosmWasm transaction commands",
+	}
+
+	cmd.AddCommand(
+		wasmStoreCmd(a),
+		wasmInstantiateCmd(a),
+		wasmExecuteCmd(a),
+	)
+
+	return cmd
+}
+
+func wasmContractStateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "contract-state",
+		Aliases: []string{"state"},
+		Short:   "Querying commands for the state of a contract",
+	}
+
+	cmd.AddCommand(
+		wasmSmartCmd(a),
+		wasmRawCmd(a),
+		wasmAllStateCmd(a),
+	)
+
+	return cmd
+}
+
+func wasmSmartCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "smart [chain-name] <contract> <query-json>",
+		Short: "query a contract with a JSON query message",
+		Long: `Send a JSON query message to a contract of the given chain, or of the default chain,
+and print the JSON response of the contract.
+
+` + wasmClientHelp,
+		Example: fmt.Sprintf(`$ %s query wasm contract-state smart juno juno1... '{"config": {}}'
+$ %s q wasm state smart juno1... '{"balance": {"address": "juno1..."}}' --height 1000000`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 2)
+			if !json.Valid([]byte(args[1])) {
+				return fmt.Errorf("invalid query %s: not JSON", args[1])
+			}
+			contract, err := resolveAddressArg(a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			var res struct {
+				Data []byte `json:"data"`
+			}
+			req := map[string]interface{}{"address": contract, "query_data": []byte(args[1])}
+			if err := wc.query(cmd.Context(), "SmartContractState", req, &res); err != nil {
+				return err
+			}
+			// The response of the contract is JSON, wrapped in the bytes of the query response.
+			if !json.Valid(res.Data) {
+				return fmt.Errorf("contract %s answered a response that is not JSON: %q", contract, res.Data)
+			}
+			return writeOutput(cmd, a, json.RawMessage(res.Data))
+		},
+	}
+	return gRPCFlags(cmd, a.Viper)
+}
+
+func wasmRawCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "raw [chain-name] <contract> <key>",
+		Short: "query the value stored at a key by a contract",
+		Long: `Query the value stored at a key in the state of a contract of the given chain, or of the default chain.
+The key is hex, unless --key-format is ascii or base64. The value is printed as is if it is JSON,
+as most contracts store their state, or else as a base64 JSON string.
+
+` + wasmClientHelp,
+		Example: fmt.Sprintf(`$ %s query wasm contract-state raw juno juno1... 636F6E666967
+$ %s q wasm state raw juno1... config --key-format ascii`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString(wasmKeyFormatFlag)
+			if err != nil {
+				return err
+			}
+			chainName, args := txArgs(a, args, 2)
+			key, err := decodeWasmKey(args[1], format)
+			if err != nil {
+				return err
+			}
+			contract, err := resolveAddressArg(a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			var res struct {
+				Data []byte `json:"data"`
+			}
+			req := map[string]interface{}{"address": contract, "query_data": key}
+			if err := wc.query(cmd.Context(), "RawContractState", req, &res); err != nil {
+				return err
+			}
+			if len(res.Data) == 0 {
+				return fmt.Errorf("contract %s stores no value at key %X", contract, key)
+			}
+			return writeOutput(cmd, a, wasmValue(res.Data))
+		},
+	}
+	cmd.Flags().String(wasmKeyFormatFlag, "hex", "the encoding of the key (hex, ascii, or base64)")
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// decodeWasmKey decodes the key of a contract's state, given in format.
+func decodeWasmKey(key, format string) ([]byte, error) {
+	var bz []byte
+	var err error
+	switch format {
+	case "hex":
+		bz, err = hex.DecodeString(key)
+	case "ascii":
+		bz = []byte(key)
+	case "base64":
+		bz, err = base64.StdEncoding.DecodeString(key)
+	default:
+		return nil, fmt.Errorf("invalid --%s %q (must be hex, ascii, or base64)", wasmKeyFormatFlag, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key %q: %w", format, key, err)
+	}
+	if len(bz) == 0 {
+		return nil, errors.New("the key must not be empty")
+	}
+	return bz, nil
+}
+
+// wasmValue returns a value of a contract's state as JSON: as is if it is JSON, or else as a base64 string.
+func wasmValue(bz []byte) json.RawMessage {
+	if json.Valid(bz) {
+		return bz
+	}
+	s, _ := json.Marshal(bz)
+	return s
+}
+
+func wasmAllStateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "all [chain-name] <contract>",
+		Short: "query every key and value stored by a contract",
+		Long: `Query every key and value in the state of a contract of the given chain, or of the default chain.
+The keys are printed in hex, and the values as is if they are JSON, or else as base64 JSON strings.
+
+` + paginationHelp + `
+
+` + wasmClientHelp,
+		Example: fmt.Sprintf(`$ %s query wasm contract-state all juno juno1...
+$ %s q wasm state all juno1... --limit 10 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pages, err := paginationFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			chainName, args := txArgs(a, args, 1)
+			contract, err := resolveAddressArg(a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			models := wasmModels{}
+			q := query.Query{Options: pages.queryOptions(0)}
+			err = q.AllPages(func(pr *tmquery.PageRequest) (*tmquery.PageResponse, error) {
+				var res struct {
+					Models []struct {
+						Key   []byte `json:"key"`
+						Value []byte `json:"value"`
+					} `json:"models"`
+					Pagination json.RawMessage `json:"pagination"`
+				}
+				req := map[string]interface{}{"address": contract, "pagination": wasmPageRequest(pr)}
+				if err := wc.query(cmd.Context(), "AllContractState", req, &res); err != nil {
+					return nil, err
+				}
+				for _, m := range res.Models {
+					models = append(models, wasmModel{Key: fmt.Sprintf("%X", m.Key), Value: wasmValue(m.Value)})
+				}
+				return wasmPageResponse(res.Pagination)
+			})
+			if err != nil {
+				return err
+			}
+			return pages.writeOutput(cmd, a, models, q.Pages)
+		},
+	}
+	addPaginationFlags(cmd, "keys")
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// wasmModel is a key of a contract's state, in hex, with its value.
+type wasmModel struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// wasmModels is the result of query wasm contract-state all.
+type wasmModels []wasmModel
+
+var _ fmt.Stringer = wasmModels{}
+
+// String returns the models as a table of keys and values.
+func (ms wasmModels) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, m := range ms {
+		fmt.Fprintf(w, "%s\t%s\n", m.Key, m.Value)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func wasmCodeListCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "code-list [chain-name]",
+		Aliases: []string{"codes"},
+		Short:   "query the codes stored on the chain",
+		Long: `Query the id, creator, and checksum of the codes stored on the given chain, or on the default chain.
+
+` + paginationHelp + `
+
+` + wasmClientHelp,
+		Example: fmt.Sprintf(`$ %s query wasm code-list juno
+$ %s q wasm codes --reverse --limit 10`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(0, 1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pages, err := paginationFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			chainName, _ := txArgs(a, args, 0)
+
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			codes := wasmCodes{}
+			q := query.Query{Options: pages.queryOptions(0)}
+			err = q.AllPages(func(pr *tmquery.PageRequest) (*tmquery.PageResponse, error) {
+				var res struct {
+					CodeInfos []struct {
+						CodeID   string `json:"codeId"`
+						Creator  string `json:"creator"`
+						DataHash []byte `json:"dataHash"`
+					} `json:"codeInfos"`
+					Pagination json.RawMessage `json:"pagination"`
+				}
+				req := map[string]interface{}{"pagination": wasmPageRequest(pr)}
+				if err := wc.query(cmd.Context(), "Codes", req, &res); err != nil {
+					return nil, err
+				}
+				for _, info := range res.CodeInfos {
+					id, err := strconv.ParseUint(info.CodeID, 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid code id %q: %w", info.CodeID, err)
+					}
+					codes = append(codes, wasmCode{CodeID: id, Creator: info.Creator, Checksum: fmt.Sprintf("%X", info.DataHash)})
+				}
+				return wasmPageResponse(res.Pagination)
+			})
+			if err != nil {
+				return err
+			}
+			return pages.writeOutput(cmd, a, codes, q.Pages)
+		},
+	}
+	addPaginationFlags(cmd, "codes")
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// wasmCode is a code stored on a chain.
+type wasmCode struct {
+	CodeID  uint64 `json:"code_id"`
+	Creator string `json:"creator"`
+
+	// Checksum is the SHA-256 hash of the bytecode, in hex.
+	Checksum string `json:"checksum"`
+}
+
+// wasmCodes is the result of query wasm code-list.
+type wasmCodes []wasmCode
+
+var _ fmt.Stringer = wasmCodes{}
+
+// String returns the codes as a table.
+func (cs wasmCodes) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE ID\tCREATOR\tCHECKSUM")
+	for _, c := range cs {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", c.CodeID, c.Creator, c.Checksum)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func wasmContractListByCodeCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "contract-list-by-code [chain-name] <code-id>",
+		Aliases: []string{"contracts"},
+		Short:   "query the contracts instantiated from a code",
+		Long: `Query the addresses of the contracts instantiated from a code of the given chain, or of the default chain.
+
+` + paginationHelp + `
+
+` + wasmClientHelp,
+		Example: fmt.Sprintf(`$ %s query wasm contract-list-by-code juno 1
+$ %s q wasm contracts 1 -o json`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pages, err := paginationFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			chainName, args := txArgs(a, args, 1)
+			codeID, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid code id %q: %w", args[0], err)
+			}
+
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			contracts := wasmContracts{}
+			q := query.Query{Options: pages.queryOptions(0)}
+			err = q.AllPages(func(pr *tmquery.PageRequest) (*tmquery.PageResponse, error) {
+				var res struct {
+					Contracts  []string        `json:"contracts"`
+					Pagination json.RawMessage `json:"pagination"`
+				}
+				req := map[string]interface{}{"code_id": strconv.FormatUint(codeID, 10), "pagination": wasmPageRequest(pr)}
+				if err := wc.query(cmd.Context(), "ContractsByCode", req, &res); err != nil {
+					return nil, err
+				}
+				contracts = append(contracts, res.Contracts...)
+				return wasmPageResponse(res.Pagination)
+			})
+			if err != nil {
+				return err
+			}
+			return pages.writeOutput(cmd, a, contracts, q.Pages)
+		},
+	}
+	addPaginationFlags(cmd, "contracts")
+	return gRPCFlags(cmd, a.Viper)
+}
+
+// wasmContracts is the result of query wasm contract-list-by-code.
+type wasmContracts []string
+
+var _ fmt.Stringer = wasmContracts{}
+
+// String returns the addresses of the contracts, one per line.
+func (cs wasmContracts) String() string {
+	var b strings.Builder
+	for _, c := range cs {
+		b.WriteString(c + "\n")
+	}
+	return b.String()
+}
+
+// wasmPageRequest returns the JSON encoding of pr, for the requests of the paged wasm queries.
+func wasmPageRequest(pr *tmquery.PageRequest) json.RawMessage {
+	var b bytes.Buffer
+	if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&b, pr); err != nil {
+		panic(err)
+	}
+	return b.Bytes()
+}
+
+// wasmPageResponse decodes the page response of a paged wasm query from its JSON encoding.
+func wasmPageResponse(bz json.RawMessage) (*tmquery.PageResponse, error) {
+	var res tmquery.PageResponse
+	if len(bz) == 0 || string(bz) == "null" {
+		return &res, nil
+	}
+	if err := (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(bz), &res); err != nil {
+		return nil, fmt.Errorf("failed to decode the page response: %w", err)
+	}
+	return &res, nil
+}
+
+func wasmStoreCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store [chain-name] <from-key> <wasm-file>",
+		Short: "store the bytecode of a contract on the chain",
+		Long: fmt.Sprintf(`Store the bytecode of a contract, read from a .wasm file, on the given chain, or on the default chain,
+signed by a key in its keyring. The code id is in the store_code event of the transaction.
+
+The bytecode is compressed with gzip, unless the file already is, as wasmd accepts.
+The transaction is broadcast through the chain's RPC endpoint, whose requests are limited to about 1MB
+by default, rather than through its gRPC endpoint; wasmd itself rejects compressed bytecode above %d KiB.
+
+`+wasmClientHelp+`
+
+`+txOptionsHelp, wasmMaxCodeSize/1024),
+		Example: fmt.Sprintf(`$ %s tx wasm store juno mykey contract.wasm
+$ %s tx wasm store mykey contract.wasm.gz --gas-prices 0.075ujuno`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainName, args := txArgs(a, args, 2)
+			bz, err := os.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			code, err := compressWasm(bz)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[1], err)
+			}
+			if len(code) > wasmMaxCodeSize {
+				return fmt.Errorf("%s: the compressed bytecode is %d bytes, above the limit of %d bytes of wasmd", args[1], len(code), wasmMaxCodeSize)
+			}
+
+			cl, sender, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			msg, err := wc.newMsg(cl, "StoreCode", map[string]interface{}{
+				"sender":         cl.MustEncodeAccAddr(sender),
+				"wasm_byte_code": code,
+			})
+			if err != nil {
+				return err
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	return wasmTxGRPCFlags(a, cmd)
+}
+
+// compressWasm returns bz compressed with gzip, if it is a WebAssembly module, or bz if it already is compressed.
+func compressWasm(bz []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(bz, []byte{0x1f, 0x8b}):
+		return bz, nil
+	case !bytes.HasPrefix(bz, []byte("\x00asm")):
+		return nil, errors.New("not a WebAssembly module, nor a gzip file")
+	}
+	var b bytes.Buffer
+	w, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(bz); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func wasmInstantiateCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "instantiate [chain-name] <from-key> <code-id> <init-json>",
+		Short: "instantiate a contract from a stored code",
+		Long: `Instantiate a contract from a code stored on the given chain, or on the default chain,
+with a JSON instantiate message, signed by a key in its keyring.
+The contract address is in the instantiate event of the transaction.
+
+The contract is labeled with --label. Its admin, which may migrate it, is given by --admin, as a key or an address;
+--no-admin must be given instead for a contract without an admin, which cannot be migrated.
+--amount sends funds to the contract.
+
+` + wasmClientHelp + `
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx wasm instantiate juno mykey 1 '{"count": 0}' --label counter --admin mykey
+$ %s tx wasm instantiate mykey 1 '{}' --label counter --no-admin --amount 1000ujuno`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f := cmd.Flags()
+			label, err := f.GetString(wasmLabelFlag)
+			if err != nil {
+				return err
+			}
+			if label == "" {
+				return fmt.Errorf("--%s is required", wasmLabelFlag)
+			}
+			admin, err := f.GetString(wasmAdminFlag)
+			if err != nil {
+				return err
+			}
+			noAdmin, err := f.GetBool(wasmNoAdminFlag)
+			if err != nil {
+				return err
+			}
+			if (admin == "") == !noAdmin {
+				return fmt.Errorf("exactly one of --%s and --%s must be given", wasmAdminFlag, wasmNoAdminFlag)
+			}
+			funds, err := wasmFundsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			chainName, args := txArgs(a, args, 3)
+			codeID, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid code id %q: %w", args[1], err)
+			}
+			if !json.Valid([]byte(args[2])) {
+				return fmt.Errorf("invalid instantiate message %s: not JSON", args[2])
+			}
+			cl, sender, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			if admin != "" {
+				adminAddr, err := cl.AccountFromKeyOrAddress(admin)
+				if err != nil {
+					return err
+				}
+				admin = cl.MustEncodeAccAddr(adminAddr)
+			}
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			msg, err := wc.newMsg(cl, "InstantiateContract", map[string]interface{}{
+				"sender":  cl.MustEncodeAccAddr(sender),
+				"admin":   admin,
+				"code_id": strconv.FormatUint(codeID, 10),
+				"label":   label,
+				"msg":     []byte(args[2]),
+				"funds":   funds,
+			})
+			if err != nil {
+				return err
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(wasmLabelFlag, "", "the label of the contract (required)")
+	cmd.Flags().String(wasmAdminFlag, "", "the key or address of the admin of the contract")
+	cmd.Flags().Bool(wasmNoAdminFlag, false, "instantiate the contract without an admin")
+	cmd.Flags().String(wasmAmountFlag, "", "the coins to send to the contract (e.g. 1000ujuno)")
+	return wasmTxGRPCFlags(a, cmd)
+}
+
+func wasmExecuteCmd(a *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "execute [chain-name] <from-key> <contract> <msg-json>",
+		Short: "execute a contract with a JSON message",
+		Long: `Execute a contract of the given chain, or of the default chain, with a JSON execute message,
+signed by a key in its keyring. --amount sends funds to the contract.
+
+` + wasmClientHelp + `
+
+` + txOptionsHelp,
+		Example: fmt.Sprintf(`$ %s tx wasm execute juno mykey juno1... '{"increment": {}}'
+$ %s tx wasm execute mykey juno1... '{"deposit": {}}' --amount 1000ujuno --dry-run`,
+			appName, appName),
+		Args: withUsage(cobra.RangeArgs(3, 4)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			funds, err := wasmFundsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			chainName, args := txArgs(a, args, 3)
+			if !json.Valid([]byte(args[2])) {
+				return fmt.Errorf("invalid execute message %s: not JSON", args[2])
+			}
+			cl, sender, err := txChainClient(cmd, a, chainName, args[0])
+			if err != nil {
+				return err
+			}
+			contract, err := cl.ResolveAccAddr(args[1])
+			if err != nil {
+				return err
+			}
+			wc, err := newWasmClient(cmd, a, chainName)
+			if err != nil {
+				return err
+			}
+			defer wc.Close()
+
+			msg, err := wc.newMsg(cl, "ExecuteContract", map[string]interface{}{
+				"sender":   cl.MustEncodeAccAddr(sender),
+				"contract": contract,
+				"msg":      []byte(args[2]),
+				"funds":    funds,
+			})
+			if err != nil {
+				return err
+			}
+			return sendTx(cmd, a, cl, msg)
+		},
+	}
+	addTxOptionsFlags(a, cmd)
+	cmd.Flags().String(wasmAmountFlag, "", "the coins to send to the contract (e.g. 1000ujuno)")
+	return wasmTxGRPCFlags(a, cmd)
+}
+
+// wasmFundsFromFlags returns the coins of the --amount flag of cmd.
+func wasmFundsFromFlags(cmd *cobra.Command) (sdk.Coins, error) {
+	amount, err := cmd.Flags().GetString(wasmAmountFlag)
+	if err != nil {
+		return nil, err
+	}
+	funds, err := sdk.ParseCoinsNormalized(amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s %q: %w", wasmAmountFlag, amount, err)
+	}
+	if funds == nil {
+		funds = sdk.Coins{}
+	}
+	return funds, nil
+}
+
+// wasmTxGRPCFlags adds the gRPC flags to cmd, a wasm tx command, which only reads descriptors from the gRPC endpoint.
+func wasmTxGRPCFlags(a *appState, cmd *cobra.Command) *cobra.Command {
+	cmd = gRPCFlags(cmd, a.Viper)
+	// The descriptors of the messages do not depend on the height.
+	if err := cmd.Flags().MarkHidden(gRPCHeightFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// wasmClient calls the cosmwasm.wasm.v1 services of a chain through its gRPC endpoint,
+// from the descriptors served by its reflection service.
+type wasmClient struct {
+	chainName string
+	conn      *grpc.ClientConn
+	rc        *grpcreflect.Client
+	src       grpcdynamic.DescriptorSource
+}
+
+// newWasmClient connects to the gRPC endpoint of the named chain, as set by the gRPC flags of cmd.
+// The client must be closed once done.
+func newWasmClient(cmd *cobra.Command, a *appState, chainName string) (*wasmClient, error) {
+	if chainName == "" {
+		return nil, NoDefaultChainError{}
+	}
+	gRPCAddr, err := chooseGRPCAddr(cmd, a, chainName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialGRPC(cmd, a, gRPCAddr)
+	if err != nil {
+		return nil, err
+	}
+	rc := newReflectionClient(cmd.Context(), a.Log, conn)
+	src, err := newDescriptorSource(cmd, a, gRPCAddr, rc)
+	if err != nil {
+		rc.Reset()
+		conn.Close()
+		return nil, err
+	}
+	return &wasmClient{chainName: chainName, conn: conn, rc: rc, src: src}, nil
+}
+
+func (c *wasmClient) Close() {
+	c.rc.Reset()
+	c.conn.Close()
+}
+
+// method returns the descriptor of the named method of the named service of the CosmWasm module,
+// or a WasmNotSupportedError if the chain does not serve the service.
+func (c *wasmClient) method(serviceName, methodName string) (*desc.MethodDescriptor, error) {
+	serviceName = wasmPackage + "." + serviceName
+	svcDesc, err := resolveService(c.src, serviceName, false)
+	if err != nil {
+		var notFound GRPCServiceNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, WasmNotSupportedError{Chain: c.chainName, Service: serviceName}
+		}
+		return nil, err
+	}
+	methodDesc := svcDesc.FindMethodByName(methodName)
+	if methodDesc == nil {
+		return nil, GRPCMethodNotFoundError{
+			TargetService: serviceName,
+			Requested:     methodName,
+			Available:     svcDesc.GetMethods(),
+		}
+	}
+	return methodDesc, nil
+}
+
+// query invokes the named method of the Query service with the JSON encoding of req,
+// and decodes the JSON encoding of its response into res.
+// Fields holding bytes are base64 in both encodings.
+func (c *wasmClient) query(ctx context.Context, methodName string, req, res interface{}) error {
+	methodDesc, err := c.method("Query", methodName)
+	if err != nil {
+		return err
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	out, err := invokeDynamic(ctx, c.conn, c.src, methodDesc, in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(out, res)
+}
+
+// newMsg returns the request message of the named method of the Msg service, with the fields of the JSON encoding of fields.
+// The messages of the service are first registered in the codec of cl, from their descriptors, as byop.DynamicMsg.
+func (c *wasmClient) newMsg(cl *client.ChainClient, methodName string, fields map[string]interface{}) (sdk.Msg, error) {
+	methodDesc, err := c.method("Msg", methodName)
+	if err != nil {
+		return nil, err
+	}
+	msgName := methodDesc.GetInputType().GetFullyQualifiedName()
+	fds := fileDescriptorSet([]*desc.FileDescriptor{methodDesc.GetFile()})
+	m, err := byop.NewModuleFromDescriptors(wasmPackage, fds, msgName)
+	if err != nil {
+		return nil, err
+	}
+	m.RegisterInterfaces(cl.Codec.InterfaceRegistry)
+	m.RegisterLegacyAminoCodec(cl.Codec.Amino)
+
+	fields["@type"] = "/" + msgName
+	bz, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var msg sdk.Msg
+	if err := cl.Codec.Marshaler.UnmarshalInterfaceJSON(bz, &msg); err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w", msgName, err)
+	}
+	return msg, nil
+}
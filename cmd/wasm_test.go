@@ -0,0 +1,341 @@
+package cmd_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	msgv1 "cosmossdk.io/api/cosmos/msg/v1"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/builder"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/strangelove-ventures/lens/cmd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const testContractAddr = "cosmos14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s4hmalr"
+
+// runWasmServer runs a server serving the cosmwasm.wasm.v1 Query service, described through reflection
+// with the Msg service, whose contract testContractAddr stores a count of 7.
+func runWasmServer(t *testing.T) string {
+	t.Helper()
+
+	pageReq, err := desc.LoadMessageDescriptor("cosmos.base.query.v1beta1.PageRequest")
+	require.NoError(t, err)
+	pageRes, err := desc.LoadMessageDescriptor("cosmos.base.query.v1beta1.PageResponse")
+	require.NoError(t, err)
+
+	stateReq := func(name string) *builder.MessageBuilder {
+		return builder.NewMessage(name).
+			AddField(builder.NewField("address", builder.FieldTypeString())).
+			AddField(builder.NewField("query_data", builder.FieldTypeBytes()))
+	}
+	stateRes := func(name string) *builder.MessageBuilder {
+		return builder.NewMessage(name).
+			AddField(builder.NewField("data", builder.FieldTypeBytes()))
+	}
+	model := builder.NewMessage("Model").
+		AddField(builder.NewField("key", builder.FieldTypeBytes())).
+		AddField(builder.NewField("value", builder.FieldTypeBytes()))
+	codeInfo := builder.NewMessage("CodeInfoResponse").
+		AddField(builder.NewField("code_id", builder.FieldTypeUInt64())).
+		AddField(builder.NewField("creator", builder.FieldTypeString())).
+		AddField(builder.NewField("data_hash", builder.FieldTypeBytes()))
+	messages := []*builder.MessageBuilder{
+		stateReq("QuerySmartContractStateRequest"),
+		stateRes("QuerySmartContractStateResponse"),
+		stateReq("QueryRawContractStateRequest"),
+		stateRes("QueryRawContractStateResponse"),
+		builder.NewMessage("QueryAllContractStateRequest").
+			AddField(builder.NewField("address", builder.FieldTypeString())).
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageReq))),
+		model,
+		builder.NewMessage("QueryAllContractStateResponse").
+			AddField(builder.NewField("models", builder.FieldTypeMessage(model)).SetRepeated()).
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageRes))),
+		builder.NewMessage("QueryCodesRequest").
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageReq))),
+		codeInfo,
+		builder.NewMessage("QueryCodesResponse").
+			AddField(builder.NewField("code_infos", builder.FieldTypeMessage(codeInfo)).SetRepeated()).
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageRes))),
+		builder.NewMessage("QueryContractsByCodeRequest").
+			AddField(builder.NewField("code_id", builder.FieldTypeUInt64())).
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageReq))),
+		builder.NewMessage("QueryContractsByCodeResponse").
+			AddField(builder.NewField("contracts", builder.FieldTypeString()).SetRepeated()).
+			AddField(builder.NewField("pagination", builder.FieldTypeImportedMessage(pageRes))),
+	}
+	querySvc := builder.NewService("Query")
+	for _, method := range []string{"SmartContractState", "RawContractState", "AllContractState", "Codes", "ContractsByCode"} {
+		var req, res *builder.MessageBuilder
+		for _, m := range messages {
+			switch m.GetName() {
+			case "Query" + method + "Request":
+				req = m
+			case "Query" + method + "Response":
+				res = m
+			}
+		}
+		querySvc.AddMethod(builder.NewMethod(method, builder.RpcTypeMessage(req, false), builder.RpcTypeMessage(res, false)))
+	}
+	queryFile := builder.NewFile("cosmwasm/wasm/v1/query.proto").
+		SetPackageName("cosmwasm.wasm.v1").
+		SetProto3(true).
+		AddService(querySvc)
+	for _, m := range messages {
+		queryFile.AddMessage(m)
+	}
+
+	signer := &descriptorpb.MessageOptions{}
+	protov2.SetExtension(signer, msgv1.E_Signer, []string{"sender"})
+	// The file of cosmos.base.v1beta1.Coin imports gogoproto, which is not in the global registry to be served.
+	coin := builder.NewMessage("Coin").
+		AddField(builder.NewField("denom", builder.FieldTypeString())).
+		AddField(builder.NewField("amount", builder.FieldTypeString()))
+	funds := func() *builder.FieldBuilder {
+		return builder.NewField("funds", builder.FieldTypeMessage(coin)).SetRepeated()
+	}
+	msgs := map[string]*builder.MessageBuilder{
+		"StoreCode": builder.NewMessage("MsgStoreCode").
+			AddField(builder.NewField("sender", builder.FieldTypeString())).
+			AddField(builder.NewField("wasm_byte_code", builder.FieldTypeBytes())),
+		"InstantiateContract": builder.NewMessage("MsgInstantiateContract").
+			AddField(builder.NewField("sender", builder.FieldTypeString())).
+			AddField(builder.NewField("admin", builder.FieldTypeString())).
+			AddField(builder.NewField("code_id", builder.FieldTypeUInt64())).
+			AddField(builder.NewField("label", builder.FieldTypeString())).
+			AddField(builder.NewField("msg", builder.FieldTypeBytes())).
+			AddField(funds()),
+		"ExecuteContract": builder.NewMessage("MsgExecuteContract").
+			AddField(builder.NewField("sender", builder.FieldTypeString())).
+			AddField(builder.NewField("contract", builder.FieldTypeString())).
+			AddField(builder.NewField("msg", builder.FieldTypeBytes())).
+			AddField(funds().SetNumber(5)),
+	}
+	msgSvc := builder.NewService("Msg")
+	txFile := builder.NewFile("cosmwasm/wasm/v1/tx.proto").
+		SetPackageName("cosmwasm.wasm.v1").
+		SetProto3(true).
+		AddMessage(coin).
+		AddService(msgSvc)
+	for method, m := range msgs {
+		m.SetOptions(signer)
+		res := builder.NewMessage(m.GetName() + "Response")
+		txFile.AddMessage(m).AddMessage(res)
+		msgSvc.AddMethod(builder.NewMethod(method, builder.RpcTypeMessage(m, false), builder.RpcTypeMessage(res, false)))
+	}
+
+	local := new(protoregistry.Files)
+	var queryDesc *desc.FileDescriptor
+	for _, b := range []*builder.FileBuilder{queryFile, txFile} {
+		fd, err := b.Build()
+		require.NoError(t, err)
+		file, err := protodesc.NewFile(fd.AsFileDescriptorProto(), protoregistry.GlobalFiles)
+		require.NoError(t, err)
+		require.NoError(t, local.RegisterFile(file))
+		if b == queryFile {
+			queryDesc = fd
+		}
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(wasmQueryServiceDesc(t, queryDesc.FindService("cosmwasm.wasm.v1.Query")), nil)
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{
+		Services: staticServices{
+			"cosmwasm.wasm.v1.Query": {},
+			"cosmwasm.wasm.v1.Msg":   {},
+		},
+		DescriptorResolver: fallbackResolver{local: local},
+	}))
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return ln.Addr().String()
+}
+
+// wasmQueryServiceDesc returns a service serving the methods of svc with fixed responses.
+func wasmQueryServiceDesc(t *testing.T, svc *desc.ServiceDescriptor) *grpc.ServiceDesc {
+	t.Helper()
+
+	responses := map[string]func(req *dynamic.Message, res *dynamic.Message){
+		"SmartContractState": func(req, res *dynamic.Message) {
+			if string(req.GetFieldByName("query_data").([]byte)) == `{"get_count":{}}` {
+				res.SetFieldByName("data", []byte(`{"count":7}`))
+			}
+		},
+		"RawContractState": func(req, res *dynamic.Message) {
+			if string(req.GetFieldByName("query_data").([]byte)) == "state" {
+				res.SetFieldByName("data", []byte(`{"count":7}`))
+			}
+		},
+		"AllContractState": func(req, res *dynamic.Message) {
+			model := dynamic.NewMessage(svc.GetFile().FindMessage("cosmwasm.wasm.v1.Model"))
+			model.SetFieldByName("key", []byte("state"))
+			model.SetFieldByName("value", []byte(`{"count":7}`))
+			owner := dynamic.NewMessage(model.GetMessageDescriptor())
+			owner.SetFieldByName("key", []byte{0xff})
+			owner.SetFieldByName("value", []byte{0x01})
+			res.SetFieldByName("models", []interface{}{model, owner})
+		},
+		"Codes": func(req, res *dynamic.Message) {
+			info := dynamic.NewMessage(svc.GetFile().FindMessage("cosmwasm.wasm.v1.CodeInfoResponse"))
+			info.SetFieldByName("code_id", uint64(1))
+			info.SetFieldByName("creator", testContractAddr)
+			info.SetFieldByName("data_hash", []byte{0xab, 0xcd})
+			res.SetFieldByName("code_infos", []interface{}{info})
+		},
+		"ContractsByCode": func(req, res *dynamic.Message) {
+			if req.GetFieldByName("code_id").(uint64) == 1 {
+				res.SetFieldByName("contracts", []string{testContractAddr})
+			}
+		},
+	}
+
+	sd := &grpc.ServiceDesc{
+		ServiceName: svc.GetFullyQualifiedName(),
+		HandlerType: (*interface{})(nil),
+	}
+	for _, m := range svc.GetMethods() {
+		m := m
+		respond := responses[m.GetName()]
+		require.NotNil(t, respond, m.GetName())
+		sd.Methods = append(sd.Methods, grpc.MethodDesc{
+			MethodName: m.GetName(),
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamic.NewMessage(m.GetInputType())
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				res := dynamic.NewMessage(m.GetOutputType())
+				respond(req, res)
+				return res, nil
+			},
+		})
+	}
+	return sd
+}
+
+func TestWasmQuery(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", runWasmServer(t))
+
+	// The response of the contract is unwrapped from the bytes of the query response.
+	res := sys.MustRun(t, "query", "wasm", "contract-state", "smart", "cosmoshub", testContractAddr, `{"get_count":{}}`)
+	require.JSONEq(t, `{"count":7}`, res.Stdout.String())
+	res = sys.Run(zaptest.NewLogger(t), "query", "wasm", "contract-state", "smart", testContractAddr, `{"get_count"`)
+	require.ErrorContains(t, res.Err, "not JSON")
+
+	res = sys.MustRun(t, "query", "wasm", "state", "raw", testContractAddr, "state", "--key-format", "ascii")
+	require.JSONEq(t, `{"count":7}`, res.Stdout.String())
+	res = sys.MustRun(t, "query", "wasm", "state", "raw", testContractAddr, "7374617465")
+	require.JSONEq(t, `{"count":7}`, res.Stdout.String())
+	res = sys.Run(zaptest.NewLogger(t), "query", "wasm", "state", "raw", testContractAddr, "6f74686572")
+	require.ErrorContains(t, res.Err, "stores no value at key 6F74686572")
+
+	// Values which are not JSON are printed in base64.
+	res = sys.MustRun(t, "query", "wasm", "state", "all", testContractAddr, "-o", "text")
+	require.Equal(t, "KEY         VALUE\n7374617465  {\"count\":7}\nFF          \"AQ==\"\n", res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "wasm", "code-list", "-o", "text")
+	require.Equal(t, "CODE ID  CREATOR"+strings.Repeat(" ", len(testContractAddr)-len("CREATOR")+2)+"CHECKSUM\n1        "+testContractAddr+"  ABCD\n", res.Stdout.String())
+	res = sys.MustRun(t, "query", "wasm", "code-list", "-o", "json")
+	require.JSONEq(t, `[{"code_id":1,"creator":"`+testContractAddr+`","checksum":"ABCD"}]`, res.Stdout.String())
+
+	res = sys.MustRun(t, "query", "wasm", "contract-list-by-code", "cosmoshub", "1", "-o", "text")
+	require.Equal(t, testContractAddr+"\n", res.Stdout.String())
+}
+
+func TestWasm_NotSupported(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", runGRPCReflectionServer(t))
+
+	for _, args := range [][]string{
+		{"query", "wasm", "code-list"},
+		{"tx", "wasm", "execute", "mykey", testContractAddr, `{}`, "--dry-run"},
+	} {
+		res := sys.Run(zaptest.NewLogger(t), args...)
+		var notSupported cmd.WasmNotSupportedError
+		require.ErrorAs(t, res.Err, &notSupported, args)
+		require.Equal(t, "cosmoshub", notSupported.Chain)
+		require.ErrorContains(t, res.Err, "chain cosmoshub does not support wasm")
+	}
+}
+
+func TestWasmTx_DryRun(t *testing.T) {
+	t.Parallel()
+
+	sys := NewSystem(t)
+	sys.MustRunWithInput(t, strings.NewReader(ZeroMnemonic+"\n"), "keys", "restore", "mykey")
+	sys.MustRun(t, "chains", "edit", "cosmoshub", "grpc-addr", runWasmServer(t))
+
+	res := sys.MustRun(t, "tx", "wasm", "execute", "mykey", testContractAddr, `{"increment":{}}`, "--amount", "5uatom", "--dry-run")
+	require.JSONEq(t, `[{
+		"@type": "/cosmwasm.wasm.v1.MsgExecuteContract",
+		"sender": "`+ZeroCosmosAddr+`",
+		"contract": "`+testContractAddr+`",
+		"msg": "eyJpbmNyZW1lbnQiOnt9fQ==",
+		"funds": [{"denom": "uatom", "amount": "5"}]
+	}]`, res.Stdout.String())
+
+	res = sys.MustRun(t, "tx", "wasm", "instantiate", "cosmoshub", "mykey", "1", `{}`, "--label", "counter", "--admin", "mykey", "--dry-run")
+	require.JSONEq(t, `[{
+		"@type": "/cosmwasm.wasm.v1.MsgInstantiateContract",
+		"sender": "`+ZeroCosmosAddr+`",
+		"admin": "`+ZeroCosmosAddr+`",
+		"code_id": "1",
+		"label": "counter",
+		"msg": "e30=",
+		"funds": []
+	}]`, res.Stdout.String())
+	res = sys.Run(zaptest.NewLogger(t), "tx", "wasm", "instantiate", "mykey", "1", `{}`, "--label", "counter", "--dry-run")
+	require.ErrorContains(t, res.Err, "exactly one of --admin and --no-admin must be given")
+
+	// The bytecode is compressed.
+	code := []byte("\x00asm\x01\x00\x00\x00")
+	file := filepath.Join(t.TempDir(), "contract.wasm")
+	require.NoError(t, os.WriteFile(file, code, 0o600))
+	res = sys.MustRun(t, "tx", "wasm", "store", "mykey", file, "--dry-run")
+	var msgs []struct {
+		Type         string `json:"@type"`
+		Sender       string `json:"sender"`
+		WasmByteCode []byte `json:"wasm_byte_code"`
+	}
+	require.NoError(t, json.Unmarshal(res.Stdout.Bytes(), &msgs))
+	require.Len(t, msgs, 1)
+	require.Equal(t, "/cosmwasm.wasm.v1.MsgStoreCode", msgs[0].Type)
+	require.Equal(t, ZeroCosmosAddr, msgs[0].Sender)
+	r, err := gzip.NewReader(bytes.NewReader(msgs[0].WasmByteCode))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, code, decompressed)
+
+	require.NoError(t, os.WriteFile(file, []byte("not wasm"), 0o600))
+	res = sys.Run(zaptest.NewLogger(t), "tx", "wasm", "store", "mykey", file, "--dry-run")
+	require.ErrorContains(t, res.Err, "not a WebAssembly module")
+}